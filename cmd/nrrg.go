@@ -16,6 +16,9 @@ limitations under the License.
 package cmd
 
 import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
 	mapset "github.com/deckarep/golang-set"
@@ -23,10 +26,23 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
 	"github.com/xuri/excelize/v2"
 	"github.com/zhenggao2/ngapp/nrgrid"
 	"github.com/zhenggao2/ngapp/utils"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	_ "gonum.org/v1/plot/vg/vgimg"
+	_ "gonum.org/v1/plot/vg/vgsvg"
+	imgcolor "image/color"
+	"io"
+	"io/ioutil"
 	"math"
+	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -66,6 +82,14 @@ type NrrgFlags struct {
 	pucch       PucchFlags
 	csi         CsiFlags
 	srs         SrsFlags
+	paging      PagingFlags
+	osi         OsiFlags
+	drx         DrxFlags
+	cgsps       CgSpsFlags
+	dss         DssFlags
+	ca          CaFlags
+	meas        MeasFlags
+	pos         PosFlags
 	advanced    AdvancedFlags
 }
 
@@ -91,12 +115,30 @@ type GridSettingFlags struct {
 	_maxL        int     // Maximum number of transmitted SSBs within a half frame in a cell
 	candSsbIndex []int   // List of candidate SSB index
 
+	ssbPosInBurst    string // The ssb-PositionsInBurst of ServingCellConfigCommon, which is shortBitmap(4 bits), mediumBitmap(8 bits) or longBitmap(64 bits)
+	ssbInOneGroup    string // The inOneGroup of ssb-PositionsInBurst of SIB1(8 bits)
+	ssbGroupPresence string // The groupPresence of ssb-PositionsInBurst of SIB1(8 bits), which is present only when L_max is 64
+
+	ssbPositionQcl       string // The ssb-PositionQCL-r16 of ServingCellConfigCommon for NR-U, which can be n1/n2/n4/n8
+	dbWinLen             string // The discoveryBurstWindowLength-r16 of ServingCellConfigCommon for NR-U, which can be ms0dot5/ms1/ms2/ms3/ms4/ms5
+	_intraCellGuardBands []int  // The intraCellGuardBandsDL-List-r16 and intraCellGuardBandsUL-List-r16 for NR-U, which is a list of [startCRB, nrofCRBs] of each intra-cell guard band
+
 	_carrierScs      string // The subcarrierSpacing of SCS-SpecificCarrier
 	bw               string // Channel bandwidth of the carrier in MHz
 	dlArfcn          int    // DL ARFCN of the carrier
 	_carrierNumRbs   int    // The carrierBandwidth of SCS-SpecificCarrier
 	_offsetToCarrier int    // The offsetToCarrier of SCS-SpecificCarrier
 
+	supBand            string // Supplementary band(SUL or SDL) paired with the band, such as n80/n84 for SUL and n75 for SDL, or empty if not configured
+	_supDuplexMode     string // Duplex mode of the supplementary band, which can be SUL or SDL
+	supArfcn           int    // ARFCN of the supplementary carrier, which is UL ARFCN for SUL and DL ARFCN for SDL
+	supBw              string // Channel bandwidth of the supplementary carrier in MHz
+	supScs             string // The subcarrierSpacing of the supplementary carrier, or empty to use the subcarrierSpacing of the carrier
+	_supScs            string // The subcarrierSpacing of SCS-SpecificCarrier of the supplementary carrier
+	_supCarrierNumRbs  int    // The carrierBandwidth of SCS-SpecificCarrier of the supplementary carrier
+	supOffsetToCarrier int    // The offsetToCarrier of SCS-SpecificCarrier of the supplementary carrier
+	supUsed            bool   // Whether the supplementary carrier is used, i.e. RACH/PUCCH/PUSCH/SRS are transmitted on SUL carrier, or PDSCH scheduled by DCI 1_1 is received on SDL carrier
+
 	pci int // Physical cell identity, which can be 0~1007
 
 	_mibCommonScs            string // The subCarrierSpacingCommon of MIB
@@ -122,6 +164,18 @@ type TddUlDlFlags struct {
 	patNumDlSymbs []int    // The nrofDownlinkSymbols of TDD-UL-DL-Pattern, and max length is 2
 	patNumUlSymbs []int    // The nrofUplinkSymbols of TDD-UL-DL-Pattern, and max length is 2
 	patNumUlSlots []int    // The nrofUplinkSlots of TDD-UL-DL-Pattern, and max length is 2
+
+	dedSlotIndex  []int    // The slotIndex of each TDD-UL-DL-SlotConfig of TDD-UL-DL-ConfigDedicated
+	dedSymbols    []string // The symbols of each TDD-UL-DL-SlotConfig of TDD-UL-DL-ConfigDedicated, which can be allDownlink, allUplink or explicit
+	dedNumDlSymbs []int    // The nrofDownlinkSymbols of explicit symbols of each TDD-UL-DL-SlotConfig of TDD-UL-DL-ConfigDedicated
+	dedNumUlSymbs []int    // The nrofUplinkSymbols of explicit symbols of each TDD-UL-DL-SlotConfig of TDD-UL-DL-ConfigDedicated
+
+	sfiPeriod      int      // The monitoringSlotPeriodicity of the search space for DCI 2_0 in slots, and SFI is not configured if sfiPeriod is 0
+	sfiOffset      int      // The monitoringSlotOffset of the search space for DCI 2_0 in slots
+	sfcScs         string   // The subcarrierSpacing of SlotFormatCombinationsPerCell, which is the same as the carrier if empty
+	sfcId          []int    // The slotFormatCombinationId of each SlotFormatCombination of SlotFormatCombinationsPerCell
+	sfcSlotFormats []string // The slotFormats of each SlotFormatCombination, which are separated by '_', e.g. 0_0_0_28_1
+	sfiIndex       []int    // The SFI-index field of DCI 2_0 detected in each PDCCH monitoring occasion, which is repeated if the number of monitoring occasions is larger than the length of sfiIndex
 }
 
 // initial/dedicated UL/DL BWP
@@ -133,6 +187,31 @@ type BwpFlags struct {
 	_bwpLocAndBw []int
 	_bwpStartRb  []int
 	_bwpNumRbs   []int
+
+	_supBwpStartRb []int // RB_start of initial and dedicated BWP of the supplementary carrier
+	_supBwpNumRbs  []int // L_RBs of initial and dedicated BWP of the supplementary carrier
+
+	useInterlace bool // the useInterlacePUCCH-PUSCH-r16 of BWP-UplinkDedicated for NR-U
+
+	// additional dedicated BWPs(bwp-Id 2..4), while bwp-Id of dedicated DL/UL BWP(DED_DL_BWP/DED_UL_BWP) is always 1
+	dlBwpId            []int    // bwp-Id of additional dedicated DL BWPs
+	dlBwpScs           []string // subcarrierSpacing of additional dedicated DL BWPs
+	dlBwpCp            []string // cyclicPrefix of additional dedicated DL BWPs
+	dlBwpStartRb       []int    // RB_start of additional dedicated DL BWPs, in common RBs of the subcarrierSpacing of the BWP
+	dlBwpNumRbs        []int    // L_RBs of additional dedicated DL BWPs
+	ulBwpId            []int    // bwp-Id of additional dedicated UL BWPs
+	ulBwpScs           []string // subcarrierSpacing of additional dedicated UL BWPs
+	ulBwpCp            []string // cyclicPrefix of additional dedicated UL BWPs
+	ulBwpStartRb       []int    // RB_start of additional dedicated UL BWPs, in common RBs of the subcarrierSpacing of the BWP
+	ulBwpNumRbs        []int    // L_RBs of additional dedicated UL BWPs
+	defaultDlBwpId     int      // the defaultDownlinkBWP-Id of ServingCellConfig
+	bwpInactivityTimer string   // the bwp-InactivityTimer of ServingCellConfig, or bwp-InactivityTimer is not configured if not set
+	bwpSwDelayType     string   // the bwp-SwitchingDelay of UE capability, which can be type1 or type2
+	bwpSwTrigger       []string // the trigger of each BWP switching, which can be dci(Bandwidth part indicator of DCI 1_1/0_1) or rrc(firstActiveDownlinkBWP-Id/firstActiveUplinkBWP-Id of RRCReconfiguration)
+	bwpSwDir           []string // the link direction of each BWP switching, which can be DL or UL
+	bwpSwSfn           []int    // the SFN of each BWP switching
+	bwpSwSlot          []int    // the slot of each BWP switching
+	bwpSwBwpId         []int    // the bwp-Id of the new active BWP of each BWP switching
 }
 
 const (
@@ -143,13 +222,20 @@ const (
 	INI_UL_BWP int = 2
 	DED_UL_BWP int = 3
 
+	// BWP tags of the supplementary carrier
+
+	SUP_INI_BWP int = 0
+	SUP_DED_BWP int = 1
+
 	// DL DCI tags
 
-	DCI_10_SIB1  int = 0 // rnti = SI-RNTI
-	DCI_10_MSG2  int = 1 // rnti = RA-RNTI
-	DCI_10_MSG4  int = 2 // rnti = TC-RNTI
-	DCI_11_PDSCH int = 3 // rnti = C-RNTI
-	DCI_10_MSGB  int = 4 // rnti = MSGB-RNTI (two-steps CBRA)
+	DCI_10_SIB1   int = 0 // rnti = SI-RNTI
+	DCI_10_MSG2   int = 1 // rnti = RA-RNTI
+	DCI_10_MSG4   int = 2 // rnti = TC-RNTI
+	DCI_11_PDSCH  int = 3 // rnti = C-RNTI
+	DCI_10_MSGB   int = 4 // rnti = MSGB-RNTI (two-steps CBRA)
+	DCI_10_PAGING int = 5 // rnti = P-RNTI
+	DCI_10_OSI    int = 6 // rnti = SI-RNTI (other SI)
 
 	// UL DCI tags
 
@@ -160,10 +246,13 @@ const (
 
 	// Common DMRS tags
 
-	DMRS_DCI_10_SIB1 int = 0
-	DMRS_DCI_10_MSG2 int = 1
-	DMRS_DCI_10_MSG4 int = 2
-	DMRS_RAR_UL_MSG3 int = 3
+	DMRS_DCI_10_SIB1   int = 0
+	DMRS_DCI_10_MSG2   int = 1
+	DMRS_DCI_10_MSG4   int = 2
+	DMRS_RAR_UL_MSG3   int = 3 // Msg3 scheduled by either RAR UL grant or fallbackRAR UL grant
+	DMRS_DCI_10_MSGB   int = 4
+	DMRS_DCI_10_PAGING int = 5
+	DMRS_DCI_10_OSI    int = 6
 
 	// NR resource tags
 
@@ -176,14 +265,17 @@ const (
 	NR_RES_CSI_RS          int = 6
 	NR_RES_MSG2            int = 7
 	NR_RES_MSG4            int = 8
+	NR_RES_MSGB            int = 9
+	NR_RES_PAGING          int = 4
+	NR_RES_OSI             int = 11
 
 	NR_RES_PRACH int = 10
 	// NR_RES_PUCCH int = 11
-	NR_RES_PUCCH_SR      int = 90
-	NR_RES_PUCCH_ACK     int = 91
-	NR_RES_PUCCH_CSI     int = 92
-	NR_RES_PUCCH_SR_CSI  int = 93
-	NR_RES_PUCCH_ACK_CSI int = 94
+	NR_RES_PUCCH_SR      int = 100
+	NR_RES_PUCCH_ACK     int = 101
+	NR_RES_PUCCH_CSI     int = 102
+	NR_RES_PUCCH_SR_CSI  int = 103
+	NR_RES_PUCCH_ACK_CSI int = 104
 	NR_RES_PUSCH         int = 12
 	NR_RES_MSG3          int = 13
 	NR_RES_SRS0          int = 14
@@ -191,17 +283,22 @@ const (
 	NR_RES_SRS1_3        int = 16
 	NR_RES_SRS0_1        int = 17
 	NR_RES_SRS0_1_2_3    int = 18
-
-	NR_RES_DMRS_PBCH  int = 20
-	NR_RES_DMRS_SIB1  int = 21
-	NR_RES_DMRS_PDCCH int = 22
-	NR_RES_DMRS_PDSCH int = 23
-	NR_RES_DMRS_MSG2  int = 24
-	NR_RES_DMRS_MSG4  int = 25
+	NR_RES_MSGA          int = 19
+
+	NR_RES_DMRS_PBCH   int = 20
+	NR_RES_DMRS_SIB1   int = 21
+	NR_RES_DMRS_PDCCH  int = 22
+	NR_RES_DMRS_PDSCH  int = 23
+	NR_RES_DMRS_MSG2   int = 24
+	NR_RES_DMRS_MSG4   int = 25
+	NR_RES_DMRS_MSGB   int = 26
+	NR_RES_DMRS_PAGING int = 27
+	NR_RES_DMRS_OSI    int = 28
 
 	NR_RES_DMRS_PUCCH int = 30
 	NR_RES_DMRS_PUSCH int = 31
 	NR_RES_DMRS_MSG3  int = 32
+	NR_RES_DMRS_MSGA  int = 33
 
 	NR_RES_PTRS_PDSCH int = 40
 	NR_RES_PTRS_PUSCH int = 41
@@ -235,6 +332,17 @@ const (
 	NR_RES_CSI_RS_CDM_GRP_14 int = 96
 	NR_RES_CSI_RS_CDM_GRP_15 int = 97
 
+	NR_RES_LTE_CRS   int = 110
+	NR_RES_LTE_PDCCH int = 111
+
+	NR_RES_BWP_SW int = 120
+
+	NR_RES_MEAS_GAP int = 130
+	NR_RES_SMTC     int = 131
+
+	NR_RES_PRS     int = 140
+	NR_RES_SRS_POS int = 141
+
 	NR_RES_BUTT int = 999
 )
 
@@ -306,7 +414,7 @@ type UlDciFlags struct {
 	_tdSliv                []int    // the SLIV of PUSCH TDRA (38.214 6.1.2.1	Resource allocation in time domain)
 	_tdStartSymb           []int    // the starting symbol S (38.214 6.1.2.1	Resource allocation in time domain)
 	_tdNumSymbs            []int    // the number of consecutive symbols L (38.214 6.1.2.1	Resource allocation in time domain)
-	_fdRaType              []string // the PUSCH resource allocation type, which can be raType0 or raType1 (38.214 6.1.2.2	Resource allocation in frequency domain)
+	_fdRaType              []string // the PUSCH resource allocation type, which can be raType0, raType1 or raType2 (38.214 6.1.2.2	Resource allocation in frequency domain)
 	fdFreqHop              []string // the "Frequency hopping flag" field of DCI 0_1 or RAR UL grant (38.214 6.3	UE PUSCH frequency hopping procedure)
 	_fdFreqHopOffset       []int    // the frequency offset of 2nd hop (38.214 6.3	UE PUSCH frequency hopping procedure)
 	_fdBitsRaType0         int      // the number of bits of the "Frequency domain resource assignment" field when raType0 is configured
@@ -314,6 +422,9 @@ type UlDciFlags struct {
 	_fdRa                  []string // the "Frequency domain resource assignment" field of DCI 0_1 or RAR UL grant
 	fdStartRb              []int    // the starting VRB RB_start (38.214 5.1.2.2	Resource allocation in frequency domain)
 	fdNumRbs               []int    // the number of contiguously allocated resource blocks L_RBs (38.214 6.1.2.2	Resource allocation in frequency domain)
+	fdInterlaces           []int    // the interlace indexes of PUSCH scheduled by DCI 0_1 when raType2 is used (38.214 6.1.2.2.3	Uplink resource allocation type 2)
+	fdStartRbSet           int      // the starting RB set of PUSCH scheduled by DCI 0_1 when raType2 is used
+	fdNumRbSets            int      // the number of contiguous RB sets of PUSCH scheduled by DCI 0_1 when raType2 is used
 	mcsCw0                 []int    // the "Modulation and coding scheme" field for transport block 1 of DCI 0_1 or RAR UL grant
 	_tbs                   []int    // the calculated TBS of transport block 1
 	precodingInfoNumLayers int      // the "Precoding information and number of layers" field of DCI 0_1
@@ -345,11 +456,25 @@ type RachFlags struct {
 	_raLen                             int
 	_raNumRbs                          int
 	_raKBar                            int
+	raType                             string // the type of random access procedure, which can be 4-step or 2-step
+	msgAPuschTimeOffset                int    // the msgA-PUSCH-TimeDomainOffset-r16 of MsgA-PUSCH-Resource-r16, in number of slots relative to the start of PRACH slot
+	msgAMappingType                    string // the mappingTypeMsgA-PUSCH-r16 of MsgA-PUSCH-Resource-r16, which can be typeA or typeB
+	msgAStartSymbAndLength             int    // the startSymbolAndLengthMsgA-PO-r16 of MsgA-PUSCH-Resource-r16
+	msgANumSlots                       int    // the nrofSlotsMsgA-PUSCH-r16 of MsgA-PUSCH-Resource-r16
+	msgANumPosPerSlot                  int    // the nrofMsgA-PO-PerSlot-r16 of MsgA-PUSCH-Resource-r16
+	msgAGuardPeriod                    int    // the guardPeriodMsgA-PUSCH-r16 of MsgA-PUSCH-Resource-r16, in number of symbols
+	msgANumPosFdm                      int    // the nrofMsgA-PO-FDM-r16 of MsgA-PUSCH-Resource-r16
+	msgAFreqStart                      int    // the frequencyStartMsgA-PUSCH-r16 of MsgA-PUSCH-Resource-r16, relative to the first PRB of the initial UL BWP
+	msgANumRbs                         int    // the nrofPRBs-PerMsgA-PO-r16 of MsgA-PUSCH-Resource-r16
+	msgAGuardBand                      int    // the guardBandMsgA-PUSCH-r16 of MsgA-PUSCH-Resource-r16, in number of PRBs
+	msgADmrsAddPos                     string // the msgA-DMRS-AdditionalPosition-r16 of MsgA-DMRS-Config-r16
+	msgAMcs                            int    // the msgA-MCS-r16 of MsgA-PUSCH-Resource-r16
+	msgBRespWin                        string // the msgB-ResponseWindow-r16 of RACH-ConfigCommonTwoStepRA-r16
 }
 
 // SIB1/Msg2/Msg4/Msg3 DMRS flags
 type DmrsCommonFlags struct {
-	_tag               []string // tag of DMRS, such as DCI_10_SIB1, DCI_10_MSG2, DCI_10_MSG4, RAR_UL_MSG3, FBRAR_UL_MSG3
+	_tag               []string // tag of DMRS, such as DCI_10_SIB1, DCI_10_MSG2, DCI_10_MSG4, RAR_UL_MSG3(or FBRAR_UL_MSG3), DCI_10_MSGB, DCI_10_PAGING, DCI_10_OSI
 	_dmrsType          []string // the dmrs-Type, which can be type1 or type2
 	_dmrsAddPos        []string // the dmrs-AdditionalPosition, which can be pos0, pos1, pos2 or pos3
 	_maxLength         []string // the maxLength, which can be len1 or len2
@@ -398,6 +523,9 @@ type PuschFlags struct {
 	puschMcsTable                string // the mcs-Table or mcs-TableTransformPrecoder of PUSCH-Config, which can be qam64, qam256 or qam64LowSE
 	puschXOh                     string // the xOverhead of PUSCH-ServingCellConfig, which can be xoh0, xoh6, xoh12, xoh18
 	_puschRepType                string // pusch-RepTypeIndicatorDCI-0-1-r16 or pusch-RepTypeIndicatorDCI-0-2-r16 of PUSCH-Config, which can be typeA or typeB
+	_puschAllocStartSymb         int    // the startSymbol-r16 of PUSCH-Allocation-r16 indexed by TDRA of DCI 0_1 for PUSCH repetition Type B, which can be 0..13
+	_puschAllocLength            int    // the length-r16 of PUSCH-Allocation-r16 indexed by TDRA of DCI 0_1 for PUSCH repetition Type B, which can be 1..14
+	_puschAllocNumReps           string // the numberOfRepetitions-r16 of PUSCH-Allocation-r16 indexed by TDRA of DCI 0_1 for PUSCH repetition Type B, which can be n1, n2, n3, n4, n7, n8, n12 or n16
 
 	puschDmrsType         string // the dmrs-Type of DMRS-UplinkConfig, which can be type1 or type2
 	puschDmrsAddPos       string // the dmrs-AdditionalPosition of DMRS-UplinkConfig, which can be pos0, pos1, pos2 or pos3
@@ -426,6 +554,8 @@ type PuschFlags struct {
 
 // PUCCH-Config
 type PucchFlags struct {
+	pucchResCommon int // the pucch-ResourceCommon of PUCCH-ConfigCommon, which is used before dedicated PUCCH resource configuration
+
 	_numSlots         string // the nrofSlots of PUCCH-FormatConfig, which can be n1/n2/n4/n8
 	_interSlotFreqHop string // the interslotFrequencyHopping of PUSCH-FormatConfig, which can be enabled or disabled
 	_addDmrs          bool   // the additionalDMRS of PUSCH-FormatConfig
@@ -440,6 +570,8 @@ type PucchFlags struct {
 	_pucchNumRbs           []int    // the nrofPRBs of PUCCH-format1 and PUCCH-format3
 	_pucchStartSymb        []int    // the startingSymbolIndex of PUCCH-format1 and PUCCH-format3
 	_pucchNumSymbs         []int    // the nrofSymbols of PUCCH-format1 and PUCCH-format3
+	_pucchInterlace        []int    // the interlace0-r16 of InterlaceAllocation-r16 of PUCCH-Resource when useInterlacePUCCH-PUSCH-r16 is configured
+	_pucchRbSet            []int    // the rb-SetIndex-r16 of InterlaceAllocation-r16 of PUCCH-Resource when useInterlacePUCCH-PUSCH-r16 is configured
 
 	//_dsrResId    []int
 	dsrPeriod    string // the periodicityAndOffset of SchedulingRequestResourceConfig
@@ -510,6 +642,131 @@ type SrsFlags struct {
 	_usage          []string
 }
 
+// Paging
+type PagingFlags struct {
+	defaultPagingCycle    string // the defaultPagingCycle of PCCH-Config, which can be rf32/rf64/rf128/rf256
+	nAndPagingFrameOffset string // the nAndPagingFrameOffset of PCCH-Config, which can be oneT/halfT/quarterT/oneEighthT/oneSixteenthT
+	pagingFrameOffset     int    // the PF_offset of nAndPagingFrameOffset, which is 0 for oneT, or [0..T/N-1] otherwise
+	ns                    string // the ns of PCCH-Config, which can be four/two/one
+	firstPdcchMoOfPo      []int  // the firstPDCCH-MonitoringOccasionOfPO of PCCH-Config, which is one value per PO within a PF if configured
+	numPdcchMoPerSsbInPo  int    // the nrofPDCCH-MonitoringOccasionPerSSB-InPO of PCCH-Config, which can be 1..4
+	ueId                  int    // the UE_ID for paging, which is 5G-S-TMSI mod 1024
+	pagedUe               bool   // whether the UE is paged before random access(mobile terminated access)
+	pagingSearchSpace     int    // the pagingSearchSpace of PDCCH-ConfigCommon, which can be 0(Type0-PDCCH CSS set) or searchSpaceId of the Type2-PDCCH CSS set
+}
+
+// Other system information
+type OsiFlags struct {
+	siWindowLength string   // the si-WindowLength of SI-SchedulingInfo, which can be s5/s10/s20/s40/s80/s160/s320/s640/s1280 in number of slots
+	siPeriodicity  []string // the si-Periodicity of each SchedulingInfo in schedulingInfoList, which can be rf8/rf16/rf32/rf64/rf128/rf256/rf512
+	siNumTxPerSsb  int      // number of PDCCH monitoring occasions(x=0,1,...) for each transmitted SSB in SI-window in which SI message is transmitted
+}
+
+// DRX
+type DrxFlags struct {
+	drxEnabled               bool   // whether DRX-Config is configured for the UE after random access procedure
+	drxOnDurationTimer       string // the drx-onDurationTimer of DRX-Config, which can be ms1..ms1600
+	drxInactivityTimer       string // the drx-InactivityTimer of DRX-Config, which can be ms0..ms2560
+	drxHarqRttTimerDl        int    // the drx-HARQ-RTT-TimerDL of DRX-Config in number of symbols, which can be 0..56
+	drxHarqRttTimerUl        int    // the drx-HARQ-RTT-TimerUL of DRX-Config in number of symbols, which can be 0..56
+	drxRetransmissionTimerDl string // the drx-RetransmissionTimerDL of DRX-Config, which can be sl0..sl320
+	drxRetransmissionTimerUl string // the drx-RetransmissionTimerUL of DRX-Config, which can be sl0..sl320
+	drxLongCycle             string // the drx-LongCycle of drx-LongCycleStartOffset, which can be ms10..ms10240
+	drxStartOffset           int    // the drx-StartOffset of drx-LongCycleStartOffset, which can be 0..drx-LongCycle-1
+	drxShortCycle            string // the drx-ShortCycle of shortDRX, which can be ms2..ms640, or short DRX cycle is not configured if not set
+	drxShortCycleTimer       int    // the drx-ShortCycleTimer of shortDRX in multiples of drx-ShortCycle, which can be 1..16
+	numTxPerCycle            int    // number of PDSCH/PUSCH with new transmission scheduled per DRX cycle, or full buffer if 0
+	nackPeriod               int    // every nackPeriod-th PDSCH/PUSCH with new transmission is not successfully decoded, or all are successfully decoded if 0
+}
+
+// Configured grant and DL SPS
+type CgSpsFlags struct {
+	spsEnabled          bool   // whether SPS-Config is configured
+	spsPeriodicity      string // the periodicity of SPS-Config, which can be ms10/ms20/ms32/ms40/ms64/ms80/ms128/ms160/ms320/ms640
+	spsNumHarqProc      int    // the nrofHARQ-Processes of SPS-Config, which can be 1..8
+	spsHarqProcIdOffset int    // the harq-ProcID-Offset-r16 of SPS-Config, which can be 0..15
+	spsN1PucchAn        int    // the n1PUCCH-AN of SPS-Config, which is the PUCCH-ResourceId of a format 0 or format 1 PUCCH resource
+	cgEnabled           bool   // whether ConfiguredGrantConfig is configured
+	cgType              string // the type of configured grant, which can be type1(rrc-ConfiguredUplinkGrant is configured) or type2
+	cgPeriodicity       string // the periodicity of ConfiguredGrantConfig in number of symbols, e.g. sym2/sym7/sym1x14/sym2x14 for normal CP, or sym2/sym6/sym1x12/sym2x12 for extended CP
+	cgNumHarqProc       int    // the nrofHARQ-Processes of ConfiguredGrantConfig, which can be 1..16
+	cgHarqProcIdOffset2 int    // the harq-ProcID-Offset2 of ConfiguredGrantConfig, which can be 0..15
+	cgRepK              string // the repK of ConfiguredGrantConfig, which can be n1/n2/n4/n8
+	cgRepKRv            string // the repK-RV of ConfiguredGrantConfig, which can be s1-0231/s2-0303/s3-0000
+	cgTimeDomainOffset  int    // the timeDomainOffset of rrc-ConfiguredUplinkGrant in number of slots, which can be 0..5119
+}
+
+// LTE-NR DSS
+type DssFlags struct {
+	lteCrsEnabled            bool   // whether lte-CRS-ToMatchAround is configured
+	lteCarrierFreqDl         int    // the carrierFreqDL of lte-CRS-ToMatchAround, which is the center of the LTE carrier in number of 15KHz subcarriers relative to point A
+	lteCarrierBw             string // the carrierBandwidthDL of lte-CRS-ToMatchAround, which can be n6/n15/n25/n50/n75/n100
+	lteNumCrsPorts           string // the nrofCRS-Ports of lte-CRS-ToMatchAround, which can be n1/n2/n4
+	lteVShift                string // the v-Shift of lte-CRS-ToMatchAround, which can be n0..n5
+	lteMbsfnSubframes        []int  // the subframes(i.e. 15KHz slots) of mbsfn-SubframeConfigList of lte-CRS-ToMatchAround, which can be 1/2/3/6/7/8 for FDD or 3/4/7/8/9 for TDD
+	ltePdcchNumSymbs         int    // number of OFDM symbols of LTE PDCCH region per subframe, which can be 1..3
+	rmpRbBitmap              string // the resourceBlocks of bitmaps of RateMatchPattern, where the leftmost bit corresponds to CRB 0, or RateMatchPattern is not configured if not set
+	rmpSymbBitmap            string // the symbolsInResourceBlock of bitmaps of RateMatchPattern, where the leftmost bit corresponds to symbol 0 of slot
+	rmpPeriodicityAndPattern string // the periodicityAndPattern of bitmaps of RateMatchPattern, where each bit corresponds to a slot, or symbolsInResourceBlock is present in every slot if not set
+}
+
+// Carrier aggregation
+type CaFlags struct {
+	scellIndex          []int    // the sCellIndex of each SCell, which can be 1..31, or carrier aggregation is not configured if not set
+	scellBand           []string // the NR frequency band of each SCell, which must be a TDD or FDD band
+	scellScs            []string // the subcarrierSpacing of SCS-SpecificCarrier of each SCell, e.g. 15KHz/30KHz/60KHz for FR1
+	scellBw             []string // the channel bandwidth of each SCell in MHz
+	scellPatPeriod      []string // the dl-UL-TransmissionPeriodicity of TDD-UL-DL-Pattern of each SCell, which is ignored for FDD SCell
+	scellPatNumDlSlots  []int    // the nrofDownlinkSlots of TDD-UL-DL-Pattern of each SCell
+	scellPatNumDlSymbs  []int    // the nrofDownlinkSymbols of TDD-UL-DL-Pattern of each SCell
+	scellPatNumUlSymbs  []int    // the nrofUplinkSymbols of TDD-UL-DL-Pattern of each SCell
+	scellPatNumUlSlots  []int    // the nrofUplinkSlots of TDD-UL-DL-Pattern of each SCell
+	scellCif            []int    // the cif-InSchedulingCell of CrossCarrierSchedulingConfig of each SCell, which can be 1..7 if the SCell is scheduled by the PCell, or 0 if the SCell is self-scheduled
+	_scellDuplexMode    []string // the duplex mode of each SCell, which can be TDD or FDD
+	_scellCarrierNumRbs []int    // the carrierBandwidth of SCS-SpecificCarrier of each SCell
+}
+
+// SMTC and measurement gap
+type MeasFlags struct {
+	smtcPeriodicity string   // the periodicity of periodicityAndOffset of SSB-MTC, which can be sf5/sf10/sf20/sf40/sf80/sf160, or SSB-MTC is not configured if not set
+	smtcOffset      int      // the offset of periodicityAndOffset of SSB-MTC in number of subframes, which can be 0..periodicity-1
+	smtcDuration    string   // the duration of SSB-MTC, which can be sf1..sf5
+	gapPatternId    int      // the gapPatternId of GapConfig, which can be 0..25, or MeasGapConfig is not configured if -1
+	gapOffset       int      // the gapOffset of GapConfig in number of subframes, which can be 0..MGRP-1
+	gapMgta         string   // the mgta of GapConfig, which can be ms0/ms0dot25/ms0dot5
+	nbrPci          []int    // the physical cell identity of each neighbour cell, or no neighbour cell if not set
+	nbrSsbPeriod    []string // the SSB periodicity of each neighbour cell, which can be ms5/ms10/ms20/ms40/ms80/ms160
+	nbrSsbOffset    []int    // the timing offset of the SSB bursts of each neighbour cell relative to SFN 0 of the serving cell in number of symbols, which can be 0..SSB periodicity*symbols per subframe-1
+}
+
+// DL-PRS and SRS for positioning
+type PosFlags struct {
+	prsPeriod             int      // the dl-PRS-Periodicity of DL-PRS-ResourceSet(i.e. T_PRS_per), which can be 4/5/8/10/16/20/32/40/64/80/160/320/640/1280/2560/5120/10240/20480
+	prsSetSlotOffset      int      // the dl-PRS-ResourceSetSlotOffset of DL-PRS-ResourceSet in number of slots(i.e. T_PRS_offset), which can be 0..2^u*T_PRS_per-1
+	prsRepFactor          int      // the dl-PRS-ResourceRepetitionFactor of DL-PRS-ResourceSet(i.e. T_PRS_rep), which can be 1/2/4/6/8/16/32
+	prsTimeGap            int      // the dl-PRS-ResourceTimeGap of DL-PRS-ResourceSet in number of slots(i.e. T_PRS_gap), which can be 1/2/4/8/16/32
+	prsCombSize           int      // the dl-PRS-CombSizeN of DL-PRS-ResourceSet(i.e. K_PRS_comb), which can be 2/4/6/12
+	prsNumSymbs           int      // the dl-PRS-NumSymbols of each DL PRS resource(i.e. L_PRS), which can be 2/4/6/12
+	prsStartPrb           int      // the dl-PRS-StartPRB of DL-PRS-ResourceSet relative to point A, which can be 0..2176
+	prsNumPrbs            int      // the dl-PRS-ResourceBandwidth of DL-PRS-ResourceSet in number of PRBs, which can be 24..272 with a granularity of 4 PRBs
+	prsMutingOpt1         string   // the bitmap of dl-PRS-MutingOption1 of DL-PRS-ResourceSet, where the leftmost bit corresponds to the first instance(s) of the resource set, or dl-PRS-MutingOption1 is not configured if not set
+	prsMutingBitRepFactor int      // the dl-PRS-MutingBitRepetitionFactor of dl-PRS-MutingOption1, which can be 1/2/4/8
+	prsMutingOpt2         string   // the bitmap of dl-PRS-MutingOption2 of DL-PRS-ResourceSet, where the leftmost bit corresponds to the first repetition, or dl-PRS-MutingOption2 is not configured if not set
+	prsResId              []int    // the nr-DL-PRS-ResourceID of each DL PRS resource, which can be 0..63, or DL-PRS-ResourceSet is not configured if not set
+	prsResReOffset        []int    // the dl-PRS-CombSizeN-AndReOffset of each DL PRS resource(i.e. k_PRS_offset), which can be 0..K_PRS_comb-1
+	prsResSlotOffset      []int    // the dl-PRS-ResourceSlotOffset of each DL PRS resource(i.e. T_PRS_offset_res), which can be 0..511
+	prsResSymbOffset      []int    // the dl-PRS-ResourceSymbolOffset of each DL PRS resource(i.e. l_PRS_start), which can be 0..12
+	srsPosResId           []int    // the srs-PosResourceId of each SRS-PosResource, which can be 0..63, or SRS-PosResourceSet is not configured if not set
+	srsPosNumCombs        []string // the transmissionComb of each SRS-PosResource, which can be n2/n4/n8
+	srsPosCombOff         []int    // the combOffset of transmissionComb of each SRS-PosResource, which can be 0..K_TC-1
+	srsPosStartPos        []int    // the startPosition of resourceMapping of each SRS-PosResource, which can be 0..13
+	srsPosNumSymbs        []string // the nrofSymbols of resourceMapping of each SRS-PosResource, which can be n1/n2/n4/n8/n12
+	srsPosFreqShift       []int    // the freqDomainShift of each SRS-PosResource in number of RBs, which can be 0..268
+	srsPosCSrs            []int    // the c-SRS of freqHopping of each SRS-PosResource, which can be 0..63
+	srsPosPeriod          []string // the periodicity of periodicityAndOffset-sp of each periodic SRS-PosResource, e.g. sl10/sl20/sl40/sl80/sl160
+	srsPosOffset          []int    // the offset of periodicityAndOffset-sp of each periodic SRS-PosResource in number of slots, which can be 0..periodicity-1
+}
+
 // Advanced settings
 type AdvancedFlags struct {
 	bestSsb       int
@@ -517,12 +774,88 @@ type AdvancedFlags struct {
 	prachOccMsg1  int
 	pdcchOccMsg2  int
 	pdcchOccMsg4  int
+	msgAPreamb    int      // index of the contention-based MsgA preamble among preambles associated with the best SSB
+	fallbackRar   bool     // whether MsgB is fallbackRAR instead of successRAR
+	cRnti         int      // the C-RNTI of UE after random access procedure
+	numSchedRfs   int      // number of radio frames for PDSCH/PUSCH scheduling with C-RNTI after random access procedure
+	format        []string // format(s) of NR resource grid export, which can be xlsx, csv, json, parquet, png or svg
+	outPath       string   // path of NR resource grid export without file extension
+	report        bool     // whether to export the report of RE counts, overheads and peak throughput to <outPath>_report.csv
+	imgSlots      []int    // slot range of PNG/SVG export, which is [sfn, firstSlot, numSlots], or one image per radio frame if not set
+	imgRbs        []int    // RB range of PNG/SVG export, which is [firstRb, numRbs]
+	imgSymbs      []int    // symbol range within each slot of PNG/SVG export, which is [firstSymb, numSymbs]
+	lbtFailCands  int      // number of candidate SSB positions at the start of discovery burst transmission window which are not transmitted due to LBT failure(NR-U)
 	//dsrRes        int
 }
 
 type DataPerRf struct {
-	res  []int        // REs in a slot, ordering: subcarriers per symbol, then symbols per radio frame
-	tags []mapset.Set // physical signals/channels mapped per slot
+	res   []int        // REs in a slot, ordering: subcarriers per symbol, then symbols per radio frame
+	tags  []mapset.Set // physical signals/channels mapped per slot
+	beams map[int]int  // SSB index of the beam of REs which are transmitted with a specific SSB beam, key = RE index
+}
+
+// NR resource grid of SCell, whose SCS, carrier bandwidth and TDD pattern can be different from the PCell
+type ScellData struct {
+	slotPerRf int
+	scPerSymb int
+	scPerSlot int
+	scPerRf   int
+	tddPat    []string          // D/F/U of each symbol in a radio frame, which is nil for FDD SCell
+	gridDl    map[int]DataPerRf // TDD or FDD DL grid (key=SFN, val=data per radio frame)
+	gridUl    map[int]DataPerRf // FDD UL grid only (key=SFN, val=data per radio frame)
+}
+
+// NR resource grid of additional dedicated BWP, whose SCS and cyclic prefix can be different from the carrier
+type BwpData struct {
+	id          int
+	dir         string // DL or UL
+	cp          string // normal or extended
+	slotPerRf   int
+	symbPerSlot int
+	scPerSymb   int
+	scPerSlot   int
+	scPerRf     int
+	startRb     int
+	numRbs      int
+	grid        map[int]DataPerRf    // key=SFN, val=data per radio frame, where RBs below RB_start of the BWP are marked as GB
+	pucchTr     map[int]*PucchTrInfo // PUCCH transmissions with HARQ-ACK on dedicated PUCCH resources of additional dedicated UL BWP (key=sfn*slotPerRf+slot of the BWP)
+}
+
+// BWP switching of the UE, where index 0 is for DL and index 1 is for UL
+type BwpSwInfo struct {
+	actBwp    []int    // bwp-Id of the active BWP
+	actFrom   []int    // the first slot(=sfn*slotPerRf+slot) when the active BWP can be used, i.e. the end of BWP switch delay
+	prevBwp   []int    // bwp-Id of the previous active BWP
+	prevUntil []int    // the slot until which(exclusive) the previous active BWP can be used
+	inactEnd  int      // expiry of bwp-InactivityTimer, which is -1 if bwp-InactivityTimer is not running
+	history   []string // BWP switchings
+}
+
+// PUCCH transmission on dedicated PUCCH resource
+type PucchTrInfo struct {
+	reqUci  []string      // UCI types requested in the slot
+	uci     []string      // UCI types transmitted after multiplexing
+	numAck  int           // number of HARQ-ACK information bits requested in the slot
+	r       int           // index of the dedicated PUCCH resource
+	numRep  int           // number of slots for PUCCH repetitions
+	res     map[int][]int // REs mapped per slot (key=sfn*slotPerRf+slot, val=REs)
+	onPusch bool          // whether the UCI is multiplexed on the overlapping PUSCH instead of PUCCH
+}
+
+// DRX timers and statistics of the UE, where timers are represented by the slot(=sfn*slotPerRf+slot) in which the timer expires
+type DrxInfo struct {
+	onDurationEnd  int     // expiry of drx-onDurationTimer
+	inactivityEnd  int     // expiry of drx-InactivityTimer
+	shortCycleEnd  int     // expiry of drx-ShortCycleTimer, and the Short DRX cycle is used before it
+	retxDl         [][]int // [start, end, nack) of drx-RetransmissionTimerDL, which is started upon expiry of drx-HARQ-RTT-TimerDL
+	retxUl         [][]int // [start, end, nack) of drx-RetransmissionTimerUL, which is started upon expiry of drx-HARQ-RTT-TimerUL, and nack=1 if the PUSCH is not successfully decoded
+	numNewDl       int     // number of PDSCH with new transmission in current DRX cycle
+	numNewUl       int     // number of PUSCH with new transmission in current DRX cycle
+	numNewTx       int     // number of PDSCH/PUSCH with new transmission, which is used to determine NACK
+	numMos         int     // number of PDCCH monitoring occasions of USS
+	numActiveMos   int     // number of PDCCH monitoring occasions of USS within active time
+	numActiveSlots int     // number of slots within active time
+	numSlots       int     // number of slots of data scheduling
 }
 
 //
@@ -539,15 +872,28 @@ type NrrgData struct {
 	scPerSubf   int
 	scPerRf     int
 
+	// dimensions of the supplementary carrier(SUL or SDL), whose subcarrierSpacing can be different from the carrier
+	supSlotPerRf int
+	supScPerSymb int
+	supScPerSlot int
+	supScPerRf   int
+
 	gridTdd      map[int]DataPerRf // TDD only (key=SFN, val=data per radio frame)
 	tddPatEvenRf []string
 	tddPatOddRf  []string
+	tddNumSlots  int               // number of slots of the periodicity(P+P2) of TDD-UL-DL-ConfigCommon
+	tddPat       map[int][]string  // slot format(D, U or F) of each symbol per radio frame, which is determined by getTddPat (key=SFN)
+	sfConflicts  []string          // SPS PDSCH/CG PUSCH allocations which conflict with the slot format
 	gridFddUl    map[int]DataPerRf // FDD UL only (key=SFN, val=data per radio frame)
 	gridFddDl    map[int]DataPerRf // FDD DL only (key=SFN, val=data per radio frame)
+	gridSup      map[int]DataPerRf // SUL or SDL carrier only (key=SFN, val=data per radio frame)
+	scells       []ScellData       // SCells of carrier aggregation, in the order of ca.scellIndex
+	bwps         []BwpData         // additional dedicated DL BWPs followed by additional dedicated UL BWPs
 
 	ssbFirstSymbs  []int
+	ssbCands       []int         // candidate SSB indexes which are transmitted within a half frame
 	trSsb          map[int]bool  // whether SSB is transmitted in certain SFN?
-	ssbSymbs       map[int][]int // SSB symbols per radio frame
+	ssbSymbs       map[int][]int // SSB symbols per radio frame, which is empty if no SSB is transmitted in the radio frame
 	ssbSc0Rb0      int
 	coreset0Sc0Rb0 int
 
@@ -558,18 +904,43 @@ type NrrgData struct {
 	occCss0             map[int]bool // whether PDCCH occasions for CSS0 is determined in certain SFN?
 	trPdcchSib1         map[int]bool // whether PDCCH for SIB1 is transmitted in certain SFN?
 	css0PdcchCandidates map[string][]nrgrid.Css0PdcchCandidate
+	trSib1              map[int]bool     // whether SIB1 is transmitted in certain SFN?
+	trCsi               map[int]bool     // whether periodic CSI-RS/CSI-IM is transmitted in certain SFN?
+	trSrs               map[int]bool     // whether periodic SRS is transmitted in certain SFN?
+	trPucch             map[int]bool     // whether SR/periodic CSI report on PUCCH is transmitted in certain SFN?
+	sib1Loc             map[string][]int // [SFN, slot] of SIB1 PDSCH (key="sfn_issb")
+	trOsi               map[int]bool     // whether SI messages whose SI-window starts in certain SFN are transmitted?
+	trLteCrs            map[int]bool     // whether LTE CRS and LTE PDCCH region are mapped in certain SFN?
+	trMeasGap           map[int]bool     // whether measurement gaps are marked in certain SFN?
+	trPrs               map[int]bool     // whether DL PRS is transmitted in certain SFN?
+	trSfi               map[int]bool     // whether PDCCH of DCI 2_0 is mapped in certain SFN?
+	measGapLost         []string         // PDSCH/PUSCH which are not scheduled due to measurement gaps
+	drx                 *DrxInfo         // DRX statistics of data scheduling, which is nil if DRX is not configured
+	bsw                 *BwpSwInfo       // BWP switching of data scheduling, which is nil if neither additional dedicated BWP nor BWP switching is configured
 
 	coreset1NumCces    int
 	coreset1RegBundles []nrgrid.RegInfo
 	coreset1Cces       []int
 
-	dci10Sib1Prbs []int
-	dci10Msg2Prbs []int
-	dci10Msg4Prbs []int
-	dci11Prbs     []int
-
-	msg4Recved bool
-	resMap     map[int]nrgrid.NrResExt
+	dci10Sib1Prbs   []int
+	dci10Msg2Prbs   []int
+	dci10Msg4Prbs   []int
+	dci10MsgBPrbs   []int
+	dci10PagingPrbs []int
+	dci10OsiPrbs    []int
+	dci11Prbs       []int
+
+	pucchTr    map[string]*PucchTrInfo // PUCCH transmissions on dedicated PUCCH resources (key=firstSlot_UCI)
+	pucchSlots map[int]string          // slots(=sfn*slotPerRf+slot) occupied by dedicated PUCCH transmissions (val=key of pucchTr)
+
+	raRnti       int   // the RA-RNTI of Msg1
+	msgBRnti     int   // the MSGB-RNTI of MsgA
+	msg1Ro       []int // the PRACH occasion of Msg1 or MsgA preamble, which is [SFN, index of TD PRACH occasions, f]
+	dci10Cce0    int   // the n_CCE,0 of the latest DCI 1_0, which is used for PUCCH resource determination before dedicated PUCCH resource configuration
+	dci10NumCces int   // the N_CCE of the CORESET of the latest DCI 1_0, which is used for PUCCH resource determination before dedicated PUCCH resource configuration
+	msg4Recved   bool
+	msg4Slot     int // the slot(=sfn*slotPerRf+slot) of Msg4 or MsgB with successRAR, after which measurement gaps and SMTC windows are applied
+	resMap       map[int]nrgrid.NrResExt
 }
 
 // nrrgCmd represents the "nrrg" command
@@ -593,15 +964,38 @@ var gridSettingCmd = &cobra.Command{
 
 		// initialization
 		if flags.dmrsCommon._tdL == nil {
-			flags.dmrsCommon._tdL = make([][]int, 4)
+			flags.dmrsCommon._tdL = make([][]int, 7)
 		}
 		if flags.dmrsCommon._fdK == nil {
-			flags.dmrsCommon._fdK = make([][]int, 4)
+			flags.dmrsCommon._fdK = make([][]int, 7)
 		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		viper.WatchConfig()
 
+		// process gridsetting.band of SUL or SDL
+		// Note: SUL and SDL bands can't be used standalone, so a SUL or SDL band is configured as the supplementary band paired with the TDD or FDD band in use.
+		if cmd.Flags().Lookup("band").Changed {
+			if p, exist := nrgrid.OpBands[flags.gridsetting.band]; exist && (p.DuplexMode == "SUL" || p.DuplexMode == "SDL") {
+				// Note: the band configured in the config file is read from the sub-tree of gridsetting, because nrrg.gridsetting.band is overridden by the band flag.
+				supBand := flags.gridsetting.band
+				band := ""
+				if cfg := viper.Sub("nrrg.gridsetting"); cfg != nil {
+					band = cfg.GetString("band")
+				}
+				if q, exist := nrgrid.OpBands[band]; !exist || (q.DuplexMode != "TDD" && q.DuplexMode != "FDD") {
+					regRed.Printf("[ERR]: %v band %v must be paired with a TDD or FDD band, please configure band first!\n", p.DuplexMode, supBand)
+					return
+				}
+
+				regGreen.Printf("[INFO]: %v band %v is configured as the supplementary band(supBand) paired with band %v.\n", p.DuplexMode, supBand, band)
+				flags.gridsetting.band = band
+				flags.gridsetting.supBand = supBand
+				cmd.Flags().Lookup("band").Changed = false
+				cmd.Flags().Lookup("supBand").Changed = true
+			}
+		}
+
 		// process gridsetting.band
 		if cmd.Flags().Lookup("band").Changed {
 			regGreen.Printf("[INFO]: Processing gridSetting.band...\n")
@@ -648,16 +1042,8 @@ var gridSettingCmd = &cobra.Command{
 			//	return
 			//}
 
-			// SDL and SUL are not supported!
-			if p.DuplexMode == "SDL" || p.DuplexMode == "SUL" {
-				regRed.Printf("[ERR]: %v is not supported!\n", p.DuplexMode)
-				return
-			}
-
-			// NR-U is not supported!
-			if band == "n46" || band == "n96" || band == "n102" {
-				regRed.Printf("[ERR]: NR-U [Band n46/n96/n102] is not supported!\n")
-				return
+			if flags.gridsetting._unlicensed {
+				fmt.Printf("Operation with shared spectrum channel access(NR-U) is used for band %v.\n", band)
 			}
 
 			// get available SSB scs
@@ -705,7 +1091,7 @@ var gridSettingCmd = &cobra.Command{
 			// set SCS for SSB/RMSI/Carrier
 			flags.gridsetting._ssbScs = flags.gridsetting.scs
 			flags.gridsetting._carrierScs = flags.gridsetting.scs
-			if flags.gridsetting._freqRange == "FR2-2" {
+			if flags.gridsetting._freqRange == "FR2-2" || flags.gridsetting._unlicensed {
 				// refer to 38.311 vh30
 				// subCarrierSpacingCommon of MIB
 				// For operation with shared spectrum channel access in FR1 (see 37.213 [48]) and for operation in FR2-2, the subcarrier spacing for SIB1, Msg.2/4 and MsgB for initial access, paging and broadcast SI-messages is same as that for the corresponding SSB.
@@ -717,12 +1103,19 @@ var gridSettingCmd = &cobra.Command{
 			// update SSB pattern
 			band := flags.gridsetting.band
 			scs := flags.gridsetting._ssbScs
+			var ssbScsSet []string
 			for _, v := range nrgrid.SsbRasters[band] {
+				ssbScsSet = append(ssbScsSet, v[0])
 				if v[0] == scs {
 					fmt.Printf("SSB Raster Info: %v\n", v)
 					flags.gridsetting._ssbPattern = v[1]
 				}
 			}
+			// Note: SSB, CORESET0 and initial BWPs are mapped onto the NR resource grid of the carrier with a single numerology, so the SCS of carrier must be one of the SSB SCS of the band, e.g. 60KHz in FR1 can only be used by the additional dedicated BWPs.
+			if !utils.ContainsStr(ssbScsSet, scs) {
+				regRed.Printf("[ERR]: The subcarrierSpacing(=%v) of carrier must be one of the SSB SCS(=%v) of band %v, please use dlBwpScs/ulBwpScs of \"nrrg bwp\" for %v!\n", scs, ssbScsSet, band, scs)
+				return
+			}
 
 			// update SSB burst (refer to 3GPP TS 38.213 vh40: 4.1	Cell search)
 			pat := flags.gridsetting._ssbPattern
@@ -759,10 +1152,10 @@ var gridSettingCmd = &cobra.Command{
 
 			// update refScs of TDD-UL-DL-Config
 			flags.tdduldl._refScs = scs
-			// update u_PDCCH/u_PDSCH/u_PUSCH in DCI 0_1/1_1 and Msg3 PUSCH
+			// update u_PDCCH/u_PDSCH/u_PUSCH in DCI 0_1/1_1, Msg3 PUSCH and MsgA PUSCH
 			u := nrgrid.Scs2Mu[flags.gridsetting._carrierScs]
-			flags.uldci._muPdcch = []int{u, u}
-			flags.uldci._muPusch = []int{u, u}
+			flags.uldci._muPdcch = []int{u, u, u, u}
+			flags.uldci._muPusch = []int{u, u, u, u}
 			flags.uldci._tdDelta = nrgrid.PuschTimeAllocMsg3K2Delta[flags.gridsetting._carrierScs]
 			// update SCS of initial UL BWP and dedicated UL/DL BWP
 			flags.bwp._bwpScs[DED_DL_BWP] = flags.gridsetting._carrierScs
@@ -773,10 +1166,10 @@ var gridSettingCmd = &cobra.Command{
 			// update TRS periodicity (2023/2/20: For simplicity, TRS is not supported!)
 			fmt.Printf("Available TRS periodicity: %v\n", []string{"slots10", "slots20", "slots40", "slots80", "slots160", "slots320", "slots640"}[u:u+4])
 
-			// update u_PDCCH/u_PDSCH for SIB1/Msg2/Msg4
+			// update u_PDCCH/u_PDSCH for SIB1/Msg2/Msg4/MsgB/Paging/OSI
 			u = nrgrid.Scs2Mu[flags.gridsetting._mibCommonScs]
-			flags.dldci._muPdcch = []int{u, u, u, u}
-			flags.dldci._muPdsch = []int{u, u, u, u}
+			flags.dldci._muPdcch = []int{u, u, u, u, u, u, u}
+			flags.dldci._muPdsch = []int{u, u, u, u, u, u, u}
 			// update SCS for initial DL BWP
 			// refer to 3GPP TS 38.331 vh30: subcarrierSpacing of BWP
 			// For the initial DL BWP and operation in licensed spectrum this field has the same value as the field subCarrierSpacingCommon in MIB of the same serving cell.
@@ -806,19 +1199,21 @@ var gridSettingCmd = &cobra.Command{
 			fr := flags.gridsetting._freqRange
 			bw := flags.gridsetting.bw
 			carrierScsVal, _ := strconv.Atoi(flags.gridsetting._carrierScs[:len(flags.gridsetting._carrierScs)-3])
-			var idx int
-			if fr == "FR1" {
-				idx = utils.IndexStr(nrgrid.BwSetFr1, bw)
-			} else if fr == "FR2-1" {
-				idx = utils.IndexStr(nrgrid.BwSetFr21, bw)
-			} else if fr == "FR2-2" {
-				idx = utils.IndexStr(nrgrid.BwSetFr22, bw)
-			}
-			if idx < 0 {
-				regRed.Printf("[ERR]: Invalid carrier bandwidth for %v: carrierBw=%v\n", fr, bw)
+			nrb := getNrb(fr, bw, carrierScsVal)
+			if nrb == 0 {
+				regRed.Printf("[ERR]: Invalid carrier bandwidth for %v: carrierBw=%v, carrierScs=%v\n", fr, bw, flags.gridsetting._carrierScs)
 				return
 			}
-			flags.gridsetting._carrierNumRbs = nrgrid.NrbFr1[carrierScsVal][idx]
+			flags.gridsetting._carrierNumRbs = nrb
+
+			// update intra-cell guard bands for NR-U
+			flags.gridsetting._intraCellGuardBands = []int{}
+			if flags.gridsetting._unlicensed {
+				if gb, exist := nrgrid.IntraCellGbFr1[fmt.Sprintf("%v_%v", carrierScsVal, bw)]; exist {
+					flags.gridsetting._intraCellGuardBands = append(flags.gridsetting._intraCellGuardBands, gb...)
+				}
+				fmt.Printf("Intra-cell guard bands([startCRB, nrofCRBs]): %v\n", flags.gridsetting._intraCellGuardBands)
+			}
 
 			// update RB_Start and L_RB for initial UL BWP and dedicated UL/DL BWP
 			flags.bwp._bwpStartRb[DED_DL_BWP] = 0
@@ -839,12 +1234,10 @@ var gridSettingCmd = &cobra.Command{
 			flags.dldci._fdBitsRaType0 = bitsRaType0Dl
 			flags.dldci._fdBitsRaType1 = []int{}
 			for i, _ := range flags.dldci._rnti {
-				if i == DCI_10_SIB1 || i == DCI_10_MSG2 || i == DCI_10_MSG4 {
+				if i == DCI_10_SIB1 || i == DCI_10_MSG2 || i == DCI_10_MSG4 || i == DCI_10_MSGB || i == DCI_10_PAGING || i == DCI_10_OSI {
 					flags.dldci._fdBitsRaType1 = append(flags.dldci._fdBitsRaType1, bitsRaType1Bwp0)
 				} else if i == DCI_11_PDSCH {
 					flags.dldci._fdBitsRaType1 = append(flags.dldci._fdBitsRaType1, bitsRaType1Bwp1)
-				} else if i == DCI_10_MSGB {
-					flags.dldci._fdBitsRaType1 = append(flags.dldci._fdBitsRaType1, bitsRaType1Bwp0)
 				}
 			}
 			flags.uldci._fdBitsRaType0 = bitsRaType0Ul
@@ -870,6 +1263,20 @@ var gridSettingCmd = &cobra.Command{
 			for range flags.uldci._rnti {
 				flags.uldci._fdFreqHopOffset = append(flags.uldci._fdFreqHopOffset, utils.FloorInt(float64(flags.gridsetting._carrierNumRbs)/2))
 			}
+
+			// update FDRA of DCI 1_1/0_1 and PRBs of dedicated PUCCH for the new size of dedicated DL/UL BWP
+			updateDedFdra()
+			updateDedPucchPrbs()
+		}
+
+		// process gridsetting.supBand and gridsetting.supBw
+		if cmd.Flags().Lookup("supBand").Changed || cmd.Flags().Lookup("supBw").Changed || cmd.Flags().Lookup("supScs").Changed || cmd.Flags().Lookup("scs").Changed {
+			regGreen.Printf("[INFO]: Processing gridSetting.supBand/supBw...\n")
+			err := updateSupCarrier()
+			if err != nil {
+				regRed.Printf("[ERR]: %s\n", err.Error())
+				return
+			}
 		}
 
 		// process gridsetting.dmrsTypeAPos
@@ -911,15 +1318,47 @@ var gridSettingCmd = &cobra.Command{
 		}
 
 		regGreen.Printf("[INFO]: Post-processing...\n")
+		// validate cyclic prefix of BWPs
+		err := validateBwpCp()
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return
+		}
+
+		// validate supplementary carrier
+		err = validateSupCarrier()
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return
+		}
+
+		// update transmitted SSBs
+		err = validateSsbPosInBurst()
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return
+		}
+
 		// update rach info
-		err := updateRach()
+		err = updateRach()
+		if err != nil {
+			regRed.Printf("[ERR]: %v\n", err.Error())
+			return
+		}
+
+		// validate MsgA of 2-step RA
+		err = validateMsgA()
 		if err != nil {
 			regRed.Printf("[ERR]: %v\n", err.Error())
 			return
 		}
 
 		// update n_CRB_SSB/k_SSB
-		updateKSsbAndNCrbSsb()
+		err = updateKSsbAndNCrbSsb()
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return
+		}
 
 		// validate CORESET0
 		err = validateCoreset0()
@@ -942,6 +1381,13 @@ var gridSettingCmd = &cobra.Command{
 			return
 		}
 
+		// validate NR-U
+		err = validateNru()
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return
+		}
+
 		// validate PUCCH
 		err = validatePucch()
 		if err != nil {
@@ -956,6 +1402,83 @@ var gridSettingCmd = &cobra.Command{
 			return
 		}
 
+		// validate SRS
+		err = validateSrs()
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return
+		}
+
+		// validate paging
+		err = validatePaging()
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return
+		}
+
+		// validate OSI
+		err = validateOsi()
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return
+		}
+
+		// validate DRX
+		err = validateDrx()
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return
+		}
+
+		// validate configured grant and DL SPS
+		err = validateCgSps()
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return
+		}
+
+		// validate LTE-NR DSS
+		err = validateDss()
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return
+		}
+
+		// validate carrier aggregation
+		err = validateCa()
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return
+		}
+
+		// validate BWP switching
+		err = validateBwpSw()
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return
+		}
+
+		// validate TDD-UL-DL-ConfigDedicated and SFI
+		err = validateTddSfi()
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return
+		}
+
+		// validate SMTC and measurement gap
+		err = validateMeas()
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return
+		}
+
+		// validate DL-PRS and SRS for positioning
+		err = validatePos()
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return
+		}
+
 		laPrint(cmd, args)
 		viper.WriteConfig()
 
@@ -981,71 +1504,129 @@ var gridSettingCmd = &cobra.Command{
 			return
 		}
 
-		/*
-			// receiving SIB1
-			regYellow.Printf("[5GNR SIM]UE recv SSB/SIB1 @ [SFN=%d]\n", sfn)
-			sfn, slot, err = recvSib1(sfn)
+		// RACH procedure, where timeline records [SFN, slot] of each step
+		var timeline []string
+
+		// receiving SIB1
+		sfn, slot, err = recvSib1(sfn)
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return
+		}
+		regYellow.Printf("[5GNR SIM]UE recv SSB/SIB1 @ [SFN=%d, Slot=%d]\n", sfn, slot)
+		timeline = append(timeline, fmt.Sprintf("SIB1@[%d,%d]", sfn, slot))
+
+		if flags.paging.pagedUe {
+			// receiving paging(PDCCH with P-RNTI and paging PDSCH)
+			sfn, slot, err = recvPaging(sfn, slot)
+			if err != nil {
+				regRed.Printf("[ERR]: %s\n", err.Error())
+				return
+			}
+			regYellow.Printf("[5GNR SIM]UE recv Paging @ [SFN=%d, Slot=%d]\n", sfn, slot)
+			timeline = append(timeline, fmt.Sprintf("Paging@[%d,%d]", sfn, slot))
+		}
+
+		if flags.rach.raType == "2-step" {
+			// sending MsgA(PRACH and PUSCH)
+			sfn, slot, err = sendMsgA(sfn, slot)
+			if err != nil {
+				regRed.Printf("[ERR]: %s\n", err.Error())
+				return
+			}
+			regYellow.Printf("[5GNR SIM]UE send MsgA(PRACH and PUSCH) @ [SFN=%d, Slot=%d]\n", sfn, slot)
+			timeline = append(timeline, fmt.Sprintf("MsgA@[%d,%d]", sfn, slot))
+			sfn, slot = getCarrierSlot(sfn, slot)
+
+			// monitoring PDCCH for MsgB
+			sfn, slot, err = monitorPdcch(sfn, slot, "dci10", "MSGB-RNTI")
 			if err != nil {
 				regRed.Printf("[ERR]: %s\n", err.Error())
 				return
 			}
+			regYellow.Printf("[5GNR SIM]UE recv PDCCH(DCI 1_0, MSGB-RNTI) @ [SFN=%d, Slot=%d]\n", sfn, slot)
+			timeline = append(timeline, fmt.Sprintf("PDCCH(MSGB-RNTI)@[%d,%d]", sfn, slot))
 
+			// receiving MsgB
+			sfn, slot, err = recvMsgB(sfn, slot)
+			if err != nil {
+				regRed.Printf("[ERR]: %s\n", err.Error())
+				return
+			}
+			regYellow.Printf("[5GNR SIM]UE recv MsgB @ [SFN=%d, Slot=%d]\n", sfn, slot)
+			timeline = append(timeline, fmt.Sprintf("MsgB@[%d,%d]", sfn, slot))
+		} else {
 			// sending Msg1(PRACH)
-			regYellow.Printf("[5GNR SIM]UE send PRACH(Msg1) @ [SFN=%d, Slot=%d]\n", sfn, slot)
 			sfn, slot, err = sendMsg1(sfn, slot)
 			if err != nil {
 				regRed.Printf("[ERR]: %s\n", err.Error())
 				return
 			}
+			regYellow.Printf("[5GNR SIM]UE send PRACH(Msg1) @ [SFN=%d, Slot=%d]\n", sfn, slot)
+			timeline = append(timeline, fmt.Sprintf("Msg1@[%d,%d]", sfn, slot))
+			sfn, slot = getCarrierSlot(sfn, slot)
 
 			// monitoring PDCCH for Msg2(RAR)
-			regYellow.Printf("[5GNR SIM]UE recv PDCCH(DCI 1_0, RA-RNTI) @ [SFN=%d, Slot=%d]\n", sfn, slot)
 			sfn, slot, err = monitorPdcch(sfn, slot, "dci10", "RA-RNTI")
 			if err != nil {
 				regRed.Printf("[ERR]: %s\n", err.Error())
 				return
 			}
+			regYellow.Printf("[5GNR SIM]UE recv PDCCH(DCI 1_0, RA-RNTI) @ [SFN=%d, Slot=%d]\n", sfn, slot)
+			timeline = append(timeline, fmt.Sprintf("PDCCH(RA-RNTI)@[%d,%d]", sfn, slot))
 
 			// receiving Msg2(RAR)
-			regYellow.Printf("[5GNR SIM]UE recv RAR(Msg2) @ [SFN=%d, Slot=%d]\n", sfn, slot)
 			sfn, slot, err = recvMsg2(sfn, slot)
 			if err != nil {
 				regRed.Printf("[ERR]: %s\n", err.Error())
 				return
 			}
+			regYellow.Printf("[5GNR SIM]UE recv RAR(Msg2) @ [SFN=%d, Slot=%d]\n", sfn, slot)
+			timeline = append(timeline, fmt.Sprintf("Msg2@[%d,%d]", sfn, slot))
+		}
 
+		// Msg3/Msg4 follows MsgB with fallbackRAR in 2-step RA
+		if flags.rach.raType == "4-step" || flags.advanced.fallbackRar {
 			// sending Msg3 PUSCH
-			regYellow.Printf("[5GNR SIM]UE send Msg3 @ [SFN=%d, Slot=%d]\n", sfn, slot)
 			sfn, slot, err = sendMsg3(sfn, slot)
 			if err != nil {
 				regRed.Printf("[ERR]: %s\n", err.Error())
 				return
 			}
+			regYellow.Printf("[5GNR SIM]UE send Msg3 @ [SFN=%d, Slot=%d]\n", sfn, slot)
+			timeline = append(timeline, fmt.Sprintf("Msg3@[%d,%d]", sfn, slot))
+			sfn, slot = getCarrierSlot(sfn, slot)
 
 			// monitoring PDCCH for Msg4
-			regYellow.Printf("[5GNR SIM]UE recv PDCCH(DCI 1_0, TC-RNTI) @ [SFN=%d, Slot=%d]\n", sfn, slot)
 			sfn, slot, err = monitorPdcch(sfn, slot, "dci10", "TC-RNTI")
 			if err != nil {
 				regRed.Printf("[ERR]: %s\n", err.Error())
 				return
 			}
+			regYellow.Printf("[5GNR SIM]UE recv PDCCH(DCI 1_0, TC-RNTI) @ [SFN=%d, Slot=%d]\n", sfn, slot)
+			timeline = append(timeline, fmt.Sprintf("PDCCH(TC-RNTI)@[%d,%d]", sfn, slot))
 
 			// receiving Msg4
-			regYellow.Printf("[5GNR SIM]UE recv Msg4 @ [SFN=%d, Slot=%d]\n", sfn, slot)
 			sfn, slot, err = recvMsg4(sfn, slot)
 			if err != nil {
 				regRed.Printf("[ERR]: %s\n", err.Error())
 				return
 			}
+			regYellow.Printf("[5GNR SIM]UE recv Msg4 @ [SFN=%d, Slot=%d]\n", sfn, slot)
+			timeline = append(timeline, fmt.Sprintf("Msg4@[%d,%d]", sfn, slot))
+		}
 
-			// sending HARQ-AN of Msg4(PUCCH)
-			regYellow.Printf("[5GNR SIM]UE send PUCCH(Msg4 HARQ) @ [SFN=%d, Slot=%d]\n", sfn, slot)
-			sfn, slot, err = sendPucch(sfn, slot, true, false, false, "common") //harq=True, sr=False, csi=False, pucchResSet='common'
-			if err != nil {
-				regRed.Printf("[ERR]: %s\n", err.Error())
-				return
-			}
-		*/
+		// sending HARQ-AN of Msg4 or MsgB(PUCCH)
+		sfn, slot, err = sendPucch(sfn, slot, true, false, false, "common") //harq=True, sr=False, csi=False, pucchResSet='common'
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return
+		}
+		regYellow.Printf("[5GNR SIM]UE send PUCCH(HARQ-ACK) @ [SFN=%d, Slot=%d]\n", sfn, slot)
+		timeline = append(timeline, fmt.Sprintf("PUCCH@[%d,%d]", sfn, slot))
+		sfn, slot = getCarrierSlot(sfn, slot)
+
+		regGreen.Printf("[INFO]: RACH timeline: %v\n", strings.Join(timeline, " -> "))
 
 		// UL always-on transmission (pCSI/SRS)
 		regYellow.Printf("[5GNR SIM]Init always-on-transmission(periodic CSI-RS/SRS) @ [SFN=%d, Slot=%d]\n", sfn, slot)
@@ -1055,6 +1636,50 @@ var gridSettingCmd = &cobra.Command{
 			return
 		}
 
+		// DL SPS and configured grant with CS-RNTI (PDSCH/PUSCH)
+		if flags.advanced.numSchedRfs > 0 && (flags.cgsps.spsEnabled || flags.cgsps.cgEnabled) {
+			regYellow.Printf("[5GNR SIM]Start DL SPS/configured grant(DCI 1_1/0_1, CS-RNTI) @ [SFN=%d, Slot=%d]\n", sfn, slot)
+			numSps, numCg, err := schedCgSps(sfn, slot)
+			if err != nil {
+				regRed.Printf("[ERR]: %s\n", err.Error())
+				return
+			}
+			regGreen.Printf("[INFO]: DL SPS/configured grant: %v SPS PDSCH(s) and %v configured grant PUSCH(s) are transmitted within %v radio frame(s)\n", numSps, numCg, flags.advanced.numSchedRfs)
+		}
+
+		// data scheduling with C-RNTI (PDSCH/PUSCH)
+		if flags.advanced.numSchedRfs > 0 {
+			regYellow.Printf("[5GNR SIM]Start data scheduling(DCI 1_1/0_1, C-RNTI) @ [SFN=%d, Slot=%d]\n", sfn, slot)
+			numPdsch, numPusch, err := schedData(sfn, slot)
+			if err != nil {
+				regRed.Printf("[ERR]: %s\n", err.Error())
+				return
+			}
+			regGreen.Printf("[INFO]: Data scheduling: %v PDSCH(s) and %v PUSCH(s) are scheduled within %v radio frame(s)\n", numPdsch, numPusch, flags.advanced.numSchedRfs)
+		}
+
+		// SPS PDSCH/CG PUSCH allocations which conflict with the slot format
+		if len(rgd.sfConflicts) > 0 {
+			fmt.Printf("Allocations dropped due to conflict with the slot format: %v\n", rgd.sfConflicts)
+			regGreen.Printf("[INFO]: Slot format: %v SPS PDSCH/CG PUSCH allocation(s) conflict with the slot format determined by TDD-UL-DL-ConfigCommon, TDD-UL-DL-ConfigDedicated and DCI 2_0\n", len(rgd.sfConflicts))
+		}
+
+		// PDSCH/PUSCH scheduling opportunities lost due to measurement gaps
+		if len(rgd.measGapLost) > 0 {
+			fmt.Printf("PDSCH/PUSCH not scheduled or transmitted due to measurement gap: %v\n", rgd.measGapLost)
+			regGreen.Printf("[INFO]: Measurement gap: %v PDSCH/PUSCH scheduling opportunities are lost due to measurement gaps(gapPatternId=%v, gapOffset=%v, mgta=%v)\n", len(rgd.measGapLost), flags.meas.gapPatternId, flags.meas.gapOffset, flags.meas.gapMgta)
+		}
+
+		// SMTC windows of SSB-MTC
+		if len(flags.meas.smtcPeriodicity) > 0 {
+			markSmtc()
+		}
+
+		// SSBs of neighbour cells within SMTC windows and measurement gaps
+		if len(flags.meas.nbrPci) > 0 {
+			checkNbrSsbs()
+		}
+
 		// export NR resource grid
 		regYellow.Printf("[5GNR SIM]Exporting NR resource grid...\n")
 		err = exportNrrg()
@@ -1210,6 +1835,13 @@ func makeResMap(wb *excelize.File) error {
 	})
 	rgd.resMap[NR_RES_GB] = nrgrid.NrResExt{Tag: "GB", Style: style}
 
+	style, _ = wb.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#000000"}, Pattern: 1},
+		Font:      &excelize.Font{Color: "#FFFFFF"},
+	})
+	rgd.resMap[NR_RES_F] = nrgrid.NrResExt{Tag: "F", Style: style}
+
 	style, _ = wb.NewStyle(&excelize.Style{
 		Alignment: &excelize.Alignment{Horizontal: "center"},
 		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#00FF00"}, Pattern: 1},
@@ -1238,6 +1870,20 @@ func makeResMap(wb *excelize.File) error {
 	})
 	rgd.resMap[NR_RES_SIB1] = nrgrid.NrResExt{Tag: "SIB1", Style: style}
 
+	style, _ = wb.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#FFFFFF"}, Pattern: 1},
+		Font:      &excelize.Font{Color: "#FF00FF"},
+	})
+	rgd.resMap[NR_RES_PAGING] = nrgrid.NrResExt{Tag: "PAGING", Style: style}
+
+	style, _ = wb.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#FFFFFF"}, Pattern: 1},
+		Font:      &excelize.Font{Color: "#008080"},
+	})
+	rgd.resMap[NR_RES_OSI] = nrgrid.NrResExt{Tag: "OSI", Style: style}
+
 	style, _ = wb.NewStyle(&excelize.Style{
 		Alignment: &excelize.Alignment{Horizontal: "center"},
 		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#FF00FF"}, Pattern: 1},
@@ -1245,6 +1891,7 @@ func makeResMap(wb *excelize.File) error {
 	})
 	rgd.resMap[NR_RES_MSG2] = nrgrid.NrResExt{Tag: "MSG2", Style: style}
 	rgd.resMap[NR_RES_MSG4] = nrgrid.NrResExt{Tag: "MSG4", Style: style}
+	rgd.resMap[NR_RES_MSGB] = nrgrid.NrResExt{Tag: "MSGB", Style: style}
 
 	style, _ = wb.NewStyle(&excelize.Style{
 		Alignment: &excelize.Alignment{Horizontal: "center"},
@@ -1266,6 +1913,10 @@ func makeResMap(wb *excelize.File) error {
 	rgd.resMap[NR_RES_DMRS_PDSCH] = nrgrid.NrResExt{Tag: "DMRS", Style: style}
 	rgd.resMap[NR_RES_DMRS_PUCCH] = nrgrid.NrResExt{Tag: "DMRS", Style: style}
 	rgd.resMap[NR_RES_DMRS_MSG3] = nrgrid.NrResExt{Tag: "DMRS", Style: style}
+	rgd.resMap[NR_RES_DMRS_MSGB] = nrgrid.NrResExt{Tag: "DMRS", Style: style}
+	rgd.resMap[NR_RES_DMRS_PAGING] = nrgrid.NrResExt{Tag: "DMRS", Style: style}
+	rgd.resMap[NR_RES_DMRS_OSI] = nrgrid.NrResExt{Tag: "DMRS", Style: style}
+	rgd.resMap[NR_RES_DMRS_MSGA] = nrgrid.NrResExt{Tag: "DMRS", Style: style}
 	rgd.resMap[NR_RES_DMRS_PUSCH] = nrgrid.NrResExt{Tag: "DMRS", Style: style}
 
 	style, _ = wb.NewStyle(&excelize.Style{
@@ -1292,18 +1943,243 @@ func makeResMap(wb *excelize.File) error {
 		rgd.resMap[NR_RES_PDCCH_CANDIDATE+i] = nrgrid.NrResExt{Tag: fmt.Sprintf("PDCCH%v", i), Style: style}
 	}
 
-	return nil
-}
+	style, _ = wb.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#FFD9B3"}, Pattern: 1},
+		Font:      &excelize.Font{Color: "#000000"},
+	})
+	rgd.resMap[NR_RES_CORESET1] = nrgrid.NrResExt{Tag: "CORESET1", Style: style}
 
-func exportNrrg() error {
-	wb := excelize.NewFile()
-	if err := makeResMap(wb); err != nil {
-		return err
+	style, _ = wb.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#0080FF"}, Pattern: 1},
+		Font:      &excelize.Font{Color: "#FFFFFF"},
+	})
+	for i := 0; i < 16; i++ {
+		rgd.resMap[NR_RES_CSI_RS_CDM_GRP_0+i] = nrgrid.NrResExt{Tag: fmt.Sprintf("CSI%v", i), Style: style}
 	}
 
-	if flags.gridsetting._duplexMode == "TDD" {
-		var keys []int
-		for sfn := range rgd.gridTdd {
+	style, _ = wb.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#004080"}, Pattern: 1},
+		Font:      &excelize.Font{Color: "#FFFFFF"},
+	})
+	rgd.resMap[NR_RES_TRS] = nrgrid.NrResExt{Tag: "TRS", Style: style}
+
+	style, _ = wb.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#808000"}, Pattern: 1},
+		Font:      &excelize.Font{Color: "#FFFFFF"},
+	})
+	rgd.resMap[NR_RES_LTE_CRS] = nrgrid.NrResExt{Tag: "CRS", Style: style}
+
+	style, _ = wb.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#C0C0C0"}, Pattern: 1},
+		Font:      &excelize.Font{Color: "#000000"},
+	})
+	rgd.resMap[NR_RES_LTE_PDCCH] = nrgrid.NrResExt{Tag: "LTE-PDCCH", Style: style}
+
+	style, _ = wb.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#404040"}, Pattern: 1},
+		Font:      &excelize.Font{Color: "#FFFFFF"},
+	})
+	rgd.resMap[NR_RES_BWP_SW] = nrgrid.NrResExt{Tag: "BWP-SW", Style: style}
+
+	style, _ = wb.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#800080"}, Pattern: 1},
+		Font:      &excelize.Font{Color: "#FFFFFF"},
+	})
+	rgd.resMap[NR_RES_MEAS_GAP] = nrgrid.NrResExt{Tag: "MG", Style: style}
+
+	style, _ = wb.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#D8BFD8"}, Pattern: 1},
+		Font:      &excelize.Font{Color: "#000000"},
+	})
+	rgd.resMap[NR_RES_SMTC] = nrgrid.NrResExt{Tag: "SMTC", Style: style}
+
+	style, _ = wb.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#FF6600"}, Pattern: 1},
+		Font:      &excelize.Font{Color: "#000000"},
+	})
+	rgd.resMap[NR_RES_PRS] = nrgrid.NrResExt{Tag: "PRS", Style: style}
+
+	style, _ = wb.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#008080"}, Pattern: 1},
+		Font:      &excelize.Font{Color: "#FFFFFF"},
+	})
+	rgd.resMap[NR_RES_SRS_POS] = nrgrid.NrResExt{Tag: "SRS-POS", Style: style}
+
+	style, _ = wb.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#C0C0C0"}, Pattern: 1},
+		Font:      &excelize.Font{Color: "#000000"},
+	})
+	rgd.resMap[NR_RES_CSI_IM] = nrgrid.NrResExt{Tag: "CSI-IM", Style: style}
+
+	style, _ = wb.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#FFC000"}, Pattern: 1},
+		Font:      &excelize.Font{Color: "#000000"},
+	})
+	rgd.resMap[NR_RES_SRS0] = nrgrid.NrResExt{Tag: "SRS0", Style: style}
+	rgd.resMap[NR_RES_SRS0_2] = nrgrid.NrResExt{Tag: "SRS02", Style: style}
+	rgd.resMap[NR_RES_SRS1_3] = nrgrid.NrResExt{Tag: "SRS13", Style: style}
+	rgd.resMap[NR_RES_SRS0_1] = nrgrid.NrResExt{Tag: "SRS01", Style: style}
+	rgd.resMap[NR_RES_SRS0_1_2_3] = nrgrid.NrResExt{Tag: "SRS0123", Style: style}
+
+	style, _ = wb.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#80FF00"}, Pattern: 1},
+		Font:      &excelize.Font{Color: "#000000"},
+	})
+	rgd.resMap[NR_RES_PRACH] = nrgrid.NrResExt{Tag: "PRACH", Style: style}
+
+	style, _ = wb.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#FF00FF"}, Pattern: 1},
+		Font:      &excelize.Font{Color: "#000000"},
+	})
+	rgd.resMap[NR_RES_MSG3] = nrgrid.NrResExt{Tag: "MSG3", Style: style}
+	rgd.resMap[NR_RES_MSGA] = nrgrid.NrResExt{Tag: "MSGA", Style: style}
+	rgd.resMap[NR_RES_PUSCH] = nrgrid.NrResExt{Tag: "PUSCH", Style: style}
+
+	style, _ = wb.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#8000FF"}, Pattern: 1},
+		Font:      &excelize.Font{Color: "#FFFFFF"},
+	})
+	rgd.resMap[NR_RES_PUCCH_SR] = nrgrid.NrResExt{Tag: "SR", Style: style}
+	rgd.resMap[NR_RES_PUCCH_ACK] = nrgrid.NrResExt{Tag: "ACK", Style: style}
+	rgd.resMap[NR_RES_PUCCH_CSI] = nrgrid.NrResExt{Tag: "CSI", Style: style}
+	rgd.resMap[NR_RES_PUCCH_SR_CSI] = nrgrid.NrResExt{Tag: "SR+CSI", Style: style}
+	rgd.resMap[NR_RES_PUCCH_ACK_CSI] = nrgrid.NrResExt{Tag: "ACK+CSI", Style: style}
+
+	return nil
+}
+
+// exportNrrg exports NR resource grid in format(s) given by advanced.format.
+func exportNrrg() error {
+	for _, f := range flags.advanced.format {
+		if !utils.ContainsStr([]string{"xlsx", "csv", "json", "parquet", "png", "svg"}, f) {
+			return errors.New(fmt.Sprintf("Invalid format(=%v) of NR resource grid export, which can be xlsx, csv, json, parquet, png or svg!", f))
+		}
+	}
+
+	// Note: resMap is always created with the workbook because the cell styles are part of NrResExt.
+	wb := excelize.NewFile()
+	if err := makeResMap(wb); err != nil {
+		return err
+	}
+
+	outPath := flags.advanced.outPath
+	if len(outPath) == 0 {
+		outPath = fmt.Sprintf("./logs/nrrg_export_%v", time.Now().Format("20060102_150405"))
+	}
+	if err := os.MkdirAll(filepath.Dir(outPath), 0775); err != nil {
+		return err
+	}
+
+	for _, f := range flags.advanced.format {
+		var fn string
+		var err error
+		switch f {
+		case "xlsx":
+			fn = outPath + ".xlsx"
+			err = exportNrrgXlsx(wb, fn)
+		case "csv":
+			fn = outPath + ".csv"
+			err = exportNrrgPerGridSet(outPath, ".csv", exportNrrgCsv)
+		case "json":
+			fn = outPath + ".json"
+			err = exportNrrgPerGridSet(outPath, ".json", exportNrrgJson)
+		case "parquet":
+			fn = outPath + ".parquet"
+			err = exportNrrgPerGridSet(outPath, ".parquet", exportNrrgParquet)
+		case "png", "svg":
+			fn = fmt.Sprintf("%v_*.%v", outPath, f)
+			err = exportNrrgImg(wb, outPath, f)
+		}
+		if err != nil {
+			regRed.Printf("[ERR]: %s\n", err.Error())
+			return err
+		}
+		regGreen.Printf("[INFO]: NR resource grid exported: %v\n", fn)
+	}
+
+	// report of RE counts, overheads and peak throughput
+	var fn string
+	if flags.advanced.report {
+		fn = outPath + "_report.csv"
+	}
+	if err := reportNrrg(fn); err != nil {
+		return err
+	}
+	if len(fn) > 0 {
+		regGreen.Printf("[INFO]: NR resource grid report exported: %v\n", fn)
+	}
+
+	return nil
+}
+
+// exportNrrgPerGridSet exports NR resource grid of the PCell to <outPath><ext>, and NR resource grid of each SCell or additional dedicated BWP to <outPath>_<name><ext>, e.g. <outPath>_scell1.csv or <outPath>_dlbwp2.csv.
+//  export: function which exports NR resource grid of a grid set to a file, where k is -1 for the PCell, or index of the grid set as in getNrrgGridSet
+func exportNrrgPerGridSet(outPath, ext string, export func(fn string, k int) error) error {
+	if err := export(outPath+ext, -1); err != nil {
+		return err
+	}
+
+	for k := 0; k < len(rgd.scells)+len(rgd.bwps); k++ {
+		name, _, _, _ := getNrrgGridSet(k)
+		fn := fmt.Sprintf("%v_%v%v", outPath, strings.ToLower(name), ext)
+		if err := export(fn, k); err != nil {
+			return err
+		}
+		regGreen.Printf("[INFO]: NR resource grid of %v exported: %v\n", name, fn)
+	}
+
+	return nil
+}
+
+// getNrrgGridSet returns the name, link directions, NR resource grids and [symbPerSlot, slotPerRf, scPerSymb] of each NR resource grid of grid set k, where k is -1 for the PCell, [0, len(rgd.scells)) for SCells, followed by additional dedicated BWPs.
+// Note: gridSup of the PCell has the dimensions of the supplementary carrier.
+func getNrrgGridSet(k int) (string, []string, []map[int]DataPerRf, [][]int) {
+	var dims [][]int
+	if k < 0 {
+		dirs, grids := getNrrgGrids()
+		for _, dir := range dirs {
+			if dir == "SUL" || dir == "SDL" {
+				dims = append(dims, []int{rgd.symbPerSlot, rgd.supSlotPerRf, rgd.supScPerSymb})
+			} else {
+				dims = append(dims, []int{rgd.symbPerSlot, rgd.slotPerRf, rgd.scPerSymb})
+			}
+		}
+		return "PCell", dirs, grids, dims
+	}
+
+	if k < len(rgd.scells) {
+		dirs, grids := getScellGrids(k)
+		for range grids {
+			dims = append(dims, []int{rgd.symbPerSlot, rgd.scells[k].slotPerRf, rgd.scells[k].scPerSymb})
+		}
+		return fmt.Sprintf("SCell%v", flags.ca.scellIndex[k]), dirs, grids, dims
+	}
+
+	bd := rgd.bwps[k-len(rgd.scells)]
+	name := fmt.Sprintf("%vBwp%v", map[string]string{"DL": "Dl", "UL": "Ul"}[bd.dir], bd.id)
+	return name, []string{bd.dir}, []map[int]DataPerRf{bd.grid}, [][]int{{bd.symbPerSlot, bd.slotPerRf, bd.scPerSymb}}
+}
+
+// exportNrrgXlsx exports NR resource grid to an Excel workbook with one cell per RE, where empty slots are skipped, and each SCell or additional dedicated BWP is exported to separate sheet(s) named <name>_<dir>, e.g. SCell1_DL or DlBwp2_DL.
+func exportNrrgXlsx(wb *excelize.File, fn string) error {
+	if flags.gridsetting._duplexMode == "TDD" {
+		var keys []int
+		for sfn := range rgd.gridTdd {
 			keys = append(keys, sfn)
 		}
 		sort.Ints(keys)
@@ -1340,7 +2216,7 @@ func exportNrrg() error {
 					tag := rgd.resMap[rgd.gridTdd[sfn].res[isymb*rgd.scPerSymb+isc]].Tag
 					style := rgd.resMap[rgd.gridTdd[sfn].res[isymb*rgd.scPerSymb+isc]].Style
 					axis := fmt.Sprintf("%v%v", int2Col(col), row+1+isc)
-					wb.SetCellValue(shn, axis, fmt.Sprintf("%v", tag))
+					wb.SetCellValue(shn, axis, getReLabel(tag, rgd.gridTdd[sfn], isymb*rgd.scPerSymb+isc))
 					wb.SetCellStyle(shn, axis, axis, style)
 				}
 			}
@@ -1387,7 +2263,7 @@ func exportNrrg() error {
 					tag := rgd.resMap[rgd.gridFddDl[sfn].res[isymb*rgd.scPerSymb+isc]].Tag
 					style := rgd.resMap[rgd.gridFddDl[sfn].res[isymb*rgd.scPerSymb+isc]].Style
 					axis := fmt.Sprintf("%v%v", int2Col(col), row+1+isc)
-					wb.SetCellValue(shn, axis, fmt.Sprintf("%v", tag))
+					wb.SetCellValue(shn, axis, getReLabel(tag, rgd.gridFddDl[sfn], isymb*rgd.scPerSymb+isc))
 					wb.SetCellStyle(shn, axis, axis, style)
 				}
 			}
@@ -1395,1380 +2271,10244 @@ func exportNrrg() error {
 
 		wb.SetPanes(shn, `{"freeze":true,"split":false,"x_split":1,"y_split":1}`)
 		wb.AutoFilter(shn, "A1", fmt.Sprintf("%v%v", int2Col(col), rgd.scPerSymb+1), "")
-	}
-
-	if err := wb.SaveAs(fmt.Sprintf("./logs/nrrg_export_%v.xlsx", time.Now().Format("20060102_150405"))); err != nil {
-		regRed.Printf("[ERR]: %s\n", err.Error())
-		return err
-	}
 
-	return nil
-}
+		// FDD UL
+		keys = nil
+		for sfn := range rgd.gridFddUl {
+			keys = append(keys, sfn)
+		}
+		sort.Ints(keys)
 
-func initNrrgData() error {
-	// constants
-	rgd.subfPerRf = 10
-	rgd.slotPerSubf = int(math.Exp2(float64(nrgrid.Scs2Mu[flags.gridsetting.scs])))
-	rgd.slotPerRf = rgd.slotPerSubf * rgd.subfPerRf
-	rgd.symbPerSlot = 14
-	rgd.symbPerSubf = rgd.symbPerSlot * rgd.slotPerSubf
-	rgd.symbPerRf = rgd.symbPerSlot * rgd.slotPerRf
-	rgd.scPerRb = 12
-	rgd.scPerSymb = rgd.scPerRb * flags.gridsetting._carrierNumRbs
-	rgd.scPerSlot = rgd.scPerSymb * rgd.symbPerSlot
-	rgd.scPerSubf = rgd.scPerSlot * rgd.slotPerSubf
-	rgd.scPerRf = rgd.scPerSlot * rgd.slotPerRf
+		shn = "FDD_UL"
+		if wb.GetSheetName(wb.GetActiveSheetIndex()) == "Sheet1" {
+			wb.SetSheetName("Sheet1", shn)
+		} else {
+			wb.NewSheet(shn)
+		}
 
-	// resource grid
-	if flags.gridsetting._duplexMode == "TDD" {
-		if len(flags.tdduldl.patPeriod) == 1 || len(flags.tdduldl.patPeriod) == 2 {
-			period := 0.0
-			for _, p := range flags.tdduldl.patPeriod {
-				v, _ := strconv.ParseFloat(p[:len(p)-2], 64)
-				period += v
+		row = 1
+		col = 1
+		for isc := 0; isc < rgd.scPerSymb; isc++ {
+			// write vertical header
+			if isc == 0 {
+				wb.SetCellValue(shn, fmt.Sprintf("%v%v", int2Col(col), row), "k/l")
 			}
-			if math.Mod(20, period) == 0 {
-				periodsPer20ms := int(20 / period)
-				var patPerPeriod []string
-				for i := range flags.tdduldl.patPeriod {
-					for j := 0; j < flags.tdduldl.patNumDlSlots[i]*rgd.symbPerSlot+flags.tdduldl.patNumDlSymbs[i]; j++ {
-						patPerPeriod = append(patPerPeriod, "D")
-					}
-					for j := 0; j < rgd.symbPerSlot-flags.tdduldl.patNumDlSymbs[i]-flags.tdduldl.patNumUlSymbs[i]; j++ {
-						patPerPeriod = append(patPerPeriod, "GB")
-					}
-					for j := 0; j < flags.tdduldl.patNumUlSymbs[i]+flags.tdduldl.patNumUlSlots[i]*rgd.symbPerSlot; j++ {
-						patPerPeriod = append(patPerPeriod, "U")
-					}
+			wb.SetCellValue(shn, fmt.Sprintf("%v%v", int2Col(col), row+1+isc), fmt.Sprintf("%v-%v", isc/rgd.scPerRb, isc%rgd.scPerRb))
+		}
+		for _, sfn := range keys {
+			for isymb := 0; isymb < rgd.symbPerRf; isymb++ {
+				// skip empty slot
+				if rgd.gridFddUl[sfn].tags[isymb/rgd.symbPerSlot] == nil || rgd.gridFddUl[sfn].tags[isymb/rgd.symbPerSlot].Cardinality() == 0 {
+					continue
+				} else {
+					col++
 				}
 
-				var patPer20ms []string
-				for i := 0; i < periodsPer20ms; i++ {
-					patPer20ms = append(patPer20ms, patPerPeriod...)
-				}
+				// write horizontal header
+				wb.SetCellValue(shn, fmt.Sprintf("%v%v", int2Col(col), row), fmt.Sprintf("%v-%v-%v", sfn, isymb/rgd.symbPerSlot, isymb%rgd.symbPerSlot))
 
-				rgd.tddPatEvenRf = patPer20ms[:rgd.symbPerRf]
-				rgd.tddPatOddRf = patPer20ms[rgd.symbPerRf:]
-				fmt.Printf("tddPatEvenRf=%v\n", rgd.tddPatEvenRf)
-				fmt.Printf("tddPatOddRf =%v\n", rgd.tddPatOddRf)
-			} else {
-				return errors.New(fmt.Sprintf("Invalid TDD-UL-DL-Config periodicity(=%v), which should divide 20m.", flags.tdduldl.patPeriod))
+				for isc := 0; isc < rgd.scPerSymb; isc++ {
+					tag := rgd.resMap[rgd.gridFddUl[sfn].res[isymb*rgd.scPerSymb+isc]].Tag
+					style := rgd.resMap[rgd.gridFddUl[sfn].res[isymb*rgd.scPerSymb+isc]].Style
+					axis := fmt.Sprintf("%v%v", int2Col(col), row+1+isc)
+					wb.SetCellValue(shn, axis, getReLabel(tag, rgd.gridFddUl[sfn], isymb*rgd.scPerSymb+isc))
+					wb.SetCellStyle(shn, axis, axis, style)
+				}
 			}
-		} else {
-			return errors.New(fmt.Sprintf("Invalid TDD-UL-DL-Config: tdduldl.patPeriod=%v.", flags.tdduldl.patPeriod))
 		}
 
-		rgd.gridTdd = make(map[int]DataPerRf)
-	} else {
-		rgd.gridFddUl = make(map[int]DataPerRf)
-		rgd.gridFddDl = make(map[int]DataPerRf)
+		wb.SetPanes(shn, `{"freeze":true,"split":false,"x_split":1,"y_split":1}`)
+		wb.AutoFilter(shn, "A1", fmt.Sprintf("%v%v", int2Col(col), rgd.scPerSymb+1), "")
 	}
 
-	rgd.trSsb = make(map[int]bool)
-	rgd.ssbSymbs = make(map[int][]int)
+	// supplementary carrier(SUL or SDL), which has its own subcarrierSpacing and carrierBandwidth
+	if len(flags.gridsetting.supBand) > 0 {
+		var keys []int
+		for sfn := range rgd.gridSup {
+			keys = append(keys, sfn)
+		}
+		sort.Ints(keys)
 
-	// first symbols of SSB
-	var s1, s3 []int
-	var s2 int
-	if flags.gridsetting._ssbPattern == "Case A" && flags.gridsetting._ssbScs == "15KHz" {
-		s1 = []int{2, 8}
-		s2 = 14
-		if !flags.gridsetting._unlicensed {
-			if flags.gridsetting._maxDlFreq <= 3000 {
-				s3 = []int{0, 1}
-			} else {
-				s3 = []int{0, 1, 2, 3}
+		shn := flags.gridsetting._supDuplexMode
+		wb.NewSheet(shn)
+
+		row := 1
+		col := 1
+		for isc := 0; isc < rgd.supScPerSymb; isc++ {
+			// write vertical header
+			if isc == 0 {
+				wb.SetCellValue(shn, fmt.Sprintf("%v%v", int2Col(col), row), "k/l")
 			}
-		} else {
-			s3 = []int{0, 1, 2, 3, 4}
-		}
-	} else if flags.gridsetting._ssbPattern == "Case B" && flags.gridsetting._ssbScs == "30KHz" {
-		s1 = []int{4, 8, 16, 20}
-		s2 = 28
-		if flags.gridsetting._maxDlFreq <= 3000 {
-			s3 = []int{0}
-		} else {
-			s3 = []int{0, 1}
+			wb.SetCellValue(shn, fmt.Sprintf("%v%v", int2Col(col), row+1+isc), fmt.Sprintf("%v-%v", isc/rgd.scPerRb, isc%rgd.scPerRb))
 		}
-	} else if flags.gridsetting._ssbPattern == "Case C" && flags.gridsetting._ssbScs == "30KHz" {
-		s1 = []int{2, 8}
-		s2 = 14
-		if !flags.gridsetting._unlicensed {
-			if flags.gridsetting._duplexMode == "FDD" {
-				if flags.gridsetting._maxDlFreq <= 3000 {
-					s3 = []int{0, 1}
-				} else {
-					s3 = []int{0, 1, 2, 3}
-				}
-			} else if flags.gridsetting._duplexMode == "TDD" {
-				if flags.gridsetting._maxDlFreq < 1880 {
-					s3 = []int{0, 1}
+		for _, sfn := range keys {
+			for isymb := 0; isymb < rgd.supSlotPerRf*rgd.symbPerSlot; isymb++ {
+				// skip empty slot
+				if rgd.gridSup[sfn].tags[isymb/rgd.symbPerSlot] == nil || rgd.gridSup[sfn].tags[isymb/rgd.symbPerSlot].Cardinality() == 0 {
+					continue
 				} else {
-					s3 = []int{0, 1, 2, 3}
+					col++
 				}
-			}
-		} else {
-			s3 = []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
-		}
-	} else if flags.gridsetting._ssbPattern == "Case D" && flags.gridsetting._ssbScs == "120KHz" {
-		s1 = []int{4, 8, 16, 20}
-		s2 = 28
-		s3 = []int{0, 1, 2, 3, 5, 6, 7, 8, 10, 11, 12, 13, 15, 16, 17, 18}
-	} else if flags.gridsetting._ssbPattern == "Case E" && flags.gridsetting._ssbScs == "240KHz" {
-		s1 = []int{8, 12, 16, 20, 32, 36, 40, 44}
-		s2 = 56
-		s3 = []int{0, 1, 2, 3, 5, 6, 7, 8}
-	} else if ((flags.gridsetting._ssbPattern == "Case F" && flags.gridsetting._ssbScs == "480KHz") || (flags.gridsetting._ssbPattern == "Case G" && flags.gridsetting._ssbScs == "960KHz")) && flags.gridsetting._freqRange == "FR2-2" {
-		s1 = []int{2, 9}
-		s2 = 14
-		s3 = utils.PyRange(0, 32, 1)
-	}
-
-	for _, i := range s1 {
-		for _, j := range s3 {
-			rgd.ssbFirstSymbs = append(rgd.ssbFirstSymbs, i+s2*j)
-		}
-	}
-	sort.Ints(rgd.ssbFirstSymbs)
-	fmt.Printf("ssbFirstSymbs: %v\n", rgd.ssbFirstSymbs)
-
-	// first subcarrier of SSB/CORESET0
-	rmsiScs, _ := strconv.Atoi(flags.gridsetting._mibCommonScs[:len(flags.gridsetting._mibCommonScs)-3])
-	rgd.ssbSc0Rb0 = (flags.gridsetting._nCrbSsb*12*int(flags.gridsetting._nCrbSsbScs)+flags.gridsetting._kSsb*int(flags.gridsetting._kSsbScs))/rmsiScs - flags.gridsetting._offsetToCarrier*12
-	rgd.coreset0Sc0Rb0 = rgd.ssbSc0Rb0 - flags.gridsetting._coreset0Offset*12
-	fmt.Printf("offsetToCarrier=%v, nCrbSsb=%v(SCS=%.0fKHz), kSsb=%v(SCS=%.0fKHz) -> ssbSc0Rb0=%v\n", flags.gridsetting._offsetToCarrier, flags.gridsetting._nCrbSsb, flags.gridsetting._nCrbSsbScs, flags.gridsetting._kSsb, flags.gridsetting._kSsbScs, rgd.ssbSc0Rb0)
-	fmt.Printf("coreset0Offset=%v -> coreset0Sc0Rb0=%v\n", flags.gridsetting._coreset0Offset, rgd.coreset0Sc0Rb0)
-
-	// CORESET0
-	rgd.coreset0NumCces = flags.gridsetting._coreset0NumSymbs * flags.gridsetting._coreset0NumRbs / 6
-	if flags.gridsetting._css0AggLevel > rgd.coreset0NumCces {
-		return errors.New(fmt.Sprintf("Invalid configurations of CSS0/CORESET0: aggregation level=%v while total number of CCEs=%v!", flags.gridsetting._css0AggLevel, rgd.coreset0NumCces))
-	}
 
-	var err error
-	rgd.coreset0RegBundles, rgd.coreset0Cces, err = coresetCceRegMapping("CORESET0", flags.gridsetting._coreset0NumRbs, flags.gridsetting._coreset0NumSymbs, true, 6, 2, flags.gridsetting.pci)
-	if err != nil {
-		return err
-	}
-	fmt.Printf("CORESET0 CCE-to-REG mapping:\nregBundles=%v\ncces      =%v\n", rgd.coreset0RegBundles, rgd.coreset0Cces)
+				// write horizontal header
+				wb.SetCellValue(shn, fmt.Sprintf("%v%v", int2Col(col), row), fmt.Sprintf("%v-%v-%v", sfn, isymb/rgd.symbPerSlot, isymb%rgd.symbPerSlot))
 
-	rgd.css0TdOccasions = make(map[string][]nrgrid.Css0OccasionTd)
-	rgd.occCss0 = make(map[int]bool)
-	rgd.trPdcchSib1 = make(map[int]bool)
-	rgd.css0PdcchCandidates = make(map[string][]nrgrid.Css0PdcchCandidate)
+				for isc := 0; isc < rgd.supScPerSymb; isc++ {
+					tag := rgd.resMap[rgd.gridSup[sfn].res[isymb*rgd.supScPerSymb+isc]].Tag
+					style := rgd.resMap[rgd.gridSup[sfn].res[isymb*rgd.supScPerSymb+isc]].Style
+					axis := fmt.Sprintf("%v%v", int2Col(col), row+1+isc)
+					wb.SetCellValue(shn, axis, getReLabel(tag, rgd.gridSup[sfn], isymb*rgd.supScPerSymb+isc))
+					wb.SetCellStyle(shn, axis, axis, style)
+				}
+			}
+		}
 
-	// CORESET1
-	rgd.coreset1NumCces = flags.searchspace._coreset1Duration * flags.searchspace.coreset1NumRbs / 6
-	L, _ := strconv.Atoi(flags.searchspace.coreset1RegBundleSize[1:])
-	R, _ := strconv.Atoi(flags.searchspace.coreset1InterleaverSize[1:])
-	rgd.coreset1RegBundles, rgd.coreset1Cces, err = coresetCceRegMapping("CORESET1", flags.searchspace.coreset1NumRbs, flags.searchspace._coreset1Duration, flags.searchspace.coreset1CceRegMappingType == "interleaved", L, R, flags.searchspace._coreset1ShiftIndex)
-	if err != nil {
-		return err
+		wb.SetPanes(shn, `{"freeze":true,"split":false,"x_split":1,"y_split":1}`)
+		wb.AutoFilter(shn, "A1", fmt.Sprintf("%v%v", int2Col(col), rgd.supScPerSymb+1), "")
 	}
-	fmt.Printf("CORESET1 CCE-to-REG mapping:\nregBundles=%v\ncces      =%v\n", rgd.coreset1RegBundles, rgd.coreset1Cces)
-
-	var vrbBundles, prbBundles []int
-	// interleaved VRB-to-PRB mapping for DCI 1_0 with SI-RNTI in Type0 CSS
-	L, _ = strconv.Atoi(flags.dldci.fdBundleSize[DCI_10_SIB1][1:])
-	vrbBundles, prbBundles, rgd.dci10Sib1Prbs = pdschVrbPrbMapping(flags.gridsetting._coreset0NumRbs, 0, 0, L)
-	fmt.Printf("DCI_10_SIB1 VRB-to-PRB mapping:\nvrbBundles=%v\nprbBundles=%v\nprbs=%v\n", vrbBundles, prbBundles, rgd.dci10Sib1Prbs)
 
-	// interleaved VRB-to-PRB mapping for DCI 1_0 other than Type0 CSS
-	L, _ = strconv.Atoi(flags.dldci.fdBundleSize[DCI_10_MSG2][1:])
-	vrbBundles, prbBundles, rgd.dci10Msg2Prbs = pdschVrbPrbMapping(flags.gridsetting._coreset0NumRbs, 0, 0, L)
-	fmt.Printf("DCI_10_MSG2 VRB-to-PRB mapping:\nvrbBundles=%v\nprbBundles=%v\nprbs=%v\n", vrbBundles, prbBundles, rgd.dci10Msg2Prbs)
+	// SCells of carrier aggregation and additional dedicated BWPs
+	for k := 0; k < len(rgd.scells)+len(rgd.bwps); k++ {
+		name, dirs, grids, dims := getNrrgGridSet(k)
+		for j, grid := range grids {
+			symbPerSlot, scPerSymb := dims[j][0], dims[j][2]
+			symbPerRf := symbPerSlot * dims[j][1]
+			shn := fmt.Sprintf("%v_%v", name, dirs[j])
+			wb.NewSheet(shn)
 
-	L, _ = strconv.Atoi(flags.dldci.fdBundleSize[DCI_10_MSG4][1:])
-	vrbBundles, prbBundles, rgd.dci10Msg4Prbs = pdschVrbPrbMapping(flags.gridsetting._coreset0NumRbs, 0, 0, L)
-	fmt.Printf("DCI_10_MSG4 VRB-to-PRB mapping:\nvrbBundles=%v\nprbBundles=%v\nprbs=%v\n", vrbBundles, prbBundles, rgd.dci10Msg4Prbs)
+			row := 1
+			col := 1
+			for isc := 0; isc < scPerSymb; isc++ {
+				// write vertical header
+				if isc == 0 {
+					wb.SetCellValue(shn, fmt.Sprintf("%v%v", int2Col(col), row), "k/l")
+				}
+				wb.SetCellValue(shn, fmt.Sprintf("%v%v", int2Col(col), row+1+isc), fmt.Sprintf("%v-%v", isc/rgd.scPerRb, isc%rgd.scPerRb))
+			}
+			for _, sfn := range getNrrgSfns(grid) {
+				for isymb := 0; isymb < symbPerRf; isymb++ {
+					// skip empty slot
+					if grid[sfn].tags[isymb/symbPerSlot] == nil || grid[sfn].tags[isymb/symbPerSlot].Cardinality() == 0 {
+						continue
+					} else {
+						col++
+					}
 
-	// interleaved VRB-to-PRB mapping for DCI 1_1
-	L, _ = strconv.Atoi(flags.dldci.fdBundleSize[DCI_11_PDSCH][1:])
-	vrbBundles, prbBundles, rgd.dci11Prbs = pdschVrbPrbMapping(flags.bwp._bwpNumRbs[DED_DL_BWP], flags.bwp._bwpStartRb[DED_DL_BWP], 0, L)
-	fmt.Printf("DCI_11_PDSCSH VRB-to-PRB mapping:\nvrbBundles=%v\nprbBundles=%v\nprbs=%v\n", vrbBundles, prbBundles, rgd.dci11Prbs)
+					// write horizontal header
+					wb.SetCellValue(shn, fmt.Sprintf("%v%v", int2Col(col), row), fmt.Sprintf("%v-%v-%v", sfn, isymb/symbPerSlot, isymb%symbPerSlot))
 
-	//TODO RACH duration of long PRACH
-	/*
-		if self.nrRachCfgFormat in ('0', '1', '2', '3'):
-			self.nrRachCfgDuration = {'0':14, '1':42, '2':49, '3':14}[self.nrRachCfgFormat]
-	*/
+					for isc := 0; isc < scPerSymb; isc++ {
+						tag := rgd.resMap[grid[sfn].res[isymb*scPerSymb+isc]].Tag
+						style := rgd.resMap[grid[sfn].res[isymb*scPerSymb+isc]].Style
+						axis := fmt.Sprintf("%v%v", int2Col(col), row+1+isc)
+						wb.SetCellValue(shn, axis, getReLabel(tag, grid[sfn], isymb*scPerSymb+isc))
+						wb.SetCellStyle(shn, axis, axis, style)
+					}
+				}
+			}
 
-	//TODO SSB-PRACH association
-	/*
-		self.numTxSsb = len([c for c in self.ssbSet if c == '1'])
-		self.minNumValidPrachOccasionPerAssociationPeriod = math.ceil(self.numTxSsb / self.nrRachSsbPerRachOccasionM8 * 8)
-	*/
+			wb.SetPanes(shn, `{"freeze":true,"split":false,"x_split":1,"y_split":1}`)
+			wb.AutoFilter(shn, "A1", fmt.Sprintf("%v%v", int2Col(col), scPerSymb+1), "")
+		}
+	}
 
-	rgd.msg4Recved = false
-	rgd.resMap = make(map[int]nrgrid.NrResExt)
+	if err := wb.SaveAs(fn); err != nil {
+		return err
+	}
 
 	return nil
 }
 
-// CCE-to-REG mapping of CORESET
-func coresetCceRegMapping(coreset string, numRbs int, numSymbs int, interlaved bool, L int, R int, nShift int) ([]nrgrid.RegInfo, []int, error) {
-	if !utils.ContainsStr([]string{"CORESET0", "CORESET1"}, coreset) {
-		return nil, nil, errors.New(fmt.Sprintf("coresetCceRegMapping: Only CORESET0 and CORESET1 are supported."))
+// getReLabel returns the label of RE in Excel export, which is the tag of NR resource, followed by the SSB index if the RE is transmitted with a specific SSB beam, e.g. PBCH#3.
+func getReLabel(tag string, grid DataPerRf, ire int) string {
+	if beam, exist := grid.beams[ire]; exist {
+		return fmt.Sprintf("%v#%v", tag, beam)
 	}
 
-	if !interlaved && L != 6 {
-		return nil, nil, errors.New(fmt.Sprintf("coresetCceRegMapping: L should equal 6 for non-interleaved mapping."))
-	}
+	return tag
+}
 
-	if interlaved {
-		if (numSymbs == 1 && !utils.ContainsInt([]int{2, 6}, L)) || (utils.ContainsInt([]int{2, 3}, numSymbs) && !utils.ContainsInt([]int{numSymbs, 6}, L)) {
-			return nil, nil, errors.New(fmt.Sprintf("coresetCceRegMapping: For interleaved CCE-to-REG mapping, L = 2/6 for N_CORESTE_symb=1 and L=N_CORESET_symb/6 for N_CORESET_symb=2/3."))
-		}
-		if (numRbs*numSymbs)%(L*R) != 0 {
-			return nil, nil, errors.New(fmt.Sprintf("coresetCceRegMapping: N_CORESET_REG should be multiples of L*R."))
-		}
+// getNrrgGrids returns the link directions(TDD, DL, UL, SUL or SDL) and the corresponding NR resource grids.
+func getNrrgGrids() ([]string, []map[int]DataPerRf) {
+	dirs := []string{"DL", "UL"}
+	grids := []map[int]DataPerRf{rgd.gridFddDl, rgd.gridFddUl}
+	if flags.gridsetting._duplexMode == "TDD" {
+		dirs = []string{"TDD"}
+		grids = []map[int]DataPerRf{rgd.gridTdd}
 	}
 
-	// indexing REGs
-	// refer to 3GPP 38.211 vh40
-	// 7.3.2.2	Control-resource set (CORESET)
-	// Resource-element groups within a control-resource set are numbered in increasing order in a time-first manner, starting with 0 for the first OFDM symbol and the lowest-numbered resource block in the control resource set.
-	numRegs := numRbs * numSymbs
-	regBundles := make([]nrgrid.RegInfo, numRegs)
-	count := 0
-	for i := 0; i < numRbs; i++ {
-		for j := 0; j < numSymbs; j++ {
-			regBundles[i*numSymbs+j] = nrgrid.RegInfo{Id: count, Isymb: j, Irb: i}
-			count++
-		}
+	if len(flags.gridsetting.supBand) > 0 {
+		dirs = append(dirs, flags.gridsetting._supDuplexMode)
+		grids = append(grids, rgd.gridSup)
 	}
 
-	// indexing REG bundles
-	numRegBundles := numRegs / L
-	for i := 0; i < numRbs; i++ {
-		for j := 0; j < numSymbs; j++ {
-			regBundles[i*numSymbs+j].Id /= L
-		}
-	}
+	return dirs, grids
+}
 
-	// indexing CCEs
-	numCces := numRegs / 6
-	numRegBundlesPerCce := 6 / L
-	cces := make([]int, numRegs)
-	for i := 0; i < numCces; i++ {
-		var regBundlesSet []int
-		for j := 0; j < numRegBundlesPerCce; j++ {
-			regBundlesSet = append(regBundlesSet, numRegBundlesPerCce*i+j)
-		}
+// getNrrgSfns returns the sorted radio frames of NR resource grid.
+func getNrrgSfns(grid map[int]DataPerRf) []int {
+	var keys []int
+	for sfn := range grid {
+		keys = append(keys, sfn)
+	}
+	sort.Ints(keys)
 
-		if interlaved {
-			C := numRegs / (L * R)
-			for j := range regBundlesSet {
-				x := regBundlesSet[j]
-				c := x / R
-				r := x % R
-				regBundlesSet[j] = (r*C + c + nShift) % numRegBundles
-			}
-		}
+	return keys
+}
 
-		for j := 0; j < numRbs; j++ {
-			for k := 0; k < numSymbs; k++ {
-				if utils.ContainsInt(regBundlesSet, regBundles[j*numSymbs+k].Id) {
-					cces[j*numSymbs+k] = i
+// nrrgColumns are the columns of long-form NR resource grid export.
+var nrrgColumns = []string{"dir", "sfn", "slot", "symbol", "rb", "sc", "tag", "beam"}
+
+// walkNrrg calls f for each RE of NR resource grid in order of link direction, SFN, slot, symbol, RB and subcarrier, where:
+//  dir: link direction, which can be TDD, DL, UL, SUL or SDL
+//  rb: common RB index of the carrier
+//  sc: subcarrier index within the RB
+//  beam: SSB index of the beam, which is -1 if the RE is not transmitted with a specific SSB beam
+// The k is -1 for the PCell, or index of the grid set of SCell or additional dedicated BWP as in getNrrgGridSet.
+func walkNrrg(k int, f func(dir string, sfn, slot, symb, rb, sc int, tag string, beam int) error) error {
+	_, dirs, grids, dims := getNrrgGridSet(k)
+	for i, grid := range grids {
+		symbPerSlot, scPerSymb := dims[i][0], dims[i][2]
+		symbPerRf := symbPerSlot * dims[i][1]
+		for _, sfn := range getNrrgSfns(grid) {
+			for isymb := 0; isymb < symbPerRf; isymb++ {
+				for isc := 0; isc < scPerSymb; isc++ {
+					tag := rgd.resMap[grid[sfn].res[isymb*scPerSymb+isc]].Tag
+					beam, exist := grid[sfn].beams[isymb*scPerSymb+isc]
+					if !exist {
+						beam = -1
+					}
+					if err := f(dirs[i], sfn, isymb/symbPerSlot, isymb%symbPerSlot, isc/rgd.scPerRb, isc%rgd.scPerRb, tag, beam); err != nil {
+						return err
+					}
 				}
 			}
 		}
 	}
 
-	return regBundles, cces, nil
+	return nil
 }
 
-// DL VRB-to-PRB mapping
-//  - For PDSCH transmissions scheduled with DCI format 1_0 with the CRC scrambled by SI-RNTI in Type0-PDCCH common search space in CORESET 0: bwpStart=0 and coresetStart=0
-//  - For PDSCH transmissions scheduled with DCI format 1_0 in any common search space in bandwidth part i with starting position N_start_BWP,i, other than Type0-PDCCH common search space in CORESET 0
-//  - For all other PDSCH transmissions: coresetStart=0
-func pdschVrbPrbMapping(bwpSize int, bwpStart int, coresetStart int, L int) ([]int, []int, []int) {
-	numBundles := utils.CeilInt(float64(bwpSize+(bwpStart+coresetStart)%L) / float64(L))
-	var rbBundleSize []int
-	for i := 0; i < numBundles; i++ {
-		if i == 0 {
-			rbBundleSize = append(rbBundleSize, L-(bwpStart+coresetStart)%L)
-		} else if i == numBundles-1 {
-			if (bwpSize+bwpStart+coresetStart)%L > 0 {
-				rbBundleSize = append(rbBundleSize, (bwpSize+bwpStart+coresetStart)%L)
-			} else {
-				rbBundleSize = append(rbBundleSize, L)
-			}
-		} else {
-			rbBundleSize = append(rbBundleSize, L)
-		}
+// fmtBeam formats the SSB index of the beam in long-form export, which is null if the RE is not transmitted with a specific SSB beam.
+func fmtBeam(beam int, null string) string {
+	if beam < 0 {
+		return null
 	}
 
-	vrbBundles := utils.PyRange(0, numBundles, 1)
-	var prbBundles []int
-	for j := 0; j < numBundles; j++ {
-		if j == numBundles-1 {
-			prbBundles = append(prbBundles, j)
-		} else {
-			R := 2
-			C := utils.FloorInt(float64(numBundles) / float64(R))
-			c := j / R
-			r := j % R
-			prbBundles = append(prbBundles, r*C+c)
-		}
+	return strconv.Itoa(beam)
+}
+
+// exportNrrgCsv exports NR resource grid of the PCell(k=-1), SCell or additional dedicated BWP to a long-form CSV file with one row per RE.
+func exportNrrgCsv(fn string, k int) error {
+	fout, err := os.OpenFile(fn, os.O_TRUNC|os.O_WRONLY|os.O_CREATE, 0664)
+	if err != nil {
+		return err
 	}
+	defer fout.Close()
 
-	// indexing VRBs and PRBs
-	var prbs []int
-	for j := 0; j < numBundles; j++ {
-		for k := 0; k < rbBundleSize[j]; k++ {
-			prbs = append(prbs, utils.SumInt(rbBundleSize[:prbBundles[j]])+k)
-		}
+	w := csv.NewWriter(fout)
+	if err := w.Write(nrrgColumns); err != nil {
+		return err
+	}
+	err = walkNrrg(k, func(dir string, sfn, slot, symb, rb, sc int, tag string, beam int) error {
+		return w.Write([]string{dir, strconv.Itoa(sfn), strconv.Itoa(slot), strconv.Itoa(symb), strconv.Itoa(rb), strconv.Itoa(sc), tag, fmtBeam(beam, "")})
+	})
+	if err != nil {
+		return err
 	}
 
-	return vrbBundles, prbBundles, prbs
+	w.Flush()
+	return w.Error()
 }
 
-func alwaysOnTr(sfn, slot int) error {
-	// init gridTdd or gridFddDl/gridFddUl if necessary
-	if flags.gridsetting._duplexMode == "TDD" {
-		initTddGrid(sfn)
-	} else {
-		initFddGrid(sfn)
+// nrrgRe is an RE of NR resource grid in long-form JSON export, where beam is null if the RE is not transmitted with a specific SSB beam.
+type nrrgRe struct {
+	Dir    string `json:"dir"`
+	Sfn    int    `json:"sfn"`
+	Slot   int    `json:"slot"`
+	Symbol int    `json:"symbol"`
+	Rb     int    `json:"rb"`
+	Sc     int    `json:"sc"`
+	Tag    string `json:"tag"`
+	Beam   *int   `json:"beam"`
+}
+
+// exportNrrgJson exports NR resource grid of the PCell(k=-1), SCell or additional dedicated BWP to a long-form JSON file, which is an array of objects with one object per RE.
+// Note: the REs are streamed to the file one by one, so the grid is not buffered in memory.
+func exportNrrgJson(fn string, k int) error {
+	fout, err := os.OpenFile(fn, os.O_TRUNC|os.O_WRONLY|os.O_CREATE, 0664)
+	if err != nil {
+		return err
 	}
+	defer fout.Close()
 
-	if err := aotSsb(sfn); err != nil {
+	w := bufio.NewWriter(fout)
+	if _, err := w.WriteString("["); err != nil {
+		return err
+	}
+	sep := "\n"
+	err = walkNrrg(k, func(dir string, sfn, slot, symb, rb, sc int, tag string, beam int) error {
+		re := nrrgRe{Dir: dir, Sfn: sfn, Slot: slot, Symbol: symb, Rb: rb, Sc: sc, Tag: tag}
+		if beam >= 0 {
+			re.Beam = &beam
+		}
+		b, err := json.Marshal(re)
+		if err != nil {
+			return err
+		}
+		if _, err := w.WriteString(sep); err != nil {
+			return err
+		}
+		sep = ",\n"
+		_, err = w.Write(b)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := w.WriteString("\n]\n"); err != nil {
 		return err
 	}
 
-	if err := aotPdcchSib1(sfn); err != nil {
+	return w.Flush()
+}
+
+// nrrgParquetRe is an RE of NR resource grid in Parquet export, which has the same columns as nrrgColumns, where beam is null if the RE is not transmitted with a specific SSB beam.
+type nrrgParquetRe struct {
+	Dir    string `parquet:"name=dir, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Sfn    int32  `parquet:"name=sfn, type=INT32"`
+	Slot   int32  `parquet:"name=slot, type=INT32"`
+	Symbol int32  `parquet:"name=symbol, type=INT32"`
+	Rb     int32  `parquet:"name=rb, type=INT32"`
+	Sc     int32  `parquet:"name=sc, type=INT32"`
+	Tag    string `parquet:"name=tag, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Beam   *int32 `parquet:"name=beam, type=INT32, repetitiontype=OPTIONAL"`
+}
+
+// exportNrrgParquet exports NR resource grid of the PCell(k=-1), SCell or additional dedicated BWP to a Parquet file with snappy compression, e.g. pandas.read_parquet(fn).
+// Note: the REs are written to row groups of the Parquet writer, so the grid is not buffered in memory as a whole.
+func exportNrrgParquet(fn string, k int) error {
+	fout, err := local.NewLocalFileWriter(fn)
+	if err != nil {
 		return err
 	}
+	defer fout.Close()
 
-	if err := aotSib1(sfn); err != nil {
+	pw, err := writer.NewParquetWriter(fout, new(nrrgParquetRe), 1)
+	if err != nil {
 		return err
 	}
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
 
-	if rgd.msg4Recved {
-		if err := aotCsi(sfn, slot); err != nil {
-			return err
-		}
-		if err := aotSrs(sfn, slot); err != nil {
-			return err
+	err = walkNrrg(k, func(dir string, sfn, slot, symb, rb, sc int, tag string, beam int) error {
+		re := nrrgParquetRe{Dir: dir, Sfn: int32(sfn), Slot: int32(slot), Symbol: int32(symb), Rb: int32(rb), Sc: int32(sc), Tag: tag}
+		if beam >= 0 {
+			b := int32(beam)
+			re.Beam = &b
 		}
+		return pw.Write(re)
+	})
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return pw.WriteStop()
 }
 
-func initTddGrid(sfn int) {
-	_, exist := rgd.gridTdd[sfn]
-	if !exist {
-		rgd.gridTdd[sfn] = DataPerRf{res: make([]int, rgd.scPerRf), tags: make([]mapset.Set, rgd.slotPerRf)}
-		for i := 0; i < rgd.scPerRf; i++ {
-			if sfn%2 == 0 {
-				rgd.gridTdd[sfn].res[i] = map[string]int{"D": NR_RES_D, "U": NR_RES_U, "GB": NR_RES_GB}[rgd.tddPatEvenRf[i/rgd.scPerSymb]]
-			} else {
-				rgd.gridTdd[sfn].res[i] = map[string]int{"D": NR_RES_D, "U": NR_RES_U, "GB": NR_RES_GB}[rgd.tddPatOddRf[i/rgd.scPerSymb]]
+// gridPlotter draws REs of NR resource grid as filled rectangles, where X is the symbol and Y is the subcarrier within the image.
+type gridPlotter struct {
+	res    [][]int // NR resources per symbol, then per subcarrier
+	colors map[int]imgcolor.Color
+}
+
+func (g gridPlotter) Plot(c draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&c)
+	for x, col := range g.res {
+		// merge consecutive subcarriers of the same NR resource, which reduces size of SVG significantly
+		y0 := 0
+		for y := 1; y <= len(col); y++ {
+			if y < len(col) && col[y] == col[y0] {
+				continue
 			}
+
+			pts := []vg.Point{{X: trX(float64(x)), Y: trY(float64(y0))}, {X: trX(float64(x + 1)), Y: trY(float64(y0))}, {X: trX(float64(x + 1)), Y: trY(float64(y))}, {X: trX(float64(x)), Y: trY(float64(y))}}
+			c.FillPolygon(g.colors[col[y0]], c.ClipPolygonXY(pts))
+			y0 = y
 		}
+	}
+}
 
-		rgd.trSsb[sfn] = false
-		rgd.occCss0[sfn] = false
-		rgd.trPdcchSib1[sfn] = false
-	}
+func (g gridPlotter) DataRange() (xmin, xmax, ymin, ymax float64) {
+	return 0, float64(len(g.res)), 0, float64(len(g.res[0]))
 }
 
-func initFddGrid(sfn int) {
-	_, exist := rgd.gridFddDl[sfn]
-	if !exist {
-		rgd.gridFddDl[sfn] = DataPerRf{res: make([]int, rgd.scPerRf), tags: make([]mapset.Set, rgd.slotPerRf)}
-		rgd.gridFddUl[sfn] = DataPerRf{res: make([]int, rgd.scPerRf), tags: make([]mapset.Set, rgd.slotPerRf)}
-		for i := 0; i < rgd.scPerRf; i++ {
-			rgd.gridFddDl[sfn].res[i] = NR_RES_D
-			rgd.gridFddUl[sfn].res[i] = NR_RES_U
-		}
+// resThumbnail draws the legend thumbnail of NR resource.
+type resThumbnail struct {
+	color imgcolor.Color
+}
 
-		rgd.trSsb[sfn] = false
-		rgd.occCss0[sfn] = false
-		rgd.trPdcchSib1[sfn] = false
-	}
+func (t resThumbnail) Thumbnail(c *draw.Canvas) {
+	pts := []vg.Point{{X: c.Min.X, Y: c.Min.Y}, {X: c.Max.X, Y: c.Min.Y}, {X: c.Max.X, Y: c.Max.Y}, {X: c.Min.X, Y: c.Max.Y}}
+	c.FillPolygon(t.color, pts)
+	c.StrokeLines(draw.LineStyle{Color: imgcolor.Black, Width: vg.Points(0.5)}, append(pts, pts[0]))
 }
 
-func aotSsb(sfn int) error {
-	ssbPeriod, _ := strconv.Atoi(flags.gridsetting.ssbPeriod[:len(flags.gridsetting.ssbPeriod)-2])
-	if ssbPeriod >= 10 && (sfn-flags.gridsetting._sfn)%(ssbPeriod/10) != 0 {
-		fmt.Printf("No SSB transmission in current frame(sfn=%d)\n", sfn)
-		return nil
+// getResColor returns the fill color of the cell style of NR resource, which is created by makeResMap.
+func getResColor(wb *excelize.File, style int) imgcolor.Color {
+	xf := wb.Styles.CellXfs.Xf[style]
+	if xf.FillID != nil {
+		fill := wb.Styles.Fills.Fill[*xf.FillID]
+		if fill.PatternFill != nil && fill.PatternFill.FgColor != nil && len(fill.PatternFill.FgColor.RGB) >= 6 {
+			rgb := fill.PatternFill.FgColor.RGB
+			v, err := strconv.ParseUint(rgb[len(rgb)-6:], 16, 32)
+			if err == nil {
+				return imgcolor.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xFF}
+			}
+		}
 	}
 
-	if rgd.trSsb[sfn] {
-		return nil
+	return imgcolor.White
+}
+
+// getTagColors returns the fill color of each tag of NR resources, where NR resources with the same tag share the same color, and the tags whose cell style colors are already used by other tags are assigned distinct colors so that each legend entry is unique.
+// Note: tags are ordered by the smallest NR resource of each tag, so the colors are the same across images.
+func getTagColors(wb *excelize.File) map[string]imgcolor.Color {
+	var keys []int
+	for k := range rgd.resMap {
+		keys = append(keys, k)
 	}
+	sort.Ints(keys)
 
-	var ssbHrfSet []int
-	if ssbPeriod < 10 {
-		ssbHrfSet = []int{0, 1}
-	} else {
-		ssbHrfSet = []int{flags.gridsetting._hrf}
+	colors := make(map[string]imgcolor.Color)
+	used := make(map[imgcolor.Color]bool)
+	// index of the next candidate color of the golden-angle hue sequence
+	next := 0
+	for _, k := range keys {
+		tag := rgd.resMap[k].Tag
+		if _, exist := colors[tag]; exist {
+			continue
+		}
+
+		c := getResColor(wb, rgd.resMap[k].Style)
+		for used[c] {
+			c = getHsvColor(math.Mod(float64(next)*0.618033988749895, 1), 0.65, 0.9)
+			next++
+		}
+		colors[tag] = c
+		used[c] = true
 	}
-	v := flags.gridsetting.pci % 4
 
-	for _, hrf := range ssbHrfSet {
-		for _, issb := range flags.gridsetting.candSsbIndex {
-			ssbFirstSymb := hrf*(rgd.symbPerRf/2) + rgd.ssbFirstSymbs[issb]
-			fmt.Printf("[AOT @ SFN=%d] hrf=%d, issb=%d, ssbSc0Rb0=%d, v=%d, ssbFirstSymb=%d\n", hrf, sfn, issb, rgd.ssbSc0Rb0, v, ssbFirstSymb)
+	return colors
+}
 
-			if flags.gridsetting._duplexMode == "TDD" {
-				// refer to 3GPP 38.213 vh40
-				// 11.1 Slot configurations
-				// For operation on a single carrier in unpaired spectrum, for a set of symbols of a slot indicated to a UE for reception of SS/PBCH blocks by ssb-PositionsInBurst in SIB1 or by ssb-PositionsInBurst in ServingCellConfigCommon or..., the UE does not transmit PUSCH, PUCCH, PRACH in the slot if a transmission would overlap with any symbol from the set of symbols and the UE does not transmit SRS in the set of symbols of the slot. The UE does not expect the set of symbols of the slot to be indicated as uplink by tdd-UL-DL-ConfigurationCommon, or tdd-UL-DL-ConfigurationDedicated, when provided to the UE.
-				for i := 0; i < 4; i++ {
-					if rgd.gridTdd[sfn].res[rgd.scPerSymb*(ssbFirstSymb+i)+rgd.ssbSc0Rb0] != NR_RES_D {
-						return errors.New(fmt.Sprintf("The UE does not expect the set of symbols of the slot which are used for SSB transmission(ssb index=%d, first symbol=%d) to be indicated as uplink by TDD-UL-DL-ConfigurationCommon.", issb, ssbFirstSymb+i))
-					}
-				}
+// getHsvColor converts HSV color with h, s and v within [0, 1] to RGB color.
+func getHsvColor(h, s, v float64) imgcolor.Color {
+	i := math.Floor(h * 6)
+	f := h*6 - i
+	p, q, t := v*(1-s), v*(1-f*s), v*(1-(1-f)*s)
+	rgb := [][]float64{{v, t, p}, {q, v, p}, {p, v, t}, {p, q, v}, {t, p, v}, {v, p, q}}[int(i)%6]
+	return imgcolor.RGBA{R: uint8(255 * rgb[0]), G: uint8(255 * rgb[1]), B: uint8(255 * rgb[2]), A: 0xFF}
+}
 
-				// symbol 0: PSS
-				for i := 0; i < 240; i++ {
-					if i >= 56 && i <= 182 {
-						rgd.gridTdd[sfn].res[rgd.scPerSymb*ssbFirstSymb+rgd.ssbSc0Rb0+i] = NR_RES_PSS
-					} else {
-						rgd.gridTdd[sfn].res[rgd.scPerSymb*ssbFirstSymb+rgd.ssbSc0Rb0+i] = NR_RES_DTX
-					}
-				}
+// exportNrrgImg exports NR resource grid to PNG or SVG images, one image per radio frame or one image for the slot range given by advanced.imgSlots, which can be zoomed by advanced.imgRbs and advanced.imgSymbs.
+// Radio frames whose slots are all empty are skipped as in exportNrrgXlsx.
+//  outPath: path of NR resource grid export without file extension
+//  format: png or svg
+func exportNrrgImg(wb *excelize.File, outPath, format string) error {
+	// [first RB, number of RBs] and [first symbol, number of symbols within each slot]
+	rbs := []int{0, flags.gridsetting._carrierNumRbs}
+	if len(flags.advanced.imgRbs) > 0 {
+		rbs = flags.advanced.imgRbs
+	}
+	symbs := []int{0, rgd.symbPerSlot}
+	if len(flags.advanced.imgSymbs) > 0 {
+		symbs = flags.advanced.imgSymbs
+	}
+	if len(rbs) != 2 || rbs[0] < 0 || rbs[1] < 1 || rbs[0]+rbs[1] > flags.gridsetting._carrierNumRbs {
+		return errors.New(fmt.Sprintf("Invalid imgRbs(=%v), which must be [firstRb, numRbs] within the carrier(%v RBs)!", flags.advanced.imgRbs, flags.gridsetting._carrierNumRbs))
+	}
+	if len(symbs) != 2 || symbs[0] < 0 || symbs[1] < 1 || symbs[0]+symbs[1] > rgd.symbPerSlot {
+		return errors.New(fmt.Sprintf("Invalid imgSymbs(=%v), which must be [firstSymb, numSymbs] within a slot(%v symbols)!", flags.advanced.imgSymbs, rgd.symbPerSlot))
+	}
+	if len(flags.advanced.imgSlots) > 0 && (len(flags.advanced.imgSlots) != 3 || flags.advanced.imgSlots[1] < 0 || flags.advanced.imgSlots[1] >= rgd.slotPerRf || flags.advanced.imgSlots[2] < 1) {
+		return errors.New(fmt.Sprintf("Invalid imgSlots(=%v), which must be [sfn, firstSlot, numSlots]!", flags.advanced.imgSlots))
+	}
+
+	tagColors := getTagColors(wb)
+	colors := make(map[int]imgcolor.Color)
+	for k, v := range rgd.resMap {
+		colors[k] = tagColors[v.Tag]
+	}
+
+	// Note: imgRbs, imgSymbs and imgSlots only apply to the carrier of the PCell, and the supplementary carrier, SCells and additional dedicated BWPs are exported with one image per radio frame with all RBs and symbols.
+	var dirs []string
+	var grids []map[int]DataPerRf
+	// [symbPerSlot, slotPerRf, scPerSymb] of each NR resource grid, and whether the grid is of the carrier of the PCell
+	var dims [][]int
+	var carrier []bool
+	for k := -1; k < len(rgd.scells)+len(rgd.bwps); k++ {
+		name, d, g, gd := getNrrgGridSet(k)
+		for j := range g {
+			if k < 0 {
+				dirs = append(dirs, d[j])
+			} else {
+				dirs = append(dirs, fmt.Sprintf("%v_%v", name, d[j]))
+			}
+			grids = append(grids, g[j])
+			dims = append(dims, gd[j])
+			carrier = append(carrier, k < 0 && d[j] != "SUL" && d[j] != "SDL")
+		}
+	}
 
-				// symbol 1/3: PBCH
-				for i := 0; i < 240; i++ {
-					if i >= v && (i-v)%4 == 0 {
-						rgd.gridTdd[sfn].res[rgd.scPerSymb*(ssbFirstSymb+1)+rgd.ssbSc0Rb0+i] = NR_RES_DMRS_PBCH
-						rgd.gridTdd[sfn].res[rgd.scPerSymb*(ssbFirstSymb+3)+rgd.ssbSc0Rb0+i] = NR_RES_DMRS_PBCH
-					} else {
-						rgd.gridTdd[sfn].res[rgd.scPerSymb*(ssbFirstSymb+1)+rgd.ssbSc0Rb0+i] = NR_RES_PBCH
-						rgd.gridTdd[sfn].res[rgd.scPerSymb*(ssbFirstSymb+3)+rgd.ssbSc0Rb0+i] = NR_RES_PBCH
-					}
-				}
+	for i, grid := range grids {
+		slotPerRf, scPerSymb := dims[i][1], dims[i][2]
+		scPerSlot := scPerSymb * dims[i][0]
+		rbs, symbs := rbs, symbs
+		if !carrier[i] {
+			rbs, symbs = []int{0, scPerSymb / rgd.scPerRb}, []int{0, dims[i][0]}
+		}
 
-				// symbol 2: PBCH and SSS
-				for i := 0; i < 240; i++ {
-					if i >= 56 && i <= 182 {
-						rgd.gridTdd[sfn].res[rgd.scPerSymb*(ssbFirstSymb+2)+rgd.ssbSc0Rb0+i] = NR_RES_SSS
-					} else if i <= 47 || i >= 192 {
-						if i >= v && (i-v)%4 == 0 {
-							rgd.gridTdd[sfn].res[rgd.scPerSymb*(ssbFirstSymb+2)+rgd.ssbSc0Rb0+i] = NR_RES_DMRS_PBCH
-						} else {
-							rgd.gridTdd[sfn].res[rgd.scPerSymb*(ssbFirstSymb+2)+rgd.ssbSc0Rb0+i] = NR_RES_PBCH
-						}
-					} else {
-						rgd.gridTdd[sfn].res[rgd.scPerSymb*(ssbFirstSymb+2)+rgd.ssbSc0Rb0+i] = NR_RES_DTX
+		// [first slot(=sfn*slotPerRf+slot), number of slots] of each image
+		var ranges [][]int
+		if len(flags.advanced.imgSlots) > 0 && carrier[i] {
+			ranges = append(ranges, []int{flags.advanced.imgSlots[0]*rgd.slotPerRf + flags.advanced.imgSlots[1], flags.advanced.imgSlots[2]})
+		} else {
+			for _, sfn := range getNrrgSfns(grid) {
+				// skip empty radio frame
+				empty := true
+				for _, tags := range grid[sfn].tags {
+					if tags != nil && tags.Cardinality() > 0 {
+						empty = false
+						break
 					}
 				}
-
-				if rgd.gridTdd[sfn].tags[ssbFirstSymb/rgd.symbPerSlot] == nil {
-					rgd.gridTdd[sfn].tags[ssbFirstSymb/rgd.symbPerSlot] = mapset.NewSet()
-				}
-				rgd.gridTdd[sfn].tags[ssbFirstSymb/rgd.symbPerSlot].Add("SSB")
-			} else {
-				// symbol 0: PSS
-				for i := 0; i < 240; i++ {
-					if i >= 56 && i <= 182 {
-						rgd.gridFddDl[sfn].res[rgd.scPerSymb*ssbFirstSymb+rgd.ssbSc0Rb0+i] = NR_RES_PSS
-					} else {
-						rgd.gridFddDl[sfn].res[rgd.scPerSymb*ssbFirstSymb+rgd.ssbSc0Rb0+i] = NR_RES_DTX
-					}
+				if !empty {
+					ranges = append(ranges, []int{sfn * slotPerRf, slotPerRf})
 				}
+			}
+		}
 
-				// symbol 1/3: PBCH
-				for i := 0; i < 240; i++ {
-					if i >= v && (i-v)%4 == 0 {
-						rgd.gridFddDl[sfn].res[rgd.scPerSymb*(ssbFirstSymb+1)+rgd.ssbSc0Rb0+i] = NR_RES_DMRS_PBCH
-						rgd.gridFddDl[sfn].res[rgd.scPerSymb*(ssbFirstSymb+3)+rgd.ssbSc0Rb0+i] = NR_RES_DMRS_PBCH
-					} else {
-						rgd.gridFddDl[sfn].res[rgd.scPerSymb*(ssbFirstSymb+1)+rgd.ssbSc0Rb0+i] = NR_RES_PBCH
-						rgd.gridFddDl[sfn].res[rgd.scPerSymb*(ssbFirstSymb+3)+rgd.ssbSc0Rb0+i] = NR_RES_PBCH
-					}
+		for _, r := range ranges {
+			var res [][]int
+			var xticks []plot.Tick
+			// NR resources present in the image, which are ordered by first appearance
+			var present []int
+			for n := r[0]; n < r[0]+r[1]; n++ {
+				sfn := n / slotPerRf
+				slot := n % slotPerRf
+				if _, exist := grid[sfn]; !exist {
+					return errors.New(fmt.Sprintf("No NR resource grid(%v) for SFN=%v, which is out of the simulation.", dirs[i], sfn))
 				}
 
-				// symbol 2: PBCH and SSS
-				for i := 0; i < 240; i++ {
-					if i >= 56 && i <= 182 {
-						rgd.gridFddDl[sfn].res[rgd.scPerSymb*(ssbFirstSymb+2)+rgd.ssbSc0Rb0+i] = NR_RES_SSS
-					} else if i <= 47 || i >= 192 {
-						if i >= v && (i-v)%4 == 0 {
-							rgd.gridFddDl[sfn].res[rgd.scPerSymb*(ssbFirstSymb+2)+rgd.ssbSc0Rb0+i] = NR_RES_DMRS_PBCH
-						} else {
-							rgd.gridFddDl[sfn].res[rgd.scPerSymb*(ssbFirstSymb+2)+rgd.ssbSc0Rb0+i] = NR_RES_PBCH
+				xticks = append(xticks, plot.Tick{Value: float64(len(res)), Label: fmt.Sprintf("%v-%v", sfn, slot)})
+				for symb := symbs[0]; symb < symbs[0]+symbs[1]; symb++ {
+					col := make([]int, rbs[1]*rgd.scPerRb)
+					for isc := range col {
+						col[isc] = grid[sfn].res[slot*scPerSlot+symb*scPerSymb+rbs[0]*rgd.scPerRb+isc]
+						if !utils.ContainsInt(present, col[isc]) {
+							present = append(present, col[isc])
 						}
-					} else {
-						rgd.gridFddDl[sfn].res[rgd.scPerSymb*(ssbFirstSymb+2)+rgd.ssbSc0Rb0+i] = NR_RES_DTX
 					}
+					res = append(res, col)
 				}
+			}
 
-				if rgd.gridFddDl[sfn].tags[ssbFirstSymb/rgd.symbPerSlot] == nil {
-					rgd.gridFddDl[sfn].tags[ssbFirstSymb/rgd.symbPerSlot] = mapset.NewSet()
+			var yticks []plot.Tick
+			step := utils.MaxInt([]int{1, rbs[1] / 20})
+			for irb := 0; irb <= rbs[1]; irb += step {
+				yticks = append(yticks, plot.Tick{Value: float64(irb * rgd.scPerRb), Label: strconv.Itoa(rbs[0] + irb)})
+			}
+
+			p := plot.New()
+			p.Title.Text = fmt.Sprintf("NR resource grid(%v): SFN=%v, slot=%v, numSlots=%v, RB=%v~%v, symbol=%v~%v", dirs[i], r[0]/slotPerRf, r[0]%slotPerRf, r[1], rbs[0], rbs[0]+rbs[1]-1, symbs[0], symbs[0]+symbs[1]-1)
+			p.X.Label.Text = "SFN-slot"
+			p.Y.Label.Text = "RB"
+			p.X.Tick.Marker = plot.ConstantTicks(xticks)
+			p.Y.Tick.Marker = plot.ConstantTicks(yticks)
+			p.Add(gridPlotter{res: res, colors: colors})
+
+			// legend of NR resources with the same tag is merged
+			lp := plot.New()
+			lp.HideAxes()
+			lp.Legend.Top = true
+			lp.Legend.Left = true
+			var tags []string
+			for _, k := range present {
+				if !utils.ContainsStr(tags, rgd.resMap[k].Tag) {
+					tags = append(tags, rgd.resMap[k].Tag)
+					lp.Legend.Add(rgd.resMap[k].Tag, resThumbnail{color: colors[k]})
 				}
-				rgd.gridFddDl[sfn].tags[ssbFirstSymb/rgd.symbPerSlot].Add("SSB")
 			}
 
-			rgd.ssbSymbs[sfn] = append(rgd.ssbSymbs[sfn], []int{ssbFirstSymb, ssbFirstSymb + 1, ssbFirstSymb + 2, ssbFirstSymb + 3}...)
+			// image size: 0.1 inch per symbol and 0.05 inch per RB
+			legendW := 2 * vg.Inch
+			w := vg.Length(utils.MaxInt([]int{6 * 72, len(res) * 72 / 10})) + legendW
+			h := vg.Length(utils.MaxInt([]int{4 * 72, utils.MinInt([]int{rbs[1] * 72 / 20, 40 * 72})}))
+			h = vg.Length(math.Max(float64(h), float64(len(tags))*0.25*float64(vg.Inch)))
+			c, err := draw.NewFormattedCanvas(w, h, format)
+			if err != nil {
+				return err
+			}
+			dc := draw.New(c)
+			p.Draw(draw.Canvas{Canvas: dc.Canvas, Rectangle: vg.Rectangle{Min: dc.Min, Max: vg.Point{X: dc.Max.X - legendW, Y: dc.Max.Y}}})
+			lp.Draw(draw.Canvas{Canvas: dc.Canvas, Rectangle: vg.Rectangle{Min: vg.Point{X: dc.Max.X - legendW, Y: dc.Min.Y}, Max: dc.Max}})
+
+			fn := fmt.Sprintf("%v_%v_%v_%v.%v", outPath, dirs[i], r[0]/slotPerRf, r[0]%slotPerRf, format)
+			fout, err := os.Create(fn)
+			if err != nil {
+				return err
+			}
+			if _, err := c.WriteTo(fout); err != nil {
+				fout.Close()
+				return err
+			}
+			fout.Close()
 		}
 	}
 
-	rgd.trSsb[sfn] = true
-
 	return nil
 }
 
-func aotPdcchSib1(sfn int) error {
-	// skip current SFN if no SSB is transmitted
-	if !rgd.trSsb[sfn] {
-		return nil
+// getNrrgOverheads returns the overhead categories of NR resource grid report and the NR resources of each category.
+func getNrrgOverheads() ([]string, map[string][]int) {
+	cats := []string{"SSB", "PDCCH", "DMRS", "CSI-RS", "TRS", "PTRS", "SRS", "PUCCH", "PRACH", "LTE", "PRS"}
+	res := map[string][]int{
+		"SSB":    {NR_RES_PSS, NR_RES_SSS, NR_RES_PBCH, NR_RES_DMRS_PBCH},
+		"PDCCH":  {NR_RES_DMRS_PDCCH, NR_RES_CORESET1},
+		"DMRS":   {NR_RES_DMRS_SIB1, NR_RES_DMRS_PDSCH, NR_RES_DMRS_MSG2, NR_RES_DMRS_MSG4, NR_RES_DMRS_MSGB, NR_RES_DMRS_PAGING, NR_RES_DMRS_OSI, NR_RES_DMRS_PUSCH, NR_RES_DMRS_MSG3, NR_RES_DMRS_MSGA},
+		"CSI-RS": {NR_RES_CSI_IM},
+		"TRS":    {NR_RES_TRS},
+		"PTRS":   {NR_RES_PTRS_PDSCH, NR_RES_PTRS_PUSCH},
+		"SRS":    {NR_RES_SRS0, NR_RES_SRS0_2, NR_RES_SRS1_3, NR_RES_SRS0_1, NR_RES_SRS0_1_2_3, NR_RES_SRS_POS},
+		"PUCCH":  {NR_RES_PUCCH_SR, NR_RES_PUCCH_ACK, NR_RES_PUCCH_CSI, NR_RES_PUCCH_SR_CSI, NR_RES_PUCCH_ACK_CSI, NR_RES_DMRS_PUCCH},
+		"PRACH":  {NR_RES_PRACH},
+		"LTE":    {NR_RES_LTE_CRS, NR_RES_LTE_PDCCH},
+		"PRS":    {NR_RES_PRS},
 	}
-
-	if rgd.trPdcchSib1[sfn] {
-		return nil
+	for i := 0; i < 8; i++ {
+		res["PDCCH"] = append(res["PDCCH"], NR_RES_PDCCH_CANDIDATE+i)
 	}
-
-	err := detCss0(sfn)
-	if err != nil {
-		return err
+	for i := 0; i < 16; i++ {
+		res["CSI-RS"] = append(res["CSI-RS"], NR_RES_CSI_RS_CDM_GRP_0+i)
 	}
 
-	for _, issb := range flags.gridsetting.candSsbIndex {
-		var ssbFirstSymbsMinus1 []int
-		if flags.gridsetting.ssbPeriod == "5ms" {
-			ssbFirstSymbsMinus1 = []int{rgd.ssbFirstSymbs[issb] - 1, rgd.symbPerRf/2 + rgd.ssbFirstSymbs[issb] - 1}
-		} else {
-			ssbFirstSymbsMinus1 = []int{flags.gridsetting._hrf*rgd.symbPerRf/2 + rgd.ssbFirstSymbs[issb] - 1}
-		}
-		fmt.Printf("issb=%v, ssbFirstSymbsMinus1=%v\n", issb, ssbFirstSymbsMinus1)
-
-		key := fmt.Sprintf("%v_%v", sfn, issb)
-		for _, td := range rgd.css0TdOccasions[key] {
-			sfnc := td.Sfn
-			nc := td.Slot
-			firstSymb := td.FirstSymb
+	return cats, res
+}
 
-			// validation #1: PDCCH occasion should not start before corresponding SSB transmission!
-			if nc*rgd.symbPerSlot+firstSymb <= ssbFirstSymbsMinus1[0] {
-				continue
+// reportNrrg reports the number of REs per NR resource tag per slot, radio frame and link direction, the overhead of reference signals and common channels, and the estimated peak and scheduled throughput of PDSCH and PUSCH.
+// For TDD, the link direction of each symbol is determined by tdd-UL-DL-ConfigurationCommon, and guard symbols are not counted.
+// The supplementary carrier(SUL or SDL), if configured, is reported as a separate link direction, and each SCell of carrier aggregation is reported as separate link direction(s) named <name>_<dir>, e.g. SCell1_DL.
+// Note: additional dedicated BWPs are not included in the report, and the peak and scheduled throughput are only estimated for the PCell.
+//  fn: file name of the per-slot and per-frame report in CSV format, which is not exported if empty
+func reportNrrg(fn string) error {
+	var dirs []string
+	// number of REs per NR resource, key = dir, sfn*slotPerRf+slot and NR resource
+	cnt := make(map[string]map[int]map[int]int)
+	// number of symbols, key = dir and sfn*slotPerRf+slot
+	symbs := make(map[string]map[int]int)
+	// [number of PRBs, number of symbols, number of DMRS REs] of PDSCH or PUSCH per slot of the PCell, key = dir
+	schSlots := make(map[string][][]int)
+	// radio frames, slots per radio frame and subcarriers per symbol, key = dir
+	sfns := make(map[string][]int)
+	slotPerRf := make(map[string]int)
+	scPerSymb := make(map[string]int)
+
+	for k := -1; k < len(rgd.scells); k++ {
+		name, gdirs, grids, dims := getNrrgGridSet(k)
+		prefix := ""
+		if k >= 0 {
+			prefix = name + "_"
+		}
+
+		for i, grid := range grids {
+			gdir := gdirs[i]
+			symbPerSlot, spr, sps := dims[i][0], dims[i][1], dims[i][2]
+			for _, dir := range map[bool][]string{true: {"DL", "UL"}, false: {gdir}}[gdir == "TDD"] {
+				dirs = append(dirs, prefix+dir)
+				cnt[prefix+dir] = make(map[int]map[int]int)
+				symbs[prefix+dir] = make(map[int]int)
+				sfns[prefix+dir] = getNrrgSfns(grid)
+				slotPerRf[prefix+dir] = spr
+				scPerSymb[prefix+dir] = sps
 			}
 
-			// validation #2/#3: Collision of SSB/CORESET0, and TDD-UL-DL-Config
-			// refer to 38.213 vh40
-			// 10	UE procedure for receiving control information
-			// If a UE monitors the PDCCH candidate for a Type0-PDCCH CSS set on the serving cell according to the procedure described in clause 13, the UE may assume that no SS/PBCH block is transmitted in REs used for monitoring the PDCCH candidate on the serving cell.
-			// 11.1	Slot configuration
-			// For a set of symbols of a slot indicated to a UE by pdcch-ConfigSIB1 in MIB for a CORESET for Type0-PDCCH CSS set, the UE does not expect the set of symbols to be indicated as uplink by tdd-UL-DL-ConfigurationCommon, or tdd-UL-DL-ConfigurationDedicated.
-			if flags.gridsetting._duplexMode == "TDD" {
-				valid := true
-				for i := 0; i < flags.gridsetting._coreset0NumSymbs; i++ {
-					for j := 0; j < flags.gridsetting._coreset0NumRbs*rgd.scPerRb; j++ {
-						if rgd.gridTdd[sfn].res[rgd.scPerSymb*(nc*rgd.symbPerSlot+firstSymb+i)+rgd.coreset0Sc0Rb0+j] != NR_RES_D {
-							valid = false
-							break
-						}
+			for _, sfn := range getNrrgSfns(grid) {
+				var pat []string
+				if gdir == "TDD" {
+					if k < 0 {
+						pat = getTddPat(sfn)
+					} else {
+						pat = rgd.scells[k].tddPat
 					}
 				}
 
-				if !valid {
-					continue
+				for slot := 0; slot < spr; slot++ {
+					n := sfn*spr + slot
+					for symb := 0; symb < symbPerSlot; symb++ {
+						dir := gdir
+						if dir == "TDD" {
+							switch pat[slot*symbPerSlot+symb] {
+							case "D":
+								dir = "DL"
+							case "U":
+								dir = "UL"
+							default:
+								continue
+							}
+						}
+						dir = prefix + dir
+
+						if cnt[dir][n] == nil {
+							cnt[dir][n] = make(map[int]int)
+						}
+						symbs[dir][n]++
+						for sc := 0; sc < sps; sc++ {
+							cnt[dir][n][grid[sfn].res[(slot*symbPerSlot+symb)*sps+sc]]++
+						}
+					}
+
+					if k < 0 {
+						for _, dir := range map[bool][]string{true: {"DL", "UL"}, false: {gdir}}[gdir == "TDD"] {
+							sch := map[string]string{"DL": "PDSCH", "UL": "PUSCH", "SDL": "PDSCH", "SUL": "PUSCH"}[dir]
+							if v := getNrrgSchSlot(grid[sfn], slot, symbPerSlot, sps, sch); v[0] > 0 {
+								schSlots[dir] = append(schSlots[dir], v)
+							}
+						}
+					}
 				}
 			}
+		}
+	}
 
-			M, _ := strconv.Atoi(flags.gridsetting._css0NumCandidates[1:])
-			for m := 0; m < M; m++ {
-				cces, err := detCcesPerPdcchCand(0, flags.gridsetting._css0AggLevel, m, nc, "type0", 0, rgd.coreset0NumCces, 0, M)
-				if err != nil {
-					return err
-				}
+	// NR resources with the same tag(e.g. DMRS) are merged
+	getTags := func(m map[int]int) ([]string, map[string]int) {
+		var tags []string
+		tagCnt := make(map[string]int)
+		for k, v := range m {
+			tag := rgd.resMap[k].Tag
+			if _, exist := tagCnt[tag]; !exist {
+				tags = append(tags, tag)
+			}
+			tagCnt[tag] += v
+		}
+		sort.Strings(tags)
+		return tags, tagCnt
+	}
 
-				rgd.css0PdcchCandidates[key] = append(rgd.css0PdcchCandidates[key], nrgrid.Css0PdcchCandidate{sfnc, nc, firstSymb, m, cces})
-				fmt.Printf("SSB/SIB1 PDCCH candidate: issb=%v, sfnc=%v, nc=%v, firstSymb=%v, m=%v, cces=%v\n", issb, sfnc, nc, firstSymb, m, cces)
+	var fout io.Writer = ioutil.Discard
+	if len(fn) > 0 {
+		f, err := os.OpenFile(fn, os.O_TRUNC|os.O_WRONLY|os.O_CREATE, 0664)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		fout = f
+	}
 
-				for i, icce := range rgd.coreset0Cces {
-					if utils.ContainsInt(cces, icce) {
-						isymb := rgd.coreset0RegBundles[i].Isymb
-						irb := rgd.coreset0RegBundles[i].Irb
-						for isc := 0; isc < rgd.scPerRb; isc++ {
-							if flags.gridsetting._duplexMode == "TDD" {
-								if isc > 0 && (isc-1)%4 == 0 {
-									rgd.gridTdd[sfnc].res[nc*rgd.scPerSlot+(firstSymb+isymb)*rgd.scPerSymb+rgd.coreset0Sc0Rb0+irb*rgd.scPerRb+isc] = NR_RES_DMRS_PDCCH
-								} else {
-									rgd.gridTdd[sfnc].res[nc*rgd.scPerSlot+(firstSymb+isymb)*rgd.scPerSymb+rgd.coreset0Sc0Rb0+irb*rgd.scPerRb+isc] = NR_RES_PDCCH_CANDIDATE + m
-								}
+	w := bufio.NewWriter(fout)
+	w.WriteString("level,dir,sfn,slot,tag,numREs,ratio\n")
 
-								if rgd.gridTdd[sfnc].tags[nc] == nil {
-									rgd.gridTdd[sfnc].tags[nc] = mapset.NewSet()
-								}
-								rgd.gridTdd[sfnc].tags[nc].Add("PDCCH")
-							} else {
-								if isc > 0 && (isc-1)%4 == 0 {
-									rgd.gridFddDl[sfnc].res[nc*rgd.scPerSlot+(firstSymb+isymb)*rgd.scPerSymb+rgd.coreset0Sc0Rb0+irb*rgd.scPerRb+isc] = NR_RES_DMRS_PDCCH
-								} else {
-									rgd.gridFddDl[sfnc].res[nc*rgd.scPerSlot+(firstSymb+isymb)*rgd.scPerSymb+rgd.coreset0Sc0Rb0+irb*rgd.scPerRb+isc] = NR_RES_PDCCH_CANDIDATE + m
-								}
+	cats, catRes := getNrrgOverheads()
+	for _, dir := range dirs {
+		total := make(map[int]int)
+		totalREs := 0
+		for _, sfn := range sfns[dir] {
+			frame := make(map[int]int)
+			frameREs := 0
+			for slot := 0; slot < slotPerRf[dir]; slot++ {
+				n := sfn*slotPerRf[dir] + slot
+				if _, exist := cnt[dir][n]; !exist {
+					continue
+				}
 
-								if rgd.gridFddDl[sfnc].tags[nc] == nil {
-									rgd.gridFddDl[sfnc].tags[nc] = mapset.NewSet()
-								}
-								rgd.gridFddDl[sfnc].tags[nc].Add("PDCCH")
-							}
-						}
+				slotREs := symbs[dir][n] * scPerSymb[dir]
+				tags, tagCnt := getTags(cnt[dir][n])
+				for _, tag := range tags {
+					if _, err := w.WriteString(fmt.Sprintf("slot,%v,%v,%v,%v,%v,%.4f\n", dir, sfn, slot, strconv.Quote(tag), tagCnt[tag], float64(tagCnt[tag])/float64(slotREs))); err != nil {
+						return err
 					}
 				}
+
+				for k, v := range cnt[dir][n] {
+					frame[k] += v
+					total[k] += v
+				}
+				frameREs += slotREs
+			}
+
+			if frameREs == 0 {
+				continue
+			}
+			tags, tagCnt := getTags(frame)
+			for _, tag := range tags {
+				if _, err := w.WriteString(fmt.Sprintf("frame,%v,%v,,%v,%v,%.4f\n", dir, sfn, strconv.Quote(tag), tagCnt[tag], float64(tagCnt[tag])/float64(frameREs))); err != nil {
+					return err
+				}
 			}
+			totalREs += frameREs
 		}
-	}
 
-	fmt.Printf("[SFN=%v, candSsbIndex=%v] css0PdcchCandidates=%v\n", sfn, flags.gridsetting.candSsbIndex, rgd.css0PdcchCandidates)
-	rgd.trPdcchSib1[sfn] = true
+		if totalREs == 0 {
+			continue
+		}
 
-	return nil
-}
+		regYellow.Printf("NR resource grid report(%v): numFrames=%v, numREs=%v\n", dir, len(sfns[dir]), totalREs)
+		tags, tagCnt := getTags(total)
+		for _, tag := range tags {
+			if _, err := w.WriteString(fmt.Sprintf("total,%v,,,%v,%v,%.4f\n", dir, strconv.Quote(tag), tagCnt[tag], float64(tagCnt[tag])/float64(totalREs))); err != nil {
+				return err
+			}
+			fmt.Printf("    tag=%v, numREs=%v, ratio=%.2f%%\n", tag, tagCnt[tag], 100*float64(tagCnt[tag])/float64(totalREs))
+		}
+
+		var oh []string
+		for _, cat := range cats {
+			numREs := 0
+			for _, k := range catRes[cat] {
+				numREs += total[k]
+			}
+			if numREs > 0 {
+				oh = append(oh, fmt.Sprintf("%v=%.2f%%", cat, 100*float64(numREs)/float64(totalREs)))
+			}
+		}
+		fmt.Printf("Overhead(%v): %v\n", dir, strings.Join(oh, ", "))
+
+		// PDSCH or PUSCH is scheduled on the supplementary carrier when SDL or SUL is used
+		if strings.HasPrefix(dir, "SCell") || (dir == "DL" && isSdlUsed()) || (dir == "UL" && isSulUsed()) {
+			continue
+		}
+		sch := map[string]string{"DL": "PDSCH", "UL": "PUSCH", "SDL": "PDSCH", "SUL": "PUSCH"}[dir]
+		// the peak throughput assumes the same DMRS overhead as the scheduled PDSCH/PUSCH
+		numPrbs := flags.bwp._bwpNumRbs[map[string]int{"PDSCH": DED_DL_BWP, "PUSCH": DED_UL_BWP}[sch]]
+		if dir == "SUL" || dir == "SDL" {
+			numPrbs = flags.bwp._supBwpNumRbs[SUP_DED_BWP]
+		}
+		dmrsOh := 0
+		for _, v := range schSlots[dir] {
+			dmrsOh = utils.MaxInt([]int{dmrsOh, utils.CeilInt(float64(v[2]) / float64(v[0]))})
+		}
+		var slotSymbs []int
+		for _, sfn := range sfns[dir] {
+			for slot := 0; slot < slotPerRf[dir]; slot++ {
+				slotSymbs = append(slotSymbs, symbs[dir][sfn*slotPerRf[dir]+slot])
+			}
+		}
+		peak := getNrrgPeakTput(sch, numPrbs, dmrsOh, slotSymbs, len(sfns[dir]))
+		sched := getNrrgSchedTput(sch, schSlots[dir], len(sfns[dir]))
+		fmt.Printf("Peak throughput(%v): %.2f Mbps, scheduled throughput(%v): %.2f Mbps\n", sch, peak, sch, sched)
+	}
+
+	// PDCCH monitoring ratio and active time ratio of DRX
+	if rgd.drx != nil && rgd.drx.numMos > 0 {
+		if _, err := w.WriteString(fmt.Sprintf("drx,DL,,,%v,%v,%.4f\n", strconv.Quote("PDCCH monitoring(USS)"), rgd.drx.numActiveMos, float64(rgd.drx.numActiveMos)/float64(rgd.drx.numMos))); err != nil {
+			return err
+		}
+		if _, err := w.WriteString(fmt.Sprintf("drx,DL,,,%v,%v,%.4f\n", strconv.Quote("Active time"), rgd.drx.numActiveSlots, float64(rgd.drx.numActiveSlots)/float64(rgd.drx.numSlots))); err != nil {
+			return err
+		}
+		fmt.Printf("DRX: monitoring ratio=%.2f%%(%v of %v PDCCH monitoring occasions of USS), active time=%.2f%%(%v of %v slots)\n", 100*float64(rgd.drx.numActiveMos)/float64(rgd.drx.numMos), rgd.drx.numActiveMos, rgd.drx.numMos, 100*float64(rgd.drx.numActiveSlots)/float64(rgd.drx.numSlots), rgd.drx.numActiveSlots, rgd.drx.numSlots)
+	}
+
+	return w.Flush()
+}
+
+// getNrrgSchSlot returns [number of PRBs, number of symbols, number of DMRS REs] of PDSCH or PUSCH in slot of grid, where PRBs and symbols are those containing any RE of the PDSCH/PUSCH, its DMRS or PTRS.
+// Note: DTX REs of the DMRS CDM groups without data within the PRBs on DMRS symbols are counted as DMRS REs.
+func getNrrgSchSlot(grid DataPerRf, slot, symbPerSlot, scPerSymb int, sch string) []int {
+	resData, resDmrs, resPtrs := NR_RES_PDSCH, NR_RES_DMRS_PDSCH, NR_RES_PTRS_PDSCH
+	if sch == "PUSCH" {
+		resData, resDmrs, resPtrs = NR_RES_PUSCH, NR_RES_DMRS_PUSCH, NR_RES_PTRS_PUSCH
+	}
+
+	rbs := make(map[int]bool)
+	numSymbs := 0
+	var dmrsSymbs []int
+	for symb := 0; symb < symbPerSlot; symb++ {
+		found, dmrs := false, false
+		for sc := 0; sc < scPerSymb; sc++ {
+			res := grid.res[(slot*symbPerSlot+symb)*scPerSymb+sc]
+			if res == resData || res == resDmrs || res == resPtrs {
+				rbs[sc/rgd.scPerRb] = true
+				found = true
+			}
+			dmrs = dmrs || res == resDmrs
+		}
+		if found {
+			numSymbs++
+		}
+		if dmrs {
+			dmrsSymbs = append(dmrsSymbs, symb)
+		}
+	}
+
+	numDmrsRes := 0
+	for _, symb := range dmrsSymbs {
+		for rb := range rbs {
+			for isc := 0; isc < rgd.scPerRb; isc++ {
+				if res := grid.res[(slot*symbPerSlot+symb)*scPerSymb+rb*rgd.scPerRb+isc]; res == resDmrs || res == NR_RES_DTX {
+					numDmrsRes++
+				}
+			}
+		}
+	}
+
+	return []int{len(rbs), numSymbs, numDmrsRes}
+}
+
+// getNrrgSchSettings returns whether transform precoding is enabled, the MCS table, the maximum number of layers and xOverhead of PDSCH(sch=PDSCH) or PUSCH(sch=PUSCH).
+func getNrrgSchSettings(sch string) (bool, string, int, int) {
+	var tp bool
+	var mcsTab, xOh string
+	var layers int
+	if sch == "PDSCH" {
+		mcsTab = flags.pdsch.pdschMcsTable
+		xOh = flags.pdsch.pdschXOh
+		layers = flags.pdsch.pdschMaxLayers
+	} else {
+		tp = flags.pusch.puschTp == "enabled"
+		mcsTab = flags.pusch.puschMcsTable
+		xOh = flags.pusch.puschXOh
+		layers = flags.pusch.puschCbMaxRankNonCbMaxLayers
+		if tp {
+			layers = 1
+		}
+	}
+	xoh, _ := strconv.Atoi(xOh[3:])
+
+	return tp, mcsTab, layers, xoh
+}
+
+// getNrrgMaxTbs returns the TBS of PDSCH or PUSCH scheduled with the highest valid MCS of the configured MCS table and the maximum number of layers, which is 0 if there is no RE for data.
+//  sch: PDSCH or PUSCH
+//  td: number of symbols
+//  fd: number of PRBs
+//  dmrsOh: N_DMRS_PRB
+func getNrrgMaxTbs(sch string, td, fd, dmrsOh int) int {
+	tp, mcsTab, layers, xoh := getNrrgSchSettings(sch)
+	if fd <= 0 || 12*td-dmrsOh-xoh <= 0 {
+		return 0
+	}
+
+	// refer to 3GPP 38.211 vh40 7.3.1.3: two codewords are used when the number of layers is larger than 4
+	cws := []int{layers}
+	if layers > 4 {
+		cws = []int{layers / 2, layers - layers/2}
+	}
+
+	bits := 0
+	for _, layer := range cws {
+		for mcs := 31; mcs >= 0; mcs-- {
+			tbs, err := getTbs(sch, tp, "C-RNTI", mcsTab, td, fd, mcs, layer, dmrsOh, xoh, 1)
+			if err == nil {
+				bits += tbs
+				break
+			}
+		}
+	}
+
+	return bits
+}
+
+// getNrrgPeakTput returns the peak throughput(in Mbps) of PDSCH or PUSCH, assuming that all PRBs of the BWP and all DL(for PDSCH) or UL(for PUSCH) symbols of each slot are allocated with the highest MCS.
+// Note: for TDD, slots without any DL(or UL) symbol are not counted.
+//  sch: PDSCH or PUSCH
+//  numPrbs: number of PRBs of the BWP
+//  dmrsOh: N_DMRS_PRB
+//  slotSymbs: number of DL(or UL) symbols of each slot
+//  numFrames: number of radio frames
+func getNrrgPeakTput(sch string, numPrbs, dmrsOh int, slotSymbs []int, numFrames int) float64 {
+	// TBS per number of symbols
+	tbsPerTd := make(map[int]int)
+	bits, numSlots := 0, 0
+	for _, td := range slotSymbs {
+		if td <= 0 {
+			continue
+		}
+		if _, exist := tbsPerTd[td]; !exist {
+			tbsPerTd[td] = getNrrgMaxTbs(sch, td, numPrbs, dmrsOh)
+		}
+		bits += tbsPerTd[td]
+		numSlots++
+	}
+
+	_, mcsTab, layers, xoh := getNrrgSchSettings(sch)
+	fmt.Printf("Peak throughput(%v) settings: mcsTable=%v, layers=%v, xOverhead=%v, numPrbs=%v, N_DMRS_PRB=%v, numSlots=%v, TBS per number of symbols=%v\n", sch, mcsTab, layers, xoh, numPrbs, dmrsOh, numSlots, tbsPerTd)
+
+	// each radio frame is 10ms
+	return float64(bits) / (float64(numFrames) * 10 * 1000)
+}
+
+// getNrrgSchedTput returns the scheduled throughput(in Mbps) of PDSCH or PUSCH in the resource grid, assuming that PDSCH/PUSCH is always scheduled with the highest MCS.
+// Note: each slot with PDSCH/PUSCH is regarded as a new transmission, and TBS is determined by the scheduled PRBs and symbols and the DMRS REs counted in the slot.
+//  sch: PDSCH or PUSCH
+//  slots: [number of PRBs, number of symbols, number of DMRS REs] of PDSCH/PUSCH per slot
+//  numFrames: number of radio frames
+func getNrrgSchedTput(sch string, slots [][]int, numFrames int) float64 {
+	// TBS per allocation of [number of PRBs, number of symbols, N_DMRS_PRB]
+	tbsPerAlloc := make(map[string]int)
+	bits := 0
+	for _, v := range slots {
+		// refer to 3GPP 38.214 vh40 5.1.3.2: N_DMRS_PRB is the number of REs for DM-RS per PRB in the scheduled duration including the overhead of the DM-RS CDM groups without data
+		fd, td := v[0], v[1]
+		if fd <= 0 {
+			continue
+		}
+		dmrsOh := utils.CeilInt(float64(v[2]) / float64(fd))
+
+		key := fmt.Sprint([]int{fd, td, dmrsOh})
+		if _, exist := tbsPerAlloc[key]; !exist {
+			tbsPerAlloc[key] = getNrrgMaxTbs(sch, td, fd, dmrsOh)
+		}
+		bits += tbsPerAlloc[key]
+	}
+
+	fmt.Printf("Scheduled throughput(%v) settings: numSlots=%v, TBS per allocation([numPrbs numSymbs N_DMRS_PRB])=%v\n", sch, len(slots), tbsPerAlloc)
+
+	// each radio frame is 10ms
+	return float64(bits) / (float64(numFrames) * 10 * 1000)
+}
+
+func initNrrgData() error {
+	// constants
+	rgd.subfPerRf = 10
+	rgd.slotPerSubf = int(math.Exp2(float64(nrgrid.Scs2Mu[flags.gridsetting.scs])))
+	rgd.slotPerRf = rgd.slotPerSubf * rgd.subfPerRf
+	// refer to 3GPP 38.211 vh40
+	// 4.3.2	Slots: Table 4.3.2-1(normal cyclic prefix) and Table 4.3.2-2(extended cyclic prefix)
+	rgd.symbPerSlot = getSymbPerSlot(flags.bwp._bwpCp[DED_DL_BWP])
+	rgd.symbPerSubf = rgd.symbPerSlot * rgd.slotPerSubf
+	rgd.symbPerRf = rgd.symbPerSlot * rgd.slotPerRf
+	rgd.scPerRb = 12
+	rgd.scPerSymb = rgd.scPerRb * flags.gridsetting._carrierNumRbs
+	rgd.scPerSlot = rgd.scPerSymb * rgd.symbPerSlot
+	rgd.scPerSubf = rgd.scPerSlot * rgd.slotPerSubf
+	rgd.scPerRf = rgd.scPerSlot * rgd.slotPerRf
+
+	// resource grid
+	if flags.gridsetting._duplexMode == "TDD" {
+		if len(flags.tdduldl.patPeriod) == 1 || len(flags.tdduldl.patPeriod) == 2 {
+			period := 0.0
+			for _, p := range flags.tdduldl.patPeriod {
+				v, _ := strconv.ParseFloat(p[:len(p)-2], 64)
+				period += v
+			}
+			if math.Mod(20, period) == 0 {
+				periodsPer20ms := int(20 / period)
+				var patPerPeriod []string
+				for i := range flags.tdduldl.patPeriod {
+					for j := 0; j < flags.tdduldl.patNumDlSlots[i]*rgd.symbPerSlot+flags.tdduldl.patNumDlSymbs[i]; j++ {
+						patPerPeriod = append(patPerPeriod, "D")
+					}
+					for j := 0; j < rgd.symbPerSlot-flags.tdduldl.patNumDlSymbs[i]-flags.tdduldl.patNumUlSymbs[i]; j++ {
+						patPerPeriod = append(patPerPeriod, "F")
+					}
+					for j := 0; j < flags.tdduldl.patNumUlSymbs[i]+flags.tdduldl.patNumUlSlots[i]*rgd.symbPerSlot; j++ {
+						patPerPeriod = append(patPerPeriod, "U")
+					}
+				}
+
+				var patPer20ms []string
+				for i := 0; i < periodsPer20ms; i++ {
+					patPer20ms = append(patPer20ms, patPerPeriod...)
+				}
+
+				rgd.tddPatEvenRf = patPer20ms[:rgd.symbPerRf]
+				rgd.tddPatOddRf = patPer20ms[rgd.symbPerRf:]
+				rgd.tddNumSlots = len(patPerPeriod) / rgd.symbPerSlot
+				fmt.Printf("tddPatEvenRf=%v\n", rgd.tddPatEvenRf)
+				fmt.Printf("tddPatOddRf =%v\n", rgd.tddPatOddRf)
+			} else {
+				return errors.New(fmt.Sprintf("Invalid TDD-UL-DL-Config periodicity(=%v), which should divide 20m.", flags.tdduldl.patPeriod))
+			}
+		} else {
+			return errors.New(fmt.Sprintf("Invalid TDD-UL-DL-Config: tdduldl.patPeriod=%v.", flags.tdduldl.patPeriod))
+		}
+
+		rgd.gridTdd = make(map[int]DataPerRf)
+		rgd.tddPat = make(map[int][]string)
+		rgd.sfConflicts = nil
+	} else {
+		rgd.gridFddUl = make(map[int]DataPerRf)
+		rgd.gridFddDl = make(map[int]DataPerRf)
+	}
+	rgd.gridSup = make(map[int]DataPerRf)
+	if len(flags.gridsetting.supBand) > 0 {
+		rgd.supSlotPerRf = int(math.Exp2(float64(nrgrid.Scs2Mu[flags.gridsetting._supScs]))) * rgd.subfPerRf
+		rgd.supScPerSymb = rgd.scPerRb * flags.gridsetting._supCarrierNumRbs
+		rgd.supScPerSlot = rgd.supScPerSymb * rgd.symbPerSlot
+		rgd.supScPerRf = rgd.supScPerSlot * rgd.supSlotPerRf
+	}
+
+	// SCells of carrier aggregation
+	initScellData()
+
+	// additional dedicated BWPs
+	initBwpData()
+
+	rgd.trSsb = make(map[int]bool)
+	rgd.ssbSymbs = make(map[int][]int)
+
+	// first symbols of SSB
+	var s1, s3 []int
+	var s2 int
+	if flags.gridsetting._ssbPattern == "Case A" && flags.gridsetting._ssbScs == "15KHz" {
+		s1 = []int{2, 8}
+		s2 = 14
+		if !flags.gridsetting._unlicensed {
+			if flags.gridsetting._maxDlFreq <= 3000 {
+				s3 = []int{0, 1}
+			} else {
+				s3 = []int{0, 1, 2, 3}
+			}
+		} else {
+			s3 = []int{0, 1, 2, 3, 4}
+		}
+	} else if flags.gridsetting._ssbPattern == "Case B" && flags.gridsetting._ssbScs == "30KHz" {
+		s1 = []int{4, 8, 16, 20}
+		s2 = 28
+		if flags.gridsetting._maxDlFreq <= 3000 {
+			s3 = []int{0}
+		} else {
+			s3 = []int{0, 1}
+		}
+	} else if flags.gridsetting._ssbPattern == "Case C" && flags.gridsetting._ssbScs == "30KHz" {
+		s1 = []int{2, 8}
+		s2 = 14
+		if !flags.gridsetting._unlicensed {
+			if flags.gridsetting._duplexMode == "FDD" {
+				if flags.gridsetting._maxDlFreq <= 3000 {
+					s3 = []int{0, 1}
+				} else {
+					s3 = []int{0, 1, 2, 3}
+				}
+			} else if flags.gridsetting._duplexMode == "TDD" {
+				if flags.gridsetting._maxDlFreq < 1880 {
+					s3 = []int{0, 1}
+				} else {
+					s3 = []int{0, 1, 2, 3}
+				}
+			}
+		} else {
+			s3 = []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+		}
+	} else if flags.gridsetting._ssbPattern == "Case D" && flags.gridsetting._ssbScs == "120KHz" {
+		s1 = []int{4, 8, 16, 20}
+		s2 = 28
+		s3 = []int{0, 1, 2, 3, 5, 6, 7, 8, 10, 11, 12, 13, 15, 16, 17, 18}
+	} else if flags.gridsetting._ssbPattern == "Case E" && flags.gridsetting._ssbScs == "240KHz" {
+		s1 = []int{8, 12, 16, 20, 32, 36, 40, 44}
+		s2 = 56
+		s3 = []int{0, 1, 2, 3, 5, 6, 7, 8}
+	} else if ((flags.gridsetting._ssbPattern == "Case F" && flags.gridsetting._ssbScs == "480KHz") || (flags.gridsetting._ssbPattern == "Case G" && flags.gridsetting._ssbScs == "960KHz")) && flags.gridsetting._freqRange == "FR2-2" {
+		s1 = []int{2, 9}
+		s2 = 14
+		s3 = utils.PyRange(0, 32, 1)
+	}
+
+	for _, i := range s1 {
+		for _, j := range s3 {
+			rgd.ssbFirstSymbs = append(rgd.ssbFirstSymbs, i+s2*j)
+		}
+	}
+	sort.Ints(rgd.ssbFirstSymbs)
+	fmt.Printf("ssbFirstSymbs: %v\n", rgd.ssbFirstSymbs)
+	rgd.ssbCands = getSsbCands()
+	fmt.Printf("ssbCands: %v\n", rgd.ssbCands)
+
+	// first subcarrier of SSB/CORESET0
+	rmsiScs, _ := strconv.Atoi(flags.gridsetting._mibCommonScs[:len(flags.gridsetting._mibCommonScs)-3])
+	rgd.ssbSc0Rb0 = (flags.gridsetting._nCrbSsb*12*int(flags.gridsetting._nCrbSsbScs)+flags.gridsetting._kSsb*int(flags.gridsetting._kSsbScs))/rmsiScs - flags.gridsetting._offsetToCarrier*12
+	rgd.coreset0Sc0Rb0 = rgd.ssbSc0Rb0 - flags.gridsetting._coreset0Offset*12
+	fmt.Printf("offsetToCarrier=%v, nCrbSsb=%v(SCS=%.0fKHz), kSsb=%v(SCS=%.0fKHz) -> ssbSc0Rb0=%v\n", flags.gridsetting._offsetToCarrier, flags.gridsetting._nCrbSsb, flags.gridsetting._nCrbSsbScs, flags.gridsetting._kSsb, flags.gridsetting._kSsbScs, rgd.ssbSc0Rb0)
+	fmt.Printf("coreset0Offset=%v -> coreset0Sc0Rb0=%v\n", flags.gridsetting._coreset0Offset, rgd.coreset0Sc0Rb0)
+
+	// CORESET0
+	rgd.coreset0NumCces = flags.gridsetting._coreset0NumSymbs * flags.gridsetting._coreset0NumRbs / 6
+	if flags.gridsetting._css0AggLevel > rgd.coreset0NumCces {
+		return errors.New(fmt.Sprintf("Invalid configurations of CSS0/CORESET0: aggregation level=%v while total number of CCEs=%v!", flags.gridsetting._css0AggLevel, rgd.coreset0NumCces))
+	}
+
+	var err error
+	rgd.coreset0RegBundles, rgd.coreset0Cces, err = coresetCceRegMapping("CORESET0", flags.gridsetting._coreset0NumRbs, flags.gridsetting._coreset0NumSymbs, true, 6, 2, flags.gridsetting.pci)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("CORESET0 CCE-to-REG mapping:\nregBundles=%v\ncces      =%v\n", rgd.coreset0RegBundles, rgd.coreset0Cces)
+
+	rgd.css0TdOccasions = make(map[string][]nrgrid.Css0OccasionTd)
+	rgd.occCss0 = make(map[int]bool)
+	rgd.trPdcchSib1 = make(map[int]bool)
+	rgd.css0PdcchCandidates = make(map[string][]nrgrid.Css0PdcchCandidate)
+	rgd.trSib1 = make(map[int]bool)
+	rgd.trCsi = make(map[int]bool)
+	rgd.trSrs = make(map[int]bool)
+	rgd.trPucch = make(map[int]bool)
+	rgd.sib1Loc = make(map[string][]int)
+	rgd.trOsi = make(map[int]bool)
+	rgd.trLteCrs = make(map[int]bool)
+	rgd.trMeasGap = make(map[int]bool)
+	rgd.trPrs = make(map[int]bool)
+	rgd.trSfi = make(map[int]bool)
+	rgd.measGapLost = nil
+	rgd.drx = nil
+	rgd.bsw = nil
+
+	// CORESET1
+	rgd.coreset1NumCces = flags.searchspace._coreset1Duration * flags.searchspace.coreset1NumRbs / 6
+	L, _ := strconv.Atoi(flags.searchspace.coreset1RegBundleSize[1:])
+	R, _ := strconv.Atoi(flags.searchspace.coreset1InterleaverSize[1:])
+	rgd.coreset1RegBundles, rgd.coreset1Cces, err = coresetCceRegMapping("CORESET1", flags.searchspace.coreset1NumRbs, flags.searchspace._coreset1Duration, flags.searchspace.coreset1CceRegMappingType == "interleaved", L, R, flags.searchspace._coreset1ShiftIndex)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("CORESET1 CCE-to-REG mapping:\nregBundles=%v\ncces      =%v\n", rgd.coreset1RegBundles, rgd.coreset1Cces)
+
+	var vrbBundles, prbBundles []int
+	// interleaved VRB-to-PRB mapping for DCI 1_0 with SI-RNTI in Type0 CSS
+	L, _ = strconv.Atoi(flags.dldci.fdBundleSize[DCI_10_SIB1][1:])
+	vrbBundles, prbBundles, rgd.dci10Sib1Prbs = pdschVrbPrbMapping(flags.gridsetting._coreset0NumRbs, 0, 0, L)
+	fmt.Printf("DCI_10_SIB1 VRB-to-PRB mapping:\nvrbBundles=%v\nprbBundles=%v\nprbs=%v\n", vrbBundles, prbBundles, rgd.dci10Sib1Prbs)
+
+	// interleaved VRB-to-PRB mapping for DCI 1_0 other than Type0 CSS
+	L, _ = strconv.Atoi(flags.dldci.fdBundleSize[DCI_10_MSG2][1:])
+	vrbBundles, prbBundles, rgd.dci10Msg2Prbs = pdschVrbPrbMapping(flags.gridsetting._coreset0NumRbs, 0, 0, L)
+	fmt.Printf("DCI_10_MSG2 VRB-to-PRB mapping:\nvrbBundles=%v\nprbBundles=%v\nprbs=%v\n", vrbBundles, prbBundles, rgd.dci10Msg2Prbs)
+
+	L, _ = strconv.Atoi(flags.dldci.fdBundleSize[DCI_10_MSG4][1:])
+	vrbBundles, prbBundles, rgd.dci10Msg4Prbs = pdschVrbPrbMapping(flags.gridsetting._coreset0NumRbs, 0, 0, L)
+	fmt.Printf("DCI_10_MSG4 VRB-to-PRB mapping:\nvrbBundles=%v\nprbBundles=%v\nprbs=%v\n", vrbBundles, prbBundles, rgd.dci10Msg4Prbs)
+
+	if flags.rach.raType == "2-step" {
+		if err := validateDlDciEntry(DCI_10_MSGB); err != nil {
+			return err
+		}
+		L, _ = strconv.Atoi(flags.dldci.fdBundleSize[DCI_10_MSGB][1:])
+		vrbBundles, prbBundles, rgd.dci10MsgBPrbs = pdschVrbPrbMapping(flags.gridsetting._coreset0NumRbs, 0, 0, L)
+		fmt.Printf("DCI_10_MSGB VRB-to-PRB mapping:\nvrbBundles=%v\nprbBundles=%v\nprbs=%v\n", vrbBundles, prbBundles, rgd.dci10MsgBPrbs)
+	}
+
+	if flags.paging.pagedUe {
+		if err := validateDlDciEntry(DCI_10_PAGING); err != nil {
+			return err
+		}
+		L, _ = strconv.Atoi(flags.dldci.fdBundleSize[DCI_10_PAGING][1:])
+		vrbBundles, prbBundles, rgd.dci10PagingPrbs = pdschVrbPrbMapping(flags.gridsetting._coreset0NumRbs, 0, 0, L)
+		fmt.Printf("DCI_10_PAGING VRB-to-PRB mapping:\nvrbBundles=%v\nprbBundles=%v\nprbs=%v\n", vrbBundles, prbBundles, rgd.dci10PagingPrbs)
+	}
+
+	if len(flags.osi.siPeriodicity) > 0 {
+		if err := validateDlDciEntry(DCI_10_OSI); err != nil {
+			return err
+		}
+		L, _ = strconv.Atoi(flags.dldci.fdBundleSize[DCI_10_OSI][1:])
+		vrbBundles, prbBundles, rgd.dci10OsiPrbs = pdschVrbPrbMapping(flags.gridsetting._coreset0NumRbs, 0, 0, L)
+		fmt.Printf("DCI_10_OSI VRB-to-PRB mapping:\nvrbBundles=%v\nprbBundles=%v\nprbs=%v\n", vrbBundles, prbBundles, rgd.dci10OsiPrbs)
+	}
+
+	// interleaved VRB-to-PRB mapping for DCI 1_1
+	L, _ = strconv.Atoi(flags.dldci.fdBundleSize[DCI_11_PDSCH][1:])
+	pdschBwpStart, pdschBwpSize := getPdschBwp()
+	vrbBundles, prbBundles, rgd.dci11Prbs = pdschVrbPrbMapping(pdschBwpSize, pdschBwpStart, 0, L)
+	fmt.Printf("DCI_11_PDSCSH VRB-to-PRB mapping:\nvrbBundles=%v\nprbBundles=%v\nprbs=%v\n", vrbBundles, prbBundles, rgd.dci11Prbs)
+
+	// TD/FD pattern of DMRS for PDSCH scheduled by DCI 1_0, which is determined by validatePdsch but not saved in config
+	for _, i := range []int{DMRS_DCI_10_SIB1, DMRS_DCI_10_MSG2, DMRS_DCI_10_MSG4, DMRS_DCI_10_MSGB, DMRS_DCI_10_PAGING, DMRS_DCI_10_OSI} {
+		if (i == DMRS_DCI_10_MSGB && flags.rach.raType != "2-step") || (i == DMRS_DCI_10_PAGING && !flags.paging.pagedUe) || (i == DMRS_DCI_10_OSI && len(flags.osi.siPeriodicity) == 0) {
+			continue
+		}
+		if err := validateDmrsCommonEntry(i); err != nil {
+			return err
+		}
+		if len(flags.dmrsCommon._tdL[i]) == 0 {
+			flags.dmrsCommon._tdL[i], flags.dmrsCommon._fdK[i] = getDmrsPdschTdFdPattern(flags.dmrsCommon._dmrsType[i], flags.dldci._tdMappingType[i], flags.dldci._tdStartSymb[i], flags.dldci._tdNumSymbs[i], flags.dmrsCommon._numFrontLoadSymbs[i], flags.dmrsCommon._dmrsAddPos[i], flags.dmrsCommon._cdmGroupsWoData[i], flags.bwp._bwpCp[INI_DL_BWP])
+			fmt.Printf("TD pattern within a slot of DMRS for %v: %v\n", flags.dmrsCommon._tag[i], flags.dmrsCommon._tdL[i])
+			fmt.Printf("FD pattern within a PRB of DMRS for %v: %v\n", flags.dmrsCommon._tag[i], flags.dmrsCommon._fdK[i])
+		}
+	}
+
+	// TD/FD pattern of DMRS for Msg3 PUSCH scheduled by RAR UL grant or fallbackRAR UL grant, which is determined by validatePusch but not saved in config
+	if len(flags.dmrsCommon._tdL[DMRS_RAR_UL_MSG3]) == 0 {
+		j := msg3UlGrantIdx()
+		flags.dmrsCommon._cdmGroupsWoData[DMRS_RAR_UL_MSG3], flags.dmrsCommon._dmrsAddPos[DMRS_RAR_UL_MSG3] = getMsg3DmrsCfg(j)
+		flags.dmrsCommon._tdL[DMRS_RAR_UL_MSG3], flags.dmrsCommon._tdL2, flags.dmrsCommon._fdK[DMRS_RAR_UL_MSG3] = getDmrsPuschTdFdPattern("type1", flags.uldci._tdMappingType[j], flags.uldci._tdStartSymb[j], flags.uldci._tdNumSymbs[j], 1, flags.dmrsCommon._dmrsAddPos[DMRS_RAR_UL_MSG3], flags.dmrsCommon._cdmGroupsWoData[DMRS_RAR_UL_MSG3], flags.uldci.fdFreqHop[j], flags.bwp._bwpCp[INI_UL_BWP])
+		fmt.Printf("TD pattern within a slot of DMRS for %v: %v\n", flags.dmrsCommon._tag[DMRS_RAR_UL_MSG3], flags.dmrsCommon._tdL[DMRS_RAR_UL_MSG3])
+		fmt.Printf("TD pattern within a slot of DMRS for %v (2nd hop): %v\n", flags.dmrsCommon._tag[DMRS_RAR_UL_MSG3], flags.dmrsCommon._tdL2)
+		fmt.Printf("FD pattern within a PRB of DMRS for %v: %v\n", flags.dmrsCommon._tag[DMRS_RAR_UL_MSG3], flags.dmrsCommon._fdK[DMRS_RAR_UL_MSG3])
+	}
+
+	// TD/FD pattern of DMRS for PDSCH scheduled by DCI 1_1, which is determined by validateDci11PdschAntPorts but not saved in config
+	if len(flags.pdsch._tdL) == 0 {
+		flags.pdsch._tdL, flags.pdsch._fdK = getDmrsPdschTdFdPattern(flags.pdsch.pdschDmrsType, flags.dldci._tdMappingType[DCI_11_PDSCH], flags.dldci._tdStartSymb[DCI_11_PDSCH], flags.dldci._tdNumSymbs[DCI_11_PDSCH], flags.pdsch._numFrontLoadSymbs, flags.pdsch.pdschDmrsAddPos, flags.pdsch._cdmGroupsWoData, flags.bwp._bwpCp[DED_DL_BWP])
+		fmt.Printf("TD pattern within a slot of DMRS for PDSCH(DCI 1_1): %v\n", flags.pdsch._tdL)
+		fmt.Printf("FD pattern within a PRB of DMRS for PDSCH(DCI 1_1): %v\n", flags.pdsch._fdK)
+	}
+
+	// TD/FD pattern of DMRS for PUSCH scheduled by DCI 0_1, which is determined by validateDci01PuschAntPorts but not saved in config
+	if len(flags.pusch._tdL) == 0 {
+		flags.pusch._tdL, flags.pusch._tdL2, flags.pusch._fdK = getDmrsPuschTdFdPattern(flags.pusch.puschDmrsType, flags.uldci._tdMappingType[DCI_01_PUSCH], flags.uldci._tdStartSymb[DCI_01_PUSCH], flags.uldci._tdNumSymbs[DCI_01_PUSCH], flags.pusch._numFrontLoadSymbs, flags.pusch.puschDmrsAddPos, flags.pusch._cdmGroupsWoData, flags.uldci.fdFreqHop[DCI_01_PUSCH], flags.bwp._bwpCp[DED_UL_BWP])
+		if flags.uldci.fdFreqHop[DCI_01_PUSCH] != "intra-slot" {
+			fmt.Printf("TD pattern within a slot of DMRS for PUSCH (DCI 0_1): %v\n", flags.pusch._tdL)
+		} else {
+			fmt.Printf("TD pattern within a slot of DMRS for PUSCH (DCI 0_1) (1st hop): %v\n", flags.pusch._tdL)
+			fmt.Printf("TD pattern within a slot of DMRS for PUSCH (DCI 0_1) (2nd hop): %v\n", flags.pusch._tdL2)
+		}
+		fmt.Printf("FD pattern within a PRB of DMRS for PUSCH (DCI 0_1): %v\n", flags.pusch._fdK)
+	}
+
+	// dedicated PUCCH transmissions
+	rgd.pucchTr = make(map[string]*PucchTrInfo)
+	rgd.pucchSlots = make(map[int]string)
+
+	rgd.msg4Recved = false
+	rgd.msg4Slot = -1
+	rgd.resMap = make(map[int]nrgrid.NrResExt)
+
+	return nil
+}
+
+// CCE-to-REG mapping of CORESET
+func coresetCceRegMapping(coreset string, numRbs int, numSymbs int, interlaved bool, L int, R int, nShift int) ([]nrgrid.RegInfo, []int, error) {
+	if !utils.ContainsStr([]string{"CORESET0", "CORESET1"}, coreset) {
+		return nil, nil, errors.New(fmt.Sprintf("coresetCceRegMapping: Only CORESET0 and CORESET1 are supported."))
+	}
+
+	if !interlaved && L != 6 {
+		return nil, nil, errors.New(fmt.Sprintf("coresetCceRegMapping: L should equal 6 for non-interleaved mapping."))
+	}
+
+	if interlaved {
+		if (numSymbs == 1 && !utils.ContainsInt([]int{2, 6}, L)) || (utils.ContainsInt([]int{2, 3}, numSymbs) && !utils.ContainsInt([]int{numSymbs, 6}, L)) {
+			return nil, nil, errors.New(fmt.Sprintf("coresetCceRegMapping: For interleaved CCE-to-REG mapping, L = 2/6 for N_CORESTE_symb=1 and L=N_CORESET_symb/6 for N_CORESET_symb=2/3."))
+		}
+		if (numRbs*numSymbs)%(L*R) != 0 {
+			return nil, nil, errors.New(fmt.Sprintf("coresetCceRegMapping: N_CORESET_REG should be multiples of L*R."))
+		}
+	}
+
+	// indexing REGs
+	// refer to 3GPP 38.211 vh40
+	// 7.3.2.2	Control-resource set (CORESET)
+	// Resource-element groups within a control-resource set are numbered in increasing order in a time-first manner, starting with 0 for the first OFDM symbol and the lowest-numbered resource block in the control resource set.
+	numRegs := numRbs * numSymbs
+	regBundles := make([]nrgrid.RegInfo, numRegs)
+	count := 0
+	for i := 0; i < numRbs; i++ {
+		for j := 0; j < numSymbs; j++ {
+			regBundles[i*numSymbs+j] = nrgrid.RegInfo{Id: count, Isymb: j, Irb: i}
+			count++
+		}
+	}
+
+	// indexing REG bundles
+	numRegBundles := numRegs / L
+	for i := 0; i < numRbs; i++ {
+		for j := 0; j < numSymbs; j++ {
+			regBundles[i*numSymbs+j].Id /= L
+		}
+	}
+
+	// indexing CCEs
+	numCces := numRegs / 6
+	numRegBundlesPerCce := 6 / L
+	cces := make([]int, numRegs)
+	for i := 0; i < numCces; i++ {
+		var regBundlesSet []int
+		for j := 0; j < numRegBundlesPerCce; j++ {
+			regBundlesSet = append(regBundlesSet, numRegBundlesPerCce*i+j)
+		}
+
+		if interlaved {
+			C := numRegs / (L * R)
+			for j := range regBundlesSet {
+				x := regBundlesSet[j]
+				c := x / R
+				r := x % R
+				regBundlesSet[j] = (r*C + c + nShift) % numRegBundles
+			}
+		}
+
+		for j := 0; j < numRbs; j++ {
+			for k := 0; k < numSymbs; k++ {
+				if utils.ContainsInt(regBundlesSet, regBundles[j*numSymbs+k].Id) {
+					cces[j*numSymbs+k] = i
+				}
+			}
+		}
+	}
+
+	return regBundles, cces, nil
+}
+
+// DL VRB-to-PRB mapping
+//  - For PDSCH transmissions scheduled with DCI format 1_0 with the CRC scrambled by SI-RNTI in Type0-PDCCH common search space in CORESET 0: bwpStart=0 and coresetStart=0
+//  - For PDSCH transmissions scheduled with DCI format 1_0 in any common search space in bandwidth part i with starting position N_start_BWP,i, other than Type0-PDCCH common search space in CORESET 0
+//  - For all other PDSCH transmissions: coresetStart=0
+func pdschVrbPrbMapping(bwpSize int, bwpStart int, coresetStart int, L int) ([]int, []int, []int) {
+	numBundles := utils.CeilInt(float64(bwpSize+(bwpStart+coresetStart)%L) / float64(L))
+	var rbBundleSize []int
+	for i := 0; i < numBundles; i++ {
+		if i == 0 {
+			rbBundleSize = append(rbBundleSize, L-(bwpStart+coresetStart)%L)
+		} else if i == numBundles-1 {
+			if (bwpSize+bwpStart+coresetStart)%L > 0 {
+				rbBundleSize = append(rbBundleSize, (bwpSize+bwpStart+coresetStart)%L)
+			} else {
+				rbBundleSize = append(rbBundleSize, L)
+			}
+		} else {
+			rbBundleSize = append(rbBundleSize, L)
+		}
+	}
+
+	vrbBundles := utils.PyRange(0, numBundles, 1)
+	var prbBundles []int
+	for j := 0; j < numBundles; j++ {
+		if j == numBundles-1 {
+			prbBundles = append(prbBundles, j)
+		} else {
+			R := 2
+			C := utils.FloorInt(float64(numBundles) / float64(R))
+			c := j / R
+			r := j % R
+			prbBundles = append(prbBundles, r*C+c)
+		}
+	}
+
+	// indexing VRBs and PRBs
+	var prbs []int
+	for j := 0; j < numBundles; j++ {
+		for k := 0; k < rbBundleSize[j]; k++ {
+			prbs = append(prbs, utils.SumInt(rbBundleSize[:prbBundles[j]])+k)
+		}
+	}
+
+	return vrbBundles, prbBundles, prbs
+}
+
+func alwaysOnTr(sfn, slot int) error {
+	if err := aotCommon(sfn); err != nil {
+		return err
+	}
+
+	if rgd.msg4Recved {
+		if err := aotCsi(sfn, slot); err != nil {
+			return err
+		}
+		if err := aotPrs(sfn, slot); err != nil {
+			return err
+		}
+		// Note: measurement gaps are marked after CSI-RS/TRS and PRS, which are transmitted by the gNB regardless of measurement gaps of the UE.
+		if err := aotMeasGap(sfn, slot); err != nil {
+			return err
+		}
+		if err := aotSrs(sfn, slot); err != nil {
+			return err
+		}
+		if err := aotPucch(sfn, slot); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// aotCommon maps SSB, PDCCH of SIB1, PDCCH of DCI 2_0, LTE CRS(LTE-NR DSS), SIB1 and SI messages of radio frame sfn, which are transmitted regardless of whether Msg4 is received.
+func aotCommon(sfn int) error {
+	// init gridTdd or gridFddDl/gridFddUl if necessary
+	if flags.gridsetting._duplexMode == "TDD" {
+		initTddGrid(sfn)
+	} else {
+		initFddGrid(sfn)
+	}
+	if len(flags.gridsetting.supBand) > 0 {
+		initSupGrid(sfn)
+	}
+
+	if err := aotSsb(sfn); err != nil {
+		return err
+	}
+
+	if err := aotPdcchSib1(sfn); err != nil {
+		return err
+	}
+
+	if err := aotSfi(sfn); err != nil {
+		return err
+	}
+
+	// Note: LTE CRS is mapped before PDSCH of SIB1 and SI messages, so that PDSCH scheduled by DCI 1_0 can be rate matched around LTE CRS.
+	if err := aotLteCrs(sfn); err != nil {
+		return err
+	}
+
+	if err := aotSib1(sfn); err != nil {
+		return err
+	}
+
+	if err := aotOsi(sfn); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// aotSfi maps PDCCH of DCI 2_0 with SFI-RNTI in Type3-PDCCH CSS in each PDCCH monitoring occasion for DCI 2_0 of radio frame sfn.
+// Note: the slot format is determined assuming that DCI 2_0 is detected in every monitoring occasion starting from SFN 0(see getTddPat), hence DCI 2_0 which can't be mapped due to no available PDCCH candidate is only reported.
+func aotSfi(sfn int) error {
+	if flags.tdduldl.sfiPeriod == 0 || rgd.trSfi[sfn] {
+		return nil
+	}
+	rgd.trSfi[sfn] = true
+
+	iss := utils.IndexStr(flags.searchspace._ssType, "type3")
+	for slot := 0; slot < rgd.slotPerRf; slot++ {
+		if (sfn*rgd.slotPerRf+slot-flags.tdduldl.sfiOffset)%flags.tdduldl.sfiPeriod != 0 {
+			continue
+		}
+
+		cces, err := mapUssPdcch(getDlGrid(sfn), "", sfn, slot, rgd.symbPerSlot, rgd.scPerSymb, flags.searchspace.coreset1StartCrb*rgd.scPerRb, iss, 0, "DCI 2_0", "SFI-RNTI")
+		if err != nil {
+			return err
+		}
+		if cces == nil {
+			regYellow.Printf("PDCCH(DCI 2_0, SFI-RNTI)@[sfn=%v, slot=%v] is not mapped due to no available PDCCH candidate.\n", sfn, slot)
+		}
+	}
+
+	return nil
+}
+
+func initTddGrid(sfn int) {
+	_, exist := rgd.gridTdd[sfn]
+	if !exist {
+		rgd.gridTdd[sfn] = DataPerRf{res: make([]int, rgd.scPerRf), tags: make([]mapset.Set, rgd.slotPerRf), beams: make(map[int]int)}
+		pat := getTddPat(sfn)
+		for i := 0; i < rgd.scPerRf; i++ {
+			rgd.gridTdd[sfn].res[i] = map[string]int{"D": NR_RES_D, "U": NR_RES_U, "F": NR_RES_F}[pat[i/rgd.scPerSymb]]
+		}
+
+		rgd.trSsb[sfn] = false
+		rgd.occCss0[sfn] = false
+		rgd.trPdcchSib1[sfn] = false
+		rgd.trSib1[sfn] = false
+		rgd.trOsi[sfn] = false
+		rgd.trLteCrs[sfn] = false
+		rgd.trCsi[sfn] = false
+		rgd.trSrs[sfn] = false
+		rgd.trPucch[sfn] = false
+		rgd.trMeasGap[sfn] = false
+		rgd.trPrs[sfn] = false
+		rgd.trSfi[sfn] = false
+	}
+}
+
+func initFddGrid(sfn int) {
+	_, exist := rgd.gridFddDl[sfn]
+	if !exist {
+		rgd.gridFddDl[sfn] = DataPerRf{res: make([]int, rgd.scPerRf), tags: make([]mapset.Set, rgd.slotPerRf), beams: make(map[int]int)}
+		rgd.gridFddUl[sfn] = DataPerRf{res: make([]int, rgd.scPerRf), tags: make([]mapset.Set, rgd.slotPerRf), beams: make(map[int]int)}
+		for i := 0; i < rgd.scPerRf; i++ {
+			rgd.gridFddDl[sfn].res[i] = NR_RES_D
+			rgd.gridFddUl[sfn].res[i] = NR_RES_U
+		}
+
+		rgd.trSsb[sfn] = false
+		rgd.occCss0[sfn] = false
+		rgd.trPdcchSib1[sfn] = false
+		rgd.trSib1[sfn] = false
+		rgd.trOsi[sfn] = false
+		rgd.trLteCrs[sfn] = false
+		rgd.trCsi[sfn] = false
+		rgd.trSrs[sfn] = false
+		rgd.trPucch[sfn] = false
+		rgd.trMeasGap[sfn] = false
+		rgd.trPrs[sfn] = false
+		rgd.trSfi[sfn] = false
+	}
+}
+
+// initSupGrid initializes gridSup of radio frame sfn with the dimensions of the supplementary carrier.
+func initSupGrid(sfn int) {
+	_, exist := rgd.gridSup[sfn]
+	if !exist {
+		rgd.gridSup[sfn] = DataPerRf{res: make([]int, rgd.supScPerRf), tags: make([]mapset.Set, rgd.supSlotPerRf), beams: make(map[int]int)}
+		res := NR_RES_U
+		if flags.gridsetting._supDuplexMode == "SDL" {
+			res = NR_RES_D
+		}
+		for i := 0; i < rgd.supScPerRf; i++ {
+			rgd.gridSup[sfn].res[i] = res
+		}
+	}
+}
+
+// initScellData initializes the dimensions, TDD pattern and NR resource grids of each SCell.
+// Note: SCells use the same cyclic prefix as the PCell, and radio frames of all serving cells are aligned.
+func initScellData() {
+	rgd.scells = nil
+	for i := range flags.ca.scellIndex {
+		var sd ScellData
+		sd.slotPerRf = int(math.Exp2(float64(nrgrid.Scs2Mu[flags.ca.scellScs[i]]))) * rgd.subfPerRf
+		sd.scPerSymb = rgd.scPerRb * flags.ca._scellCarrierNumRbs[i]
+		sd.scPerSlot = sd.scPerSymb * rgd.symbPerSlot
+		sd.scPerRf = sd.scPerSlot * sd.slotPerRf
+
+		if flags.ca._scellDuplexMode[i] == "TDD" {
+			// Note: The flexible symbols are all within one slot, and the periodicity of SCell must divide 10ms, so each radio frame has the same pattern.
+			var patPerPeriod []string
+			for j := 0; j < flags.ca.scellPatNumDlSlots[i]*rgd.symbPerSlot+flags.ca.scellPatNumDlSymbs[i]; j++ {
+				patPerPeriod = append(patPerPeriod, "D")
+			}
+			for j := 0; j < rgd.symbPerSlot-flags.ca.scellPatNumDlSymbs[i]-flags.ca.scellPatNumUlSymbs[i]; j++ {
+				patPerPeriod = append(patPerPeriod, "F")
+			}
+			for j := 0; j < flags.ca.scellPatNumUlSymbs[i]+flags.ca.scellPatNumUlSlots[i]*rgd.symbPerSlot; j++ {
+				patPerPeriod = append(patPerPeriod, "U")
+			}
+			for len(sd.tddPat) < rgd.symbPerSlot*sd.slotPerRf {
+				sd.tddPat = append(sd.tddPat, patPerPeriod...)
+			}
+			fmt.Printf("tddPat(SCell%v)=%v\n", flags.ca.scellIndex[i], sd.tddPat)
+		} else {
+			sd.gridUl = make(map[int]DataPerRf)
+		}
+		sd.gridDl = make(map[int]DataPerRf)
+
+		rgd.scells = append(rgd.scells, sd)
+	}
+}
+
+// initScellGrid initializes the NR resource grids of SCell i of radio frame sfn.
+func initScellGrid(i, sfn int) {
+	sd := rgd.scells[i]
+	_, exist := sd.gridDl[sfn]
+	if !exist {
+		sd.gridDl[sfn] = DataPerRf{res: make([]int, sd.scPerRf), tags: make([]mapset.Set, sd.slotPerRf), beams: make(map[int]int)}
+		if sd.tddPat == nil {
+			sd.gridUl[sfn] = DataPerRf{res: make([]int, sd.scPerRf), tags: make([]mapset.Set, sd.slotPerRf), beams: make(map[int]int)}
+		}
+		for k := 0; k < sd.scPerRf; k++ {
+			if sd.tddPat != nil {
+				sd.gridDl[sfn].res[k] = map[string]int{"D": NR_RES_D, "U": NR_RES_U, "F": NR_RES_F}[sd.tddPat[k/sd.scPerSymb]]
+			} else {
+				sd.gridDl[sfn].res[k] = NR_RES_D
+				sd.gridUl[sfn].res[k] = NR_RES_U
+			}
+		}
+	}
+}
+
+// getScellGrid returns the NR resource grid of SCell i of radio frame sfn for the given direction(D or U).
+func getScellGrid(i, sfn int, dir string) DataPerRf {
+	initScellGrid(i, sfn)
+	if dir == "U" && rgd.scells[i].tddPat == nil {
+		return rgd.scells[i].gridUl[sfn]
+	}
+	return rgd.scells[i].gridDl[sfn]
+}
+
+// getScellGrids returns the link directions(TDD, DL or UL) and the corresponding NR resource grids of SCell i.
+func getScellGrids(i int) ([]string, []map[int]DataPerRf) {
+	if rgd.scells[i].tddPat != nil {
+		return []string{"TDD"}, []map[int]DataPerRf{rgd.scells[i].gridDl}
+	}
+	return []string{"DL", "UL"}, []map[int]DataPerRf{rgd.scells[i].gridDl, rgd.scells[i].gridUl}
+}
+
+// isScellSymbs returns whether all symbols in [firstSymb, firstSymb+numSymbs) of slot of SCell i are of the given direction(D or U), which is always true for FDD SCell.
+func isScellSymbs(i, slot, firstSymb, numSymbs int, dir string) bool {
+	if rgd.scells[i].tddPat == nil {
+		return true
+	}
+
+	for symb := firstSymb; symb < firstSymb+numSymbs; symb++ {
+		if rgd.scells[i].tddPat[slot*rgd.symbPerSlot+symb] != dir {
+			return false
+		}
+	}
+
+	return true
+}
+
+// initBwpData initializes the dimensions and NR resource grids of additional dedicated DL/UL BWPs.
+func initBwpData() {
+	rgd.bwps = nil
+	for _, dir := range []string{"DL", "UL"} {
+		ids, scs, cp, startRb, numRbs := flags.bwp.dlBwpId, flags.bwp.dlBwpScs, flags.bwp.dlBwpCp, flags.bwp.dlBwpStartRb, flags.bwp.dlBwpNumRbs
+		if dir == "UL" {
+			ids, scs, cp, startRb, numRbs = flags.bwp.ulBwpId, flags.bwp.ulBwpScs, flags.bwp.ulBwpCp, flags.bwp.ulBwpStartRb, flags.bwp.ulBwpNumRbs
+		}
+
+		for i, id := range ids {
+			bd := BwpData{id: id, dir: dir, cp: cp[i], startRb: startRb[i], numRbs: numRbs[i], grid: make(map[int]DataPerRf), pucchTr: make(map[int]*PucchTrInfo)}
+			bd.slotPerRf = int(math.Exp2(float64(nrgrid.Scs2Mu[scs[i]]))) * rgd.subfPerRf
+			bd.symbPerSlot = getSymbPerSlot(cp[i])
+			bd.scPerSymb = rgd.scPerRb * (startRb[i] + numRbs[i])
+			bd.scPerSlot = bd.scPerSymb * bd.symbPerSlot
+			bd.scPerRf = bd.scPerSlot * bd.slotPerRf
+
+			rgd.bwps = append(rgd.bwps, bd)
+		}
+	}
+}
+
+// getBwpIndex returns index of rgd.bwps of the additional dedicated BWP with the given link direction(DL or UL) and bwp-Id, which is -1 if not found.
+func getBwpIndex(dir string, id int) int {
+	for j, bd := range rgd.bwps {
+		if bd.dir == dir && bd.id == id {
+			return j
+		}
+	}
+
+	return -1
+}
+
+// getBwpSymbDir returns the direction(D, U or F) of symbol symb(=slot*symbPerSlot+symb) in radio frame sfn of additional dedicated BWP j.
+// For TDD, the symbol of the BWP is D or U only if all the overlapping symbols of the carrier are D or U according to the slot format of the carrier, otherwise it's F.
+func getBwpSymbDir(j, sfn, symb int) string {
+	bd := rgd.bwps[j]
+	if flags.gridsetting._duplexMode != "TDD" {
+		return map[string]string{"DL": "D", "UL": "U"}[bd.dir]
+	}
+
+	pat := getTddPat(sfn)
+	// [first, last] symbols of the carrier overlapping with the symbol of the BWP
+	symbPerRf := bd.symbPerSlot * bd.slotPerRf
+	first := symb * rgd.symbPerRf / symbPerRf
+	last := ((symb+1)*rgd.symbPerRf+symbPerRf-1)/symbPerRf - 1
+	for k := first + 1; k <= last; k++ {
+		if pat[k] != pat[first] {
+			return "F"
+		}
+	}
+
+	return pat[first]
+}
+
+// getBwpGrid returns the NR resource grid of additional dedicated BWP j of radio frame sfn, where SSB and CSI-RS of the carrier are projected onto additional dedicated DL BWP when the grid is initialized.
+func getBwpGrid(j, sfn int) DataPerRf {
+	bd := rgd.bwps[j]
+	_, exist := bd.grid[sfn]
+	if !exist {
+		bd.grid[sfn] = DataPerRf{res: make([]int, bd.scPerRf), tags: make([]mapset.Set, bd.slotPerRf), beams: make(map[int]int)}
+		for symb := 0; symb < bd.symbPerSlot*bd.slotPerRf; symb++ {
+			res := map[string]int{"D": NR_RES_D, "U": NR_RES_U, "F": NR_RES_F}[getBwpSymbDir(j, sfn, symb)]
+			for k := 0; k < bd.scPerSymb; k++ {
+				if k < bd.startRb*rgd.scPerRb {
+					bd.grid[sfn].res[symb*bd.scPerSymb+k] = NR_RES_GB
+				} else {
+					bd.grid[sfn].res[symb*bd.scPerSymb+k] = res
+				}
+			}
+		}
+
+		if bd.dir == "DL" {
+			projectBwpRes(j, sfn)
+		}
+	}
+
+	return bd.grid[sfn]
+}
+
+// projectBwpRes marks the free REs of additional dedicated DL BWP j of radio frame sfn which overlap in time and frequency with SSB or CSI-RS/CSI-IM of the carrier as the same NR resource of the carrier.
+// Note: SSB is transmitted regardless of the active BWP, and CSI-RS/CSI-IM of the dedicated DL BWP are assumed to be also configured on additional dedicated DL BWPs. Both the carrier and the BWP start from the same frequency as in validateBwpSw.
+func projectBwpRes(j, sfn int) {
+	bd := rgd.bwps[j]
+	cgrid := getDlGrid(sfn)
+	if cgrid.res == nil {
+		return
+	}
+
+	// subcarrier spacing of the BWP and the carrier in units of 15KHz
+	scsB, scsC := bd.slotPerRf/rgd.subfPerRf, rgd.slotPerSubf
+	symbPerRf := bd.symbPerSlot * bd.slotPerRf
+	for symb := 0; symb < symbPerRf; symb++ {
+		// [first, last] symbols of the carrier overlapping with the symbol of the BWP
+		first := symb * rgd.symbPerRf / symbPerRf
+		last := ((symb+1)*rgd.symbPerRf+symbPerRf-1)/symbPerRf - 1
+		for k := bd.startRb * rgd.scPerRb; k < bd.scPerSymb; k++ {
+			ire := symb*bd.scPerSymb + k
+			if bd.grid[sfn].res[ire] != NR_RES_D {
+				continue
+			}
+
+			// [c0, c1] subcarriers of the carrier overlapping with the subcarrier of the BWP
+			c0 := k * scsB / scsC
+			c1 := utils.MinInt([]int{((k+1)*scsB+scsC-1)/scsC - 1, rgd.scPerSymb - 1})
+			found := false
+			for a := first; a <= last && !found; a++ {
+				for c := c0; c <= c1 && !found; c++ {
+					res := cgrid.res[a*rgd.scPerSymb+c]
+					// Note: the unused subcarriers of PSS/SSS symbols are also projected, which belong to the SSB transmission resources.
+					if cat := resCategory(res); cat == "SSB" || cat == "CSI-RS" || cat == "TRS" || (res == NR_RES_DTX && c >= rgd.ssbSc0Rb0 && c < rgd.ssbSc0Rb0+240 && isSsbPrb(cgrid, a*rgd.scPerSymb, c)) {
+						bd.grid[sfn].res[ire] = res
+						if beam, exist := cgrid.beams[a*rgd.scPerSymb+c]; exist {
+							bd.grid[sfn].beams[ire] = beam
+						}
+						found = true
+					}
+				}
+			}
+		}
+	}
+}
+
+// isBwpSymbs returns whether all symbols in [firstSymb, firstSymb+numSymbs) of slot m(=sfn*slotPerRf+slot) of additional dedicated BWP j are of the given direction(D or U).
+func isBwpSymbs(j, m, firstSymb, numSymbs int, dir string) bool {
+	bd := rgd.bwps[j]
+	for symb := firstSymb; symb < firstSymb+numSymbs; symb++ {
+		if getBwpSymbDir(j, m/bd.slotPerRf, (m%bd.slotPerRf)*bd.symbPerSlot+symb) != dir {
+			return false
+		}
+	}
+
+	return true
+}
+
+func aotSsb(sfn int) error {
+	ssbPeriod, _ := strconv.Atoi(flags.gridsetting.ssbPeriod[:len(flags.gridsetting.ssbPeriod)-2])
+	if ssbPeriod >= 10 && (sfn-flags.gridsetting._sfn)%(ssbPeriod/10) != 0 {
+		// Note: aotCommon is called repeatedly for the same radio frame, so it's printed only once per radio frame.
+		if _, exist := rgd.ssbSymbs[sfn]; !exist {
+			fmt.Printf("No SSB transmission in current frame(sfn=%d)\n", sfn)
+			rgd.ssbSymbs[sfn] = []int{}
+		}
+		return nil
+	}
+
+	if rgd.trSsb[sfn] {
+		return nil
+	}
+
+	var ssbHrfSet []int
+	if ssbPeriod < 10 {
+		ssbHrfSet = []int{0, 1}
+	} else {
+		ssbHrfSet = []int{flags.gridsetting._hrf}
+	}
+	v := flags.gridsetting.pci % 4
+
+	for _, hrf := range ssbHrfSet {
+		for _, issb := range rgd.ssbCands {
+			ssbFirstSymb := hrf*(rgd.symbPerRf/2) + rgd.ssbFirstSymbs[issb]
+			fmt.Printf("[AOT @ SFN=%d] hrf=%d, issb=%d, ssbSc0Rb0=%d, v=%d, ssbFirstSymb=%d\n", hrf, sfn, issb, rgd.ssbSc0Rb0, v, ssbFirstSymb)
+
+			if flags.gridsetting._duplexMode == "TDD" {
+				// refer to 3GPP 38.213 vh40
+				// 11.1 Slot configurations
+				// For operation on a single carrier in unpaired spectrum, for a set of symbols of a slot indicated to a UE for reception of SS/PBCH blocks by ssb-PositionsInBurst in SIB1 or by ssb-PositionsInBurst in ServingCellConfigCommon or..., the UE does not transmit PUSCH, PUCCH, PRACH in the slot if a transmission would overlap with any symbol from the set of symbols and the UE does not transmit SRS in the set of symbols of the slot. The UE does not expect the set of symbols of the slot to be indicated as uplink by tdd-UL-DL-ConfigurationCommon, or tdd-UL-DL-ConfigurationDedicated, when provided to the UE.
+				for i := 0; i < 4; i++ {
+					if rgd.gridTdd[sfn].res[rgd.scPerSymb*(ssbFirstSymb+i)+rgd.ssbSc0Rb0] != NR_RES_D {
+						return errors.New(fmt.Sprintf("The UE does not expect the set of symbols of the slot which are used for SSB transmission(ssb index=%d, first symbol=%d) to be indicated as uplink by TDD-UL-DL-ConfigurationCommon.", issb, ssbFirstSymb+i))
+					}
+				}
+
+				// symbol 0: PSS
+				for i := 0; i < 240; i++ {
+					if i >= 56 && i <= 182 {
+						rgd.gridTdd[sfn].res[rgd.scPerSymb*ssbFirstSymb+rgd.ssbSc0Rb0+i] = NR_RES_PSS
+					} else {
+						rgd.gridTdd[sfn].res[rgd.scPerSymb*ssbFirstSymb+rgd.ssbSc0Rb0+i] = NR_RES_DTX
+					}
+				}
+
+				// symbol 1/3: PBCH
+				for i := 0; i < 240; i++ {
+					if i >= v && (i-v)%4 == 0 {
+						rgd.gridTdd[sfn].res[rgd.scPerSymb*(ssbFirstSymb+1)+rgd.ssbSc0Rb0+i] = NR_RES_DMRS_PBCH
+						rgd.gridTdd[sfn].res[rgd.scPerSymb*(ssbFirstSymb+3)+rgd.ssbSc0Rb0+i] = NR_RES_DMRS_PBCH
+					} else {
+						rgd.gridTdd[sfn].res[rgd.scPerSymb*(ssbFirstSymb+1)+rgd.ssbSc0Rb0+i] = NR_RES_PBCH
+						rgd.gridTdd[sfn].res[rgd.scPerSymb*(ssbFirstSymb+3)+rgd.ssbSc0Rb0+i] = NR_RES_PBCH
+					}
+				}
+
+				// symbol 2: PBCH and SSS
+				for i := 0; i < 240; i++ {
+					if i >= 56 && i <= 182 {
+						rgd.gridTdd[sfn].res[rgd.scPerSymb*(ssbFirstSymb+2)+rgd.ssbSc0Rb0+i] = NR_RES_SSS
+					} else if i <= 47 || i >= 192 {
+						if i >= v && (i-v)%4 == 0 {
+							rgd.gridTdd[sfn].res[rgd.scPerSymb*(ssbFirstSymb+2)+rgd.ssbSc0Rb0+i] = NR_RES_DMRS_PBCH
+						} else {
+							rgd.gridTdd[sfn].res[rgd.scPerSymb*(ssbFirstSymb+2)+rgd.ssbSc0Rb0+i] = NR_RES_PBCH
+						}
+					} else {
+						rgd.gridTdd[sfn].res[rgd.scPerSymb*(ssbFirstSymb+2)+rgd.ssbSc0Rb0+i] = NR_RES_DTX
+					}
+				}
+
+				if rgd.gridTdd[sfn].tags[ssbFirstSymb/rgd.symbPerSlot] == nil {
+					rgd.gridTdd[sfn].tags[ssbFirstSymb/rgd.symbPerSlot] = mapset.NewSet()
+				}
+				rgd.gridTdd[sfn].tags[ssbFirstSymb/rgd.symbPerSlot].Add("SSB")
+			} else {
+				// symbol 0: PSS
+				for i := 0; i < 240; i++ {
+					if i >= 56 && i <= 182 {
+						rgd.gridFddDl[sfn].res[rgd.scPerSymb*ssbFirstSymb+rgd.ssbSc0Rb0+i] = NR_RES_PSS
+					} else {
+						rgd.gridFddDl[sfn].res[rgd.scPerSymb*ssbFirstSymb+rgd.ssbSc0Rb0+i] = NR_RES_DTX
+					}
+				}
+
+				// symbol 1/3: PBCH
+				for i := 0; i < 240; i++ {
+					if i >= v && (i-v)%4 == 0 {
+						rgd.gridFddDl[sfn].res[rgd.scPerSymb*(ssbFirstSymb+1)+rgd.ssbSc0Rb0+i] = NR_RES_DMRS_PBCH
+						rgd.gridFddDl[sfn].res[rgd.scPerSymb*(ssbFirstSymb+3)+rgd.ssbSc0Rb0+i] = NR_RES_DMRS_PBCH
+					} else {
+						rgd.gridFddDl[sfn].res[rgd.scPerSymb*(ssbFirstSymb+1)+rgd.ssbSc0Rb0+i] = NR_RES_PBCH
+						rgd.gridFddDl[sfn].res[rgd.scPerSymb*(ssbFirstSymb+3)+rgd.ssbSc0Rb0+i] = NR_RES_PBCH
+					}
+				}
+
+				// symbol 2: PBCH and SSS
+				for i := 0; i < 240; i++ {
+					if i >= 56 && i <= 182 {
+						rgd.gridFddDl[sfn].res[rgd.scPerSymb*(ssbFirstSymb+2)+rgd.ssbSc0Rb0+i] = NR_RES_SSS
+					} else if i <= 47 || i >= 192 {
+						if i >= v && (i-v)%4 == 0 {
+							rgd.gridFddDl[sfn].res[rgd.scPerSymb*(ssbFirstSymb+2)+rgd.ssbSc0Rb0+i] = NR_RES_DMRS_PBCH
+						} else {
+							rgd.gridFddDl[sfn].res[rgd.scPerSymb*(ssbFirstSymb+2)+rgd.ssbSc0Rb0+i] = NR_RES_PBCH
+						}
+					} else {
+						rgd.gridFddDl[sfn].res[rgd.scPerSymb*(ssbFirstSymb+2)+rgd.ssbSc0Rb0+i] = NR_RES_DTX
+					}
+				}
+
+				if rgd.gridFddDl[sfn].tags[ssbFirstSymb/rgd.symbPerSlot] == nil {
+					rgd.gridFddDl[sfn].tags[ssbFirstSymb/rgd.symbPerSlot] = mapset.NewSet()
+				}
+				rgd.gridFddDl[sfn].tags[ssbFirstSymb/rgd.symbPerSlot].Add("SSB")
+			}
+
+			// REs of SSB(including DTX) are transmitted with the SSB beam
+			grid := getDlGrid(sfn)
+			for i := 0; i < 4; i++ {
+				for j := 0; j < 240; j++ {
+					grid.beams[rgd.scPerSymb*(ssbFirstSymb+i)+rgd.ssbSc0Rb0+j] = getSsbIndex(issb)
+				}
+			}
+
+			rgd.ssbSymbs[sfn] = append(rgd.ssbSymbs[sfn], []int{ssbFirstSymb, ssbFirstSymb + 1, ssbFirstSymb + 2, ssbFirstSymb + 3}...)
+		}
+	}
+
+	rgd.trSsb[sfn] = true
+
+	return nil
+}
+
+func aotPdcchSib1(sfn int) error {
+	// skip current SFN if no SSB is transmitted
+	if !rgd.trSsb[sfn] {
+		return nil
+	}
+
+	if rgd.trPdcchSib1[sfn] {
+		return nil
+	}
+
+	err := detCss0(sfn)
+	if err != nil {
+		return err
+	}
+
+	for _, issb := range rgd.ssbCands {
+		var ssbFirstSymbsMinus1 []int
+		if flags.gridsetting.ssbPeriod == "5ms" {
+			ssbFirstSymbsMinus1 = []int{rgd.ssbFirstSymbs[issb] - 1, rgd.symbPerRf/2 + rgd.ssbFirstSymbs[issb] - 1}
+		} else {
+			ssbFirstSymbsMinus1 = []int{flags.gridsetting._hrf*rgd.symbPerRf/2 + rgd.ssbFirstSymbs[issb] - 1}
+		}
+		fmt.Printf("issb=%v, ssbFirstSymbsMinus1=%v\n", issb, ssbFirstSymbsMinus1)
+
+		key := fmt.Sprintf("%v_%v", sfn, issb)
+		for _, td := range rgd.css0TdOccasions[key] {
+			sfnc := td.Sfn
+			nc := td.Slot
+			firstSymb := td.FirstSymb
+
+			// validation #1: PDCCH occasion should not start before corresponding SSB transmission!
+			if (sfnc-sfn)*rgd.symbPerRf+nc*rgd.symbPerSlot+firstSymb <= ssbFirstSymbsMinus1[0] {
+				continue
+			}
+
+			// validation #2/#3: Collision of SSB/CORESET0, and TDD-UL-DL-Config
+			// refer to 38.213 vh40
+			// 10	UE procedure for receiving control information
+			// If a UE monitors the PDCCH candidate for a Type0-PDCCH CSS set on the serving cell according to the procedure described in clause 13, the UE may assume that no SS/PBCH block is transmitted in REs used for monitoring the PDCCH candidate on the serving cell.
+			// 11.1	Slot configuration
+			// For a set of symbols of a slot indicated to a UE by pdcch-ConfigSIB1 in MIB for a CORESET for Type0-PDCCH CSS set, the UE does not expect the set of symbols to be indicated as uplink by tdd-UL-DL-ConfigurationCommon, or tdd-UL-DL-ConfigurationDedicated.
+			if flags.gridsetting._duplexMode == "TDD" {
+				valid := true
+				for i := 0; i < flags.gridsetting._coreset0NumSymbs; i++ {
+					for j := 0; j < flags.gridsetting._coreset0NumRbs*rgd.scPerRb; j++ {
+						if rgd.gridTdd[sfnc].res[rgd.scPerSymb*(nc*rgd.symbPerSlot+firstSymb+i)+rgd.coreset0Sc0Rb0+j] != NR_RES_D {
+							valid = false
+							break
+						}
+					}
+				}
+
+				if !valid {
+					continue
+				}
+			}
+
+			M, _ := strconv.Atoi(flags.gridsetting._css0NumCandidates[1:])
+			for m := 0; m < M; m++ {
+				cces, err := detCcesPerPdcchCand(0, flags.gridsetting._css0AggLevel, m, nc, "type0", 0, rgd.coreset0NumCces, 0, M)
+				if err != nil {
+					return err
+				}
+
+				rgd.css0PdcchCandidates[key] = append(rgd.css0PdcchCandidates[key], nrgrid.Css0PdcchCandidate{sfnc, nc, firstSymb, m, cces})
+				fmt.Printf("SSB/SIB1 PDCCH candidate: issb=%v, sfnc=%v, nc=%v, firstSymb=%v, m=%v, cces=%v\n", issb, sfnc, nc, firstSymb, m, cces)
+
+				for i, icce := range rgd.coreset0Cces {
+					if utils.ContainsInt(cces, icce) {
+						isymb := rgd.coreset0RegBundles[i].Isymb
+						irb := rgd.coreset0RegBundles[i].Irb
+						for isc := 0; isc < rgd.scPerRb; isc++ {
+							// refer to 3GPP 38.213 vh40
+							// 13	UE procedure for monitoring Type0-PDCCH CSS sets
+							// ... the UE assumes that the DM-RS antenna port associated with PDCCH receptions in the CORESET configured by pdcch-ConfigSIB1 in MIB, ... and the corresponding PDSCH receptions, and the corresponding SS/PBCH block are quasi co-located with respect to average gain, QCL-TypeA and QCL-TypeD properties
+							getDlGrid(sfnc).beams[nc*rgd.scPerSlot+(firstSymb+isymb)*rgd.scPerSymb+rgd.coreset0Sc0Rb0+irb*rgd.scPerRb+isc] = getSsbIndex(issb)
+
+							if flags.gridsetting._duplexMode == "TDD" {
+								if isc > 0 && (isc-1)%4 == 0 {
+									rgd.gridTdd[sfnc].res[nc*rgd.scPerSlot+(firstSymb+isymb)*rgd.scPerSymb+rgd.coreset0Sc0Rb0+irb*rgd.scPerRb+isc] = NR_RES_DMRS_PDCCH
+								} else {
+									rgd.gridTdd[sfnc].res[nc*rgd.scPerSlot+(firstSymb+isymb)*rgd.scPerSymb+rgd.coreset0Sc0Rb0+irb*rgd.scPerRb+isc] = NR_RES_PDCCH_CANDIDATE + m
+								}
+
+								if rgd.gridTdd[sfnc].tags[nc] == nil {
+									rgd.gridTdd[sfnc].tags[nc] = mapset.NewSet()
+								}
+								rgd.gridTdd[sfnc].tags[nc].Add("PDCCH")
+							} else {
+								if isc > 0 && (isc-1)%4 == 0 {
+									rgd.gridFddDl[sfnc].res[nc*rgd.scPerSlot+(firstSymb+isymb)*rgd.scPerSymb+rgd.coreset0Sc0Rb0+irb*rgd.scPerRb+isc] = NR_RES_DMRS_PDCCH
+								} else {
+									rgd.gridFddDl[sfnc].res[nc*rgd.scPerSlot+(firstSymb+isymb)*rgd.scPerSymb+rgd.coreset0Sc0Rb0+irb*rgd.scPerRb+isc] = NR_RES_PDCCH_CANDIDATE + m
+								}
+
+								if rgd.gridFddDl[sfnc].tags[nc] == nil {
+									rgd.gridFddDl[sfnc].tags[nc] = mapset.NewSet()
+								}
+								rgd.gridFddDl[sfnc].tags[nc].Add("PDCCH")
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	fmt.Printf("[SFN=%v, candSsbIndex=%v] css0PdcchCandidates=%v\n", sfn, flags.gridsetting.candSsbIndex, rgd.css0PdcchCandidates)
+	rgd.trPdcchSib1[sfn] = true
+
+	return nil
+}
 
 func detCss0(sfn int) error {
 	if rgd.occCss0[sfn] {
 		return nil
 	}
 
-	if flags.gridsetting._coreset0MultiplexingPat == 1 {
-		// refer to 3GPP 38.213 vh40
-		//  Table 13-11: Parameters for PDCCH monitoring occasions for Type0-PDCCH CSS set - SS/PBCH block and CORESET multiplexing pattern 1 and FR1
-		var css0OccasionsPat1Fr1 = map[int]*nrgrid.Css0OccasionPat1{
-			0:  {0, 1, 2, []int{0}},
-			1:  {0, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
-			2:  {16, 1, 2, []int{0}},
-			3:  {16, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
-			4:  {40, 1, 2, []int{0}},
-			5:  {40, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
-			6:  {56, 1, 2, []int{0}},
-			7:  {56, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
-			8:  {0, 1, 4, []int{0}},
-			9:  {40, 1, 4, []int{0}},
-			10: {0, 1, 2, []int{1}},
-			11: {0, 1, 2, []int{2}},
-			12: {16, 1, 2, []int{1}},
-			13: {16, 1, 2, []int{2}},
-			14: {40, 1, 2, []int{1}},
-			15: {40, 1, 2, []int{2}},
+	if flags.gridsetting._coreset0MultiplexingPat == 1 {
+		// refer to 3GPP 38.213 vh40
+		//  Table 13-11: Parameters for PDCCH monitoring occasions for Type0-PDCCH CSS set - SS/PBCH block and CORESET multiplexing pattern 1 and FR1
+		var css0OccasionsPat1Fr1 = map[int]*nrgrid.Css0OccasionPat1{
+			0:  {0, 1, 2, []int{0}},
+			1:  {0, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
+			2:  {16, 1, 2, []int{0}},
+			3:  {16, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
+			4:  {40, 1, 2, []int{0}},
+			5:  {40, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
+			6:  {56, 1, 2, []int{0}},
+			7:  {56, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
+			8:  {0, 1, 4, []int{0}},
+			9:  {40, 1, 4, []int{0}},
+			10: {0, 1, 2, []int{1}},
+			11: {0, 1, 2, []int{2}},
+			12: {16, 1, 2, []int{1}},
+			13: {16, 1, 2, []int{2}},
+			14: {40, 1, 2, []int{1}},
+			15: {40, 1, 2, []int{2}},
+		}
+
+		// refer to 3GPP 38.213 vh40
+		//  Table 13-12: Parameters for PDCCH monitoring occasions for Type0-PDCCH CSS set - SS/PBCH block and CORESET multiplexing pattern 1 and FR2-1, or SS/PBCH block and CORESET multiplexing pattern 1 and {SS/PBCH block, PDCCH} SCS {120, 120} kHz in FR2-2
+		var css0OccasionsPat1Fr21OrFr22Scs120 = map[int]*nrgrid.Css0OccasionPat1{
+			0:  {0, 1, 2, []int{0}},
+			1:  {0, 2, 1, []int{0, 7}},
+			2:  {20, 1, 2, []int{0}},
+			3:  {20, 2, 1, []int{0, 7}},
+			4:  {40, 1, 2, []int{0}},
+			5:  {40, 2, 1, []int{0, 7}},
+			6:  {0, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
+			7:  {20, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
+			8:  {40, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
+			9:  {60, 1, 2, []int{0}},
+			10: {60, 2, 1, []int{0, 7}},
+			11: {60, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
+			12: {0, 1, 4, []int{0}},
+			13: {40, 1, 4, []int{0}},
+			14: nil,
+			15: nil,
+		}
+
+		// refer to 3GPP 38.213 vh40
+		//  Table 13-12A: Parameters for PDCCH monitoring occasions for Type0-PDCCH CSS set - SS/PBCH block and CORESET multiplexing pattern 1 and {SS/PBCH block, PDCCH} SCS {480, 480} kHz or {960, 960} kHz in FR2-2
+		// For u=5(480KHz), X=1.25; For u=6(960KHz), X=0.625
+		var css0OccasionsPat1Fr22Scs480 = map[int]*nrgrid.Css0OccasionPat1{
+			0:  {0, 1, 2, []int{0}},
+			1:  {0, 2, 1, []int{0, 7}},
+			2:  {10, 1, 2, []int{0}},
+			3:  {10, 2, 1, []int{0, 7}},
+			4:  {40, 1, 2, []int{0}},
+			5:  {40, 2, 1, []int{0, 7}},
+			6:  {0, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
+			7:  {10, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
+			8:  {40, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
+			9:  {50, 1, 2, []int{0}},
+			10: {50, 2, 1, []int{0, 7}},
+			11: {50, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
+			12: {0, 1, 4, []int{0}},
+			13: {40, 1, 4, []int{0}},
+			14: nil,
+			15: nil,
+		}
+
+		// refer to 3GPP 38.213 vh40
+		//  Table 13-12A: Parameters for PDCCH monitoring occasions for Type0-PDCCH CSS set - SS/PBCH block and CORESET multiplexing pattern 1 and {SS/PBCH block, PDCCH} SCS {480, 480} kHz or {960, 960} kHz in FR2-2
+		// For u=5(480KHz), X=1.25; For u=6(960KHz), X=0.625
+		var css0OccasionsPat1Fr22Scs960 = map[int]*nrgrid.Css0OccasionPat1{
+			0:  {0, 1, 2, []int{0}},
+			1:  {0, 2, 1, []int{0, 7}},
+			2:  {5, 1, 2, []int{0}},
+			3:  {5, 2, 1, []int{0, 7}},
+			4:  {40, 1, 2, []int{0}},
+			5:  {40, 2, 1, []int{0, 7}},
+			6:  {0, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
+			7:  {10, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
+			8:  {40, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
+			9:  {45, 1, 2, []int{0}},
+			10: {45, 2, 1, []int{0, 7}},
+			11: {45, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
+			12: {0, 1, 4, []int{0}},
+			13: {40, 1, 4, []int{0}},
+			14: nil,
+			15: nil,
+		}
+
+		var p *nrgrid.Css0OccasionPat1
+		var exist bool
+		if flags.gridsetting._freqRange == "FR1" {
+			p, exist = css0OccasionsPat1Fr1[flags.gridsetting.rmsiCss0]
+			if !exist || p == nil {
+				return errors.New(fmt.Sprintf("Invalid key(=%v) when referring css0OccasionsPat1Fr1.", flags.gridsetting.rmsiCss0))
+			}
+		} else if flags.gridsetting._freqRange == "FR2-1" || (flags.gridsetting._freqRange == "FR2-2" && flags.gridsetting._ssbScs == "120KHz") {
+			// refer to 38.214 vh40
+			// 13	UE procedure for monitoring Type0-PDCCH CSS sets
+			// The SCS of the CORESET for Type0-PDCCH CSS set is provided by subCarrierSpacingCommon for FR1 and FR2-1 and same as the SCS of the corresponding SS/PBCH block for FR2-2.
+			p, exist = css0OccasionsPat1Fr21OrFr22Scs120[flags.gridsetting.rmsiCss0]
+			if !exist || p == nil {
+				return errors.New(fmt.Sprintf("Invalid key(=%v) when referring css0OccasionsPat1Fr21OrFr22Scs120.", flags.gridsetting.rmsiCss0))
+			}
+		} else if flags.gridsetting._freqRange == "FR2-2" && flags.gridsetting._ssbScs == "480KHz" {
+			p, exist = css0OccasionsPat1Fr22Scs480[flags.gridsetting.rmsiCss0]
+			if !exist || p == nil {
+				return errors.New(fmt.Sprintf("Invalid key(=%v) when referring css0OccasionsPat1Fr22Scs480.", flags.gridsetting.rmsiCss0))
+			}
+		} else if flags.gridsetting._freqRange == "FR2-2" && flags.gridsetting._ssbScs == "960KHz" {
+			p, exist = css0OccasionsPat1Fr22Scs960[flags.gridsetting.rmsiCss0]
+			if !exist || p == nil {
+				return errors.New(fmt.Sprintf("Invalid key(=%v) when referring css0OccasionsPat1Fr22Scs960.", flags.gridsetting.rmsiCss0))
+			}
+		}
+
+		O := float64(p.O8) / 8
+		M := float64(p.M2) / 2
+		var u int
+		if flags.gridsetting._freqRange == "FR1" || flags.gridsetting._freqRange == "FR2-1" {
+			u = nrgrid.Scs2Mu[flags.gridsetting._mibCommonScs]
+		} else if flags.gridsetting._freqRange == "FR2-2" {
+			u = nrgrid.Scs2Mu[flags.gridsetting._ssbScs]
+		}
+
+		// refer to 3GPP 38.213 vh40
+		// 13	UE procedure for monitoring Type0-PDCCH CSS sets
+		// For operation with shared spectrum channel access, ... i is the candidate SS/PBCH block index
+		for _, issb := range rgd.ssbCands {
+			v := O*math.Exp2(float64(u)) + math.Floor(float64(issb)*M)
+			n0 := int(v) % rgd.slotPerRf
+
+			v2 := utils.FloorInt(v / float64(rgd.slotPerRf))
+			sfnc := sfn
+			if !((v2%2 == 0 && sfnc%2 == 0) || (v2%2 == 1 && sfnc%2 == 1)) {
+				sfnc++
+				err := alwaysOnTr(sfnc, 0)
+				if err != nil {
+					return err
+				}
+			}
+
+			var firstSymb int
+			if len(p.FirstSymbs) == 2 {
+				firstSymb = p.FirstSymbs[issb%2]
+			} else {
+				firstSymb = p.FirstSymbs[0]
+			}
+
+			key := fmt.Sprintf("%v_%v", sfn, issb)
+			_, exist := rgd.css0TdOccasions[key]
+			if !exist {
+				offsets := []int{0}
+				if u < 4 {
+					offsets = append(offsets, 1)
+				} else if u == 5 {
+					offsets = append(offsets, 4)
+				} else if u == 6 {
+					offsets = append(offsets, 8)
+				}
+
+				// Note: the second monitoring slot may be in the next radio frame, e.g. n0 is the last slot of the radio frame.
+				for _, d := range offsets {
+					sfnd := sfnc + (n0+d)/rgd.slotPerRf
+					if sfnd != sfnc {
+						err := alwaysOnTr(sfnd, 0)
+						if err != nil {
+							return err
+						}
+					}
+					rgd.css0TdOccasions[key] = append(rgd.css0TdOccasions[key], nrgrid.Css0OccasionTd{sfnd, (n0 + d) % rgd.slotPerRf, firstSymb})
+				}
+			}
+		}
+	} else if flags.gridsetting._coreset0MultiplexingPat == 2 {
+		// refer to 38.213 vh40
+		// 13	UE procedure for monitoring Type0-PDCCH CSS sets
+		// Table 13-13: PDCCH monitoring occasions for Type0-PDCCH CSS set - SS/PBCH block and CORESET multiplexing pattern 2 and {SS/PBCH block, PDCCH} SCS {120, 60} kHz
+		// Table 13-14: PDCCH monitoring occasions for Type0-PDCCH CSS set - SS/PBCH block and CORESET multiplexing pattern 2 and {SS/PBCH block, PDCCH} SCS {240, 120} kHz
+		for _, issb := range rgd.ssbCands {
+			sfnc := sfn
+			nc := rgd.ssbFirstSymbs[issb] / rgd.symbPerSlot
+
+			var firstSymb int
+			if flags.gridsetting._ssbScs == "120KHz" && flags.gridsetting._mibCommonScs == "60KHz" {
+				firstSymb = []int{0, 1, 6, 7}[issb%4]
+			} else if flags.gridsetting._ssbScs == "240KHz" && flags.gridsetting._mibCommonScs == "120KHz" {
+				if issb%8 == 4 || issb%8 == 5 {
+					nc--
+					firstSymb = []int{12, 13}[issb%8-4]
+				} else {
+					if issb%8 < 4 {
+						firstSymb = issb % 8
+					} else if issb%8 > 5 {
+						firstSymb = issb%8 - 6
+					}
+				}
+			}
+
+			key := fmt.Sprintf("%v_%v", sfn, issb)
+			_, exist := rgd.css0TdOccasions[key]
+			if !exist {
+				rgd.css0TdOccasions[key] = append(rgd.css0TdOccasions[key], nrgrid.Css0OccasionTd{sfnc, nc, firstSymb})
+			}
+		}
+	} else {
+		// refer to 38.213 vh40
+		// 13	UE procedure for monitoring Type0-PDCCH CSS sets
+		// Table 13-15: PDCCH monitoring occasions for Type0-PDCCH CSS set - SS/PBCH block and CORESET multiplexing pattern 3 and {SS/PBCH block, PDCCH} SCS {120, 120} kHz
+		// Table 13-15A: PDCCH monitoring occasions for Type0-PDCCH CSS set - SS/PBCH block and CORESET multiplexing pattern 3 and {SS/PBCH block, PDCCH} SCS {480, 480} kHz or {960, 960} kHz
+		for _, issb := range rgd.ssbCands {
+			sfnc := sfn
+			nc := rgd.ssbFirstSymbs[issb] / rgd.symbPerSlot
+
+			var firstSymb int
+			if flags.gridsetting._ssbScs == "120KHz" && flags.gridsetting._mibCommonScs == "120KHz" {
+				firstSymb = []int{4, 8, 2, 6}[issb%4]
+			} else if flags.gridsetting._ssbScs == "480KHz" || flags.gridsetting._ssbScs == "960KHz" {
+				firstSymb = []int{2, 9}[issb%2]
+			}
+
+			key := fmt.Sprintf("%v_%v", sfn, issb)
+			_, exist := rgd.css0TdOccasions[key]
+			if !exist {
+				rgd.css0TdOccasions[key] = append(rgd.css0TdOccasions[key], nrgrid.Css0OccasionTd{sfnc, nc, firstSymb})
+			}
+		}
+	}
+
+	// TODO: validate PDCCH occasions of CSS0 --> 2023/3/27: moved to aotPdcchSib1
+	fmt.Printf("[SFN=%v, candSsbIndex=%v] css0TdOccasions=%v\n", sfn, flags.gridsetting.candSsbIndex, rgd.css0TdOccasions)
+	rgd.occCss0[sfn] = true
+
+	return nil
+}
+
+// detCcesPerPdcchCand determines the CCE indexes for aggregation level L corresponding to PDCCH candidate m of the search space set in slot n for an active DL BWP of a serving cell corresponding to carrier indicator field value n_CI For a search space set s associated with CORESET p
+//  p: CORESET index
+//  L: aggregation level
+//  m: PDCCH candidate index
+//  n: slot index
+//  sst: search space type, which can be type0, type0a, type1, type2, type3 or uss
+//  nRNTI: the n_RNTI
+//  NCCE: the N_CCE
+//  nCI: the n_CI
+//  Mmax: the M_max
+func detCcesPerPdcchCand(p int, L int, m int, n int, sst string, nRNTI int, NCCE int, nCI int, Mmax int) ([]int, error) {
+	if sst == "uss" && nRNTI == 0 {
+		return nil, errors.New(fmt.Sprintf("For a USS, the nRNTI must not be 0!"))
+	}
+
+	var Y int
+	if sst != "uss" {
+		Y = 0
+	} else {
+		var Ap int
+		if p%3 == 0 {
+			Ap = 39827
+		} else if p%3 == 1 {
+			Ap = 39829
+		} else {
+			Ap = 39839
+		}
+		D := 65537
+		for k := -1; k <= n; k++ {
+			if k == -1 {
+				Y = nRNTI
+			} else {
+				Y = (Ap * Y) % D
+			}
+		}
+	}
+
+	v1 := Y + utils.FloorInt(float64(m*NCCE)/float64(L*Mmax)) + nCI
+	v2 := utils.FloorInt(float64(NCCE) / float64(L))
+	v3 := v1 % v2
+
+	var cces []int
+	for i := 0; i < L; i++ {
+		cces = append(cces, L*v3+i)
+	}
+
+	return cces, nil
+}
+
+// aotSib1 maps SIB1 PDSCH and associated DMRS, which is scheduled by DCI 1_0 with SI-RNTI in Type0-PDCCH CSS.
+func aotSib1(sfn int) error {
+	// skip current SFN if no PDCCH for SIB1 is transmitted
+	if !rgd.trPdcchSib1[sfn] {
+		return nil
+	}
+
+	if rgd.trSib1[sfn] {
+		return nil
+	}
+
+	// refer to 3GPP 38.331 vh30
+	// 5.2.1	Introduction (of System information)
+	// The SIB1 is transmitted on the DL-SCH with a periodicity of 160 ms and variable transmission repetition periodicity within 160 ms as specified in TS 38.213 [13], clause 13. The default transmission repetition periodicity of SIB1 is 20 ms but the actual transmission repetition periodicity is up to network implementation. For SSB and CORESET multiplexing pattern 1, SIB1 repetition transmission period is 20 ms. For SSB and CORESET multiplexing pattern 2/3, SIB1 transmission repetition period is the same as the SSB period (TS 38.213 [13], clause 4.1).
+	// Note: PDCCH for SIB1 is only mapped in radio frames with SSB transmission, so SIB1 period is max(20ms, SSB period) for SSB and CORESET multiplexing pattern 1.
+	ssbPeriod, _ := strconv.Atoi(flags.gridsetting.ssbPeriod[:len(flags.gridsetting.ssbPeriod)-2])
+	sib1Period := ssbPeriod
+	if flags.gridsetting._coreset0MultiplexingPat == 1 {
+		sib1Period = utils.MaxInt([]int{20, ssbPeriod})
+	}
+	if sib1Period >= 10 && (sfn-flags.gridsetting._sfn)%(sib1Period/10) != 0 {
+		fmt.Printf("No SIB1 transmission in current frame(sfn=%d)\n", sfn)
+		return nil
+	}
+
+	prbs, err := getDci10Prbs(DCI_10_SIB1)
+	if err != nil {
+		return err
+	}
+	k0 := flags.dldci._tdK0[DCI_10_SIB1]
+
+	for _, issb := range rgd.ssbCands {
+		// select PDCCH occasion for SIB1
+		key := fmt.Sprintf("%v_%v", sfn, issb)
+		var pdcch *nrgrid.Css0PdcchCandidate
+		for i, cand := range rgd.css0PdcchCandidates[key] {
+			if flags.advanced.pdcchSlotSib1 < 0 || cand.Nc == flags.advanced.pdcchSlotSib1 {
+				pdcch = &rgd.css0PdcchCandidates[key][i]
+				break
+			}
+		}
+		if pdcch == nil {
+			fmt.Printf("No valid PDCCH occasion for SIB1: sfn=%v, issb=%v, pdcchSlotSib1=%v\n", sfn, issb, flags.advanced.pdcchSlotSib1)
+			continue
+		}
+
+		// determine slot of SIB1 PDSCH
+		sfnd := pdcch.Sfnc + (pdcch.Nc+k0)/rgd.slotPerRf
+		nd := (pdcch.Nc + k0) % rgd.slotPerRf
+		if sfnd != sfn {
+			err := alwaysOnTr(sfnd, 0)
+			if err != nil {
+				return err
+			}
+		}
+
+		// refer to 3GPP TS 38.214 vh40: 5.1.4	PDSCH resource mapping
+		// When receiving the PDSCH scheduled with SI-RNTI and the system information indicator in DCI is set to 0, the UE shall assume that no SS/PBCH block is transmitted in REs used by the UE for a reception of the PDSCH.
+		// Note: REs which are already occupied(SSB/PDCCH etc.) or not available(UL/GB in TDD) are rate-matched and reported as collisions.
+		numSib1Res, numDmrsRes, collisions := mapDci10Pdsch(DCI_10_SIB1, sfnd, nd, prbs, getSsbIndex(issb))
+		rgd.sib1Loc[fmt.Sprintf("%v_%v", sfn, getSsbIndex(issb))] = []int{sfnd, nd}
+
+		fmt.Printf("SIB1 PDSCH: issb=%v, PDCCH@[sfn=%v, slot=%v, firstSymb=%v, m=%v], PDSCH@[sfn=%v, slot=%v, S=%v, L=%v], prbs=%v\n", issb, pdcch.Sfnc, pdcch.Nc, pdcch.FirstSymb, pdcch.M, sfnd, nd, flags.dldci._tdStartSymb[DCI_10_SIB1], flags.dldci._tdNumSymbs[DCI_10_SIB1], prbs)
+		fmt.Printf("SIB1 PDSCH: issb=%v, REs of SIB1=%v, REs of DMRS=%v, RMSI overhead=%.2f%% of slot, collisions=%v\n", issb, numSib1Res, numDmrsRes, float64(100*(numSib1Res+numDmrsRes))/float64(rgd.scPerSlot), collisions)
+	}
+
+	rgd.trSib1[sfn] = true
+
+	return nil
+}
+
+// aotOsi maps PDCCH(DCI 1_0, SI-RNTI) in Type0A-PDCCH CSS and PDSCH of SI messages whose SI-window starts in radio frame sfn.
+func aotOsi(sfn int) error {
+	if len(flags.osi.siPeriodicity) == 0 || rgd.trOsi[sfn] {
+		return nil
+	}
+	rgd.trOsi[sfn] = true
+
+	w, _ := strconv.Atoi(flags.osi.siWindowLength[1:])
+	iss := utils.IndexStr(flags.searchspace._ssType, "type0a")
+	S := len(rgd.ssbCands)
+
+	prbs, err := getDci10Prbs(DCI_10_OSI)
+	if err != nil {
+		return err
+	}
+	k0 := flags.dldci._tdK0[DCI_10_OSI]
+
+	for n, period := range flags.osi.siPeriodicity {
+		// refer to 3GPP 38.331 vh30
+		// 5.2.2.3.2	Acquisition of an SI message
+		// - for the concerned SI message, determine the number n which corresponds to the order of entry in the list of SI messages configured by schedulingInfoList in si-SchedulingInfo in SIB1;
+		// - determine the integer value x = (n - 1) x w, where w is the si-WindowLength;
+		// - the SI-window starts at the slot #a, where a = x mod N, in the radio frame for which SFN mod T = FLOOR(x/N), where T is the si-Periodicity of the concerned SI message and N is the number of slots in a radio frame as specified in TS 38.213 [13];
+		T, _ := strconv.Atoi(period[2:])
+		x := n * w
+		if (sfn%1024)%T != x/rgd.slotPerRf {
+			continue
+		}
+		a := x % rgd.slotPerRf
+
+		// refer to 3GPP 38.213 vh40
+		// 13	UE procedure for monitoring Type0-PDCCH CSS sets
+		// ... the PDCCH monitoring occasions for SI message reception in SI-window are same as PDCCH monitoring occasions for SIB1 ...
+		// refer to 3GPP 38.331 vh30
+		// 5.2.2.3.2	Acquisition of an SI message
+		// ... the PDCCH monitoring occasions for SI message, which are not overlapping with UL symbols (determined according to tdd-UL-DL-ConfigurationCommon), are sequentially numbered from one in the SI-window. The [x*N+K]th PDCCH monitoring occasion for SI message in SI-window corresponds to the Kth transmitted SSB, where x = 0, 1, ...X-1, K = 1, 2, ...N, N is the number of actual transmitted SSBs determined according to ssb-PositionsInBurst in SIB1 and X is equal to CEIL(number of PDCCH monitoring occasions in SI-window/N).
+		// Note: searchSpaceOtherSystemInformation is always set to other than 0, and SI message is transmitted in the first siNumTxPerSsb PDCCH monitoring occasions(x=0,1,...) for each transmitted SSB.
+		mos, err := getCssMos(iss, sfn, a, w, 0, -1)
+		if err != nil {
+			return err
+		}
+		X := utils.CeilInt(float64(len(mos)) / float64(S))
+		fmt.Printf("SI message #%v(si-Periodicity=%v): SI-window@[sfn=%v, slot=%v], w=%v slots, number of PDCCH monitoring occasions=%v, X=%v\n", n+1, period, sfn, a, w, len(mos), X)
+
+		for k, issb := range rgd.ssbCands {
+			beam := getSsbIndex(issb)
+			var moPerSsb [][]int
+			for j := k; j < len(mos); j += S {
+				moPerSsb = append(moPerSsb, mos[j])
+			}
+			fmt.Printf("SI message #%v: issb=%v, PDCCH monitoring occasions=%v\n", n+1, beam, moPerSsb)
+			if len(moPerSsb) == 0 {
+				fmt.Printf("No PDCCH monitoring occasion for SI message #%v in SI-window: issb=%v\n", n+1, beam)
+				continue
+			}
+
+			if len(moPerSsb) < flags.osi.siNumTxPerSsb {
+				fmt.Printf("SI message #%v: issb=%v, only %v PDCCH monitoring occasion(s) in SI-window while siNumTxPerSsb=%v\n", n+1, beam, len(moPerSsb), flags.osi.siNumTxPerSsb)
+			}
+
+			for x := 0; x < utils.MinInt([]int{flags.osi.siNumTxPerSsb, len(moPerSsb)}); x++ {
+				sfnc, nc, firstSymb := moPerSsb[x][0], moPerSsb[x][1], moPerSsb[x][2]
+				cand, m, err := mapCoreset0Pdcch(iss, sfnc, nc, firstSymb, beam)
+				if err != nil {
+					return err
+				}
+				if cand == nil {
+					fmt.Printf("No valid PDCCH candidate(DCI 1_0, SI-RNTI) in PDCCH monitoring occasion for SI message #%v@[sfn=%v, slot=%v, firstSymb=%v], issb=%v, x=%v\n", n+1, sfnc, nc, firstSymb, beam, x)
+					continue
+				}
+
+				// map SI message PDSCH
+				sfnd := (sfnc*rgd.slotPerRf + nc + k0) / rgd.slotPerRf
+				nd := (sfnc*rgd.slotPerRf + nc + k0) % rgd.slotPerRf
+				if err := aotCommon(sfnd); err != nil {
+					return err
+				}
+
+				numDataRes, numDmrsRes, collisions := mapDci10Pdsch(DCI_10_OSI, sfnd, nd, prbs, beam)
+				fmt.Printf("SI message #%v PDSCH: issb=%v, x=%v, PDCCH@[sfn=%v, slot=%v, firstSymb=%v, m=%v], cces=%v, PDSCH@[sfn=%v, slot=%v, S=%v, L=%v], prbs=%v, TBS=%v bits, REs of PDSCH=%v, REs of DMRS=%v, OSI overhead=%.2f%% of slot, collisions=%v\n", n+1, beam, x, sfnc, nc, firstSymb, m, cand, sfnd, nd, flags.dldci._tdStartSymb[DCI_10_OSI], flags.dldci._tdNumSymbs[DCI_10_OSI], prbs, flags.dldci._tbsCw0[DCI_10_OSI], numDataRes, numDmrsRes, float64(100*(numDataRes+numDmrsRes))/float64(rgd.scPerSlot), collisions)
+			}
+		}
+	}
+
+	return nil
+}
+
+// aotLteCrs maps LTE CRS and LTE PDCCH region of the LTE carrier indicated by lte-CRS-ToMatchAround(i.e. LTE-NR DSS) in radio frame sfn.
+// Note: LTE CRS and LTE PDCCH region are only mapped on REs which are not occupied by NR resources, and the REs already occupied are reported as collisions.
+func aotLteCrs(sfn int) error {
+	if !flags.dss.lteCrsEnabled || rgd.trLteCrs[sfn] {
+		return nil
+	}
+	rgd.trLteCrs[sfn] = true
+
+	nrb, _ := strconv.Atoi(flags.dss.lteCarrierBw[1:])
+	numPorts, _ := strconv.Atoi(flags.dss.lteNumCrsPorts[1:])
+	vShift, _ := strconv.Atoi(flags.dss.lteVShift[1:])
+
+	// refer to 3GPP 36.211 vh10
+	// 6.10.1.2	Mapping to resource elements
+	// k = 6m + (v + v_shift) mod 6, l = 0, N_DL_symb - 3 if p in {0, 1}, l = 1 if p in {2, 3}
+	// v = 0 if p = 0 and l = 0, v = 3 if p = 0 and l != 0, v = 3 if p = 1 and l = 0, v = 0 if p = 1 and l != 0, v = 3(n_s mod 2) if p = 2, v = 3 + 3(n_s mod 2) if p = 3
+	// Note: an LTE subframe is aligned with a slot of 15KHz SCS, and LTE slot n_s mod 2 = 0/1 corresponds to symbols 0..6/7..13 of the slot(i.e. normal CP is assumed).
+	// crsSymbs maps symbol of slot to the set of (k mod 6) of CRS REs
+	crsSymbs := make(map[int][]int)
+	for p := 0; p < numPorts; p++ {
+		for ns := 0; ns < 2; ns++ {
+			ls := []int{0, 4}
+			if p >= 2 {
+				ls = []int{1}
+			}
+			for _, l := range ls {
+				var v int
+				switch {
+				case p == 0 && l == 0:
+					v = 0
+				case p == 0:
+					v = 3
+				case p == 1 && l == 0:
+					v = 3
+				case p == 1:
+					v = 0
+				case p == 2:
+					v = 3 * ns
+				default:
+					v = 3 + 3*ns
+				}
+				crsSymbs[7*ns+l] = append(crsSymbs[7*ns+l], (v+vShift)%6)
+			}
+		}
+	}
+
+	// the first subcarrier of the LTE carrier relative to the first subcarrier of the NR carrier
+	// Note: the DC subcarrier at the center of the LTE carrier is not used, and LTE subcarrier k is mapped to subcarrier sc0+k if k < 6*nrb or sc0+k+1 otherwise.
+	sc0 := flags.dss.lteCarrierFreqDl - 6*nrb - 12*flags.gridsetting._offsetToCarrier
+
+	grid := getDlGrid(sfn)
+	collisions := make(map[string]int)
+	numCrsRes, numPdcchRes := 0, 0
+	for slot := 0; slot < rgd.slotPerRf; slot++ {
+		// refer to 3GPP 36.211 vh10
+		// 6.10.1.2	Mapping to resource elements
+		// In case of multimedia broadcast single frequency network(MBSFN) subframes, cell-specific reference signals shall only be transmitted in the non-MBSFN region of the MBSFN subframe.
+		// Note: the non-MBSFN region of MBSFN subframe spans the first min(ltePdcchNumSymbs, 2) OFDM symbols of the subframe.
+		numCtrlSymbs := flags.dss.ltePdcchNumSymbs
+		numSymbs := rgd.symbPerSlot
+		if utils.ContainsInt(flags.dss.lteMbsfnSubframes, slot) {
+			numCtrlSymbs = utils.MinInt([]int{numCtrlSymbs, 2})
+			numSymbs = numCtrlSymbs
+		}
+
+		for symb := 0; symb < numSymbs; symb++ {
+			_, isCrsSymb := crsSymbs[symb]
+			if (!isCrsSymb && symb >= numCtrlSymbs) || !isTddSymbs(sfn, slot, symb, 1, "D") {
+				continue
+			}
+
+			for k := 0; k < 12*nrb; k++ {
+				sc := sc0 + k
+				if k >= 6*nrb {
+					sc++
+				}
+				if sc < 0 || sc >= rgd.scPerSymb {
+					continue
+				}
+
+				res := NR_RES_LTE_PDCCH
+				if utils.ContainsInt(crsSymbs[symb], k%6) {
+					res = NR_RES_LTE_CRS
+				} else if symb >= numCtrlSymbs {
+					continue
+				}
+
+				ire := slot*rgd.scPerSlot + symb*rgd.scPerSymb + sc
+				if grid.res[ire] != NR_RES_D {
+					collisions[resCategory(grid.res[ire])]++
+					continue
+				}
+
+				grid.res[ire] = res
+				if res == NR_RES_LTE_CRS {
+					numCrsRes++
+				} else {
+					numPdcchRes++
+				}
+			}
+		}
+	}
+
+	fmt.Printf("LTE CRS@[sfn=%v]: carrierFreqDL=%v, carrierBandwidthDL=%v, nrofCRS-Ports=%v, v-Shift=%v, MBSFN subframes=%v, LTE PDCCH symbols=%v, REs of CRS=%v, REs of LTE PDCCH=%v, collisions=%v\n", sfn, flags.dss.lteCarrierFreqDl, flags.dss.lteCarrierBw, flags.dss.lteNumCrsPorts, flags.dss.lteVShift, flags.dss.lteMbsfnSubframes, flags.dss.ltePdcchNumSymbs, numCrsRes, numPdcchRes, collisions)
+
+	return nil
+}
+
+// isRmpRe returns whether the RE of CRB crb in symbol symb of slot of radio frame sfn is declared as not available for PDSCH by RateMatchPattern.
+func isRmpRe(sfn, slot, symb, crb int) bool {
+	if len(flags.dss.rmpRbBitmap) == 0 {
+		return false
+	}
+
+	// refer to 3GPP 38.214 vh40
+	// 5.1.4.1	PDSCH resource mapping with RB symbol level granularity
+	// - A pair of reserved resources with a bitmap pair where the pair consists of a bitmap of resource blocks(resourceBlocks) with one-RB granularity in frequency domain and a bitmap of symbols(symbolsInResourceBlock) within a slot ...
+	// - A time-domain pattern(periodicityAndPattern) where each bit corresponds to a unit equal to a duration of the symbol level bitmap, and a bit value equal to 1 indicates that the pair is present in the unit ...
+	// Note: the bitmap pair is assumed to be always applied(i.e. rateMatchPatternGroup1/rateMatchPatternGroup2 and dynamic indication by DCI 1_1 are not supported), and periodicityAndPattern is assumed to start from slot 0 of SFN 0.
+	if pat := flags.dss.rmpPeriodicityAndPattern; len(pat) > 0 && pat[(sfn*rgd.slotPerRf+slot)%len(pat)] != '1' {
+		return false
+	}
+
+	return crb >= 0 && crb < len(flags.dss.rmpRbBitmap) && flags.dss.rmpRbBitmap[crb] == '1' && flags.dss.rmpSymbBitmap[symb] == '1'
+}
+
+// aotMeasGap marks the free REs within measurement gaps of MeasGapConfig as MG, starting from the given slot of radio frame sfn.
+// Note: measurement gaps are only marked after Msg4, since MeasGapConfig is provided by RRC signalling after random access procedure.
+// Note: the UE neither receives DL nor transmits UL on the serving cell within measurement gaps, hence free REs(D/U/F) within measurement gaps are marked as MG on all carriers of the serving cell(including the supplementary carrier), while REs already occupied(e.g. SSB, CSI-RS and TRS) are kept.
+func aotMeasGap(sfn, slot int) error {
+	if flags.meas.gapPatternId < 0 || rgd.trMeasGap[sfn] {
+		return nil
+	}
+	rgd.trMeasGap[sfn] = true
+
+	// grids of the serving cell and their slotPerRf and scPerSymb, where gridSup has the dimensions of the supplementary carrier
+	grids := []DataPerRf{rgd.gridTdd[sfn]}
+	dims := [][]int{{rgd.slotPerRf, rgd.scPerSymb}}
+	if flags.gridsetting._duplexMode != "TDD" {
+		grids = []DataPerRf{rgd.gridFddDl[sfn], rgd.gridFddUl[sfn]}
+		dims = append(dims, dims[0])
+	}
+	if len(flags.gridsetting.supBand) > 0 {
+		grids = append(grids, rgd.gridSup[sfn])
+		dims = append(dims, []int{rgd.supSlotPerRf, rgd.supScPerSymb})
+	}
+
+	// slots of the carrier within measurement gaps
+	var gapSlots []int
+	numRes := 0
+	for i, grid := range grids {
+		slotPerRf, scPerSymb := dims[i][0], dims[i][1]
+		for n := getOverlapSlot(slot, rgd.slotPerRf, slotPerRf, false); n < slotPerRf; n++ {
+			for symb := 0; symb < rgd.symbPerSlot; symb++ {
+				if !isMeasGap(sfn*slotPerRf+n, 1, symb, 1, rgd.symbPerSlot, slotPerRf/rgd.subfPerRf) {
+					continue
+				}
+
+				for ire := (n*rgd.symbPerSlot + symb) * scPerSymb; ire < (n*rgd.symbPerSlot+symb+1)*scPerSymb; ire++ {
+					if res := grid.res[ire]; res == NR_RES_D || res == NR_RES_U || res == NR_RES_F {
+						grid.res[ire] = NR_RES_MEAS_GAP
+						numRes++
+					}
+				}
+				if grid.tags[n] == nil {
+					grid.tags[n] = mapset.NewSet()
+				}
+				grid.tags[n].Add("MG")
+
+				if i == 0 && (len(gapSlots) == 0 || gapSlots[len(gapSlots)-1] != n) {
+					gapSlots = append(gapSlots, n)
+				}
+			}
+		}
+	}
+
+	if len(gapSlots) > 0 {
+		gap := nrgrid.MeasGapPatterns[flags.meas.gapPatternId]
+		fmt.Printf("Measurement gap@[sfn=%v]: gapPatternId=%v(MGL=%vms, MGRP=%vms), gapOffset=%v, mgta=%v, slots=%v, REs of MG=%v\n", sfn, flags.meas.gapPatternId, gap[0], gap[1], flags.meas.gapOffset, flags.meas.gapMgta, gapSlots, numRes)
+	}
+
+	return nil
+}
+
+// getMeasGapWindow returns the periodicity, offset relative to the start of SFN 0 and duration of measurement gaps in units of 1/4 symbol, where symbPerSubf is the number of symbols per subframe.
+func getMeasGapWindow(symbPerSubf int) (int, int, int) {
+	// refer to 3GPP 38.331 vh30
+	// 5.5.2.9	Measurement gap configuration
+	// the first subframe of each gap occurs at an SFN and subframe meeting the following condition: SFN mod T = FLOOR(gapOffset/10); subframe = gapOffset mod 10; with T = MGRP/10 as defined in TS 38.133 [14];
+	// apply the specified timing advance mgta to the gap occurrences calculated above (i.e. the UE starts the measurement mgta ms before the gap subframe occurrences);
+	// Note: the condition is equivalent to (SFN*10 + subframe) mod MGRP = gapOffset, and the unit of 1/4 symbol is used so that MGL of 1.5/3.5/5.5ms and mgta of 0.25ms are integers.
+	gap := nrgrid.MeasGapPatterns[flags.meas.gapPatternId]
+	mgta := map[string]float64{"ms0": 0, "ms0dot25": 0.25, "ms0dot5": 0.5}[flags.meas.gapMgta]
+	q := float64(4 * symbPerSubf)
+
+	return int(gap[1] * q), int((float64(flags.meas.gapOffset) - mgta) * q), int(gap[0] * q)
+}
+
+// isMeasGap returns whether any of symbols [firstSymb, firstSymb+numSymbs) of the numSlots slots starting from slot n(=sfn*slotPerRf+slot) overlaps with measurement gaps, where symbPerSlot and slotPerSubf are of the numerology of the slots.
+func isMeasGap(n, numSlots, firstSymb, numSymbs, symbPerSlot, slotPerSubf int) bool {
+	if flags.meas.gapPatternId < 0 {
+		return false
+	}
+
+	period, offset, duration := getMeasGapWindow(symbPerSlot * slotPerSubf)
+	for m := n; m < n+numSlots; m++ {
+		t := 4 * (m*symbPerSlot + firstSymb)
+		if isInWindow(t, t+4*numSymbs, period, offset, duration, false) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isMeasGapMo returns whether the PDCCH monitoring occasion of USS in slot n(=sfn*slotPerRf+slot) of the carrier overlaps with measurement gaps.
+func isMeasGapMo(n int) bool {
+	iss := utils.IndexStr(flags.searchspace._ssType, "uss")
+	bits := flags.searchspace._ssMonitoringSymbolWithinSlot[iss]
+	first := strings.Index(bits, "1")
+
+	return first >= 0 && isMeasGap(n, 1, first, strings.LastIndex(bits, "1")+flags.searchspace._coreset1Duration-first, rgd.symbPerSlot, rgd.slotPerSubf)
+}
+
+// isInWindow returns whether [t0, t1) is within(within=true) or overlaps with(within=false) any of the periodic windows [k*period+offset, k*period+offset+duration), where duration is no larger than period.
+func isInWindow(t0, t1, period, offset, duration int, within bool) bool {
+	for w := utils.FloorInt(float64(t0-offset)/float64(period))*period + offset; w < t1; w += period {
+		if (within && t0 >= w && t1 <= w+duration) || (!within && t0 < w+duration) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// markSmtc marks the free DL REs within SMTC windows of SSB-MTC as SMTC in the DL carrier of the serving cell.
+// Note: SMTC windows are marked after all channels are mapped, since the UE can still receive DL within SMTC windows outside measurement gaps.
+// Note: SMTC windows are only marked in slots after Msg4, since SSB-MTC of measObjectNR is provided by RRC signalling after random access procedure.
+func markSmtc() {
+	grid := rgd.gridTdd
+	if flags.gridsetting._duplexMode != "TDD" {
+		grid = rgd.gridFddDl
+	}
+
+	// refer to 3GPP 38.331 vh30
+	// 5.5.2.10	Reference signal measurement timing configuration
+	// Note: the unit of 1/4 symbol is used as in checkNbrSsbs.
+	q := 4 * rgd.symbPerSubf
+	period, _ := strconv.Atoi(flags.meas.smtcPeriodicity[2:])
+	duration, _ := strconv.Atoi(flags.meas.smtcDuration[2:])
+	for _, sfn := range getNrrgSfns(grid) {
+		var smtcSlots []int
+		numRes := 0
+		for slot := 0; slot < rgd.slotPerRf; slot++ {
+			if rgd.msg4Slot < 0 || sfn*rgd.slotPerRf+slot <= rgd.msg4Slot {
+				continue
+			}
+
+			for symb := 0; symb < rgd.symbPerSlot; symb++ {
+				s := sfn*rgd.symbPerRf + slot*rgd.symbPerSlot + symb
+				if !isInWindow(4*s, 4*(s+1), period*q, flags.meas.smtcOffset*q, duration*q, false) {
+					continue
+				}
+
+				for ire := slot*rgd.scPerSlot + symb*rgd.scPerSymb; ire < slot*rgd.scPerSlot+(symb+1)*rgd.scPerSymb; ire++ {
+					if grid[sfn].res[ire] == NR_RES_D {
+						grid[sfn].res[ire] = NR_RES_SMTC
+						numRes++
+					}
+				}
+				if grid[sfn].tags[slot] == nil {
+					grid[sfn].tags[slot] = mapset.NewSet()
+				}
+				grid[sfn].tags[slot].Add("SMTC")
+
+				if len(smtcSlots) == 0 || smtcSlots[len(smtcSlots)-1] != slot {
+					smtcSlots = append(smtcSlots, slot)
+				}
+			}
+		}
+
+		if len(smtcSlots) > 0 {
+			fmt.Printf("SMTC window@[sfn=%v]: periodicity=%v, offset=%v, duration=%v, slots=%v, REs of SMTC=%v\n", sfn, flags.meas.smtcPeriodicity, flags.meas.smtcOffset, flags.meas.smtcDuration, smtcSlots, numRes)
+		}
+	}
+}
+
+// getNbrSsbMeas returns whether the neighbour SSB starting from symbol s(relative to the start of SFN 0) is within an SMTC window of SSB-MTC and within an active measurement gap of MeasGapConfig respectively, where both are false before Msg4.
+func getNbrSsbMeas(s int) (bool, bool) {
+	if rgd.msg4Slot < 0 || s < (rgd.msg4Slot+1)*rgd.symbPerSlot {
+		return false, false
+	}
+
+	// refer to 3GPP 38.331 vh30
+	// 5.5.2.10	Reference signal measurement timing configuration
+	// the first subframe of each SMTC occasion occurs at an SFN and subframe of the NR SpCell meeting the following condition: SFN mod T = (FLOOR (Offset/10)); if the Periodicity is larger than sf5: subframe = Offset mod 10; else: subframe = Offset or (Offset +5); with T = CEIL(Periodicity/10).
+	// Note: the condition is equivalent to (SFN*10 + subframe) mod Periodicity = Offset, and the unit of 1/4 symbol is used as for measurement gaps.
+	smtc := false
+	if len(flags.meas.smtcPeriodicity) > 0 {
+		q := 4 * rgd.symbPerSubf
+		period, _ := strconv.Atoi(flags.meas.smtcPeriodicity[2:])
+		duration, _ := strconv.Atoi(flags.meas.smtcDuration[2:])
+		smtc = isInWindow(4*s, 4*(s+4), period*q, flags.meas.smtcOffset*q, duration*q, true)
+	}
+
+	gap := false
+	if flags.meas.gapPatternId >= 0 {
+		period, offset, duration := getMeasGapWindow(rgd.symbPerSubf)
+		gap = isInWindow(4*s, 4*(s+4), period, offset, duration, true)
+	}
+
+	return smtc, gap
+}
+
+// checkNbrSsbs checks whether the SSBs of each neighbour cell within the radio frames of NR resource grid after Msg4 fall inside SMTC windows of SSB-MTC and measurement gaps of MeasGapConfig.
+// Note: the neighbour cells are assumed to use the same subcarrier spacing, SSB pattern and ssb-PositionsInBurst as the serving cell, and the RF retuning time within measurement gaps is not considered.
+func checkNbrSsbs() {
+	sfns := getNrrgSfns(rgd.gridTdd)
+	if flags.gridsetting._duplexMode != "TDD" {
+		sfns = getNrrgSfns(rgd.gridFddDl)
+	}
+	if len(sfns) == 0 || rgd.msg4Slot < 0 {
+		return
+	}
+	// Note: only SSBs after Msg4 are checked, since SMTC windows and measurement gaps are only applied after Msg4.
+	first, last := utils.MaxInt([]int{sfns[0] * rgd.symbPerRf, (rgd.msg4Slot + 1) * rgd.symbPerSlot}), (sfns[len(sfns)-1]+1)*rgd.symbPerRf
+
+	for i, pci := range flags.meas.nbrPci {
+		period, _ := strconv.Atoi(flags.meas.nbrSsbPeriod[i][2:])
+		// SSBs of the neighbour cell within SMTC windows or measurement gaps
+		var ssbs []string
+		numSsbs, numSmtc, numGap, numBoth := 0, 0, 0, 0
+		for hf := flags.meas.nbrSsbOffset[i]; hf < last; hf += period * rgd.symbPerSubf {
+			for _, issb := range rgd.ssbCands {
+				s := hf + rgd.ssbFirstSymbs[issb]
+				if s < first || s+4 > last {
+					continue
+				}
+				numSsbs++
+
+				smtc, gap := getNbrSsbMeas(s)
+				if smtc {
+					numSmtc++
+				}
+				if gap {
+					numGap++
+				}
+				if smtc && gap {
+					numBoth++
+				}
+				if smtc || gap {
+					ssbs = append(ssbs, fmt.Sprintf("SSB%v@[%v,%v,%v](SMTC=%v,MG=%v)", issb, s/rgd.symbPerRf, (s%rgd.symbPerRf)/rgd.symbPerSlot, s%rgd.symbPerSlot, smtc, gap))
+				}
+			}
+		}
+
+		fmt.Printf("Neighbour cell(pci=%v): SSB periodicity=%v, timing offset=%v symbols, SSBs within SMTC windows or measurement gaps=%v\n", pci, flags.meas.nbrSsbPeriod[i], flags.meas.nbrSsbOffset[i], ssbs)
+		regGreen.Printf("[INFO]: Neighbour cell(pci=%v): %v of %v SSB(s) are within SMTC windows, %v within measurement gaps and %v within both\n", pci, numSmtc, numSsbs, numGap, numBoth)
+	}
+}
+
+// aotPrs maps DL PRS resources of DL-PRS-ResourceSet, starting from the given slot of radio frame sfn.
+// Note: DL PRS is only mapped on REs which are not occupied by other resources(e.g. SSB and CSI-RS), and the REs already occupied are reported as collisions.
+func aotPrs(sfn, slot int) error {
+	if len(flags.pos.prsResId) == 0 || rgd.trPrs[sfn] {
+		return nil
+	}
+	rgd.trPrs[sfn] = true
+
+	// refer to 3GPP 38.211 vh40
+	// 7.4.1.7.3	Mapping to physical resources in a downlink PRS resource
+	// k = m*K_PRS_comb + ((k_PRS_offset + k') mod K_PRS_comb), l = l_PRS_start, l_PRS_start + 1, ..., l_PRS_start + L_PRS - 1
+	// Note: k' is given by Table 7.4.1.7.3-1 as a function of l - l_PRS_start, and dl-PRS-StartPRB is relative to point A.
+	comb := flags.pos.prsCombSize
+	kPrime := map[int][]int{
+		2:  {0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1},
+		4:  {0, 2, 1, 3, 0, 2, 1, 3, 0, 2, 1, 3},
+		6:  {0, 3, 1, 4, 2, 5, 0, 3, 1, 4, 2, 5},
+		12: {0, 6, 3, 9, 1, 7, 4, 10, 2, 8, 5, 11},
+	}[comb]
+	sc0 := (flags.pos.prsStartPrb - flags.gridsetting._offsetToCarrier) * rgd.scPerRb
+
+	// refer to 3GPP 38.211 vh40
+	// 7.4.1.7.4	Mapping to slots in a downlink PRS resource set
+	// (N_frame_slot*n_f + n_s_f - T_PRS_offset - T_PRS_offset_res) mod 2^u*T_PRS_per in {i*T_PRS_gap}, i = 0, 1, ..., T_PRS_rep - 1
+	period := flags.pos.prsPeriod * rgd.slotPerSubf
+
+	grid := getDlGrid(sfn)
+	// [sfn, slot] of muted and dropped DL PRS occasions
+	var muted, dropped []string
+	for s := slot; s < rgd.slotPerRf; s++ {
+		for j, id := range flags.pos.prsResId {
+			d := sfn*rgd.slotPerRf + s - flags.pos.prsSetSlotOffset - flags.pos.prsResSlotOffset[j]
+			r := (d%period + period) % period
+			if r%flags.pos.prsTimeGap != 0 || r/flags.pos.prsTimeGap >= flags.pos.prsRepFactor {
+				continue
+			}
+
+			if isPrsMuted(utils.FloorInt(float64(d)/float64(period)), r/flags.pos.prsTimeGap) {
+				muted = append(muted, fmt.Sprintf("PRS(resId=%v)@[%v,%v]", id, sfn, s))
+				continue
+			}
+
+			firstSymb := flags.pos.prsResSymbOffset[j]
+			if !isTddSymbs(sfn, s, firstSymb, flags.pos.prsNumSymbs, "D") {
+				dropped = append(dropped, fmt.Sprintf("PRS(resId=%v)@[%v,%v]", id, sfn, s))
+				continue
+			}
+
+			collisions := make(map[string]int)
+			numRes := 0
+			for l := 0; l < flags.pos.prsNumSymbs; l++ {
+				for m := 0; m < flags.pos.prsNumPrbs*rgd.scPerRb/comb; m++ {
+					sc := sc0 + m*comb + (flags.pos.prsResReOffset[j]+kPrime[l])%comb
+					if sc < 0 || sc >= rgd.scPerSymb {
+						continue
+					}
+
+					ire := s*rgd.scPerSlot + (firstSymb+l)*rgd.scPerSymb + sc
+					if grid.res[ire] != NR_RES_D {
+						collisions[resCategory(grid.res[ire])]++
+						continue
+					}
+
+					grid.res[ire] = NR_RES_PRS
+					numRes++
+				}
+			}
+			if grid.tags[s] == nil {
+				grid.tags[s] = mapset.NewSet()
+			}
+			grid.tags[s].Add("PRS")
+
+			fmt.Printf("DL-PRS@[sfn=%v, slot=%v]: resourceId=%v, repetition=%v, comb=%v, reOffset=%v, firstSymb=%v, numSymbs=%v, REs of PRS=%v, collisions=%v\n", sfn, s, id, r/flags.pos.prsTimeGap, comb, flags.pos.prsResReOffset[j], firstSymb, flags.pos.prsNumSymbs, numRes, collisions)
+		}
+	}
+
+	if len(muted) > 0 {
+		fmt.Printf("[SFN=%v] Muted DL PRS occasions: %v\n", sfn, muted)
+	}
+	if len(dropped) > 0 {
+		fmt.Printf("[SFN=%v] Dropped DL PRS occasions: %v\n", sfn, dropped)
+	}
+
+	return nil
+}
+
+// isPrsMuted returns whether the repetition rep of DL PRS resources within instance inst of DL-PRS-ResourceSet is muted by dl-PRS-MutingOption1 or dl-PRS-MutingOption2.
+func isPrsMuted(inst, rep int) bool {
+	// refer to 3GPP 38.214 vh40
+	// 5.1.6.5	PRS reception procedure
+	// Note: for dl-PRS-MutingOption1, each bit of the bitmap corresponds to dl-PRS-MutingBitRepetitionFactor consecutive instances of DL-PRS-ResourceSet, and all DL PRS resources within the instance(s) are muted if the bit is 0.
+	// Note: for dl-PRS-MutingOption2, each bit of the bitmap corresponds to a single repetition index of each DL PRS resource within each instance of DL-PRS-ResourceSet, and the repetition is muted if the bit is 0.
+	if opt1 := flags.pos.prsMutingOpt1; len(opt1) > 0 {
+		k := utils.FloorInt(float64(inst) / float64(flags.pos.prsMutingBitRepFactor))
+		if opt1[(k%len(opt1)+len(opt1))%len(opt1)] == '0' {
+			return true
+		}
+	}
+
+	if opt2 := flags.pos.prsMutingOpt2; len(opt2) > 0 && opt2[rep] == '0' {
+		return true
+	}
+
+	return false
+}
+
+// getDci10Sc0Rb0 returns the first subcarrier of the CORESET in which DCI 1_0 is received.
+//  i: index of DL DCI, which can be DCI_10_SIB1, DCI_10_MSG2, DCI_10_MSG4, DCI_10_MSGB, DCI_10_PAGING or DCI_10_OSI
+// Note: only Type1-PDCCH CSS set(ra-SearchSpace) can be configured in CORESET1.
+func getDci10Sc0Rb0(i int) int {
+	if i == DCI_10_MSG2 || i == DCI_10_MSG4 || i == DCI_10_MSGB {
+		if iss := utils.IndexStr(flags.searchspace._ssType, "type1"); iss >= 0 && flags.searchspace._ssCoresetId[iss] != 0 {
+			return flags.searchspace.coreset1StartCrb * rgd.scPerRb
+		}
+	}
+
+	return rgd.coreset0Sc0Rb0
+}
+
+// getDci10Prbs returns the sorted PRBs(relative to the lowest RB of the CORESET in which DCI 1_0 is received) of PDSCH scheduled by DCI 1_0.
+//  i: index of DL DCI, which can be DCI_10_SIB1, DCI_10_MSG2, DCI_10_MSG4, DCI_10_MSGB, DCI_10_PAGING or DCI_10_OSI
+func getDci10Prbs(i int) ([]int, error) {
+	// refer to 3GPP TS 38.214 vh40: 5.1.2.2	Resource allocation in frequency domain
+	// For PDSCH scheduled with a DCI format 1_0 in any type of PDCCH common search space, regardless of which bandwidth part is the active bandwidth part, RB numbering starts from the lowest RB of the CORESET in which the DCI was received.
+	prbMap := map[int][]int{DCI_10_SIB1: rgd.dci10Sib1Prbs, DCI_10_MSG2: rgd.dci10Msg2Prbs, DCI_10_MSG4: rgd.dci10Msg4Prbs, DCI_10_MSGB: rgd.dci10MsgBPrbs, DCI_10_PAGING: rgd.dci10PagingPrbs, DCI_10_OSI: rgd.dci10OsiPrbs}[i]
+
+	var prbs []int
+	for vrb := flags.dldci.fdStartRb[i]; vrb < flags.dldci.fdStartRb[i]+flags.dldci.fdNumRbs[i]; vrb++ {
+		if vrb >= len(prbMap) {
+			return nil, errors.New(fmt.Sprintf("Invalid FDRA of %v: fdStartRb=%v, fdNumRbs=%v while CORESET0 has %v RBs.", flags.dldci._tag[i], flags.dldci.fdStartRb[i], flags.dldci.fdNumRbs[i], len(prbMap)))
+		}
+
+		if flags.dldci.fdVrbPrbMappingType[i] == "interleaved" {
+			prbs = append(prbs, prbMap[vrb])
+		} else {
+			prbs = append(prbs, vrb)
+		}
+	}
+	sort.Ints(prbs)
+
+	sc0Rb0 := getDci10Sc0Rb0(i)
+	if sc0Rb0 < 0 || sc0Rb0+(utils.MaxInt(prbs)+1)*rgd.scPerRb > rgd.scPerSymb {
+		return nil, errors.New(fmt.Sprintf("%v PDSCH(coresetSc0Rb0=%v, prbs=%v) is out of the carrier bandwidth(%v RBs).", flags.dldci._tag[i], sc0Rb0, prbs, flags.gridsetting._carrierNumRbs))
+	}
+
+	return prbs, nil
+}
+
+// mapDci10Pdsch maps PDSCH and associated DMRS scheduled by DCI 1_0, and returns number of REs of PDSCH, number of REs of DMRS and collisions.
+//  i: index of DL DCI, which can be DCI_10_SIB1, DCI_10_MSG2, DCI_10_MSG4, DCI_10_MSGB, DCI_10_PAGING or DCI_10_OSI
+//  sfnd: radio frame of the PDSCH
+//  nd: slot of the PDSCH
+//  prbs: PRBs of the PDSCH returned by getDci10Prbs
+//  beam: SSB index of the beam which is quasi co-located with the PDSCH
+func mapDci10Pdsch(i, sfnd, nd int, prbs []int, beam int) (int, int, map[string]int) {
+	dataRes := map[int]int{DCI_10_SIB1: NR_RES_SIB1, DCI_10_MSG2: NR_RES_MSG2, DCI_10_MSG4: NR_RES_MSG4, DCI_10_MSGB: NR_RES_MSGB, DCI_10_PAGING: NR_RES_PAGING, DCI_10_OSI: NR_RES_OSI}[i]
+	dmrsRes := map[int]int{DCI_10_SIB1: NR_RES_DMRS_SIB1, DCI_10_MSG2: NR_RES_DMRS_MSG2, DCI_10_MSG4: NR_RES_DMRS_MSG4, DCI_10_MSGB: NR_RES_DMRS_MSGB, DCI_10_PAGING: NR_RES_DMRS_PAGING, DCI_10_OSI: NR_RES_DMRS_OSI}[i]
+	tag := map[int]string{DCI_10_SIB1: "SIB1", DCI_10_MSG2: "MSG2", DCI_10_MSG4: "MSG4", DCI_10_MSGB: "MSGB", DCI_10_PAGING: "PAGING", DCI_10_OSI: "OSI"}[i]
+
+	// TD pattern of PDSCH and DMRS(index of common DMRS is the same as index of DL DCI)
+	S := flags.dldci._tdStartSymb[i]
+	L := flags.dldci._tdNumSymbs[i]
+	// refer to 3GPP TS 38.211 vh40: 7.4.1.1.2	Mapping to physical resources (DMRS for PDSCH)
+	// l is defined relative to the start of the slot if PDSCH mapping type A, relative to the start of the scheduled PDSCH resources if PDSCH mapping type B
+	var tdL []int
+	for _, l := range flags.dmrsCommon._tdL[i] {
+		if flags.dldci._tdMappingType[i] == "typeA" {
+			tdL = append(tdL, l)
+		} else {
+			tdL = append(tdL, S+l)
+		}
+	}
+	fdK := flags.dmrsCommon._fdK[i]
+
+	grid := getDlGrid(sfnd)
+	sc0Rb0 := getDci10Sc0Rb0(i)
+	numDataRes, numDmrsRes := 0, 0
+	collisions := make(map[string]int)
+	for symb := S; symb < S+L; symb++ {
+		isDmrs := utils.ContainsInt(tdL, symb)
+		for _, prb := range prbs {
+			// refer to 3GPP TS 38.214 vh40
+			// 5.1.4	PDSCH resource mapping
+			// When receiving the PDSCH scheduled with SI-RNTI and the system information indicator in DCI is set to 1, RA-RNTI, MsgB-RNTI, P-RNTI or TC-RNTI, the UE assumes SS/PBCH block transmission according to ssb-PositionsInBurst in SIB1, and if the PDSCH resource allocation overlaps with PRBs containing SS/PBCH block transmission resources the UE shall assume that the PRBs containing SS/PBCH block transmission resources are not available for PDSCH in the OFDM symbols where SS/PBCH block is transmitted.
+			if i != DCI_10_SIB1 && isSsbPrb(grid, nd*rgd.scPerSlot+symb*rgd.scPerSymb, sc0Rb0+prb*rgd.scPerRb) {
+				continue
+			}
+
+			for isc := 0; isc < rgd.scPerRb; isc++ {
+				ire := nd*rgd.scPerSlot + symb*rgd.scPerSymb + sc0Rb0 + prb*rgd.scPerRb + isc
+				// refer to 3GPP TS 38.214 vh40
+				// 5.1.4	PDSCH resource mapping
+				// Note: REs declared as not available for PDSCH by RateMatchPattern(5.1.4.1) or lte-CRS-ToMatchAround(5.1.4.2) of ServingCellConfigCommon are rate matched for PDSCH scheduled by DCI 1_0 with SI-RNTI, P-RNTI, RA-RNTI, MsgB-RNTI or TC-RNTI, which are not counted as collisions.
+				crb := (sc0Rb0+prb*rgd.scPerRb+isc)/rgd.scPerRb + flags.gridsetting._offsetToCarrier
+				if isRmpRe(sfnd, nd, symb, crb) || grid.res[ire] == NR_RES_LTE_CRS {
+					continue
+				}
+
+				if grid.res[ire] != NR_RES_D {
+					collisions[resCategory(grid.res[ire])]++
+					continue
+				}
+
+				// refer to 3GPP TS 38.214 vh40: 5.1.6.2	DM-RS reception procedure
+				// When receiving PDSCH scheduled by DCI format 1_0, ..., a single symbol front-loaded DM-RS of configuration type 1 on DM-RS port 1000 is transmitted
+				// Note: DM-RS port 1000 belongs to CDM group 0(even subcarriers), while REs of other CDM group(s) without data are DTX.
+				grid.beams[ire] = beam
+				if isDmrs && fdK[isc] == 1 {
+					if isc%2 == 0 {
+						grid.res[ire] = dmrsRes
+						numDmrsRes++
+					} else {
+						grid.res[ire] = NR_RES_DTX
+					}
+				} else {
+					grid.res[ire] = dataRes
+					numDataRes++
+				}
+			}
+		}
+	}
+
+	if grid.tags[nd] == nil {
+		grid.tags[nd] = mapset.NewSet()
+	}
+	grid.tags[nd].Add(tag)
+
+	return numDataRes, numDmrsRes, collisions
+}
+
+// isSsbPrb returns whether the PRB starting from subcarrier sc0 overlaps with the 240 subcarriers of SSB transmitted in the OFDM symbol starting from RE symb0 of grid.
+// Note: PRBs containing only the DTX subcarriers of PSS/SSS symbols also belong to the SSB transmission resources.
+func isSsbPrb(grid DataPerRf, symb0, sc0 int) bool {
+	if sc0+rgd.scPerRb <= rgd.ssbSc0Rb0 || sc0 >= rgd.ssbSc0Rb0+240 {
+		return false
+	}
+
+	// Note: subcarrier 120 of SSB is occupied by PSS, SSS or PBCH in all the 4 OFDM symbols of SSB.
+	return utils.ContainsInt([]int{NR_RES_PSS, NR_RES_SSS, NR_RES_PBCH, NR_RES_DMRS_PBCH}, grid.res[symb0+rgd.ssbSc0Rb0+120])
+}
+
+// resCategory returns the category of the given NR resource, which is used when reporting collisions.
+func resCategory(res int) string {
+	switch {
+	case res == NR_RES_PSS || res == NR_RES_SSS || res == NR_RES_PBCH || res == NR_RES_DMRS_PBCH:
+		return "SSB"
+	case res == NR_RES_DTX:
+		// Note: DTX is used by both the unused subcarriers of PSS/SSS symbols and the DMRS CDM group(s) without data of PDSCH/PUSCH.
+		return "DTX"
+	case (res >= NR_RES_PDCCH_CANDIDATE && res < NR_RES_PDCCH_CANDIDATE+8) || res == NR_RES_DMRS_PDCCH || res == NR_RES_CORESET0 || res == NR_RES_CORESET1:
+		return "CORESET"
+	case res == NR_RES_SIB1 || res == NR_RES_DMRS_SIB1:
+		return "SIB1"
+	case res == NR_RES_PAGING || res == NR_RES_DMRS_PAGING:
+		return "PAGING"
+	case res == NR_RES_OSI || res == NR_RES_DMRS_OSI:
+		return "OSI"
+	case res == NR_RES_MSG2 || res == NR_RES_DMRS_MSG2:
+		return "MSG2"
+	case res == NR_RES_MSG3 || res == NR_RES_DMRS_MSG3:
+		return "MSG3"
+	case res == NR_RES_MSG4 || res == NR_RES_DMRS_MSG4:
+		return "MSG4"
+	case res == NR_RES_MSGA || res == NR_RES_DMRS_MSGA:
+		return "MSGA"
+	case res == NR_RES_MSGB || res == NR_RES_DMRS_MSGB:
+		return "MSGB"
+	case res == NR_RES_PRACH:
+		return "PRACH"
+	case res == NR_RES_PDSCH:
+		return "PDSCH"
+	case res == NR_RES_PUSCH:
+		return "PUSCH"
+	case res == NR_RES_DMRS_PDSCH || res == NR_RES_DMRS_PUSCH:
+		return "DMRS"
+	case res == NR_RES_PTRS_PDSCH || res == NR_RES_PTRS_PUSCH:
+		return "PTRS"
+	case res == NR_RES_U || res == NR_RES_GB:
+		return "TDD-UL/GB"
+	case res == NR_RES_F:
+		return "TDD-F"
+	case res == NR_RES_D:
+		return "TDD-DL"
+	case res == NR_RES_TRS:
+		return "TRS"
+	case res == NR_RES_CSI_IM || (res >= NR_RES_CSI_RS_CDM_GRP_0 && res < NR_RES_CSI_RS_CDM_GRP_0+16):
+		return "CSI-RS"
+	case (res >= NR_RES_SRS0 && res <= NR_RES_SRS0_1_2_3) || res == NR_RES_SRS_POS:
+		return "SRS"
+	case (res >= NR_RES_PUCCH_SR && res <= NR_RES_PUCCH_ACK_CSI) || res == NR_RES_DMRS_PUCCH:
+		return "PUCCH"
+	case res == NR_RES_LTE_CRS || res == NR_RES_LTE_PDCCH:
+		return "LTE"
+	case res == NR_RES_BWP_SW:
+		return "BWP-SW"
+	case res == NR_RES_MEAS_GAP:
+		return "MEAS-GAP"
+	case res == NR_RES_SMTC:
+		return "SMTC"
+	case res == NR_RES_PRS:
+		return "PRS"
+	default:
+		return "OTHERS"
+	}
+}
+
+// aotCsi maps periodic NZP-CSI-RS(for CSI acquisition or TRS) and CSI-IM, starting from the given slot of radio frame sfn.
+func aotCsi(sfn, slot int) error {
+	if rgd.trCsi[sfn] {
+		return nil
+	}
+
+	// [sfn, slot] of CSI occasions which collide with other resources
+	var collided []string
+
+	// NZP-CSI-RS resources
+	for i := range flags.csi._resId {
+		period, _ := strconv.Atoi(flags.csi.period[i][5:])
+		offset := flags.csi.offset[i]
+		isTrs := flags.csi._trsInfo[i] == "true"
+
+		// RE pattern within a PRB
+		res, err := getCsiRsRePattern(i)
+		if err != nil {
+			return err
+		}
+
+		var tag string
+		var slots int
+		if isTrs {
+			// refer to 3GPP 38.214 vh40
+			// 5.1.6.1.1	CSI-RS for tracking
+			// For frequency range 1, the UE may be configured with one or more NZP CSI-RS set(s), where a NZP-CSI-RS-ResourceSet consists of four periodic NZP CSI-RS resources in two consecutive slots with two periodic NZP CSI-RS resources in each slot.
+			// - the time-domain locations of the two CSI-RS resources in a slot, or of the four CSI-RS resources in two consecutive slots (which are the same across two consecutive slots), as defined by higher layer parameter CSI-RS-resourceMapping, is given by one of l in {4,8}, l in {5,9}, or l in {6,10} for frequency range 1 and frequency range 2
+			tag = "TRS"
+			slots = 2
+			var res2 [][]int
+			for _, v := range res {
+				res2 = append(res2, []int{v[0] + 4, v[1], v[2]})
+			}
+			res = append(res, res2...)
+		} else {
+			tag = "CSI-RS"
+			slots = 1
+		}
+
+		// frequency-domain allocation
+		rbs := getCsiRbs(flags.csi._startRb[i], flags.csi._numRbs[i], flags.csi._density[i])
+
+		for s := slot; s < rgd.slotPerRf; s++ {
+			if (sfn*rgd.slotPerRf+s-offset)%period != 0 {
+				continue
+			}
+
+			var occasion [][]int
+			for n := 0; n < slots; n++ {
+				sfn2 := sfn + (s+n)/rgd.slotPerRf
+				slot2 := (s + n) % rgd.slotPerRf
+				if sfn2 != sfn {
+					// Note: CSI-RS/SRS of the next radio frame are not mapped to avoid recursion
+					err := aotCommon(sfn2)
+					if err != nil {
+						return err
+					}
+				}
+
+				for _, rb := range rbs {
+					for _, v := range res {
+						occasion = append(occasion, []int{sfn2, slot2, v[0], rb*rgd.scPerRb + v[1], v[2]})
+					}
+				}
+			}
+
+			var resTag func(j int) int
+			if isTrs {
+				resTag = func(j int) int { return NR_RES_TRS }
+			} else {
+				resTag = func(j int) int { return NR_RES_CSI_RS_CDM_GRP_0 + j }
+			}
+			if !mapCsiOccasion(occasion, resTag, tag) {
+				collided = append(collided, fmt.Sprintf("%v(resId=%v)@[%v,%v]", tag, flags.csi._resId[i], sfn, s))
+			}
+		}
+	}
+
+	// CSI-IM resource
+	// refer to 3GPP 38.214 vh40
+	// 5.2.2.4	Channel State Information – Interference Measurement (CSI-IM)
+	// If csi-IM-ResourceElementPattern is set to 'pattern0', ..., the UE shall assume that CSI-IM resource is located in resource elements (k_CSI-IM, l_CSI-IM), (k_CSI-IM, l_CSI-IM+1), (k_CSI-IM+1, l_CSI-IM) and (k_CSI-IM+1, l_CSI-IM+1).
+	// If csi-IM-ResourceElementPattern is set to 'pattern1', ..., the UE shall assume that CSI-IM resource is located in resource elements (k_CSI-IM, l_CSI-IM), (k_CSI-IM+1, l_CSI-IM), (k_CSI-IM+2, l_CSI-IM) and (k_CSI-IM+3, l_CSI-IM).
+	kImSc, _ := strconv.Atoi(flags.csi._csiImScLoc[1:])
+	lImSymb := flags.csi._csiImSymbLoc
+	var resIm [][]int
+	if flags.csi._csiImRePattern == "pattern0" {
+		resIm = [][]int{{lImSymb, kImSc, 0}, {lImSymb, kImSc + 1, 0}, {lImSymb + 1, kImSc, 0}, {lImSymb + 1, kImSc + 1, 0}}
+	} else {
+		resIm = [][]int{{lImSymb, kImSc, 0}, {lImSymb, kImSc + 1, 0}, {lImSymb, kImSc + 2, 0}, {lImSymb, kImSc + 3, 0}}
+	}
+	periodIm, _ := strconv.Atoi(flags.csi._csiImPeriod[5:])
+	rbsIm := getCsiRbs(flags.csi._csiImStartRb, flags.csi._csiImNumRbs, "one")
+	for s := slot; s < rgd.slotPerRf; s++ {
+		if (sfn*rgd.slotPerRf+s-flags.csi._csiImOffset)%periodIm != 0 {
+			continue
+		}
+
+		var occasion [][]int
+		for _, rb := range rbsIm {
+			for _, v := range resIm {
+				occasion = append(occasion, []int{sfn, s, v[0], rb*rgd.scPerRb + v[1], v[2]})
+			}
+		}
+
+		if !mapCsiOccasion(occasion, func(j int) int { return NR_RES_CSI_IM }, "CSI-IM") {
+			collided = append(collided, fmt.Sprintf("CSI-IM@[%v,%v]", sfn, s))
+		}
+	}
+
+	if len(collided) > 0 {
+		fmt.Printf("[SFN=%v] CSI occasions with collisions: %v\n", sfn, collided)
+	}
+
+	rgd.trCsi[sfn] = true
+
+	return nil
+}
+
+// getCsiRsRePattern returns the RE pattern within a PRB and a slot of NZP-CSI-RS, where each element is [l, k, j] and j is the CDM group index.
+//  i: index of the NZP-CSI-RS resource
+func getCsiRsRePattern(i int) ([][]int, error) {
+	// refer to 3GPP 38.211 vh40
+	// 7.4.1.5.3	Mapping to physical resources (CSI-RS)
+	// The quantity k' and l' are given by Tables 7.4.1.5.3-2 to 7.4.1.5.3-5, and k_i/l_i are given by frequencyDomainAllocation and firstOFDMSymbolInTimeDomain of CSI-RS-ResourceMapping.
+	loc := flags.csi._tdLoc[i]
+	ki := getCsiRsFreqAlloc(flags.csi.freqAllocRow[i], flags.csi.freqAllocBits[i])
+	li := []int{flags.csi._firstSymb[i]}
+
+	var res [][]int
+	for j := range loc.CdmGrpIndj {
+		if loc.Ki[j] >= len(ki) || loc.Li[j] >= len(li) {
+			return nil, errors.New(fmt.Sprintf("Invalid CSI-RS resource mapping(resourceId=%v): row=%v, ki=%v, li=%v.", flags.csi._resId[i], loc.Row, ki, li))
+		}
+
+		kBar := ki[loc.Ki[j]] + loc.KBarLBar[j][0]
+		lBar := li[loc.Li[j]] + loc.KBarLBar[j][1]
+		for _, kap := range loc.Kap {
+			for _, lap := range loc.Lap {
+				res = append(res, []int{lBar + lap, kBar + kap, loc.CdmGrpIndj[j]})
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// getCsiRsFreqAlloc returns the k_i of NZP-CSI-RS, which is determined by frequencyDomainAllocation of CSI-RS-ResourceMapping.
+//  row: the row of frequencyDomainAllocation, which can be row1, row2, row4 or other
+//  bits: the bit-string of frequencyDomainAllocation
+func getCsiRsFreqAlloc(row string, bits string) []int {
+	// refer to 3GPP 38.211 vh40
+	// 7.4.1.5.3	Mapping to physical resources (CSI-RS)
+	//  - [b3...b0], k_i-1 = f(i) for row 1 of Table 7.4.1.5.3-1
+	//  - [b11...b0], k_i-1 = f(i) for row 2 of Table 7.4.1.5.3-1
+	//  - [b2...b0], k_i-1 = 4f(i) for row 4 of Table 7.4.1.5.3-1
+	//  - [b5...b0], k_i-1 = 2f(i) for all other cases
+	// where f(i) is the bit number of the i:th bit in the bitmap set to one, repeated across every resource block configured for CSI-RS reception by the UE.
+	var ki []int
+	for i := 0; i < len(bits); i++ {
+		if bits[len(bits)-1-i] != '1' {
+			continue
+		}
+
+		switch row {
+		case "row1", "row2":
+			ki = append(ki, i)
+		case "row4":
+			ki = append(ki, 4*i)
+		default:
+			ki = append(ki, 2*i)
+		}
+	}
+
+	return ki
+}
+
+// getCsiRbs returns the RBs(relative to the first RB of carrier) of CSI-RS or CSI-IM.
+//  startRb: the startingRB of CSI-FrequencyOccupation, which is relative to CRB0
+//  numRbs: the nrofRBs of CSI-FrequencyOccupation
+//  density: the density of CSI-RS-ResourceMapping, which can be evenPRBs, oddPRBs, one or three
+func getCsiRbs(startRb, numRbs int, density string) []int {
+	var rbs []int
+	for crb := startRb; crb < startRb+numRbs; crb++ {
+		if (density == "evenPRBs" && crb%2 != 0) || (density == "oddPRBs" && crb%2 != 1) {
+			continue
+		}
+
+		rb := crb - flags.gridsetting._offsetToCarrier
+		if rb >= 0 && rb < flags.gridsetting._carrierNumRbs {
+			rbs = append(rbs, rb)
+		}
+	}
+
+	return rbs
+}
+
+// mapCsiOccasion maps a CSI-RS or CSI-IM occasion, and returns whether none of its REs collides with other resources.
+// Note: the occasion is always transmitted by the gNB. REs overlapping with PDSCH of SIB1/SI messages/paging, measurement gaps or SMTC windows are overwritten, since the PDSCH is rate matched around CSI-RS and the UE doesn't receive in measurement gaps, while REs overlapping with SSB, CORESET, DMRS or non-DL symbols are not mapped. All collisions are reported.
+//  occasion: REs of the occasion, where each element is [sfn, slot, l, sc, j]
+//  resTag: returns the NR resource of CDM group j
+//  tag: tag of the slot
+func mapCsiOccasion(occasion [][]int, resTag func(j int) int, tag string) bool {
+	// refer to 3GPP 38.214 vh40
+	// 5.1.6.1.1	CSI-RS for tracking
+	// The UE is not expected to be configured with a CSI-RS resource(s) in resource elements that overlap with SS/PBCH block(s) or with the CORESET(s) configured to the UE.
+	// 5.2.2.3.1	NZP CSI-RS
+	// The UE expects that ... CSI-RS resource and the SS/PBCH block are not ... in the same PRB(s) and OFDM symbol(s).
+	collisions := make(map[string]int)
+	for _, re := range occasion {
+		grid := getDlGrid(re[0])
+		ire := re[1]*rgd.scPerSlot + re[2]*rgd.scPerSymb + re[3]
+		res := grid.res[ire]
+		if res != NR_RES_D {
+			collisions[resCategory(res)]++
+			if !utils.ContainsInt([]int{NR_RES_SIB1, NR_RES_OSI, NR_RES_PAGING, NR_RES_MEAS_GAP, NR_RES_SMTC}, res) {
+				continue
+			}
+		}
+
+		grid.res[ire] = resTag(re[4])
+		if grid.tags[re[1]] == nil {
+			grid.tags[re[1]] = mapset.NewSet()
+		}
+		grid.tags[re[1]].Add(tag)
+	}
+
+	if len(collisions) > 0 {
+		fmt.Printf("%v occasion@[%v,%v] collides with: %v\n", tag, occasion[0][0], occasion[0][1], collisions)
+		return false
+	}
+
+	return true
+}
+
+// isCsiRes returns whether the NR resource is NZP-CSI-RS(including TRS) or CSI-IM, around which PDSCH scheduled by DCI 1_1 is rate matched.
+func isCsiRes(res int) bool {
+	return res == NR_RES_CSI_IM || res == NR_RES_TRS || (res >= NR_RES_CSI_RS_CDM_GRP_0 && res < NR_RES_CSI_RS_CDM_GRP_0+16)
+}
+
+// getDlGrid returns the DL resource grid(gridTdd for TDD, gridFddDl for FDD) of the radio frame sfn.
+func getDlGrid(sfn int) DataPerRf {
+	if flags.gridsetting._duplexMode == "TDD" {
+		return rgd.gridTdd[sfn]
+	} else {
+		return rgd.gridFddDl[sfn]
+	}
+}
+
+// getPdschGrid returns the resource grid of PDSCH scheduled by DCI 1_1 of the radio frame sfn, which is gridSup if SDL is used.
+func getPdschGrid(sfn int) DataPerRf {
+	if isSdlUsed() {
+		return rgd.gridSup[sfn]
+	}
+
+	return getDlGrid(sfn)
+}
+
+// aotSrs maps periodic SRS, starting from the given slot of radio frame sfn.
+func aotSrs(sfn, slot int) error {
+	if rgd.trSrs[sfn] {
+		return nil
+	}
+
+	// Note: periodic SRS is mapped in slots of the UL carrier, starting from the first slot which overlaps with slot of the carrier.
+	ulSlotPerRf, _, _ := getUlDims()
+	slot = getOverlapSlot(slot, rgd.slotPerRf, ulSlotPerRf, false)
+
+	// [sfn, slot] of dropped SRS occasions
+	var dropped []string
+
+	for i := range flags.srs._resId {
+		if flags.srs._resType[i] != "periodic" {
+			continue
+		}
+
+		period, _ := strconv.Atoi(flags.srs.srsPeriod[i][2:])
+		offset := flags.srs.srsOffset[i]
+		for s := slot; s < ulSlotPerRf; s++ {
+			if (sfn*ulSlotPerRf+s-offset)%period != 0 {
+				continue
+			}
+
+			occasion := getSrsOccasion(i, sfn, s)
+			if ok, cause := mapSrsOccasion(occasion); !ok {
+				dropped = append(dropped, fmt.Sprintf("SRS(resId=%v)@[%v,%v](%v)", flags.srs._resId[i], sfn, s, cause))
+			}
+		}
+	}
+
+	// periodic SRS-PosResource of SRS-PosResourceSet
+	for j := range flags.pos.srsPosResId {
+		period, _ := strconv.Atoi(flags.pos.srsPosPeriod[j][2:])
+		offset := flags.pos.srsPosOffset[j]
+		for s := slot; s < ulSlotPerRf; s++ {
+			if (sfn*ulSlotPerRf+s-offset)%period != 0 {
+				continue
+			}
+
+			occasion := getSrsPosOccasion(j, sfn, s)
+			if ok, cause := mapSrsOccasion(occasion); !ok {
+				dropped = append(dropped, fmt.Sprintf("SRS-Pos(resId=%v)@[%v,%v](%v)", flags.pos.srsPosResId[j], sfn, s, cause))
+			}
+		}
+	}
+
+	if len(dropped) > 0 {
+		fmt.Printf("[SFN=%v] Dropped SRS occasions: %v\n", sfn, dropped)
+	}
+
+	rgd.trSrs[sfn] = true
+
+	return nil
+}
+
+// getSrsOccasion returns REs of an SRS occasion, where each element is [sfn, slot, l, sc, res].
+//  i: index of the SRS resource
+//  sfn: radio frame of the SRS occasion
+//  slot: slot(of the UL carrier) of the SRS occasion
+func getSrsOccasion(i, sfn, slot int) [][]int {
+	// refer to 3GPP 38.211 vh40
+	// 6.4.1.4.3	Mapping to physical resources
+	numPorts := map[string]int{"port1": 1, "ports2": 2, "ports4": 4}[flags.srs.srsNumPorts[i]]
+	KTC, _ := strconv.Atoi(flags.srs.srsNumCombs[i][1:])
+	nCsMax := map[int]int{2: 8, 4: 12}[KTC]
+	numSymbs, _ := strconv.Atoi(flags.srs.srsNumSymbs[i][1:])
+	R, _ := strconv.Atoi(flags.srs.srsRepetition[i][1:])
+	period, _ := strconv.Atoi(flags.srs.srsPeriod[i][2:])
+	BSRS := flags.srs.srsBSrs[i]
+	bHop := flags.srs.srsBHop[i]
+	nRRC := flags.srs.srsFreqPos[i]
+	nShift := flags.srs.srsFreqShift[i]
+	var mSRSb, Nb []int
+	for _, v := range strings.Split(flags.srs._mSRSb[i], "_") {
+		k, _ := strconv.Atoi(v)
+		mSRSb = append(mSRSb, k)
+	}
+	for _, v := range strings.Split(flags.srs._Nb[i], "_") {
+		k, _ := strconv.Atoi(v)
+		Nb = append(Nb, k)
+	}
+
+	// the starting position in the time domain
+	// l0 = N_symb_slot - 1 - l_offset
+	l0 := rgd.symbPerSlot - 1 - flags.srs.srsStartPos[i]
+
+	// the transmission comb(s) and SRS resources of each comb
+	//  k_TC(pi) = (k_TC_bar + K_TC/2) mod K_TC if n_SRS_cs in {n_SRS_cs_max/2,...,n_SRS_cs_max-1} and N_SRS_ap = 4 and pi in {1001,1003}
+	//  k_TC(pi) = k_TC_bar otherwise
+	kTcBar := flags.srs.srsCombOff[i]
+	combs := make(map[int]int)
+	switch {
+	case numPorts == 1:
+		combs[kTcBar] = NR_RES_SRS0
+	case numPorts == 2:
+		combs[kTcBar] = NR_RES_SRS0_1
+	case numPorts == 4 && flags.srs.srsCs[i] >= nCsMax/2:
+		combs[kTcBar] = NR_RES_SRS0_2
+		combs[(kTcBar+KTC/2)%KTC] = NR_RES_SRS1_3
+	default:
+		combs[kTcBar] = NR_RES_SRS0_1_2_3
+	}
+
+	// the reference point for k0
+	//  If N_BWP_start <= n_shift, the reference point for k0 = 0 is subcarrier 0 in common resource block 0, otherwise the reference point is the lowest subcarrier of the BWP.
+	offsetToCarrier, carrierNumRbs := getUlCarrier()
+	bwpStart, _ := getUlBwp(DED_UL_BWP)
+	bwpStartCrb := offsetToCarrier + bwpStart
+	refSc := 0
+	if bwpStartCrb > nShift {
+		refSc = bwpStartCrb * rgd.scPerRb
+	}
+	refSc -= offsetToCarrier * rgd.scPerRb
+
+	ulSlotPerRf, _, _ := getUlDims()
+	var occasion [][]int
+	for lap := 0; lap < numSymbs; lap++ {
+		// the SRS counter
+		//  n_SRS = ((N_slot_frame * n_f + n_s - T_offset) / T_SRS) * (N_SRS_symb / R) + floor(l'/R) for periodic SRS
+		nSrs := ((sfn*ulSlotPerRf+slot-flags.srs.srsOffset[i])/period)*(numSymbs/R) + utils.FloorInt(float64(lap)/float64(R))
+
+		// the frequency position index n_b
+		sumNb := 0
+		for b := 0; b <= BSRS; b++ {
+			var nb int
+			if bHop >= BSRS || b <= bHop {
+				nb = utils.FloorInt(float64(4*nRRC)/float64(mSRSb[b])) % Nb[b]
+			} else {
+				nb = (getSrsFb(b, bHop, nSrs, Nb) + utils.FloorInt(float64(4*nRRC)/float64(mSRSb[b]))) % Nb[b]
+			}
+			// M_sc_b = m_SRS_b * N_RB_sc / K_TC
+			sumNb += KTC * (mSRSb[b] * rgd.scPerRb / KTC) * nb
+		}
+
+		for kTc, res := range combs {
+			// k0_bar = n_shift * N_RB_sc + (k_TC + k_offset) mod K_TC, where k_offset = 0 when SRS is not configured by SRS-PosResourceSet
+			k0 := refSc + nShift*rgd.scPerRb + kTc%KTC + sumNb
+			for m := 0; m < mSRSb[BSRS]*rgd.scPerRb/KTC; m++ {
+				sc := k0 + KTC*m
+				if sc >= 0 && sc < carrierNumRbs*rgd.scPerRb {
+					occasion = append(occasion, []int{sfn, slot, l0 + lap, sc, res})
+				}
+			}
+		}
+	}
+
+	return occasion
+}
+
+// getSrsPosOccasion returns REs of an SRS occasion of SRS-PosResource, where each element is [sfn, slot, l, sc, res].
+//  j: index of the SRS-PosResource
+//  sfn: radio frame of the SRS occasion
+//  slot: slot of the SRS occasion
+func getSrsPosOccasion(j, sfn, slot int) [][]int {
+	// refer to 3GPP 38.211 vh40
+	// 6.4.1.4.3	Mapping to physical resources
+	// k0_bar = n_shift * N_RB_sc + (k_TC + k_offset) mod K_TC, where k_offset is given by Table 6.4.1.4.3-2 for SRS configured by SRS-PosResourceSet
+	// Note: SRS-PosResource is transmitted on a single antenna port without frequency hopping, i.e. B_SRS = 0 and m_SRS,0 is given by c-SRS.
+	KTC, _ := strconv.Atoi(flags.pos.srsPosNumCombs[j][1:])
+	numSymbs, _ := strconv.Atoi(flags.pos.srsPosNumSymbs[j][1:])
+	kOffset := map[int][]int{
+		2: {0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1},
+		4: {0, 2, 1, 3, 0, 2, 1, 3, 0, 2, 1, 3},
+		8: {0, 4, 2, 6, 1, 5, 3, 7, 0, 4, 2, 6},
+	}[KTC]
+	mSRS0 := nrgrid.SrsBwCfg[fmt.Sprint(flags.pos.srsPosCSrs[j])].MSRSb[0]
+	nShift := flags.pos.srsPosFreqShift[j]
+
+	// the starting position in the time domain
+	// l0 = N_symb_slot - 1 - l_offset
+	l0 := rgd.symbPerSlot - 1 - flags.pos.srsPosStartPos[j]
+
+	// the reference point for k0
+	//  If N_BWP_start <= n_shift, the reference point for k0 = 0 is subcarrier 0 in common resource block 0, otherwise the reference point is the lowest subcarrier of the BWP.
+	offsetToCarrier, carrierNumRbs := getUlCarrier()
+	bwpStart, _ := getUlBwp(DED_UL_BWP)
+	bwpStartCrb := offsetToCarrier + bwpStart
+	refSc := 0
+	if bwpStartCrb > nShift {
+		refSc = bwpStartCrb * rgd.scPerRb
+	}
+	refSc -= offsetToCarrier * rgd.scPerRb
+
+	var occasion [][]int
+	for lap := 0; lap < numSymbs; lap++ {
+		k0 := refSc + nShift*rgd.scPerRb + (flags.pos.srsPosCombOff[j]+kOffset[lap])%KTC
+		for m := 0; m < mSRS0*rgd.scPerRb/KTC; m++ {
+			sc := k0 + KTC*m
+			if sc >= 0 && sc < carrierNumRbs*rgd.scPerRb {
+				occasion = append(occasion, []int{sfn, slot, l0 + lap, sc, NR_RES_SRS_POS})
+			}
+		}
+	}
+
+	return occasion
+}
+
+// getSrsFb returns F_b(n_SRS) for SRS frequency hopping.
+func getSrsFb(b, bHop, nSrs int, Nb []int) int {
+	// refer to 3GPP 38.211 vh40
+	// 6.4.1.4.3	Mapping to physical resources
+	// regardless of the value of N_b, N_b_hop = 1
+	prod := func(b2 int) int {
+		p := 1
+		for k := bHop; k <= b2; k++ {
+			if k != bHop {
+				p *= Nb[k]
+			}
+		}
+		return p
+	}
+
+	if Nb[b]%2 == 0 {
+		return (Nb[b]/2)*utils.FloorInt(float64(nSrs%prod(b))/float64(prod(b-1))) + utils.FloorInt(float64(nSrs%prod(b))/float64(2*prod(b-1)))
+	} else {
+		return utils.FloorInt(float64(Nb[b])/2) * utils.FloorInt(float64(nSrs)/float64(prod(b-1)))
+	}
+}
+
+// mapSrsOccasion maps an SRS occasion if all its REs are uplink and not occupied by other SRS resources, and returns whether the occasion is mapped and the cause if it's dropped.
+//  occasion: REs of the occasion, where each element is [sfn, slot, l, sc, res]
+func mapSrsOccasion(occasion [][]int) (bool, string) {
+	if len(occasion) == 0 {
+		return false, "no RE"
+	}
+
+	// refer to 3GPP 38.213 vh40
+	// 11.1.1	UE procedure for determining slot format
+	// For a set of symbols of a slot that are indicated as downlink or flexible by the SFI-index field value in DCI format 2_0, ... the UE cancels the transmission of the SRS in the set of symbols of the slot.
+	symbs := make(map[int]bool)
+	for _, re := range occasion {
+		if !symbs[re[2]] {
+			symbs[re[2]] = true
+			// Note: the dropped SRS occasion is reported once by aotSrs, hence it's not recorded as a slot format conflict.
+			if !isTddSymbs(re[0], re[1], re[2], 1, "U") {
+				return false, fmt.Sprintf("slot format=%v", getTddPat(re[0])[re[1]*rgd.symbPerSlot+re[2]])
+			}
+		}
+	}
+
+	_, scPerSymb, scPerSlot := getUlDims()
+	collisions := make(map[string]int)
+	for _, re := range occasion {
+		grid := getUlGrid(re[0])
+		res := grid.res[re[1]*scPerSlot+re[2]*scPerSymb+re[3]]
+		if res != NR_RES_U {
+			collisions[resCategory(res)]++
+		}
+	}
+
+	if len(collisions) > 0 {
+		return false, fmt.Sprintf("collisions=%v", collisions)
+	}
+
+	for _, re := range occasion {
+		grid := getUlGrid(re[0])
+		grid.res[re[1]*scPerSlot+re[2]*scPerSymb+re[3]] = re[4]
+		if grid.tags[re[1]] == nil {
+			grid.tags[re[1]] = mapset.NewSet()
+		}
+		grid.tags[re[1]].Add("SRS")
+	}
+
+	return true, ""
+}
+
+// getUlGrid returns the UL resource grid(gridSup for SUL, gridTdd for TDD, gridFddUl for FDD) of the radio frame sfn.
+func getUlGrid(sfn int) DataPerRf {
+	if isSulUsed() {
+		return rgd.gridSup[sfn]
+	} else if flags.gridsetting._duplexMode == "TDD" {
+		return rgd.gridTdd[sfn]
+	} else {
+		return rgd.gridFddUl[sfn]
+	}
+}
+
+// recvSib1 returns the radio frame and slot of SIB1 PDSCH associated with the best SSB, starting from radio frame sfn.
+// Note: aotCommon instead of alwaysOnTr is used during RACH procedure so that periodic CSI-RS/SRS are mapped only after HARQ-ACK of Msg4.
+func recvSib1(sfn int) (int, int, error) {
+	if !utils.ContainsInt(flags.gridsetting.candSsbIndex, flags.advanced.bestSsb) {
+		return -1, -1, errors.New(fmt.Sprintf("Invalid bestSsb(=%v) which must be one of candSsbIndex(=%v)!", flags.advanced.bestSsb, flags.gridsetting.candSsbIndex))
+	}
+
+	// refer to 3GPP 38.331 vh30
+	// 5.2.1	Introduction (of System information)
+	// The SIB1 is transmitted on the DL-SCH with a periodicity of 160 ms ...
+	for i := 0; i < 16; i++ {
+		if err := aotCommon(sfn + i); err != nil {
+			return -1, -1, err
+		}
+
+		if loc, exist := rgd.sib1Loc[fmt.Sprintf("%v_%v", sfn+i, flags.advanced.bestSsb)]; exist {
+			return loc[0], loc[1], nil
+		}
+	}
+
+	return -1, -1, errors.New(fmt.Sprintf("No SIB1 associated with bestSsb(=%v) is transmitted within 160ms starting from SFN=%v!", flags.advanced.bestSsb, sfn))
+}
+
+// getPrachTdOccasions returns TD pattern of PRACH occasions within a radio frame, where each element is [firstSymb, numSymbs, t_id, s_id] and firstSymb is relative to the start of the radio frame.
+func getPrachTdOccasions() [][]int {
+	// Note: PRACH occasions are mapped to the UL resource grid, whose numerology is that of SUL carrier if SUL is used.
+	ulSlotPerRf, _, _ := getUlDims()
+	slotPerSubf := ulSlotPerRf / rgd.subfPerRf
+	var tdOccasions [][]int
+	if utils.ContainsStr([]string{"0", "1", "2", "3"}, flags.rach._raFormat) {
+		// refer to 3GPP 38.211 vh40
+		// Table 6.3.3.1-1: PRACH preamble formats for L_RA=839 and Δf_RA∈{1.25,5} kHz.
+		// Note: duration(CP+sequence+GP) of long PRACH is measured in symbols of 15KHz, and t_id/s_id of RA-RNTI is based on u=0.
+		numSymbs := map[string]int{"0": 14, "1": 42, "2": 49, "3": 14}[flags.rach._raFormat] * slotPerSubf
+		for _, subf := range flags.rach._raSubfNumFr1SlotNumFr2 {
+			firstSymb := subf*slotPerSubf*rgd.symbPerSlot + flags.rach._raStartingSymb*slotPerSubf
+			tdOccasions = append(tdOccasions, []int{firstSymb, numSymbs, subf, flags.rach._raStartingSymb})
+		}
+	} else {
+		// refer to 3GPP 38.211 vh40
+		// 5.3.2	OFDM baseband signal generation
+		// - n_RA_slot is given by
+		//   - if deltaf_RA is {30, 120}kHz and either of "Number of PRACH slots within a subframe" in Tables 6.3.3.2-2 to 6.3.3.2-3 or "Number of PRACH slots within a 60 kHz slot" in Table 6.3.3.2-4 is equal to 1, then n_RA_slot = 1, otherwise n_RA_slot = {0,1}
+		// Note: Msg1 SCS of short PRACH is the same as the SCS of the UL carrier, and n_RA_slot is always 0 for 15/60KHz.
+		var nRaSlots []int
+		if flags.rach._raNumSlotsPerSubfFr1Per60KSlotFr2 == 2 {
+			nRaSlots = []int{0, 1}
+		} else if flags.rach._msg1Scs == "30KHz" || flags.rach._msg1Scs == "120KHz" {
+			nRaSlots = []int{1}
+		} else {
+			nRaSlots = []int{0}
+		}
+
+		// the reference period is subframe for FR1 and 60KHz slot for FR2
+		slotPerRef := slotPerSubf
+		if flags.gridsetting._freqRange != "FR1" {
+			slotPerRef = slotPerSubf / 4
+		}
+
+		for _, ref := range flags.rach._raSubfNumFr1SlotNumFr2 {
+			for _, nRaSlot := range nRaSlots {
+				for t := 0; t < flags.rach._raNumOccasionsPerSlot; t++ {
+					firstSymb := ref*slotPerRef*rgd.symbPerSlot + nRaSlot*rgd.symbPerSlot + flags.rach._raStartingSymb + t*flags.rach._raDuration
+					tdOccasions = append(tdOccasions, []int{firstSymb, flags.rach._raDuration, firstSymb / rgd.symbPerSlot, firstSymb % rgd.symbPerSlot})
+				}
+			}
+		}
+	}
+
+	return tdOccasions
+}
+
+// getRoSsbs returns the SSB indexes which are mapped to the j-th valid PRACH occasion of a SSB-to-PRACH occasion mapping cycle.
+//  j: index of valid PRACH occasion within the mapping cycle
+//  N: number of SSBs per PRACH occasion
+func getRoSsbs(j int, N float64) []int {
+	ssbs := flags.gridsetting.candSsbIndex
+	if N < 1 {
+		return []int{ssbs[utils.FloorInt(float64(j)*N)]}
+	}
+
+	return ssbs[j*int(N) : utils.MinInt([]int{(j + 1) * int(N), len(ssbs)})]
+}
+
+// getValidPrachOccasions returns valid PRACH occasions of radio frame sfn, where each element is [sfn, index of tdOccasions, f].
+//  tdOccasions: TD pattern of PRACH occasions returned by getPrachTdOccasions
+func getValidPrachOccasions(sfn int, tdOccasions [][]int) [][]int {
+	var ros [][]int
+	if !utils.ContainsInt(flags.rach._raY, sfn%flags.rach._raX) {
+		return ros
+	}
+
+	for i, td := range tdOccasions {
+		// refer to 3GPP 38.213 vh40
+		// 8.1	Random access preamble
+		// For unpaired spectrum, ... a PRACH occasion in a PRACH slot is valid if it is within UL symbols, ...
+		if flags.gridsetting._duplexMode == "TDD" && !isSulUsed() {
+			valid := true
+			for symb := td[0]; symb < td[0]+td[1]; symb++ {
+				pat := rgd.tddPatEvenRf
+				if (sfn+symb/rgd.symbPerRf)%2 == 1 {
+					pat = rgd.tddPatOddRf
+				}
+				if pat[symb%rgd.symbPerRf] != "U" {
+					valid = false
+					break
+				}
+			}
+
+			if !valid {
+				continue
+			}
+		}
+
+		for f := 0; f < flags.rach.msg1Fdm; f++ {
+			ros = append(ros, []int{sfn, i, f})
+		}
+	}
+
+	return ros
+}
+
+// getPrachAssocPeriod returns the association period(in number of radio frames) for mapping SSB indexes to PRACH occasions, and the number of PRACH occasions per SSB-to-PRACH occasion mapping cycle.
+//  tdOccasions: TD pattern of PRACH occasions returned by getPrachTdOccasions
+func getPrachAssocPeriod(tdOccasions [][]int) (int, int, error) {
+	// refer to 3GPP 38.213 vh40
+	// 8.1	Random access preamble
+	// SS/PBCH block indexes are mapped to valid PRACH occasions in the following order
+	// - First, in increasing order of preamble indexes within a single PRACH occasion
+	// - Second, in increasing order of frequency resource indexes for frequency multiplexed PRACH occasions
+	// - Third, in increasing order of time resource indexes for time multiplexed PRACH occasions within a PRACH slot
+	// - Fourth, in increasing order of indexes for PRACH slots
+	// An association period, starting from frame 0, for mapping SS/PBCH block indexes to PRACH occasions is the smallest value in the set determined by the PRACH configuration period according to Table 8.1-1 such that N_Tx_SSB SS/PBCH block indexes are mapped at least once to the PRACH occasions within the association period
+	N := nrgrid.SsbPerRachOccasion2Float[flags.rach.ssbPerRachOccasion]
+	numTxSsb := len(flags.gridsetting.candSsbIndex)
+	numRosPerCycle := utils.CeilInt(float64(numTxSsb) / N)
+
+	apLen := -1
+	for _, T := range []int{1, 2, 4, 8, 16} {
+		if T*flags.rach._raX > 16 {
+			break
+		}
+
+		numRos := 0
+		for i := 0; i < T*flags.rach._raX; i++ {
+			numRos += len(getValidPrachOccasions(i, tdOccasions))
+		}
+		if numRos >= numRosPerCycle {
+			apLen = T * flags.rach._raX
+			break
+		}
+	}
+	if apLen < 0 {
+		return -1, -1, errors.New(fmt.Sprintf("No valid association period(max 160ms) for SSB to PRACH occasion mapping: numTxSsb=%v, ssbPerRachOccasion=%v, prachConfId=%v", numTxSsb, flags.rach.ssbPerRachOccasion, flags.rach.prachConfId))
+	}
+
+	return apLen, numRosPerCycle, nil
+}
+
+// sendMsg1 selects the PRACH occasion associated with the best SSB and maps it to the UL resource grid, and returns the radio frame and slot(of the UL carrier) of the last symbol of the selected PRACH occasion.
+//  sfn: radio frame of SIB1
+//  slot: slot of SIB1
+func sendMsg1(sfn, slot int) (int, int, error) {
+	tdOccasions := getPrachTdOccasions()
+	fmt.Printf("TD pattern of PRACH occasions within a radio frame([firstSymb, numSymbs, t_id, s_id]): %v\n", tdOccasions)
+
+	N := nrgrid.SsbPerRachOccasion2Float[flags.rach.ssbPerRachOccasion]
+	apLen, numRosPerCycle, err := getPrachAssocPeriod(tdOccasions)
+	if err != nil {
+		return -1, -1, err
+	}
+	fmt.Printf("SSB to PRACH occasion mapping: numTxSsb=%v, ssbPerRachOccasion=%v, numRosPerCycle=%v, association period=%v radio frame(s)\n", len(flags.gridsetting.candSsbIndex), N, numRosPerCycle, apLen)
+
+	// select PRACH occasion for Msg1 after SIB1 is received
+	// Note: PRACH occasions are in symbols of the UL carrier, which starts from the first UL slot after the slot of SIB1.
+	occ := utils.MaxInt([]int{flags.advanced.prachOccMsg1, 0})
+	ulSlotPerRf, _, _ := getUlDims()
+	symbPerRf := ulSlotPerRf * rgd.symbPerSlot
+	startSymb := (getOverlapSlot(sfn*rgd.slotPerRf+slot, rgd.slotPerRf, ulSlotPerRf, true) + 1) * rgd.symbPerSlot
+	var ro []int
+	var ros [][]int
+	iro := -1
+	n := 0
+	for ap := sfn / apLen; ap <= (sfn+1024)/apLen && ro == nil; ap++ {
+		ros = nil
+		for i := ap * apLen; i < (ap+1)*apLen; i++ {
+			ros = append(ros, getValidPrachOccasions(i, tdOccasions)...)
+		}
+
+		// valid PRACH occasions not mapped to SSB after integer number of SSB-to-PRACH mapping cycles are not used
+		numCycles := len(ros) / numRosPerCycle
+		for i := 0; i < numCycles*numRosPerCycle; i++ {
+			j := i % numRosPerCycle
+			if !utils.ContainsInt(getRoSsbs(j, N), flags.advanced.bestSsb) {
+				continue
+			}
+
+			if ros[i][0]*symbPerRf+tdOccasions[ros[i][1]][0] < startSymb {
+				continue
+			}
+
+			if n == occ {
+				ro = ros[i]
+				iro = i
+				break
+			}
+			n++
+		}
+	}
+	if ro == nil {
+		return -1, -1, errors.New(fmt.Sprintf("No valid PRACH occasion for Msg1: bestSsb=%v, prachOccMsg1=%v, starting from [SFN=%v, slot=%v]", flags.advanced.bestSsb, flags.advanced.prachOccMsg1, sfn, slot+1))
+	}
+
+	// map PRACH occasion of Msg1
+	td := tdOccasions[ro[1]]
+	rbStart, sfnu, symbu, collisions, err := mapPrachOccasion(ro, td, flags.advanced.bestSsb)
+	if err != nil {
+		return -1, -1, err
+	}
+
+	// SSB-to-PRACH occasion mapping of all transmitted SSBs within the mapping cycle of the PRACH occasion of Msg1
+	// Note: PRACH occasion mapped to multiple SSBs(ssbPerRachOccasion > 1) is labeled with the best SSB if it's one of them, or the first SSB otherwise.
+	cycleStart := (iro / numRosPerCycle) * numRosPerCycle
+	for j := 0; j < numRosPerCycle; j++ {
+		v := ros[cycleStart+j]
+		ssbs := getRoSsbs(j, N)
+		fmt.Printf("SSB to PRACH occasion mapping: SSB index=%v -> PRACH occasion@[sfn=%v, firstSymb=%v, numSymbs=%v, f=%v]\n", ssbs, v[0], tdOccasions[v[1]][0], tdOccasions[v[1]][1], v[2])
+		if cycleStart+j == iro {
+			continue
+		}
+
+		beam := ssbs[0]
+		if utils.ContainsInt(ssbs, flags.advanced.bestSsb) {
+			beam = flags.advanced.bestSsb
+		}
+		if _, _, _, _, err := mapPrachOccasion(v, tdOccasions[v[1]], beam); err != nil {
+			return -1, -1, err
+		}
+	}
+
+	// refer to 3GPP 38.321 vh40
+	// 5.1.3	Random Access Preamble transmission
+	// RA-RNTI = 1 + s_id + 14 × t_id + 14 × 80 × f_id + 14 × 80 × 8 × ul_carrier_id
+	rgd.raRnti = 1 + td[3] + 14*td[2] + 14*80*ro[2]
+	rgd.msg1Ro = ro
+	fmt.Printf("Msg1(PRACH): bestSsb=%v, PRACH occasion@[sfn=%v, firstSymb=%v, numSymbs=%v, f=%v], rbStart=%v, numRbs=%v, RA-RNTI=%v, collisions=%v\n", flags.advanced.bestSsb, ro[0], td[0], td[1], ro[2], rbStart, flags.rach._raNumRbs, rgd.raRnti, collisions)
+
+	return sfnu, symbu / rgd.symbPerSlot, nil
+}
+
+// mapPrachOccasion maps PRACH occasion in UL symbols, and returns the starting RB, the radio frame and symbol of the last PRACH symbol, and collisions.
+//  ro: PRACH occasion as [sfn, index of tdOccasions, f]
+//  td: TD pattern of the PRACH occasion
+//  beam: SSB index which is associated with the PRACH occasion
+func mapPrachOccasion(ro, td []int, beam int) (int, int, int, map[string]int, error) {
+	// refer to 3GPP 38.211 vh40
+	// 5.3.2	OFDM baseband signal generation
+	// n_RA_start is the offset of lowest PRACH transmission occasion in frequency domain with respect to PRB 0 of the initial uplink bandwidth part given by msg1-FrequencyStart, and n_RA is the PRACH transmission occasion index in frequency domain for a given time instance.
+	// Note: the whole PRBs of PRACH occasion, including guard subcarriers, are mapped.
+	bwpStart, _ := getUlBwp(INI_UL_BWP)
+	rbStart := bwpStart + flags.rach.msg1FreqStart + ro[2]*flags.rach._raNumRbs
+	if _, carrierNumRbs := getUlCarrier(); rbStart+flags.rach._raNumRbs > carrierNumRbs {
+		return -1, -1, -1, nil, errors.New(fmt.Sprintf("PRACH occasion(rbStart=%v, numRbs=%v) is out of the UL carrier bandwidth(%v RBs).", rbStart, flags.rach._raNumRbs, carrierNumRbs))
+	}
+
+	ulSlotPerRf, scPerSymb, _ := getUlDims()
+	symbPerRf := ulSlotPerRf * rgd.symbPerSlot
+	collisions := make(map[string]int)
+	var sfnu, symbu int
+	for i := 0; i < td[1]; i++ {
+		sfnu = ro[0] + (td[0]+i)/symbPerRf
+		symbu = (td[0] + i) % symbPerRf
+		if err := aotCommon(sfnu); err != nil {
+			return -1, -1, -1, nil, err
+		}
+
+		grid := getUlGrid(sfnu)
+		for isc := rbStart * rgd.scPerRb; isc < (rbStart+flags.rach._raNumRbs)*rgd.scPerRb; isc++ {
+			ire := symbu*scPerSymb + isc
+			if grid.res[ire] != NR_RES_U {
+				collisions[resCategory(grid.res[ire])]++
+				continue
+			}
+			grid.res[ire] = NR_RES_PRACH
+			grid.beams[ire] = beam
+		}
+
+		if grid.tags[symbu/rgd.symbPerSlot] == nil {
+			grid.tags[symbu/rgd.symbPerSlot] = mapset.NewSet()
+		}
+		grid.tags[symbu/rgd.symbPerSlot].Add("PRACH")
+	}
+
+	return rbStart, sfnu, symbu, collisions, nil
+}
+
+// sendMsgA transmits MsgA preamble and maps MsgA PUSCH occasion associated with the preamble, and returns the radio frame and slot(of the UL carrier) of MsgA PUSCH.
+//  sfn: radio frame of SIB1
+//  slot: slot of SIB1
+func sendMsgA(sfn, slot int) (int, int, error) {
+	// MsgA preamble
+	if _, _, err := sendMsg1(sfn, slot); err != nil {
+		return -1, -1, err
+	}
+
+	// refer to 3GPP 38.321 vh40
+	// 5.1.3a	MSGA transmission
+	// MSGB-RNTI = 1 + s_id + 14 × t_id + 14 × 80 × f_id + 14 × 80 × 8 × ul_carrier_id + 14 × 80 × 8 × 2
+	rgd.msgBRnti = rgd.raRnti + 14*80*8*2
+
+	// Note: MsgA PRACH and PUSCH occasions are in slots and symbols of the UL carrier, which is SUL carrier if SUL is used.
+	ulSlotPerRf, scPerSymb, scPerSlot := getUlDims()
+	symbPerRf := ulSlotPerRf * rgd.symbPerSlot
+	tdOccasions := getPrachTdOccasions()
+	ro := rgd.msg1Ro
+	prachSlot := (ro[0]*symbPerRf + tdOccasions[ro[1]][0]) / rgd.symbPerSlot
+
+	// contention-based preambles associated with the best SSB
+	// Note: MsgA preambles are assumed to be the cb-PreamblesPerSSB preambles of PRACH occasions which are not shared with 4-step RA.
+	N := nrgrid.SsbPerRachOccasion2Float[flags.rach.ssbPerRachOccasion]
+	posBestSsb := utils.IndexInt(flags.gridsetting.candSsbIndex, flags.advanced.bestSsb)
+	preambsPerRo := flags.rach.cbPreambsPerSsb
+	preamb := flags.advanced.msgAPreamb
+	if N >= 1 {
+		preambsPerRo *= int(N)
+		preamb += (posBestSsb % int(N)) * flags.rach.cbPreambsPerSsb
+	}
+
+	// refer to 3GPP 38.213 vh40
+	// 8.1A	PUSCH for Type-2 random access procedure
+	// A UE determines a first slot for a first PUSCH occasion in an active UL BWP from msgA-PUSCH-TimeDomainOffset, relative to the start of each PRACH slot, ... nrofSlotsMsgA-PUSCH consecutive slots, ... nrofMsgA-PO-PerSlot PUSCH occasions in each slot separated by guardPeriodMsgA-PUSCH symbols, ... nrofMsgA-PO-FDM PUSCH occasions in frequency separated by guardBandMsgA-PUSCH RBs, the first of which starts from frequencyStartMsgA-PUSCH from the first RB of the active UL BWP.
+	// A PUSCH occasion is valid if it does not overlap in time and frequency with any valid PRACH occasion associated with either a Type-1 random access procedure or a Type-2 random access procedure.
+	// For unpaired spectrum, a PUSCH occasion is valid if it is within UL symbols.
+	// PUSCH occasions are ordered first in increasing order of frequency resource indexes for frequency multiplexed PUSCH occasions, second in increasing order of DMRS resource indexes within a PUSCH occasion, third in increasing order of time resource indexes for time multiplexed PUSCH occasions within a PUSCH slot, and fourth in increasing order of indexes for PUSCH slots.
+	// Note: only one DMRS resource per PUSCH occasion is assumed.
+	S := flags.uldci._tdStartSymb[RA_UL_MSGA]
+	L := flags.uldci._tdNumSymbs[RA_UL_MSGA]
+	numRbs := flags.uldci.fdNumRbs[RA_UL_MSGA]
+	bwpStart, _ := getUlBwp(INI_UL_BWP)
+	getPos := func(prachSlot int) [][]int {
+		var pos [][]int // valid PUSCH occasions, where each element is [slot relative to SFN 0, firstSymb, rbStart]
+		for s := 0; s < flags.rach.msgANumSlots; s++ {
+			for t := 0; t < flags.rach.msgANumPosPerSlot; t++ {
+				for f := 0; f < flags.rach.msgANumPosFdm; f++ {
+					po := []int{prachSlot + flags.uldci._tdK2[RA_UL_MSGA] + s, S + t*(L+flags.rach.msgAGuardPeriod), bwpStart + flags.uldci.fdStartRb[RA_UL_MSGA] + f*(numRbs+flags.rach.msgAGuardBand)}
+					poFirstSymb := po[0]*rgd.symbPerSlot + po[1]
+
+					valid := true
+					if flags.gridsetting._duplexMode == "TDD" && !isSulUsed() {
+						for symb := poFirstSymb; symb < poFirstSymb+L; symb++ {
+							pat := rgd.tddPatEvenRf
+							if (symb/symbPerRf)%2 == 1 {
+								pat = rgd.tddPatOddRf
+							}
+							if pat[symb%symbPerRf] != "U" {
+								valid = false
+								break
+							}
+						}
+					}
+
+					sfnPo := po[0] / ulSlotPerRf
+					for i := utils.MaxInt([]int{sfnPo - 1, 0}); i <= sfnPo && valid; i++ {
+						for _, v := range getValidPrachOccasions(i, tdOccasions) {
+							td := tdOccasions[v[1]]
+							roFirstSymb := v[0]*symbPerRf + td[0]
+							roRbStart := bwpStart + flags.rach.msg1FreqStart + v[2]*flags.rach._raNumRbs
+							if poFirstSymb < roFirstSymb+td[1] && roFirstSymb < poFirstSymb+L && po[2] < roRbStart+flags.rach._raNumRbs && roRbStart < po[2]+numRbs {
+								valid = false
+								break
+							}
+						}
+					}
+
+					if valid {
+						pos = append(pos, po)
+					}
+				}
+			}
+		}
+
+		return pos
+	}
+
+	// refer to 3GPP 38.213 vh40
+	// 8.1A	PUSCH for Type-2 random access procedure
+	// A consecutive number of N_preamble preamble indexes from valid PRACH occasions in a PRACH slot ... are mapped to a valid PUSCH occasion ..., where N_preamble = ceil(T_preamble/T_PUSCH), T_preamble is a total number of valid PRACH occasions per association pattern period multiplied by the number of preambles per valid PRACH occasion provided by msgA-RACH-ResourceGroupA/B, and T_PUSCH is a total number of valid PUSCH occasions per PUSCH configuration per association pattern period multiplied by the number of DMRS indexes per valid PUSCH occasion.
+	// An association pattern period includes one or more association periods and is determined so that a pattern between PRACH occasions and PUSCH occasions repeats at most every 160 msec. PRACH occasions and PUSCH occasions that are not mapped after an integer number of association periods, if any, are not used.
+	// Note: the validity of PRACH/PUSCH occasions depends on TDD-UL-DL-ConfigCommon which repeats every two radio frames, so the association pattern period is the association period if it is a multiple of two radio frames(or for paired spectrum), otherwise two association periods.
+	apLen, numRosPerCycle, err := getPrachAssocPeriod(tdOccasions)
+	if err != nil {
+		return -1, -1, err
+	}
+	appLen := apLen
+	if flags.gridsetting._duplexMode == "TDD" && !isSulUsed() && apLen%2 != 0 {
+		appLen *= 2
+	}
+	appStart := (ro[0] / appLen) * appLen
+
+	var pos [][]int
+	numRos, preambIdx, lastPrachSlot := 0, -1, -1
+	for ap := appStart; ap < appStart+appLen; ap += apLen {
+		var ros [][]int
+		for i := ap; i < ap+apLen; i++ {
+			ros = append(ros, getValidPrachOccasions(i, tdOccasions)...)
+		}
+
+		// valid PRACH occasions not mapped to SSB after integer number of SSB-to-PRACH mapping cycles are not used
+		numCycles := len(ros) / numRosPerCycle
+		for _, v := range ros[:numCycles*numRosPerCycle] {
+			if v[0] == ro[0] && v[1] == ro[1] && v[2] == ro[2] {
+				preambIdx = numRos*preambsPerRo + preamb
+			}
+			numRos++
+
+			// valid PUSCH occasions are determined relative to the start of each PRACH slot
+			s := (v[0]*symbPerRf + tdOccasions[v[1]][0]) / rgd.symbPerSlot
+			if s != lastPrachSlot {
+				pos = append(pos, getPos(s)...)
+				lastPrachSlot = s
+			}
+		}
+	}
+	if preambIdx < 0 {
+		return -1, -1, errors.New(fmt.Sprintf("PRACH occasion of MsgA preamble(=%v) is not mapped to SSB within the association pattern period([sfn=%v, %v radio frame(s)])", ro, appStart, appLen))
+	}
+	if len(pos) == 0 {
+		return -1, -1, errors.New(fmt.Sprintf("No valid MsgA PUSCH occasion within the association pattern period([sfn=%v, %v radio frame(s)]): K2=%v, S=%v, L=%v, msgANumSlots=%v, msgANumPosPerSlot=%v, msgANumPosFdm=%v", appStart, appLen, flags.uldci._tdK2[RA_UL_MSGA], S, L, flags.rach.msgANumSlots, flags.rach.msgANumPosPerSlot, flags.rach.msgANumPosFdm))
+	}
+
+	tPreamb := numRos * preambsPerRo
+	nPreamb := utils.CeilInt(float64(tPreamb) / float64(len(pos)))
+	po := pos[preambIdx/nPreamb]
+
+	sfnu := po[0] / ulSlotPerRf
+	nu := po[0] % ulSlotPerRf
+	if err := aotCommon(sfnu); err != nil {
+		return -1, -1, err
+	}
+
+	// refer to 3GPP 38.211 vh40
+	// 6.4.1.1.3	Precoding and mapping to physical resources (DMRS for PUSCH)
+	// l is defined relative to the start of the slot if frequency hopping is disabled and PUSCH mapping type A, relative to the start of the scheduled PUSCH resources if frequency hopping is disabled and PUSCH mapping type B
+	tdL, _, fdK := getDmrsPuschTdFdPattern("type1", flags.uldci._tdMappingType[RA_UL_MSGA], S, L, 1, flags.rach.msgADmrsAddPos, 2, "disabled", flags.bwp._bwpCp[INI_UL_BWP])
+	var dmrs []int
+	for _, l := range tdL {
+		if flags.uldci._tdMappingType[RA_UL_MSGA] == "typeA" {
+			dmrs = append(dmrs, l)
+		} else {
+			dmrs = append(dmrs, po[1]+l)
+		}
+	}
+
+	grid := getUlGrid(sfnu)
+	numMsgARes, numDmrsRes := 0, 0
+	collisions := make(map[string]int)
+	for symb := po[1]; symb < po[1]+L; symb++ {
+		isDmrs := utils.ContainsInt(dmrs, symb)
+		for rb := po[2]; rb < po[2]+numRbs; rb++ {
+			for isc := 0; isc < rgd.scPerRb; isc++ {
+				ire := nu*scPerSlot + symb*scPerSymb + rb*rgd.scPerRb + isc
+				if grid.res[ire] != NR_RES_U {
+					collisions[resCategory(grid.res[ire])]++
+					continue
+				}
+
+				// Note: MsgA PUSCH uses DMRS port 0 of configuration type 1, and REs of the other CDM group are DTX.
+				if isDmrs && fdK[isc] == 1 {
+					if isc%2 == 0 {
+						grid.res[ire] = NR_RES_DMRS_MSGA
+						numDmrsRes++
+					} else {
+						grid.res[ire] = NR_RES_DTX
+					}
+				} else {
+					grid.res[ire] = NR_RES_MSGA
+					numMsgARes++
+				}
+			}
+		}
+	}
+
+	if grid.tags[nu] == nil {
+		grid.tags[nu] = mapset.NewSet()
+	}
+	grid.tags[nu].Add("MSGA")
+
+	fmt.Printf("MsgA PUSCH: PRACH slot@[sfn=%v, slot=%v], preamble=%v, preambsPerRo=%v, association pattern period@[sfn=%v, %v radio frame(s)], validRos=%v, validPos=%v, N_preamble=%v, PUSCH@[sfn=%v, slot=%v, S=%v, L=%v], rbStart=%v, numRbs=%v, dmrs=%v, TBS=%v, REs of PUSCH=%v, REs of DMRS=%v, MSGB-RNTI=%v, collisions=%v\n", prachSlot/ulSlotPerRf, prachSlot%ulSlotPerRf, preamb, preambsPerRo, appStart, appLen, numRos, len(pos), nPreamb, sfnu, nu, po[1], L, po[2], numRbs, dmrs, flags.uldci._tbs[RA_UL_MSGA], numMsgARes, numDmrsRes, rgd.msgBRnti, collisions)
+
+	return sfnu, nu, nil
+}
+
+// monitorPdcch monitors PDCCH in Type1-PDCCH CSS set, and returns the radio frame and slot of the selected PDCCH occasion.
+//  sfn: radio frame where the monitoring window starts
+//  slot: slot after which the monitoring window starts
+//  dci: DCI format, which can be dci10 only
+//  rnti: RNTI of the DCI, which can be RA-RNTI(Msg2), MSGB-RNTI(MsgB) or TC-RNTI(Msg4)
+// Note: the Type1-PDCCH CSS set can be configured in either CORESET0 or CORESET1.
+func monitorPdcch(sfn, slot int, dci string, rnti string) (int, int, error) {
+	if dci != "dci10" {
+		return -1, -1, errors.New(fmt.Sprintf("Unsupported DCI format(=%v) in monitorPdcch!", dci))
+	}
+
+	iss := utils.IndexStr(flags.searchspace._ssType, "type1")
+	if iss < 0 {
+		return -1, -1, errors.New(fmt.Sprintf("Type1-PDCCH CSS set(ra-SearchSpace) is not configured!"))
+	}
+
+	// CORESET of the Type1-PDCCH CSS set
+	coresetId := flags.searchspace._ssCoresetId[iss]
+	coresetDuration, numCces, coresetCces, regBundles := flags.gridsetting._coreset0NumSymbs, rgd.coreset0NumCces, rgd.coreset0Cces, rgd.coreset0RegBundles
+	if coresetId != 0 {
+		coresetDuration, numCces, coresetCces, regBundles = flags.searchspace._coreset1Duration, rgd.coreset1NumCces, rgd.coreset1Cces, rgd.coreset1RegBundles
+	}
+	coresetSc0Rb0 := getDci10Sc0Rb0(DCI_10_MSG2)
+
+	// refer to 3GPP 38.321 vh40
+	// 5.1.4	Random Access Response reception
+	// start the ra-ResponseWindow configured in RACH-ConfigCommon at the first PDCCH occasion as specified in TS 38.213 [6] from the end of the Random Access Preamble transmission;
+	// 5.1.4a	MSGB reception and contention resolution for Random Access Procedure
+	// start the msgB-ResponseWindow at the PDCCH occasion as specified in TS 38.213 [6], clause 8.2A;
+	// 5.1.5	Contention Resolution
+	// start the ra-ContentionResolutionTimer and restart the ra-ContentionResolutionTimer at each HARQ retransmission in the first symbol after the end of the Msg3 transmission;
+	var winSize, occ int
+	switch rnti {
+	case "RA-RNTI":
+		winSize, _ = strconv.Atoi(flags.rach.raRespWin[2:])
+		occ = flags.advanced.pdcchOccMsg2
+	case "MSGB-RNTI":
+		winSize, _ = strconv.Atoi(flags.rach.msgBRespWin[2:])
+		occ = flags.advanced.pdcchOccMsg2
+	case "TC-RNTI":
+		winSize, _ = strconv.Atoi(flags.rach.contResTimer[2:])
+		winSize *= rgd.slotPerSubf
+		occ = flags.advanced.pdcchOccMsg4
+	default:
+		return -1, -1, errors.New(fmt.Sprintf("Unsupported RNTI(=%v) in monitorPdcch!", rnti))
+	}
+
+	period, _ := strconv.Atoi(flags.searchspace._ssPeriodicity[iss][2:])
+	offset := flags.searchspace._ssSlotOffset[iss]
+	duration := flags.searchspace._ssDuration[iss]
+	L, _ := strconv.Atoi(flags.searchspace.ssAggregationLevel[iss][2:])
+	M, _ := strconv.Atoi(flags.searchspace.ssNumOfPdcchCandidates[iss][1:])
+
+	n := 0
+	for i := 1; i <= winSize; i++ {
+		sfnc := (sfn*rgd.slotPerRf + slot + i) / rgd.slotPerRf
+		nc := (sfn*rgd.slotPerRf + slot + i) % rgd.slotPerRf
+
+		// refer to 3GPP 38.213 vh40
+		// 10.1	UE procedure for determining physical downlink control channel assignment
+		// A UE determines that a PDCCH monitoring occasion on an active DL BWP exists in a slot with number n_s_f_u in a frame with number n_f if (n_f*N_frame_slot + n_s_f_u - o_s) mod k_s = 0. The UE monitors PDCCH candidates for search space set s for T_s consecutive slots, starting from slot n_s_f_u, and does not monitor PDCCH candidates for search space set s for the next k_s - T_s consecutive slots.
+		if ((sfnc*rgd.slotPerRf+nc-offset)%period+period)%period >= duration {
+			continue
+		}
+
+		if err := aotCommon(sfnc); err != nil {
+			return -1, -1, err
+		}
+
+		grid := getDlGrid(sfnc)
+		for firstSymb, bit := range flags.searchspace._ssMonitoringSymbolWithinSlot[iss] {
+			if bit != '1' || firstSymb+coresetDuration > rgd.symbPerSlot {
+				continue
+			}
+
+			// select the first PDCCH candidate which doesn't collide with other channels
+			var cand []int
+			var m int
+			for m = 0; m < M && cand == nil; m++ {
+				cces, err := detCcesPerPdcchCand(coresetId, L, m, nc, "type1", 0, numCces, 0, M)
+				if err != nil {
+					return -1, -1, err
+				}
+
+				valid := true
+				for j, icce := range coresetCces {
+					if !utils.ContainsInt(cces, icce) {
+						continue
+					}
+
+					for isc := 0; isc < rgd.scPerRb; isc++ {
+						ire := nc*rgd.scPerSlot + (firstSymb+regBundles[j].Isymb)*rgd.scPerSymb + coresetSc0Rb0 + regBundles[j].Irb*rgd.scPerRb + isc
+						if grid.res[ire] != NR_RES_D {
+							valid = false
+							break
+						}
+					}
+				}
+
+				if valid {
+					cand = cces
+				}
+			}
+			if cand == nil {
+				continue
+			}
+
+			if n < occ {
+				n++
+				continue
+			}
+
+			// map PDCCH candidate
+			m--
+			for j, icce := range coresetCces {
+				if !utils.ContainsInt(cand, icce) {
+					continue
+				}
+
+				for isc := 0; isc < rgd.scPerRb; isc++ {
+					ire := nc*rgd.scPerSlot + (firstSymb+regBundles[j].Isymb)*rgd.scPerSymb + coresetSc0Rb0 + regBundles[j].Irb*rgd.scPerRb + isc
+					if isc > 0 && (isc-1)%4 == 0 {
+						grid.res[ire] = NR_RES_DMRS_PDCCH
+					} else {
+						grid.res[ire] = NR_RES_PDCCH_CANDIDATE + m
+					}
+				}
+			}
+
+			if grid.tags[nc] == nil {
+				grid.tags[nc] = mapset.NewSet()
+			}
+			grid.tags[nc].Add("PDCCH")
+
+			rgd.dci10Cce0 = cand[0]
+			rgd.dci10NumCces = numCces
+			fmt.Printf("PDCCH(DCI 1_0, %v): CORESET%v, PDCCH occasion@[sfn=%v, slot=%v, firstSymb=%v, m=%v], cces=%v\n", rnti, coresetId, sfnc, nc, firstSymb, m, cand)
+
+			return sfnc, nc, nil
+		}
+	}
+
+	return -1, -1, errors.New(fmt.Sprintf("No valid PDCCH occasion(DCI 1_0, %v) within monitoring window of %v slots: pdcchOccMsg2=%v, pdcchOccMsg4=%v", rnti, winSize, flags.advanced.pdcchOccMsg2, flags.advanced.pdcchOccMsg4))
+}
+
+// recvMsg2 maps Msg2(RAR) PDSCH scheduled by DCI 1_0 with RA-RNTI, and returns the radio frame and slot of Msg2.
+func recvMsg2(sfn, slot int) (int, int, error) {
+	return recvDci10Pdsch(DCI_10_MSG2, sfn, slot)
+}
+
+// recvMsg4 maps Msg4 PDSCH scheduled by DCI 1_0 with TC-RNTI, and returns the radio frame and slot of Msg4.
+func recvMsg4(sfn, slot int) (int, int, error) {
+	sfnd, nd, err := recvDci10Pdsch(DCI_10_MSG4, sfn, slot)
+	if err != nil {
+		return -1, -1, err
+	}
+
+	rgd.msg4Recved = true
+	rgd.msg4Slot = sfnd*rgd.slotPerRf + nd
+
+	return sfnd, nd, nil
+}
+
+// recvMsgB maps MsgB PDSCH scheduled by DCI 1_0 with MSGB-RNTI, and returns the radio frame and slot of MsgB.
+func recvMsgB(sfn, slot int) (int, int, error) {
+	sfnd, nd, err := recvDci10Pdsch(DCI_10_MSGB, sfn, slot)
+	if err != nil {
+		return -1, -1, err
+	}
+
+	// refer to 3GPP 38.321 vh40
+	// 5.1.4a	MSGB reception and contention resolution for Random Access Procedure
+	// if the MSGB contains a fallbackRAR MAC subPDU; and if the Random Access Preamble transmitted is identified by the RAPID in the MAC subPDU: ... proceed with Msg3 transmission as in 4-step RA type.
+	// if the MSGB contains a successRAR MAC subPDU ... consider this Random Access procedure successfully completed.
+	if flags.advanced.fallbackRar {
+		fmt.Printf("MsgB: fallbackRAR is received, and Msg3 is scheduled by fallbackRAR UL grant.\n")
+	} else {
+		fmt.Printf("MsgB: successRAR is received, and the random access procedure is successfully completed.\n")
+		rgd.msg4Recved = true
+		rgd.msg4Slot = sfnd*rgd.slotPerRf + nd
+	}
+
+	return sfnd, nd, nil
+}
+
+// recvDci10Pdsch maps PDSCH scheduled by DCI 1_0 which is received in slot of radio frame sfn, and returns the radio frame and slot of the PDSCH.
+//  i: index of DL DCI, which can be DCI_10_MSG2, DCI_10_MSG4 or DCI_10_MSGB
+func recvDci10Pdsch(i, sfn, slot int) (int, int, error) {
+	prbs, err := getDci10Prbs(i)
+	if err != nil {
+		return -1, -1, err
+	}
+
+	sfnd := (sfn*rgd.slotPerRf + slot + flags.dldci._tdK0[i]) / rgd.slotPerRf
+	nd := (sfn*rgd.slotPerRf + slot + flags.dldci._tdK0[i]) % rgd.slotPerRf
+	if err := aotCommon(sfnd); err != nil {
+		return -1, -1, err
+	}
+
+	numDataRes, numDmrsRes, collisions := mapDci10Pdsch(i, sfnd, nd, prbs, flags.advanced.bestSsb)
+	fmt.Printf("%v PDSCH: PDCCH@[sfn=%v, slot=%v], PDSCH@[sfn=%v, slot=%v, S=%v, L=%v], prbs=%v, REs of PDSCH=%v, REs of DMRS=%v, collisions=%v\n", map[int]string{DCI_10_MSG2: "Msg2", DCI_10_MSG4: "Msg4", DCI_10_MSGB: "MsgB"}[i], sfn, slot, sfnd, nd, flags.dldci._tdStartSymb[i], flags.dldci._tdNumSymbs[i], prbs, numDataRes, numDmrsRes, collisions)
+
+	return sfnd, nd, nil
+}
+
+// recvPaging maps PDCCH(DCI 1_0, P-RNTI) and paging PDSCH in all PDCCH monitoring occasions of the first valid PO of the UE after slot of radio frame sfn, and returns the radio frame and slot of paging PDSCH which is associated with the best SSB.
+// Note: a PO is skipped if no valid PDCCH candidate exists in all PDCCH monitoring occasions of the best SSB, and the UE tries the PO of the next DRX cycle.
+func recvPaging(sfn, slot int) (int, int, error) {
+	T, N, Ns := getPagingParams()
+	pf, iS := getPagingFrame(sfn, T, N, Ns, flags.paging.pagingFrameOffset, flags.paging.ueId)
+
+	// The [x*S+K]th PDCCH monitoring occasion for paging in the PO corresponds to the Kth transmitted SSB, where x=0,1,...,X-1, K=1,2,...,S.
+	// Note: the UE receives paging in the first PDCCH monitoring occasion corresponding to the best SSB in which a valid PDCCH candidate exists.
+	S := len(rgd.ssbCands)
+	X := flags.paging.numPdcchMoPerSsbInPo
+	K := -1
+	for k, issb := range rgd.ssbCands {
+		if getSsbIndex(issb) == flags.advanced.bestSsb {
+			K = k
+			break
+		}
+	}
+	if K < 0 {
+		return -1, -1, errors.New(fmt.Sprintf("The best SSB(=%v) is not transmitted(candSsbIndex=%v).", flags.advanced.bestSsb, flags.gridsetting.candSsbIndex))
+	}
+
+	// When SearchSpaceId other than 0 is configured for pagingSearchSpace, ... When firstPDCCH-MonitoringOccasionOfPO is present, the starting PDCCH monitoring occasion number of (i_s + 1)th PO is the (i_s + 1)th value of the firstPDCCH-MonitoringOccasionOfPO parameter; otherwise, it is equal to i_s * S*X.
+	// Note: iss is -1 when SearchSpaceId 0(Type0-PDCCH CSS set) is configured for pagingSearchSpace.
+	iss := -1
+	firstMo := 0
+	if flags.paging.pagingSearchSpace != 0 {
+		iss = utils.IndexStr(flags.searchspace._ssType, "type2")
+		firstMo = iS * S * X
+		if len(flags.paging.firstPdcchMoOfPo) > 0 {
+			firstMo = flags.paging.firstPdcchMoOfPo[iS]
+		}
+	}
+
+	prbs, err := getDci10Prbs(DCI_10_PAGING)
+	if err != nil {
+		return -1, -1, err
+	}
+	k0 := flags.dldci._tdK0[DCI_10_PAGING]
+
+	// Note: the UE tries POs within 1024 radio frames after SIB1 reception.
+	for ; pf < sfn+1024; pf += T {
+		// The PDCCH monitoring occasions for paging which do not overlap with UL symbols (determined according to tdd-UL-DL-ConfigurationCommon) are sequentially numbered from zero starting from the first PDCCH monitoring occasion for paging in the PF.
+		var mos [][]int
+		if iss < 0 {
+			mos, X, err = getType0PagingMos(pf, iS, Ns)
+			if err != nil {
+				return -1, -1, err
+			}
+			if X == 0 {
+				return -1, -1, errors.New(fmt.Sprintf("No PDCCH monitoring occasion for paging(pagingSearchSpace=0) in PF(=%v): i_s=%v, Ns=%v", pf, iS, Ns))
+			}
+		} else {
+			mos, err = getCssMos(iss, pf, 0, T*rgd.slotPerRf, firstMo, S*X)
+			if err != nil {
+				return -1, -1, err
+			}
+			if len(mos) < S*X {
+				return -1, -1, errors.New(fmt.Sprintf("Insufficient PDCCH monitoring occasions for paging within the DRX cycle(T=%v) starting from PF(=%v): firstMo=%v, S*X=%v", T, pf, firstMo, S*X))
+			}
+		}
+
+		// the PO is valid only if the PDCCH monitoring occasion of the UE is after SIB1 reception
+		if mos[K][0]*rgd.slotPerRf+mos[K][1] <= sfn*rgd.slotPerRf+slot {
+			continue
+		}
+		fmt.Printf("Paging: PF=%v(SFN mod 1024=%v), i_s=%v, PO=%v\n", pf, pf%1024, iS, mos)
+
+		sfnUe, nUe := -1, -1
+		for j, mo := range mos {
+			sfnc, nc, firstSymb := mo[0], mo[1], mo[2]
+			beam := getSsbIndex(rgd.ssbCands[j%S])
+
+			cand, m, err := mapCoreset0Pdcch(iss, sfnc, nc, firstSymb, beam)
+			if err != nil {
+				return -1, -1, err
+			}
+			if cand == nil {
+				fmt.Printf("No valid PDCCH candidate(DCI 1_0, P-RNTI) in PDCCH monitoring occasion for paging@[sfn=%v, slot=%v, firstSymb=%v], issb=%v\n", sfnc, nc, firstSymb, beam)
+				continue
+			}
+
+			// map paging PDSCH
+			sfnd := (sfnc*rgd.slotPerRf + nc + k0) / rgd.slotPerRf
+			nd := (sfnc*rgd.slotPerRf + nc + k0) % rgd.slotPerRf
+			if err := aotCommon(sfnd); err != nil {
+				return -1, -1, err
+			}
+
+			numDataRes, numDmrsRes, collisions := mapDci10Pdsch(DCI_10_PAGING, sfnd, nd, prbs, beam)
+			fmt.Printf("Paging PDSCH: issb=%v, PDCCH@[sfn=%v, slot=%v, firstSymb=%v, m=%v], cces=%v, PDSCH@[sfn=%v, slot=%v, S=%v, L=%v], prbs=%v, TBS=%v bits, REs of PDSCH=%v, REs of DMRS=%v, collisions=%v\n", beam, sfnc, nc, firstSymb, m, cand, sfnd, nd, flags.dldci._tdStartSymb[DCI_10_PAGING], flags.dldci._tdNumSymbs[DCI_10_PAGING], prbs, flags.dldci._tbsCw0[DCI_10_PAGING], numDataRes, numDmrsRes, collisions)
+
+			if j%S == K && sfnUe < 0 {
+				sfnUe, nUe = sfnd, nd
+			}
+		}
+
+		if sfnUe >= 0 {
+			return sfnUe, nUe, nil
+		}
+		regYellow.Printf("No valid PDCCH candidate(DCI 1_0, P-RNTI) in PDCCH monitoring occasions for paging of the best SSB(=%v) in PF(=%v), skip to the PO of next DRX cycle.\n", flags.advanced.bestSsb, pf)
+	}
+
+	return -1, -1, errors.New(fmt.Sprintf("No valid PO for paging of the best SSB(=%v) within 1024 radio frames starting from SFN=%v.", flags.advanced.bestSsb, sfn))
+}
+
+// getType0PagingMos returns PDCCH monitoring occasions as [SFN, slot, firstSymb] of the PO in radio frame pf when SearchSpaceId 0 is configured for pagingSearchSpace, where the [x*S+K]th element corresponds to the Kth transmitted SSB, and the number of PDCCH monitoring occasions per SSB(X).
+//  pf: the paging frame
+//  iS: index of the PO
+//  Ns: number of POs per PF
+func getType0PagingMos(pf, iS, Ns int) ([][]int, int, error) {
+	// Note: the Type0-PDCCH monitoring occasions of SSBs in radio frame pf-1 may be in radio frame pf.
+	for _, sfn := range []int{pf - 1, pf} {
+		if sfn < 0 {
+			continue
+		}
+		if err := aotCommon(sfn); err != nil {
+			return nil, -1, err
+		}
+		if err := detCss0(sfn); err != nil {
+			return nil, -1, err
+		}
+	}
+
+	// refer to 3GPP 38.304 vh40
+	// 7.1	Discontinuous Reception for paging
+	// When SearchSpaceId = 0 is configured for pagingSearchSpace, the PDCCH monitoring occasions for paging are same as for RMSI as defined in clause 13 in TS 38.213. When SearchSpaceId = 0 is configured for pagingSearchSpace, Ns is either 1 or 2. For Ns = 1, there is only one PO which starts from the first PDCCH monitoring occasion for paging in the PF. For Ns = 2, PO is either in the first half frame (i_s = 0) or the second half frame (i_s = 1) of the PF.
+	S := len(rgd.ssbCands)
+	mosPerSsb := make([][][]int, S)
+	X := -1
+	for k, issb := range rgd.ssbCands {
+		var tds []nrgrid.Css0OccasionTd
+		for _, sfn := range []int{pf - 1, pf} {
+			tds = append(tds, rgd.css0TdOccasions[fmt.Sprintf("%v_%v", sfn, issb)]...)
+		}
+		for _, td := range tds {
+			if td.Sfn != pf || (Ns == 2 && td.Slot/(rgd.slotPerRf/2) != iS) {
+				continue
+			}
+
+			if flags.gridsetting._duplexMode == "TDD" {
+				ul := false
+				for isymb := 0; isymb < flags.gridsetting._coreset0NumSymbs; isymb++ {
+					if rgd.gridTdd[td.Sfn].res[td.Slot*rgd.scPerSlot+(td.FirstSymb+isymb)*rgd.scPerSymb+rgd.coreset0Sc0Rb0] == NR_RES_U {
+						ul = true
+						break
+					}
+				}
+				if ul {
+					continue
+				}
+			}
+
+			dup := false
+			for _, mo := range mosPerSsb[k] {
+				if mo[1] == td.Slot && mo[2] == td.FirstSymb {
+					dup = true
+					break
+				}
+			}
+			if !dup {
+				mosPerSsb[k] = append(mosPerSsb[k], []int{td.Sfn, td.Slot, td.FirstSymb})
+			}
+		}
+
+		if X < 0 || len(mosPerSsb[k]) < X {
+			X = len(mosPerSsb[k])
+		}
+	}
+
+	var mos [][]int
+	for x := 0; x < X; x++ {
+		for k := 0; k < S; k++ {
+			mos = append(mos, mosPerSsb[k][x])
+		}
+	}
+
+	return mos, X, nil
+}
+
+// getCssMos returns PDCCH monitoring occasions as [SFN, slot, firstSymb] of the common search space set in CORESET0, which do not overlap with UL symbols determined by tdd-UL-DL-ConfigurationCommon.
+//  iss: index of the search space set
+//  sfn, slot: the first slot in which PDCCH monitoring occasions are numbered from zero
+//  numSlots: number of consecutive slots in which PDCCH monitoring occasions are numbered
+//  firstMo: number of the first PDCCH monitoring occasion to return
+//  numMos: maximum number of PDCCH monitoring occasions to return, or all PDCCH monitoring occasions if negative
+func getCssMos(iss, sfn, slot, numSlots, firstMo, numMos int) ([][]int, error) {
+	period, _ := strconv.Atoi(flags.searchspace._ssPeriodicity[iss][2:])
+	offset := flags.searchspace._ssSlotOffset[iss]
+	duration := flags.searchspace._ssDuration[iss]
+
+	var mos [][]int
+	n := 0
+	for i := 0; i < numSlots && (numMos < 0 || len(mos) < numMos); i++ {
+		sfnc := (sfn*rgd.slotPerRf + slot + i) / rgd.slotPerRf
+		nc := (sfn*rgd.slotPerRf + slot + i) % rgd.slotPerRf
+
+		// refer to 3GPP 38.213 vh40
+		// 10.1	UE procedure for determining physical downlink control channel assignment
+		// A UE determines that a PDCCH monitoring occasion on an active DL BWP exists in a slot with number n_s_f_u in a frame with number n_f if (n_f*N_frame_slot + n_s_f_u - o_s) mod k_s = 0. The UE monitors PDCCH candidates for search space set s for T_s consecutive slots, starting from slot n_s_f_u, and does not monitor PDCCH candidates for search space set s for the next k_s - T_s consecutive slots.
+		if ((sfnc*rgd.slotPerRf+nc-offset)%period+period)%period >= duration {
+			continue
+		}
+
+		if err := aotCommon(sfnc); err != nil {
+			return nil, err
+		}
+
+		grid := getDlGrid(sfnc)
+		for firstSymb, bit := range flags.searchspace._ssMonitoringSymbolWithinSlot[iss] {
+			if bit != '1' {
+				continue
+			}
+
+			if flags.gridsetting._duplexMode == "TDD" {
+				ul := false
+				for isymb := 0; isymb < flags.gridsetting._coreset0NumSymbs; isymb++ {
+					if grid.res[nc*rgd.scPerSlot+(firstSymb+isymb)*rgd.scPerSymb+rgd.coreset0Sc0Rb0] == NR_RES_U {
+						ul = true
+						break
+					}
+				}
+				if ul {
+					continue
+				}
+			}
+
+			if n >= firstMo && (numMos < 0 || len(mos) < numMos) {
+				mos = append(mos, []int{sfnc, nc, firstSymb})
+			}
+			n++
+		}
+	}
+
+	return mos, nil
+}
+
+// mapCoreset0Pdcch selects the first PDCCH candidate of the common search space set which doesn't collide with other channels in the PDCCH monitoring occasion, and maps the PDCCH candidate in CORESET0.
+// It returns the CCEs and index of the PDCCH candidate, or nil CCEs if no valid PDCCH candidate exists.
+//  iss: index of the search space set, or -1 for Type0-PDCCH CSS set
+//  sfnc, nc, firstSymb: the PDCCH monitoring occasion
+//  beam: SSB index of the beam which is quasi co-located with the PDCCH
+func mapCoreset0Pdcch(iss, sfnc, nc, firstSymb, beam int) ([]int, int, error) {
+	var L, M int
+	ssType := "type0"
+	if iss < 0 {
+		L = flags.gridsetting._css0AggLevel
+		M, _ = strconv.Atoi(flags.gridsetting._css0NumCandidates[1:])
+	} else {
+		L, _ = strconv.Atoi(flags.searchspace.ssAggregationLevel[iss][2:])
+		M, _ = strconv.Atoi(flags.searchspace.ssNumOfPdcchCandidates[iss][1:])
+		ssType = flags.searchspace._ssType[iss]
+	}
+	grid := getDlGrid(sfnc)
+
+	var cand []int
+	var m int
+	for m = 0; m < M && cand == nil; m++ {
+		cces, err := detCcesPerPdcchCand(0, L, m, nc, ssType, 0, rgd.coreset0NumCces, 0, M)
+		if err != nil {
+			return nil, -1, err
+		}
+
+		valid := true
+		for i, icce := range rgd.coreset0Cces {
+			if !utils.ContainsInt(cces, icce) {
+				continue
+			}
+
+			for isc := 0; isc < rgd.scPerRb; isc++ {
+				ire := nc*rgd.scPerSlot + (firstSymb+rgd.coreset0RegBundles[i].Isymb)*rgd.scPerSymb + rgd.coreset0Sc0Rb0 + rgd.coreset0RegBundles[i].Irb*rgd.scPerRb + isc
+				// Note: PDCCH candidates of Type0-PDCCH CSS set are already mapped by aotPdcchSib1, which are shared with PDCCH of P-RNTI when pagingSearchSpace is 0.
+				if iss < 0 && ((grid.res[ire] >= NR_RES_PDCCH_CANDIDATE && grid.res[ire] < NR_RES_PDCCH_CANDIDATE+8) || grid.res[ire] == NR_RES_DMRS_PDCCH) {
+					continue
+				}
+				if grid.res[ire] != NR_RES_D {
+					valid = false
+					break
+				}
+			}
+		}
+
+		if valid {
+			cand = cces
+		}
+	}
+	if cand == nil {
+		return nil, -1, nil
+	}
+
+	// map PDCCH candidate
+	m--
+	for i, icce := range rgd.coreset0Cces {
+		if !utils.ContainsInt(cand, icce) {
+			continue
+		}
+
+		for isc := 0; isc < rgd.scPerRb; isc++ {
+			ire := nc*rgd.scPerSlot + (firstSymb+rgd.coreset0RegBundles[i].Isymb)*rgd.scPerSymb + rgd.coreset0Sc0Rb0 + rgd.coreset0RegBundles[i].Irb*rgd.scPerRb + isc
+			grid.beams[ire] = beam
+			if isc > 0 && (isc-1)%4 == 0 {
+				grid.res[ire] = NR_RES_DMRS_PDCCH
+			} else {
+				grid.res[ire] = NR_RES_PDCCH_CANDIDATE + m
+			}
+		}
+	}
+
+	if grid.tags[nc] == nil {
+		grid.tags[nc] = mapset.NewSet()
+	}
+	grid.tags[nc].Add("PDCCH")
+
+	return cand, m, nil
+}
+
+// sendMsg3 maps Msg3 PUSCH scheduled by RAR UL grant or fallbackRAR UL grant, and returns the radio frame and slot(of the UL carrier) of Msg3.
+//  sfn: radio frame of Msg2 or MsgB
+//  slot: slot of Msg2 or MsgB
+func sendMsg3(sfn, slot int) (int, int, error) {
+	// refer to 3GPP 38.213 vh40
+	// 8.3	PUSCH scheduled by RAR UL grant
+	// For a PUSCH transmission scheduled by a RAR UL grant ..., if a UE receives a PDSCH with a RAR message ending in slot n for a corresponding PRACH transmission from the UE, the UE transmits the PUSCH in slot n + k2 + delta
+	// Note: the same timing is assumed for PUSCH scheduled by fallbackRAR UL grant in MsgB.
+	// refer to 3GPP 38.214 vh40
+	// 6.1.2.1	Resource allocation in time domain
+	// The slot where the UE shall transmit the PUSCH is determined by K2 as floor(n*2^u_PUSCH/2^u_PDCCH) + K2
+	// Note: the slot n of Msg2/MsgB is converted to the numerology of the UL carrier in the same way, which is different from the carrier if SUL with a different subcarrierSpacing is used.
+	k := msg3UlGrantIdx()
+	k2 := flags.uldci._tdK2[k] + flags.uldci._tdDelta
+	ulSlotPerRf, scPerSymb, scPerSlot := getUlDims()
+	mu := getOverlapSlot(sfn*rgd.slotPerRf+slot, rgd.slotPerRf, ulSlotPerRf, false) + k2
+	sfnu := mu / ulSlotPerRf
+	nu := mu % ulSlotPerRf
+	if err := aotCommon(sfnu); err != nil {
+		return -1, -1, err
+	}
+
+	S := flags.uldci._tdStartSymb[k]
+	L := flags.uldci._tdNumSymbs[k]
+	bwpStart, bwpSize := getUlBwp(INI_UL_BWP)
+	rbStart := flags.uldci.fdStartRb[k]
+	numRbs := flags.uldci.fdNumRbs[k]
+	if rbStart+numRbs > bwpSize {
+		return -1, -1, errors.New(fmt.Sprintf("Invalid FDRA of Msg3: fdStartRb=%v, fdNumRbs=%v while initial UL BWP has %v RBs.", rbStart, numRbs, bwpSize))
+	}
+
+	// TD/FD pattern of each hop, where each element is [firstSymb, numSymbs, rbStart] and DMRS symbols
+	// refer to 3GPP 38.214 vh40
+	// 6.3	UE PUSCH frequency hopping procedure
+	// In case of intra-slot frequency hopping, ... The number of symbols in the first hop is given by floor(N_PUSCH_symb/2), the number of symbols in the second hop is given by N_PUSCH_symb - floor(N_PUSCH_symb/2)
+	// RB_start in the second hop = (RB_start + RB_offset) mod N_BWP_size
+	// refer to 3GPP 38.211 vh40
+	// 6.4.1.1.3	Precoding and mapping to physical resources (DMRS for PUSCH)
+	// l is defined relative to the start of the slot if frequency hopping is disabled and PUSCH mapping type A, relative to the start of the scheduled PUSCH resources if frequency hopping is disabled and PUSCH mapping type B, relative to the start of each hop in case frequency hopping is enabled
+	var hops [][]int
+	var dmrs [][]int
+	if flags.uldci.fdFreqHop[k] == "intra-slot" {
+		L1 := utils.FloorInt(float64(L) / 2)
+		hops = [][]int{{S, L1, rbStart}, {S + L1, L - L1, (rbStart + flags.uldci._fdFreqHopOffset[k]) % bwpSize}}
+		dmrs = make([][]int, 2)
+		for _, l := range flags.dmrsCommon._tdL[DMRS_RAR_UL_MSG3] {
+			dmrs[0] = append(dmrs[0], S+l)
+		}
+		for _, l := range flags.dmrsCommon._tdL2 {
+			dmrs[1] = append(dmrs[1], S+L1+l)
+		}
+	} else {
+		hops = [][]int{{S, L, rbStart}}
+		dmrs = make([][]int, 1)
+		for _, l := range flags.dmrsCommon._tdL[DMRS_RAR_UL_MSG3] {
+			if flags.uldci._tdMappingType[k] == "typeA" {
+				dmrs[0] = append(dmrs[0], l)
+			} else {
+				dmrs[0] = append(dmrs[0], S+l)
+			}
+		}
+	}
+	fdK := flags.dmrsCommon._fdK[DMRS_RAR_UL_MSG3]
+
+	grid := getUlGrid(sfnu)
+	numMsg3Res, numDmrsRes := 0, 0
+	collisions := make(map[string]int)
+	_, carrierNumRbs := getUlCarrier()
+	for ihop, hop := range hops {
+		if bwpStart+hop[2]+numRbs > carrierNumRbs {
+			return -1, -1, errors.New(fmt.Sprintf("Msg3 PUSCH(bwpStart=%v, rbStart=%v, numRbs=%v) is out of the UL carrier bandwidth(%v RBs).", bwpStart, hop[2], numRbs, carrierNumRbs))
+		}
+
+		for symb := hop[0]; symb < hop[0]+hop[1]; symb++ {
+			isDmrs := utils.ContainsInt(dmrs[ihop], symb)
+			for rb := hop[2]; rb < hop[2]+numRbs; rb++ {
+				for isc := 0; isc < rgd.scPerRb; isc++ {
+					ire := nu*scPerSlot + symb*scPerSymb + (bwpStart+rb)*rgd.scPerRb + isc
+					if grid.res[ire] != NR_RES_U {
+						collisions[resCategory(grid.res[ire])]++
+						continue
+					}
+
+					// refer to 3GPP 38.214 vh40
+					// 6.2.2	UE DM-RS transmission procedure
+					// For PUSCH scheduled by ... RAR UL grant, ... the UE shall use single symbol front-loaded DM-RS of configuration type 1 on DM-RS port 0 and the remaining REs not used for DM-RS in the symbols are not used for any PUSCH transmission
+					if isDmrs && fdK[isc] == 1 {
+						if isc%2 == 0 {
+							grid.res[ire] = NR_RES_DMRS_MSG3
+							numDmrsRes++
+						} else {
+							grid.res[ire] = NR_RES_DTX
+						}
+					} else {
+						grid.res[ire] = NR_RES_MSG3
+						numMsg3Res++
+					}
+				}
+			}
+		}
+	}
+
+	if grid.tags[nu] == nil {
+		grid.tags[nu] = mapset.NewSet()
+	}
+	grid.tags[nu].Add("MSG3")
+
+	fmt.Printf("Msg3 PUSCH: tag=%v, PDSCH(RAR)@[sfn=%v, slot=%v], PUSCH@[sfn=%v, slot=%v, S=%v, L=%v], hops([firstSymb, numSymbs, rbStart])=%v, numRbs=%v, dmrs=%v, REs of PUSCH=%v, REs of DMRS=%v, collisions=%v\n", flags.uldci._tag[k], sfn, slot, sfnu, nu, S, L, hops, numRbs, dmrs, numMsg3Res, numDmrsRes, collisions)
+
+	return sfnu, nu, nil
+}
+
+// sendPucch maps PUCCH carrying UCI, and returns the radio frame and slot(of the UL carrier) of PUCCH.
+//  sfn: radio frame of PDSCH whose HARQ-ACK is reported
+//  slot: slot of PDSCH whose HARQ-ACK is reported, which is slot of SDL carrier for dedicated or SPS PUCCH resource if SDL is used
+//  harq/sr/csi: whether HARQ-ACK/SR/CSI is reported
+//  pucchResSet: PUCCH resource set, which can be common, dedicated or sps(n1PUCCH-AN of SPS-Config for SPS PDSCH without corresponding PDCCH)
+func sendPucch(sfn, slot int, harq, sr, csi bool, pucchResSet string) (int, int, error) {
+	if !harq || sr || csi || !utils.ContainsStr([]string{"common", "dedicated", "sps"}, pucchResSet) {
+		return -1, -1, errors.New(fmt.Sprintf("Only HARQ-ACK on common, dedicated or SPS PUCCH resource is supported in sendPucch: harq=%v, sr=%v, csi=%v, pucchResSet=%v", harq, sr, csi, pucchResSet))
+	}
+
+	// refer to 3GPP 38.213 vh40
+	// 9.2.3	UE procedure for reporting HARQ-ACK
+	// For DCI format 1_0, the PDSCH-to-HARQ_feedback timing indicator field values map to {1, 2, 3, 4, 5, 6, 7, 8}.
+	// For DCI format 1_1, ... the PDSCH-to-HARQ_feedback timing indicator field values map to values for a set of number of slots provided by dl-DataToUL-ACK
+	// Note: dl-DataToUL-ACK is assumed to be {1, 2, 3, 4, 5, 6, 7, 8} for DCI format 1_1.
+	// ... For a PDSCH reception ending in slot n, the UE provides corresponding HARQ-ACK information in a PUCCH transmission within slot n + k, where ... slot n is the last UL slot overlapping with the PDSCH reception in case the numerologies of the PDSCH and the PUCCH are different.
+	// Note: PDSCH scheduled by DCI 1_1 and SPS PDSCH are received on SDL carrier if SDL is used, and PUCCH is transmitted on SUL carrier if SUL is used.
+	k1 := flags.dldci.tdK1 + 1
+	n := sfn*rgd.slotPerRf + slot
+	if pucchResSet != "common" {
+		pdschSlotPerRf, _, _ := getPdschDims()
+		n = getOverlapSlot(sfn*pdschSlotPerRf+slot, pdschSlotPerRf, rgd.slotPerRf, true)
+	}
+	ulSlotPerRf, scPerSymb, scPerSlot := getUlDims()
+	mu := getOverlapSlot(n, rgd.slotPerRf, ulSlotPerRf, true) + k1
+	sfnu := mu / ulSlotPerRf
+	nu := mu % ulSlotPerRf
+	if err := aotCommon(sfnu); err != nil {
+		return -1, -1, err
+	}
+
+	if pucchResSet == "dedicated" {
+		// refer to 3GPP 38.213 vh40
+		// 9.2.3	UE procedure for reporting HARQ-ACK
+		// A UE does not expect to multiplex in a PUCCH transmission HARQ-ACK information that does not fit in a single slot.
+		// Note: there is only one HARQ-ACK information bit for the single TB of PDSCH scheduled by DCI 1_1, and HARQ-ACK information bits of PDSCHs reported in the same slot are multiplexed by addDedUci.
+		// Note: HARQ-ACK is reported on the dedicated PUCCH resource of the active UL BWP, which can be an additional dedicated UL BWP.
+		j, m := getHarqAckSlot(rgd.bsw, n)
+		if m < 0 {
+			fmt.Printf("PUCCH(HARQ-ACK)@[%v,%v] dropped since no UL BWP is active.\n", sfnu, nu)
+			return sfnu, nu, nil
+		}
+
+		if j >= 0 {
+			if err := addBwpHarqAck(j, m); err != nil {
+				return -1, -1, err
+			}
+
+			n := m * rgd.slotPerRf / rgd.bwps[j].slotPerRf
+			return n / rgd.slotPerRf, n % rgd.slotPerRf, nil
+		}
+
+		if err := addDedUci(sfnu, nu, "HARQ-ACK"); err != nil {
+			return -1, -1, err
+		}
+
+		return sfnu, nu, nil
+	}
+
+	if pucchResSet == "sps" {
+		// refer to 3GPP 38.213 vh40
+		// 9.2.3	UE procedure for reporting HARQ-ACK
+		// If the UE transmits HARQ-ACK information corresponding only to a PDSCH reception without a corresponding PDCCH, a PUCCH resource for corresponding PUCCH transmission with HARQ-ACK information is provided by n1PUCCH-AN.
+		// Note: the PDSCH-to-HARQ_feedback timing of SPS PDSCH is indicated by the activation DCI 1_1.
+		if err := addDedUci(sfnu, nu, "SPS-ACK"); err != nil {
+			return -1, -1, err
+		}
+
+		return sfnu, nu, nil
+	}
+
+	p, exist := nrgrid.CommonPucchResSets[flags.pucch.pucchResCommon]
+	if !exist {
+		return -1, -1, errors.New(fmt.Sprintf("Invalid pucch-ResourceCommon(=%v) of PUCCH-ConfigCommon!", flags.pucch.pucchResCommon))
+	}
+
+	// refer to 3GPP 38.213 vh40
+	// 9.2.1	PUCCH Resource Sets
+	// If a UE does not have dedicated PUCCH resource configuration, ... The UE determines an index r_PUCCH, 0 <= r_PUCCH <= 15, as r_PUCCH = floor(2*n_CCE,0/N_CCE) + 2*delta_PRI
+	// - If floor(r_PUCCH/8) = 0, ... the UE determines the PRB index of the PUCCH transmission in the first hop as RB_offset_BWP + floor(r_PUCCH/N_CS) and the PRB index of the PUCCH transmission in the second hop as N_size_BWP - 1 - RB_offset_BWP - floor(r_PUCCH/N_CS)
+	// - If floor(r_PUCCH/8) = 1, ... the UE determines the PRB index of the PUCCH transmission in the first hop as N_size_BWP - 1 - RB_offset_BWP - floor((r_PUCCH-8)/N_CS) and the PRB index of the PUCCH transmission in the second hop as RB_offset_BWP + floor((r_PUCCH-8)/N_CS)
+	bwpStart, bwpSize := getUlBwp(INI_UL_BWP)
+	rbOffset := p.PrbOffset
+	if rbOffset < 0 {
+		rbOffset = utils.FloorInt(float64(bwpSize) / 4)
+	}
+	numCs := len(p.InitialCsSet)
+	r := utils.FloorInt(float64(2*rgd.dci10Cce0)/float64(rgd.dci10NumCces)) + 2*flags.dldci.deltaPri
+	var rbs []int
+	var cs int
+	if r/8 == 0 {
+		rbs = []int{rbOffset + r/numCs, bwpSize - 1 - rbOffset - r/numCs}
+		cs = p.InitialCsSet[r%numCs]
+	} else {
+		rbs = []int{bwpSize - 1 - rbOffset - (r-8)/numCs, rbOffset + (r-8)/numCs}
+		cs = p.InitialCsSet[(r-8)%numCs]
+	}
+
+	// refer to 3GPP 38.211 vh40
+	// 6.4.1.3.1.2	Mapping to physical resources (DMRS for PUCCH format 1)
+	// l = 0, 2, 4, ... where l = 0 corresponds to the first OFDM symbol of the PUCCH transmission
+	grid := getUlGrid(sfnu)
+	numPucchRes, numDmrsRes := 0, 0
+	collisions := make(map[string]int)
+	for i := 0; i < p.NumSymbs; i++ {
+		symb := p.FirstSymb + i
+		rb := rbs[0]
+		if i >= p.NumSymbs/2 {
+			rb = rbs[1]
+		}
+
+		for isc := 0; isc < rgd.scPerRb; isc++ {
+			ire := nu*scPerSlot + symb*scPerSymb + (bwpStart+rb)*rgd.scPerRb + isc
+			if grid.res[ire] != NR_RES_U {
+				collisions[resCategory(grid.res[ire])]++
+				continue
+			}
+
+			if p.PucchFmt == 1 && i%2 == 0 {
+				grid.res[ire] = NR_RES_DMRS_PUCCH
+				numDmrsRes++
+			} else {
+				grid.res[ire] = NR_RES_PUCCH_ACK
+				numPucchRes++
+			}
+		}
+	}
+
+	if grid.tags[nu] == nil {
+		grid.tags[nu] = mapset.NewSet()
+	}
+	grid.tags[nu].Add("PUCCH")
+
+	fmt.Printf("PUCCH(HARQ-ACK): pucchResCommon=%v, format=%v, r_PUCCH=%v, PUCCH@[sfn=%v, slot=%v, firstSymb=%v, numSymbs=%v], prbs=%v, initialCs=%v, REs of PUCCH=%v, REs of DMRS=%v, collisions=%v\n", flags.pucch.pucchResCommon, p.PucchFmt, r, sfnu, nu, p.FirstSymb, p.NumSymbs, rbs, cs, numPucchRes, numDmrsRes, collisions)
+
+	return sfnu, nu, nil
+}
+
+// getDedPucchRes returns the index of dedicated PUCCH resource which is indicated by the PUCCH resource indicator field of DCI 1_1.
+//  numUciBits: number of UCI information bits
+func getDedPucchRes(numUciBits int) (int, error) {
+	// refer to 3GPP 38.213 vh40
+	// 9.2.1	PUCCH Resource Sets
+	// If a UE has dedicated PUCCH resource configuration, the UE is provided by higher layers with one or more PUCCH resources...
+	// - a first set of PUCCH resources with pucch-ResourceSetId = 0 if N_UCI <= 2 including 1 or 2 HARQ-ACK information bits and a positive or negative SR on one SR transmission occasion if transmission of HARQ-ACK information and SR occurs simultaneously, or
+	// - a second set of PUCCH resources with pucch-ResourceSetId = 1, if provided by higher layers, if 2 < N_UCI <= N_2 where N_2 is equal to maxPayloadSize if maxPayloadSize is provided for the PUCCH resource set with pucch-ResourceSetId = 1; otherwise N_2 is equal to 1706
+	// 9.2.3	UE procedure for reporting HARQ-ACK
+	// the PUCCH resource determination is based on a PUCCH resource indicator field, if present, in a last DCI format 1_0, DCI format 1_1, or DCI format 1_2 ... PUCCH resource indicator field values map to values of a set of PUCCH resource indexes, as defined in Table 9.2.3-2
+	// Note: PUCCH resource set 0 consists of format 0/1 resources other than the SR resource, while PUCCH resource set 1 consists of format 2/3/4 resources. The PUCCH resource indicator is wrapped around when it exceeds the size of the PUCCH resource set.
+	var resSet []int
+	for i, f := range flags.pucch._pucchFormat {
+		if numUciBits <= 2 && utils.ContainsStr([]string{"format0", "format1"}, f) && flags.pucch._pucchResId[i] != flags.pucch._dsrPucchRes {
+			resSet = append(resSet, i)
+		}
+		if numUciBits > 2 && utils.ContainsStr([]string{"format2", "format3", "format4"}, f) {
+			resSet = append(resSet, i)
+		}
+	}
+
+	if len(resSet) == 0 {
+		return -1, errors.New(fmt.Sprintf("No dedicated PUCCH resource is available for %v UCI bit(s): pucchResId=%v, pucchFormat=%v, dsrPucchRes=%v", numUciBits, flags.pucch._pucchResId, flags.pucch._pucchFormat, flags.pucch._dsrPucchRes))
+	}
+
+	return resSet[flags.dldci.deltaPri%len(resSet)], nil
+}
+
+// getDedPucchPrbs returns the starting PRB(relative to the lowest RB of dedicated UL BWP) of each hop of the dedicated PUCCH resource, or all PRBs of the interlace if interlaced PUCCH is used.
+//  r: index of the dedicated PUCCH resource
+//  j: index of the slot for PUCCH repetitions, which is 0 for the first PUCCH transmission
+func getDedPucchPrbs(r, j int) []int {
+	// refer to 3GPP 38.213 vh40
+	// 9.2.1	PUCCH Resource Sets
+	// If a UE is provided useInterlacePUCCH-PUSCH in BWP-UplinkDedicated, ... the UE transmits the PUCCH over the RBs of the interlace provided by interlace0 within the RB set provided by rb-SetIndex.
+	if flags.bwp.useInterlace {
+		bwpStart, bwpSize := getUlBwp(DED_UL_BWP)
+		prbs, _ := getInterlacedPrbs(bwpStart, bwpSize, []int{flags.pucch._pucchInterlace[r]}, flags.pucch._pucchRbSet[r], 1)
+		return prbs
+	}
+
+	// refer to 3GPP 38.331 vh40
+	// PUCCH-Resource: startingPRB - the index of first PRB before frequency hopping or without frequency hopping; secondHopPRB - the index of first PRB after frequency hopping.
+	if flags.pucch._pucchIntraSlotFreqHop[r] == "enabled" {
+		return []int{flags.pucch._pucchStartRb[r], flags.pucch._pucchSecondHopPrb[r]}
+	}
+
+	// refer to 3GPP 38.213 vh40
+	// 9.2.6	PUCCH repetition procedure
+	// If a UE is configured to perform frequency hopping for PUCCH transmissions across different slots, the UE performs frequency hopping per slot, the UE transmits the PUCCH starting from a first PRB, provided by startingPRB, in slots with even number and starting from the second PRB, provided by secondHopPRB, in slots with odd number.
+	if flags.pucch._interSlotFreqHop == "enabled" && j%2 == 1 {
+		return []int{flags.pucch._pucchSecondHopPrb[r]}
+	}
+
+	return []int{flags.pucch._pucchStartRb[r]}
+}
+
+// mapDedPucch maps the dedicated PUCCH resource and associated DMRS in slot of grid, and returns the REs mapped, number of REs of PUCCH, number of REs of DMRS and collisions.
+//  r: index of the dedicated PUCCH resource
+//  nu: slot of the PUCCH
+//  j: index of the slot for PUCCH repetitions, which is 0 for the first PUCCH transmission
+//  res: NR resource of UCI, which can be NR_RES_PUCCH_ACK etc
+//  scPerSymb/scPerSlot: number of subcarriers per symbol/slot of grid, which can be NR resource grid of the carrier or additional dedicated UL BWP
+//  bwpStart/bwpSize: RB_start and L_RBs of the UL BWP
+func mapDedPucch(grid DataPerRf, r, nu, j, res, scPerSymb, scPerSlot, bwpStart, bwpSize int) ([]int, int, int, map[string]int, error) {
+	firstSymb := flags.pucch._pucchStartSymb[r]
+	numSymbs := flags.pucch._pucchNumSymbs[r]
+	numRbs := flags.pucch._pucchNumRbs[r]
+	hopping := flags.pucch._pucchIntraSlotFreqHop[r] == "enabled"
+	prbs := getDedPucchPrbs(r, j)
+	if len(prbs) == 0 {
+		return nil, 0, 0, nil, errors.New(fmt.Sprintf("No PRB is available for PUCCH(pucchResId=%v) in the UL BWP(size=%v)!", flags.pucch._pucchResId[r], bwpSize))
+	}
+	for _, rb := range prbs {
+		if rb < 0 || (!flags.bwp.useInterlace && rb+numRbs > bwpSize) || (flags.bwp.useInterlace && rb >= bwpSize) {
+			return nil, 0, 0, nil, errors.New(fmt.Sprintf("PRBs(=%v, numRbs=%v) of PUCCH(pucchResId=%v) exceed the UL BWP(size=%v)!", prbs, numRbs, flags.pucch._pucchResId[r], bwpSize))
+		}
+	}
+
+	// DMRS symbols relative to the first symbol of PUCCH
+	// refer to 3GPP 38.211 vh40
+	// 6.4.1.3.1.2	Mapping to physical resources (DMRS for PUCCH format 1): l = 0, 2, 4, ...
+	// 6.4.1.3.3.2	Mapping to physical resources (DMRS for PUCCH format 3 and 4): Table 6.4.1.3.3.2-1
+	// Note: there is no DMRS for PUCCH format 0.
+	var dmrs []int
+	if flags.pucch._pucchFormat[r] == "format1" {
+		dmrs = utils.PyRange(0, numSymbs, 2)
+	} else if flags.pucch._pucchFormat[r] != "format0" {
+		col := 0
+		if hopping {
+			col++
+		}
+		if flags.pucch._addDmrs {
+			col += 2
+		}
+		dmrs = nrgrid.PucchFmt3Fmt4DmrsPos[numSymbs][col]
+	}
+
+	// refer to 3GPP 38.211 vh40
+	// 6.3.2.4.2/6.3.2.6.5	Mapping to physical resources
+	// In case of intra-slot frequency hopping, ... the number of symbols in the first hop is floor(N_PUCCH_symb/2)
+	var ires []int
+	numPucchRes, numDmrsRes := 0, 0
+	collisions := make(map[string]int)
+	for i := 0; i < numSymbs; i++ {
+		symb := firstSymb + i
+		rb := prbs[0]
+		if hopping && i >= numSymbs/2 {
+			rb = prbs[1]
+		}
+		rbs := utils.PyRange(rb, rb+numRbs, 1)
+		if flags.bwp.useInterlace {
+			rbs = prbs
+		}
+
+		for _, irb := range rbs {
+			for isc := 0; isc < rgd.scPerRb; isc++ {
+				ire := nu*scPerSlot + symb*scPerSymb + (bwpStart+irb)*rgd.scPerRb + isc
+				if grid.res[ire] != NR_RES_U {
+					collisions[resCategory(grid.res[ire])]++
+					continue
+				}
+
+				if utils.ContainsInt(dmrs, i) {
+					grid.res[ire] = NR_RES_DMRS_PUCCH
+					numDmrsRes++
+				} else {
+					grid.res[ire] = res
+					numPucchRes++
+				}
+				ires = append(ires, ire)
+			}
+		}
+	}
+
+	if grid.tags[nu] == nil {
+		grid.tags[nu] = mapset.NewSet()
+	}
+	grid.tags[nu].Add("PUCCH")
+
+	return ires, numPucchRes, numDmrsRes, collisions, nil
+}
+
+// aotPucch adds SR and periodic CSI report to dedicated PUCCH, starting from the given slot(of the carrier) of radio frame sfn.
+func aotPucch(sfn, slot int) error {
+	if rgd.trPucch[sfn] {
+		return nil
+	}
+
+	srPeriod, _ := strconv.Atoi(flags.pucch.dsrPeriod[2:])
+	csiPeriod, _ := strconv.Atoi(flags.csi.csiRepPeriod[5:])
+	srRes := utils.IndexInt(flags.pucch._pucchResId, flags.pucch._dsrPucchRes)
+	csiRes := utils.IndexInt(flags.pucch._pucchResId, flags.csi._csiRepPucchRes)
+	// [sfn, slot] of SR/CSI PUCCH dropped due to measurement gaps
+	// Note: SR/CSI PUCCH are in slots of the UL carrier, starting from the first UL slot overlapping with the given slot of the carrier.
+	var dropped []string
+	ulSlotPerRf, _, _ := getUlDims()
+	for s := getOverlapSlot(slot, rgd.slotPerRf, ulSlotPerRf, false); s < ulSlotPerRf; s++ {
+		// refer to 3GPP 38.213 vh40
+		// 9.2.4	UE procedure for reporting SR
+		// A UE determines a slot in a frame with number n_f for a PUCCH transmission carrying SR with SR_PERIODICITY in number of symbols and offset SR_OFFSET in number of slots if (n_f*N_frame_slot + n_s - SR_OFFSET) mod SR_PERIODICITY = 0.
+		// refer to 3GPP 38.133 vh40
+		// 9.1.2	Measurement gap
+		// Note: the UE is not required to transmit periodic SR/CSI report on PUCCH which overlaps with measurement gaps.
+		if (sfn*ulSlotPerRf+s-flags.pucch.dsrOffset)%srPeriod == 0 {
+			if isMeasGap(sfn*ulSlotPerRf+s, 1, flags.pucch._pucchStartSymb[srRes], flags.pucch._pucchNumSymbs[srRes], rgd.symbPerSlot, ulSlotPerRf/rgd.subfPerRf) {
+				dropped = append(dropped, fmt.Sprintf("SR@[%v,%v]", sfn, s))
+			} else if err := addDedUci(sfn, s, "SR"); err != nil {
+				return err
+			}
+		}
+
+		// refer to 3GPP 38.214 vh40
+		// 5.2.1.4	Reporting configurations
+		// For periodic and semi-persistent CSI reporting on PUCCH, the periodicity T_CSI (measured in slots) and the slot offset T_offset are configured by reportSlotConfig ... The UE reports CSI in slots satisfying (N_frame_slot*n_f + n_s - T_offset) mod T_CSI = 0.
+		if (sfn*ulSlotPerRf+s-flags.csi.csiRepOffset)%csiPeriod == 0 {
+			if isMeasGap(sfn*ulSlotPerRf+s, 1, flags.pucch._pucchStartSymb[csiRes], flags.pucch._pucchNumSymbs[csiRes], rgd.symbPerSlot, ulSlotPerRf/rgd.subfPerRf) {
+				dropped = append(dropped, fmt.Sprintf("CSI@[%v,%v]", sfn, s))
+			} else if err := addDedUci(sfn, s, "CSI"); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(dropped) > 0 {
+		fmt.Printf("PUCCH dropped due to measurement gap: %v\n", dropped)
+	}
+
+	rgd.trPucch[sfn] = true
+
+	return nil
+}
+
+// addDedUci adds UCI to dedicated PUCCH in slot(of the UL carrier) of radio frame sfn, which is multiplexed with other UCI in the same slot(without PUCCH repetitions), or prioritized against overlapping PUCCH repetitions.
+//  uci: UCI type, which can be SR, HARQ-ACK, SPS-ACK(HARQ-ACK of SPS PDSCH without corresponding PDCCH) or CSI
+func addDedUci(sfn, slot int, uci string) error {
+	ulSlotPerRf, _, _ := getUlDims()
+	n := sfn*ulSlotPerRf + slot
+	numRep, _ := strconv.Atoi(flags.pucch._numSlots[1:])
+
+	if numRep == 1 {
+		// refer to 3GPP 38.213 vh40
+		// 9.2.5	UE procedure for reporting multiple UCI types
+		// If a UE would transmit multiple PUCCHs in a slot that include HARQ-ACK information, and/or SR, and/or CSI reports and any PUCCH with HARQ-ACK information in the slot satisfies the above timing conditions when applicable, the UE multiplexes all corresponding UCI types
+		// Note: only PUCCH format 1/3 is configured, so there is at most one PUCCH in a slot and all UCI in the same slot are assumed to be overlapping.
+		// Note: HARQ-ACK information bits of all PDSCHs reported in the same slot are multiplexed in a PUCCH, and the PUCCH resource is determined by the number of HARQ-ACK information bits.
+		ucis := []string{uci}
+		numAck := 0
+		if uci == "HARQ-ACK" || uci == "SPS-ACK" {
+			numAck = 1
+		}
+		old, exist := rgd.pucchSlots[n]
+		if exist {
+			if utils.ContainsStr(rgd.pucchTr[old].reqUci, uci) && numAck == 0 {
+				return nil
+			}
+			numAck += rgd.pucchTr[old].numAck
+			ucis = append([]string{}, rgd.pucchTr[old].reqUci...)
+			if !utils.ContainsStr(ucis, uci) {
+				ucis = append(ucis, uci)
+			}
+		}
+
+		r, muxUci, decision, err := muxDedUci(ucis, numAck)
+		if err != nil {
+			return err
+		}
+
+		// Note: the PUCCH already mapped in the slot, if any, is kept when the PUCCH resource of the multiplexed UCI can't be transmitted.
+		if !isTddSymbs(sfn, slot, flags.pucch._pucchStartSymb[r], flags.pucch._pucchNumSymbs[r], "U") {
+			fmt.Printf("PUCCH(%v)@[%v,%v] dropped due to non-UL symbols.\n", uci, sfn, slot)
+			return nil
+		}
+
+		if exist {
+			unmapDedPucch(old, n)
+			delete(rgd.pucchTr, old)
+		}
+
+		if len(ucis) > 1 || numAck > 1 {
+			fmt.Printf("UCI multiplexing@[sfn=%v, slot=%v]: UCI=%v, HARQ-ACK bits=%v, transmitted UCI=%v, pucchResId=%v, decision=%v\n", sfn, slot, ucis, numAck, muxUci, flags.pucch._pucchResId[r], decision)
+		}
+
+		key := fmt.Sprintf("%v_%v", n, strings.Join(muxUci, "+"))
+		rgd.pucchTr[key] = &PucchTrInfo{reqUci: ucis, uci: muxUci, numAck: numAck, r: r, numRep: 1, res: make(map[int][]int)}
+		return mapDedPucchTr(key, n, 0)
+	}
+
+	// refer to 3GPP 38.213 vh40
+	// 9.2.6	PUCCH repetition procedure
+	// For PUCCH formats 1, 3, or 4, a UE can be configured a number of slots, N_PUCCH_repeat, for repetitions of a PUCCH transmission by respective nrofSlots.
+	// - For N_PUCCH_repeat > 1, the UE repeats the PUCCH transmission with the UCI over N_PUCCH_repeat slots
+	// - For unpaired spectrum, the UE determines the N_PUCCH_repeat slots for a PUCCH transmission as the N_PUCCH_repeat slots starting from a slot indicated to the UE ... that have UL symbols ... for the PUCCH transmission
+	// - A UE does not expect to multiplex different UCI types in a PUCCH transmission with repetitions over N_PUCCH_repeat > 1 slots. If a UE would transmit a first PUCCH over more than one slot and at least a second PUCCH over one or more slots, and the transmissions of the first PUCCH and the second PUCCH would overlap in a number of slots then, for each slot of the number of slots and with UCI type priority of HARQ-ACK > SR > CSI with higher priority > CSI with lower priority
+	//  - the UE does not expect the first PUCCH and any of the second PUCCHs to start at a same slot and include a UCI type with same priority
+	//  - if the first PUCCH and any of the second PUCCHs include a UCI type with same priority, the UE transmits the PUCCH starting at an earlier slot and does not transmit the PUCCH starting at a later slot
+	//  - if the first PUCCH and any of the second PUCCHs do not include a UCI type with same priority, the UE transmits the PUCCH that includes the UCI type with higher priority and does not transmit the PUCCH that include UCI type with lower priority
+	key := fmt.Sprintf("%v_%v", n, uci)
+	if _, exist := rgd.pucchTr[key]; exist {
+		return nil
+	}
+
+	numAck := 0
+	if uci == "HARQ-ACK" || uci == "SPS-ACK" {
+		numAck = 1
+	}
+	r, muxUci, _, err := muxDedUci([]string{uci}, numAck)
+	if err != nil {
+		return err
+	}
+	rgd.pucchTr[key] = &PucchTrInfo{reqUci: []string{uci}, uci: muxUci, numAck: numAck, r: r, numRep: numRep, res: make(map[int][]int)}
+
+	// [sfn, slot] of dropped PUCCH repetitions
+	var dropped []string
+	for j, m, numTx := 0, n, 0; numTx < numRep; j, m = j+1, m+1 {
+		sfnm := m / ulSlotPerRf
+		nm := m % ulSlotPerRf
+		if !isTddSymbs(sfnm, nm, flags.pucch._pucchStartSymb[r], flags.pucch._pucchNumSymbs[r], "U") {
+			continue
+		}
+		numTx++
+
+		if old, exist := rgd.pucchSlots[m]; exist {
+			if getUciPriority(rgd.pucchTr[old].uci) >= getUciPriority([]string{uci}) {
+				dropped = append(dropped, fmt.Sprintf("%v@[%v,%v]", key, sfnm, nm))
+				continue
+			}
+
+			unmapDedPucch(old, m)
+			dropped = append(dropped, fmt.Sprintf("%v@[%v,%v]", old, sfnm, nm))
+		}
+
+		if err := aotCommon(sfnm); err != nil {
+			return err
+		}
+		if err := mapDedPucchTr(key, m, j); err != nil {
+			return err
+		}
+	}
+
+	if len(dropped) > 0 {
+		fmt.Printf("PUCCH repetitions(key=%v) overlapping: dropped=%v\n", key, dropped)
+	}
+
+	return nil
+}
+
+// muxDedUci returns the index of dedicated PUCCH resource, the UCI transmitted and the multiplexing decision for the UCI in the same slot.
+//  ucis: UCI types in the same slot, which can be SR, HARQ-ACK, SPS-ACK or CSI
+//  numAck: number of HARQ-ACK information bits in the same slot
+func muxDedUci(ucis []string, numAck int) (int, []string, string, error) {
+	srRes := utils.IndexInt(flags.pucch._pucchResId, flags.pucch._dsrPucchRes)
+	csiRes := utils.IndexInt(flags.pucch._pucchResId, flags.csi._csiRepPucchRes)
+	spsRes := utils.IndexInt(flags.pucch._pucchResId, flags.cgsps.spsN1PucchAn)
+	sr := utils.ContainsStr(ucis, "SR")
+	ack := utils.ContainsStr(ucis, "HARQ-ACK") || utils.ContainsStr(ucis, "SPS-ACK")
+	csi := utils.ContainsStr(ucis, "CSI")
+	// whether HARQ-ACK is only for SPS PDSCH without corresponding PDCCH
+	spsOnly := !utils.ContainsStr(ucis, "HARQ-ACK") && utils.ContainsStr(ucis, "SPS-ACK")
+
+	var decision []string
+	// refer to 3GPP 38.213 vh40
+	// 9.2.5.2	UE procedure for multiplexing HARQ-ACK/SR and CSI in a PUCCH
+	// If a UE is not provided simultaneousHARQ-ACK-CSI and a PUCCH resource with HARQ-ACK information overlaps with a PUCCH resource with CSI reports, the UE multiplexes only the HARQ-ACK information and SR, if any, in a PUCCH and does not transmit the CSI reports
+	if ack && csi && !flags.pucch._simHarqAckCsi {
+		csi = false
+		decision = append(decision, "CSI dropped without simultaneousHARQ-ACK-CSI")
+	}
+
+	var muxUci []string
+	for _, v := range []string{"SR", "HARQ-ACK", "CSI"} {
+		if (v == "SR" && sr) || (v == "HARQ-ACK" && ack) || (v == "CSI" && csi) {
+			muxUci = append(muxUci, v)
+		}
+	}
+
+	r := -1
+	var err error
+	switch {
+	case ack && csi && spsOnly:
+		// Note: HARQ-ACK for SPS PDSCH only is multiplexed with CSI reports in the PUCCH resource for CSI report, since there is no PUCCH resource indicator.
+		r = csiRes
+		decision = append(decision, "HARQ-ACK for SPS PDSCH, SR if any and CSI multiplexed in the PUCCH resource for CSI report")
+	case ack && csi:
+		// refer to 3GPP 38.213 vh40
+		// 9.2.5.2	UE procedure for multiplexing HARQ-ACK/SR and CSI in a PUCCH
+		// If a UE is provided simultaneousHARQ-ACK-CSI, the UE multiplexes HARQ-ACK information, SR if any, and CSI reports in a PUCCH resource that the UE determines as described in clause 9.2.3 from a PUCCH resource set with O_UCI > 2
+		r, err = getDedPucchRes(3)
+		decision = append(decision, "HARQ-ACK, SR if any and CSI multiplexed in the PUCCH resource indicated by DCI 1_1")
+	case sr && csi:
+		// refer to 3GPP 38.213 vh40
+		// 9.2.5.2	UE procedure for multiplexing HARQ-ACK/SR and CSI in a PUCCH
+		// If a UE would transmit CSI reports in a PUCCH and SR in a PUCCH that overlap, the UE multiplexes the SR bits and the CSI report bits in the PUCCH resource for CSI reports.
+		r = csiRes
+		decision = append(decision, "SR multiplexed in the PUCCH resource for CSI report")
+	case sr && ack:
+		// refer to 3GPP 38.213 vh40
+		// 9.2.5.1	UE procedure for multiplexing HARQ-ACK or CSI and SR in a PUCCH
+		// If a UE would transmit positive SR and at most two HARQ-ACK information bits in a resource using PUCCH format 0, the UE transmits PUCCH in the resource using PUCCH format 0 for HARQ-ACK information with a cyclic shift m_CS as shown in Table 9.2.5-1 and Table 9.2.5-2.
+		// If a UE would transmit SR in a resource using PUCCH format 0 and HARQ-ACK information bits in a resource using PUCCH format 1 in a slot, the UE transmits only a PUCCH with the HARQ-ACK information bits in the resource using PUCCH format 1.
+		// If a UE would transmit a PUCCH with positive SR using PUCCH format 1 and at most two HARQ-ACK information bits using PUCCH format 1 in a slot, the UE transmits the HARQ-ACK information in the PUCCH resource with PUCCH format 1 for the positive SR
+		// 9.2.5.2	UE procedure for multiplexing HARQ-ACK/SR and CSI in a PUCCH
+		// If a UE would transmit SR and HARQ-ACK information bits in a resource using PUCCH format 2/3/4, the UE multiplexes the SR bits with the HARQ-ACK information bits
+		// Note: SR is always assumed to be positive.
+		ackRes := spsRes
+		if !spsOnly {
+			ackRes, err = getDedPucchRes(numAck)
+		}
+		if err == nil && !spsOnly && numAck > 2 {
+			r, err = getDedPucchRes(numAck + 1)
+			decision = append(decision, "SR multiplexed with HARQ-ACK in the PUCCH resource indicated by DCI 1_1")
+		} else if err == nil && ackRes >= 0 && srRes >= 0 {
+			switch {
+			case flags.pucch._pucchFormat[ackRes] == "format0":
+				r = ackRes
+				decision = append(decision, "SR multiplexed in the PUCCH format 0 resource for HARQ-ACK")
+			case flags.pucch._pucchFormat[srRes] == "format0":
+				r = ackRes
+				muxUci = muxUci[1:]
+				decision = append(decision, "SR dropped and HARQ-ACK transmitted in the PUCCH format 1 resource for HARQ-ACK")
+			default:
+				r = srRes
+				decision = append(decision, "HARQ-ACK transmitted in the PUCCH resource for positive SR")
+			}
+		}
+	case ack && spsOnly:
+		// refer to 3GPP 38.213 vh40
+		// 9.2.3	UE procedure for reporting HARQ-ACK
+		// If the UE transmits HARQ-ACK information corresponding only to a PDSCH reception without a corresponding PDCCH, a PUCCH resource for corresponding PUCCH transmission with HARQ-ACK information is provided by n1PUCCH-AN.
+		r = spsRes
+	case ack:
+		// refer to 3GPP 38.213 vh40
+		// 9.2.1	PUCCH Resource Sets
+		// Note: PUCCH resource set 0 is used for at most 2 HARQ-ACK information bits, otherwise PUCCH resource set 1 is used.
+		r, err = getDedPucchRes(numAck)
+		if numAck > 1 {
+			decision = append(decision, "HARQ-ACK information bits multiplexed in the PUCCH resource indicated by DCI 1_1")
+		}
+	case sr:
+		r = srRes
+	case csi:
+		r = csiRes
+	}
+	if err != nil {
+		return -1, nil, "", err
+	}
+
+	if r < 0 {
+		return -1, nil, "", errors.New(fmt.Sprintf("No dedicated PUCCH resource is available for UCI=%v: pucchResId=%v, dsrPucchRes=%v, csiRepPucchRes=%v", ucis, flags.pucch._pucchResId, flags.pucch._dsrPucchRes, flags.csi._csiRepPucchRes))
+	}
+
+	return r, muxUci, strings.Join(decision, "; "), nil
+}
+
+// getUciPriority returns the UCI type priority of UCI on PUCCH, which is HARQ-ACK > SR > CSI.
+func getUciPriority(ucis []string) int {
+	switch {
+	case utils.ContainsStr(ucis, "HARQ-ACK") || utils.ContainsStr(ucis, "SPS-ACK"):
+		return 2
+	case utils.ContainsStr(ucis, "SR"):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// mapDedPucchTr maps the dedicated PUCCH transmission in slot m(=sfn*slotPerRf+slot) of the UL carrier.
+//  key: key of the dedicated PUCCH transmission
+//  j: index of the slot for PUCCH repetitions, which is 0 for the first PUCCH transmission
+func mapDedPucchTr(key string, m, j int) error {
+	tr := rgd.pucchTr[key]
+	ulSlotPerRf, scPerSymb, scPerSlot := getUlDims()
+	sfnu := m / ulSlotPerRf
+	nu := m % ulSlotPerRf
+	if err := aotCommon(sfnu); err != nil {
+		return err
+	}
+
+	// Note: the UCI of PUCCH without repetitions is multiplexed on the PUSCH which is already scheduled in the same slot.
+	if tr.numRep == 1 && hasSlotTag(getUlGrid, ulSlotPerRf, m, 1, "PUSCH") {
+		tr.res[m] = nil
+		rgd.pucchSlots[m] = key
+		muxUciOnPusch(tr, fmt.Sprintf("[sfn=%v, slot=%v]", sfnu, nu))
+		return nil
+	}
+
+	var res int
+	switch strings.Join(tr.uci, "+") {
+	case "SR":
+		res = NR_RES_PUCCH_SR
+	case "HARQ-ACK", "SR+HARQ-ACK":
+		res = NR_RES_PUCCH_ACK
+	case "CSI":
+		res = NR_RES_PUCCH_CSI
+	case "SR+CSI":
+		res = NR_RES_PUCCH_SR_CSI
+	default:
+		res = NR_RES_PUCCH_ACK_CSI
+	}
+
+	r := tr.r
+	bwpStart, bwpSize := getUlBwp(DED_UL_BWP)
+	ires, numPucchRes, numDmrsRes, collisions, err := mapDedPucch(getUlGrid(sfnu), r, nu, j, res, scPerSymb, scPerSlot, bwpStart, bwpSize)
+	if err != nil {
+		return err
+	}
+	tr.res[m] = ires
+	rgd.pucchSlots[m] = key
+
+	fmt.Printf("PUCCH(%v): pucchResId=%v, format=%v, PUCCH@[sfn=%v, slot=%v, firstSymb=%v, numSymbs=%v], repetition=%v/%v, prbs=%v, REs of PUCCH=%v, REs of DMRS=%v, collisions=%v\n", strings.Join(tr.uci, "+"), flags.pucch._pucchResId[r], flags.pucch._pucchFormat[r], sfnu, nu, flags.pucch._pucchStartSymb[r], flags.pucch._pucchNumSymbs[r], j, tr.numRep, getDedPucchPrbs(r, j), numPucchRes, numDmrsRes, collisions)
+
+	return nil
+}
+
+// unmapDedPucch removes the dedicated PUCCH transmission in slot m(=sfn*slotPerRf+slot) of the UL carrier from UL grid.
+//  key: key of the dedicated PUCCH transmission
+func unmapDedPucch(key string, m int) {
+	ulSlotPerRf, _, _ := getUlDims()
+	grid := getUlGrid(m / ulSlotPerRf)
+	for _, ire := range rgd.pucchTr[key].res[m] {
+		grid.res[ire] = NR_RES_U
+	}
+
+	delete(rgd.pucchTr[key].res, m)
+	delete(rgd.pucchSlots, m)
+}
+
+// muxUciOnPusch multiplexes the UCI of dedicated PUCCH transmission tr on the overlapping PUSCH at loc, where the PUCCH is not transmitted.
+func muxUciOnPusch(tr *PucchTrInfo, loc string) {
+	// refer to 3GPP 38.213 vh40
+	// 9	UE procedures for reporting control information
+	// If a UE would transmit a PUCCH with UCI ... that overlaps with a PUSCH transmission, ... the UE multiplexes the UCI in the PUSCH transmission and does not transmit the PUCCH.
+	// 9.3	UCI reporting in physical uplink shared channel
+	// Note: HARQ-ACK and CSI reports are multiplexed on PUSCH regardless of simultaneousHARQ-ACK-CSI, while SR is not multiplexed on PUSCH and the buffer status is reported by BSR MAC CE instead.
+	var muxUci []string
+	for _, v := range []string{"HARQ-ACK", "CSI"} {
+		if utils.ContainsStr(tr.reqUci, v) || (v == "HARQ-ACK" && utils.ContainsStr(tr.reqUci, "SPS-ACK")) {
+			muxUci = append(muxUci, v)
+		}
+	}
+	tr.onPusch = true
+
+	var decision []string
+	if len(muxUci) > 0 {
+		decision = append(decision, "UCI multiplexed on PUSCH")
+	}
+	decision = append(decision, "PUCCH dropped")
+	if utils.ContainsStr(tr.reqUci, "SR") {
+		decision = append(decision, "SR dropped")
+	}
+	fmt.Printf("UCI on PUSCH@%v: UCI=%v, HARQ-ACK bits=%v, multiplexed UCI=%v, decision=%v\n", loc, tr.reqUci, tr.numAck, muxUci, strings.Join(decision, "; "))
+}
+
+// hasPucchRep returns whether any of the numSlots slots starting from slot n(=sfn*slotPerRf+slot) is occupied by dedicated PUCCH with repetitions.
+func hasPucchRep(n, numSlots int) bool {
+	for m := n; m < n+numSlots; m++ {
+		if key, exist := rgd.pucchSlots[m]; exist && rgd.pucchTr[key].numRep > 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getHarqAckSlot returns the index of rgd.bwps of the active UL BWP and the slot(=sfn*slotPerRf+slot of the UL BWP) of PUCCH for HARQ-ACK of PDSCH ending in slot n(=sfn*slotPerRf+slot) of the carrier.
+// The index is -1 if the active UL BWP is the dedicated UL BWP(bwp-Id 1) whose slot is of the UL carrier, and the slot is -1 if no UL BWP can be used for the PUCCH, e.g. during BWP switching delay.
+// Note: the active UL BWP is determined in slot n+k1 of the carrier.
+func getHarqAckSlot(bsw *BwpSwInfo, n int) (int, int) {
+	// refer to 3GPP 38.213 vh40
+	// 9.2.3	UE procedure for reporting HARQ-ACK
+	// For a PDSCH reception ending in slot n, the UE provides corresponding HARQ-ACK information in a PUCCH transmission within slot n + k, where ... slot n is the last UL slot overlapping with the PDSCH reception in case the numerologies of the PDSCH and the PUCCH are different.
+	k1 := flags.dldci.tdK1 + 1
+	id := getActBwp(bsw, 1, n+k1)
+	j := getBwpIndex("UL", id)
+	if j < 0 {
+		if id == 1 {
+			ulSlotPerRf, _, _ := getUlDims()
+			return -1, getOverlapSlot(n, rgd.slotPerRf, ulSlotPerRf, true) + k1
+		}
+		return -1, -1
+	}
+
+	bd := rgd.bwps[j]
+	m := getOverlapSlot(n, rgd.slotPerRf, bd.slotPerRf, true) + k1
+	if !isBwpActive(bsw, 1, id, m*rgd.slotPerRf/bd.slotPerRf) {
+		return j, -1
+	}
+
+	return j, m
+}
+
+// isPucchSymbs returns whether all symbols of dedicated PUCCH resource r in slot m(=sfn*slotPerRf+slot) of the UL BWP j, which is the dedicated UL BWP(bwp-Id 1) of the UL carrier if j < 0, are UL.
+func isPucchSymbs(j, m, r int) bool {
+	if j < 0 {
+		ulSlotPerRf, _, _ := getUlDims()
+		return isTddSymbs(m/ulSlotPerRf, m%ulSlotPerRf, flags.pucch._pucchStartSymb[r], flags.pucch._pucchNumSymbs[r], "U")
+	}
+
+	return isBwpSymbs(j, m, flags.pucch._pucchStartSymb[r], flags.pucch._pucchNumSymbs[r], "U")
+}
+
+// isPucchMeasGap returns whether dedicated PUCCH resource r in slot m(=sfn*slotPerRf+slot) of the UL BWP j, which is the dedicated UL BWP(bwp-Id 1) of the UL carrier if j < 0, overlaps with measurement gaps.
+func isPucchMeasGap(j, m, r int) bool {
+	if j < 0 {
+		ulSlotPerRf, _, _ := getUlDims()
+		return isMeasGap(m, 1, flags.pucch._pucchStartSymb[r], flags.pucch._pucchNumSymbs[r], rgd.symbPerSlot, ulSlotPerRf/rgd.subfPerRf)
+	}
+
+	bd := rgd.bwps[j]
+	return isMeasGap(m, 1, flags.pucch._pucchStartSymb[r], flags.pucch._pucchNumSymbs[r], bd.symbPerSlot, bd.slotPerRf/rgd.subfPerRf)
+}
+
+// addBwpHarqAck adds HARQ-ACK to dedicated PUCCH in slot m(=sfn*slotPerRf+slot) of additional dedicated UL BWP j, which is multiplexed with other HARQ-ACK information bits in the same slot.
+// Note: the PUCCH-Config of additional dedicated UL BWPs is assumed to be the same as the dedicated UL BWP(bwp-Id 1), where PRBs of PUCCH resources are relative to RB_start of the BWP. SR and CSI reports are only transmitted on the dedicated UL BWP, and PUCCH repetitions are not supported on additional dedicated UL BWPs.
+func addBwpHarqAck(j, m int) error {
+	bd := rgd.bwps[j]
+	numAck := 1
+	old, exist := bd.pucchTr[m]
+	if exist {
+		numAck += old.numAck
+	}
+
+	r, muxUci, _, err := muxDedUci([]string{"HARQ-ACK"}, numAck)
+	if err != nil {
+		return err
+	}
+
+	sfn, slot := m/bd.slotPerRf, m%bd.slotPerRf
+	// Note: the PUCCH already mapped in the slot, if any, is kept when the PUCCH resource of the multiplexed HARQ-ACK can't be transmitted.
+	if !isBwpSymbs(j, m, flags.pucch._pucchStartSymb[r], flags.pucch._pucchNumSymbs[r], "U") {
+		fmt.Printf("PUCCH(HARQ-ACK)@UL BWP%v[%v,%v] dropped due to non-UL symbols.\n", bd.id, sfn, slot)
+		return nil
+	}
+
+	grid := getBwpGrid(j, sfn)
+	if exist {
+		for _, ire := range old.res[m] {
+			grid.res[ire] = NR_RES_U
+		}
+		fmt.Printf("UCI multiplexing@UL BWP%v[sfn=%v, slot=%v]: HARQ-ACK bits=%v, pucchResId=%v\n", bd.id, sfn, slot, numAck, flags.pucch._pucchResId[r])
+	}
+
+	// Note: HARQ-ACK is multiplexed on the PUSCH which is already scheduled in the same slot of the BWP.
+	if tags := grid.tags[slot]; tags != nil && tags.Contains("PUSCH") {
+		bd.pucchTr[m] = &PucchTrInfo{reqUci: []string{"HARQ-ACK"}, uci: muxUci, numAck: numAck, r: r, numRep: 1, res: make(map[int][]int)}
+		muxUciOnPusch(bd.pucchTr[m], fmt.Sprintf("UL BWP%v[sfn=%v, slot=%v]", bd.id, sfn, slot))
+		return nil
+	}
+
+	ires, numPucchRes, numDmrsRes, collisions, err := mapDedPucch(grid, r, slot, 0, NR_RES_PUCCH_ACK, bd.scPerSymb, bd.scPerSlot, bd.startRb, bd.numRbs)
+	if err != nil {
+		return err
+	}
+	bd.pucchTr[m] = &PucchTrInfo{reqUci: []string{"HARQ-ACK"}, uci: muxUci, numAck: numAck, r: r, numRep: 1, res: map[int][]int{m: ires}}
+
+	fmt.Printf("PUCCH(HARQ-ACK) on UL BWP%v: pucchResId=%v, format=%v, PUCCH@[sfn=%v, slot=%v, firstSymb=%v, numSymbs=%v], prbs=%v, REs of PUCCH=%v, REs of DMRS=%v, collisions=%v\n", bd.id, flags.pucch._pucchResId[r], flags.pucch._pucchFormat[r], sfn, slot, flags.pucch._pucchStartSymb[r], flags.pucch._pucchNumSymbs[r], getDedPucchPrbs(r, 0), numPucchRes, numDmrsRes, collisions)
+
+	return nil
+}
+
+// schedData schedules PDSCH by DCI 1_1 and PUSCH by DCI 0_1 with C-RNTI in the dedicated BWPs for advanced.numSchedRfs radio frames, starting from the slot next to slot of radio frame sfn, and returns number of PDSCH and number of PUSCH scheduled.
+//  sfn: radio frame of the last step of random access procedure
+//  slot: slot of the last step of random access procedure
+func schedData(sfn, slot int) (int, int, error) {
+	if flags.advanced.cRnti < 1 || flags.advanced.cRnti > 65519 {
+		return -1, -1, errors.New(fmt.Sprintf("Invalid C-RNTI(=%v), which must be within [1, 65519]!", flags.advanced.cRnti))
+	}
+
+	iss := utils.IndexStr(flags.searchspace._ssType, "uss")
+	if iss < 0 || flags.searchspace._ssCoresetId[iss] != 1 {
+		return -1, -1, errors.New(fmt.Sprintf("USS must be configured in CORESET1!"))
+	}
+	L, _ := strconv.Atoi(flags.searchspace.ssAggregationLevel[iss][2:])
+	if L > rgd.coreset1NumCces {
+		return -1, -1, errors.New(fmt.Sprintf("Invalid configurations of USS/CORESET1: aggregation level=%v while total number of CCEs=%v!", L, rgd.coreset1NumCces))
+	}
+
+	period, _ := strconv.Atoi(flags.searchspace._ssPeriodicity[iss][2:])
+	offset := flags.searchspace._ssSlotOffset[iss]
+	duration := flags.searchspace._ssDuration[iss]
+
+	k0 := flags.dldci._tdK0[DCI_11_PDSCH]
+	k1 := flags.dldci.tdK1 + 1
+	k2 := flags.uldci._tdK2[DCI_01_PUSCH]
+	r, err := getDedPucchRes(1)
+	if err != nil {
+		return -1, -1, err
+	}
+	// number of slots of PDSCH with pdsch-AggregationFactor, PUSCH with repetitions and PUCCH with repetitions
+	numSlotsPdsch, _ := strconv.Atoi(flags.pdsch._pdschAggFactor[1:])
+	numRepsPusch, numSlotsPusch := getDedPuschReps()
+	numRepPucch, _ := strconv.Atoi(flags.pucch._numSlots[1:])
+
+	// init always-on-transmission of all radio frames involved so that periodic CSI-RS/SRS are mapped before PDSCH/PUSCH
+	// Note: K0 and K1/K2 are in slots of PDSCH and PUCCH/PUSCH, which are of the supplementary carrier if SDL or SUL is used, and they are converted to slots of the carrier.
+	ulSlotPerRf, _, _ := getUlDims()
+	pdschSlotPerRf, _, _ := getPdschDims()
+	kd := utils.CeilInt(float64((k0+numSlotsPdsch)*rgd.slotPerRf)/float64(pdschSlotPerRf)) - 1 + utils.CeilInt(float64(k1*rgd.slotPerRf)/float64(ulSlotPerRf))
+	ku := utils.CeilInt(float64((k2+numSlotsPusch)*rgd.slotPerRf)/float64(ulSlotPerRf)) - 1
+	n0 := sfn*rgd.slotPerRf + slot
+	numSlots := flags.advanced.numSchedRfs * rgd.slotPerRf
+	for f := sfn; f <= (n0+numSlots+utils.MaxInt([]int{kd, ku}))/rgd.slotPerRf; f++ {
+		if err := alwaysOnTr(f, 0); err != nil {
+			return -1, -1, err
+		}
+	}
+
+	// DRX of the UE, which is nil if DRX is not configured
+	var drx *DrxInfo
+	if flags.drx.drxEnabled {
+		drx = &DrxInfo{numSlots: numSlots}
+		rgd.drx = drx
+	}
+
+	// BWP switching of the UE, which is nil if neither additional dedicated BWP nor BWP switching is configured
+	var bsw *BwpSwInfo
+	if len(rgd.bwps) > 0 || len(flags.bwp.bwpSwTrigger) > 0 {
+		bsw = &BwpSwInfo{actBwp: []int{1, 1}, actFrom: []int{0, 0}, prevBwp: []int{1, 1}, prevUntil: []int{0, 0}, inactEnd: -1}
+		rgd.bsw = bsw
+
+		// Note: BWP switchings are only performed during data scheduling after random access procedure.
+		for i, trigger := range flags.bwp.bwpSwTrigger {
+			if m := flags.bwp.bwpSwSfn[i]*rgd.slotPerRf + flags.bwp.bwpSwSlot[i]; m <= n0 || m > n0+numSlots {
+				regYellow.Printf("BWP switching(bwpSwTrigger=%v, bwpSwDir=%v, bwpSwBwpId=%v)@[%v,%v] is ignored since it's out of data scheduling slots[%v,%v]~[%v,%v].\n", trigger, flags.bwp.bwpSwDir[i], flags.bwp.bwpSwBwpId[i], flags.bwp.bwpSwSfn[i], flags.bwp.bwpSwSlot[i], (n0+1)/rgd.slotPerRf, (n0+1)%rgd.slotPerRf, (n0+numSlots)/rgd.slotPerRf, (n0+numSlots)%rgd.slotPerRf)
+			}
+		}
+	}
+
+	numPdsch, numPusch := 0, 0
+	numBwpPdsch, numBwpPusch := 0, 0
+	numScellPdsch, numScellPusch := make([]int, len(rgd.scells)), make([]int, len(rgd.scells))
+	// [sfn, slot] of PDSCH/PUSCH which are not scheduled due to PDCCH blocking
+	var blocked []string
+	for i := 1; i <= numSlots; i++ {
+		sfnc := (n0 + i) / rgd.slotPerRf
+		nc := (n0 + i) % rgd.slotPerRf
+
+		if bsw != nil {
+			if err := updateBwpSw(bsw, n0+i, iss); err != nil {
+				return -1, -1, err
+			}
+		}
+
+		active := true
+		if drx != nil {
+			active = updateDrx(drx, n0+i)
+			if active {
+				drx.numActiveSlots++
+			}
+		}
+
+		// refer to 3GPP 38.213 vh40
+		// 10.1	UE procedure for determining physical downlink control channel assignment
+		// A UE determines that a PDCCH monitoring occasion on an active DL BWP exists in a slot with number n_s_f_u in a frame with number n_f if (n_f*N_frame_slot + n_s_f_u - o_s) mod k_s = 0.
+		if ((sfnc*rgd.slotPerRf+nc-offset)%period+period)%period >= duration {
+			continue
+		}
+
+		// refer to 3GPP 38.321 vh40
+		// 5.7	Discontinuous Reception (DRX)
+		// When DRX is configured, the MAC entity shall ... if the MAC entity is in Active Time: monitor the PDCCH as specified in TS 38.213 [6];
+		var retxDl, retxUl int
+		newDl, newUl := true, true
+		if drx != nil {
+			drx.numMos++
+			if !active {
+				continue
+			}
+			drx.numActiveMos++
+
+			retxDl = getDrxRetx(drx.retxDl, n0+i, true)
+			retxUl = getDrxRetx(drx.retxUl, n0+i, true)
+			newDl = flags.drx.numTxPerCycle == 0 || drx.numNewDl < flags.drx.numTxPerCycle
+			newUl = flags.drx.numTxPerCycle == 0 || drx.numNewUl < flags.drx.numTxPerCycle
+		}
+
+		// number of PDSCH/PUSCH scheduled before current slot, which is used to restart bwp-InactivityTimer
+		numSched := numPdsch + numPusch + numBwpPdsch + numBwpPusch
+
+		// DCI 1_1 is scheduled only if all symbols of PDSCH are DL and all symbols of the PUCCH for HARQ-ACK are UL, and the PDSCH doesn't overlap with other PDSCH(e.g. SPS PDSCH or PDSCH with pdsch-AggregationFactor)
+		// Note: PDSCH/PUSCH are scheduled on the dedicated DL/UL BWP(bwp-Id 1) only when it's active, and HARQ-ACK is reported on the active UL BWP.
+		// refer to 3GPP 38.214 vh40
+		// 5.1.2.1	Resource allocation in time domain
+		// The slot allocated for the PDSCH is floor(n*2^u_PDSCH/2^u_PDCCH) + K0
+		md := getOverlapSlot(n0+i, rgd.slotPerRf, pdschSlotPerRf, false) + k0
+		sfnd := md / pdschSlotPerRf
+		nd := md % pdschSlotPerRf
+		// Note: DCI 1_1 is not scheduled if the PUCCH for HARQ-ACK with repetitions would overlap with PUSCH which is already scheduled, since the PUSCH can't be dropped afterwards.
+		jh, mh := getHarqAckSlot(bsw, getOverlapSlot(md+numSlotsPdsch-1, pdschSlotPerRf, rgd.slotPerRf, true))
+		schedDl := isBwpActive(bsw, 0, 1, n0+i) && isBwpActive(bsw, 0, 1, n0+i+k0) && mh >= 0 && (newDl || retxDl >= 0) && !hasSlotTag(getPdschGrid, pdschSlotPerRf, md, numSlotsPdsch, "PDSCH") && isTddSymbs(sfnd, nd, flags.dldci._tdStartSymb[DCI_11_PDSCH], flags.dldci._tdNumSymbs[DCI_11_PDSCH], "D") && isPucchSymbs(jh, mh, r) && (numRepPucch == 1 || jh >= 0 || !hasSlotTag(getUlGrid, ulSlotPerRf, mh, numRepPucch, "PUSCH"))
+		// refer to 3GPP 38.133 vh40
+		// 9.1.2	Measurement gap
+		// Note: DCI 1_1 is not scheduled if any of the PDCCH monitoring occasion, the PDSCH and the PUCCH for HARQ-ACK overlaps with measurement gaps, and the scheduling opportunity is regarded as lost.
+		if schedDl && (isMeasGapMo(n0+i) || isMeasGap(md, numSlotsPdsch, flags.dldci._tdStartSymb[DCI_11_PDSCH], flags.dldci._tdNumSymbs[DCI_11_PDSCH], rgd.symbPerSlot, pdschSlotPerRf/rgd.subfPerRf) || isPucchMeasGap(jh, mh, r)) {
+			rgd.measGapLost = append(rgd.measGapLost, fmt.Sprintf("PDSCH@[%v,%v]", sfnd, nd))
+			schedDl = false
+		}
+		if schedDl {
+			cces, err := monitorUssPdcch(sfnc, nc, iss, 0, "DCI 1_1", "C-RNTI")
+			if err != nil {
+				return -1, -1, err
+			}
+
+			if cces == nil {
+				blocked = append(blocked, fmt.Sprintf("PDSCH@[%v,%v]", sfnd, nd))
+			} else {
+				sfnd, nd, err = recvPdsch(sfnc, nc, "C-RNTI")
+				if err != nil {
+					return -1, -1, err
+				}
+
+				sfnh, nh, err := sendPucch(sfnd, nd, true, false, false, "dedicated")
+				if err != nil {
+					return -1, -1, err
+				}
+				numPdsch++
+
+				if drx != nil {
+					// refer to 3GPP 38.321 vh40
+					// 5.7	Discontinuous Reception (DRX)
+					// 1> if a MAC PDU is received in a configured downlink assignment: start the drx-HARQ-RTT-TimerDL for the corresponding HARQ process in the first symbol after the end of the corresponding transmission carrying the DL HARQ feedback;
+					// 1> if a drx-HARQ-RTT-TimerDL expires: if the data of the corresponding HARQ process was not successfully decoded: start the drx-RetransmissionTimerDL for the corresponding HARQ process in the first symbol after the expiry of drx-HARQ-RTT-TimerDL.
+					// 1> if the PDCCH indicates a DL transmission: ... stop the drx-RetransmissionTimerDL for the corresponding HARQ process.
+					// 1> if the PDCCH indicates a new transmission (DL or UL): start or restart drx-InactivityTimer in the first symbol after the end of the PDCCH reception.
+					if retxDl >= 0 {
+						drx.retxDl = append(drx.retxDl[:retxDl], drx.retxDl[retxDl+1:]...)
+						fmt.Printf("DRX: PDSCH retransmission is scheduled within drx-RetransmissionTimerDL @ [SFN=%v, Slot=%v]\n", sfnc, nc)
+					} else {
+						drx.numNewDl++
+						if nackDrxNewTx(drx, n0+i) {
+							start := getDrxTimerStart(sfnh*ulSlotPerRf+nh, ulSlotPerRf, flags.pucch._pucchStartSymb[r]+flags.pucch._pucchNumSymbs[r]+flags.drx.drxHarqRttTimerDl)
+							retx, _ := strconv.Atoi(flags.drx.drxRetransmissionTimerDl[2:])
+							drx.retxDl = append(drx.retxDl, []int{start, start + retx, 1})
+						}
+					}
+				}
+			}
+		}
+
+		// DCI 0_1 is scheduled only if all symbols of PUSCH are UL(or any actual repetition is transmitted for PUSCH repetition Type B), and the PUSCH doesn't overlap with other PUSCH(e.g. configured grant PUSCH or PUSCH with repetitions)
+		// refer to 3GPP 38.214 vh40
+		// 6.1.2.1	Resource allocation in time domain
+		// The slot where the UE shall transmit the PUSCH is determined by K2 as floor(n*2^u_PUSCH/2^u_PDCCH) + K2
+		mu := getOverlapSlot(n0+i, rgd.slotPerRf, ulSlotPerRf, false) + k2
+		sfnu := mu / ulSlotPerRf
+		nu := mu % ulSlotPerRf
+		S, L := flags.uldci._tdStartSymb[DCI_01_PUSCH], flags.uldci._tdNumSymbs[DCI_01_PUSCH]
+		// slots and symbols of PUSCH with repetitions which are checked against measurement gaps
+		gapSlots, gapSymbs := numSlotsPusch, L
+		validUl := false
+		if flags.pusch._puschRepType == "typeA" {
+			validUl = isTddSymbs(sfnu, nu, S, L, "U")
+		} else {
+			for _, rep := range getPuschReps(mu, S, L, numRepsPusch, "typeB") {
+				validUl = validUl || rep[4] == 0
+			}
+			gapSlots, gapSymbs = 1, numRepsPusch*L
+		}
+		schedUl := isBwpActive(bsw, 1, 1, n0+i+k2) && (newUl || retxUl >= 0) && !hasSlotTag(getUlGrid, ulSlotPerRf, mu, numSlotsPusch, "PUSCH") && !hasPucchRep(mu, numSlotsPusch) && validUl
+		if schedUl && (isMeasGapMo(n0+i) || isMeasGap(mu, gapSlots, S, gapSymbs, rgd.symbPerSlot, ulSlotPerRf/rgd.subfPerRf)) {
+			rgd.measGapLost = append(rgd.measGapLost, fmt.Sprintf("PUSCH@[%v,%v]", sfnu, nu))
+			schedUl = false
+		}
+		if schedUl {
+			// Note: the PDCCH scheduling PUSCH on the dedicated UL BWP is transmitted on the DL BWP which can be used in the slot.
+			cces, _, err := monitorActDlBwpPdcch(bsw, n0+i, iss, "DCI 0_1")
+			if err != nil {
+				return -1, -1, err
+			}
+
+			if cces == nil {
+				blocked = append(blocked, fmt.Sprintf("PUSCH@[%v,%v]", sfnu, nu))
+			} else {
+				sfnu, nu, err = sendPusch(sfnc, nc, flags.uldci._tdStartSymb[DCI_01_PUSCH], "C-RNTI")
+				if err != nil {
+					return -1, -1, err
+				}
+				numPusch++
+
+				if drx != nil {
+					// refer to 3GPP 38.321 vh40
+					// 5.7	Discontinuous Reception (DRX)
+					// 1> if the PDCCH indicates a UL transmission: start the drx-HARQ-RTT-TimerUL for the corresponding HARQ process in the first symbol after the end of the first repetition of the corresponding PUSCH transmission; stop the drx-RetransmissionTimerUL for the corresponding HARQ process.
+					// 1> if a drx-HARQ-RTT-TimerUL expires: start the drx-RetransmissionTimerUL for the corresponding HARQ process in the first symbol after the expiry of drx-HARQ-RTT-TimerUL.
+					// Note: drx-RetransmissionTimerUL is started for every PUSCH and keeps the UE in active time, but retransmission is only scheduled for PUSCH which is not successfully decoded, and retransmission is always assumed to be successfully decoded.
+					nack := 0
+					if retxUl >= 0 {
+						drx.retxUl = append(drx.retxUl[:retxUl], drx.retxUl[retxUl+1:]...)
+						fmt.Printf("DRX: PUSCH retransmission is scheduled within drx-RetransmissionTimerUL @ [SFN=%v, Slot=%v]\n", sfnc, nc)
+					} else {
+						drx.numNewUl++
+						if nackDrxNewTx(drx, n0+i) {
+							nack = 1
+						}
+					}
+					start := getDrxTimerStart(sfnu*ulSlotPerRf+nu, ulSlotPerRf, flags.uldci._tdStartSymb[DCI_01_PUSCH]+flags.uldci._tdNumSymbs[DCI_01_PUSCH]+flags.drx.drxHarqRttTimerUl)
+					retx, _ := strconv.Atoi(flags.drx.drxRetransmissionTimerUl[2:])
+					drx.retxUl = append(drx.retxUl, []int{start, start + retx, nack})
+				}
+			}
+		}
+
+		// additional dedicated BWPs and bwp-InactivityTimer
+		if bsw != nil {
+			nd, bd, err := schedBwp(bsw, 0, n0+i, iss)
+			if err != nil {
+				return -1, -1, err
+			}
+			nu, bu, err := schedBwp(bsw, 1, n0+i, iss)
+			if err != nil {
+				return -1, -1, err
+			}
+			numBwpPdsch += nd
+			numBwpPusch += nu
+			blocked = append(append(blocked, bd...), bu...)
+
+			if numPdsch+numPusch+numBwpPdsch+numBwpPusch > numSched {
+				restartBwpInactivityTimer(bsw, n0+i)
+			}
+		}
+
+		// SCells of carrier aggregation
+		// Note: SCells belong to the same DRX group as the PCell, and numTxPerCycle of DRX only applies to the PCell.
+		for j := range rgd.scells {
+			nd, nu, b, err := schedScell(j, sfnc, nc, iss)
+			if err != nil {
+				return -1, -1, err
+			}
+			numScellPdsch[j] += nd
+			numScellPusch[j] += nu
+			blocked = append(blocked, b...)
+		}
+
+		if drx != nil {
+			markDrxMo(sfnc, nc, iss)
+		}
+	}
+
+	if len(blocked) > 0 {
+		fmt.Printf("PDSCH/PUSCH blocked due to no available PDCCH candidate: %v\n", blocked)
+	}
+
+	if bsw != nil {
+		fmt.Printf("BWP switchings: %v\n", bsw.history)
+		regGreen.Printf("[INFO]: Data scheduling: %v PDSCH(s) and %v PUSCH(s) are scheduled on additional dedicated BWP(s) within %v radio frame(s)\n", numBwpPdsch, numBwpPusch, flags.advanced.numSchedRfs)
+	}
+
+	for j := range rgd.scells {
+		regGreen.Printf("[INFO]: Data scheduling: %v PDSCH(s) and %v PUSCH(s) are scheduled on SCell%v(cif=%v) within %v radio frame(s)\n", numScellPdsch[j], numScellPusch[j], flags.ca.scellIndex[j], flags.ca.scellCif[j], flags.advanced.numSchedRfs)
+	}
+
+	if drx != nil && drx.numMos > 0 {
+		regGreen.Printf("[INFO]: DRX: %v of %v PDCCH monitoring occasions(USS) are within active time, monitoring ratio=%.2f%%, active time=%v of %v slots(%.2f%%)\n", drx.numActiveMos, drx.numMos, float64(100*drx.numActiveMos)/float64(drx.numMos), drx.numActiveSlots, drx.numSlots, float64(100*drx.numActiveSlots)/float64(drx.numSlots))
+	}
+
+	return numPdsch, numPusch, nil
+}
+
+// hasSlotTag returns whether any of the numSlots slots starting from slot n(=sfn*slotPerRf+slot) is tagged with tag in the resource grid returned by getGrid.
+//  slotPerRf: number of slots per radio frame of the resource grid
+func hasSlotTag(getGrid func(sfn int) DataPerRf, slotPerRf, n, numSlots int, tag string) bool {
+	for k := n; k < n+numSlots; k++ {
+		if tags := getGrid(k / slotPerRf).tags[k%slotPerRf]; tags != nil && tags.Contains(tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getDrxTimerStart returns the slot(=sfn*slotPerRf+slot of the carrier) of the symbol which is numSymbs symbols after the start of slot m(=sfn*slotPerRf+slot) of the UL carrier, where drx-HARQ-RTT-TimerDL/UL expires.
+//  ulSlotPerRf: number of slots per radio frame of the UL carrier
+func getDrxTimerStart(m, ulSlotPerRf, numSymbs int) int {
+	return (m*rgd.symbPerSlot + numSymbs) * rgd.slotPerRf / ulSlotPerRf / rgd.symbPerSlot
+}
+
+// updateDrx updates DRX timers at the beginning of slot n(=sfn*slotPerRf+slot), and returns whether slot n is within active time.
+func updateDrx(drx *DrxInfo, n int) bool {
+	onDuration, _ := strconv.Atoi(flags.drx.drxOnDurationTimer[2:])
+	longCycle, _ := strconv.Atoi(flags.drx.drxLongCycle[2:])
+
+	// refer to 3GPP 38.321 vh40
+	// 5.7	Discontinuous Reception (DRX)
+	// 1> if the drx-InactivityTimer expires or a DRX Command MAC CE is received:
+	//  2> if the Short DRX cycle is configured: start or restart drx-ShortCycleTimer in the first symbol after the expiry of drx-InactivityTimer or in the first symbol after the end of DRX Command MAC CE reception; use the Short DRX cycle.
+	//  2> else: use the Long DRX cycle.
+	// 1> if drx-ShortCycleTimer expires: use the Long DRX cycle.
+	shortCycle := 0
+	if len(flags.drx.drxShortCycle) > 0 {
+		shortCycle, _ = strconv.Atoi(flags.drx.drxShortCycle[2:])
+		if n == drx.inactivityEnd {
+			drx.shortCycleEnd = n + flags.drx.drxShortCycleTimer*shortCycle*rgd.slotPerSubf
+		}
+	}
+
+	// 1> if the Short DRX cycle is used, and [(SFN x 10) + subframe number] modulo (drx-ShortCycle) = (drx-StartOffset) modulo (drx-ShortCycle):
+	//  2> start drx-onDurationTimer after drx-SlotOffset from the beginning of the subframe.
+	// 1> if the Long DRX cycle is used, and [(SFN x 10) + subframe number] modulo (drx-LongCycle) = drx-StartOffset:
+	//  2> start drx-onDurationTimer after drx-SlotOffset from the beginning of the subframe.
+	// Note: drx-SlotOffset is assumed to be 0, and new data arrives at the start of each DRX cycle.
+	if n%rgd.slotPerSubf == 0 {
+		subf := (n / rgd.slotPerSubf) % 10240
+		if (n < drx.shortCycleEnd && subf%shortCycle == flags.drx.drxStartOffset%shortCycle) || (n >= drx.shortCycleEnd && subf%longCycle == flags.drx.drxStartOffset) {
+			drx.onDurationEnd = n + onDuration*rgd.slotPerSubf
+			drx.numNewDl, drx.numNewUl = 0, 0
+			fmt.Printf("DRX: drx-onDurationTimer is started @ [SFN=%v, Slot=%v], shortCycle=%v\n", n/rgd.slotPerRf, n%rgd.slotPerRf, n < drx.shortCycleEnd)
+		}
+	}
+
+	// 5.7	Discontinuous Reception (DRX)
+	// When a DRX cycle is configured, the Active Time for Serving Cells in a DRX group includes the time while:
+	// - drx-onDurationTimer or drx-InactivityTimer configured for the DRX group is running; or
+	// - drx-RetransmissionTimerDL or drx-RetransmissionTimerUL is running on any Serving Cell in the DRX group; or ...
+	if n < drx.onDurationEnd || n < drx.inactivityEnd || getDrxRetx(drx.retxDl, n, false) >= 0 || getDrxRetx(drx.retxUl, n, false) >= 0 {
+		return true
+	}
+
+	return false
+}
+
+// getDrxRetx returns the index of the running drx-RetransmissionTimerDL/UL in slot n(=sfn*slotPerRf+slot), or -1 if no drx-RetransmissionTimerDL/UL is running.
+//  nack: whether only drx-RetransmissionTimerDL/UL of HARQ process which is not successfully decoded is considered
+func getDrxRetx(retx [][]int, n int, nack bool) int {
+	for i, t := range retx {
+		if n >= t[0] && n < t[1] && (!nack || t[2] == 1) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// nackDrxNewTx starts or restarts drx-InactivityTimer upon new transmission indicated by PDCCH in slot n(=sfn*slotPerRf+slot), and returns whether the new transmission is not successfully decoded.
+func nackDrxNewTx(drx *DrxInfo, n int) bool {
+	inactivity, _ := strconv.Atoi(flags.drx.drxInactivityTimer[2:])
+	if inactivity > 0 {
+		drx.inactivityEnd = n + inactivity*rgd.slotPerSubf + 1
+	}
+
+	drx.numNewTx++
+	return flags.drx.nackPeriod > 0 && drx.numNewTx%flags.drx.nackPeriod == 0
+}
+
+// markDrxMo marks REs of CORESET1 which are not occupied by PDCCH in the PDCCH monitoring occasion of USS within active time.
+//  iss: index of the USS
+func markDrxMo(sfn, slot, iss int) {
+	coreset1Sc0Rb0 := flags.searchspace.coreset1StartCrb * rgd.scPerRb
+
+	grid := getDlGrid(sfn)
+	for firstSymb, bit := range flags.searchspace._ssMonitoringSymbolWithinSlot[iss] {
+		if bit != '1' {
+			continue
+		}
+
+		for _, reg := range rgd.coreset1RegBundles {
+			for isc := 0; isc < rgd.scPerRb; isc++ {
+				ire := slot*rgd.scPerSlot + (firstSymb+reg.Isymb)*rgd.scPerSymb + coreset1Sc0Rb0 + reg.Irb*rgd.scPerRb + isc
+				if grid.res[ire] == NR_RES_D {
+					grid.res[ire] = NR_RES_CORESET1
+				}
+			}
+		}
+	}
+
+	if grid.tags[slot] == nil {
+		grid.tags[slot] = mapset.NewSet()
+	}
+	grid.tags[slot].Add("ACTIVE")
+}
+
+// isTddSymbs returns whether all symbols in [firstSymb, firstSymb+numSymbs) of slot in radio frame sfn are of the given direction(D or U) according to the slot format determined by getTddPat, which is always true for FDD.
+func isTddSymbs(sfn, slot, firstSymb, numSymbs int, dir string) bool {
+	// all symbols of SUL carrier are UL, and all symbols of SDL carrier are DL
+	if flags.gridsetting._duplexMode != "TDD" || (dir == "U" && isSulUsed()) || (dir == "D" && isSdlUsed()) {
+		return true
+	}
+
+	pat := getTddPat(sfn)
+	for symb := firstSymb; symb < firstSymb+numSymbs; symb++ {
+		if pat[slot*rgd.symbPerSlot+symb] != dir {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasTddDlSymbs returns whether any symbol in [firstSymb, firstSymb+numSymbs) relative to the start of slot n(=sfn*slotPerRf+slot) is DL according to the slot format determined by getTddPat, which is always false for FDD or SUL carrier.
+func hasTddDlSymbs(n, firstSymb, numSymbs int) bool {
+	if flags.gridsetting._duplexMode != "TDD" || isSulUsed() {
+		return false
+	}
+
+	for a := n*rgd.symbPerSlot + firstSymb; a < n*rgd.symbPerSlot+firstSymb+numSymbs; a++ {
+		if getTddPat(a / rgd.symbPerRf)[a%rgd.symbPerRf] == "D" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getTddPat returns the slot format(D, U or F) of each symbol of radio frame sfn, which is determined by tdd-UL-DL-ConfigurationCommon, tdd-UL-DL-ConfigurationDedicated and the slot format combinations indicated by DCI 2_0.
+func getTddPat(sfn int) []string {
+	if pat, exist := rgd.tddPat[sfn]; exist {
+		return pat
+	}
+
+	pat := make([]string, rgd.symbPerRf)
+	if sfn%2 == 0 {
+		copy(pat, rgd.tddPatEvenRf)
+	} else {
+		copy(pat, rgd.tddPatOddRf)
+	}
+
+	for slot := 0; slot < rgd.slotPerRf; slot++ {
+		n := sfn*rgd.slotPerRf + slot
+
+		// refer to 3GPP 38.213 vh40
+		// 11.1	Slot configuration
+		// If the UE is additionally provided tdd-UL-DL-ConfigurationDedicated, the parameter tdd-UL-DL-ConfigurationDedicated overrides only flexible symbols per slot over the number of slots as provided by tdd-UL-DL-ConfigurationCommon.
+		// - for each slot, a slot index provided by slotIndex, a set of symbols provided by symbols where: if symbols = allDownlink, all symbols in the slot are downlink; if symbols = allUplink, all symbols in the slot are uplink; if symbols = explicit, nrofDownlinkSymbols provides a number of downlink first symbols in the slot and nrofUplinkSymbols provides a number of uplink last symbols in the slot.
+		if i := utils.IndexInt(flags.tdduldl.dedSlotIndex, n%rgd.tddNumSlots); i >= 0 {
+			numDlSymbs, numUlSymbs := flags.tdduldl.dedNumDlSymbs[i], flags.tdduldl.dedNumUlSymbs[i]
+			switch flags.tdduldl.dedSymbols[i] {
+			case "allDownlink":
+				numDlSymbs, numUlSymbs = rgd.symbPerSlot, 0
+			case "allUplink":
+				numDlSymbs, numUlSymbs = 0, rgd.symbPerSlot
+			}
+			for symb := 0; symb < rgd.symbPerSlot; symb++ {
+				k := slot*rgd.symbPerSlot + symb
+				if pat[k] != "F" {
+					continue
+				}
+				if symb < numDlSymbs {
+					pat[k] = "D"
+				} else if symb >= rgd.symbPerSlot-numUlSymbs {
+					pat[k] = "U"
+				}
+			}
+		}
+
+		// refer to 3GPP 38.213 vh40
+		// 11.1.1	UE procedure for determining slot format
+		// An SFI-index field value in a DCI format 2_0 indicates to a UE a slot format for each slot in a number of slots for each DL BWP or each UL BWP starting from a slot where the UE detects the DCI format 2_0.
+		// For a set of symbols of a slot that are indicated as downlink/uplink by tdd-UL-DL-ConfigurationCommon, or tdd-UL-DL-ConfigurationDedicated, the UE does not expect to detect a DCI format 2_0 with an SFI-index field value indicating the set of symbols of the slot as uplink/downlink, respectively, or as flexible.
+		// A UE configured ... with a reference SCS configuration μSFI ... expects that ... each slot format in a combination of slot formats indicated by an SFI-index field value in DCI format 2_0 is applicable to 2^(μ−μSFI) consecutive slots in the active DL BWP or the active UL BWP where the first slot starts at a same time as a first slot for the reference SCS configuration μSFI and each downlink or flexible or uplink symbol for the reference SCS configuration μSFI corresponds to 2^(μ−μSFI) consecutive downlink or flexible or uplink symbols for the SCS configuration μ.
+		// Note: the slot format indicated by the latest DCI 2_0 which covers the slot is applied, and DCI 2_0 is assumed to be detected in every monitoring occasion starting from SFN 0.
+		if flags.tdduldl.sfiPeriod == 0 {
+			continue
+		}
+		r := getSfiSlotRatio()
+		for m := n - ((n-flags.tdduldl.sfiOffset)%flags.tdduldl.sfiPeriod+flags.tdduldl.sfiPeriod)%flags.tdduldl.sfiPeriod; m >= 0 && n-m < 256*r; m -= flags.tdduldl.sfiPeriod {
+			sfi := flags.tdduldl.sfiIndex[((m-flags.tdduldl.sfiOffset)/flags.tdduldl.sfiPeriod)%len(flags.tdduldl.sfiIndex)]
+			formats := strings.Split(flags.tdduldl.sfcSlotFormats[utils.IndexInt(flags.tdduldl.sfcId, sfi)], "_")
+			if n/r-m/r >= len(formats) {
+				continue
+			}
+
+			sf, _ := strconv.Atoi(formats[n/r-m/r])
+			if sf == 255 {
+				break
+			}
+			// symbols whose semi-static direction conflicts with the slot format, which are ignored
+			var conflicts []int
+			for symb := 0; symb < rgd.symbPerSlot; symb++ {
+				k := slot*rgd.symbPerSlot + symb
+				d := string(nrgrid.SlotFormats[sf][((n%r)*rgd.symbPerSlot+symb)/r])
+				if pat[k] == "F" {
+					pat[k] = d
+				} else if pat[k] != d {
+					conflicts = append(conflicts, symb)
+				}
+			}
+			if len(conflicts) > 0 {
+				fmt.Printf("SFI conflict@[sfn=%v, slot=%v]: slot format %v(%v) of SFI-index %v is ignored for symbols %v, which are D/U by TDD-UL-DL-ConfigCommon or TDD-UL-DL-ConfigDedicated\n", sfn, slot, sf, nrgrid.SlotFormats[sf], sfi, conflicts)
+			}
+			break
+		}
+	}
+
+	rgd.tddPat[sfn] = pat
+	return pat
+}
+
+// getSfiSlotRatio returns the number of slots of the carrier per slot of the reference SCS of SlotFormatCombinationsPerCell, i.e. 2^(μ−μSFI).
+func getSfiSlotRatio() int {
+	if len(flags.tdduldl.sfcScs) == 0 {
+		return 1
+	}
+	return int(math.Exp2(float64(nrgrid.Scs2Mu[flags.gridsetting.scs] - nrgrid.Scs2Mu[flags.tdduldl.sfcScs])))
+}
+
+// checkSlotFormat returns whether all symbols in [firstSymb, firstSymb+numSymbs) of slot in radio frame sfn are of the given direction(D or U) according to the slot format, otherwise the allocation of the given channel(e.g. SPS PDSCH or CG PUSCH) is recorded as a slot format conflict.
+func checkSlotFormat(ch string, sfn, slot, firstSymb, numSymbs int, dir string) bool {
+	if isTddSymbs(sfn, slot, firstSymb, numSymbs, dir) {
+		return true
+	}
+
+	k := slot*rgd.symbPerSlot + firstSymb
+	rgd.sfConflicts = append(rgd.sfConflicts, fmt.Sprintf("%v@[%v,%v,S=%v,L=%v](%v)", ch, sfn, slot, firstSymb, numSymbs, strings.Join(getTddPat(sfn)[k:k+numSymbs], "")))
+	return false
+}
+
+// schedCgSps activates DL SPS and configured grant Type 2 by DCI 1_1/0_1 with CS-RNTI, or configures configured grant Type 1, and maps SPS PDSCH and configured grant PUSCH for advanced.numSchedRfs radio frames, starting from the slot next to slot of radio frame sfn, and returns number of SPS PDSCH and number of configured grant PUSCH.
+//  sfn: radio frame of the last step of random access procedure
+//  slot: slot of the last step of random access procedure
+func schedCgSps(sfn, slot int) (int, int, error) {
+	iss := utils.IndexStr(flags.searchspace._ssType, "uss")
+	if iss < 0 || flags.searchspace._ssCoresetId[iss] != 1 {
+		return -1, -1, errors.New(fmt.Sprintf("USS must be configured in CORESET1!"))
+	}
+
+	k0 := flags.dldci._tdK0[DCI_11_PDSCH]
+	k1 := flags.dldci.tdK1 + 1
+	k2 := flags.uldci._tdK2[DCI_01_PUSCH]
+	numSlotsPdsch, _ := strconv.Atoi(flags.pdsch._pdschAggFactor[1:])
+
+	// init always-on-transmission of all radio frames involved so that periodic CSI-RS/SRS are mapped before PDSCH/PUSCH
+	// Note: SPS PDSCH is in slots of the DL carrier for PDSCH and configured grant PUSCH is in slots of the UL carrier, which are of the supplementary carrier if SDL or SUL is used.
+	ulSlotPerRf, _, _ := getUlDims()
+	pdschSlotPerRf, _, _ := getPdschDims()
+	kd := utils.CeilInt(float64((k0+numSlotsPdsch)*rgd.slotPerRf)/float64(pdschSlotPerRf)) - 1 + utils.CeilInt(float64(k1*rgd.slotPerRf)/float64(ulSlotPerRf))
+	ku := utils.CeilInt(float64(k2*rgd.slotPerRf) / float64(ulSlotPerRf))
+	n0 := sfn*rgd.slotPerRf + slot
+	n1 := n0 + flags.advanced.numSchedRfs*rgd.slotPerRf
+	for f := sfn; f <= (n1+utils.MaxInt([]int{kd, ku}))/rgd.slotPerRf; f++ {
+		if err := alwaysOnTr(f, 0); err != nil {
+			return -1, -1, err
+		}
+	}
+
+	numSps, numCg := 0, 0
+	if flags.cgsps.spsEnabled {
+		S := flags.dldci._tdStartSymb[DCI_11_PDSCH]
+		L := flags.dldci._tdNumSymbs[DCI_11_PDSCH]
+		r, err := getDedPucchRes(1)
+		if err != nil {
+			return -1, -1, err
+		}
+
+		// DCI 1_1 activating DL SPS is sent only if all symbols of PDSCH are DL and all symbols of the PUCCH for HARQ-ACK are UL
+		nAct, err := activateCgSps(n0, n1, iss, "DCI 1_1", func(n int) bool {
+			md := getOverlapSlot(n, rgd.slotPerRf, pdschSlotPerRf, false) + k0
+			mh := getOverlapSlot(getOverlapSlot(md+numSlotsPdsch-1, pdschSlotPerRf, rgd.slotPerRf, true), rgd.slotPerRf, ulSlotPerRf, true) + k1
+			return isTddSymbs(md/pdschSlotPerRf, md%pdschSlotPerRf, S, L, "D") && isTddSymbs(mh/ulSlotPerRf, mh%ulSlotPerRf, flags.pucch._pucchStartSymb[r], flags.pucch._pucchNumSymbs[r], "U")
+		})
+		if err != nil {
+			return -1, -1, err
+		}
+
+		if nAct < 0 {
+			fmt.Printf("DL SPS is not activated due to no available PDCCH candidate.\n")
+		} else {
+			periodicity, _ := strconv.Atoi(flags.cgsps.spsPeriodicity[2:])
+			// [sfn, slot] of SPS PDSCH dropped due to non-DL symbols
+			var dropped []string
+			// refer to 3GPP 38.321 vh40
+			// 5.8.1	Downlink
+			// After a downlink assignment is configured for semi-persistent scheduling, the MAC entity shall consider sequentially that the Nth downlink assignment occurs in the slot for which:
+			// (numberOfSlotsPerFrame x SFN + slot number in the frame) = [(numberOfSlotsPerFrame x SFN_start_time + slot_start_time) + N x periodicity x numberOfSlotsPerFrame / 10] modulo (1024 x numberOfSlotsPerFrame)
+			// where SFN_start_time and slot_start_time are the SFN and slot, respectively, of the first transmission of PDSCH where configured downlink assignment was (re-)initialised.
+			// Note: numberOfSlotsPerFrame is of the DL carrier for PDSCH, and the SPS PDSCH is scheduled up to the last slot of the DL carrier for PDSCH which overlaps with slot n1.
+			nStart := getOverlapSlot(nAct, rgd.slotPerRf, pdschSlotPerRf, false) + k0
+			nEnd := getOverlapSlot(n1, rgd.slotPerRf, pdschSlotPerRf, true)
+			for N := 0; nStart+N*periodicity*pdschSlotPerRf/10 <= nEnd; N++ {
+				n := nStart + N*periodicity*pdschSlotPerRf/10
+				sfnd := n / pdschSlotPerRf
+				nd := n % pdschSlotPerRf
+				if !checkSlotFormat("SPS PDSCH", sfnd, nd, S, L, "D") {
+					dropped = append(dropped, fmt.Sprintf("[%v,%v]", sfnd, nd))
+					continue
+				}
+
+				// refer to 3GPP 38.133 vh40
+				// 9.1.2	Measurement gap
+				// Note: SPS PDSCH which overlaps with measurement gaps is not received, and the transmission opportunity is regarded as lost.
+				if isMeasGap(n, numSlotsPdsch, S, L, rgd.symbPerSlot, pdschSlotPerRf/rgd.subfPerRf) {
+					rgd.measGapLost = append(rgd.measGapLost, fmt.Sprintf("SPS PDSCH@[%v,%v]", sfnd, nd))
+					continue
+				}
+
+				// refer to 3GPP 38.321 vh40
+				// 5.3.1	DL Assignment reception
+				// For configured downlink assignments without harq-ProcID-Offset, the HARQ Process ID associated with the slot where the DL transmission starts is derived from the following equation:
+				// HARQ Process ID = [floor (CURRENT_slot x 10 / (numberOfSlotsPerFrame x periodicity))] modulo nrofHARQ-Processes
+				// For configured downlink assignments with harq-ProcID-Offset, ... HARQ Process ID = [floor (CURRENT_slot / periodicity)] modulo nrofHARQ-Processes + harq-ProcID-Offset
+				// where CURRENT_slot = [(SFN x numberOfSlotsPerFrame) + slot number in the frame]
+				// Note: both equations are equivalent when periodicity of the latter is in number of slots, i.e. periodicity x numberOfSlotsPerFrame / 10.
+				curSlot := n % (1024 * pdschSlotPerRf)
+				hpid := (curSlot*10/(pdschSlotPerRf*periodicity))%flags.cgsps.spsNumHarqProc + flags.cgsps.spsHarqProcIdOffset
+
+				// the first SPS PDSCH is scheduled by the activation DCI 1_1, whose HARQ-ACK is reported in the PUCCH resource indicated by the DCI
+				rnti, pucchResSet := "SPS", "sps"
+				sfnc, nc := (n-k0)/pdschSlotPerRf, (n-k0)%pdschSlotPerRf
+				if N == 0 {
+					rnti, pucchResSet = "CS-RNTI", "dedicated"
+					sfnc, nc = nAct/rgd.slotPerRf, nAct%rgd.slotPerRf
+				}
+				sfnl, nl, err := recvPdsch(sfnc, nc, rnti)
+				if err != nil {
+					return -1, -1, err
+				}
+				grid := getPdschGrid(sfnd)
+				grid.tags[nd].Add("SPS")
+
+				sfnh, nh, err := sendPucch(sfnl, nl, true, false, false, pucchResSet)
+				if err != nil {
+					return -1, -1, err
+				}
+				fmt.Printf("DL SPS: N=%v, PDSCH@[sfn=%v, slot=%v], HARQ process ID=%v, HARQ-ACK@[sfn=%v, slot=%v]\n", N, sfnd, nd, hpid, sfnh, nh)
+				numSps++
+			}
+
+			if len(dropped) > 0 {
+				fmt.Printf("SPS PDSCH dropped due to non-DL symbols: %v\n", dropped)
+			}
+		}
+	}
+
+	if flags.cgsps.cgEnabled {
+		S := flags.uldci._tdStartSymb[DCI_01_PUSCH]
+		L := flags.uldci._tdNumSymbs[DCI_01_PUSCH]
+		P, err := getCgPeriodicity()
+		if err != nil {
+			return -1, -1, err
+		}
+		repK, _ := strconv.Atoi(flags.cgsps.cgRepK[1:])
+		rvSeq := map[string][]int{"s1-0231": {0, 2, 3, 1}, "s2-0303": {0, 3, 0, 3}, "s3-0000": {0, 0, 0, 0}}[flags.cgsps.cgRepKRv]
+
+		// the first symbol of configured grant PUSCH, and the slot of DCI 0_1 activating configured grant Type 2
+		start, nAct := -1, -1
+		if flags.cgsps.cgType == "type1" {
+			// refer to 3GPP 38.321 vh40
+			// 5.8.2	Uplink
+			// After an uplink grant is configured for a configured grant Type 1, the MAC entity shall consider sequentially that the Nth (N >= 0) uplink grant occurs in the symbol for which:
+			// [(SFN x numberOfSlotsPerFrame x numberOfSymbolsPerSlot) + (slot number in the frame x numberOfSymbolsPerSlot) + symbol number in the slot] = (timeReferenceSFN x numberOfSlotsPerFrame x numberOfSymbolsPerSlot + timeDomainOffset x numberOfSymbolsPerSlot + S + N x periodicity) modulo (1024 x numberOfSlotsPerFrame x numberOfSymbolsPerSlot).
+			// Note: timeReferenceSFN is assumed to be 0, and the uplink grants before the end of random access procedure are not used.
+			// Note: numberOfSlotsPerFrame is of the UL carrier, and the uplink grants start after the last slot of the UL carrier which overlaps with slot n0.
+			start = flags.cgsps.cgTimeDomainOffset*rgd.symbPerSlot + S
+			n0u := getOverlapSlot(n0, rgd.slotPerRf, ulSlotPerRf, true)
+			if start < (n0u+1)*rgd.symbPerSlot {
+				start += ((n0u+1)*rgd.symbPerSlot - start + P - 1) / P * P
+			}
+		} else {
+			// DCI 0_1 activating configured grant Type 2 is sent only if all symbols of PUSCH are UL
+			nAct, err = activateCgSps(n0, n1, iss, "DCI 0_1", func(n int) bool {
+				mu := getOverlapSlot(n, rgd.slotPerRf, ulSlotPerRf, false) + k2
+				return isTddSymbs(mu/ulSlotPerRf, mu%ulSlotPerRf, S, L, "U")
+			})
+			if err != nil {
+				return -1, -1, err
+			}
+
+			if nAct < 0 {
+				fmt.Printf("Configured grant Type 2 is not activated due to no available PDCCH candidate.\n")
+			} else {
+				// refer to 3GPP 38.321 vh40
+				// 5.8.2	Uplink
+				// After an uplink grant is configured for a configured grant Type 2, the MAC entity shall consider sequentially that the Nth (N >= 0) uplink grant occurs in the symbol for which:
+				// [(SFN x numberOfSlotsPerFrame x numberOfSymbolsPerSlot) + (slot number in the frame x numberOfSymbolsPerSlot) + symbol number in the slot] = [(SFN_start_time x numberOfSlotsPerFrame x numberOfSymbolsPerSlot + slot_start_time x numberOfSymbolsPerSlot + symbol_start_time) + N x periodicity] modulo (1024 x numberOfSlotsPerFrame x numberOfSymbolsPerSlot).
+				// where SFN_start_time, slot_start_time, and symbol_start_time are the SFN, slot, and symbol, respectively, of the first transmission opportunity of PUSCH where the configured uplink grant was (re-)initialised.
+				start = (getOverlapSlot(nAct, rgd.slotPerRf, ulSlotPerRf, false)+k2)*rgd.symbPerSlot + S
+			}
+		}
+
+		n1u := getOverlapSlot(n1, rgd.slotPerRf, ulSlotPerRf, true)
+		for N := 0; start >= 0 && (start+N*P)/rgd.symbPerSlot <= n1u; N++ {
+			n := (start + N*P) / rgd.symbPerSlot
+			Sn := (start + N*P) % rgd.symbPerSlot
+			// refer to 3GPP 38.321 vh40
+			// 5.4.1	UL Grant reception
+			// For configured uplink grants without harq-ProcID-Offset2, the HARQ Process ID associated with the first symbol of a UL transmission is derived from the following equation:
+			// HARQ Process ID = [floor(CURRENT_symbol / periodicity)] modulo nrofHARQ-Processes
+			// For configured uplink grants with harq-ProcID-Offset2, ... HARQ Process ID = [floor(CURRENT_symbol / periodicity)] modulo nrofHARQ-Processes + harq-ProcID-Offset2
+			// where CURRENT_symbol = (SFN x numberOfSlotsPerFrame x numberOfSymbolsPerSlot + slot number in the frame x numberOfSymbolsPerSlot + symbol number in the slot)
+			curSymb := (start + N*P) % (1024 * ulSlotPerRf * rgd.symbPerSlot)
+			hpid := (curSymb/P)%flags.cgsps.cgNumHarqProc + flags.cgsps.cgHarqProcIdOffset2
+
+			// refer to 3GPP 38.214 vh40
+			// 6.1.2.3	Resource allocation for uplink transmission with configured grant
+			// For PUSCH transmissions of a Type 1 or Type 2 configured grant, the number of repetitions K to be applied to the transmitted transport block is provided by ... repK
+			// 6.1.2.3.1	Transport block repetition for uplink transmissions of PUSCH repetition Type A with a configured grant
+			// The higher layer configured parameters repK and repK-RV define the K repetitions to be applied to the transmitted transport block, and the redundancy version pattern to be applied to the repetitions. ... For the n-th transmission occasion among K repetitions, n = 1, 2, ..., K, it is associated with (mod(n-1,4)+1)th value in the configured RV sequence.
+			// Note: for unpaired spectrum, the repetition in a slot is omitted if any symbol of the PUSCH in the slot is not UL.
+			// Note: for periodicity of less than one slot, the transmission occasion is omitted if the PUSCH crosses the slot boundary.
+			var txs []string
+			for k := 0; k < repK; k++ {
+				sfnu := (n + k) / ulSlotPerRf
+				nu := (n + k) % ulSlotPerRf
+				if Sn+L > rgd.symbPerSlot || !checkSlotFormat("CG PUSCH", sfnu, nu, Sn, L, "U") {
+					txs = append(txs, fmt.Sprintf("[%v,%v,%v](omitted)", sfnu, nu, Sn))
+					continue
+				}
+
+				// refer to 3GPP 38.213 vh40
+				// 9.2.6	PUCCH repetition procedure
+				// ... the UE transmits the PUCCH and does not transmit the PUSCH in the overlapping slots.
+				if hasPucchRep(n+k, 1) {
+					txs = append(txs, fmt.Sprintf("[%v,%v,%v](PUCCH repetitions)", sfnu, nu, Sn))
+					continue
+				}
+
+				// Note: configured grant PUSCH which overlaps with measurement gaps is not transmitted, and the transmission opportunity is regarded as lost.
+				if isMeasGap(n+k, 1, Sn, L, rgd.symbPerSlot, ulSlotPerRf/rgd.subfPerRf) {
+					rgd.measGapLost = append(rgd.measGapLost, fmt.Sprintf("CG PUSCH@[%v,%v]", sfnu, nu))
+					txs = append(txs, fmt.Sprintf("[%v,%v,%v](MG)", sfnu, nu, Sn))
+					continue
+				}
+
+				// the first PUSCH of configured grant Type 2 is scheduled by the activation DCI 0_1
+				rnti := "CG"
+				sfnc, nc := (n+k-k2)/ulSlotPerRf, (n+k-k2)%ulSlotPerRf
+				if N == 0 && k == 0 && flags.cgsps.cgType == "type2" {
+					rnti = "CS-RNTI"
+					sfnc, nc = nAct/rgd.slotPerRf, nAct%rgd.slotPerRf
+				}
+				_, _, err = sendPusch(sfnc, nc, Sn, rnti)
+				if err != nil {
+					return -1, -1, err
+				}
+				grid := getUlGrid(sfnu)
+				grid.tags[nu].Add("CG")
+
+				rv := 0
+				if repK > 1 {
+					rv = rvSeq[k%4]
+				}
+				txs = append(txs, fmt.Sprintf("[%v,%v,%v](rv=%v)", sfnu, nu, Sn, rv))
+				numCg++
+			}
+			fmt.Printf("Configured grant(%v): N=%v, HARQ process ID=%v, repK=%v, PUSCH@%v\n", flags.cgsps.cgType, N, hpid, repK, strings.Join(txs, " "))
+		}
+	}
+
+	return numSps, numCg, nil
+}
+
+// activateCgSps monitors USS with CS-RNTI for DCI activating DL SPS or configured grant Type 2 in the slots after slot n0(=sfn*slotPerRf+slot) and up to slot n1, and returns the slot of the activation DCI, or -1 if no PDCCH candidate is available.
+//  iss: index of the USS
+//  dci: DCI format, which can be DCI 1_1 or DCI 0_1
+//  valid: returns whether the PDSCH/PUSCH scheduled by the DCI in slot n is valid
+func activateCgSps(n0, n1, iss int, dci string, valid func(n int) bool) (int, error) {
+	period, _ := strconv.Atoi(flags.searchspace._ssPeriodicity[iss][2:])
+	offset := flags.searchspace._ssSlotOffset[iss]
+	duration := flags.searchspace._ssDuration[iss]
+
+	for n := n0 + 1; n <= n1; n++ {
+		if ((n-offset)%period+period)%period >= duration || !valid(n) {
+			continue
+		}
+
+		// refer to 3GPP 38.213 vh40
+		// 10.2	PDCCH validation for DL SPS and UL grant Type 2
+		// A UE validates, for scheduling activation or scheduling release, a DL SPS assignment PDCCH or configured UL grant Type 2 PDCCH if the CRC of a corresponding DCI format is scrambled with a CS-RNTI provided by cs-RNTI and the new data indicator field in the DCI format for the enabled transport block is set to '0'
+		cces, err := monitorUssPdcch(n/rgd.slotPerRf, n%rgd.slotPerRf, iss, 0, dci, "CS-RNTI")
+		if err != nil {
+			return -1, err
+		}
+
+		if cces != nil {
+			return n, nil
+		}
+	}
+
+	return -1, nil
+}
+
+// updateBwpSw performs BWP switchings triggered by expiry of bwp-InactivityTimer, DCI or RRC at the beginning of slot n(=sfn*slotPerRf+slot).
+//  iss: index of the USS, in which the DCI 1_1/0_1 for BWP switching is monitored
+func updateBwpSw(bsw *BwpSwInfo, n, iss int) error {
+	// refer to 3GPP 38.321 vh40
+	// 5.15.1	Bandwidth Part operation
+	// 1> if the bwp-InactivityTimer associated with the active DL BWP expires: 2> if the defaultDownlinkBWP-Id is configured: 3> perform BWP switching to a BWP indicated by the defaultDownlinkBWP-Id.
+	if n == bsw.inactEnd {
+		bsw.inactEnd = -1
+		if err := switchBwp(bsw, 0, flags.bwp.defaultDlBwpId, n, "timer"); err != nil {
+			return err
+		}
+	}
+
+	for i, trigger := range flags.bwp.bwpSwTrigger {
+		if flags.bwp.bwpSwSfn[i]*rgd.slotPerRf+flags.bwp.bwpSwSlot[i] != n {
+			continue
+		}
+
+		k := utils.IndexStr([]string{"DL", "UL"}, flags.bwp.bwpSwDir[i])
+		if trigger == "dci" {
+			// refer to 3GPP 38.212 vh40
+			// 7.3.1.1.2/7.3.1.2.2	Format 0_1/1_1
+			// Bandwidth part indicator - 0, 1 or 2 bits ...
+			// Note: the DCI 1_1/0_1 for BWP switching is transmitted in the PDCCH monitoring occasion of USS on the DL BWP which can be used in slot n.
+			dci := []string{"DCI 1_1", "DCI 0_1"}[k]
+			period, _ := strconv.Atoi(flags.searchspace._ssPeriodicity[iss][2:])
+			offset, duration := flags.searchspace._ssSlotOffset[iss], flags.searchspace._ssDuration[iss]
+			if ((n-offset)%period+period)%period >= duration {
+				regYellow.Printf("BWP switching(%v, %v BWP%v)@[%v,%v] is ignored since it's not in PDCCH monitoring occasion of USS.\n", dci, flags.bwp.bwpSwDir[i], flags.bwp.bwpSwBwpId[i], n/rgd.slotPerRf, n%rgd.slotPerRf)
+				continue
+			}
+
+			cces, loc, err := monitorActDlBwpPdcch(bsw, n, iss, dci+", BWP switching")
+			if err != nil {
+				return err
+			}
+			if cces == nil {
+				regYellow.Printf("BWP switching(%v, %v BWP%v)@[%v,%v] is ignored due to no available PDCCH candidate.\n", dci, flags.bwp.bwpSwDir[i], flags.bwp.bwpSwBwpId[i], n/rgd.slotPerRf, n%rgd.slotPerRf)
+				continue
+			}
+			fmt.Printf("BWP switching(%v, %v BWP%v): %v\n", dci, flags.bwp.bwpSwDir[i], flags.bwp.bwpSwBwpId[i], loc)
+		}
+
+		if err := switchBwp(bsw, k, flags.bwp.bwpSwBwpId[i], n, trigger); err != nil {
+			return err
+		}
+	}
+
+	// refer to 3GPP 38.321 vh40
+	// 5.15.1	Bandwidth Part operation
+	// 1> if a PDCCH for BWP switching is received, and the MAC entity switches the active DL BWP: 2> if the defaultDownlinkBWP-Id is configured, and the MAC entity switches to a DL BWP which is not indicated by the defaultDownlinkBWP-Id: 3> start or restart the bwp-InactivityTimer associated with the active DL BWP.
+	// Note: bwp-InactivityTimer is started when the new active DL BWP can be used, i.e. after the BWP switch delay, and it's also started when the active DL BWP is switched by RRC to a DL BWP other than the default DL BWP.
+	if n == bsw.actFrom[0] {
+		restartBwpInactivityTimer(bsw, n)
+	}
+
+	return nil
+}
+
+// switchBwp switches the active DL(k=0) or UL(k=1) BWP to BWP id in slot n(=sfn*slotPerRf+slot), where trigger can be dci, rrc or timer(bwp-InactivityTimer).
+// For unpaired spectrum, the linked DL and UL BWPs with the same bwp-Id are switched together.
+func switchBwp(bsw *BwpSwInfo, k, id, n int, trigger string) error {
+	ks := []int{k}
+	if flags.gridsetting._duplexMode == "TDD" {
+		ks = []int{0, 1}
+	}
+
+	for _, k := range ks {
+		dir := []string{"DL", "UL"}[k]
+		if bsw.actBwp[k] == id {
+			continue
+		}
+
+		start, delay := n, 0
+		if trigger == "rrc" {
+			// refer to 3GPP 38.133 vh80
+			// 8.6.3	RRC based BWP switch delay
+			// For RRC-based BWP switch, after the UE receives BWP switching request at DL slot n on a serving cell, UE shall be able to receive PDSCH (for DL active BWP switch) or transmit PUSCH (for UL active BWP switch) on the new BWP on the serving cell on which BWP switch occurs on the first DL or UL slot occurring right after a time duration of T_RRCprocessingDelay + T_BWPswitchDelayRRC beginning of DL slot n.
+			// T_RRCprocessingDelay is the length of the RRC procedure delay in millisecond as defined in clause 12 in TS 38.331. T_BWPswitchDelayRRC = 6 ms is the time used by the UE to perform BWP switch.
+			// Note: T_RRCprocessingDelay of RRCReconfiguration is 10ms, and the previous active BWP can still be used during T_RRCprocessingDelay.
+			start = n + 10*rgd.slotPerSubf
+			delay = 6 * rgd.slotPerSubf
+		} else {
+			// refer to 3GPP 38.133 vh80
+			// 8.6.2	DCI and timer based BWP switch delay
+			// For DCI-based BWP switch, after the UE receives BWP switching request at DL slot n on a serving cell, UE shall be able to receive PDSCH (for DL active BWP switch) or transmit PUSCH (for UL active BWP switch) on the new BWP on the serving cell on which BWP switch occurs on the first DL or UL slot occurring right after a time duration of T_BWPswitchDelay beginning of DL slot n.
+			// For timer-based BWP switch, the UE shall start BWP switch at DL slot n, where n is the beginning of a DL subframe (FR1) or DL half-subframe (FR2) immediately after a BWP-inactivity timer bwp-InactivityTimer expires on a serving cell, and the UE shall be able to receive PDSCH (for DL active BWP switch) or transmit PUSCH (for UL active BWP switch) on the new BWP on the serving cell on which BWP switch occurs on the first DL or UL slot occurring right after a time duration of T_BWPswitchDelay beginning of DL slot n.
+			var err error
+			if delay, err = getBwpSwDelay(dir, bsw.actBwp[k], id); err != nil {
+				return err
+			}
+			if trigger == "timer" {
+				g := rgd.slotPerSubf
+				if flags.gridsetting._freqRange != "FR1" {
+					g = utils.MaxInt([]int{1, rgd.slotPerSubf / 2})
+				}
+				for start = (n + g - 1) / g * g; !isTddSymbs(start/rgd.slotPerRf, start%rgd.slotPerRf, 0, 1, "D"); start += g {
+				}
+			}
+		}
+
+		bsw.prevBwp[k], bsw.prevUntil[k] = bsw.actBwp[k], start
+		bsw.actBwp[k], bsw.actFrom[k] = id, start+delay
+		if err := markBwpSwGap(k, id, start, start+delay); err != nil {
+			return err
+		}
+
+		sw := fmt.Sprintf("%v BWP%v->BWP%v(%v)@[%v,%v]", dir, bsw.prevBwp[k], id, trigger, n/rgd.slotPerRf, n%rgd.slotPerRf)
+		bsw.history = append(bsw.history, sw)
+		fmt.Printf("BWP switching: %v, switch delay=[%v,%v]~[%v,%v](%v slots), BWP%v is active from [sfn=%v, slot=%v]\n", sw, start/rgd.slotPerRf, start%rgd.slotPerRf, (start+delay-1)/rgd.slotPerRf, (start+delay-1)%rgd.slotPerRf, delay, id, bsw.actFrom[k]/rgd.slotPerRf, bsw.actFrom[k]%rgd.slotPerRf)
+	}
+
+	return nil
+}
+
+// getBwpSwDelay returns T_BWPswitchDelay in slots of the carrier for DCI or timer based switching from BWP id0 to BWP id1 in the given link direction(DL or UL).
+func getBwpSwDelay(dir string, id0, id1 int) (int, error) {
+	// refer to 3GPP 38.133 vh80
+	// 8.6.2	DCI and timer based BWP switch delay
+	// Table 8.6.2-1: BWP switch delay
+	//  u	NR Slot length (ms)	BWP switch delay T_BWPswitchDelay (slots) Type 1, Type 2
+	//  0	1	1	3
+	//  1	0.5	2	5
+	//  2	0.25	3	9
+	//  3	0.125	6	18
+	// If the BWP switch involves changing of SCS, the BWP switch delay is determined by the larger one between the SCS before BWP switch and the SCS after BWP switch.
+	// Note: T_BWPswitchDelay is not defined for u>3(i.e. 480KHz and 960KHz SCS of FR2-2).
+	u := utils.MaxInt([]int{getBwpMu(dir, id0), getBwpMu(dir, id1)})
+	delays, exist := map[string][]int{"type1": {1, 2, 3, 6}, "type2": {3, 5, 9, 18}}[flags.bwp.bwpSwDelayType]
+	if !exist {
+		return -1, errors.New(fmt.Sprintf("Invalid bwpSwDelayType(=%v), which can be type1 or type2!", flags.bwp.bwpSwDelayType))
+	}
+	if u < 0 || u >= len(delays) {
+		return -1, errors.New(fmt.Sprintf("T_BWPswitchDelay is not defined for u=%v when switching %v BWP%v to BWP%v, which must be within [0, %v]!", u, dir, id0, id1, len(delays)-1))
+	}
+
+	return utils.CeilInt(float64(delays[u]*rgd.slotPerSubf) / math.Exp2(float64(u))), nil
+}
+
+// getBwpMu returns the numerology of the dedicated(bwp-Id 1) or additional dedicated DL/UL BWP.
+func getBwpMu(dir string, id int) int {
+	if id == 1 {
+		return nrgrid.Scs2Mu[flags.bwp._bwpScs[map[string]int{"DL": DED_DL_BWP, "UL": DED_UL_BWP}[dir]]]
+	}
+
+	return int(math.Log2(float64(rgd.bwps[getBwpIndex(dir, id)].slotPerRf / rgd.subfPerRf)))
+}
+
+// markBwpSwGap marks the free REs of DL(k=0) or UL(k=1) BWP id in slots [n0, n1) of the carrier as BWP-SW, during which the UE is not required to receive DL or transmit UL due to BWP switching.
+func markBwpSwGap(k, id, n0, n1 int) error {
+	free := []int{NR_RES_D, NR_RES_U}[k]
+	if id == 1 {
+		for n := n0; n < n1; n++ {
+			sfn, slot := n/rgd.slotPerRf, n%rgd.slotPerRf
+			if err := aotCommon(sfn); err != nil {
+				return err
+			}
+
+			grid := getPdschGrid(sfn)
+			rb0, numRbs := getPdschBwp()
+			if k == 1 {
+				grid = getUlGrid(sfn)
+				rb0, numRbs = getUlBwp(DED_UL_BWP)
+			}
+			for ire := slot*rgd.scPerSlot + rb0*rgd.scPerRb; ire < (slot+1)*rgd.scPerSlot; ire++ {
+				if ire%rgd.scPerSymb < (rb0+numRbs)*rgd.scPerRb && ire%rgd.scPerSymb >= rb0*rgd.scPerRb && grid.res[ire] == free {
+					grid.res[ire] = NR_RES_BWP_SW
+				}
+			}
+			if grid.tags[slot] == nil {
+				grid.tags[slot] = mapset.NewSet()
+			}
+			grid.tags[slot].Add("BWP-SW")
+		}
+
+		return nil
+	}
+
+	j := getBwpIndex([]string{"DL", "UL"}[k], id)
+	bd := rgd.bwps[j]
+	for m := n0 * bd.slotPerRf / rgd.slotPerRf; m < utils.CeilInt(float64(n1*bd.slotPerRf)/float64(rgd.slotPerRf)); m++ {
+		grid := getBwpGrid(j, m/bd.slotPerRf)
+		slot := m % bd.slotPerRf
+		for ire := slot * bd.scPerSlot; ire < (slot+1)*bd.scPerSlot; ire++ {
+			if grid.res[ire] == free {
+				grid.res[ire] = NR_RES_BWP_SW
+			}
+		}
+		if grid.tags[slot] == nil {
+			grid.tags[slot] = mapset.NewSet()
+		}
+		grid.tags[slot].Add("BWP-SW")
+	}
+
+	return nil
+}
+
+// getActBwp returns the bwp-Id of the active DL(k=0) or UL(k=1) BWP which can be used in slot n(=sfn*slotPerRf+slot) of the carrier, which is -1 if no BWP can be used, e.g. during BWP switching delay.
+func getActBwp(bsw *BwpSwInfo, k, n int) int {
+	switch {
+	case bsw == nil:
+		return 1
+	case n < bsw.prevUntil[k]:
+		return bsw.prevBwp[k]
+	case n >= bsw.actFrom[k]:
+		return bsw.actBwp[k]
+	default:
+		return -1
+	}
+}
+
+// isBwpActive returns whether BWP id is the active DL(k=0) or UL(k=1) BWP which can be used in slot n(=sfn*slotPerRf+slot) of the carrier, where the dedicated BWP(bwp-Id 1) is always active if BWP switching is not configured(bsw=nil).
+func isBwpActive(bsw *BwpSwInfo, k, id, n int) bool {
+	if bsw == nil {
+		return id == 1
+	}
+
+	return (bsw.actBwp[k] == id && n >= bsw.actFrom[k]) || (bsw.prevBwp[k] == id && n < bsw.prevUntil[k])
+}
+
+// restartBwpInactivityTimer starts or restarts bwp-InactivityTimer in slot n(=sfn*slotPerRf+slot) if bwp-InactivityTimer is configured and the active DL BWP, which is not the default DL BWP, can be used.
+func restartBwpInactivityTimer(bsw *BwpSwInfo, n int) {
+	// refer to 3GPP 38.321 vh40
+	// 5.15.1	Bandwidth Part operation
+	// 1> if the defaultDownlinkBWP-Id is configured, and the active DL BWP is not the BWP indicated by the defaultDownlinkBWP-Id ...: 2> if a PDCCH addressed to C-RNTI or CS-RNTI indicating downlink assignment or uplink grant is received on the active BWP: 3> start or restart the bwp-InactivityTimer associated with the active DL BWP.
+	if len(flags.bwp.bwpInactivityTimer) == 0 || bsw.actBwp[0] == flags.bwp.defaultDlBwpId || n < bsw.actFrom[0] {
+		return
+	}
+
+	timer, _ := strconv.Atoi(flags.bwp.bwpInactivityTimer[2:])
+	bsw.inactEnd = n + timer*rgd.slotPerSubf
+}
+
+// schedBwp schedules PDSCH by DCI 1_1(k=0) or PUSCH by DCI 0_1(k=1) with C-RNTI on the active additional dedicated DL/UL BWP with PDCCH monitoring occasion in slot n(=sfn*slotPerRf+slot) of the carrier, and returns number of PDSCH or PUSCH scheduled, and PDSCH/PUSCH which are not scheduled due to PDCCH blocking.
+// Note: the PDCCH monitoring occasions of additional dedicated BWPs are assumed to be the same as USS of the dedicated DL BWP, and the PDCCH is mapped on the DL BWP which can be used in slot n.
+// Note: HARQ-ACK of PDSCH on additional dedicated DL BWP is reported on the dedicated PUCCH resource of the active UL BWP.
+//  iss: index of the USS
+func schedBwp(bsw *BwpSwInfo, k, n, iss int) (int, []string, error) {
+	// the previous active BWP can still be used before the BWP switching starts, e.g. during T_RRCprocessingDelay of RRC based BWP switching
+	dir := []string{"DL", "UL"}[k]
+	id := bsw.actBwp[k]
+	if n < bsw.prevUntil[k] {
+		id = bsw.prevBwp[k]
+	}
+	j := getBwpIndex(dir, id)
+	if j < 0 || !isBwpActive(bsw, k, id, n) {
+		return 0, nil, nil
+	}
+	bd := rgd.bwps[j]
+
+	sch, dci := "PDSCH", "DCI 1_1"
+	koff, S, L := flags.dldci._tdK0[DCI_11_PDSCH], flags.dldci._tdStartSymb[DCI_11_PDSCH], flags.dldci._tdNumSymbs[DCI_11_PDSCH]
+	if k == 1 {
+		sch, dci = "PUSCH", "DCI 0_1"
+		koff, S, L = flags.uldci._tdK2[DCI_01_PUSCH], flags.uldci._tdStartSymb[DCI_01_PUSCH], flags.uldci._tdNumSymbs[DCI_01_PUSCH]
+	}
+
+	// refer to 3GPP 38.214 vh40
+	// 5.1.2.1	Resource allocation in time domain
+	// The slot allocated for the PDSCH is K_s = floor(n*2^u_PDSCH/2^u_PDCCH) + K0 ...
+	// 6.1.2.1	Resource allocation in time domain
+	// The slot where the UE shall transmit the PUSCH is determined by K2 as K_s = floor(n*2^u_PUSCH/2^u_PDCCH) + K2 ...
+	// Note: u_PDCCH is the numerology of the carrier.
+	// Note: the BWP must be active in both the first and the last slot of the carrier overlapping with the PDSCH/PUSCH.
+	m := n*bd.slotPerRf/rgd.slotPerRf + koff
+	mh := ((m+1)*rgd.slotPerRf+bd.slotPerRf-1)/bd.slotPerRf - 1
+	grid := getBwpGrid(j, m/bd.slotPerRf)
+	if tags := grid.tags[m%bd.slotPerRf]; (tags != nil && tags.Contains(sch)) || !isBwpSymbs(j, m, S, L, map[string]string{"DL": "D", "UL": "U"}[dir]) || !isBwpActive(bsw, k, bd.id, m*rgd.slotPerRf/bd.slotPerRf) || !isBwpActive(bsw, k, bd.id, mh) {
+		return 0, nil, nil
+	}
+
+	// refer to 3GPP 38.213 vh40
+	// 9.2.3	UE procedure for reporting HARQ-ACK
+	// For a PDSCH reception ending in slot n, the UE provides corresponding HARQ-ACK information in a PUCCH transmission within slot n + k, where ... slot n is the last UL slot overlapping with the PDSCH reception in case the numerologies of the PDSCH and the PUCCH are different.
+	harq := k == 0
+	gapHarq := false
+	if harq {
+		r, err := getDedPucchRes(1)
+		if err != nil {
+			return -1, nil, err
+		}
+		jh, nh := getHarqAckSlot(bsw, mh)
+		if nh < 0 || !isPucchSymbs(jh, nh, r) {
+			return 0, nil, nil
+		}
+		gapHarq = isPucchMeasGap(jh, nh, r)
+	}
+
+	// Note: PDSCH/PUSCH is not scheduled if any of the PDCCH monitoring occasion, the PDSCH/PUSCH and the PUCCH for HARQ-ACK overlaps with measurement gaps.
+	if isMeasGapMo(n) || isMeasGap(m, 1, S, L, bd.symbPerSlot, bd.slotPerRf/rgd.subfPerRf) || gapHarq {
+		rgd.measGapLost = append(rgd.measGapLost, fmt.Sprintf("%v@%v BWP%v[%v,%v]", sch, dir, bd.id, m/bd.slotPerRf, m%bd.slotPerRf))
+		return 0, nil, nil
+	}
+
+	cces, loc, err := monitorActDlBwpPdcch(bsw, n, iss, dci)
+	if err != nil {
+		return -1, nil, err
+	}
+	if cces == nil {
+		return 0, []string{fmt.Sprintf("%v@%v BWP%v[%v,%v]", sch, dir, bd.id, m/bd.slotPerRf, m%bd.slotPerRf)}, nil
+	}
+
+	// Note: HARQ-ACK on PUCCH in the same slot of the UL BWP is multiplexed on the PUSCH, and the PUCCH is not transmitted.
+	if tr, exist := bd.pucchTr[m]; exist && k == 1 && !tr.onPusch {
+		for _, ire := range tr.res[m] {
+			grid.res[ire] = NR_RES_U
+		}
+		tr.res[m] = nil
+		muxUciOnPusch(tr, fmt.Sprintf("UL BWP%v[sfn=%v, slot=%v]", bd.id, m/bd.slotPerRf, m%bd.slotPerRf))
+	}
+
+	dmrs, numDataRes, numDmrsRes, collisions := mapSch(grid, sch, m%bd.slotPerRf, bd.scPerSymb, bd.scPerSlot, bd.startRb, bd.numRbs, bd.cp)
+	tbs, err := getSchTbs(sch, bd.numRbs)
+	if err != nil {
+		return -1, nil, err
+	}
+	fmt.Printf("%v(%v, C-RNTI) on %v BWP%v(scs=%vKHz): %v, %v@[sfn=%v, slot=%v, S=%v, L=%v], rbStart=%v, numPrbs=%v, dmrs=%v, TBS=%v bits, REs of %v=%v, REs of DMRS=%v, collisions=%v\n", sch, dci, dir, bd.id, 15*bd.slotPerRf/rgd.subfPerRf, loc, sch, m/bd.slotPerRf, m%bd.slotPerRf, S, L, bd.startRb, bd.numRbs, dmrs, tbs, sch, numDataRes, numDmrsRes, collisions)
+
+	if harq {
+		sfnh, nh, err := sendPucch(mh/rgd.slotPerRf, mh%rgd.slotPerRf, true, false, false, "dedicated")
+		if err != nil {
+			return -1, nil, err
+		}
+		fmt.Printf("HARQ-ACK: PDSCH@DL BWP%v[sfn=%v, slot=%v] -> PUCCH@UL BWP%v[sfn=%v, slot=%v]\n", bd.id, m/bd.slotPerRf, m%bd.slotPerRf, getActBwp(bsw, 1, mh+flags.dldci.tdK1+1), sfnh, nh)
+	}
+
+	return 1, nil, nil
+}
+
+// schedScell schedules PDSCH by DCI 1_1 and PUSCH by DCI 0_1 with C-RNTI on SCell i in the PDCCH monitoring occasion of USS in slot of radio frame sfn of the PCell, and returns number of PDSCH and number of PUSCH scheduled, and PDSCH/PUSCH which are not scheduled due to PDCCH blocking.
+// Note: for SCell with cross-carrier scheduling, the PDCCH is transmitted in USS of the PCell with carrier indicator field. For self-scheduled SCell, the PDCCH is transmitted in USS of the SCell, whose CORESET has the same configuration as CORESET1 of the PCell, and the PDCCH monitoring occasions of the SCell are assumed to be aligned with those of the PCell.
+// Note: the PDSCH-Config/PUSCH-Config, time domain resource allocation and MCS of the PCell are assumed for SCells, and PDSCH/PUSCH occupy all RBs of the SCell. SSB and CSI-RS are not transmitted on SCells.
+func schedScell(i, sfn, slot, iss int) (int, int, []string, error) {
+	sd := rgd.scells[i]
+	cif := flags.ca.scellCif[i]
+	k0 := flags.dldci._tdK0[DCI_11_PDSCH]
+	k2 := flags.uldci._tdK2[DCI_01_PUSCH]
+	r, err := getDedPucchRes(1)
+	if err != nil {
+		return -1, -1, nil, err
+	}
+
+	// refer to 3GPP 38.214 vh40
+	// 5.1.2.1	Resource allocation in time domain
+	// The slot allocated for the PDSCH is K_s = floor(n*2^u_PDSCH/2^u_PDCCH) + K0 ..., where n is the slot with the scheduling DCI, and K0 is based on the numerology of PDSCH
+	// 6.1.2.1	Resource allocation in time domain
+	// The slot where the UE shall transmit the PUSCH is determined by K2 as K_s = floor(n*2^u_PUSCH/2^u_PDCCH) + K2 ..., where n is the slot with the scheduling DCI, and K2 is based on the numerology of PUSCH
+	// Note: ca-SlotOffset is not supported.
+	n := sfn*rgd.slotPerRf + slot
+	ns := n * sd.slotPerRf / rgd.slotPerRf
+
+	numPdsch, numPusch := 0, 0
+	var blocked []string
+
+	// refer to 3GPP 38.213 vh40
+	// 9	UE procedures for reporting control information
+	// If a UE is not provided PDCCH-ConfigSecondaryCell, ... PUCCH transmission is on the primary cell.
+	// 9.2.3	UE procedure for reporting HARQ-ACK
+	// For a PDSCH reception ending in slot n, the UE provides corresponding HARQ-ACK information in a PUCCH transmission within slot n + k, where ... slot n is the last UL slot overlapping with the PDSCH reception in case the numerologies of the PDSCH and the PUCCH are different.
+	// Note: HARQ-ACK of SCells is multiplexed with HARQ-ACK of the PCell in the same dedicated PUCCH resource.
+	md := ns + k0
+	mh := ((md+1)*rgd.slotPerRf+sd.slotPerRf-1)/sd.slotPerRf - 1
+	S, L := flags.dldci._tdStartSymb[DCI_11_PDSCH], flags.dldci._tdNumSymbs[DCI_11_PDSCH]
+	jh, nh := getHarqAckSlot(rgd.bsw, mh)
+	if !hasScellSlotTag(i, md, "D", "PDSCH") && isScellSymbs(i, md%sd.slotPerRf, S, L, "D") && nh >= 0 && isPucchSymbs(jh, nh, r) {
+		var src string
+		valid := true
+		// Note: measurement gaps apply to all serving cells, and the PDCCH monitoring occasion of the SCell is aligned with that of the PCell.
+		if isMeasGapMo(n) || isMeasGap(md, 1, S, L, rgd.symbPerSlot, sd.slotPerRf/rgd.subfPerRf) || isPucchMeasGap(jh, nh, r) {
+			rgd.measGapLost = append(rgd.measGapLost, fmt.Sprintf("SCell%v PDSCH@[%v,%v]", flags.ca.scellIndex[i], md/sd.slotPerRf, md%sd.slotPerRf))
+			valid = false
+		} else {
+			var cces []int
+			if cif > 0 {
+				cces, err = monitorUssPdcch(sfn, slot, iss, cif, fmt.Sprintf("DCI 1_1, CIF=%v", cif), "C-RNTI")
+				src = fmt.Sprintf("PDCCH@PCell[sfn=%v, slot=%v]", sfn, slot)
+			} else {
+				cces, err = monitorScellUssPdcch(i, ns, iss, "DCI 1_1")
+				src = fmt.Sprintf("PDCCH@SCell%v[sfn=%v, slot=%v]", flags.ca.scellIndex[i], ns/sd.slotPerRf, ns%sd.slotPerRf)
+			}
+			if err != nil {
+				return -1, -1, nil, err
+			}
+			if cces == nil {
+				blocked = append(blocked, fmt.Sprintf("SCell%v PDSCH@[%v,%v]", flags.ca.scellIndex[i], md/sd.slotPerRf, md%sd.slotPerRf))
+				valid = false
+			}
+		}
+
+		if valid {
+			dmrs, numDataRes, numDmrsRes, collisions := mapScellSch(i, "PDSCH", md)
+
+			tbs, err := getSchTbs("PDSCH", flags.ca._scellCarrierNumRbs[i])
+			if err != nil {
+				return -1, -1, nil, err
+			}
+			fmt.Printf("SCell%v PDSCH(DCI 1_1, C-RNTI): %v, PDSCH@[sfn=%v, slot=%v, S=%v, L=%v], numPrbs=%v, dmrs=%v, TBS=%v bits, REs of PDSCH=%v, REs of DMRS=%v, collisions=%v\n", flags.ca.scellIndex[i], src, md/sd.slotPerRf, md%sd.slotPerRf, S, L, flags.ca._scellCarrierNumRbs[i], dmrs, tbs, numDataRes, numDmrsRes, collisions)
+
+			sfnh, nh, err := sendPucch(mh/rgd.slotPerRf, mh%rgd.slotPerRf, true, false, false, "dedicated")
+			if err != nil {
+				return -1, -1, nil, err
+			}
+			fmt.Printf("SCell%v HARQ-ACK: PDSCH@SCell%v[sfn=%v, slot=%v] -> PUCCH@PCell[sfn=%v, slot=%v]\n", flags.ca.scellIndex[i], flags.ca.scellIndex[i], md/sd.slotPerRf, md%sd.slotPerRf, sfnh, nh)
+			numPdsch++
+		}
+	}
+
+	mu := ns + k2
+	S, L = flags.uldci._tdStartSymb[DCI_01_PUSCH], flags.uldci._tdNumSymbs[DCI_01_PUSCH]
+	if !hasScellSlotTag(i, mu, "U", "PUSCH") && isScellSymbs(i, mu%sd.slotPerRf, S, L, "U") {
+		var src string
+		valid := true
+		if isMeasGapMo(n) || isMeasGap(mu, 1, S, L, rgd.symbPerSlot, sd.slotPerRf/rgd.subfPerRf) {
+			rgd.measGapLost = append(rgd.measGapLost, fmt.Sprintf("SCell%v PUSCH@[%v,%v]", flags.ca.scellIndex[i], mu/sd.slotPerRf, mu%sd.slotPerRf))
+			valid = false
+		} else {
+			var cces []int
+			if cif > 0 {
+				cces, err = monitorUssPdcch(sfn, slot, iss, cif, fmt.Sprintf("DCI 0_1, CIF=%v", cif), "C-RNTI")
+				src = fmt.Sprintf("PDCCH@PCell[sfn=%v, slot=%v]", sfn, slot)
+			} else {
+				cces, err = monitorScellUssPdcch(i, ns, iss, "DCI 0_1")
+				src = fmt.Sprintf("PDCCH@SCell%v[sfn=%v, slot=%v]", flags.ca.scellIndex[i], ns/sd.slotPerRf, ns%sd.slotPerRf)
+			}
+			if err != nil {
+				return -1, -1, nil, err
+			}
+			if cces == nil {
+				blocked = append(blocked, fmt.Sprintf("SCell%v PUSCH@[%v,%v]", flags.ca.scellIndex[i], mu/sd.slotPerRf, mu%sd.slotPerRf))
+				valid = false
+			}
+		}
+
+		if valid {
+			dmrs, numDataRes, numDmrsRes, collisions := mapScellSch(i, "PUSCH", mu)
+
+			tbs, err := getSchTbs("PUSCH", flags.ca._scellCarrierNumRbs[i])
+			if err != nil {
+				return -1, -1, nil, err
+			}
+			fmt.Printf("SCell%v PUSCH(DCI 0_1, C-RNTI): %v, PUSCH@[sfn=%v, slot=%v, S=%v, L=%v], numPrbs=%v, dmrs=%v, TBS=%v bits, REs of PUSCH=%v, REs of DMRS=%v, collisions=%v\n", flags.ca.scellIndex[i], src, mu/sd.slotPerRf, mu%sd.slotPerRf, S, L, flags.ca._scellCarrierNumRbs[i], dmrs, tbs, numDataRes, numDmrsRes, collisions)
+			numPusch++
+		}
+	}
+
+	return numPdsch, numPusch, blocked, nil
+}
+
+// getSchTbs returns the TBS of PDSCH(sch=PDSCH) or PUSCH(sch=PUSCH) with the time domain resource allocation and MCS of DCI 1_1/0_1 and numRbs PRBs, which is -1 if MCS of DCI 1_1 is not set.
+func getSchTbs(sch string, numRbs int) (int, error) {
+	if sch == "PDSCH" {
+		if flags.dldci.mcsCw0[DCI_11_PDSCH] < 0 {
+			return -1, nil
+		}
+		dmrsOh := (2 * flags.pdsch._cdmGroupsWoData) * len(flags.pdsch._tdL) / flags.pdsch._numFrontLoadSymbs
+		xoh, _ := strconv.Atoi(flags.pdsch.pdschXOh[3:])
+		return getTbs("PDSCH", false, "C-RNTI", flags.pdsch.pdschMcsTable, flags.dldci._tdNumSymbs[DCI_11_PDSCH], numRbs, flags.dldci.mcsCw0[DCI_11_PDSCH], len(flags.pdsch._dmrsPorts), dmrsOh, xoh, 1)
+	}
+
+	dmrsOh := (2 * flags.pusch._cdmGroupsWoData) * len(flags.pusch._dmrsPosLBar)
+	xoh, _ := strconv.Atoi(flags.pusch.puschXOh[3:])
+	return getTbs("PUSCH", flags.pusch.puschTp == "enabled", "C-RNTI", flags.pusch.puschMcsTable, flags.uldci._tdNumSymbs[DCI_01_PUSCH], numRbs, flags.uldci.mcsCw0[DCI_01_PUSCH], len(flags.pusch._dmrsPorts), dmrsOh, xoh, 1)
+}
+
+// hasScellSlotTag returns whether slot m(=sfn*slotPerRf+slot) of SCell i in the given direction(D or U) has the given tag.
+func hasScellSlotTag(i, m int, dir, tag string) bool {
+	tags := getScellGrid(i, m/rgd.scells[i].slotPerRf, dir).tags[m%rgd.scells[i].slotPerRf]
+	return tags != nil && tags.Contains(tag)
+}
+
+// mapScellSch maps PDSCH(sch=PDSCH) or PUSCH(sch=PUSCH) and associated DMRS on all RBs of SCell i in slot m(=sfn*slotPerRf+slot), and returns DMRS symbols, number of REs of PDSCH/PUSCH, number of REs of DMRS and collisions.
+func mapScellSch(i int, sch string, m int) ([]int, int, int, map[string]int) {
+	sd := rgd.scells[i]
+	dir := map[string]string{"PDSCH": "D", "PUSCH": "U"}[sch]
+	grid := getScellGrid(i, m/sd.slotPerRf, dir)
+
+	return mapSch(grid, sch, m%sd.slotPerRf, sd.scPerSymb, sd.scPerSlot, 0, flags.ca._scellCarrierNumRbs[i], "normal")
+}
+
+// mapSch maps PDSCH(sch=PDSCH) or PUSCH(sch=PUSCH) scheduled by DCI 1_1/0_1 and associated DMRS on numRbs RBs starting from rb0 in slot of grid, and returns DMRS symbols, number of REs of PDSCH/PUSCH, number of REs of DMRS and collisions.
+//  scPerSymb/scPerSlot: number of subcarriers per symbol/slot of grid, which can be NR resource grid of SCell or additional dedicated BWP
+//  cp: cyclic prefix of grid, which can be normal or extended
+// Note: PTRS, PDSCH aggregation, PUSCH repetition and frequency hopping are not supported for SCells and additional dedicated BWPs.
+func mapSch(grid DataPerRf, sch string, slot, scPerSymb, scPerSlot, rb0, numRbs int, cp string) ([]int, int, int, map[string]int) {
+	S, L := flags.dldci._tdStartSymb[DCI_11_PDSCH], flags.dldci._tdNumSymbs[DCI_11_PDSCH]
+	mappingType := flags.dldci._tdMappingType[DCI_11_PDSCH]
+	dmrsType, tdL, fdK := flags.pdsch.pdschDmrsType, flags.pdsch._tdL, flags.pdsch._fdK
+	dmrsCdmGroups := getDmrsCdmGroups(dmrsType, flags.pdsch._dmrsPorts, 1000)
+	resFree, resData, resDmrs := NR_RES_D, NR_RES_PDSCH, NR_RES_DMRS_PDSCH
+	if sch == "PUSCH" {
+		S, L = flags.uldci._tdStartSymb[DCI_01_PUSCH], flags.uldci._tdNumSymbs[DCI_01_PUSCH]
+		mappingType = flags.uldci._tdMappingType[DCI_01_PUSCH]
+		dmrsType, tdL, fdK = flags.pusch.puschDmrsType, flags.pusch._tdL, flags.pusch._fdK
+		dmrsCdmGroups = getDmrsCdmGroups(dmrsType, flags.pusch._dmrsPorts, 0)
+		resFree, resData, resDmrs = NR_RES_U, NR_RES_PUSCH, NR_RES_DMRS_PUSCH
+	}
+
+	// Note: DMRS positions of extended cyclic prefix(Table 7.4.1.1.2-5 and Table 6.4.1.1.3-4 of 38.211) are different from those of the carrier.
+	if cp == "extended" {
+		if sch == "PDSCH" {
+			tdL, _ = getDmrsPdschTdFdPattern(dmrsType, mappingType, S, L, flags.pdsch._numFrontLoadSymbs, flags.pdsch.pdschDmrsAddPos, flags.pdsch._cdmGroupsWoData, cp)
+		} else {
+			tdL, _, _ = getDmrsPuschTdFdPattern(dmrsType, mappingType, S, L, flags.pusch._numFrontLoadSymbs, flags.pusch.puschDmrsAddPos, flags.pusch._cdmGroupsWoData, "disabled", cp)
+		}
+	}
+
+	// refer to 3GPP 38.211 vh40
+	// 6.4.1.1.3/7.4.1.1.2	Precoding and mapping to physical resources (DMRS for PUSCH/PDSCH)
+	// l is defined relative to the start of the slot if mapping type A, relative to the start of the scheduled PDSCH/PUSCH resources if mapping type B
+	var dmrs []int
+	for _, l := range tdL {
+		if mappingType == "typeA" {
+			dmrs = append(dmrs, l)
+		} else {
+			dmrs = append(dmrs, S+l)
+		}
+	}
+
+	numDataRes, numDmrsRes := 0, 0
+	collisions := make(map[string]int)
+	for symb := S; symb < S+L; symb++ {
+		isDmrs := utils.ContainsInt(dmrs, symb)
+		for k := rb0 * rgd.scPerRb; k < (rb0+numRbs)*rgd.scPerRb; k++ {
+			ire := slot*scPerSlot + symb*scPerSymb + k
+			// Note: PDSCH is rate matched around CSI-RS/CSI-IM(38.214 5.1.4), which are not counted as collisions.
+			if sch == "PDSCH" && isCsiRes(grid.res[ire]) {
+				continue
+			}
+			if grid.res[ire] != resFree {
+				collisions[resCategory(grid.res[ire])]++
+				continue
+			}
+
+			// Note: REs of CDM group(s) without data which are not used by the scheduled DMRS port(s) are DTX.
+			isc := k % rgd.scPerRb
+			if isDmrs && fdK[isc] == 1 {
+				if utils.ContainsInt(dmrsCdmGroups, getDmrsCdmGroup(dmrsType, isc)) {
+					grid.res[ire] = resDmrs
+					numDmrsRes++
+				} else {
+					grid.res[ire] = NR_RES_DTX
+				}
+			} else {
+				grid.res[ire] = resData
+				numDataRes++
+			}
+		}
+	}
+
+	if grid.tags[slot] == nil {
+		grid.tags[slot] = mapset.NewSet()
+	}
+	grid.tags[slot].Add(sch)
+
+	return dmrs, numDataRes, numDmrsRes, collisions
+}
+
+// monitorUssPdcch maps the first PDCCH candidate of USS in CORESET1 which doesn't collide with other channels in slot of radio frame sfn, and returns CCEs of the PDCCH candidate, which is nil if no PDCCH candidate is available.
+//  iss: index of the USS
+//  nCI: carrier indicator field value, which is cif-InSchedulingCell of the scheduled SCell for cross-carrier scheduling, or 0 otherwise
+//  dci: DCI format, which is used for printing only
+//  rnti: RNTI scrambling CRC of the DCI, which can be C-RNTI or CS-RNTI and is used for printing only, since Y_p,n_s_f_u of USS is always determined by C-RNTI
+func monitorUssPdcch(sfn, slot, iss, nCI int, dci, rnti string) ([]int, error) {
+	return mapUssPdcch(getDlGrid(sfn), "", sfn, slot, rgd.symbPerSlot, rgd.scPerSymb, flags.searchspace.coreset1StartCrb*rgd.scPerRb, iss, nCI, dci, rnti)
+}
+
+// monitorScellUssPdcch maps the first PDCCH candidate of USS which doesn't collide with other channels on self-scheduled SCell i in slot m(=sfn*slotPerRf+slot) of the SCell, and returns CCEs of the PDCCH candidate, which is nil if no PDCCH candidate is available.
+// Note: the CORESET of the SCell has the same configuration as CORESET1 of the PCell.
+//  iss: index of the USS
+//  dci: DCI format, which is used for printing only
+func monitorScellUssPdcch(i, m, iss int, dci string) ([]int, error) {
+	sd := rgd.scells[i]
+	return mapUssPdcch(getScellGrid(i, m/sd.slotPerRf, "D"), fmt.Sprintf("SCell%v ", flags.ca.scellIndex[i]), m/sd.slotPerRf, m%sd.slotPerRf, rgd.symbPerSlot, sd.scPerSymb, flags.searchspace.coreset1StartCrb*rgd.scPerRb, iss, 0, dci, "C-RNTI")
+}
+
+// monitorBwpUssPdcch maps the first PDCCH candidate of USS which doesn't collide with other channels on additional dedicated DL BWP j in slot m(=sfn*slotPerRf+slot) of the BWP, and returns CCEs of the PDCCH candidate, which is nil if no PDCCH candidate is available.
+// Note: the CORESET of the BWP has the same configuration as CORESET1 of the dedicated DL BWP except that it starts from the first RB of the BWP.
+//  iss: index of the USS
+//  dci: DCI format, which is used for printing only
+func monitorBwpUssPdcch(j, m, iss int, dci string) ([]int, error) {
+	bd := rgd.bwps[j]
+	return mapUssPdcch(getBwpGrid(j, m/bd.slotPerRf), fmt.Sprintf("DL BWP%v ", bd.id), m/bd.slotPerRf, m%bd.slotPerRf, bd.symbPerSlot, bd.scPerSymb, bd.startRb*rgd.scPerRb, iss, 0, dci, "C-RNTI")
+}
+
+// monitorActDlBwpPdcch maps the PDCCH of USS on the DL BWP which can be used in slot n(=sfn*slotPerRf+slot) of the carrier, and returns CCEs of the PDCCH candidate, which is nil if no PDCCH candidate is available or no DL BWP can be used(e.g. during BWP switch delay), and the location of the PDCCH.
+//  iss: index of the USS
+//  dci: DCI format, which is used for printing only
+func monitorActDlBwpPdcch(bsw *BwpSwInfo, n, iss int, dci string) ([]int, string, error) {
+	if isBwpActive(bsw, 0, 1, n) {
+		cces, err := monitorUssPdcch(n/rgd.slotPerRf, n%rgd.slotPerRf, iss, 0, dci, "C-RNTI")
+		return cces, fmt.Sprintf("PDCCH@DL BWP1[sfn=%v, slot=%v]", n/rgd.slotPerRf, n%rgd.slotPerRf), err
+	}
+
+	id := bsw.actBwp[0]
+	if n < bsw.prevUntil[0] {
+		id = bsw.prevBwp[0]
+	}
+	j := getBwpIndex("DL", id)
+	if j < 0 || !isBwpActive(bsw, 0, id, n) {
+		return nil, "", nil
+	}
+
+	// Note: the PDCCH is mapped in the first slot of the BWP overlapping with slot n of the carrier.
+	bd := rgd.bwps[j]
+	m := n * bd.slotPerRf / rgd.slotPerRf
+	cces, err := monitorBwpUssPdcch(j, m, iss, dci)
+	return cces, fmt.Sprintf("PDCCH@DL BWP%v[sfn=%v, slot=%v]", id, m/bd.slotPerRf, m%bd.slotPerRf), err
+}
+
+// mapUssPdcch maps the first PDCCH candidate of USS or Type3-PDCCH CSS in CORESET1 which doesn't collide with other channels in slot of grid, and returns CCEs of the PDCCH candidate, which is nil if no PDCCH candidate is available.
+//  cell: serving cell or BWP, which is empty for the dedicated DL BWP of the PCell and is used for printing only
+//  symbPerSlot/scPerSymb: number of symbols per slot and subcarriers per symbol of grid, where the PDCCH monitoring occasion beyond the slot(e.g. extended cyclic prefix) is skipped
+//  coreset1Sc0Rb0: the first subcarrier of the CORESET in grid
+func mapUssPdcch(grid DataPerRf, cell string, sfn, slot, symbPerSlot, scPerSymb, coreset1Sc0Rb0, iss, nCI int, dci, rnti string) ([]int, error) {
+	L, _ := strconv.Atoi(flags.searchspace.ssAggregationLevel[iss][2:])
+	M, _ := strconv.Atoi(flags.searchspace.ssNumOfPdcchCandidates[iss][1:])
+	scPerSlot := symbPerSlot * scPerSymb
+
+	for firstSymb, bit := range flags.searchspace._ssMonitoringSymbolWithinSlot[iss] {
+		if bit != '1' || firstSymb+flags.searchspace._coreset1Duration > symbPerSlot {
+			continue
+		}
+
+		for m := 0; m < M; m++ {
+			// refer to 3GPP 38.213 vh40
+			// 10.1	UE procedure for determining physical downlink control channel assignment
+			// for a USS, Y_p,-1 = n_RNTI != 0, A_p = 39827 for p mod 3 = 0, A_p = 39829 for p mod 3 = 1, A_p = 39839 for p mod 3 = 2, and D = 65537
+			// n_CI is the carrier indicator field value if the UE is configured with a carrier indicator field by CrossCarrierSchedulingConfig for the serving cell on which PDCCH is monitored; otherwise, including for any CSS, n_CI = 0
+			cces, err := detCcesPerPdcchCand(1, L, m, slot, flags.searchspace._ssType[iss], flags.advanced.cRnti, rgd.coreset1NumCces, nCI, M)
+			if err != nil {
+				return nil, err
+			}
+
+			valid := true
+			for j, icce := range rgd.coreset1Cces {
+				if !utils.ContainsInt(cces, icce) {
+					continue
+				}
+
+				for isc := 0; isc < rgd.scPerRb; isc++ {
+					ire := slot*scPerSlot + (firstSymb+rgd.coreset1RegBundles[j].Isymb)*scPerSymb + coreset1Sc0Rb0 + rgd.coreset1RegBundles[j].Irb*rgd.scPerRb + isc
+					if grid.res[ire] != NR_RES_D {
+						valid = false
+						break
+					}
+				}
+				if !valid {
+					break
+				}
+			}
+			if !valid {
+				continue
+			}
+
+			// map PDCCH candidate
+			for j, icce := range rgd.coreset1Cces {
+				if !utils.ContainsInt(cces, icce) {
+					continue
+				}
+
+				for isc := 0; isc < rgd.scPerRb; isc++ {
+					ire := slot*scPerSlot + (firstSymb+rgd.coreset1RegBundles[j].Isymb)*scPerSymb + coreset1Sc0Rb0 + rgd.coreset1RegBundles[j].Irb*rgd.scPerRb + isc
+					if isc > 0 && (isc-1)%4 == 0 {
+						grid.res[ire] = NR_RES_DMRS_PDCCH
+					} else {
+						grid.res[ire] = NR_RES_PDCCH_CANDIDATE + m
+					}
+				}
+			}
+
+			if grid.tags[slot] == nil {
+				grid.tags[slot] = mapset.NewSet()
+			}
+			grid.tags[slot].Add("PDCCH")
+
+			fmt.Printf("%vPDCCH(%v, %v): PDCCH occasion@[sfn=%v, slot=%v, firstSymb=%v, m=%v], cces=%v\n", cell, dci, rnti, sfn, slot, firstSymb, m, cces)
+
+			return cces, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// getDci11Prbs returns the sorted PRBs(relative to the lowest RB of dedicated DL BWP) of PDSCH scheduled by DCI 1_1.
+func getDci11Prbs() ([]int, error) {
+	bwpStart, bwpSize := getPdschBwp()
+
+	var prbs []int
+	if flags.dldci._fdRaType[DCI_11_PDSCH] == "raType0" {
+		// refer to 3GPP TS 38.214 vh40: 5.1.2.2.1	Downlink resource allocation type 0
+		// The RBGs shall be indexed in the order of increasing frequency and starting at the lowest frequency of the carrier bandwidth part. The order of RBG bitmap is such that RBG 0 to RBG N_RBG - 1 are mapped from MSB to LSB of the bitmap.
+		rbgs := getRaType0Rbgs(bwpStart, bwpSize, flags.pdsch._rbgSize)
+		fdRa := flags.dldci._fdRa[DCI_11_PDSCH]
+		if len(fdRa) < len(rbgs) {
+			return nil, errors.New(fmt.Sprintf("Invalid FDRA of %v: fdRa=%v while dedicated DL BWP has %v RBGs.", flags.dldci._tag[DCI_11_PDSCH], fdRa, len(rbgs)))
+		}
+
+		rb0 := 0
+		for i := range rbgs {
+			if fdRa[i] == '1' {
+				prbs = append(prbs, utils.PyRange(rb0, rb0+rbgs[i], 1)...)
+			}
+			rb0 += rbgs[i]
+		}
+	} else {
+		// refer to 3GPP TS 38.211 vh40: 7.3.1.6	Mapping from virtual to physical resource blocks
+		// The UE shall assume that the virtual resource blocks are mapped to physical resource blocks according to the indicated mapping scheme, non-interleaved or interleaved mapping.
+		rbStart := flags.dldci.fdStartRb[DCI_11_PDSCH]
+		numRbs := flags.dldci.fdNumRbs[DCI_11_PDSCH]
+		if rbStart+numRbs > bwpSize {
+			return nil, errors.New(fmt.Sprintf("Invalid FDRA of %v: fdStartRb=%v, fdNumRbs=%v while dedicated DL BWP has %v RBs.", flags.dldci._tag[DCI_11_PDSCH], rbStart, numRbs, bwpSize))
+		}
+
+		for vrb := rbStart; vrb < rbStart+numRbs; vrb++ {
+			if flags.dldci.fdVrbPrbMappingType[DCI_11_PDSCH] == "interleaved" {
+				prbs = append(prbs, rgd.dci11Prbs[vrb])
+			} else {
+				prbs = append(prbs, vrb)
+			}
+		}
+		sort.Ints(prbs)
+	}
+
+	if len(prbs) == 0 {
+		return nil, errors.New(fmt.Sprintf("No RB is allocated for %v: fdRaType=%v, fdRa=%v", flags.dldci._tag[DCI_11_PDSCH], flags.dldci._fdRaType[DCI_11_PDSCH], flags.dldci._fdRa[DCI_11_PDSCH]))
+	}
+
+	if _, carrierNumRbs := getPdschCarrier(); bwpStart+utils.MaxInt(prbs)+1 > carrierNumRbs {
+		return nil, errors.New(fmt.Sprintf("%v PDSCH(bwpStart=%v, prbs=%v) is out of the DL carrier bandwidth(%v RBs).", flags.dldci._tag[DCI_11_PDSCH], bwpStart, prbs, carrierNumRbs))
+	}
+
+	return prbs, nil
+}
+
+// recvPdsch maps PDSCH and associated DMRS/PTRS scheduled by DCI 1_1 which is received in slot of radio frame sfn, and returns the radio frame and slot(of the DL carrier for PDSCH) of the PDSCH(or the last slot of the PDSCH with pdsch-AggregationFactor).
+//  rnti: RNTI scrambling CRC of DCI 1_1, which can be C-RNTI or CS-RNTI, or SPS for PDSCH of configured downlink assignment without corresponding PDCCH, in which case slot(of the DL carrier for PDSCH) of radio frame sfn is K0 slots before the PDSCH
+func recvPdsch(sfn, slot int, rnti string) (int, int, error) {
+	prbs, err := getDci11Prbs()
+	if err != nil {
+		return -1, -1, err
+	}
+
+	S := flags.dldci._tdStartSymb[DCI_11_PDSCH]
+	L := flags.dldci._tdNumSymbs[DCI_11_PDSCH]
+	bwpStart, _ := getPdschBwp()
+	dmrsType := flags.pdsch.pdschDmrsType
+
+	// refer to 3GPP TS 38.211 vh40: 7.4.1.1.2	Mapping to physical resources (DMRS for PDSCH)
+	// l is defined relative to the start of the slot if PDSCH mapping type A, relative to the start of the scheduled PDSCH resources if PDSCH mapping type B
+	var tdL []int
+	for _, l := range flags.pdsch._tdL {
+		if flags.dldci._tdMappingType[DCI_11_PDSCH] == "typeA" {
+			tdL = append(tdL, l)
+		} else {
+			tdL = append(tdL, S+l)
+		}
+	}
+	fdK := flags.pdsch._fdK
+	dmrsCdmGroups := getDmrsCdmGroups(dmrsType, flags.pdsch._dmrsPorts, 1000)
+
+	var ptrsSymbs, ptrsRbs, ptrsScs []int
+	if flags.pdsch.pdschPtrsEnabled {
+		ptrsSymbs = getPtrsSymbs(S, L, tdL, flags.pdsch.pdschPtrsTimeDensity)
+		ptrsRbs = getPtrsRbs(len(prbs), flags.pdsch.pdschPtrsFreqDensity, flags.advanced.cRnti)
+		ptrsScs = getPtrsScs(dmrsType, flags.pdsch.pdschPtrsReOffset, []int{flags.pdsch._ptrsDmrsPorts}, 1000)
+	}
+
+	// refer to 3GPP TS 38.214 vh40
+	// 5.1.2.1	Resource allocation in time domain
+	// When receiving PDSCH scheduled by DCI format 1_1 in PDCCH with CRC scrambled by C-RNTI, MCS-C-RNTI, or CS-RNTI with NDI=1, ... if pdsch-AggregationFactor is configured, the same symbol allocation is applied across the pdsch-AggregationFactor consecutive slots. The UE may expect that the TB is repeated within each symbol allocation among each of the pdsch-AggregationFactor consecutive slots and the PDSCH is limited to a single transmission layer.
+	// Table 5.1.2.1-2: Applied redundancy version when pdsch-AggregationFactor is present
+	// refer to 3GPP TS 38.213 vh40
+	// 11.1	Slot configuration
+	// If the UE is scheduled by a DCI format to receive PDSCH over multiple slots, and if tdd-UL-DL-ConfigurationCommon, or tdd-UL-DL-ConfigurationDedicated, indicates that, for a slot from the multiple slots, at least one symbol from a set of symbols where the UE is scheduled PDSCH reception in the slot is an uplink symbol, the UE does not receive the PDSCH in the slot.
+	// Note: the redundancy version indicated by DCI 1_1 is assumed to be 0, and the PDSCH in a slot is also not received if any symbol is flexible.
+	// Note: pdsch-AggregationFactor of SPS-Config is not supported, and pdsch-AggregationFactor of PDSCH-Config also applies to SPS PDSCH.
+	K, _ := strconv.Atoi(flags.pdsch._pdschAggFactor[1:])
+	rvSeq := []int{0, 2, 3, 1}
+	// refer to 3GPP 38.214 vh40
+	// 5.1.2.1	Resource allocation in time domain
+	// The slot allocated for the PDSCH is floor(n*2^mu_PDSCH/2^mu_PDCCH) + K0
+	pdschSlotPerRf, scPerSymb, scPerSlot := getPdschDims()
+	n := getOverlapSlot(sfn*rgd.slotPerRf+slot, rgd.slotPerRf, pdschSlotPerRf, false) + flags.dldci._tdK0[DCI_11_PDSCH]
+	if rnti == "SPS" {
+		n = sfn*pdschSlotPerRf + slot + flags.dldci._tdK0[DCI_11_PDSCH]
+	}
+	numDataRes, numDmrsRes, numPtrsRes := 0, 0, 0
+	collisions := make(map[string]int)
+	var txs []string
+	// number of rate matched REs, number of REs of CSI-RS/CSI-IM rate matched and number of received slots
+	numRmRes, numCsiRes, numRxSlots := 0, 0, 0
+	for k := 0; k < K; k++ {
+		sfnd := (n + k) / pdschSlotPerRf
+		nd := (n + k) % pdschSlotPerRf
+		if err := aotCommon(sfnd); err != nil {
+			return -1, -1, err
+		}
+
+		if !isTddSymbs(sfnd, nd, S, L, "D") {
+			txs = append(txs, fmt.Sprintf("[%v,%v](omitted)", sfnd, nd))
+			continue
+		}
+
+		// refer to 3GPP TS 38.214 vh40
+		// 5.1.4	PDSCH resource mapping
+		// Note: REs declared as not available for PDSCH by RateMatchPattern(5.1.4.1) or lte-CRS-ToMatchAround(5.1.4.2) are rate matched, which are not counted as collisions.
+		// Note: REs of periodic NZP-CSI-RS(including TRS) and CSI-IM configured to the UE are also rate matched, whose overhead is covered by xOverhead instead of re-calculating the TBS.
+		grid := getPdschGrid(sfnd)
+		for symb := S; symb < S+L; symb++ {
+			isDmrs := utils.ContainsInt(tdL, symb)
+			isPtrs := utils.ContainsInt(ptrsSymbs, symb)
+			for j, prb := range prbs {
+				isRmp := isRmpRe(sfnd, nd, symb, bwpStart+prb+flags.gridsetting._offsetToCarrier)
+				for isc := 0; isc < rgd.scPerRb; isc++ {
+					ire := nd*scPerSlot + symb*scPerSymb + (bwpStart+prb)*rgd.scPerRb + isc
+					if isRmp || grid.res[ire] == NR_RES_LTE_CRS {
+						numRmRes++
+						continue
+					}
+
+					if isCsiRes(grid.res[ire]) {
+						numCsiRes++
+						continue
+					}
+
+					if grid.res[ire] != NR_RES_D {
+						collisions[resCategory(grid.res[ire])]++
+						continue
+					}
+
+					// Note: REs of CDM group(s) without data which are not used by the scheduled DMRS port(s) are DTX.
+					if isDmrs && fdK[isc] == 1 {
+						if utils.ContainsInt(dmrsCdmGroups, getDmrsCdmGroup(dmrsType, isc)) {
+							grid.res[ire] = NR_RES_DMRS_PDSCH
+							numDmrsRes++
+						} else {
+							grid.res[ire] = NR_RES_DTX
+						}
+					} else if !isDmrs && isPtrs && utils.ContainsInt(ptrsRbs, j) && utils.ContainsInt(ptrsScs, isc) {
+						grid.res[ire] = NR_RES_PTRS_PDSCH
+						numPtrsRes++
+					} else {
+						grid.res[ire] = NR_RES_PDSCH
+						numDataRes++
+					}
+				}
+			}
+		}
+
+		if grid.tags[nd] == nil {
+			grid.tags[nd] = mapset.NewSet()
+		}
+		grid.tags[nd].Add("PDSCH")
+		txs = append(txs, fmt.Sprintf("[%v,%v](rv=%v)", sfnd, nd, rvSeq[k%4]))
+		numRxSlots++
+	}
+
+	// Note: the TBS is re-calculated with the rate matched REs per PRB per slot counted as additional overhead(i.e. similar to xOverhead), although the TBS determined by DCI is not changed by rate matching.
+	tbs := flags.dldci._tbsCw0[DCI_11_PDSCH]
+	rmOh := 0
+	if numRmRes > 0 && flags.dldci.mcsCw0[DCI_11_PDSCH] >= 0 {
+		rmOh = utils.CeilInt(float64(numRmRes) / float64(numRxSlots*len(prbs)))
+		dmrsOh := (2 * flags.pdsch._cdmGroupsWoData) * len(flags.pdsch._tdL) / flags.pdsch._numFrontLoadSymbs
+		xoh, _ := strconv.Atoi(flags.pdsch.pdschXOh[3:])
+		tbs, err = getTbs("PDSCH", false, "C-RNTI", flags.pdsch.pdschMcsTable, L, len(prbs), flags.dldci.mcsCw0[DCI_11_PDSCH], len(flags.pdsch._dmrsPorts), dmrsOh, xoh+rmOh, 1)
+		if err != nil {
+			return -1, -1, err
+		}
+	}
+
+	src := fmt.Sprintf("PDSCH(DCI 1_1, %v): PDCCH@[sfn=%v, slot=%v],", rnti, sfn, slot)
+	if rnti == "SPS" {
+		src = "PDSCH(SPS):"
+	}
+	fmt.Printf("%v PDSCH@[sfn=%v, slot=%v, S=%v, L=%v], fdRaType=%v, numPrbs=%v, dmrs=%v, ptrs=%v, TBS=%v bits, REs of PDSCH=%v, REs of DMRS=%v, REs of PTRS=%v, collisions=%v\n", src, n/pdschSlotPerRf, n%pdschSlotPerRf, S, L, flags.dldci._fdRaType[DCI_11_PDSCH], len(prbs), tdL, ptrsSymbs, tbs, numDataRes, numDmrsRes, numPtrsRes, collisions)
+	if K > 1 {
+		fmt.Printf("PDSCH aggregation: pdsch-AggregationFactor=%v, PDSCH@%v\n", K, strings.Join(txs, " "))
+	}
+	if numRmRes > 0 {
+		fmt.Printf("PDSCH rate matching: REs rate matched=%v, overhead of rate matching=%v REs per PRB, TBS=%v bits(%v bits without rate matching)\n", numRmRes, rmOh, tbs, flags.dldci._tbsCw0[DCI_11_PDSCH])
+	}
+	if numCsiRes > 0 {
+		fmt.Printf("PDSCH rate matching: REs of CSI-RS/CSI-IM rate matched=%v\n", numCsiRes)
+	}
+
+	// Note: HARQ-ACK of PDSCH with aggregation is reported with respect to the last slot of the pdsch-AggregationFactor consecutive slots.
+	return (n + K - 1) / pdschSlotPerRf, (n + K - 1) % pdschSlotPerRf, nil
+}
+
+// sendPusch maps PUSCH and associated DMRS/PTRS scheduled by DCI 0_1 which is received in slot of radio frame sfn, and returns the radio frame and slot(of the UL carrier) of the PUSCH(or the first transmitted repetition of the PUSCH with repetitions).
+//  S: the first symbol of PUSCH, which is the start symbol of TDRA of DCI 0_1 except for configured grant with periodicity of less than one slot
+//  rnti: RNTI scrambling CRC of DCI 0_1, which can be C-RNTI or CS-RNTI, or CG for PUSCH of configured grant without corresponding PDCCH, in which case slot(of the UL carrier) of radio frame sfn is K2 slots before the PUSCH
+func sendPusch(sfn, slot, S int, rnti string) (int, int, error) {
+	// refer to 3GPP 38.214 vh40
+	// 6.1.2.1	Resource allocation in time domain
+	// The slot allocated for the PUSCH is floor(n*2^mu_PUSCH/2^mu_PDCCH) + K2
+	ulSlotPerRf, scPerSymb, scPerSlot := getUlDims()
+	n := getOverlapSlot(sfn*rgd.slotPerRf+slot, rgd.slotPerRf, ulSlotPerRf, false) + flags.uldci._tdK2[DCI_01_PUSCH]
+	if rnti == "CG" {
+		n = sfn*ulSlotPerRf + slot + flags.uldci._tdK2[DCI_01_PUSCH]
+	}
+	L := flags.uldci._tdNumSymbs[DCI_01_PUSCH]
+	bwpStart, bwpSize := getUlBwp(DED_UL_BWP)
+	freqHop := flags.uldci.fdFreqHop[DCI_01_PUSCH]
+	dmrsType := flags.pusch.puschDmrsType
+
+	// RBs of the first hop(relative to the lowest RB of dedicated UL BWP), and offset of RBs in odd slots in case of inter-slot frequency hopping
+	var rbs []int
+	interSlotOffset := 0
+	if flags.uldci._fdRaType[DCI_01_PUSCH] == "raType0" {
+		// refer to 3GPP TS 38.214 vh40: 6.1.2.2.1	Uplink resource allocation type 0
+		rbgs := getRaType0Rbgs(bwpStart, bwpSize, flags.pusch._rbgSize)
+		fdRa := flags.uldci._fdRa[DCI_01_PUSCH]
+		if len(fdRa) < len(rbgs) {
+			return -1, -1, errors.New(fmt.Sprintf("Invalid FDRA of %v: fdRa=%v while dedicated UL BWP has %v RBGs.", flags.uldci._tag[DCI_01_PUSCH], fdRa, len(rbgs)))
+		}
+
+		rb0 := 0
+		for i := range rbgs {
+			if fdRa[i] == '1' {
+				rbs = append(rbs, utils.PyRange(rb0, rb0+rbgs[i], 1)...)
+			}
+			rb0 += rbgs[i]
+		}
+	} else if flags.uldci._fdRaType[DCI_01_PUSCH] == "raType2" {
+		// refer to 3GPP TS 38.214 vh40: 6.1.2.2.3	Uplink resource allocation type 2
+		var err error
+		rbs, err = getInterlacedPrbs(bwpStart, bwpSize, flags.uldci.fdInterlaces, flags.uldci.fdStartRbSet, flags.uldci.fdNumRbSets)
+		if err != nil {
+			return -1, -1, err
+		}
+	} else {
+		// refer to 3GPP TS 38.214 vh40: 6.1.2.2.2	Uplink resource allocation type 1
+		rbStart := flags.uldci.fdStartRb[DCI_01_PUSCH]
+		numRbs := flags.uldci.fdNumRbs[DCI_01_PUSCH]
+		if rbStart+numRbs > bwpSize {
+			return -1, -1, errors.New(fmt.Sprintf("Invalid FDRA of %v: fdStartRb=%v, fdNumRbs=%v while dedicated UL BWP has %v RBs.", flags.uldci._tag[DCI_01_PUSCH], rbStart, numRbs, bwpSize))
+		}
+
+		// refer to 3GPP 38.214 vh40
+		// 6.3	UE PUSCH frequency hopping procedure
+		// In case of inter-slot frequency hopping, the starting RB during slot n_u_s is given by RB_start if n_u_s mod 2 = 0, and (RB_start + RB_offset) mod N_BWP_size if n_u_s mod 2 = 1
+		if freqHop == "inter-slot" {
+			interSlotOffset = (rbStart+flags.uldci._fdFreqHopOffset[DCI_01_PUSCH])%bwpSize - rbStart
+		}
+		rbs = utils.PyRange(rbStart, rbStart+numRbs, 1)
+	}
+
+	if len(rbs) == 0 {
+		return -1, -1, errors.New(fmt.Sprintf("No RB is allocated for %v: fdRaType=%v, fdRa=%v", flags.uldci._tag[DCI_01_PUSCH], flags.uldci._fdRaType[DCI_01_PUSCH], flags.uldci._fdRa[DCI_01_PUSCH]))
+	}
+
+	// Note: the repetitions of configured grant PUSCH are determined by repK in schedCgSps, and PUSCH repetition Type A is assumed.
+	K, repType := 1, "typeA"
+	if rnti == "C-RNTI" {
+		K, _ = getDedPuschReps()
+		repType = flags.pusch._puschRepType
+	}
+	reps := getPuschReps(n, S, L, K, repType)
+
+	fdK := flags.pusch._fdK
+	dmrsCdmGroups := getDmrsCdmGroups(dmrsType, flags.pusch._dmrsPorts, 0)
+
+	// refer to 3GPP 38.211 vh40
+	// 6.4.1.2.2.2	Precoding and mapping to physical resources (PT-RS for PUSCH with transform precoding)
+	// Note: PT-RS for DFT-s-OFDM is inserted before transform precoding, which is not visible on the resource grid.
+	var ptrsRbs, ptrsScs []int
+	ptrsEnabled := flags.pusch.puschPtrsEnabled && flags.pusch.puschTp == "disabled" && len(flags.pusch._ptrsDmrsPorts) > 0
+	if ptrsEnabled {
+		ptrsRbs = getPtrsRbs(len(rbs), flags.pusch.puschPtrsFreqDensity, flags.advanced.cRnti)
+		ptrsScs = getPtrsScs(dmrsType, flags.pusch.puschPtrsReOffset, flags.pusch._ptrsDmrsPorts, 0)
+	}
+
+	// radio frame and slot of the first transmitted repetition, and hops/DMRS/PTRS of the first transmitted repetition
+	sfnu, nu := -1, -1
+	var hops0, dmrs0, ptrs0 [][]int
+	numDataRes, numDmrsRes, numPtrsRes := 0, 0, 0
+	collisions := make(map[string]int)
+	var txs []string
+	for _, rep := range reps {
+		sfnr := rep[0] / ulSlotPerRf
+		nr := rep[0] % ulSlotPerRf
+		if rep[4] == 1 {
+			txs = append(txs, fmt.Sprintf("[%v,%v,S=%v,L=%v](nominal=%v, omitted)", sfnr, nr, rep[1], rep[2], rep[5]))
+			continue
+		}
+		if err := aotCommon(sfnr); err != nil {
+			return -1, -1, err
+		}
+
+		// refer to 3GPP 38.213 vh40
+		// 9.2.6	PUCCH repetition procedure
+		// If a UE would transmit a PUCCH over a first number of N_PUCCH_repeat > 1 slots and the UE would transmit a PUSCH ... over a second number of slots, and the PUCCH transmission would overlap with the PUSCH transmission in one or more slots, ... the UE transmits the PUCCH and does not transmit the PUSCH in the overlapping slots.
+		if key, exist := rgd.pucchSlots[rep[0]]; exist {
+			tr := rgd.pucchTr[key]
+			if tr.numRep > 1 {
+				txs = append(txs, fmt.Sprintf("[%v,%v,S=%v,L=%v](nominal=%v, dropped due to PUCCH repetitions)", sfnr, nr, rep[1], rep[2], rep[5]))
+				continue
+			}
+			if !tr.onPusch {
+				unmapDedPucch(key, rep[0])
+				rgd.pucchSlots[rep[0]] = key
+				muxUciOnPusch(tr, fmt.Sprintf("[sfn=%v, slot=%v]", sfnr, nr))
+			}
+		}
+
+		// TD/FD pattern of each hop, where each element is [firstSymb, numSymbs, offset of RBs] and DMRS symbols
+		// refer to 3GPP 38.214 vh40
+		// 6.3	UE PUSCH frequency hopping procedure
+		// In case of intra-slot frequency hopping, ... The number of symbols in the first hop is given by floor(N_PUSCH_symb/2), the number of symbols in the second hop is given by N_PUSCH_symb - floor(N_PUSCH_symb/2)
+		// refer to 3GPP 38.211 vh40
+		// 6.4.1.1.3	Precoding and mapping to physical resources (DMRS for PUSCH)
+		// l is defined relative to the start of the slot if frequency hopping is disabled and PUSCH mapping type A, relative to the start of the scheduled PUSCH resources if frequency hopping is disabled and PUSCH mapping type B, relative to the start of each hop in case frequency hopping is enabled
+		// Note: for PUSCH repetition Type B, DMRS is mapped per actual repetition, and l_d is the duration of the actual repetition.
+		Sr, Lr := rep[1], rep[2]
+		rbOffset := 0
+		if freqHop == "inter-slot" && nr%2 == 1 {
+			rbOffset = interSlotOffset
+		}
+		var hops [][]int
+		var dmrs [][]int
+		if freqHop == "intra-slot" {
+			L1 := utils.FloorInt(float64(Lr) / 2)
+			hopOffset := flags.uldci._fdFreqHopOffset[DCI_01_PUSCH]
+			hops = [][]int{{Sr, L1, 0}, {Sr + L1, Lr - L1, (rbs[0]+hopOffset)%bwpSize - rbs[0]}}
+			dmrs = make([][]int, 2)
+			for _, l := range flags.pusch._tdL {
+				dmrs[0] = append(dmrs[0], Sr+l)
+			}
+			for _, l := range flags.pusch._tdL2 {
+				dmrs[1] = append(dmrs[1], Sr+L1+l)
+			}
+		} else {
+			hops = [][]int{{Sr, Lr, rbOffset}}
+			dmrs = make([][]int, 1)
+			tdL := flags.pusch._tdL
+			if repType == "typeB" {
+				tdL = getPuschRepTypeBDmrs(Lr)
+			}
+			for _, l := range tdL {
+				if flags.uldci._tdMappingType[DCI_01_PUSCH] == "typeA" {
+					dmrs[0] = append(dmrs[0], l)
+				} else {
+					dmrs[0] = append(dmrs[0], Sr+l)
+				}
+			}
+		}
+
+		ptrsSymbs := make([][]int, len(hops))
+		if ptrsEnabled {
+			for ihop, hop := range hops {
+				ptrsSymbs[ihop] = getPtrsSymbs(hop[0], hop[1], dmrs[ihop], flags.pusch.puschPtrsTimeDensity)
+			}
+		}
+
+		grid := getUlGrid(sfnr)
+		for ihop, hop := range hops {
+			if rbs[len(rbs)-1]+hop[2]+1 > bwpSize {
+				return -1, -1, errors.New(fmt.Sprintf("%v PUSCH(rbStart=%v, numRbs=%v, hop=%v) is out of the dedicated UL BWP(%v RBs).", flags.uldci._tag[DCI_01_PUSCH], rbs[0], len(rbs), hop, bwpSize))
+			}
+
+			for symb := hop[0]; symb < hop[0]+hop[1]; symb++ {
+				isDmrs := utils.ContainsInt(dmrs[ihop], symb)
+				isPtrs := utils.ContainsInt(ptrsSymbs[ihop], symb)
+				for j, rb := range rbs {
+					for isc := 0; isc < rgd.scPerRb; isc++ {
+						ire := nr*scPerSlot + symb*scPerSymb + (bwpStart+rb+hop[2])*rgd.scPerRb + isc
+						if grid.res[ire] != NR_RES_U {
+							collisions[resCategory(grid.res[ire])]++
+							continue
+						}
+
+						// Note: REs of CDM group(s) without data which are not used by the scheduled DMRS port(s) are DTX.
+						if isDmrs && fdK[isc] == 1 {
+							if utils.ContainsInt(dmrsCdmGroups, getDmrsCdmGroup(dmrsType, isc)) {
+								grid.res[ire] = NR_RES_DMRS_PUSCH
+								numDmrsRes++
+							} else {
+								grid.res[ire] = NR_RES_DTX
+							}
+						} else if !isDmrs && isPtrs && utils.ContainsInt(ptrsRbs, j) && utils.ContainsInt(ptrsScs, isc) {
+							grid.res[ire] = NR_RES_PTRS_PUSCH
+							numPtrsRes++
+						} else {
+							grid.res[ire] = NR_RES_PUSCH
+							numDataRes++
+						}
+					}
+				}
+			}
+		}
+
+		if grid.tags[nr] == nil {
+			grid.tags[nr] = mapset.NewSet()
+		}
+		grid.tags[nr].Add("PUSCH")
+
+		if sfnu < 0 {
+			sfnu, nu = sfnr, nr
+			hops0, dmrs0, ptrs0 = hops, dmrs, ptrsSymbs
+		}
+		txs = append(txs, fmt.Sprintf("[%v,%v,S=%v,L=%v](nominal=%v, rv=%v, dmrs=%v)", sfnr, nr, Sr, Lr, rep[5], rep[3], dmrs))
+	}
+
+	if sfnu < 0 {
+		return -1, -1, errors.New(fmt.Sprintf("All repetitions of %v are omitted: PUSCH@%v", flags.uldci._tag[DCI_01_PUSCH], strings.Join(txs, " ")))
+	}
+
+	src := fmt.Sprintf("PUSCH(DCI 0_1, %v): PDCCH@[sfn=%v, slot=%v],", rnti, sfn, slot)
+	if rnti == "CG" {
+		src = "PUSCH(CG):"
+	}
+	fmt.Printf("%v PUSCH@[sfn=%v, slot=%v, S=%v, L=%v], fdRaType=%v, freqHop=%v, hops([firstSymb, numSymbs, offset])=%v, rbStart=%v, numRbs=%v, dmrs=%v, ptrs=%v, TBS=%v bits, REs of PUSCH=%v, REs of DMRS=%v, REs of PTRS=%v, collisions=%v\n", src, sfnu, nu, S, L, flags.uldci._fdRaType[DCI_01_PUSCH], freqHop, hops0, rbs[0], len(rbs), dmrs0, ptrs0, flags.uldci._tbs[DCI_01_PUSCH], numDataRes, numDmrsRes, numPtrsRes, collisions)
+	if K > 1 {
+		fmt.Printf("PUSCH repetition(%v): K=%v, PUSCH@%v\n", repType, K, strings.Join(txs, " "))
+	}
+
+	return sfnu, nu, nil
+}
+
+// getDedPuschReps returns the number of repetitions(or nominal repetitions of PUSCH repetition Type B) of PUSCH scheduled by DCI 0_1 with C-RNTI, and the number of slots spanned by the repetitions.
+func getDedPuschReps() (int, int) {
+	// refer to 3GPP 38.214 vh40
+	// 6.1.2.1	Resource allocation in time domain
+	// For PUSCH repetition Type A, when transmitting PUSCH scheduled by DCI format 0_1 or 0_2 in PDCCH with CRC scrambled with C-RNTI, MCS-C-RNTI, or CS-RNTI with NDI=1, the number of repetitions K is determined as
+	//  - if numberOfRepetitions is present in the resource allocation table, the number of repetitions K is equal to numberOfRepetitions;
+	//  - elseif the UE is configured with pusch-AggregationFactor, the number of repetitions K is equal to pusch-AggregationFactor;
+	//  - otherwise K=1.
+	// For PUSCH repetition Type B, the number of nominal repetitions is given by numberOfRepetitions.
+	// Note: numberOfRepetitions is only supported for PUSCH repetition Type B, since PUSCH repetition Type A uses the default PUSCH time domain resource allocation A.
+	if flags.pusch._puschRepType == "typeB" {
+		K, _ := strconv.Atoi(flags.pusch._puschAllocNumReps[1:])
+		S, L := flags.uldci._tdStartSymb[DCI_01_PUSCH], flags.uldci._tdNumSymbs[DCI_01_PUSCH]
+		return K, (S+K*L-1)/rgd.symbPerSlot + 1
+	}
+
+	K, _ := strconv.Atoi(flags.pusch._puschAggFactor[1:])
+	return K, K
+}
+
+// getPuschReps returns the (actual) repetitions of PUSCH starting in slot n(=sfn*slotPerRf+slot), where each element is [slot(=sfn*slotPerRf+slot), firstSymb, numSymbs, rv, omitted, index of nominal repetition].
+//  S: first symbol of PUSCH allocation
+//  L: number of symbols of PUSCH allocation
+//  K: number of repetitions, or number of nominal repetitions of PUSCH repetition Type B
+//  repType: PUSCH repetition type, which can be typeA or typeB
+func getPuschReps(n, S, L, K int, repType string) [][]int {
+	// refer to 3GPP 38.214 vh40
+	// Table 6.1.2.1-2: Redundancy version for PUSCH transmission
+	// Note: the redundancy version indicated by DCI 0_1 is assumed to be 0.
+	rvSeq := []int{0, 2, 3, 1}
+
+	var reps [][]int
+	if repType == "typeA" {
+		// refer to 3GPP 38.214 vh40
+		// 6.1.2.1	Resource allocation in time domain
+		// For PUSCH repetition Type A, in case K>1, the same symbol allocation is applied across the K consecutive slots and the PUSCH is limited to a single transmission layer. The UE shall repeat the TB across the K consecutive slots applying the same symbol allocation in each slot.
+		// refer to 3GPP 38.213 vh40
+		// 11.1	Slot configuration
+		// If the UE is scheduled by a DCI format to transmit PUSCH over multiple slots, and if tdd-UL-DL-ConfigurationCommon, or tdd-UL-DL-ConfigurationDedicated, indicates that, for a slot from the multiple slots, at least one symbol from a set of symbols where the UE is scheduled PUSCH transmission in the slot is a downlink symbol, the UE does not transmit the PUSCH in the slot.
+		for k := 0; k < K; k++ {
+			omitted := 0
+			if hasTddDlSymbs(n+k, S, L) {
+				omitted = 1
+			}
+			reps = append(reps, []int{n + k, S, L, rvSeq[k%4], omitted, k})
+		}
+
+		return reps
+	}
+
+	// refer to 3GPP 38.214 vh40
+	// 6.1.2.1	Resource allocation in time domain
+	// For PUSCH repetition Type B, ... For the nth nominal repetition, n = 0, ..., numberOfRepetitions - 1,
+	//  - The slot where the nominal repetition starts is given by K_s + floor((S + n*L)/N_slot_symb), and the starting symbol relative to the start of the slot is given by mod(S + n*L, N_slot_symb).
+	//  - The slot where the nominal repetition ends is given by K_s + floor((S + (n+1)*L - 1)/N_slot_symb), and the ending symbol relative to the start of the slot is given by mod(S + (n+1)*L - 1, N_slot_symb).
+	// A symbol that is indicated as downlink by tdd-UL-DL-ConfigurationCommon or tdd-UL-DL-ConfigurationDedicated is considered as an invalid symbol for PUSCH repetition Type B transmission.
+	// ... If the number of potentially valid symbols for PUSCH repetition type B transmission is greater than zero for a nominal repetition, the nominal repetition consists of one or more actual repetitions, where each actual repetition consists of a consecutive set of all potentially valid symbols that can be used for PUSCH repetition Type B transmission within a slot.
+	// An actual repetition is omitted ... if L>1 and if the number of symbols for the actual repetition is 1.
+	// The redundancy version to be applied on the nth actual repetition (with the counting including the actual repetitions that are omitted) is determined according to table 6.1.2.1-2.
+	// Note: invalidSymbolPattern and numberOfInvalidSymbolsForDL-UL-Switching are not supported, and symbols of SS/PBCH blocks and CORESET0 are not considered, so only DL symbols are invalid.
+	m := 0
+	for k := 0; k < K; k++ {
+		first := n*rgd.symbPerSlot + S + k*L
+		last := first + L - 1
+		// first symbol of the current actual repetition, or -1 if no actual repetition is ongoing
+		start := -1
+		for a := first; a <= last+1; a++ {
+			valid := a <= last && !hasTddDlSymbs(a/rgd.symbPerSlot, a%rgd.symbPerSlot, 1)
+			// an actual repetition ends before an invalid symbol, the slot boundary or the end of the nominal repetition
+			if start >= 0 && (!valid || a%rgd.symbPerSlot == 0) {
+				omitted := 0
+				if L > 1 && a-start == 1 {
+					omitted = 1
+				}
+				reps = append(reps, []int{start / rgd.symbPerSlot, start % rgd.symbPerSlot, a - start, rvSeq[m%4], omitted, k})
+				m++
+				start = -1
+			}
+			if valid && start < 0 {
+				start = a
+			}
+		}
+	}
+
+	return reps
+}
+
+// getPuschRepTypeBDmrs returns DMRS symbols relative to the start of an actual repetition of PUSCH repetition Type B.
+//  Lr: number of symbols of the actual repetition
+func getPuschRepTypeBDmrs(Lr int) []int {
+	// refer to 3GPP 38.211 vh40
+	// Table 6.4.1.1.3-4: PUSCH DM-RS positions l- within a slot for double-symbol DM-RS and intra-slot frequency hopping disabled.
+	// Note: single-symbol DMRS is assumed for actual repetition whose duration is not applicable for double-symbol DMRS.
+	numFrontLoadSymbs := flags.pusch._numFrontLoadSymbs
+	if numFrontLoadSymbs == 2 && nrgrid.DmrsPuschPosTwoSymbsWoIntraSlotFh[fmt.Sprintf("%v_typeB_%v", Lr, flags.pusch.puschDmrsAddPos)] == nil {
+		numFrontLoadSymbs = 1
+	}
+	tdL, _, _ := getDmrsPuschTdFdPattern(flags.pusch.puschDmrsType, "typeB", 0, Lr, numFrontLoadSymbs, flags.pusch.puschDmrsAddPos, flags.pusch._cdmGroupsWoData, "disabled", flags.bwp._bwpCp[DED_UL_BWP])
+
+	return tdL
+}
+
+// getDmrsCdmGroup returns the CDM group which subcarrier k within a PRB belongs to.
+//  dmrsType: DMRS configuration type, which can be type1 or type2
+func getDmrsCdmGroup(dmrsType string, k int) int {
+	// refer to 3GPP 38.211 vh40
+	// Table 6.4.1.1.3-1/Table 7.4.1.1.2-1: k = 4n + 2k' + delta for configuration type 1, and k = 6n + k' + delta for configuration type 2
+	if dmrsType == "type1" {
+		return k % 2
+	}
+
+	return (k % 6) / 2
+}
+
+// getDmrsCdmGroups returns the CDM group(s) of the given DMRS port(s).
+//  dmrsType: DMRS configuration type, which can be type1 or type2
+//  ports: DMRS antenna port(s)
+//  p0: the first DMRS antenna port, which is 0 for PUSCH and 1000 for PDSCH
+func getDmrsCdmGroups(dmrsType string, ports []int, p0 int) []int {
+	// refer to 3GPP 38.211 vh40
+	// Table 6.4.1.1.3-1: Parameters for PUSCH DM-RS configuration type 1
+	// Table 6.4.1.1.3-2: Parameters for PUSCH DM-RS configuration type 2
+	// Table 7.4.1.1.2-1: Parameters for PDSCH DM-RS configuration type 1
+	// Table 7.4.1.1.2-2: Parameters for PDSCH DM-RS configuration type 2
+	numGroups := 2
+	if dmrsType == "type2" {
+		numGroups = 3
+	}
+
+	var groups []int
+	for _, p := range ports {
+		g := ((p - p0) / 2) % numGroups
+		if !utils.ContainsInt(groups, g) {
+			groups = append(groups, g)
+		}
+	}
+
+	return groups
+}
+
+// getPtrsSymbs returns the PTRS symbols within a slot.
+//  S: first symbol of PDSCH/PUSCH allocation(or the hop of PUSCH)
+//  L: number of symbols of PDSCH/PUSCH allocation(or the hop of PUSCH)
+//  dmrs: DMRS symbols within the slot
+//  lPtrs: the L_PT-RS
+func getPtrsSymbs(S, L int, dmrs []int, lPtrs int) []int {
+	// refer to 3GPP 38.211 vh40
+	// 6.4.1.2.2.1	Precoding and mapping to physical resources (PT-RS for PUSCH without transform precoding)
+	// 7.4.1.2.2	Mapping to physical resources (PT-RS for PDSCH)
+	// 1) set i = 0 and l_ref = 0
+	// 2) if any symbol in the interval max(l_ref + (i-1)*L_PT-RS + 1, l_ref), ..., l_ref + i*L_PT-RS overlaps with a symbol used for DM-RS
+	//  - set i = 1
+	//  - set l_ref to the symbol index of the DM-RS symbol in case of a single-symbol DM-RS and to the symbol index of the second DM-RS symbol in case of a double-symbol DM-RS
+	//  - repeat from step 2 as long as l_ref + i*L_PT-RS is inside the PDSCH allocation
+	// 3) add l_ref + i*L_PT-RS to the set of time indices for PT-RS
+	// 4) increment i by one
+	// 5) repeat from step 2 above as long as l_ref + i*L_PT-RS is inside the PDSCH allocation
+	var symbs []int
+	i, lRef := 0, S
+	for lRef+i*lPtrs < S+L {
+		dmrsSymb := -1
+		for l := utils.MaxInt([]int{lRef + (i-1)*lPtrs + 1, lRef}); l <= lRef+i*lPtrs; l++ {
+			if utils.ContainsInt(dmrs, l) {
+				dmrsSymb = l
+			}
+		}
+
+		if dmrsSymb >= 0 {
+			if utils.ContainsInt(dmrs, dmrsSymb+1) {
+				dmrsSymb++
+			}
+			i, lRef = 1, dmrsSymb
+			continue
+		}
+
+		symbs = append(symbs, lRef+i*lPtrs)
+		i++
+	}
+
+	return symbs
+}
+
+// getPtrsRbs returns the PTRS RBs, which are indexes of the scheduled RBs in increasing order.
+//  numRbs: number of the scheduled RBs
+//  kPtrs: the K_PT-RS
+//  nRnti: the RNTI associated with the DCI scheduling the transmission
+func getPtrsRbs(numRbs, kPtrs, nRnti int) []int {
+	// refer to 3GPP 38.211 vh40
+	// 7.4.1.2.2	Mapping to physical resources (PT-RS for PDSCH)
+	// k = k_RE_ref + (i*K_PT-RS + k_RB_ref)*N_RB_sc, where k_RB_ref = n_RNTI mod K_PT-RS if N_RB mod K_PT-RS = 0, otherwise n_RNTI mod (N_RB mod K_PT-RS)
+	var kRbRef int
+	if numRbs%kPtrs == 0 {
+		kRbRef = nRnti % kPtrs
+	} else {
+		kRbRef = nRnti % (numRbs % kPtrs)
+	}
+
+	return utils.PyRange(kRbRef, numRbs, kPtrs)
+}
+
+// getPtrsScs returns the PTRS subcarriers within a PRB.
+//  dmrsType: DMRS configuration type, which can be type1 or type2
+//  reOffset: the resourceElementOffset of PTRS
+//  ports: DMRS antenna port(s) associated with PTRS port(s)
+//  p0: the first DMRS antenna port, which is 0 for PUSCH and 1000 for PDSCH
+func getPtrsScs(dmrsType, reOffset string, ports []int, p0 int) []int {
+	kRefRe := nrgrid.PtrsKRefRe[fmt.Sprintf("%v_%v", dmrsType, reOffset)]
+
+	var scs []int
+	for _, p := range ports {
+		if p-p0 >= 0 && p-p0 < len(kRefRe) {
+			scs = append(scs, kRefRe[p-p0])
+		}
+	}
+
+	return scs
+}
+
+func updateRach() error {
+	regYellow.Printf("-->calling updateRach\n")
+
+	var p *nrgrid.RachInfo
+	var exist bool
+	if flags.gridsetting._freqRange == "FR1" {
+		// refer to 3GPP 38.211 vh40
+		// Table 6.3.3.2-2: Random access configurations for FR1 and paired spectrum/supplementary uplink.
+		if flags.gridsetting._duplexMode == "FDD" || isSulUsed() {
+			p, exist = nrgrid.RaCfgFr1FddSUl[flags.rach.prachConfId]
+		} else {
+			p, exist = nrgrid.RaCfgFr1Tdd[flags.rach.prachConfId]
+		}
+	} else {
+		p, exist = nrgrid.RaCfgFr2Tdd[flags.rach.prachConfId]
+	}
+
+	if !exist {
+		return errors.New(fmt.Sprintf("Invalid configurations for PRACH: %v,%v with prach-ConfigurationIndex=%v\n",
+			flags.gridsetting._freqRange, flags.gridsetting._duplexMode, flags.rach.prachConfId))
+	}
+
+	fmt.Printf("RACH Info: %v\n", *p)
+
+	flags.rach._raFormat = p.Format
+	flags.rach._raX = p.X
+	flags.rach._raY = p.Y
+	flags.rach._raSubfNumFr1SlotNumFr2 = p.SubfNumFr1SlotNumFr2
+	flags.rach._raStartingSymb = p.StartingSymb
+	flags.rach._raNumSlotsPerSubfFr1Per60KSlotFr2 = p.NumSlotsPerSubfFr1Per60KSlotFr2
+	flags.rach._raNumOccasionsPerSlot = p.NumOccasionsPerSlot
+	flags.rach._raDuration = p.Duration
+
+	var raScsSet []string
+	if flags.rach._raFormat == "0" || flags.rach._raFormat == "1" || flags.rach._raFormat == "2" || flags.rach._raFormat == "3" {
+		raScsSet = append(raScsSet, nrgrid.ScsRaLongPrach["839_"+flags.rach._raFormat])
+	} else {
+		if flags.gridsetting._freqRange == "FR1" {
+			raScsSet = append(raScsSet, []string{"15KHz", "30KHz"}...)
+		} else if flags.gridsetting._freqRange == "FR2-1" {
+			raScsSet = append(raScsSet, []string{"60KHz", "120KHz"}...)
+		} else if flags.gridsetting._freqRange == "FR2-2" {
+			raScsSet = append(raScsSet, []string{"120KHz", "480KHz", "960KHz"}...)
+		}
+	}
+	fmt.Printf("Available short PRACH SCS(msg1-SubcarrierSpacing of RACH-ConfigCommon): %v\n", raScsSet)
+
+	if utils.ContainsStr([]string{"0", "1", "2", "3"}, flags.rach._raFormat) {
+		flags.rach._raLen = 839
+		if flags.rach._raFormat == "3" {
+			flags.rach._msg1Scs = "5KHz"
+		} else {
+			flags.rach._msg1Scs = "1.25KHz"
+		}
+	} else {
+		// refer to 38.211 vh40 Table 6.3.3.1-2
+		// L_RA=1151/571 for NR-U are not supported!
+		// Note: Msg1 SCS of short PRACH is the subcarrierSpacing of the UL carrier, which is SUL carrier if SUL is used.
+		flags.rach._raLen = 139
+		flags.rach._msg1Scs = getUlScs()
+	}
+
+	// Note: the PUSCH SCS is the subcarrierSpacing of the initial UL BWP of the UL carrier.
+	ulScs := getUlScs()
+	key := fmt.Sprintf("%v_%v_%v", flags.rach._raLen, flags.rach._msg1Scs[:len(flags.rach._msg1Scs)-3], ulScs[:len(ulScs)-3])
+	p2, exist2 := nrgrid.NumRbsRaAndKBar[key]
+	if !exist2 {
+		return errors.New(fmt.Sprintf("Invalid key(=%v) when referring NumRbsRaAndKBar!\n", key))
+	}
+	flags.rach._raNumRbs = p2[0]
+	flags.rach._raKBar = p2[1]
+
+	// refer to 38.211 vh40
+	// 5.3.2	OFDM baseband signal generation for PRACH
+	// - n_RA_slot is given by
+	//   - if deltaf_RA is {30, 120}kHz and either of "Number of PRACH slots within a subframe" in Tables 6.3.3.2-2 to 6.3.3.2-3 or "Number of PRACH slots within a 60 kHz slot" in Table 6.3.3.2-4 is equal to 1, then n_RA_slot = 0, otherwise n_RA_slot = {0,1}
+	if flags.rach._raNumSlotsPerSubfFr1Per60KSlotFr2 == 2 && flags.rach._msg1Scs != "30KHz" && flags.rach._msg1Scs != "120KHz" {
+		return errors.New(fmt.Sprintf("Msg1 SCS must be 30KHz for FR1 or 120KHz for FR2-1 when NumSlotsPerSubfFr1Per60KSlotFr2 = 2!\n"))
+	}
+
+	// refer to 38.331 vh30 totalNumberOfRA-Preambles
+	// Total number of preambles used for contention based and contention free 4-step or 2-step random access in the RACH resources defined in RACH-ConfigCommon, excluding preambles used for other purposes (e.g. for SI request). If the field is absent, all 64 preambles are available for RA.
+	// The setting should be consistent with the setting of ssb-perRACH-OccasionAndCB-PreamblesPerSSB, i.e. it should be a multiple of the number of SSBs per RACH occasion.
+	if nrgrid.SsbPerRachOccasion2Float[flags.rach.ssbPerRachOccasion] > 1 && flags.rach.totNumPreambs%int(nrgrid.SsbPerRachOccasion2Float[flags.rach.ssbPerRachOccasion]) != 0 {
+		return errors.New(fmt.Sprintf("The totalNumberOfRA-Preambles should be a multiple of the number of SSBs per RACH occasion. (totNumPreambs=%v, ssbPerRachoccasion=%v)\n", flags.rach.totNumPreambs, flags.rach.ssbPerRachOccasion))
+	}
+
+	return nil
+}
+
+// validateDlDciEntry validates that all the slices of DL DCI contain the entry of index i.
+//  i: index of DL DCI
+func validateDlDciEntry(i int) error {
+	lens := []int{len(flags.dldci._tag), len(flags.dldci._rnti), len(flags.dldci._muPdcch), len(flags.dldci._muPdsch), len(flags.dldci._indicatedBwp), len(flags.dldci.tdra), len(flags.dldci._tdMappingType), len(flags.dldci._tdK0), len(flags.dldci._tdSliv), len(flags.dldci._tdStartSymb), len(flags.dldci._tdNumSymbs), len(flags.dldci._fdRaType), len(flags.dldci._fdBitsRaType1), len(flags.dldci._fdRa), len(flags.dldci.fdStartRb), len(flags.dldci.fdNumRbs), len(flags.dldci.fdVrbPrbMappingType), len(flags.dldci.fdBundleSize), len(flags.dldci.mcsCw0), len(flags.dldci._tbsCw0)}
+	for _, n := range lens {
+		if n <= i {
+			return errors.New(fmt.Sprintf("The entry of index %v is missing in nrrg.dldci of the config file(_tag=%v, lengths of slices=%v)!", i, flags.dldci._tag, lens))
+		}
+	}
+
+	return nil
+}
+
+// validateUlDciEntry validates that all the slices of UL DCI contain the entry of index i.
+//  i: index of UL DCI
+func validateUlDciEntry(i int) error {
+	lens := []int{len(flags.uldci._tag), len(flags.uldci._rnti), len(flags.uldci._muPdcch), len(flags.uldci._muPusch), len(flags.uldci._indicatedBwp), len(flags.uldci.tdra), len(flags.uldci._tdMappingType), len(flags.uldci._tdK2), len(flags.uldci._tdSliv), len(flags.uldci._tdStartSymb), len(flags.uldci._tdNumSymbs), len(flags.uldci._fdRaType), len(flags.uldci.fdFreqHop), len(flags.uldci._fdFreqHopOffset), len(flags.uldci._fdBitsRaType1), len(flags.uldci._fdRa), len(flags.uldci.fdStartRb), len(flags.uldci.fdNumRbs), len(flags.uldci.mcsCw0), len(flags.uldci._tbs)}
+	for _, n := range lens {
+		if n <= i {
+			return errors.New(fmt.Sprintf("The entry of index %v is missing in nrrg.uldci of the config file(_tag=%v, lengths of slices=%v)!", i, flags.uldci._tag, lens))
+		}
+	}
+
+	return nil
+}
+
+// validateDmrsCommonEntry validates that all the slices of common DMRS contain the entry of index i.
+//  i: index of common DMRS
+func validateDmrsCommonEntry(i int) error {
+	lens := []int{len(flags.dmrsCommon._tag), len(flags.dmrsCommon._dmrsType), len(flags.dmrsCommon._dmrsAddPos), len(flags.dmrsCommon._maxLength), len(flags.dmrsCommon._dmrsPorts), len(flags.dmrsCommon._cdmGroupsWoData), len(flags.dmrsCommon._numFrontLoadSymbs)}
+	for _, n := range lens {
+		if n <= i {
+			return errors.New(fmt.Sprintf("The entry of index %v is missing in nrrg.dmrscommon of the config file(_tag=%v, lengths of slices=%v)!", i, flags.dmrsCommon._tag, lens))
+		}
+	}
+
+	return nil
+}
+
+// validateMsgA validates MsgA PUSCH configurations of 2-step RA, and updates the RA_UL_MSGA entry of UL DCI, including TBS of MsgA PUSCH.
+func validateMsgA() error {
+	if flags.rach.raType == "4-step" {
+		return nil
+	}
+
+	regYellow.Printf("-->calling validateMsgA\n")
+
+	if flags.rach.raType != "2-step" {
+		return errors.New(fmt.Sprintf("Invalid raType(=%v), which must be 4-step or 2-step!", flags.rach.raType))
+	}
+
+	// Note: 2-step RA requires the RA_UL_MSGA/FBRAR_UL_MSG3 entries of UL DCI and the DCI_10_MSGB entries of DL DCI and common DMRS.
+	for _, i := range []int{RA_UL_MSGA, FBRAR_UL_MSG3} {
+		if err := validateUlDciEntry(i); err != nil {
+			return err
+		}
+	}
+	if err := validateDlDciEntry(DCI_10_MSGB); err != nil {
+		return err
+	}
+	if err := validateDmrsCommonEntry(DMRS_DCI_10_MSGB); err != nil {
+		return err
+	}
+
+	// refer to 3GPP 38.331 vh30 MsgA-PUSCH-Resource-r16
+	// startSymbolAndLengthMsgA-PO-r16: An index giving valid combinations of start symbol, length and mapping type as start and length indicator (SLIV) for the first msgA PUSCH occasion as defined in TS 38.214 [19]
+	sl, err := nrgrid.FromSliv(flags.rach.msgAStartSymbAndLength, "PUSCH", flags.rach.msgAMappingType, flags.bwp._bwpCp[INI_UL_BWP], "typeA")
+	if err != nil {
+		return err
+	}
+	S, L := sl[0], sl[1]
+
+	// Note: DMRS position of PUSCH mapping type A is relative to the start of the slot, so only one MsgA PUSCH occasion per slot is supported for mapping type A.
+	if flags.rach.msgAMappingType == "typeA" && flags.rach.msgANumPosPerSlot != 1 {
+		return errors.New(fmt.Sprintf("msgANumPosPerSlot(=%v) must be 1 when msgAMappingType is typeA!", flags.rach.msgANumPosPerSlot))
+	}
+
+	if S+flags.rach.msgANumPosPerSlot*(L+flags.rach.msgAGuardPeriod)-flags.rach.msgAGuardPeriod > getSymbPerSlot(flags.bwp._bwpCp[INI_UL_BWP]) {
+		return errors.New(fmt.Sprintf("MsgA PUSCH occasions(S=%v, L=%v, msgANumPosPerSlot=%v, msgAGuardPeriod=%v) exceed the slot boundary!", S, L, flags.rach.msgANumPosPerSlot, flags.rach.msgAGuardPeriod))
+	}
+
+	_, bwpSize := getUlBwp(INI_UL_BWP)
+	if flags.rach.msgAFreqStart+flags.rach.msgANumPosFdm*(flags.rach.msgANumRbs+flags.rach.msgAGuardBand)-flags.rach.msgAGuardBand > bwpSize {
+		return errors.New(fmt.Sprintf("MsgA PUSCH occasions(msgAFreqStart=%v, msgANumRbs=%v, msgANumPosFdm=%v, msgAGuardBand=%v) are out of the initial UL BWP(%v RBs)!", flags.rach.msgAFreqStart, flags.rach.msgANumRbs, flags.rach.msgANumPosFdm, flags.rach.msgAGuardBand, bwpSize))
+	}
+
+	if flags.advanced.msgAPreamb < 0 || flags.advanced.msgAPreamb >= flags.rach.cbPreambsPerSsb {
+		return errors.New(fmt.Sprintf("Invalid msgAPreamb(=%v), which must be less than cbPreambsPerSsb(=%v)!", flags.advanced.msgAPreamb, flags.rach.cbPreambsPerSsb))
+	}
+
+	// refer to 38.211 vh40 6.4.1.1.3
+	// ld is the duration between the first OFDM symbol of the slot and the last OFDM symbol of the scheduled PUSCH resources in the slot for PUSCH mapping type A according to Tables 6.4.1.1.3-3 and 6.4.1.1.3-4 if intra-slot frequency hopping is not used, or
+	// ld is the duration of scheduled PUSCH resources for PUSCH mapping type B according to Tables 6.4.1.1.3-3 and 6.4.1.1.3-4 if intra-slot frequency hopping is not used
+	ld := L
+	if flags.rach.msgAMappingType == "typeA" {
+		ld = S + L
+	}
+	key := fmt.Sprintf("%v_%v_%v", ld, flags.rach.msgAMappingType, flags.rach.msgADmrsAddPos)
+	v, exist := nrgrid.DmrsPuschPosOneSymbWoIntraSlotFh[key]
+	if !exist || v == nil {
+		return errors.New(fmt.Sprintf("Invalid key(=%v) when referring DmrsPuschPosOneSymbWoIntraSlotFh!", key))
+	}
+
+	// refer to 3GPP TS 38.214 vh40
+	// 6.1.4.2	Transport block size determination
+	// For Msg3 or MsgA PUSCH transmission the N_PRB_oh is always set to 0.
+	// Note: single symbol front-loaded DMRS of configuration type 1 with two CDM groups without data is assumed for MsgA PUSCH.
+	dmrsOh := (2 * 2) * len(v)
+	tbs, err := getTbs("PUSCH", false, "MSGA", "qam64", L, flags.rach.msgANumRbs, flags.rach.msgAMcs, 1, dmrsOh, 0, 1)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("MsgA PUSCH: S=%v, L=%v, DMRS key=%v, val=%v, TBS=%v bits\n", S, L, key, v, tbs)
+
+	// update RA_UL_MSGA of UL DCI
+	// Note: MsgA PUSCH is not scheduled by any UL grant, and its TDRA/FDRA/MCS are provided by MsgA-PUSCH-Resource-r16, where K2 is msgA-PUSCH-TimeDomainOffset-r16 relative to the start of PRACH slot.
+	flags.uldci.tdra[RA_UL_MSGA] = -1
+	flags.uldci._tdMappingType[RA_UL_MSGA] = flags.rach.msgAMappingType
+	flags.uldci._tdK2[RA_UL_MSGA] = flags.rach.msgAPuschTimeOffset
+	flags.uldci._tdSliv[RA_UL_MSGA] = flags.rach.msgAStartSymbAndLength
+	flags.uldci._tdStartSymb[RA_UL_MSGA] = S
+	flags.uldci._tdNumSymbs[RA_UL_MSGA] = L
+	flags.uldci.fdFreqHop[RA_UL_MSGA] = "disabled"
+	flags.uldci._fdRa[RA_UL_MSGA] = ""
+	flags.uldci.fdStartRb[RA_UL_MSGA] = flags.rach.msgAFreqStart
+	flags.uldci.fdNumRbs[RA_UL_MSGA] = flags.rach.msgANumRbs
+	flags.uldci.mcsCw0[RA_UL_MSGA] = flags.rach.msgAMcs
+	flags.uldci._tbs[RA_UL_MSGA] = tbs
+
+	return nil
+}
+
+// convert ARFCN to F_REF(MHz) (refer to 38.104 vh80)
+//  Table 5.4.2.1-1: NR-ARFCN parameters for the global frequency raster
+func arfcn2Fref(arfcn int, maxFreq int) float64 {
+	if maxFreq < 3000 {
+		return float64(arfcn) * 0.005
+	} else if maxFreq < 24250 {
+		return 3000 + 0.015*(float64(arfcn)-600000)
+	} else {
+		return 24250.08 + 0.06*(float64(arfcn)-2016667)
+	}
+}
+
+// convert GSCN to SS_REF(MHz) (refer to 38.104 vh80)
+//  Table 5.4.3.1-1: GSCN parameters for the global frequency raster
+func gscn2Ssref(gscn int, maxFreq int) float64 {
+	if maxFreq < 3000 {
+		N := math.Floor((float64(gscn) + 1.5) / 3)
+		M := math.Mod(float64(gscn)+1.5, 3) * 2
+		ssRef := 1.2*N + 0.05*M
+
+		fmt.Printf("GSCN=%v, N=%v, M=%v, SS_REF=%vMHz\n", gscn, N, M, ssRef)
+		return ssRef
+	} else if maxFreq < 24250 {
+		N := gscn - 7499
+		return 3000 + 1.44*float64(N)
+	} else {
+		N := gscn - 22256
+		return 24250.08 + 17.28*float64(N)
+	}
+}
+
+// updateSupCarrier updates the duplex mode, the carrierBandwidth and the BWPs of the supplementary carrier(SUL or SDL).
+func updateSupCarrier() error {
+	band := flags.gridsetting.supBand
+	if len(band) == 0 {
+		flags.gridsetting._supDuplexMode = ""
+		return nil
+	}
+
+	p, exist := nrgrid.OpBands[band]
+	if !exist || (p.DuplexMode != "SUL" && p.DuplexMode != "SDL") {
+		return errors.New(fmt.Sprintf("Invalid supplementary band(=%v), which must be a SUL or SDL band!", band))
+	}
+	if p.DuplexMode == "SUL" {
+		fmt.Printf("Supplementary Band Info [%v]: UL: %v, %v\n", band, p.UlBand, p.DuplexMode)
+	} else {
+		fmt.Printf("Supplementary Band Info [%v]: DL: %v, %v\n", band, p.DlBand, p.DuplexMode)
+	}
+	flags.gridsetting._supDuplexMode = p.DuplexMode
+
+	// refer to 3GPP 38.101-1 vh80
+	// Table 5.3.5-1: Channel bandwidths for each NR band
+	// Note: the supplementary carrier has its own subcarrierSpacing(e.g. 15KHz of n80/n84 SUL paired with 30KHz of a TDD carrier), which is the subcarrierSpacing of the carrier if supScs is not configured.
+	flags.gridsetting._supScs = flags.gridsetting._carrierScs
+	if len(flags.gridsetting.supScs) > 0 {
+		if _, exist := nrgrid.Scs2Mu[flags.gridsetting.supScs]; !exist {
+			return errors.New(fmt.Sprintf("Invalid subcarrierSpacing(supScs=%v) of the supplementary carrier!", flags.gridsetting.supScs))
+		}
+		flags.gridsetting._supScs = flags.gridsetting.supScs
+	}
+	scs, _ := strconv.Atoi(flags.gridsetting._supScs[:len(flags.gridsetting._supScs)-3])
+	idx := utils.IndexStr(nrgrid.BwSetFr1, flags.gridsetting.supBw)
+	bws, exist := nrgrid.BandScs2BwFr1[fmt.Sprintf("%v_%v", band, scs)]
+	if idx < 0 || !exist || bws[idx] == 0 {
+		return errors.New(fmt.Sprintf("Invalid bandwidth of the supplementary carrier: supBand=%v, supScs=%v, supBw=%v", band, flags.gridsetting._supScs, flags.gridsetting.supBw))
+	}
+	flags.gridsetting._supCarrierNumRbs = nrgrid.NrbFr1[scs][idx]
+
+	// update RB_Start and L_RB for initial and dedicated BWP of the supplementary carrier
+	flags.bwp._supBwpStartRb = []int{0, 0}
+	flags.bwp._supBwpNumRbs = []int{flags.gridsetting._supCarrierNumRbs, flags.gridsetting._supCarrierNumRbs}
+
+	return nil
+}
+
+// getSymbPerSlot returns the number of OFDM symbols per slot with cyclic prefix cp, which is 12 for extended CP and 14 for normal CP.
+// Note: the initial/dedicated DL/UL BWPs share the NR resource grid of the carrier and have the same cyclic prefix(validated by validateBwpCp).
+func getSymbPerSlot(cp string) int {
+	if cp == "extended" {
+		return 12
+	}
+
+	return 14
+}
+
+// validateBwpCp validates the cyclic prefix of initial/dedicated DL/UL BWPs.
+func validateBwpCp() error {
+	regYellow.Printf("-->calling validateBwpCp\n")
+
+	for i, cp := range flags.bwp._bwpCp {
+		if cp != "normal" && cp != "extended" {
+			return errors.New(fmt.Sprintf("Invalid cyclicPrefix(=%v) of BWP(bwpType=%v), which must be normal or extended!", cp, flags.bwp._bwpType[i]))
+		}
+
+		// refer to 3GPP 38.331 vh30
+		// BWP: cyclicPrefix - Indicates whether to use the extended cyclic prefix for this bandwidth part. If not set, the UE uses the normal cyclic prefix. Normal CP is supported for all numerologies and slot formats. Extended CP is supported only for 60 kHz subcarrier spacing. (see TS 38.211 [16], clause 4.2)
+		if cp == "extended" && flags.bwp._bwpScs[i] != "60KHz" {
+			return errors.New(fmt.Sprintf("Extended cyclic prefix of BWP(bwpType=%v) is only supported for 60KHz SCS(bwpScs=%v)!", flags.bwp._bwpType[i], flags.bwp._bwpScs[i]))
+		}
+
+		// Note: the initial DL/UL BWPs and the dedicated DL/UL BWP(bwp-Id 1) are mapped onto the NR resource grid of the carrier, which has a single slot length.
+		if cp != flags.bwp._bwpCp[DED_DL_BWP] {
+			return errors.New(fmt.Sprintf("The initial and dedicated DL/UL BWPs must have the same cyclic prefix(bwpCp=%v)!", flags.bwp._bwpCp))
+		}
+	}
+
+	return nil
+}
+
+// validateSsbPosInBurst validates ssb-PositionsInBurst, and updates candSsbIndex with the SSB indexes of transmitted SSBs if ssb-PositionsInBurst is configured.
+func validateSsbPosInBurst() error {
+	regYellow.Printf("-->calling validateSsbPosInBurst\n")
+
+	// refer to 3GPP 38.331 vh30
+	// ServingCellConfigCommon: ssb-PositionsInBurst - Indicates the time domain positions of the transmitted SS-blocks in a half frame with SS/PBCH blocks as defined in TS 38.213 [13], clause 4.1. The first/leftmost bit corresponds to SS/PBCH block index 0, the second bit corresponds to SS/PBCH block index 1, and so on. Value 0 in the bitmap indicates that the corresponding SS/PBCH block is not transmitted while value 1 indicates that the corresponding SS/PBCH block is transmitted.
+	//  shortBitmap: Bitmap for operation where L_max = 4; mediumBitmap: Bitmap for operation where L_max = 8; longBitmap: Bitmap for operation where L_max = 64
+	// ServingCellConfigCommonSIB: ssb-PositionsInBurst - inOneGroup: When maximum number of SS/PBCH blocks per half frame equals to 4 as defined in TS 38.213 [13], clause 4.1, only the leftmost 4 bits are valid; the UE ignores the 4 rightmost bits. When maximum number of SS/PBCH blocks per half frame equals to 8 as defined in TS 38.213 [13], clause 4.1, all 8 bits are valid. The first/ leftmost bit corresponds to the SS/PBCH index 0, the second bit corresponds to SS/PBCH block index 1, and so on. Value 0 in the bitmap indicates that the corresponding SS/PBCH block is not transmitted while value 1 indicates that the corresponding SS/PBCH block is transmitted. When maximum number of SS/PBCH blocks per half frame equals to 64 as defined in TS 38.213 [13], clause 4.1, all 8 bit are valid; The first/ leftmost bit corresponds to the first SS/PBCH block index in the group (i.e., to SSB index 0, 8, and so on); the second bit corresponds to the second SS/PBCH block index in the group (i.e., to SSB index 1, 9, and so on), and so on.
+	//  groupPresence: This field is present when maximum number of SS/PBCH blocks per half frame equals to 64 as defined in TS 38.213 [13], clause 4.1. The first/leftmost bit corresponds to the SS/PBCH index 0-7, the second bit corresponds to SS/PBCH block 8-15, and so on.
+	maxL := flags.gridsetting._maxL
+	isBitmap := func(bits string) bool {
+		return len(strings.Trim(bits, "01")) == 0
+	}
+
+	var ssbs []int
+	if len(flags.gridsetting.ssbPosInBurst) > 0 {
+		bits := flags.gridsetting.ssbPosInBurst
+		if !isBitmap(bits) || len(bits) != maxL {
+			return errors.New(fmt.Sprintf("Invalid ssbPosInBurst(=%v), which must be a bitmap of %v bits when L_max is %v!", bits, maxL, maxL))
+		}
+
+		for i, c := range bits {
+			if c == '1' {
+				ssbs = append(ssbs, i)
+			}
+		}
+	} else if len(flags.gridsetting.ssbInOneGroup) > 0 {
+		inOneGroup := flags.gridsetting.ssbInOneGroup
+		groupPresence := flags.gridsetting.ssbGroupPresence
+		if !isBitmap(inOneGroup) || len(inOneGroup) != 8 {
+			return errors.New(fmt.Sprintf("Invalid ssbInOneGroup(=%v), which must be a bitmap of 8 bits!", inOneGroup))
+		}
+		if maxL == 64 && (!isBitmap(groupPresence) || len(groupPresence) != 8) {
+			return errors.New(fmt.Sprintf("Invalid ssbGroupPresence(=%v), which must be a bitmap of 8 bits when L_max is 64!", groupPresence))
+		}
+		if maxL != 64 && len(groupPresence) > 0 {
+			return errors.New(fmt.Sprintf("The ssbGroupPresence(=%v) can be configured only when L_max is 64!", groupPresence))
+		}
+
+		numGroups := 1
+		if maxL == 64 {
+			numGroups = 8
+		}
+		for g := 0; g < numGroups; g++ {
+			if maxL == 64 && groupPresence[g] != '1' {
+				continue
+			}
+			for i, c := range inOneGroup[:utils.MinInt([]int{maxL, 8})] {
+				if c == '1' {
+					ssbs = append(ssbs, 8*g+i)
+				}
+			}
+		}
+	}
+
+	if ssbs != nil {
+		flags.gridsetting.candSsbIndex = ssbs
+		fmt.Printf("candSsbIndex is updated according to ssb-PositionsInBurst: %v\n", flags.gridsetting.candSsbIndex)
+	}
+
+	if len(flags.gridsetting.candSsbIndex) == 0 {
+		return errors.New(fmt.Sprintf("At least one SSB must be transmitted!"))
+	}
+	for i, issb := range flags.gridsetting.candSsbIndex {
+		if issb < 0 || issb >= maxL || utils.IndexInt(flags.gridsetting.candSsbIndex, issb) != i {
+			return errors.New(fmt.Sprintf("Invalid candSsbIndex(=%v), which must be unique SSB indexes within [0, %v]!", flags.gridsetting.candSsbIndex, maxL-1))
+		}
+	}
+
+	return nil
+}
+
+// validateSupCarrier validates the supplementary carrier(SUL or SDL), and updates the nominal RBG size and FDRA bits of DCI 0_1(SUL) or DCI 1_1(SDL) if the supplementary carrier is used.
+func validateSupCarrier() error {
+	regYellow.Printf("-->calling validateSupCarrier\n")
+
+	band := flags.gridsetting.supBand
+	if len(band) == 0 {
+		if flags.gridsetting.supUsed {
+			return errors.New("The supplementary carrier can be used only if supBand is configured!")
+		}
+		return updateSupScs()
+	}
+
+	p, exist := nrgrid.OpBands[band]
+	if !exist || p.DuplexMode != flags.gridsetting._supDuplexMode {
+		return errors.New(fmt.Sprintf("Invalid supplementary band(=%v) with duplex mode(=%v)!", band, flags.gridsetting._supDuplexMode))
+	}
+
+	// SUL and SDL bands are FR1 bands, which are paired with a TDD or FDD band of FR1.
+	if flags.gridsetting._freqRange != "FR1" || (flags.gridsetting._duplexMode != "TDD" && flags.gridsetting._duplexMode != "FDD") {
+		return errors.New(fmt.Sprintf("The supplementary band(=%v) can only be paired with a TDD or FDD band of FR1(band=%v, duplexMode=%v, freqRange=%v)!", band, flags.gridsetting.band, flags.gridsetting._duplexMode, flags.gridsetting._freqRange))
+	}
+
+	fr := p.UlBand
+	if p.DuplexMode == "SDL" {
+		fr = p.DlBand
+	}
+	fmin, fmax, err := parseFreqRange(fr)
+	if err != nil {
+		return err
+	}
+	freq := arfcn2Fref(flags.gridsetting.supArfcn, int(fmax))
+	if freq < fmin || freq > fmax {
+		return errors.New(fmt.Sprintf("Invalid supArfcn(=%v, F_REF=%vMHz), which is out of the supplementary band(%v: %v)!", flags.gridsetting.supArfcn, freq, band, fr))
+	}
+
+	// refer to 3GPP 38.101-1 vh80
+	// Table 5.3.5-1: Channel bandwidths for each NR band
+	// Note: gridSup has the dimensions of the supplementary carrier, i.e. its subcarrierSpacing and carrierBandwidth.
+	scs, _ := strconv.Atoi(flags.gridsetting._supScs[:len(flags.gridsetting._supScs)-3])
+	if _, exist := nrgrid.BandScs2BwFr1[fmt.Sprintf("%v_%v", band, scs)]; !exist {
+		return errors.New(fmt.Sprintf("Invalid subcarrierSpacing(=%v) of the supplementary carrier, which is not supported by the supplementary band(=%v)!", flags.gridsetting._supScs, band))
+	}
+
+	// refer to 3GPP 38.331 vh30 SCS-SpecificCarrier
+	// offsetToCarrier: Offset in frequency domain between Point A (lowest subcarrier of common RB 0) and the lowest usable subcarrier on this carrier in number of PRBs (using the subcarrierSpacing defined for this carrier). The maximum value corresponds to 275*8-1.
+	if flags.gridsetting.supOffsetToCarrier < 0 || flags.gridsetting.supOffsetToCarrier > 275*8-1 {
+		return errors.New(fmt.Sprintf("Invalid supOffsetToCarrier(=%v) of the supplementary carrier, which must be in the range of [0, %v]!", flags.gridsetting.supOffsetToCarrier, 275*8-1))
+	}
+
+	for _, i := range []int{SUP_INI_BWP, SUP_DED_BWP} {
+		if flags.bwp._supBwpStartRb[i] < 0 || flags.bwp._supBwpNumRbs[i] < 1 || flags.bwp._supBwpStartRb[i]+flags.bwp._supBwpNumRbs[i] > flags.gridsetting._supCarrierNumRbs {
+			return errors.New(fmt.Sprintf("Invalid BWP of the supplementary carrier: supBwpStartRb=%v, supBwpNumRbs=%v, while supCarrierNumRbs=%v!", flags.bwp._supBwpStartRb, flags.bwp._supBwpNumRbs, flags.gridsetting._supCarrierNumRbs))
+		}
+	}
+
+	if isSulUsed() {
+		_, n := getUlBwp(DED_UL_BWP)
+		flags.pusch._rbgSize = getNominalRbgSize(n, flags.pusch.puschRbgCfg)
+		flags.uldci._fdBitsRaType0 = utils.CeilInt(float64(n) / float64(flags.pusch._rbgSize))
+		flags.uldci._fdBitsRaType1[DCI_01_PUSCH] = utils.CeilInt(math.Log2(float64(n) * (float64(n) + 1) / 2))
+		updateDedFdra()
+		updateDedPucchPrbs()
+		fmt.Printf("UL carrier: SUL(band=%v, scs=%v, N_RB=%v, offsetToCarrier=%v), RACH/PUCCH/PUSCH/SRS are transmitted on SUL carrier.\n", band, flags.gridsetting._supScs, flags.gridsetting._supCarrierNumRbs, flags.gridsetting.supOffsetToCarrier)
+	} else if isSdlUsed() {
+		_, n := getPdschBwp()
+		flags.pdsch._rbgSize = getNominalRbgSize(n, flags.pdsch.pdschRbgCfg)
+		flags.dldci._fdBitsRaType0 = utils.CeilInt(float64(n) / float64(flags.pdsch._rbgSize))
+		flags.dldci._fdBitsRaType1[DCI_11_PDSCH] = utils.CeilInt(math.Log2(float64(n) * (float64(n) + 1) / 2))
+		updateDedFdra()
+		fmt.Printf("DL carrier: SDL(band=%v, scs=%v, N_RB=%v, offsetToCarrier=%v), PDSCH scheduled by DCI 1_1 is received on SDL carrier.\n", band, flags.gridsetting._supScs, flags.gridsetting._supCarrierNumRbs, flags.gridsetting.supOffsetToCarrier)
+	}
+
+	return updateSupScs()
+}
+
+// updateSupScs updates u_PUSCH, Δ and K2 of PUSCH TDRA and subcarrierSpacing of initial/dedicated UL BWP with the subcarrierSpacing of the UL carrier, and u_PDSCH of DCI 1_1 with the subcarrierSpacing of the DL carrier for PDSCH, which are those of the supplementary carrier if SUL or SDL is used.
+func updateSupScs() error {
+	ulScs := getUlScs()
+	changed := flags.bwp._bwpScs[DED_UL_BWP] != ulScs
+	for i := range flags.uldci._muPusch {
+		changed = changed || flags.uldci._muPusch[i] != nrgrid.Scs2Mu[ulScs]
+		flags.uldci._muPusch[i] = nrgrid.Scs2Mu[ulScs]
+	}
+	flags.bwp._bwpScs[INI_UL_BWP] = ulScs
+	flags.bwp._bwpScs[DED_UL_BWP] = ulScs
+
+	flags.dldci._muPdsch[DCI_11_PDSCH] = nrgrid.Scs2Mu[flags.gridsetting._mibCommonScs]
+	if isSdlUsed() {
+		flags.dldci._muPdsch[DCI_11_PDSCH] = nrgrid.Scs2Mu[flags.gridsetting._supScs]
+	}
+
+	// refer to 3GPP 38.214 vh40
+	// 6.1.2.1.1	Determination of the resource allocation table to be used for PUSCH
+	// Table 6.1.2.1.1-4: Definition of value j
+	// Note: j and Δ are determined by u_PUSCH, hence K2 of Msg3 PUSCH and PUSCH scheduled by DCI 0_1 are re-validated if u_PUSCH is changed.
+	if !changed {
+		return nil
+	}
+	return validatePusch()
+}
+
+// parseFreqRange returns the lower and upper frequency(MHz) of frequency range such as "1710 MHz-1785 MHz" in nrgrid.OpBands.
+func parseFreqRange(fr string) (float64, float64, error) {
+	tokens := strings.FieldsFunc(strings.ReplaceAll(fr, "MHz", ""), func(r rune) bool {
+		return r == '-' || r == '–' || r == ' '
+	})
+	if len(tokens) != 2 {
+		return 0, 0, errors.New(fmt.Sprintf("Invalid frequency range: %v", fr))
+	}
+
+	fmin, err := strconv.ParseFloat(tokens[0], 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	fmax, err := strconv.ParseFloat(tokens[1], 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return fmin, fmax, nil
+}
+
+// isSulUsed returns whether RACH/PUCCH/PUSCH/SRS are transmitted on SUL carrier.
+func isSulUsed() bool {
+	return flags.gridsetting.supUsed && len(flags.gridsetting.supBand) > 0 && flags.gridsetting._supDuplexMode == "SUL"
+}
+
+// isSdlUsed returns whether PDSCH scheduled by DCI 1_1 is received on SDL carrier.
+func isSdlUsed() bool {
+	return flags.gridsetting.supUsed && len(flags.gridsetting.supBand) > 0 && flags.gridsetting._supDuplexMode == "SDL"
+}
+
+// getNrb returns the transmission bandwidth configuration N_RB of the channel bandwidth and SCS, which is 0 if not supported.
+//  fr: frequency range, which can be FR1, FR2-1 or FR2-2
+//  bw: channel bandwidth, e.g. 100MHz
+//  scs: subcarrier spacing in KHz
+func getNrb(fr, bw string, scs int) int {
+	bwSet := map[string][]string{"FR1": nrgrid.BwSetFr1, "FR2-1": nrgrid.BwSetFr21, "FR2-2": nrgrid.BwSetFr22}[fr]
+	nrbSet := map[string]map[int][]int{"FR1": nrgrid.NrbFr1, "FR2-1": nrgrid.NrbFr21, "FR2-2": nrgrid.NrbFr22}[fr][scs]
+	idx := utils.IndexStr(bwSet, bw)
+	if idx < 0 || idx >= len(nrbSet) {
+		return 0
+	}
+
+	return nrbSet[idx]
+}
+
+// getUlBwp returns RB_start and L_RBs of initial or dedicated UL BWP, which are BWPs of SUL carrier if SUL is used.
+//  tag: INI_UL_BWP or DED_UL_BWP
+func getUlBwp(tag int) (int, int) {
+	if isSulUsed() {
+		i := map[int]int{INI_UL_BWP: SUP_INI_BWP, DED_UL_BWP: SUP_DED_BWP}[tag]
+		return flags.bwp._supBwpStartRb[i], flags.bwp._supBwpNumRbs[i]
+	}
+
+	return flags.bwp._bwpStartRb[tag], flags.bwp._bwpNumRbs[tag]
+}
+
+// getPdschBwp returns RB_start and L_RBs of dedicated DL BWP for PDSCH scheduled by DCI 1_1, which is BWP of SDL carrier(cross-carrier scheduling) if SDL is used.
+func getPdschBwp() (int, int) {
+	if isSdlUsed() {
+		return flags.bwp._supBwpStartRb[SUP_DED_BWP], flags.bwp._supBwpNumRbs[SUP_DED_BWP]
+	}
+
+	return flags.bwp._bwpStartRb[DED_DL_BWP], flags.bwp._bwpNumRbs[DED_DL_BWP]
+}
+
+// getUlCarrier returns offsetToCarrier and carrierBandwidth of UL carrier, which is SUL carrier if SUL is used.
+func getUlCarrier() (int, int) {
+	if isSulUsed() {
+		return flags.gridsetting.supOffsetToCarrier, flags.gridsetting._supCarrierNumRbs
+	}
+
+	return flags.gridsetting._offsetToCarrier, flags.gridsetting._carrierNumRbs
+}
+
+// getPdschCarrier returns offsetToCarrier and carrierBandwidth of DL carrier for PDSCH scheduled by DCI 1_1, which is SDL carrier if SDL is used.
+func getPdschCarrier() (int, int) {
+	if isSdlUsed() {
+		return flags.gridsetting.supOffsetToCarrier, flags.gridsetting._supCarrierNumRbs
+	}
+
+	return flags.gridsetting._offsetToCarrier, flags.gridsetting._carrierNumRbs
+}
+
+// getUlScs returns the subcarrierSpacing of the UL carrier, which is the subcarrierSpacing of SUL carrier if SUL is used.
+func getUlScs() string {
+	if isSulUsed() {
+		return flags.gridsetting._supScs
+	}
+
+	return flags.gridsetting._carrierScs
+}
+
+// getUlDims returns the number of slots per radio frame, subcarriers per symbol and subcarriers per slot of the UL resource grid, which are those of SUL carrier if SUL is used.
+func getUlDims() (int, int, int) {
+	if isSulUsed() {
+		return rgd.supSlotPerRf, rgd.supScPerSymb, rgd.supScPerSlot
+	}
+
+	return rgd.slotPerRf, rgd.scPerSymb, rgd.scPerSlot
+}
+
+// getPdschDims returns the number of slots per radio frame, subcarriers per symbol and subcarriers per slot of the resource grid of PDSCH scheduled by DCI 1_1, which are those of SDL carrier if SDL is used.
+func getPdschDims() (int, int, int) {
+	if isSdlUsed() {
+		return rgd.supSlotPerRf, rgd.supScPerSymb, rgd.supScPerSlot
+	}
+
+	return rgd.slotPerRf, rgd.scPerSymb, rgd.scPerSlot
+}
+
+// getCarrierSlot returns the radio frame and the last slot of the carrier which overlaps with slot(of the UL carrier) of radio frame sfn.
+func getCarrierSlot(sfn, slot int) (int, int) {
+	ulSlotPerRf, _, _ := getUlDims()
+	n := getOverlapSlot(sfn*ulSlotPerRf+slot, ulSlotPerRf, rgd.slotPerRf, true)
+	return n / rgd.slotPerRf, n % rgd.slotPerRf
+}
+
+// getOverlapSlot returns the first(or the last if last is true) slot of numerology 2 overlapping with slot m of numerology 1, where slots are numbered from SFN 0.
+//  slotPerRf1/slotPerRf2: number of slots per radio frame of numerology 1/2
+func getOverlapSlot(m, slotPerRf1, slotPerRf2 int, last bool) int {
+	if last {
+		return ((m+1)*slotPerRf2+slotPerRf1-1)/slotPerRf1 - 1
+	}
+
+	return m * slotPerRf2 / slotPerRf1
+}
+
+// getRbSets returns RB sets of NR-U carrier within the BWP, where each element is [first PRB(relative to the lowest RB of the BWP), number of RBs].
+//  bwpStart: RB_start of the BWP
+//  bwpSize: L_RBs of the BWP
+func getRbSets(bwpStart, bwpSize int) [][]int {
+	// refer to 3GPP 38.214 vh40
+	// 7	Operation with shared spectrum channel access
+	// A UE is provided N_RB_set_x - 1 intra-cell guard bands on a carrier, each defined by a start CRB and a number of CRBs, which separates RB sets of the carrier. The RB sets of the BWP are the RB sets of the carrier which are contained in the BWP.
+	var rbSets [][]int
+	gb := flags.gridsetting._intraCellGuardBands
+	start := 0
+	for i := 0; i <= len(gb)/2; i++ {
+		end := flags.gridsetting._carrierNumRbs
+		if i < len(gb)/2 {
+			end = gb[2*i]
+		}
+
+		first := utils.MaxInt([]int{start, bwpStart})
+		last := utils.MinInt([]int{end, bwpStart + bwpSize})
+		if last > first {
+			rbSets = append(rbSets, []int{first - bwpStart, last - first})
+		}
+
+		if i < len(gb)/2 {
+			start = gb[2*i] + gb[2*i+1]
+		}
+	}
+
+	return rbSets
+}
+
+// getNumInterlaces returns the number of RB interlaces M of the carrier, which is 0 if interlaced transmission is not applicable.
+func getNumInterlaces() int {
+	// refer to 3GPP 38.211 vh40
+	// 4.4.4.6	Interlaced resource blocks: Table 4.4.4.6-1: The number of resource block interlaces.
+	m := map[string]int{"15KHz": 10, "30KHz": 5}
+	return m[flags.gridsetting._carrierScs]
+}
+
+// getInterlacedPrbs returns the PRBs(relative to the lowest RB of the BWP) of the interlaces within the RB sets.
+//  bwpStart: RB_start of the BWP
+//  bwpSize: L_RBs of the BWP
+//  interlaces: list of interlace indexes
+//  rbSetStart: index of the first RB set
+//  numRbSets: number of consecutive RB sets
+func getInterlacedPrbs(bwpStart, bwpSize int, interlaces []int, rbSetStart, numRbSets int) ([]int, error) {
+	M := getNumInterlaces()
+	if M == 0 {
+		return nil, errors.New(fmt.Sprintf("Interlaced transmission is only applicable to 15KHz and 30KHz SCS!(carrierScs=%v)", flags.gridsetting._carrierScs))
+	}
+
+	for _, m := range interlaces {
+		if m < 0 || m >= M {
+			return nil, errors.New(fmt.Sprintf("Invalid interlace index(=%v), which must be within [0, %v]!", m, M-1))
+		}
+	}
+
+	rbSets := getRbSets(bwpStart, bwpSize)
+	if rbSetStart < 0 || numRbSets < 1 || rbSetStart+numRbSets > len(rbSets) {
+		return nil, errors.New(fmt.Sprintf("Invalid RB sets(start=%v, num=%v) while only %v RB set(s) are available: %v", rbSetStart, numRbSets, len(rbSets), rbSets))
+	}
+
+	// refer to 3GPP 38.211 vh40
+	// 4.4.4.6	Interlaced resource blocks
+	// The relation between the interlaced resource block n_IRB_u_m in BWP i and interlace m and the common resource block n_CRB_u is given by n_CRB_u = M*n_IRB_u_m + N_start_BWP_i + ((m - N_start_BWP_i) mod M)
+	// refer to 3GPP 38.214 vh40
+	// 6.1.2.2.3	Uplink resource allocation type 2
+	// The RBs allocated are the intersection of the RBs of the indicated interlaces and the union of the indicated set of RB sets and intra-cell guard bands, if any, between the indicated RB sets.
+	first := rbSets[rbSetStart][0]
+	last := rbSets[rbSetStart+numRbSets-1][0] + rbSets[rbSetStart+numRbSets-1][1]
+	var prbs []int
+	for rb := first; rb < last; rb++ {
+		if utils.ContainsInt(interlaces, (bwpStart+rb+flags.gridsetting._offsetToCarrier)%M) {
+			prbs = append(prbs, rb)
+		}
+	}
+
+	return prbs, nil
+}
+
+// getSsbQcl returns N_SSB_QCL of NR-U, which is provided by ssb-PositionQCL.
+func getSsbQcl() int {
+	q, _ := strconv.Atoi(flags.gridsetting.ssbPositionQcl[1:])
+	return q
+}
+
+// getDbWinNumCands returns the number of candidate SSB positions within the discovery burst transmission window.
+func getDbWinNumCands() int {
+	// refer to 3GPP 38.213 vh40
+	// 4.1	Cell search
+	// For operation with shared spectrum channel access, ... a UE can be provided a duration of a discovery burst transmission window by DiscoveryBurst-WindowLength-r16 ... the UE determines the number of candidate SS/PBCH block positions within the discovery burst transmission window based on the SCS of the SS/PBCH blocks.
+	winLen, _ := strconv.ParseFloat(strings.Replace(flags.gridsetting.dbWinLen[2:], "dot", ".", 1), 64)
+	ssbScs, _ := strconv.Atoi(flags.gridsetting._ssbScs[:len(flags.gridsetting._ssbScs)-3])
+	return utils.MinInt([]int{int(winLen * float64(ssbScs) / 15 * 2), flags.gridsetting._maxLBar})
+}
+
+// getSsbCands returns candidate SSB indexes which are transmitted within a half frame.
+func getSsbCands() []int {
+	if !flags.gridsetting._unlicensed {
+		return flags.gridsetting.candSsbIndex
+	}
+
+	// refer to 3GPP 38.213 vh40
+	// 4.1	Cell search
+	// For operation with shared spectrum channel access, ... SS/PBCH blocks in a serving cell that are within a same discovery burst transmission window or across discovery burst transmission windows are assumed to be quasi co-located ... if a value of (i_bar mod N_SSB_QCL) is same among the SS/PBCH blocks, where i_bar is the candidate SS/PBCH block index.
+	// A UE assumes that a number of transmitted SS/PBCH blocks within a discovery burst transmission window on a serving cell is not larger than N_SSB_QCL.
+	// Note: The first lbtFailCands candidate SSB positions are assumed to be lost due to LBT failure.
+	q := getSsbQcl()
+	var cands, ssbs []int
+	for i := flags.advanced.lbtFailCands; i < getDbWinNumCands(); i++ {
+		if utils.ContainsInt(flags.gridsetting.candSsbIndex, i%q) && !utils.ContainsInt(ssbs, i%q) {
+			cands = append(cands, i)
+			ssbs = append(ssbs, i%q)
+		}
+	}
+
+	return cands
+}
+
+// getSsbIndex returns the SSB index of the candidate SSB index.
+func getSsbIndex(cand int) int {
+	if flags.gridsetting._unlicensed {
+		return cand % getSsbQcl()
+	}
+
+	return cand
+}
+
+// calculate N_CRB_SSB and k_SSB given GSCN and DL ARFCN
+func updateKSsbAndNCrbSsb() error {
+	regYellow.Printf("-->calling updateKSsbAndNCrbSsb\n")
+
+	ssbScs, _ := strconv.ParseFloat(flags.gridsetting._ssbScs[:len(flags.gridsetting._ssbScs)-3], 64)
+	carrierScs, _ := strconv.ParseFloat(flags.gridsetting._carrierScs[:len(flags.gridsetting._carrierScs)-3], 64)
+	rmsiScs, _ := strconv.ParseFloat(flags.gridsetting._mibCommonScs[:len(flags.gridsetting._mibCommonScs)-3], 64)
+
+	if flags.gridsetting._freqRange == "FR1" {
+		flags.gridsetting._kSsbScs = 15
+		flags.gridsetting._nCrbSsbScs = 15
+	} else if flags.gridsetting._freqRange == "FR2-1" {
+		flags.gridsetting._kSsbScs = rmsiScs
+		flags.gridsetting._nCrbSsbScs = 60
+	} else if flags.gridsetting._freqRange == "FR2-2" {
+		flags.gridsetting._kSsbScs = ssbScs
+		flags.gridsetting._nCrbSsbScs = 60
+	}
+
+	// refer to 3GPP 38.104 vh80
+	// Table 5.4.3.3-1: Applicable SS raster entries per operating band (FR1)
+	// Note: GSCN and DL ARFCN are validated for NR-U only, for which the SSB candidates are determined by the discovery burst transmission window.
+	if flags.gridsetting._unlicensed {
+		if err := validateNruRaster(); err != nil {
+			return err
+		}
+	}
+
+	ssFreq := gscn2Ssref(flags.gridsetting.gscn, flags.gridsetting._maxDlFreq)
+	ssFreqSc0Rb0 := ssFreq - 120*ssbScs/1000
+
+	dlFreq := arfcn2Fref(flags.gridsetting.dlArfcn, flags.gridsetting._maxDlFreq)
+	var dlFreqPointA float64
+	if flags.gridsetting._carrierNumRbs%2 == 0 {
+		dlFreqPointA = dlFreq - 12*float64(flags.gridsetting._carrierNumRbs/2)*carrierScs/1000
+	} else {
+		dlFreqPointA = dlFreq - 12*(math.Floor(float64(flags.gridsetting._carrierNumRbs)/2)+6)*carrierScs/1000
+	}
+
+	nCrbSsb := math.Floor((ssFreqSc0Rb0 - dlFreqPointA) / (12 * flags.gridsetting._nCrbSsbScs / 1000))
+	kSsb := (ssFreqSc0Rb0 - dlFreqPointA - 12*flags.gridsetting._nCrbSsbScs/1000*nCrbSsb) / (flags.gridsetting._kSsbScs / 1000)
+
+	fmt.Printf("%v: nCrbSsb SCS=%.0fKHz, kSsb SCS=%.0fKHz\n", flags.gridsetting._freqRange, flags.gridsetting._nCrbSsbScs, flags.gridsetting._kSsbScs)
+	fmt.Printf("ssFreq=%vMHz, ssFreqSc0Rb0=%vMHz, dlFreq=%vMHz, dlFreqPointA=%vMHz, nCrbSsb=%v, kSsb=%v\n",
+		ssFreq, ssFreqSc0Rb0, dlFreq, dlFreqPointA, nCrbSsb, kSsb)
+
+	flags.gridsetting._nCrbSsb = int(nCrbSsb)
+	flags.gridsetting._kSsb = int(math.Ceil(kSsb))
+
+	// the SSB must be within the carrier
+	if ssFreqSc0Rb0 < dlFreqPointA || ssFreqSc0Rb0+240*ssbScs/1000 > dlFreqPointA+12*float64(flags.gridsetting._carrierNumRbs)*carrierScs/1000 {
+		return errors.New(fmt.Sprintf("The SSB(gscn=%v, SS_REF=%vMHz) is out of the carrier(dlArfcn=%v, F_REF=%vMHz, carrierBandwidth=%v RBs of %vKHz)!", flags.gridsetting.gscn, ssFreq, flags.gridsetting.dlArfcn, dlFreq, flags.gridsetting._carrierNumRbs, carrierScs))
+	}
+
+	return nil
+}
+
+// validateNruRaster validates that GSCN is an applicable SS raster entry of the NR-U band for the SSB SCS, and that both SS_REF of GSCN and F_REF of DL ARFCN are within the band.
+func validateNruRaster() error {
+	band := flags.gridsetting.band
+	valid := false
+	for _, v := range nrgrid.SsbRasters[band] {
+		if v[0] == flags.gridsetting._ssbScs && isGscnInRaster(v[2], flags.gridsetting.gscn) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return errors.New(fmt.Sprintf("Invalid gscn(=%v), which is not an applicable SS raster entry of band %v for SSB SCS(=%v): %v", flags.gridsetting.gscn, band, flags.gridsetting._ssbScs, nrgrid.SsbRasters[band]))
+	}
+
+	fr := nrgrid.OpBands[band].DlBand
+	fmin, fmax, err := parseFreqRange(fr)
+	if err != nil {
+		return err
+	}
+	if ssFreq := gscn2Ssref(flags.gridsetting.gscn, flags.gridsetting._maxDlFreq); ssFreq < fmin || ssFreq > fmax {
+		return errors.New(fmt.Sprintf("Invalid gscn(=%v, SS_REF=%vMHz), which is out of the band(%v: %v)!", flags.gridsetting.gscn, ssFreq, band, fr))
+	}
+	if dlFreq := arfcn2Fref(flags.gridsetting.dlArfcn, flags.gridsetting._maxDlFreq); dlFreq < fmin || dlFreq > fmax {
+		return errors.New(fmt.Sprintf("Invalid dlArfcn(=%v, F_REF=%vMHz), which is out of the band(%v: %v)!", flags.gridsetting.dlArfcn, dlFreq, band, fr))
+	}
+
+	return nil
+}
+
+// isGscnInRaster returns whether gscn is an entry of the SS raster such as "8993 – <1> – 9530"(first – <step size> – last) or "9996, 10010, 10024"(list of GSCNs) in nrgrid.SsbRasters.
+func isGscnInRaster(raster string, gscn int) bool {
+	for _, entry := range strings.Split(raster, ",") {
+		tokens := strings.FieldsFunc(entry, func(r rune) bool {
+			return r == '–' || r == '-' || r == ' ' || r == '<' || r == '>'
+		})
+		var v []int
+		for _, token := range tokens {
+			k, err := strconv.Atoi(token)
+			if err != nil {
+				return false
+			}
+			v = append(v, k)
+		}
+
+		if (len(v) == 1 && gscn == v[0]) || (len(v) == 3 && v[1] > 0 && gscn >= v[0] && gscn <= v[2] && (gscn-v[0])%v[1] == 0) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateCoreset0() error {
+	regYellow.Printf("-->calling validateCoreset0\n")
+
+	band := flags.gridsetting.band
+	fr := flags.gridsetting._freqRange
+	ssbScs := flags.gridsetting._ssbScs
+	rmsiScs := flags.gridsetting._mibCommonScs
+
+	var ssbScsSet []string
+	var rmsiScsSet []string
+	for _, v := range nrgrid.SsbRasters[band] {
+		ssbScsSet = append(ssbScsSet, v[0])
+	}
+	if fr == "FR1" {
+		rmsiScsSet = append(rmsiScsSet, []string{"15KHz", "30KHz"}...)
+	} else if fr == "FR2-1" {
+		rmsiScsSet = append(rmsiScsSet, []string{"60KHz", "120KHz"}...)
+	} else if fr == "FR2-2" {
+		rmsiScsSet = ssbScsSet
+	}
+
+	if !(utils.ContainsStr(ssbScsSet, ssbScs) && utils.ContainsStr(rmsiScsSet, rmsiScs)) {
+		return errors.New(fmt.Sprintf("Invalid SSB SCS and/or RMSI SCS settings!\nSSB SCS range: %v and ssbScs=%v\nRMSI SCS range: %v and rmsiScs=%v\n", ssbScsSet, ssbScs, rmsiScsSet, rmsiScs))
+	}
+
+	// calculate minChBw
+	key := fmt.Sprintf("%v_%v", band, rmsiScs[:len(rmsiScs)-3])
+	var bwSubset []string
+	if fr == "FR1" {
+		for i, v := range nrgrid.BandScs2BwFr1[key] {
+			if v == 1 {
+				bwSubset = append(bwSubset, nrgrid.BwSetFr1[i])
+			}
+		}
+	} else if fr == "FR2-1" {
+		for i, v := range nrgrid.BandScs2BwFr21[key] {
+			if v == 1 {
+				bwSubset = append(bwSubset, nrgrid.BwSetFr21[i])
+			}
+		}
+	} else if fr == "FR2-2" {
+		for i, v := range nrgrid.BandScs2BwFr22[key] {
+			if v == 1 {
+				bwSubset = append(bwSubset, nrgrid.BwSetFr22[i])
+			}
+		}
+	}
+
+	if len(bwSubset) > 0 {
+		minChBw, _ = strconv.Atoi(bwSubset[0][:len(bwSubset[0])-3])
+		fmt.Printf("Available transmission bandwidth: %v\n", bwSubset)
+		fmt.Printf("Minimum transmission bandwidth is %v\n", bwSubset[0])
+	} else {
+		minChBw = -1
+		return errors.New(fmt.Sprintf("Invalid configurations for minChBw calculation: band=%v, freqRange=%v, rmsiScs=%v\n", band, fr, rmsiScs))
+	}
+
+	// validate coresetZero
+	key = fmt.Sprintf("%v_%v_%v", ssbScs[:len(ssbScs)-3], rmsiScs[:len(rmsiScs)-3], flags.gridsetting.rmsiCoreset0)
+	var p *nrgrid.Coreset0Info
+	var exist bool
+	if (band == "n79" || band == "n104") && ssbScs[:len(ssbScs)-3] == "30" && (rmsiScs[:len(rmsiScs)-3] == "15" || rmsiScs[:len(rmsiScs)-3] == "30") {
+		// 38.101-1 vh80 Table 5.2-1: NR operating bands in FR1
+		// NOTE 17: For this band, CORESET#0 values from Table 13-5 or Table 13-6 in [8, TS 38.213] are applied regardless of the minimum channel bandwidth.
+		p, exist = nrgrid.Coreset0Fr1MinChBw40m[key]
+	} else if flags.gridsetting._unlicensed && ssbScs[:len(ssbScs)-3] == "30" {
+		// refer to 3GPP 38.213 vh40
+		// 13	UE procedure for monitoring Type0-PDCCH CSS sets
+		// For operation with shared spectrum channel access, ... {SS/PBCH block, PDCCH} SCS {30, 30} kHz: Table 13-4A
+		p, exist = nrgrid.Coreset0Fr1SharedSpectrum[key]
+	} else if fr == "FR1" && utils.ContainsInt([]int{5, 10}, minChBw) {
+		p, exist = nrgrid.Coreset0Fr1MinChBw5m10m[key]
+	} else if fr == "FR1" && minChBw == 40 {
+		p, exist = nrgrid.Coreset0Fr1MinChBw40m[key]
+	} else if fr == "FR2-1" {
+		p, exist = nrgrid.Coreset0Fr21[key]
+	} else if fr == "FR2-2" {
+		p, exist = nrgrid.Coreset0Fr22[key]
+	}
+	if !exist || p == nil {
+		return errors.New(fmt.Sprintf("Invalid configurations for CORESET0: fr=%v, ssbScs=%v, rmsiScs=%v, minChBw=%vMHz, coresetZero=%v", fr, ssbScs, rmsiScs, minChBw, flags.gridsetting.rmsiCoreset0))
+	}
+	fmt.Printf("CORESET0 Info: %v\n", *p)
+	flags.gridsetting._coreset0MultiplexingPat = p.MultiplexingPat
+	flags.gridsetting._coreset0NumRbs = p.NumRbs
+	flags.gridsetting._coreset0NumSymbs = p.NumSymbs
+	flags.gridsetting._coreset0OffsetList = p.OffsetLst
+
+	// validate CORESET0 bw against carrier bw
+	carrierBw := flags.gridsetting.bw
+	rmsiScsVal, _ := strconv.Atoi(rmsiScs[:len(rmsiScs)-3])
+	numRbsRmsiScs := getNrb(fr, carrierBw, rmsiScsVal)
+	if numRbsRmsiScs == 0 {
+		return errors.New(fmt.Sprintf("Invalid carrier bandwidth for %v: carrierBw=%v, rmsiScs=%v\n", fr, carrierBw, rmsiScs))
+	}
+
+	if numRbsRmsiScs < flags.gridsetting._coreset0NumRbs {
+		return errors.New(fmt.Sprintf("Invalid configurations for CORESET0: numRbsRmsiScs=%v, coreset0NumRbs=%v\n", numRbsRmsiScs, flags.gridsetting._coreset0NumRbs))
+	}
+
+	// update coreset0Offset w.r.t k_SSB
+	kssb := flags.gridsetting._kSsb
+	if len(flags.gridsetting._coreset0OffsetList) == 2 {
+		if kssb == 0 {
+			flags.gridsetting._coreset0Offset = flags.gridsetting._coreset0OffsetList[0]
+		} else {
+			flags.gridsetting._coreset0Offset = flags.gridsetting._coreset0OffsetList[1]
+		}
+	} else {
+		flags.gridsetting._coreset0Offset = flags.gridsetting._coreset0OffsetList[0]
+	}
+
+	fmt.Printf("CORESET0: multiplexingPattern=%v, numRbs=%v, numSymbs=%v, offset=%v\n", flags.gridsetting._coreset0MultiplexingPat, flags.gridsetting._coreset0NumRbs, flags.gridsetting._coreset0NumSymbs, flags.gridsetting._coreset0Offset)
+
+	// Basic assumptions: If offset >= 0, then 1st RB of CORESET0 aligns with the carrier edge; if offset < 0, then 1st RB of SSB aligns with the carrier edge.
+	// if offset >= 0, min bw = max(coreset0NumRbs, offset + 20 * scsSsb / scsRmsi), and n_CRB_SSB needs update w.r.t to offset
+	// if offset < 0, min bw = coreset0NumRbs - offset, and don't have to update n_CRB_SSB
+	ssbScsVal, _ := strconv.Atoi(ssbScs[:len(ssbScs)-3])
+	var minBw int
+	if flags.gridsetting._coreset0Offset >= 0 {
+		minBw = utils.MaxInt([]int{flags.gridsetting._coreset0NumRbs, flags.gridsetting._coreset0Offset + 20*ssbScsVal/rmsiScsVal})
+	} else {
+		minBw = flags.gridsetting._coreset0NumRbs - flags.gridsetting._coreset0Offset
+	}
+	if numRbsRmsiScs < minBw {
+		return errors.New(fmt.Sprintf("Invalid configurations for CORESET0: numRbsRmsiScs=%v, minBw=%v(coreset0NumRbs=%v,offset=%v)\n", numRbsRmsiScs, minBw, flags.gridsetting._coreset0NumRbs, flags.gridsetting._coreset0Offset))
+	}
+
+	// validate coreste0NumSymbs against dmrs-pointA-Position
+	// refer to 3GPP TS 38.211 vf80: 7.3.2.2	Control-resource set (CORESET)
+	// N_CORESET_symb = 3 is supported only if the higher-layer parameter dmrs-TypeA-Position equals 3;
+	if flags.gridsetting._coreset0NumSymbs == 3 && flags.gridsetting.dmrsTypeAPos != "pos3" {
+		return errors.New(fmt.Sprintf("coreset0NumSymbs can be 3 only if dmrs-TypeA-Position is pos3! (corest0NumSymbs=%v,dmrsTypeAPos=%v)\n", flags.gridsetting._coreset0NumSymbs, flags.gridsetting.dmrsTypeAPos))
+	}
+
+	// update info of initial dl bwp
+	if flags.gridsetting._coreset0Offset >= 0 {
+		upper := utils.MinInt([]int{numRbsRmsiScs - flags.gridsetting._coreset0NumRbs, numRbsRmsiScs - (flags.gridsetting._coreset0NumRbs + 20*ssbScsVal/rmsiScsVal)})
+		fmt.Printf("Available RB_Start for Initial DL BWP: [%v..%v]\n", 0, upper)
+	} else {
+		upper := utils.MinInt([]int{numRbsRmsiScs - flags.gridsetting._coreset0NumRbs, numRbsRmsiScs - (flags.gridsetting._coreset0NumRbs + 20*ssbScsVal/rmsiScsVal)})
+		fmt.Printf("Available RB_Start for Initial DL BWP: [%v..%v]\n", -flags.gridsetting._coreset0Offset, upper)
+	}
+	fmt.Printf("Available L_RBs for Initial DL BWP: [%v]\n", flags.gridsetting._coreset0NumRbs)
+
+	return nil
+}
+
+func validateCss0() error {
+	regYellow.Printf("-->calling validateCss0\n")
+
+	fr := flags.gridsetting._freqRange
+	pat := flags.gridsetting._coreset0MultiplexingPat
+	css0 := flags.gridsetting.rmsiCss0
+
+	switch pat {
+	case 1:
+		if fr == "FR1" || ((fr == "FR2-1" || fr == "FR2-2") && css0 >= 0 && css0 <= 13) {
+			return nil
+		} else {
+			return errors.New(fmt.Sprintf("Invalid CSS0 setting!\nsearchSpaceZero range is [0, 13] for SSB/CORESET0 multiplexing pattern 1 and FR2\n"))
+		}
+	case 2, 3:
+		if css0 != 0 {
+			return errors.New(fmt.Sprintf("Invalid CSS0 setting!\nsearchSpaceZero range is [0] for SSB/CORESET0 multiplexing pattern %v\n", css0))
+		}
+	}
+
+	return nil
+}
+
+func validateSearchSpace() error {
+	regYellow.Printf("-->calling validateSearchSpace\n")
+
+	// validate CORESET1
+	crbStart := flags.searchspace.coreset1StartCrb
+	numRbs := flags.searchspace.coreset1NumRbs
+	cceRegMapping := flags.searchspace.coreset1CceRegMappingType
+	duration := flags.searchspace._coreset1Duration
+	L, _ := strconv.Atoi(flags.searchspace.coreset1RegBundleSize[1:])
+
+	if crbStart%6 == 0 && numRbs%6 == 0 {
+		fdres := []byte(fmt.Sprintf("%045b", 0))
+		// refer to 38.213 vh40
+		// 10.1	UE procedure for determining physical downlink control channel assignment
+		// ...if a CORESET is not associated with any search space set configured with freqMonitorLocations, the bits of the bitmap have a one-to-one mapping with non-overlapping groups of 6 consecutive PRBs, in ascending order of the PRB index in the DL BWP bandwidth of N_BWP_RB PRBs with starting common RB position N_start_BWP, where the first common RB of the first group of 6 PRBs has common RB index 6*ceil(N_start_BWP/6) if rb-Offset is not provided...
+		rb0grp0 := utils.CeilInt(6 * float64(flags.bwp._bwpStartRb[DED_DL_BWP]) / 6)
+		bit0 := (crbStart - rb0grp0) / 6
+		nbits := numRbs / 6
+		for i := 0; i < nbits; i++ {
+			fdres[bit0+i] = '1'
+		}
+		flags.searchspace._coreset1FdRes = string(fdres)
+	} else {
+		return errors.New(fmt.Sprintf("Both coreset1StartCrb and coreset1NumRbs must be multiples of 6!"))
+	}
+
+	if cceRegMapping == "nonInterleaved" {
+		flags.searchspace.coreset1RegBundleSize = "n6"
+	} else {
+		if (duration == 1 && !utils.ContainsInt([]int{2, 6}, L)) || (utils.ContainsInt([]int{2, 3}, duration) && !utils.ContainsInt([]int{duration, 6}, L)) {
+			return errors.New(fmt.Sprintf("For interleaved CCE-to-REG mapping, L = 2/6 for coreset1Duration=1 and L = duration/6 for coreset1Duration=2/3.(cceRegMapping=%v, duration=%v, L=%v)\n", cceRegMapping, duration, L))
+		}
+	}
+
+	// TODO: validate type3 CSS/USS MonitoringSymbolWithinSlot
+	// refer to 38.213 vh40
+	// 10.1	UE procedure for determining physical downlink control channel assignment
+	// If the monitoringSymbolsWithinSlot indicates to a UE to monitor PDCCH in a subset of up to three consecutive symbols that are same in every slot where the UE monitors PDCCH for all search space sets, the UE does not expect to be configured with a PDCCH SCS other than 15 kHz if the subset includes at least one symbol after the third symbol.
+	// A UE does not expect to be provided a first symbol and a number of consecutive symbols for a CORESET that results to a PDCCH candidate mapping to symbols of different slots.
+	// A UE does not expect any two PDCCH monitoring occasions on an active DL BWP, for a same search space set or for different search space sets, in a same CORESET to be separated by a non-zero number of symbols that is smaller than the CORESET duration.
+
+	return nil
+}
+
+// validateNru validates discovery burst transmission window, RB sets and interlaced PUSCH/PUCCH for operation with shared spectrum channel access(NR-U).
+func validateNru() error {
+	regYellow.Printf("-->calling validateNru\n")
+
+	// refer to 3GPP 38.214 vh40
+	// 6.1.2.2	Resource allocation in frequency domain
+	// If the higher layer parameter useInterlacePUCCH-PUSCH in BWP-UplinkDedicated is provided, uplink resource allocation type 2 is used for PUSCH scheduled by DCI format 0_1.
+	fdRaType := flags.uldci._fdRaType[DCI_01_PUSCH]
+	if flags.bwp.useInterlace {
+		flags.uldci._fdRaType[DCI_01_PUSCH] = "raType2"
+	} else if fdRaType == "raType2" {
+		flags.uldci._fdRaType[DCI_01_PUSCH] = "raType1"
+	}
+
+	if !flags.gridsetting._unlicensed {
+		if flags.bwp.useInterlace {
+			return errors.New(fmt.Sprintf("Interlaced PUSCH/PUCCH(useInterlace=true) is only applicable to operation with shared spectrum channel access(band n46/n96/n102)!"))
+		}
+
+		if flags.uldci._fdRaType[DCI_01_PUSCH] != fdRaType {
+			return validatePusch()
+		}
+		return nil
+	}
+
+	// validate discovery burst transmission window
+	if !utils.ContainsStr([]string{"n1", "n2", "n4", "n8"}, flags.gridsetting.ssbPositionQcl) {
+		return errors.New(fmt.Sprintf("Invalid ssbPositionQcl(=%v), which must be one of [n1, n2, n4, n8]!", flags.gridsetting.ssbPositionQcl))
+	}
+	if !utils.ContainsStr([]string{"ms0dot5", "ms1", "ms2", "ms3", "ms4", "ms5"}, flags.gridsetting.dbWinLen) {
+		return errors.New(fmt.Sprintf("Invalid dbWinLen(=%v), which must be one of [ms0dot5, ms1, ms2, ms3, ms4, ms5]!", flags.gridsetting.dbWinLen))
+	}
+
+	q := getSsbQcl()
+	for _, issb := range flags.gridsetting.candSsbIndex {
+		if issb < 0 || issb >= q {
+			return errors.New(fmt.Sprintf("Invalid candSsbIndex(=%v): For NR-U, SSB index must be within [0, N_SSB_QCL-1], where N_SSB_QCL=%v!", flags.gridsetting.candSsbIndex, q))
+		}
+	}
+
+	numCands := getDbWinNumCands()
+	if flags.advanced.lbtFailCands < 0 || flags.advanced.lbtFailCands >= numCands {
+		return errors.New(fmt.Sprintf("Invalid lbtFailCands(=%v), which must be within [0, %v]!", flags.advanced.lbtFailCands, numCands-1))
+	}
+
+	cands := getSsbCands()
+	var ssbs []int
+	for _, cand := range cands {
+		ssbs = append(ssbs, getSsbIndex(cand))
+	}
+	fmt.Printf("Discovery burst transmission window: dbWinLen=%v, N_SSB_QCL=%v, number of candidate SSB positions=%v, lbtFailCands=%v\n", flags.gridsetting.dbWinLen, q, numCands, flags.advanced.lbtFailCands)
+	fmt.Printf("Active candidate SSB index: %v, SSB index: %v\n", cands, ssbs)
+
+	if !utils.ContainsInt(ssbs, flags.advanced.bestSsb) {
+		return errors.New(fmt.Sprintf("The bestSsb(=%v) is not transmitted within the discovery burst transmission window(active SSB index=%v)!", flags.advanced.bestSsb, ssbs))
+	}
+
+	// validate interlaced PUSCH/PUCCH
+	bwpStart, bwpSize := getUlBwp(DED_UL_BWP)
+	fmt.Printf("RB sets([first PRB, numRbs]) of dedicated UL BWP: %v\n", getRbSets(bwpStart, bwpSize))
+	if flags.bwp.useInterlace {
+		// refer to 3GPP 38.213 vh40
+		// 9.2.1	PUCCH Resource Sets
+		// If a UE is provided useInterlacePUCCH-PUSCH in BWP-UplinkDedicated, ... the UE transmits PUCCH format 0/1/2/3 over the RBs of an interlace, provided by interlace0, within the RB set, provided by rb-SetIndex, and intra-slot frequency hopping is not applicable.
+		if flags.pucch._interSlotFreqHop == "enabled" {
+			return errors.New(fmt.Sprintf("Inter-slot frequency hopping of PUCCH is not applicable to interlaced PUCCH!"))
+		}
+
+		for i := range flags.pucch._pucchResId {
+			if !utils.ContainsStr([]string{"format0", "format1", "format2", "format3"}, flags.pucch._pucchFormat[i]) {
+				return errors.New(fmt.Sprintf("Interlaced PUCCH only supports PUCCH format 0/1/2/3!(pucchResId=%v, pucchFormat=%v)", flags.pucch._pucchResId[i], flags.pucch._pucchFormat[i]))
+			}
+
+			if flags.pucch._pucchIntraSlotFreqHop[i] == "enabled" {
+				return errors.New(fmt.Sprintf("Intra-slot frequency hopping is not applicable to interlaced PUCCH!(pucchResId=%v)", flags.pucch._pucchResId[i]))
+			}
+
+			prbs, err := getInterlacedPrbs(bwpStart, bwpSize, []int{flags.pucch._pucchInterlace[i]}, flags.pucch._pucchRbSet[i], 1)
+			if err != nil {
+				return errors.New(fmt.Sprintf("Invalid interlaced PUCCH(pucchResId=%v): %s", flags.pucch._pucchResId[i], err.Error()))
+			}
+			fmt.Printf("Interlaced PUCCH: pucchResId=%v, interlace=%v, rbSet=%v, prbs=%v\n", flags.pucch._pucchResId[i], flags.pucch._pucchInterlace[i], flags.pucch._pucchRbSet[i], prbs)
+		}
+	}
+
+	// update FDRA and TBS of PUSCH
+	if flags.bwp.useInterlace || flags.uldci._fdRaType[DCI_01_PUSCH] != fdRaType {
+		return validatePusch()
+	}
+
+	return nil
+}
+
+func validatePucch() error {
+	regYellow.Printf("-->calling validatePucch\n")
+
+	if flags.pucch._interSlotFreqHop == "enabled" {
+		for _, v := range flags.pucch._pucchIntraSlotFreqHop {
+			if v == "enabled" {
+				return errors.New(fmt.Sprintf("For long PUCCH over multiple slots, the intra and inter slot frequency hopping cannot be enabled at the same time for a UE."))
+			}
+		}
+	}
+
+	// validate PRBs of dedicated PUCCH resources against the dedicated UL BWP
+	// Note: interlaced PUCCH is validated by validateNru.
+	if !flags.bwp.useInterlace {
+		_, bwpSize := getUlBwp(DED_UL_BWP)
+		for i := range flags.pucch._pucchResId {
+			numRbs := flags.pucch._pucchNumRbs[i]
+			prbs := []int{flags.pucch._pucchStartRb[i]}
+			if flags.pucch._pucchIntraSlotFreqHop[i] == "enabled" || flags.pucch._interSlotFreqHop == "enabled" {
+				prbs = append(prbs, flags.pucch._pucchSecondHopPrb[i])
+			}
+			for _, rb := range prbs {
+				if rb < 0 || rb+numRbs > bwpSize {
+					return errors.New(fmt.Sprintf("PRBs of PUCCH(pucchResId=%v, startingPRB=%v, secondHopPRB=%v, nrofPRBs=%v) exceed the dedicated UL BWP(size=%v)!", flags.pucch._pucchResId[i], flags.pucch._pucchStartRb[i], flags.pucch._pucchSecondHopPrb[i], numRbs, bwpSize))
+				}
+			}
+		}
+	}
+
+	flags.csi._csiRepPucchRes = 1
+
+	// refer to 3GPP 38.213 vh40
+	// 9.2.4	UE procedure for reporting SR
+	// If the UE is provided sr-ResourceConfig with PUCCH-format0 or PUCCH-format1 ...
+	// Note: sub-slot SR periodicity(sym2/sym6or7) is only applicable to PUCCH format 0, which is not supported.
+	if !strings.HasPrefix(flags.pucch.dsrPeriod, "sl") {
+		return errors.New(fmt.Sprintf("Only slot-based SR periodicity is supported for PUCCH format 1!(dsrPeriod=%v)", flags.pucch.dsrPeriod))
+	}
+	srPeriod, _ := strconv.Atoi(flags.pucch.dsrPeriod[2:])
+	if flags.pucch.dsrOffset < 0 || flags.pucch.dsrOffset >= srPeriod {
+		return errors.New(fmt.Sprintf("Invalid SR offset(=%v), which must be within [0, %v]!", flags.pucch.dsrOffset, srPeriod-1))
+	}
+
+	srRes := utils.IndexInt(flags.pucch._pucchResId, flags.pucch._dsrPucchRes)
+	if srRes < 0 || !utils.ContainsStr([]string{"format0", "format1"}, flags.pucch._pucchFormat[srRes]) {
+		return errors.New(fmt.Sprintf("The PUCCH resource for SR(=%v) must be configured with PUCCH format 0 or format 1!(pucchResId=%v, pucchFormat=%v)", flags.pucch._dsrPucchRes, flags.pucch._pucchResId, flags.pucch._pucchFormat))
+	}
+
+	csiRes := utils.IndexInt(flags.pucch._pucchResId, flags.csi._csiRepPucchRes)
+	if csiRes < 0 || !utils.ContainsStr([]string{"format2", "format3", "format4"}, flags.pucch._pucchFormat[csiRes]) {
+		return errors.New(fmt.Sprintf("The PUCCH resource for CSI report(=%v) must be configured with PUCCH format 2, format 3 or format 4!(pucchResId=%v, pucchFormat=%v)", flags.csi._csiRepPucchRes, flags.pucch._pucchResId, flags.pucch._pucchFormat))
+	}
+
+	csiPeriod, _ := strconv.Atoi(flags.csi.csiRepPeriod[5:])
+	if flags.csi.csiRepOffset < 0 || flags.csi.csiRepOffset >= csiPeriod {
+		return errors.New(fmt.Sprintf("Invalid CSI report offset(=%v), which must be within [0, %v]!", flags.csi.csiRepOffset, csiPeriod-1))
+	}
+
+	return nil
+}
+
+func validateCsi() error {
+	regYellow.Printf("-->calling validateCsi\n")
+
+	if len(flags.csi._resId) != 2 {
+		return errors.New(fmt.Sprintf("Only two NZP-CSI-RS resources can be configured, one for CSI report, and the other for TRS."))
+	}
+
+	// update nzpCsiRsInfo
+	for i := range flags.csi._resId {
+		row := flags.csi.freqAllocRow[i]
+		irow, _ := strconv.Atoi(row[3:])
+		bits := flags.csi.freqAllocBits[i]
+		if !((row == "row1" && len(bits) == 4) || (row == "row2" && len(bits) == 12) || (row == "row4" && len(bits) == 3) || (!utils.ContainsStr([]string{"row1", "row2", "row4"}, row) && len(bits) == 6)) {
+			return errors.New(fmt.Sprintf("[CSI-RS resourceId=%v] Invalid length of freqAllocBits(=%v) for freqAllocRow=%v!", flags.csi._resId[i], bits, row))
+		}
+
+		ports, _ := strconv.Atoi(flags.csi._numPorts[i][1:])
+		density := map[string]string{"evenPRBs": "0.5", "oddPRBs": "0.5", "one": "1", "three": "3"}[flags.csi._density[i]]
+		key := fmt.Sprintf("%v_%v_%v", ports, density, flags.csi._cdmType[i])
+		p, exist := nrgrid.CsiRsLoc[key]
+		if !exist {
+			return errors.New(fmt.Sprintf("Invalid key(=%v) when referring CsiRsLoc!", key))
+		} else {
+			found := false
+			for _, v := range p {
+				if v.Row == irow {
+					fmt.Printf("NZP-CSI-RS Info(resourceId=%v): %v\n", flags.csi._resId[i], v)
+					flags.csi._tdLoc[i] = v
+					found = true
+				}
+			}
+			if !found {
+				return errors.New(fmt.Sprintf("[CSI-RS resourceId=%v] Invalid freqAllocRow(=%v) for key(=%v) of CsiRsLoc!", flags.csi._resId[i], row, key))
+			}
+		}
+
+		// validate k_i and l_i
+		if _, err := getCsiRsRePattern(i); err != nil {
+			return err
+		}
+	}
+
+	// validate TRS
+	// refer to 3GPP 38.214 vh40
+	// 5.1.6.1.1	CSI-RS for tracking
+	// - CSI-RS resource is configured with single port, frequency density 3 and the row 1 of Table 7.4.1.5.3-1 of 38.211
+	// - the time-domain locations of the two CSI-RS resources in a slot, ..., is given by one of l in {4,8}, l in {5,9}, or l in {6,10} for frequency range 1 and frequency range 2, l in {0,4}, l in {1,5}, l in {2,6}, l in {3,7}, l in {7,11}, l in {8,12} or l in {9,13} for frequency range 2.
+	// Note: the second CSI-RS resource of TRS in a slot is located at l+4, and l is the OFDM symbol of the RE pattern determined by CSI-RS-ResourceMapping.
+	for i, v := range flags.csi._trsInfo {
+		if v != "true" {
+			continue
+		}
+
+		if flags.csi._numPorts[i] != "p1" || flags.csi._density[i] != "three" || flags.csi.freqAllocRow[i] != "row1" {
+			return errors.New(fmt.Sprintf("[CSI-RS resourceId=%v] TRS must be configured with nrofPorts=p1, density=three and freqAllocRow=row1(nrofPorts=%v, density=%v, freqAllocRow=%v)!", flags.csi._resId[i], flags.csi._numPorts[i], flags.csi._density[i], flags.csi.freqAllocRow[i]))
+		}
+
+		validL := []int{4, 5, 6}
+		if flags.gridsetting._freqRange != "FR1" {
+			validL = []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+		}
+		res, _ := getCsiRsRePattern(i)
+		for _, re := range res {
+			if !utils.ContainsInt(validL, re[0]) {
+				return errors.New(fmt.Sprintf("[CSI-RS resourceId=%v] Invalid time-domain location of TRS: l=%v(firstSymb=%v), which must be one of %v for %v!", flags.csi._resId[i], re[0], flags.csi._firstSymb[i], validL, flags.gridsetting._freqRange))
+			}
+		}
+	}
+
+	// update CSI-IM resource
+	flags.csi._csiImPeriod = flags.csi.period[0]
+	flags.csi._csiImOffset = flags.csi.offset[0]
+	flags.csi._csiImStartRb = flags.csi._startRb[0]
+	flags.csi._csiImNumRbs = flags.csi._numRbs[0]
+	if flags.csi._numPorts[0] == "p4" {
+		// Note: Ki/Li of CsiRsLocInfo are indexes of k_i/l_i
+		k0 := getCsiRsFreqAlloc(flags.csi.freqAllocRow[0], flags.csi.freqAllocBits[0])[flags.csi._tdLoc[0].Ki[0]]
+		l0 := []int{flags.csi._firstSymb[0]}[flags.csi._tdLoc[0].Li[0]]
+		if flags.csi.freqAllocRow[0] == "row4" {
+			flags.csi._csiImRePattern = "pattern1"
+			flags.csi._csiImScLoc = fmt.Sprintf("s%v", (k0+4)%12)
+			flags.csi._csiImSymbLoc = l0
+		} else {
+			flags.csi._csiImRePattern = "pattern0"
+			flags.csi._csiImScLoc = fmt.Sprintf("s%v", (k0+2)%12)
+			flags.csi._csiImSymbLoc = l0
+		}
+	}
+
+	if flags.csi._csiImRePattern == "pattern0" && flags.csi._csiImSymbLoc > 12 {
+		return errors.New(fmt.Sprintf("The symbolLocation(=%v) of CSI-IM with csi-IM-ResourceElementPattern=pattern0 should be in the range of 0..12.", flags.csi._csiImSymbLoc))
+	}
+
+	return nil
+}
+
+// validateSrs validates SRS-Resource and updates the m_SRS_b and N_b of each SRS resource.
+func validateSrs() error {
+	regYellow.Printf("-->calling validateSrs\n")
+
+	for i := range flags.srs._resId {
+		// refer to 3GPP 38.211 vh40
+		// 6.4.1.4.3	Mapping to physical resources
+		// The variables m_SRS,b and N_b, b=B_SRS are given by the selected row of Table 6.4.1.4.3-1 with the value of C_SRS given by the field c-SRS contained in the higher-layer parameter freqHopping.
+		p, exist := nrgrid.SrsBwCfg[fmt.Sprint(flags.srs.srsCSrs[i])]
+		if !exist {
+			return errors.New(fmt.Sprintf("[SRS resourceId=%v] Invalid key(=%v) when referring SrsBwCfg!", flags.srs._resId[i], flags.srs.srsCSrs[i]))
+		}
+		var mSRSb, Nb []string
+		for b := 0; b < 4; b++ {
+			mSRSb = append(mSRSb, fmt.Sprint(p.MSRSb[b]))
+			Nb = append(Nb, fmt.Sprint(p.Nb[b]))
+		}
+		flags.srs._mSRSb[i] = strings.Join(mSRSb, "_")
+		flags.srs._Nb[i] = strings.Join(Nb, "_")
+		fmt.Printf("SRS Info(resourceId=%v): mSRSb=%v, Nb=%v\n", flags.srs._resId[i], flags.srs._mSRSb[i], flags.srs._Nb[i])
+
+		// refer to 3GPP 38.331 vh10
+		//  combOffset-n2 INTEGER (0..1), cyclicShift-n2 INTEGER (0..7)
+		//  combOffset-n4 INTEGER (0..3), cyclicShift-n4 INTEGER (0..11)
+		KTC, _ := strconv.Atoi(flags.srs.srsNumCombs[i][1:])
+		nCsMax := map[int]int{2: 8, 4: 12}[KTC]
+		if flags.srs.srsCombOff[i] < 0 || flags.srs.srsCombOff[i] >= KTC {
+			return errors.New(fmt.Sprintf("[SRS resourceId=%v] Invalid combOffset(=%v) for transmissionComb=%v!", flags.srs._resId[i], flags.srs.srsCombOff[i], flags.srs.srsNumCombs[i]))
+		}
+		if flags.srs.srsCs[i] < 0 || flags.srs.srsCs[i] >= nCsMax {
+			return errors.New(fmt.Sprintf("[SRS resourceId=%v] Invalid cyclicShift(=%v) for transmissionComb=%v!", flags.srs._resId[i], flags.srs.srsCs[i], flags.srs.srsNumCombs[i]))
+		}
+
+		// refer to 3GPP 38.214 vh40
+		// 6.2.1	UE sounding procedure between uplink and downlink
+		// The UE is not expected to be configured with repetitionFactor R > N_symb_SRS.
+		// refer to 3GPP 38.211 vh40
+		// 6.4.1.4.1	SRS resource
+		// l_offset in {0,1,...,13} ... where N_symb_SRS <= l_offset+1.
+		numSymbs, _ := strconv.Atoi(flags.srs.srsNumSymbs[i][1:])
+		R, _ := strconv.Atoi(flags.srs.srsRepetition[i][1:])
+		if R > numSymbs {
+			return errors.New(fmt.Sprintf("[SRS resourceId=%v] repetitionFactor(=%v) should not be greater than nrofSymbols(=%v)!", flags.srs._resId[i], flags.srs.srsRepetition[i], flags.srs.srsNumSymbs[i]))
+		}
+		if numSymbs > flags.srs.srsStartPos[i]+1 {
+			return errors.New(fmt.Sprintf("[SRS resourceId=%v] nrofSymbols(=%v) should not be greater than startPosition(=%v)+1!", flags.srs._resId[i], flags.srs.srsNumSymbs[i], flags.srs.srsStartPos[i]))
+		}
+
+		// validate periodicityAndOffset
+		period, _ := strconv.Atoi(flags.srs.srsPeriod[i][2:])
+		if flags.srs.srsOffset[i] < 0 || flags.srs.srsOffset[i] >= period {
+			return errors.New(fmt.Sprintf("[SRS resourceId=%v] Invalid offset(=%v) for periodicity(=%v) of SRS-PeriodicityAndOffset!", flags.srs._resId[i], flags.srs.srsOffset[i], flags.srs.srsPeriod[i]))
+		}
+
+		// validate SRS bandwidth against UL BWP
+		_, bwpSize := getUlBwp(DED_UL_BWP)
+		if p.MSRSb[0] > bwpSize {
+			return errors.New(fmt.Sprintf("[SRS resourceId=%v] m_SRS_0(=%v) of c-SRS(=%v) exceeds the size(=%v) of dedicated UL BWP!", flags.srs._resId[i], p.MSRSb[0], flags.srs.srsCSrs[i], bwpSize))
+		}
+	}
+
+	return nil
+}
+
+// validatePaging validates PCCH-Config and the Type2-PDCCH CSS set used for paging.
+func validatePaging() error {
+	regYellow.Printf("-->calling validatePaging\n")
+
+	if !flags.paging.pagedUe {
+		return nil
+	}
+
+	// Note: paging PDSCH is scheduled by DCI 1_0 with P-RNTI whose configurations are the DCI_10_PAGING entries of DL DCI and common DMRS.
+	if err := validateDlDciEntry(DCI_10_PAGING); err != nil {
+		return err
+	}
+	if err := validateDmrsCommonEntry(DMRS_DCI_10_PAGING); err != nil {
+		return err
+	}
+
+	T, N, Ns := getPagingParams()
+	if T <= 0 || N <= 0 || Ns <= 0 {
+		return errors.New(fmt.Sprintf("Invalid PCCH-Config: defaultPagingCycle=%v, nAndPagingFrameOffset=%v, ns=%v", flags.paging.defaultPagingCycle, flags.paging.nAndPagingFrameOffset, flags.paging.ns))
+	}
+
+	// refer to 3GPP 38.331 vh30
+	// PCCH-Config
+	// nAndPagingFrameOffset: Used to derive the number of total paging frames in T (corresponding to parameter N in TS 38.304 [20]) and paging frame offset (corresponding to parameter PF_offset in TS 38.304 [20]).
+	if flags.paging.pagingFrameOffset < 0 || flags.paging.pagingFrameOffset >= T/N {
+		return errors.New(fmt.Sprintf("Invalid pagingFrameOffset(=%v) for nAndPagingFrameOffset(=%v), which should be in the range [0, %v].", flags.paging.pagingFrameOffset, flags.paging.nAndPagingFrameOffset, T/N-1))
+	}
+
+	if flags.paging.ueId < 0 || flags.paging.ueId > 1023 {
+		return errors.New(fmt.Sprintf("Invalid UE_ID(=%v) for paging, which should be 5G-S-TMSI mod 1024.", flags.paging.ueId))
+	}
+
+	if flags.paging.numPdcchMoPerSsbInPo < 1 || flags.paging.numPdcchMoPerSsbInPo > 4 {
+		return errors.New(fmt.Sprintf("Invalid nrofPDCCH-MonitoringOccasionPerSSB-InPO(=%v), which should be in the range [1, 4].", flags.paging.numPdcchMoPerSsbInPo))
+	}
+
+	// refer to 3GPP 38.331 vh30
+	// PCCH-Config
+	// firstPDCCH-MonitoringOccasionOfPO: Points out the first PDCCH monitoring occasion for paging of each PO of the PF, see TS 38.304 [20].
+	// Note: the maximum value of each choice is 140*2^u*(T/N)-1, e.g. sCS15KHZoneT INTEGER (0..139), sCS120KHZoneSixteenthT INTEGER (0..17919).
+	if len(flags.paging.firstPdcchMoOfPo) > 0 {
+		if len(flags.paging.firstPdcchMoOfPo) != Ns {
+			return errors.New(fmt.Sprintf("The size of firstPDCCH-MonitoringOccasionOfPO(=%v) must be equal to Ns(=%v).", flags.paging.firstPdcchMoOfPo, Ns))
+		}
+
+		scs, _ := strconv.Atoi(flags.gridsetting.scs[:len(flags.gridsetting.scs)-3])
+		maxFirstMo := 140*(scs/15)*(T/N) - 1
+		for i, mo := range flags.paging.firstPdcchMoOfPo {
+			if mo < 0 || mo > maxFirstMo || (i > 0 && mo <= flags.paging.firstPdcchMoOfPo[i-1]) {
+				return errors.New(fmt.Sprintf("Invalid firstPDCCH-MonitoringOccasionOfPO(=%v), which should be in ascending order and in the range [0, %v].", flags.paging.firstPdcchMoOfPo, maxFirstMo))
+			}
+		}
+	}
+
+	// refer to 3GPP 38.213 vh40
+	// 10.1	UE procedure for determining physical downlink control channel assignment
+	// - a Type2-PDCCH CSS set configured by pagingSearchSpace in PDCCH-ConfigCommon for a DCI format with CRC scrambled by a P-RNTI on the primary cell of the MCG
+	// refer to 3GPP 38.304 vh40
+	// 7.1	Discontinuous Reception for paging
+	// When SearchSpaceId = 0 is configured for pagingSearchSpace, Ns is either 1 or 2.
+	if flags.paging.pagingSearchSpace == 0 {
+		if Ns > 2 {
+			return errors.New(fmt.Sprintf("Invalid ns(=%v) when pagingSearchSpace is 0, which can be one or two.", flags.paging.ns))
+		}
+	} else {
+		iss := utils.IndexStr(flags.searchspace._ssType, "type2")
+		if iss < 0 || flags.searchspace._ssId[iss] != flags.paging.pagingSearchSpace {
+			return errors.New(fmt.Sprintf("Invalid pagingSearchSpace(=%v), which can be 0 or searchSpaceId of the Type2-PDCCH CSS set(_ssId=%v, _ssType=%v).", flags.paging.pagingSearchSpace, flags.searchspace._ssId, flags.searchspace._ssType))
+		}
+		if flags.searchspace._ssCoresetId[iss] != 0 {
+			return errors.New(fmt.Sprintf("Type2-PDCCH CSS set must be configured in CORESET0!"))
+		}
+	}
+
+	fmt.Printf("Paging: T=%v, N=%v, Ns=%v, PF_offset=%v, UE_ID=%v, PF fulfills (SFN + PF_offset) mod T = %v, i_s=%v\n", T, N, Ns, flags.paging.pagingFrameOffset, flags.paging.ueId, (T/N)*(flags.paging.ueId%N), (flags.paging.ueId/N)%Ns)
+
+	return nil
+}
+
+// validateOsi validates si-SchedulingInfo of SIB1 and the Type0A-PDCCH CSS set used for SI messages.
+func validateOsi() error {
+	regYellow.Printf("-->calling validateOsi\n")
+
+	if len(flags.osi.siPeriodicity) == 0 {
+		return nil
+	}
+
+	w, err := strconv.Atoi(flags.osi.siWindowLength[1:])
+	if err != nil || !utils.ContainsInt([]int{5, 10, 20, 40, 80, 160, 320, 640, 1280}, w) {
+		return errors.New(fmt.Sprintf("Invalid si-WindowLength(=%v), which can be s5/s10/s20/s40/s80/s160/s320/s640/s1280.", flags.osi.siWindowLength))
+	}
+
+	// refer to 3GPP 38.331 vh30
+	// 5.2.2.3.2	Acquisition of an SI message
+	// the SI-window starts at the slot #a, where a = x mod N, in the radio frame for which SFN mod T = FLOOR(x/N), where T is the si-Periodicity of the concerned SI message and N is the number of slots in a radio frame
+	// SI-SchedulingInfo field descriptions
+	// si-WindowLength: The length of the SI scheduling window. ... The network always configures si-WindowLength to be shorter than or equal to the si-Periodicity.
+	scs, _ := strconv.Atoi(flags.gridsetting.scs[:len(flags.gridsetting.scs)-3])
+	N := 10 * scs / 15
+	for n, period := range flags.osi.siPeriodicity {
+		T, err := strconv.Atoi(period[2:])
+		if err != nil || !utils.ContainsInt([]int{8, 16, 32, 64, 128, 256, 512}, T) {
+			return errors.New(fmt.Sprintf("Invalid si-Periodicity(=%v) of SI message #%v, which can be rf8/rf16/rf32/rf64/rf128/rf256/rf512.", period, n+1))
 		}
 
-		// refer to 3GPP 38.213 vh40
-		//  Table 13-12: Parameters for PDCCH monitoring occasions for Type0-PDCCH CSS set - SS/PBCH block and CORESET multiplexing pattern 1 and FR2-1, or SS/PBCH block and CORESET multiplexing pattern 1 and {SS/PBCH block, PDCCH} SCS {120, 120} kHz in FR2-2
-		var css0OccasionsPat1Fr21OrFr22Scs120 = map[int]*nrgrid.Css0OccasionPat1{
-			0:  {0, 1, 2, []int{0}},
-			1:  {0, 2, 1, []int{0, 7}},
-			2:  {20, 1, 2, []int{0}},
-			3:  {20, 2, 1, []int{0, 7}},
-			4:  {40, 1, 2, []int{0}},
-			5:  {40, 2, 1, []int{0, 7}},
-			6:  {0, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
-			7:  {20, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
-			8:  {40, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
-			9:  {60, 1, 2, []int{0}},
-			10: {60, 2, 1, []int{0, 7}},
-			11: {60, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
-			12: {0, 1, 4, []int{0}},
-			13: {40, 1, 4, []int{0}},
-			14: nil,
-			15: nil,
+		if (n+1)*w > T*N {
+			return errors.New(fmt.Sprintf("SI-window(x=%v, w=%v) of SI message #%v exceeds si-Periodicity(=%v, %v slots).", n*w, w, n+1, period, T*N))
 		}
+	}
 
-		// refer to 3GPP 38.213 vh40
-		//  Table 13-12A: Parameters for PDCCH monitoring occasions for Type0-PDCCH CSS set - SS/PBCH block and CORESET multiplexing pattern 1 and {SS/PBCH block, PDCCH} SCS {480, 480} kHz or {960, 960} kHz in FR2-2
-		// For u=5(480KHz), X=1.25; For u=6(960KHz), X=0.625
-		var css0OccasionsPat1Fr22Scs480 = map[int]*nrgrid.Css0OccasionPat1{
-			0:  {0, 1, 2, []int{0}},
-			1:  {0, 2, 1, []int{0, 7}},
-			2:  {10, 1, 2, []int{0}},
-			3:  {10, 2, 1, []int{0, 7}},
-			4:  {40, 1, 2, []int{0}},
-			5:  {40, 2, 1, []int{0, 7}},
-			6:  {0, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
-			7:  {10, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
-			8:  {40, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
-			9:  {50, 1, 2, []int{0}},
-			10: {50, 2, 1, []int{0, 7}},
-			11: {50, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
-			12: {0, 1, 4, []int{0}},
-			13: {40, 1, 4, []int{0}},
-			14: nil,
-			15: nil,
+	// refer to 3GPP 38.213 vh40
+	// 10.1	UE procedure for determining physical downlink control channel assignment
+	// - a Type0A-PDCCH CSS set configured by searchSpaceOtherSystemInformation in PDCCH-ConfigCommon for a DCI format with CRC scrambled by a SI-RNTI on the primary cell of the MCG
+	iss := utils.IndexStr(flags.searchspace._ssType, "type0a")
+	if iss < 0 || flags.searchspace._ssCoresetId[iss] != 0 {
+		return errors.New(fmt.Sprintf("Type0A-PDCCH CSS set must be configured in CORESET0!"))
+	}
+
+	if flags.osi.siNumTxPerSsb < 1 {
+		return errors.New(fmt.Sprintf("Invalid siNumTxPerSsb(=%v), which must be at least 1.", flags.osi.siNumTxPerSsb))
+	}
+
+	// Note: SI message PDSCH is scheduled by DCI 1_0 with SI-RNTI whose configurations are the DCI_10_OSI entries of DL DCI and common DMRS, which are separated from SIB1.
+	if err := validateDlDciEntry(DCI_10_OSI); err != nil {
+		return err
+	}
+	if err := validateDmrsCommonEntry(DMRS_DCI_10_OSI); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateDrx validates DRX-Config of the UE.
+func validateDrx() error {
+	regYellow.Printf("-->calling validateDrx\n")
+
+	if !flags.drx.drxEnabled {
+		return nil
+	}
+
+	// refer to 3GPP 38.331 vh30
+	// DRX-Config field descriptions
+	onDuration, err := strconv.Atoi(flags.drx.drxOnDurationTimer[2:])
+	if err != nil || !utils.ContainsInt([]int{1, 2, 3, 4, 5, 6, 8, 10, 20, 30, 40, 50, 60, 80, 100, 200, 300, 400, 500, 600, 800, 1000, 1200, 1600}, onDuration) {
+		return errors.New(fmt.Sprintf("Invalid drx-onDurationTimer(=%v), which can be ms1/ms2/ms3/ms4/ms5/ms6/ms8/ms10/ms20/ms30/ms40/ms50/ms60/ms80/ms100/ms200/ms300/ms400/ms500/ms600/ms800/ms1000/ms1200/ms1600.", flags.drx.drxOnDurationTimer))
+	}
+
+	inactivity, err := strconv.Atoi(flags.drx.drxInactivityTimer[2:])
+	if err != nil || !utils.ContainsInt([]int{0, 1, 2, 3, 4, 5, 6, 8, 10, 20, 30, 40, 50, 60, 80, 100, 200, 300, 500, 750, 1280, 1920, 2560}, inactivity) {
+		return errors.New(fmt.Sprintf("Invalid drx-InactivityTimer(=%v), which can be ms0/ms1/ms2/ms3/ms4/ms5/ms6/ms8/ms10/ms20/ms30/ms40/ms50/ms60/ms80/ms100/ms200/ms300/ms500/ms750/ms1280/ms1920/ms2560.", flags.drx.drxInactivityTimer))
+	}
+
+	if flags.drx.drxHarqRttTimerDl < 0 || flags.drx.drxHarqRttTimerDl > 56 || flags.drx.drxHarqRttTimerUl < 0 || flags.drx.drxHarqRttTimerUl > 56 {
+		return errors.New(fmt.Sprintf("Invalid drx-HARQ-RTT-TimerDL(=%v) or drx-HARQ-RTT-TimerUL(=%v), which can be 0..56.", flags.drx.drxHarqRttTimerDl, flags.drx.drxHarqRttTimerUl))
+	}
+
+	for _, retx := range []string{flags.drx.drxRetransmissionTimerDl, flags.drx.drxRetransmissionTimerUl} {
+		v, err := strconv.Atoi(retx[2:])
+		if err != nil || !utils.ContainsInt([]int{0, 1, 2, 4, 6, 8, 16, 24, 33, 40, 64, 80, 96, 112, 128, 160, 320}, v) {
+			return errors.New(fmt.Sprintf("Invalid drx-RetransmissionTimerDL/drx-RetransmissionTimerUL(=%v), which can be sl0/sl1/sl2/sl4/sl6/sl8/sl16/sl24/sl33/sl40/sl64/sl80/sl96/sl112/sl128/sl160/sl320.", retx))
 		}
+	}
 
-		// refer to 3GPP 38.213 vh40
-		//  Table 13-12A: Parameters for PDCCH monitoring occasions for Type0-PDCCH CSS set - SS/PBCH block and CORESET multiplexing pattern 1 and {SS/PBCH block, PDCCH} SCS {480, 480} kHz or {960, 960} kHz in FR2-2
-		// For u=5(480KHz), X=1.25; For u=6(960KHz), X=0.625
-		var css0OccasionsPat1Fr22Scs960 = map[int]*nrgrid.Css0OccasionPat1{
-			0:  {0, 1, 2, []int{0}},
-			1:  {0, 2, 1, []int{0, 7}},
-			2:  {5, 1, 2, []int{0}},
-			3:  {5, 2, 1, []int{0, 7}},
-			4:  {40, 1, 2, []int{0}},
-			5:  {40, 2, 1, []int{0, 7}},
-			6:  {0, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
-			7:  {10, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
-			8:  {40, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
-			9:  {45, 1, 2, []int{0}},
-			10: {45, 2, 1, []int{0, 7}},
-			11: {45, 2, 1, []int{0, flags.gridsetting._coreset0NumSymbs}},
-			12: {0, 1, 4, []int{0}},
-			13: {40, 1, 4, []int{0}},
-			14: nil,
-			15: nil,
+	longCycle, err := strconv.Atoi(flags.drx.drxLongCycle[2:])
+	if err != nil || !utils.ContainsInt([]int{10, 20, 32, 40, 60, 64, 70, 80, 128, 160, 256, 320, 512, 640, 1024, 1280, 2048, 2560, 5120, 10240}, longCycle) {
+		return errors.New(fmt.Sprintf("Invalid drx-LongCycle(=%v), which can be ms10/ms20/ms32/ms40/ms60/ms64/ms70/ms80/ms128/ms160/ms256/ms320/ms512/ms640/ms1024/ms1280/ms2048/ms2560/ms5120/ms10240.", flags.drx.drxLongCycle))
+	}
+
+	if flags.drx.drxStartOffset < 0 || flags.drx.drxStartOffset >= longCycle {
+		return errors.New(fmt.Sprintf("Invalid drx-StartOffset(=%v), which can be 0..%v for drx-LongCycle(=%v).", flags.drx.drxStartOffset, longCycle-1, flags.drx.drxLongCycle))
+	}
+
+	// drx-ShortCycle: Value in ms. ms1 corresponds to 1 ms, ms2 corresponds to 2 ms, and so on. If a value of drx-ShortCycle is configured, the value of drx-LongCycle shall be a multiple of the drx-ShortCycle value.
+	if len(flags.drx.drxShortCycle) > 0 {
+		shortCycle, err := strconv.Atoi(flags.drx.drxShortCycle[2:])
+		if err != nil || !utils.ContainsInt([]int{2, 3, 4, 5, 6, 7, 8, 10, 14, 16, 20, 30, 32, 35, 40, 64, 80, 128, 160, 256, 320, 512, 640}, shortCycle) {
+			return errors.New(fmt.Sprintf("Invalid drx-ShortCycle(=%v), which can be ms2/ms3/ms4/ms5/ms6/ms7/ms8/ms10/ms14/ms16/ms20/ms30/ms32/ms35/ms40/ms64/ms80/ms128/ms160/ms256/ms320/ms512/ms640.", flags.drx.drxShortCycle))
 		}
 
-		var p *nrgrid.Css0OccasionPat1
-		var exist bool
-		if flags.gridsetting._freqRange == "FR1" {
-			p, exist = css0OccasionsPat1Fr1[flags.gridsetting.rmsiCss0]
-			if !exist || p == nil {
-				return errors.New(fmt.Sprintf("Invalid key(=%v) when referring css0OccasionsPat1Fr1.", flags.gridsetting.rmsiCss0))
-			}
-		} else if flags.gridsetting._freqRange == "FR2-1" || (flags.gridsetting._freqRange == "FR2-2" && flags.gridsetting._ssbScs == "120KHz") {
-			// refer to 38.214 vh40
-			// 13	UE procedure for monitoring Type0-PDCCH CSS sets
-			// The SCS of the CORESET for Type0-PDCCH CSS set is provided by subCarrierSpacingCommon for FR1 and FR2-1 and same as the SCS of the corresponding SS/PBCH block for FR2-2.
-			p, exist = css0OccasionsPat1Fr21OrFr22Scs120[flags.gridsetting.rmsiCss0]
-			if !exist || p == nil {
-				return errors.New(fmt.Sprintf("Invalid key(=%v) when referring css0OccasionsPat1Fr21OrFr22Scs120.", flags.gridsetting.rmsiCss0))
+		if longCycle%shortCycle != 0 {
+			return errors.New(fmt.Sprintf("drx-LongCycle(=%v) must be a multiple of drx-ShortCycle(=%v).", flags.drx.drxLongCycle, flags.drx.drxShortCycle))
+		}
+
+		if flags.drx.drxShortCycleTimer < 1 || flags.drx.drxShortCycleTimer > 16 {
+			return errors.New(fmt.Sprintf("Invalid drx-ShortCycleTimer(=%v), which can be 1..16.", flags.drx.drxShortCycleTimer))
+		}
+	}
+
+	if flags.drx.numTxPerCycle < 0 || flags.drx.nackPeriod < 0 {
+		return errors.New(fmt.Sprintf("Invalid numTxPerCycle(=%v) or nackPeriod(=%v), which must be non-negative.", flags.drx.numTxPerCycle, flags.drx.nackPeriod))
+	}
+
+	// Note: data scheduling must cover at least one Long DRX cycle so that the monitoring ratio and active time are meaningful.
+	if flags.advanced.numSchedRfs > 0 && flags.advanced.numSchedRfs*10 < longCycle {
+		numSchedRfs := (longCycle + 9) / 10
+		regYellow.Printf("numSchedRfs(=%v) is less than drx-LongCycle(=%v), and is set to %v.\n", flags.advanced.numSchedRfs, flags.drx.drxLongCycle, numSchedRfs)
+		flags.advanced.numSchedRfs = numSchedRfs
+	}
+
+	return nil
+}
+
+// validateCgSps validates SPS-Config and ConfiguredGrantConfig.
+func validateCgSps() error {
+	regYellow.Printf("-->calling validateCgSps\n")
+
+	// refer to 3GPP 38.331 vh30
+	// SPS-Config field descriptions
+	if flags.cgsps.spsEnabled {
+		periodicity, err := strconv.Atoi(flags.cgsps.spsPeriodicity[2:])
+		if err != nil || !utils.ContainsInt([]int{10, 20, 32, 40, 64, 80, 128, 160, 320, 640}, periodicity) {
+			return errors.New(fmt.Sprintf("Invalid periodicity(=%v) of SPS-Config, which can be ms10/ms20/ms32/ms40/ms64/ms80/ms128/ms160/ms320/ms640.", flags.cgsps.spsPeriodicity))
+		}
+
+		if flags.cgsps.spsNumHarqProc < 1 || flags.cgsps.spsNumHarqProc > 8 || flags.cgsps.spsHarqProcIdOffset < 0 || flags.cgsps.spsHarqProcIdOffset > 15 {
+			return errors.New(fmt.Sprintf("Invalid nrofHARQ-Processes(=%v) or harq-ProcID-Offset(=%v) of SPS-Config, which can be 1..8 and 0..15, respectively.", flags.cgsps.spsNumHarqProc, flags.cgsps.spsHarqProcIdOffset))
+		}
+
+		// n1PUCCH-AN: HARQ resource for PUCCH for DL SPS. The network configures the resource either as format0 or format1.
+		r := utils.IndexInt(flags.pucch._pucchResId, flags.cgsps.spsN1PucchAn)
+		if r < 0 || !utils.ContainsStr([]string{"format0", "format1"}, flags.pucch._pucchFormat[r]) {
+			return errors.New(fmt.Sprintf("Invalid n1PUCCH-AN(=%v) of SPS-Config, which must be a format 0 or format 1 PUCCH resource of pucchResId(=%v).", flags.cgsps.spsN1PucchAn, flags.pucch._pucchResId))
+		}
+	}
+
+	// refer to 3GPP 38.331 vh30
+	// ConfiguredGrantConfig field descriptions
+	if flags.cgsps.cgEnabled {
+		if !utils.ContainsStr([]string{"type1", "type2"}, flags.cgsps.cgType) {
+			return errors.New(fmt.Sprintf("Invalid type(=%v) of configured grant, which can be type1 or type2.", flags.cgsps.cgType))
+		}
+
+		P, err := getCgPeriodicity()
+		if err != nil {
+			return err
+		}
+
+		repK, err := strconv.Atoi(flags.cgsps.cgRepK[1:])
+		if err != nil || !utils.ContainsInt([]int{1, 2, 4, 8}, repK) {
+			return errors.New(fmt.Sprintf("Invalid repK(=%v) of ConfiguredGrantConfig, which can be n1/n2/n4/n8.", flags.cgsps.cgRepK))
+		}
+
+		if !utils.ContainsStr([]string{"s1-0231", "s2-0303", "s3-0000"}, flags.cgsps.cgRepKRv) {
+			return errors.New(fmt.Sprintf("Invalid repK-RV(=%v) of ConfiguredGrantConfig, which can be s1-0231/s2-0303/s3-0000.", flags.cgsps.cgRepKRv))
+		}
+
+		// refer to 3GPP 38.214 vh40
+		// 6.1.2.3.1	Transport block repetition for uplink transmissions of PUSCH repetition Type A with a configured grant
+		// The initial transmission of a transport block may start at ... In any RV sequence, the repetitions shall be terminated after transmitting K repetitions, or at the last transmission occasion among the K repetitions within the period P
+		// Note: PUSCH repetition Type A is assumed for configured grant, so the PUSCH must be within a slot.
+		if S, L := flags.uldci._tdStartSymb[DCI_01_PUSCH], flags.uldci._tdNumSymbs[DCI_01_PUSCH]; S+L > getSymbPerSlot(flags.bwp._bwpCp[DED_UL_BWP]) {
+			return errors.New(fmt.Sprintf("The time domain resource allocation(S=%v, L=%v) of configured grant PUSCH exceeds the slot.", S, L))
+		}
+
+		// Note: K repetitions are assumed to be within the period P.
+		if symbPerSlot := getSymbPerSlot(flags.bwp._bwpCp[DED_UL_BWP]); P >= symbPerSlot && repK*symbPerSlot > P {
+			return errors.New(fmt.Sprintf("repK(=%v) repetitions of configured grant exceed the periodicity(=%v).", flags.cgsps.cgRepK, flags.cgsps.cgPeriodicity))
+		}
+
+		// Note: for periodicity of less than one slot, repetition is not supported, and each configured grant PUSCH must be within the period P.
+		if P < getSymbPerSlot(flags.bwp._bwpCp[DED_UL_BWP]) {
+			if repK > 1 {
+				return errors.New(fmt.Sprintf("repK(=%v) must be n1 for periodicity(=%v) of less than one slot.", flags.cgsps.cgRepK, flags.cgsps.cgPeriodicity))
 			}
-		} else if flags.gridsetting._freqRange == "FR2-2" && flags.gridsetting._ssbScs == "480KHz" {
-			p, exist = css0OccasionsPat1Fr22Scs480[flags.gridsetting.rmsiCss0]
-			if !exist || p == nil {
-				return errors.New(fmt.Sprintf("Invalid key(=%v) when referring css0OccasionsPat1Fr22Scs480.", flags.gridsetting.rmsiCss0))
+
+			if L := flags.uldci._tdNumSymbs[DCI_01_PUSCH]; L > P {
+				return errors.New(fmt.Sprintf("The number of symbols(=%v) of configured grant PUSCH exceeds the periodicity(=%v).", L, flags.cgsps.cgPeriodicity))
 			}
-		} else if flags.gridsetting._freqRange == "FR2-2" && flags.gridsetting._ssbScs == "960KHz" {
-			p, exist = css0OccasionsPat1Fr22Scs960[flags.gridsetting.rmsiCss0]
-			if !exist || p == nil {
-				return errors.New(fmt.Sprintf("Invalid key(=%v) when referring css0OccasionsPat1Fr22Scs960.", flags.gridsetting.rmsiCss0))
+		}
+
+		if flags.cgsps.cgNumHarqProc < 1 || flags.cgsps.cgNumHarqProc > 16 || flags.cgsps.cgHarqProcIdOffset2 < 0 || flags.cgsps.cgHarqProcIdOffset2 > 15 {
+			return errors.New(fmt.Sprintf("Invalid nrofHARQ-Processes(=%v) or harq-ProcID-Offset2(=%v) of ConfiguredGrantConfig, which can be 1..16 and 0..15, respectively.", flags.cgsps.cgNumHarqProc, flags.cgsps.cgHarqProcIdOffset2))
+		}
+
+		if flags.cgsps.cgType == "type1" && (flags.cgsps.cgTimeDomainOffset < 0 || flags.cgsps.cgTimeDomainOffset > 5119) {
+			return errors.New(fmt.Sprintf("Invalid timeDomainOffset(=%v) of rrc-ConfiguredUplinkGrant, which can be 0..5119.", flags.cgsps.cgTimeDomainOffset))
+		}
+	}
+
+	return nil
+}
+
+// validateDss validates lte-CRS-ToMatchAround and RateMatchPattern for LTE-NR DSS.
+func validateDss() error {
+	regYellow.Printf("-->calling validateDss\n")
+
+	// refer to 3GPP 38.331 vh30
+	// RateMatchPatternLTE-CRS field descriptions
+	if flags.dss.lteCrsEnabled {
+		// Note: LTE CRS is transmitted with subcarrier spacing of 15KHz, hence only 15KHz SCS is supported for NR carrier with lte-CRS-ToMatchAround.
+		if flags.gridsetting._carrierScs != "15KHz" {
+			return errors.New(fmt.Sprintf("lte-CRS-ToMatchAround is only supported when subcarrierSpacing(=%v) of SCS-SpecificCarrier is 15KHz.", flags.gridsetting._carrierScs))
+		}
+
+		if flags.dss.lteCarrierFreqDl < 0 || flags.dss.lteCarrierFreqDl > 16383 {
+			return errors.New(fmt.Sprintf("Invalid carrierFreqDL(=%v) of lte-CRS-ToMatchAround, which can be 0..16383.", flags.dss.lteCarrierFreqDl))
+		}
+
+		if !utils.ContainsStr([]string{"n6", "n15", "n25", "n50", "n75", "n100"}, flags.dss.lteCarrierBw) {
+			return errors.New(fmt.Sprintf("Invalid carrierBandwidthDL(=%v) of lte-CRS-ToMatchAround, which can be n6/n15/n25/n50/n75/n100.", flags.dss.lteCarrierBw))
+		}
+
+		if !utils.ContainsStr([]string{"n1", "n2", "n4"}, flags.dss.lteNumCrsPorts) {
+			return errors.New(fmt.Sprintf("Invalid nrofCRS-Ports(=%v) of lte-CRS-ToMatchAround, which can be n1/n2/n4.", flags.dss.lteNumCrsPorts))
+		}
+
+		if !utils.ContainsStr([]string{"n0", "n1", "n2", "n3", "n4", "n5"}, flags.dss.lteVShift) {
+			return errors.New(fmt.Sprintf("Invalid v-Shift(=%v) of lte-CRS-ToMatchAround, which can be n0..n5.", flags.dss.lteVShift))
+		}
+
+		// refer to 3GPP 36.331 vh10
+		// MBSFN-SubframeConfig field descriptions
+		// oneFrame: FDD: The first/leftmost bit defines the MBSFN allocation for subframe #1, the second bit for #2, third bit for #3, fourth bit for #6, fifth bit for #7, sixth bit for #8. TDD: The first/leftmost bit defines the allocation for subframe #3, the second bit for #4, third bit for #7, fourth bit for #8, fifth bit for #9.
+		// Note: MBSFN subframes are assumed to be the same in every radio frame(i.e. radioframeAllocationPeriod = n1 and oneFrame is used).
+		mbsfnSubframes := map[string][]int{"FDD": {1, 2, 3, 6, 7, 8}, "TDD": {3, 4, 7, 8, 9}}[flags.gridsetting._duplexMode]
+		for _, sf := range flags.dss.lteMbsfnSubframes {
+			if !utils.ContainsInt(mbsfnSubframes, sf) {
+				return errors.New(fmt.Sprintf("Invalid MBSFN subframes(=%v) of lte-CRS-ToMatchAround, which can be %v for %v.", flags.dss.lteMbsfnSubframes, mbsfnSubframes, flags.gridsetting._duplexMode))
 			}
 		}
 
-		O := float64(p.O8) / 8
-		M := float64(p.M2) / 2
-		var u int
-		if flags.gridsetting._freqRange == "FR1" || flags.gridsetting._freqRange == "FR2-1" {
-			u = nrgrid.Scs2Mu[flags.gridsetting._mibCommonScs]
-		} else if flags.gridsetting._freqRange == "FR2-2" {
-			u = nrgrid.Scs2Mu[flags.gridsetting._ssbScs]
+		if flags.dss.ltePdcchNumSymbs < 1 || flags.dss.ltePdcchNumSymbs > 3 {
+			return errors.New(fmt.Sprintf("Invalid number of OFDM symbols(=%v) of LTE PDCCH region, which can be 1..3.", flags.dss.ltePdcchNumSymbs))
+		}
+	}
+
+	// refer to 3GPP 38.331 vh30
+	// RateMatchPattern field descriptions
+	if len(flags.dss.rmpRbBitmap) > 0 {
+		isBitmap := func(bits string) bool {
+			return len(strings.Trim(bits, "01")) == 0
 		}
 
-		for _, issb := range flags.gridsetting.candSsbIndex {
-			v := O*math.Exp2(float64(u)) + math.Floor(float64(issb)*M)
-			n0 := int(v) % rgd.slotPerRf
+		// resourceBlocks: A resource block level bitmap in the frequency domain. A bit in the bitmap set to 1 indicates that the UE shall apply rate matching in the corresponding resource block in accordance with the symbolsInResourceBlock bitmap. If used as cell-level rate matching pattern, the bitmap identifies "common resource blocks (CRB)". If used as BWP-level rate matching pattern, the bitmap identifies "physical resource blocks" inside the bandwidth part. The first/ leftmost bit corresponds to resource block 0, and so on.
+		// Note: RateMatchPattern is assumed to be cell-level rate matching pattern.
+		if !isBitmap(flags.dss.rmpRbBitmap) || len(flags.dss.rmpRbBitmap) > 275 {
+			return errors.New(fmt.Sprintf("Invalid resourceBlocks(=%v) of RateMatchPattern, which must be a bitmap of up to 275 bits.", flags.dss.rmpRbBitmap))
+		}
 
-			v2 := utils.FloorInt(v / float64(rgd.slotPerRf))
-			sfnc := sfn
-			if !((v2%2 == 0 && sfnc%2 == 0) || (v2%2 == 1 && sfnc%2 == 1)) {
-				sfnc++
-				err := alwaysOnTr(sfnc, 0)
-				if err != nil {
-					return err
-				}
+		// symbolsInResourceBlock: A symbol level bitmap in time domain. It indicates with a bit set to true that the UE shall rate match around the corresponding symbol. This pattern recurs (in time domain) with the configured periodicityAndPattern.
+		// Note: only oneSlot(i.e. 14 bits for normal CP) is supported.
+		if !isBitmap(flags.dss.rmpSymbBitmap) || len(flags.dss.rmpSymbBitmap) != getSymbPerSlot(flags.bwp._bwpCp[DED_DL_BWP]) {
+			return errors.New(fmt.Sprintf("Invalid symbolsInResourceBlock(=%v) of RateMatchPattern, which must be a bitmap of %v bits.", flags.dss.rmpSymbBitmap, getSymbPerSlot(flags.bwp._bwpCp[DED_DL_BWP])))
+		}
+
+		// periodicityAndPattern: A time domain repetition pattern at which the pattern defined by symbolsInResourceBlock and resourceBlocks recurs. This slot pattern repeats itself continuously. Absence of this field indicates the value n1, i.e., the symbolsInResourceBlock recurs every 14 symbols.
+		if pat := flags.dss.rmpPeriodicityAndPattern; len(pat) > 0 && (!isBitmap(pat) || !utils.ContainsInt([]int{2, 4, 5, 8, 10, 20, 40}, len(pat))) {
+			return errors.New(fmt.Sprintf("Invalid periodicityAndPattern(=%v) of RateMatchPattern, which must be a bitmap of 2/4/5/8/10/20/40 bits.", pat))
+		}
+	}
+
+	return nil
+}
+
+// validateBwpSw validates additional dedicated BWPs, bwp-InactivityTimer and BWP switchings.
+func validateBwpSw() error {
+	regYellow.Printf("-->calling validateBwpSw\n")
+
+	if flags.bwp.bwpSwDelayType != "type1" && flags.bwp.bwpSwDelayType != "type2" {
+		return errors.New(fmt.Sprintf("Invalid bwpSwDelayType(=%v), which can be type1 or type2!", flags.bwp.bwpSwDelayType))
+	}
+
+	if len(flags.bwp.dlBwpId) == 0 && len(flags.bwp.ulBwpId) == 0 && len(flags.bwp.bwpSwTrigger) == 0 && len(flags.bwp.bwpInactivityTimer) == 0 {
+		return nil
+	}
+
+	// Note: the additional dedicated BWPs are not supported when the supplementary carrier is used.
+	if flags.gridsetting.supUsed {
+		return errors.New("Additional dedicated BWPs and BWP switching are not supported when the supplementary carrier is used!")
+	}
+
+	for _, dir := range []string{"DL", "UL"} {
+		ids, scs, cp, startRb, numRbs := flags.bwp.dlBwpId, flags.bwp.dlBwpScs, flags.bwp.dlBwpCp, flags.bwp.dlBwpStartRb, flags.bwp.dlBwpNumRbs
+		if dir == "UL" {
+			ids, scs, cp, startRb, numRbs = flags.bwp.ulBwpId, flags.bwp.ulBwpScs, flags.bwp.ulBwpCp, flags.bwp.ulBwpStartRb, flags.bwp.ulBwpNumRbs
+		}
+
+		for _, v := range []int{len(scs), len(cp), len(startRb), len(numRbs)} {
+			if v != len(ids) {
+				return errors.New(fmt.Sprintf("The %vBwpScs(=%v), %vBwpCp(=%v), %vBwpStartRb(=%v) and %vBwpNumRbs(=%v) must have the same length as %vBwpId(=%v)!", strings.ToLower(dir), scs, strings.ToLower(dir), cp, strings.ToLower(dir), startRb, strings.ToLower(dir), numRbs, strings.ToLower(dir), ids))
+			}
+		}
+
+		for i, id := range ids {
+			// refer to 3GPP 38.331 vh30
+			// BWP-Id ::= INTEGER (0..maxNrofBWPs), where maxNrofBWPs = 4
+			// bwp-Id: An identifier for this bandwidth part. ... The BWP ID=0 is always associated with the initial BWP and may hence not be used here (in other bandwidth parts).
+			// Note: bwp-Id 1 is used by the dedicated DL/UL BWP.
+			if id < 2 || id > 4 || utils.IndexInt(ids, id) != i {
+				return errors.New(fmt.Sprintf("Invalid %vBwpId(=%v), which must be unique values within [2, 4]!", strings.ToLower(dir), ids))
 			}
 
-			var firstSymb int
-			if len(p.FirstSymbs) == 2 {
-				firstSymb = p.FirstSymbs[issb%2]
-			} else {
-				firstSymb = p.FirstSymbs[0]
+			if _, exist := nrgrid.Scs2Mu[scs[i]]; !exist {
+				return errors.New(fmt.Sprintf("Invalid subcarrierSpacing(=%v) of %v BWP%v!", scs[i], dir, id))
+			}
+			scsVal, _ := strconv.Atoi(scs[i][:len(scs[i])-3])
+			nrb := getNrb(flags.gridsetting._freqRange, flags.gridsetting.bw, scsVal)
+			if nrb == 0 {
+				return errors.New(fmt.Sprintf("The subcarrierSpacing(=%v) of %v BWP%v is not supported for carrier bandwidth(=%v) of %v!", scs[i], dir, id, flags.gridsetting.bw, flags.gridsetting._freqRange))
+			}
+
+			// refer to 3GPP 38.211 vh40
+			// 4.2	Numerologies: Table 4.2-1: Supported transmission numerologies, where extended cyclic prefix is only supported for u=2(60KHz).
+			if cp[i] != "normal" && !(cp[i] == "extended" && scs[i] == "60KHz") {
+				return errors.New(fmt.Sprintf("Invalid cyclicPrefix(=%v) of %v BWP%v, which must be normal, or extended for 60KHz SCS!", cp[i], dir, id))
+			}
+
+			// Note: the carrier bandwidth with subcarrierSpacing of the BWP is assumed to start from the same frequency as the carrier, i.e. offsetToCarrier is the same in units of Hz.
+			if startRb[i] < 0 || numRbs[i] < 1 || startRb[i]+numRbs[i] > nrb {
+				return errors.New(fmt.Sprintf("Invalid %v BWP%v: RB_start=%v, L_RBs=%v, while carrier bandwidth is %v RBs with subcarrierSpacing of %v!", dir, id, startRb[i], numRbs[i], nrb, scs[i]))
+			}
+
+			// Note: the same time domain resource allocation of DCI 1_1/0_1 is used for additional dedicated BWPs.
+			S, L := flags.dldci._tdStartSymb[DCI_11_PDSCH], flags.dldci._tdNumSymbs[DCI_11_PDSCH]
+			if dir == "UL" {
+				S, L = flags.uldci._tdStartSymb[DCI_01_PUSCH], flags.uldci._tdNumSymbs[DCI_01_PUSCH]
+			}
+			if S+L > getSymbPerSlot(cp[i]) {
+				return errors.New(fmt.Sprintf("The time domain resource allocation(S=%v, L=%v) exceeds the slot of %v BWP%v with %v cyclic prefix!", S, L, dir, id, cp[i]))
+			}
+
+			// Note: the CORESET of additional dedicated DL BWP has the same configuration as CORESET1 and starts from the first RB of the BWP.
+			if dir == "DL" && flags.searchspace.coreset1NumRbs > numRbs[i] {
+				return errors.New(fmt.Sprintf("The CORESET(coreset1NumRbs=%v) exceeds the DL BWP%v(L_RBs=%v)!", flags.searchspace.coreset1NumRbs, id, numRbs[i]))
 			}
 
-			key := fmt.Sprintf("%v_%v", sfn, issb)
-			_, exist := rgd.css0TdOccasions[key]
-			if !exist {
-				rgd.css0TdOccasions[key] = append(rgd.css0TdOccasions[key], nrgrid.Css0OccasionTd{sfnc, n0, firstSymb})
-				if u < 4 {
-					rgd.css0TdOccasions[key] = append(rgd.css0TdOccasions[key], nrgrid.Css0OccasionTd{sfnc, n0 + 1, firstSymb})
-				} else if u == 5 {
-					rgd.css0TdOccasions[key] = append(rgd.css0TdOccasions[key], nrgrid.Css0OccasionTd{sfnc, n0 + 4, firstSymb})
-				} else if u == 6 {
-					rgd.css0TdOccasions[key] = append(rgd.css0TdOccasions[key], nrgrid.Css0OccasionTd{sfnc, n0 + 8, firstSymb})
+			// refer to 3GPP 38.211 vh40
+			// Table 7.4.1.1.2-5: PDSCH DM-RS positions l- for single-symbol DM-RS and extended cyclic prefix
+			// Table 6.4.1.1.3-4: PUSCH DM-RS positions l- within a slot for single-symbol DM-RS, intra-slot frequency hopping disabled and extended cyclic prefix
+			if cp[i] == "extended" {
+				var tdL []int
+				mappingType, addPos := flags.dldci._tdMappingType[DCI_11_PDSCH], flags.pdsch.pdschDmrsAddPos
+				if dir == "DL" {
+					tdL, _ = getDmrsPdschTdFdPattern(flags.pdsch.pdschDmrsType, mappingType, S, L, flags.pdsch._numFrontLoadSymbs, addPos, flags.pdsch._cdmGroupsWoData, cp[i])
+				} else {
+					mappingType, addPos = flags.uldci._tdMappingType[DCI_01_PUSCH], flags.pusch.puschDmrsAddPos
+					tdL, _, _ = getDmrsPuschTdFdPattern(flags.pusch.puschDmrsType, mappingType, S, L, flags.pusch._numFrontLoadSymbs, addPos, flags.pusch._cdmGroupsWoData, "disabled", cp[i])
+				}
+				if tdL == nil {
+					return errors.New(fmt.Sprintf("Invalid DMRS of %v BWP%v with extended cyclic prefix(mappingType=%v, S=%v, L=%v, dmrsAdditionalPosition=%v), where dmrs-AdditionalPosition must be pos0 or pos1!", dir, id, mappingType, S, L, addPos))
 				}
 			}
-		}
-	} else if flags.gridsetting._coreset0MultiplexingPat == 2 {
-		// refer to 38.213 vh40
-		// 13	UE procedure for monitoring Type0-PDCCH CSS sets
-		// Table 13-13: PDCCH monitoring occasions for Type0-PDCCH CSS set - SS/PBCH block and CORESET multiplexing pattern 2 and {SS/PBCH block, PDCCH} SCS {120, 60} kHz
-		// Table 13-14: PDCCH monitoring occasions for Type0-PDCCH CSS set - SS/PBCH block and CORESET multiplexing pattern 2 and {SS/PBCH block, PDCCH} SCS {240, 120} kHz
-		for _, issb := range flags.gridsetting.candSsbIndex {
-			sfnc := sfn
-			nc := rgd.ssbFirstSymbs[issb] / rgd.symbPerSlot
 
-			var firstSymb int
-			if flags.gridsetting._ssbScs == "120KHz" && flags.gridsetting._mibCommonScs == "60KHz" {
-				firstSymb = []int{0, 1, 6, 7}[issb%4]
-			} else if flags.gridsetting._ssbScs == "240KHz" && flags.gridsetting._mibCommonScs == "120KHz" {
-				if issb%8 == 4 || issb%8 == 5 {
-					nc--
-					firstSymb = []int{12, 13}[issb%8-4]
-				} else {
-					if issb%8 < 4 {
-						firstSymb = issb % 8
-					} else if issb%8 > 5 {
-						firstSymb = issb%8 - 6
+			// Note: the dedicated PUCCH resources of additional dedicated UL BWP have the same configuration as the dedicated UL BWP, which must be within the slot of the BWP(e.g. 12 symbols for extended cyclic prefix).
+			if dir == "UL" {
+				for r := range flags.pucch._pucchResId {
+					if flags.pucch._pucchStartSymb[r]+flags.pucch._pucchNumSymbs[r] > getSymbPerSlot(cp[i]) {
+						return errors.New(fmt.Sprintf("The symbols(startingSymbolIndex=%v, nrofSymbols=%v) of PUCCH(pucchResId=%v) exceed the slot of UL BWP%v with %v cyclic prefix!", flags.pucch._pucchStartSymb[r], flags.pucch._pucchNumSymbs[r], flags.pucch._pucchResId[r], id, cp[i]))
 					}
 				}
 			}
 
-			key := fmt.Sprintf("%v_%v", sfn, issb)
-			_, exist := rgd.css0TdOccasions[key]
-			if !exist {
-				rgd.css0TdOccasions[key] = append(rgd.css0TdOccasions[key], nrgrid.Css0OccasionTd{sfnc, nc, firstSymb})
+			// Note: the PRBs of the dedicated PUCCH resources of additional dedicated UL BWP are relative to RB_start of the BWP.
+			if dir == "UL" && !flags.bwp.useInterlace {
+				for r := range flags.pucch._pucchResId {
+					for _, rb := range []int{flags.pucch._pucchStartRb[r], flags.pucch._pucchSecondHopPrb[r]} {
+						if rb+flags.pucch._pucchNumRbs[r] > numRbs[i] {
+							return errors.New(fmt.Sprintf("PRBs(startingPRB=%v, secondHopPRB=%v, numRbs=%v) of PUCCH(pucchResId=%v) exceed the UL BWP%v(L_RBs=%v)!", flags.pucch._pucchStartRb[r], flags.pucch._pucchSecondHopPrb[r], flags.pucch._pucchNumRbs[r], flags.pucch._pucchResId[r], id, numRbs[i]))
+						}
+					}
+				}
 			}
 		}
-	} else {
-		// refer to 38.213 vh40
-		// 13	UE procedure for monitoring Type0-PDCCH CSS sets
-		// Table 13-15: PDCCH monitoring occasions for Type0-PDCCH CSS set - SS/PBCH block and CORESET multiplexing pattern 3 and {SS/PBCH block, PDCCH} SCS {120, 120} kHz
-		// Table 13-15A: PDCCH monitoring occasions for Type0-PDCCH CSS set - SS/PBCH block and CORESET multiplexing pattern 3 and {SS/PBCH block, PDCCH} SCS {480, 480} kHz or {960, 960} kHz
-		for _, issb := range flags.gridsetting.candSsbIndex {
-			sfnc := sfn
-			nc := rgd.ssbFirstSymbs[issb] / rgd.symbPerSlot
-
-			var firstSymb int
-			if flags.gridsetting._ssbScs == "120KHz" && flags.gridsetting._mibCommonScs == "120KHz" {
-				firstSymb = []int{4, 8, 2, 6}[issb%4]
-			} else if flags.gridsetting._ssbScs == "480KHz" || flags.gridsetting._ssbScs == "960KHz" {
-				firstSymb = []int{2, 9}[issb%2]
-			}
+	}
 
-			key := fmt.Sprintf("%v_%v", sfn, issb)
-			_, exist := rgd.css0TdOccasions[key]
-			if !exist {
-				rgd.css0TdOccasions[key] = append(rgd.css0TdOccasions[key], nrgrid.Css0OccasionTd{sfnc, nc, firstSymb})
+	// refer to 3GPP 38.213 vh40
+	// 12	Bandwidth part operation
+	// For unpaired spectrum operation, a DL BWP from the set of configured DL BWPs with index provided by BWP-Id is linked with an UL BWP from the set of configured UL BWPs with index provided by BWP-Id when the DL BWP index and the UL BWP index are same.
+	if flags.gridsetting._duplexMode == "TDD" {
+		for i, id := range flags.bwp.dlBwpId {
+			k := utils.IndexInt(flags.bwp.ulBwpId, id)
+			if k < 0 || flags.bwp.ulBwpScs[k] != flags.bwp.dlBwpScs[i] || len(flags.bwp.ulBwpId) != len(flags.bwp.dlBwpId) {
+				return errors.New(fmt.Sprintf("For unpaired spectrum, the additional dedicated DL BWPs(dlBwpId=%v, dlBwpScs=%v) and UL BWPs(ulBwpId=%v, ulBwpScs=%v) must be linked with the same bwp-Id and subcarrierSpacing!", flags.bwp.dlBwpId, flags.bwp.dlBwpScs, flags.bwp.ulBwpId, flags.bwp.ulBwpScs))
 			}
 		}
 	}
 
-	// TODO: validate PDCCH occasions of CSS0 --> 2023/3/27: moved to aotPdcchSib1
-	fmt.Printf("[SFN=%v, candSsbIndex=%v] css0TdOccasions=%v\n", sfn, flags.gridsetting.candSsbIndex, rgd.css0TdOccasions)
-	rgd.occCss0[sfn] = true
-
-	return nil
-}
-
-// detCcesPerPdcchCand determines the CCE indexes for aggregation level L corresponding to PDCCH candidate m of the search space set in slot n for an active DL BWP of a serving cell corresponding to carrier indicator field value n_CI For a search space set s associated with CORESET p
-//  p: CORESET index
-//  L: aggregation level
-//  m: PDCCH candidate index
-//  n: slot index
-//  sst: search space type, which can be type0, type0a, type1, type2, type3 or uss
-//  nRNTI: the n_RNTI
-//  NCCE: the N_CCE
-//  nCI: the n_CI
-//  Mmax: the M_max
-func detCcesPerPdcchCand(p int, L int, m int, n int, sst string, nRNTI int, NCCE int, nCI int, Mmax int) ([]int, error) {
-	if sst == "uss" && nRNTI == 0 {
-		return nil, errors.New(fmt.Sprintf("For a USS, the nRNTI must not be 0!"))
+	// refer to 3GPP 38.331 vh30
+	// ServingCellConfig field descriptions
+	// defaultDownlinkBWP-Id: The initial bandwidth part is referred to by BWP-Id = 0. ... If the field is absent, the initial bandwidth part is used as default DL BWP.
+	// bwp-InactivityTimer: The duration in ms after which the UE falls back to the default Bandwidth Part.
+	// Note: the default DL BWP must be the dedicated DL BWP(bwp-Id 1) or an additional dedicated DL BWP.
+	if flags.bwp.defaultDlBwpId != 1 && utils.IndexInt(flags.bwp.dlBwpId, flags.bwp.defaultDlBwpId) < 0 {
+		return errors.New(fmt.Sprintf("Invalid defaultDlBwpId(=%v), which must be 1 or one of dlBwpId(=%v)!", flags.bwp.defaultDlBwpId, flags.bwp.dlBwpId))
+	}
+	if len(flags.bwp.bwpInactivityTimer) > 0 && !utils.ContainsStr([]string{"ms2", "ms3", "ms4", "ms5", "ms6", "ms8", "ms10", "ms20", "ms30", "ms40", "ms50", "ms60", "ms80", "ms100", "ms200", "ms300", "ms500", "ms750", "ms1280", "ms1920", "ms2560"}, flags.bwp.bwpInactivityTimer) {
+		return errors.New(fmt.Sprintf("Invalid bwpInactivityTimer(=%v)!", flags.bwp.bwpInactivityTimer))
 	}
 
-	var Y int
-	if sst != "uss" {
-		Y = 0
-	} else {
-		var Ap int
-		if p%3 == 0 {
-			Ap = 39827
-		} else if p%3 == 1 {
-			Ap = 39829
-		} else {
-			Ap = 39839
-		}
-		D := 65537
-		for k := -1; k <= n; k++ {
-			if k == -1 {
-				Y = nRNTI
-			} else {
-				Y = (Ap * Y) % D
-			}
+	n := len(flags.bwp.bwpSwTrigger)
+	for _, v := range []int{len(flags.bwp.bwpSwDir), len(flags.bwp.bwpSwSfn), len(flags.bwp.bwpSwSlot), len(flags.bwp.bwpSwBwpId)} {
+		if v != n {
+			return errors.New(fmt.Sprintf("The bwpSwDir(=%v), bwpSwSfn(=%v), bwpSwSlot(=%v) and bwpSwBwpId(=%v) must have the same length as bwpSwTrigger(=%v)!", flags.bwp.bwpSwDir, flags.bwp.bwpSwSfn, flags.bwp.bwpSwSlot, flags.bwp.bwpSwBwpId, flags.bwp.bwpSwTrigger))
 		}
 	}
-
-	v1 := Y + utils.FloorInt(float64(m*NCCE)/float64(L*Mmax)) + nCI
-	v2 := utils.FloorInt(float64(NCCE) / float64(L))
-	v3 := v1 % v2
-
-	var cces []int
-	for i := 0; i < L; i++ {
-		cces = append(cces, L*v3+i)
+	slotPerRf := int(math.Exp2(float64(nrgrid.Scs2Mu[flags.gridsetting.scs]))) * 10
+	for i, trigger := range flags.bwp.bwpSwTrigger {
+		dir := flags.bwp.bwpSwDir[i]
+		ids := map[string][]int{"DL": flags.bwp.dlBwpId, "UL": flags.bwp.ulBwpId}[dir]
+		if (trigger != "dci" && trigger != "rrc") || (dir != "DL" && dir != "UL") {
+			return errors.New(fmt.Sprintf("Invalid BWP switching(bwpSwTrigger=%v, bwpSwDir=%v), where bwpSwTrigger can be dci or rrc, and bwpSwDir can be DL or UL!", trigger, dir))
+		}
+		if id := flags.bwp.bwpSwBwpId[i]; id != 1 && !utils.ContainsInt(ids, id) {
+			return errors.New(fmt.Sprintf("Invalid bwpSwBwpId(=%v) of %v BWP switching, which must be 1 or one of %vBwpId(=%v)!", id, dir, strings.ToLower(dir), ids))
+		}
+		if flags.bwp.bwpSwSfn[i] < 0 || flags.bwp.bwpSwSfn[i] > 1023 || flags.bwp.bwpSwSlot[i] < 0 || flags.bwp.bwpSwSlot[i] >= slotPerRf {
+			return errors.New(fmt.Sprintf("Invalid [sfn, slot](=[%v, %v]) of BWP switching!", flags.bwp.bwpSwSfn[i], flags.bwp.bwpSwSlot[i]))
+		}
 	}
 
-	return cces, nil
-}
-
-func aotSib1(sfn int) error {
-
 	return nil
 }
 
-func aotCsi(sfn, slot int) error {
-
-	return nil
-}
+// validateTddSfi validates TDD-UL-DL-ConfigDedicated and SlotFormatIndicator of DCI 2_0.
+func validateTddSfi() error {
+	regYellow.Printf("-->calling validateTddSfi\n")
 
-func aotSrs(sfn, slot int) error {
+	if len(flags.tdduldl.dedSlotIndex) == 0 && flags.tdduldl.sfiPeriod == 0 {
+		return nil
+	}
 
-	return nil
-}
+	if flags.gridsetting._duplexMode != "TDD" {
+		return errors.New("TDD-UL-DL-ConfigDedicated and SFI are only supported for unpaired spectrum!")
+	}
 
-func updateRach() error {
-	regYellow.Printf("-->calling updateRach\n")
+	// the number of slots of the periodicity(P+P2) of TDD-UL-DL-ConfigCommon
+	symbPerSlot := getSymbPerSlot(flags.bwp._bwpCp[DED_DL_BWP])
+	slotPerSubf := int(math.Exp2(float64(nrgrid.Scs2Mu[flags.gridsetting.scs])))
+	period := 0.0
+	for _, p := range flags.tdduldl.patPeriod {
+		v, _ := strconv.ParseFloat(p[:len(p)-2], 64)
+		period += v
+	}
+	numSlots := int(period * float64(slotPerSubf))
 
-	var p *nrgrid.RachInfo
-	var exist bool
-	if flags.gridsetting._freqRange == "FR1" {
-		if flags.gridsetting._duplexMode == "FDD" {
-			p, exist = nrgrid.RaCfgFr1FddSUl[flags.rach.prachConfId]
-		} else {
-			p, exist = nrgrid.RaCfgFr1Tdd[flags.rach.prachConfId]
+	n := len(flags.tdduldl.dedSlotIndex)
+	for _, v := range []int{len(flags.tdduldl.dedSymbols), len(flags.tdduldl.dedNumDlSymbs), len(flags.tdduldl.dedNumUlSymbs)} {
+		if v != n {
+			return errors.New(fmt.Sprintf("The dedSymbols(=%v), dedNumDlSymbs(=%v) and dedNumUlSymbs(=%v) must have the same length as dedSlotIndex(=%v)!", flags.tdduldl.dedSymbols, flags.tdduldl.dedNumDlSymbs, flags.tdduldl.dedNumUlSymbs, flags.tdduldl.dedSlotIndex))
+		}
+	}
+	for i, idx := range flags.tdduldl.dedSlotIndex {
+		// refer to 3GPP 38.331 vh30
+		// TDD-UL-DL-SlotIndex ::= INTEGER (0..maxNrofSlots-1), where maxNrofSlots = 320
+		// Note: slotIndex must be within the periodicity(P+P2) of TDD-UL-DL-ConfigCommon.
+		if idx < 0 || idx >= numSlots || utils.IndexInt(flags.tdduldl.dedSlotIndex, idx) != i {
+			return errors.New(fmt.Sprintf("Invalid dedSlotIndex(=%v), which must be unique values within [0, %v] for patPeriod(=%v)!", flags.tdduldl.dedSlotIndex, numSlots-1, flags.tdduldl.patPeriod))
+		}
+		if !utils.ContainsStr([]string{"allDownlink", "allUplink", "explicit"}, flags.tdduldl.dedSymbols[i]) {
+			return errors.New(fmt.Sprintf("Invalid dedSymbols(=%v) of slotIndex %v, which can be allDownlink, allUplink or explicit!", flags.tdduldl.dedSymbols[i], idx))
+		}
+		numDlSymbs, numUlSymbs := flags.tdduldl.dedNumDlSymbs[i], flags.tdduldl.dedNumUlSymbs[i]
+		if flags.tdduldl.dedSymbols[i] == "explicit" && (numDlSymbs < 0 || numUlSymbs < 0 || numDlSymbs+numUlSymbs > symbPerSlot) {
+			return errors.New(fmt.Sprintf("Invalid explicit symbols of slotIndex %v: nrofDownlinkSymbols=%v, nrofUplinkSymbols=%v, while the number of symbols per slot is %v!", idx, numDlSymbs, numUlSymbs, symbPerSlot))
 		}
-	} else {
-		p, exist = nrgrid.RaCfgFr2Tdd[flags.rach.prachConfId]
 	}
 
-	if !exist {
-		return errors.New(fmt.Sprintf("Invalid configurations for PRACH: %v,%v with prach-ConfigurationIndex=%v\n",
-			flags.gridsetting._freqRange, flags.gridsetting._duplexMode, flags.rach.prachConfId))
+	if flags.tdduldl.sfiPeriod == 0 {
+		return nil
 	}
 
-	fmt.Printf("RACH Info: %v\n", *p)
+	// refer to 3GPP 38.213 vh40
+	// 11.1.1	UE procedure for determining slot format
+	// Note: only the slot formats for normal cyclic prefix in Table 11.1.1-1 are supported.
+	if symbPerSlot != 14 {
+		return errors.New("SFI is only supported for normal cyclic prefix!")
+	}
 
-	flags.rach._raFormat = p.Format
-	flags.rach._raX = p.X
-	flags.rach._raY = p.Y
-	flags.rach._raSubfNumFr1SlotNumFr2 = p.SubfNumFr1SlotNumFr2
-	flags.rach._raStartingSymb = p.StartingSymb
-	flags.rach._raNumSlotsPerSubfFr1Per60KSlotFr2 = p.NumSlotsPerSubfFr1Per60KSlotFr2
-	flags.rach._raNumOccasionsPerSlot = p.NumOccasionsPerSlot
-	flags.rach._raDuration = p.Duration
+	// refer to 3GPP 38.331 vh30
+	// SearchSpace field descriptions
+	// monitoringSlotPeriodicityAndOffset: For DCI format 2_0, only the values 'sl1', 'sl2', 'sl4', 'sl5', 'sl8', 'sl10', 'sl16', and 'sl20' are applicable.
+	if !utils.ContainsInt([]int{1, 2, 4, 5, 8, 10, 16, 20}, flags.tdduldl.sfiPeriod) || flags.tdduldl.sfiOffset < 0 || flags.tdduldl.sfiOffset >= flags.tdduldl.sfiPeriod {
+		return errors.New(fmt.Sprintf("Invalid sfiPeriod(=%v) and sfiOffset(=%v), where sfiPeriod can be 1/2/4/5/8/10/16/20 slots, and sfiOffset must be within [0, sfiPeriod-1]!", flags.tdduldl.sfiPeriod, flags.tdduldl.sfiOffset))
+	}
 
-	var raScsSet []string
-	if flags.rach._raFormat == "0" || flags.rach._raFormat == "1" || flags.rach._raFormat == "2" || flags.rach._raFormat == "3" {
-		raScsSet = append(raScsSet, nrgrid.ScsRaLongPrach["839_"+flags.rach._raFormat])
-	} else {
-		if flags.gridsetting._freqRange == "FR1" {
-			raScsSet = append(raScsSet, []string{"15KHz", "30KHz"}...)
-		} else if flags.gridsetting._freqRange == "FR2-1" {
-			raScsSet = append(raScsSet, []string{"60KHz", "120KHz"}...)
-		} else if flags.gridsetting._freqRange == "FR2-2" {
-			raScsSet = append(raScsSet, []string{"120KHz", "480KHz", "960KHz"}...)
+	// refer to 3GPP 38.331 vh30
+	// SlotFormatCombinationsPerCell field descriptions
+	// subcarrierSpacing: Reference subcarrier spacing for this Slot Format Combination. Only the values 15 kHz or 30 kHz or 60 kHz (FR1), and 60 kHz or 120 kHz (FR2) are applicable.
+	// refer to 3GPP 38.213 vh40
+	// 11.1.1	UE procedure for determining slot format
+	// A UE configured ... with a reference SCS configuration μSFI ... expects that μ ≥ μSFI.
+	if len(flags.tdduldl.sfcScs) > 0 {
+		validScs := map[bool][]string{true: {"15KHz", "30KHz", "60KHz"}, false: {"60KHz", "120KHz"}}[flags.gridsetting._freqRange == "FR1"]
+		if !utils.ContainsStr(validScs, flags.tdduldl.sfcScs) || nrgrid.Scs2Mu[flags.tdduldl.sfcScs] > nrgrid.Scs2Mu[flags.gridsetting.scs] {
+			return errors.New(fmt.Sprintf("Invalid sfcScs(=%v), which can be %v and must be no larger than the subcarrierSpacing of the carrier(=%v)!", flags.tdduldl.sfcScs, validScs, flags.gridsetting.scs))
 		}
 	}
-	fmt.Printf("Available short PRACH SCS(msg1-SubcarrierSpacing of RACH-ConfigCommon): %v\n", raScsSet)
 
-	if utils.ContainsStr([]string{"0", "1", "2", "3"}, flags.rach._raFormat) {
-		flags.rach._raLen = 839
-		if flags.rach._raFormat == "3" {
-			flags.rach._msg1Scs = "5KHz"
-		} else {
-			flags.rach._msg1Scs = "1.25KHz"
-		}
-	} else {
-		// refer to 38.211 vh40 Table 6.3.3.1-2
-		// L_RA=1151/571 for NR-U are not supported!
-		flags.rach._raLen = 139
-		flags.rach._msg1Scs = flags.gridsetting.scs
+	// refer to 3GPP 38.213 vh40
+	// 10.1	UE procedure for determining physical downlink control channel assignment
+	// ... a Type3-PDCCH CSS set configured by SearchSpace in PDCCH-Config with searchSpaceType = common for DCI formats 2_0 ...
+	if iss := utils.IndexStr(flags.searchspace._ssType, "type3"); iss < 0 || flags.searchspace._ssCoresetId[iss] != 1 {
+		return errors.New("Type3-PDCCH CSS must be configured in CORESET1 for DCI 2_0!")
 	}
 
-	key := fmt.Sprintf("%v_%v_%v", flags.rach._raLen, flags.rach._msg1Scs[:len(flags.rach._msg1Scs)-3], flags.gridsetting.scs[:len(flags.gridsetting.scs)-3])
-	p2, exist2 := nrgrid.NumRbsRaAndKBar[key]
-	if !exist2 {
-		return errors.New(fmt.Sprintf("Invalid key(=%v) when referring NumRbsRaAndKBar!\n", key))
+	if len(flags.tdduldl.sfcSlotFormats) != len(flags.tdduldl.sfcId) || len(flags.tdduldl.sfcId) == 0 {
+		return errors.New(fmt.Sprintf("The sfcSlotFormats(=%v) must have the same length as sfcId(=%v), which must not be empty!", flags.tdduldl.sfcSlotFormats, flags.tdduldl.sfcId))
 	}
-	flags.rach._raNumRbs = p2[0]
-	flags.rach._raKBar = p2[1]
+	for i, id := range flags.tdduldl.sfcId {
+		// refer to 3GPP 38.331 vh30
+		// SlotFormatCombinationId ::= INTEGER (0..maxNrofSlotFormatCombinationsPerSet-1), where maxNrofSlotFormatCombinationsPerSet = 512
+		// slotFormats: SEQUENCE (SIZE (1..maxNrofSlotFormatsPerCombination)) OF INTEGER (0..255), where maxNrofSlotFormatsPerCombination = 256
+		if id < 0 || id > 511 || utils.IndexInt(flags.tdduldl.sfcId, id) != i {
+			return errors.New(fmt.Sprintf("Invalid sfcId(=%v), which must be unique values within [0, 511]!", flags.tdduldl.sfcId))
+		}
+		formats := strings.Split(flags.tdduldl.sfcSlotFormats[i], "_")
+		if len(formats) > 256 {
+			return errors.New(fmt.Sprintf("Invalid sfcSlotFormats(=%v) of slotFormatCombinationId %v, which can contain at most 256 slot formats!", flags.tdduldl.sfcSlotFormats[i], id))
+		}
+		for _, f := range formats {
+			if v, err := strconv.Atoi(f); err != nil || v < 0 || (v >= len(nrgrid.SlotFormats) && v != 255) {
+				return errors.New(fmt.Sprintf("Invalid sfcSlotFormats(=%v) of slotFormatCombinationId %v, where each slot format must be within [0, %v] or 255!", flags.tdduldl.sfcSlotFormats[i], id, len(nrgrid.SlotFormats)-1))
+			}
+		}
 
-	// refer to 38.211 vh40
-	// 5.3.2	OFDM baseband signal generation for PRACH
-	// - n_RA_slot is given by
-	//   - if deltaf_RA is {30, 120}kHz and either of "Number of PRACH slots within a subframe" in Tables 6.3.3.2-2 to 6.3.3.2-3 or "Number of PRACH slots within a 60 kHz slot" in Table 6.3.3.2-4 is equal to 1, then n_RA_slot = 0, otherwise n_RA_slot = {0,1}
-	if flags.rach._raNumSlotsPerSubfFr1Per60KSlotFr2 == 2 && flags.rach._msg1Scs != "30KHz" && flags.rach._msg1Scs != "120KHz" {
-		return errors.New(fmt.Sprintf("Msg1 SCS must be 30KHz for FR1 or 120KHz for FR2-1 when NumSlotsPerSubfFr1Per60KSlotFr2 = 2!\n"))
+		// Note: the number of slots indicated by an SFI-index field value must be no less than the PDCCH monitoring periodicity for DCI 2_0 so that each slot is covered by a slot format, where each slot format is applicable to 2^(μ−μSFI) slots.
+		if len(formats)*getSfiSlotRatio() < flags.tdduldl.sfiPeriod {
+			return errors.New(fmt.Sprintf("The number of slots(=%v) indicated by slot formats(=%v) of slotFormatCombinationId %v with sfcScs(=%v) must be no less than sfiPeriod(=%v)!", len(formats)*getSfiSlotRatio(), len(formats), id, flags.tdduldl.sfcScs, flags.tdduldl.sfiPeriod))
+		}
 	}
 
-	// refer to 38.331 vh30 totalNumberOfRA-Preambles
-	// Total number of preambles used for contention based and contention free 4-step or 2-step random access in the RACH resources defined in RACH-ConfigCommon, excluding preambles used for other purposes (e.g. for SI request). If the field is absent, all 64 preambles are available for RA.
-	// The setting should be consistent with the setting of ssb-perRACH-OccasionAndCB-PreamblesPerSSB, i.e. it should be a multiple of the number of SSBs per RACH occasion.
-	if nrgrid.SsbPerRachOccasion2Float[flags.rach.ssbPerRachOccasion] > 1 && flags.rach.totNumPreambs%int(nrgrid.SsbPerRachOccasion2Float[flags.rach.ssbPerRachOccasion]) != 0 {
-		return errors.New(fmt.Sprintf("The totalNumberOfRA-Preambles should be a multiple of the number of SSBs per RACH occasion. (totNumPreambs=%v, ssbPerRachoccasion=%v)\n", flags.rach.totNumPreambs, flags.rach.ssbPerRachOccasion))
+	if len(flags.tdduldl.sfiIndex) == 0 {
+		return errors.New("The sfiIndex must not be empty when SFI is configured!")
+	}
+	for _, sfi := range flags.tdduldl.sfiIndex {
+		if !utils.ContainsInt(flags.tdduldl.sfcId, sfi) {
+			return errors.New(fmt.Sprintf("Invalid sfiIndex(=%v), which must be one of sfcId(=%v)!", flags.tdduldl.sfiIndex, flags.tdduldl.sfcId))
+		}
 	}
 
 	return nil
 }
 
-// convert ARFCN to F_REF(MHz) (refer to 38.104 vh80)
-//  Table 5.4.2.1-1: NR-ARFCN parameters for the global frequency raster
-func arfcn2Fref(arfcn int, maxFreq int) float64 {
-	if maxFreq < 3000 {
-		return float64(arfcn) * 0.005
-	} else if maxFreq < 24250 {
-		return 3000 + 0.015*(float64(arfcn)-600000)
-	} else {
-		return 24250.08 + 0.06*(float64(arfcn)-2016667)
+// validateMeas validates SSB-MTC, MeasGapConfig and neighbour cells.
+func validateMeas() error {
+	regYellow.Printf("-->calling validateMeas\n")
+
+	symbPerSubf := getSymbPerSlot(flags.bwp._bwpCp[DED_DL_BWP]) * int(math.Exp2(float64(nrgrid.Scs2Mu[flags.gridsetting.scs])))
+
+	// refer to 3GPP 38.331 vh30
+	// SSB-MTC field descriptions
+	// periodicityAndOffset: Periodicity and offset of the measurement window in which to receive SS/PBCH blocks, see 5.5.2.10. Periodicity and offset are given in number of subframes.
+	// duration: Duration of the measurement window in which to receive SS/PBCH blocks. It is given in number of subframes (see 5.5.2.10).
+	if len(flags.meas.smtcPeriodicity) > 0 {
+		if !utils.ContainsStr([]string{"sf5", "sf10", "sf20", "sf40", "sf80", "sf160"}, flags.meas.smtcPeriodicity) {
+			return errors.New(fmt.Sprintf("Invalid periodicity(=%v) of periodicityAndOffset of SSB-MTC, which can be sf5/sf10/sf20/sf40/sf80/sf160.", flags.meas.smtcPeriodicity))
+		}
+
+		period, _ := strconv.Atoi(flags.meas.smtcPeriodicity[2:])
+		if flags.meas.smtcOffset < 0 || flags.meas.smtcOffset >= period {
+			return errors.New(fmt.Sprintf("Invalid offset(=%v) of periodicityAndOffset of SSB-MTC, which can be 0..%v.", flags.meas.smtcOffset, period-1))
+		}
+
+		if !utils.ContainsStr([]string{"sf1", "sf2", "sf3", "sf4", "sf5"}, flags.meas.smtcDuration) {
+			return errors.New(fmt.Sprintf("Invalid duration(=%v) of SSB-MTC, which can be sf1..sf5.", flags.meas.smtcDuration))
+		}
 	}
-}
 
-// convert GSCN to SS_REF(MHz) (refer to 38.104 vh80)
-//  Table 5.4.3.1-1: GSCN parameters for the global frequency raster
-func gscn2Ssref(gscn int, maxFreq int) float64 {
-	if maxFreq < 3000 {
-		N := math.Floor((float64(gscn) + 1.5) / 3)
-		M := math.Mod(float64(gscn)+1.5, 3) * 2
-		ssRef := 1.2*N + 0.05*M
+	// refer to 3GPP 38.331 vh30
+	// GapConfig field descriptions
+	// gapOffset: Value gapOffset is the gap offset of the gap pattern with MGRP indicated in the field mgrp. The value range should be from 0 to mgrp-1.
+	// mgta: Value ms0 corresponds to 0 ms, ms0dot25 corresponds to 0.25 ms and ms0dot5 corresponds to 0.5 ms.
+	// Note: mgta of 0.5ms is only applicable to FR1, and mgta of 0.25ms is only applicable to FR2.
+	// Note: gapPatternId instead of mgl/mgrp is configured, and whether the gap pattern is supported by the UE(38.133 Table 9.1.2-2 and Table 9.1.2-3) is not checked.
+	if flags.meas.gapPatternId >= 0 {
+		gap, exist := nrgrid.MeasGapPatterns[flags.meas.gapPatternId]
+		if !exist {
+			return errors.New(fmt.Sprintf("Invalid gapPatternId(=%v) of MeasGapConfig, which can be 0..%v.", flags.meas.gapPatternId, len(nrgrid.MeasGapPatterns)-1))
+		}
 
-		fmt.Printf("GSCN=%v, N=%v, M=%v, SS_REF=%vMHz\n", gscn, N, M, ssRef)
-		return ssRef
-	} else if maxFreq < 24250 {
-		N := gscn - 7499
-		return 3000 + 1.44*float64(N)
-	} else {
-		N := gscn - 22256
-		return 24250.08 + 17.28*float64(N)
+		if flags.meas.gapOffset < 0 || flags.meas.gapOffset >= int(gap[1]) {
+			return errors.New(fmt.Sprintf("Invalid gapOffset(=%v) of MeasGapConfig, which can be 0..%v for gapPatternId %v(MGRP=%vms).", flags.meas.gapOffset, int(gap[1])-1, flags.meas.gapPatternId, gap[1]))
+		}
+
+		mgtaSet := []string{"ms0", "ms0dot5"}
+		if flags.gridsetting._freqRange != "FR1" {
+			mgtaSet = []string{"ms0", "ms0dot25"}
+		}
+		if !utils.ContainsStr(mgtaSet, flags.meas.gapMgta) {
+			return errors.New(fmt.Sprintf("Invalid mgta(=%v) of MeasGapConfig, which can be %v for %v.", flags.meas.gapMgta, mgtaSet, flags.gridsetting._freqRange))
+		}
 	}
-}
 
-// calculate N_CRB_SSB and k_SSB given GSCN and DL ARFCN
-func updateKSsbAndNCrbSsb() error {
-	regYellow.Printf("-->calling updateKSsbAndNCrbSsb\n")
+	n := len(flags.meas.nbrPci)
+	if len(flags.meas.nbrSsbPeriod) != n || len(flags.meas.nbrSsbOffset) != n {
+		return errors.New(fmt.Sprintf("The length of nbrPci(=%v), nbrSsbPeriod(=%v) and nbrSsbOffset(=%v) must be the same!", n, len(flags.meas.nbrSsbPeriod), len(flags.meas.nbrSsbOffset)))
+	}
+	for i := 0; i < n; i++ {
+		if flags.meas.nbrPci[i] < 0 || flags.meas.nbrPci[i] > 1007 || flags.meas.nbrPci[i] == flags.gridsetting.pci {
+			return errors.New(fmt.Sprintf("Invalid nbrPci(=%v), which must be within [0, 1007] and different from the PCI(=%v) of the serving cell!", flags.meas.nbrPci[i], flags.gridsetting.pci))
+		}
 
-	ssbScs, _ := strconv.ParseFloat(flags.gridsetting._ssbScs[:len(flags.gridsetting._ssbScs)-3], 64)
-	carrierScs, _ := strconv.ParseFloat(flags.gridsetting._carrierScs[:len(flags.gridsetting._carrierScs)-3], 64)
-	rmsiScs, _ := strconv.ParseFloat(flags.gridsetting._mibCommonScs[:len(flags.gridsetting._mibCommonScs)-3], 64)
+		if !utils.ContainsStr([]string{"ms5", "ms10", "ms20", "ms40", "ms80", "ms160"}, flags.meas.nbrSsbPeriod[i]) {
+			return errors.New(fmt.Sprintf("Invalid nbrSsbPeriod(=%v) of neighbour cell(pci=%v), which can be ms5/ms10/ms20/ms40/ms80/ms160.", flags.meas.nbrSsbPeriod[i], flags.meas.nbrPci[i]))
+		}
 
-	if flags.gridsetting._freqRange == "FR1" {
-		flags.gridsetting._kSsbScs = 15
-		flags.gridsetting._nCrbSsbScs = 15
-	} else if flags.gridsetting._freqRange == "FR2-1" {
-		flags.gridsetting._kSsbScs = rmsiScs
-		flags.gridsetting._nCrbSsbScs = 60
-	} else if flags.gridsetting._freqRange == "FR2-2" {
-		flags.gridsetting._kSsbScs = ssbScs
-		flags.gridsetting._nCrbSsbScs = 60
+		period, _ := strconv.Atoi(flags.meas.nbrSsbPeriod[i][2:])
+		if flags.meas.nbrSsbOffset[i] < 0 || flags.meas.nbrSsbOffset[i] >= period*symbPerSubf {
+			return errors.New(fmt.Sprintf("Invalid nbrSsbOffset(=%v) of neighbour cell(pci=%v), which can be 0..%v.", flags.meas.nbrSsbOffset[i], flags.meas.nbrPci[i], period*symbPerSubf-1))
+		}
 	}
 
-	ssFreq := gscn2Ssref(flags.gridsetting.gscn, flags.gridsetting._maxDlFreq)
-	ssFreqSc0Rb0 := ssFreq - 120*ssbScs/1000
+	return nil
+}
 
-	dlFreq := arfcn2Fref(flags.gridsetting.dlArfcn, flags.gridsetting._maxDlFreq)
-	var dlFreqPointA float64
-	if flags.gridsetting._carrierNumRbs%2 == 0 {
-		dlFreqPointA = dlFreq - 12*float64(flags.gridsetting._carrierNumRbs/2)*carrierScs/1000
-	} else {
-		dlFreqPointA = dlFreq - 12*(math.Floor(float64(flags.gridsetting._carrierNumRbs)/2)+6)*carrierScs/1000
+// validatePos validates DL-PRS-ResourceSet and SRS-PosResource for positioning.
+func validatePos() error {
+	regYellow.Printf("-->calling validatePos\n")
+
+	isBitmap := func(bits string) bool {
+		return len(strings.Trim(bits, "01")) == 0
 	}
 
-	nCrbSsb := math.Floor((ssFreqSc0Rb0 - dlFreqPointA) / (12 * flags.gridsetting._nCrbSsbScs / 1000))
-	kSsb := (ssFreqSc0Rb0 - dlFreqPointA - 12*flags.gridsetting._nCrbSsbScs/1000*nCrbSsb) / (flags.gridsetting._kSsbScs / 1000)
+	// refer to 3GPP 38.211 vh40
+	// 7.4.1.7	Positioning reference signals
+	if n := len(flags.pos.prsResId); n > 0 {
+		slotPerSubf := int(math.Exp2(float64(nrgrid.Scs2Mu[flags.gridsetting.scs])))
+		if !utils.ContainsInt([]int{4, 5, 8, 10, 16, 20, 32, 40, 64, 80, 160, 320, 640, 1280, 2560, 5120, 10240, 20480}, flags.pos.prsPeriod) {
+			return errors.New(fmt.Sprintf("Invalid dl-PRS-Periodicity(=%v) of DL-PRS-ResourceSet, which can be 4/5/8/10/16/20/32/40/64/80/160/320/640/1280/2560/5120/10240/20480.", flags.pos.prsPeriod))
+		}
 
-	fmt.Printf("%v: nCrbSsb SCS=%.0fKHz, kSsb SCS=%.0fKHz\n", flags.gridsetting._freqRange, flags.gridsetting._nCrbSsbScs, flags.gridsetting._kSsbScs)
-	fmt.Printf("ssFreq=%vMHz, ssFreqSc0Rb0=%vMHz, dlFreq=%vMHz, dlFreqPointA=%vMHz, nCrbSsb=%v, kSsb=%v\n",
-		ssFreq, ssFreqSc0Rb0, dlFreq, dlFreqPointA, nCrbSsb, kSsb)
+		period := flags.pos.prsPeriod * slotPerSubf
+		if flags.pos.prsSetSlotOffset < 0 || flags.pos.prsSetSlotOffset >= period {
+			return errors.New(fmt.Sprintf("Invalid dl-PRS-ResourceSetSlotOffset(=%v) of DL-PRS-ResourceSet, which can be 0..%v.", flags.pos.prsSetSlotOffset, period-1))
+		}
 
-	flags.gridsetting._nCrbSsb = int(nCrbSsb)
-	flags.gridsetting._kSsb = int(math.Ceil(kSsb))
+		if !utils.ContainsInt([]int{1, 2, 4, 6, 8, 16, 32}, flags.pos.prsRepFactor) {
+			return errors.New(fmt.Sprintf("Invalid dl-PRS-ResourceRepetitionFactor(=%v) of DL-PRS-ResourceSet, which can be 1/2/4/6/8/16/32.", flags.pos.prsRepFactor))
+		}
 
-	return nil
-}
+		if !utils.ContainsInt([]int{1, 2, 4, 8, 16, 32}, flags.pos.prsTimeGap) {
+			return errors.New(fmt.Sprintf("Invalid dl-PRS-ResourceTimeGap(=%v) of DL-PRS-ResourceSet, which can be 1/2/4/8/16/32.", flags.pos.prsTimeGap))
+		}
 
-func validateCoreset0() error {
-	regYellow.Printf("-->calling validateCoreset0\n")
+		// 7.4.1.7.3	Mapping to physical resources in a downlink PRS resource
+		// Note: L_PRS must be a multiple of K_PRS_comb, i.e. {K_PRS_comb, L_PRS} can be {2, 2}, {2, 4}, {2, 6}, {2, 12}, {4, 4}, {4, 12}, {6, 6}, {6, 12} or {12, 12}.
+		if !utils.ContainsInt([]int{2, 4, 6, 12}, flags.pos.prsCombSize) || !utils.ContainsInt([]int{2, 4, 6, 12}, flags.pos.prsNumSymbs) || flags.pos.prsNumSymbs%flags.pos.prsCombSize != 0 {
+			return errors.New(fmt.Sprintf("Invalid combination of dl-PRS-CombSizeN(=%v) and dl-PRS-NumSymbols(=%v), where dl-PRS-CombSizeN can be 2/4/6/12, dl-PRS-NumSymbols can be 2/4/6/12, and dl-PRS-NumSymbols must be a multiple of dl-PRS-CombSizeN.", flags.pos.prsCombSize, flags.pos.prsNumSymbs))
+		}
 
-	band := flags.gridsetting.band
-	fr := flags.gridsetting._freqRange
-	ssbScs := flags.gridsetting._ssbScs
-	rmsiScs := flags.gridsetting._mibCommonScs
+		if flags.pos.prsStartPrb < 0 || flags.pos.prsStartPrb > 2176 {
+			return errors.New(fmt.Sprintf("Invalid dl-PRS-StartPRB(=%v) of DL-PRS-ResourceSet, which can be 0..2176.", flags.pos.prsStartPrb))
+		}
 
-	var ssbScsSet []string
-	var rmsiScsSet []string
-	for _, v := range nrgrid.SsbRasters[band] {
-		ssbScsSet = append(ssbScsSet, v[0])
-	}
-	if fr == "FR1" {
-		rmsiScsSet = append(rmsiScsSet, []string{"15KHz", "30KHz"}...)
-	} else if fr == "FR2-1" {
-		rmsiScsSet = append(rmsiScsSet, []string{"60KHz", "120KHz"}...)
-	} else if fr == "FR2-2" {
-		rmsiScsSet = ssbScsSet
-	}
+		if flags.pos.prsNumPrbs < 24 || flags.pos.prsNumPrbs > 272 || flags.pos.prsNumPrbs%4 != 0 {
+			return errors.New(fmt.Sprintf("Invalid dl-PRS-ResourceBandwidth(=%v) of DL-PRS-ResourceSet, which can be 24..272 with a granularity of 4 PRBs.", flags.pos.prsNumPrbs))
+		}
 
-	if !(utils.ContainsStr(ssbScsSet, ssbScs) && utils.ContainsStr(rmsiScsSet, rmsiScs)) {
-		return errors.New(fmt.Sprintf("Invalid SSB SCS and/or RMSI SCS settings!\nSSB SCS range: %v and ssbScs=%v\nRMSI SCS range: %v and rmsiScs=%v\n", ssbScsSet, ssbScs, rmsiScsSet, rmsiScs))
-	}
+		if opt1 := flags.pos.prsMutingOpt1; len(opt1) > 0 {
+			if !isBitmap(opt1) || !utils.ContainsInt([]int{2, 4, 6, 8, 16, 32}, len(opt1)) {
+				return errors.New(fmt.Sprintf("Invalid dl-PRS-MutingOption1(=%v), which must be a bitmap of 2/4/6/8/16/32 bits.", opt1))
+			}
 
-	// calculate minChBw
-	key := fmt.Sprintf("%v_%v", band, rmsiScs[:len(rmsiScs)-3])
-	var bwSubset []string
-	if fr == "FR1" {
-		for i, v := range nrgrid.BandScs2BwFr1[key] {
-			if v == 1 {
-				bwSubset = append(bwSubset, nrgrid.BwSetFr1[i])
+			if !utils.ContainsInt([]int{1, 2, 4, 8}, flags.pos.prsMutingBitRepFactor) {
+				return errors.New(fmt.Sprintf("Invalid dl-PRS-MutingBitRepetitionFactor(=%v) of dl-PRS-MutingOption1, which can be 1/2/4/8.", flags.pos.prsMutingBitRepFactor))
 			}
 		}
-	} else if fr == "FR2-1" {
-		for i, v := range nrgrid.BandScs2BwFr21[key] {
-			if v == 1 {
-				bwSubset = append(bwSubset, nrgrid.BwSetFr21[i])
-			}
+
+		if opt2 := flags.pos.prsMutingOpt2; len(opt2) > 0 && (!isBitmap(opt2) || len(opt2) != flags.pos.prsRepFactor) {
+			return errors.New(fmt.Sprintf("Invalid dl-PRS-MutingOption2(=%v), which must be a bitmap of dl-PRS-ResourceRepetitionFactor(=%v) bits.", opt2, flags.pos.prsRepFactor))
 		}
-	} else if fr == "FR2-2" {
-		for i, v := range nrgrid.BandScs2BwFr22[key] {
-			if v == 1 {
-				bwSubset = append(bwSubset, nrgrid.BwSetFr22[i])
+
+		if len(flags.pos.prsResReOffset) != n || len(flags.pos.prsResSlotOffset) != n || len(flags.pos.prsResSymbOffset) != n {
+			return errors.New(fmt.Sprintf("The length of prsResId(=%v), prsResReOffset(=%v), prsResSlotOffset(=%v) and prsResSymbOffset(=%v) must be the same!", n, len(flags.pos.prsResReOffset), len(flags.pos.prsResSlotOffset), len(flags.pos.prsResSymbOffset)))
+		}
+
+		symbPerSlot := getSymbPerSlot(flags.bwp._bwpCp[DED_DL_BWP])
+		for j, id := range flags.pos.prsResId {
+			if id < 0 || id > 63 || utils.IndexInt(flags.pos.prsResId, id) != j {
+				return errors.New(fmt.Sprintf("Invalid prsResId(=%v), where each nr-DL-PRS-ResourceID must be unique within [0, 63]!", flags.pos.prsResId))
+			}
+
+			if flags.pos.prsResReOffset[j] < 0 || flags.pos.prsResReOffset[j] >= flags.pos.prsCombSize {
+				return errors.New(fmt.Sprintf("Invalid RE offset(=%v) of DL PRS resource %v, which can be 0..%v.", flags.pos.prsResReOffset[j], id, flags.pos.prsCombSize-1))
+			}
+
+			if flags.pos.prsResSlotOffset[j] < 0 || flags.pos.prsResSlotOffset[j] > 511 {
+				return errors.New(fmt.Sprintf("Invalid dl-PRS-ResourceSlotOffset(=%v) of DL PRS resource %v, which can be 0..511.", flags.pos.prsResSlotOffset[j], id))
+			}
+
+			if flags.pos.prsResSymbOffset[j] < 0 || flags.pos.prsResSymbOffset[j]+flags.pos.prsNumSymbs > symbPerSlot {
+				return errors.New(fmt.Sprintf("Invalid dl-PRS-ResourceSymbolOffset(=%v) of DL PRS resource %v, which must satisfy dl-PRS-ResourceSymbolOffset + dl-PRS-NumSymbols(=%v) <= %v.", flags.pos.prsResSymbOffset[j], id, flags.pos.prsNumSymbs, symbPerSlot))
+			}
+
+			// Note: all repetitions of each DL PRS resource within an instance of DL-PRS-ResourceSet must be within the periodicity.
+			if flags.pos.prsResSlotOffset[j]+(flags.pos.prsRepFactor-1)*flags.pos.prsTimeGap >= period {
+				return errors.New(fmt.Sprintf("The repetitions of DL PRS resource %v(dl-PRS-ResourceSlotOffset=%v, dl-PRS-ResourceRepetitionFactor=%v, dl-PRS-ResourceTimeGap=%v) exceed the periodicity(=%v slots) of DL-PRS-ResourceSet!", id, flags.pos.prsResSlotOffset[j], flags.pos.prsRepFactor, flags.pos.prsTimeGap, period))
 			}
 		}
 	}
 
-	if len(bwSubset) > 0 {
-		minChBw, _ = strconv.Atoi(bwSubset[0][:len(bwSubset[0])-3])
-		fmt.Printf("Available transmission bandwidth: %v\n", bwSubset)
-		fmt.Printf("Minimum transmission bandwidth is %v\n", bwSubset[0])
-	} else {
-		minChBw = -1
-		return errors.New(fmt.Sprintf("Invalid configurations for minChBw calculation: band=%v, freqRange=%v, rmsiScs=%v\n", band, fr, rmsiScs))
+	// refer to 3GPP 38.211 vh40
+	// 6.4.1.4	Sounding reference signal
+	// 6.4.1.4.3	Mapping to physical resources
+	// Note: the combination of K_TC and N_SRS_symb of SRS-PosResource must be given by Table 6.4.1.4.3-2.
+	n := len(flags.pos.srsPosResId)
+	if len(flags.pos.srsPosNumCombs) != n || len(flags.pos.srsPosCombOff) != n || len(flags.pos.srsPosStartPos) != n || len(flags.pos.srsPosNumSymbs) != n || len(flags.pos.srsPosFreqShift) != n || len(flags.pos.srsPosCSrs) != n || len(flags.pos.srsPosPeriod) != n || len(flags.pos.srsPosOffset) != n {
+		return errors.New(fmt.Sprintf("The length of srsPosResId, srsPosNumCombs, srsPosCombOff, srsPosStartPos, srsPosNumSymbs, srsPosFreqShift, srsPosCSrs, srsPosPeriod and srsPosOffset must be the same!"))
 	}
+	for j, id := range flags.pos.srsPosResId {
+		if id < 0 || id > 63 || utils.IndexInt(flags.pos.srsPosResId, id) != j {
+			return errors.New(fmt.Sprintf("Invalid srsPosResId(=%v), where each srs-PosResourceId must be unique within [0, 63]!", flags.pos.srsPosResId))
+		}
 
-	// validate coresetZero
-	key = fmt.Sprintf("%v_%v_%v", ssbScs[:len(ssbScs)-3], rmsiScs[:len(rmsiScs)-3], flags.gridsetting.rmsiCoreset0)
-	var p *nrgrid.Coreset0Info
-	var exist bool
-	if (band == "n79" || band == "n104") && ssbScs[:len(ssbScs)-3] == "30" && (rmsiScs[:len(rmsiScs)-3] == "15" || rmsiScs[:len(rmsiScs)-3] == "30") {
-		// 38.101-1 vh80 Table 5.2-1: NR operating bands in FR1
-		// NOTE 17: For this band, CORESET#0 values from Table 13-5 or Table 13-6 in [8, TS 38.213] are applied regardless of the minimum channel bandwidth.
-		p, exist = nrgrid.Coreset0Fr1MinChBw40m[key]
-	} else if fr == "FR1" && utils.ContainsInt([]int{5, 10}, minChBw) {
-		p, exist = nrgrid.Coreset0Fr1MinChBw5m10m[key]
-	} else if fr == "FR1" && minChBw == 40 {
-		p, exist = nrgrid.Coreset0Fr1MinChBw40m[key]
-	} else if fr == "FR2-1" {
-		p, exist = nrgrid.Coreset0Fr21[key]
-	} else if fr == "FR2-2" {
-		p, exist = nrgrid.Coreset0Fr22[key]
-	}
-	if !exist || p == nil {
-		return errors.New(fmt.Sprintf("Invalid configurations for CORESET0: fr=%v, ssbScs=%v, rmsiScs=%v, minChBw=%vMHz, coresetZero=%v", fr, ssbScs, rmsiScs, minChBw, flags.gridsetting.rmsiCoreset0))
-	}
-	fmt.Printf("CORESET0 Info: %v\n", *p)
-	flags.gridsetting._coreset0MultiplexingPat = p.MultiplexingPat
-	flags.gridsetting._coreset0NumRbs = p.NumRbs
-	flags.gridsetting._coreset0NumSymbs = p.NumSymbs
-	flags.gridsetting._coreset0OffsetList = p.OffsetLst
+		validSymbs := map[string][]string{"n2": {"n1", "n2", "n4", "n8", "n12"}, "n4": {"n2", "n4", "n8", "n12"}, "n8": {"n4", "n8", "n12"}}[flags.pos.srsPosNumCombs[j]]
+		if validSymbs == nil || !utils.ContainsStr(validSymbs, flags.pos.srsPosNumSymbs[j]) {
+			return errors.New(fmt.Sprintf("[SRS-PosResource srs-PosResourceId=%v] Invalid combination of transmissionComb(=%v) and nrofSymbols(=%v), where nrofSymbols can be n1/n2/n4/n8/n12 for n2, n2/n4/n8/n12 for n4, or n4/n8/n12 for n8.", id, flags.pos.srsPosNumCombs[j], flags.pos.srsPosNumSymbs[j]))
+		}
 
-	// validate CORESET0 bw against carrier bw
-	carrierBw := flags.gridsetting.bw
-	rmsiScsVal, _ := strconv.Atoi(rmsiScs[:len(rmsiScs)-3])
-	var numRbsRmsiScs int
-	var idx int
-	if fr == "FR1" {
-		idx = utils.IndexStr(nrgrid.BwSetFr1, carrierBw)
-	} else if fr == "FR2-1" {
-		idx = utils.IndexStr(nrgrid.BwSetFr21, carrierBw)
-	} else if fr == "FR2-2" {
-		idx = utils.IndexStr(nrgrid.BwSetFr22, carrierBw)
-	}
-	if idx < 0 {
-		return errors.New(fmt.Sprintf("Invalid carrier bandwidth for %v: carrierBw=%v\n", fr, carrierBw))
-	}
-	numRbsRmsiScs = nrgrid.NrbFr1[rmsiScsVal][idx]
+		KTC, _ := strconv.Atoi(flags.pos.srsPosNumCombs[j][1:])
+		if flags.pos.srsPosCombOff[j] < 0 || flags.pos.srsPosCombOff[j] >= KTC {
+			return errors.New(fmt.Sprintf("[SRS-PosResource srs-PosResourceId=%v] Invalid combOffset(=%v) for transmissionComb=%v!", id, flags.pos.srsPosCombOff[j], flags.pos.srsPosNumCombs[j]))
+		}
 
-	if numRbsRmsiScs < flags.gridsetting._coreset0NumRbs {
-		return errors.New(fmt.Sprintf("Invalid configurations for CORESET0: numRbsRmsiScs=%v, coreset0NumRbs=%v\n", numRbsRmsiScs, flags.gridsetting._coreset0NumRbs))
-	}
+		// l0 = N_symb_slot - 1 - l_offset, where the SRS-PosResource spans N_SRS_symb consecutive symbols starting from l0
+		numSymbs, _ := strconv.Atoi(flags.pos.srsPosNumSymbs[j][1:])
+		if flags.pos.srsPosStartPos[j] < numSymbs-1 || flags.pos.srsPosStartPos[j] > 13 {
+			return errors.New(fmt.Sprintf("[SRS-PosResource srs-PosResourceId=%v] Invalid startPosition(=%v), which can be %v..13 for nrofSymbols=%v.", id, flags.pos.srsPosStartPos[j], numSymbs-1, flags.pos.srsPosNumSymbs[j]))
+		}
 
-	// update coreset0Offset w.r.t k_SSB
-	kssb := flags.gridsetting._kSsb
-	if len(flags.gridsetting._coreset0OffsetList) == 2 {
-		if kssb == 0 {
-			flags.gridsetting._coreset0Offset = flags.gridsetting._coreset0OffsetList[0]
-		} else {
-			flags.gridsetting._coreset0Offset = flags.gridsetting._coreset0OffsetList[1]
+		if flags.pos.srsPosFreqShift[j] < 0 || flags.pos.srsPosFreqShift[j] > 268 {
+			return errors.New(fmt.Sprintf("[SRS-PosResource srs-PosResourceId=%v] Invalid freqDomainShift(=%v), which can be 0..268.", id, flags.pos.srsPosFreqShift[j]))
 		}
-	} else {
-		flags.gridsetting._coreset0Offset = flags.gridsetting._coreset0OffsetList[0]
-	}
 
-	fmt.Printf("CORESET0: multiplexingPattern=%v, numRbs=%v, numSymbs=%v, offset=%v\n", flags.gridsetting._coreset0MultiplexingPat, flags.gridsetting._coreset0NumRbs, flags.gridsetting._coreset0NumSymbs, flags.gridsetting._coreset0Offset)
+		if _, exist := nrgrid.SrsBwCfg[fmt.Sprint(flags.pos.srsPosCSrs[j])]; !exist {
+			return errors.New(fmt.Sprintf("[SRS-PosResource srs-PosResourceId=%v] Invalid key(=%v) when referring SrsBwCfg!", id, flags.pos.srsPosCSrs[j]))
+		}
 
-	// Basic assumptions: If offset >= 0, then 1st RB of CORESET0 aligns with the carrier edge; if offset < 0, then 1st RB of SSB aligns with the carrier edge.
-	// if offset >= 0, min bw = max(coreset0NumRbs, offset + 20 * scsSsb / scsRmsi), and n_CRB_SSB needs update w.r.t to offset
-	// if offset < 0, min bw = coreset0NumRbs - offset, and don't have to update n_CRB_SSB
-	ssbScsVal, _ := strconv.Atoi(ssbScs[:len(ssbScs)-3])
-	var minBw int
-	if flags.gridsetting._coreset0Offset >= 0 {
-		minBw = utils.MaxInt([]int{flags.gridsetting._coreset0NumRbs, flags.gridsetting._coreset0Offset + 20*ssbScsVal/rmsiScsVal})
-	} else {
-		minBw = flags.gridsetting._coreset0NumRbs - flags.gridsetting._coreset0Offset
-	}
-	if numRbsRmsiScs < minBw {
-		return errors.New(fmt.Sprintf("Invalid configurations for CORESET0: numRbsRmsiScs=%v, minBw=%v(coreset0NumRbs=%v,offset=%v)\n", numRbsRmsiScs, minBw, flags.gridsetting._coreset0NumRbs, flags.gridsetting._coreset0Offset))
+		period, err := strconv.Atoi(strings.TrimPrefix(flags.pos.srsPosPeriod[j], "sl"))
+		if err != nil || !strings.HasPrefix(flags.pos.srsPosPeriod[j], "sl") || !utils.ContainsInt([]int{1, 2, 4, 5, 8, 10, 16, 20, 32, 40, 64, 80, 160, 320, 640, 1280, 2560, 5120, 10240, 20480, 40960, 81920}, period) {
+			return errors.New(fmt.Sprintf("[SRS-PosResource srs-PosResourceId=%v] Invalid periodicity(=%v) of periodicityAndOffset-sp, which can be sl1/sl2/sl4/sl5/sl8/sl10/sl16/sl20/sl32/sl40/sl64/sl80/sl160/sl320/sl640/sl1280/sl2560/sl5120/sl10240/sl20480/sl40960/sl81920.", id, flags.pos.srsPosPeriod[j]))
+		}
+
+		if flags.pos.srsPosOffset[j] < 0 || flags.pos.srsPosOffset[j] >= period {
+			return errors.New(fmt.Sprintf("[SRS-PosResource srs-PosResourceId=%v] Invalid offset(=%v) for periodicity(=%v) of periodicityAndOffset-sp!", id, flags.pos.srsPosOffset[j], flags.pos.srsPosPeriod[j]))
+		}
 	}
 
-	// validate coreste0NumSymbs against dmrs-pointA-Position
-	// refer to 3GPP TS 38.211 vf80: 7.3.2.2	Control-resource set (CORESET)
-	// N_CORESET_symb = 3 is supported only if the higher-layer parameter dmrs-TypeA-Position equals 3;
-	if flags.gridsetting._coreset0NumSymbs == 3 && flags.gridsetting.dmrsTypeAPos != "pos3" {
-		return errors.New(fmt.Sprintf("coreset0NumSymbs can be 3 only if dmrs-TypeA-Position is pos3! (corest0NumSymbs=%v,dmrsTypeAPos=%v)\n", flags.gridsetting._coreset0NumSymbs, flags.gridsetting.dmrsTypeAPos))
+	return nil
+}
+
+// validateCa validates SCells and CrossCarrierSchedulingConfig of carrier aggregation, and updates the duplex mode and carrierBandwidth of each SCell.
+func validateCa() error {
+	regYellow.Printf("-->calling validateCa\n")
+
+	flags.ca._scellDuplexMode = nil
+	flags.ca._scellCarrierNumRbs = nil
+	n := len(flags.ca.scellIndex)
+	if n == 0 {
+		return nil
 	}
 
-	// update info of initial dl bwp
-	if flags.gridsetting._coreset0Offset >= 0 {
-		upper := utils.MinInt([]int{numRbsRmsiScs - flags.gridsetting._coreset0NumRbs, numRbsRmsiScs - (flags.gridsetting._coreset0NumRbs + 20*ssbScsVal/rmsiScsVal)})
-		fmt.Printf("Available RB_Start for Initial DL BWP: [%v..%v]\n", 0, upper)
-	} else {
-		upper := utils.MinInt([]int{numRbsRmsiScs - flags.gridsetting._coreset0NumRbs, numRbsRmsiScs - (flags.gridsetting._coreset0NumRbs + 20*ssbScsVal/rmsiScsVal)})
-		fmt.Printf("Available RB_Start for Initial DL BWP: [%v..%v]\n", -flags.gridsetting._coreset0Offset, upper)
+	for _, v := range []int{len(flags.ca.scellBand), len(flags.ca.scellScs), len(flags.ca.scellBw), len(flags.ca.scellCif)} {
+		if v != n {
+			return errors.New(fmt.Sprintf("The scellBand(=%v), scellScs(=%v), scellBw(=%v) and scellCif(=%v) must have the same length as scellIndex(=%v)!", flags.ca.scellBand, flags.ca.scellScs, flags.ca.scellBw, flags.ca.scellCif, flags.ca.scellIndex))
+		}
 	}
-	fmt.Printf("Available L_RBs for Initial DL BWP: [%v]\n", flags.gridsetting._coreset0NumRbs)
 
-	return nil
-}
+	// Note: all serving cells are mapped onto resource grids with the same slot length.
+	if getSymbPerSlot(flags.bwp._bwpCp[DED_DL_BWP]) != 14 {
+		return errors.New("Carrier aggregation is only supported for normal cyclic prefix!")
+	}
 
-func validateCss0() error {
-	regYellow.Printf("-->calling validateCss0\n")
+	for i, idx := range flags.ca.scellIndex {
+		// refer to 3GPP 38.331 vh30
+		// SCellIndex ::= INTEGER (1..31)
+		if idx < 1 || idx > 31 || utils.IndexInt(flags.ca.scellIndex, idx) != i {
+			return errors.New(fmt.Sprintf("Invalid scellIndex(=%v), which must be unique values within [1, 31]!", flags.ca.scellIndex))
+		}
 
-	fr := flags.gridsetting._freqRange
-	pat := flags.gridsetting._coreset0MultiplexingPat
-	css0 := flags.gridsetting.rmsiCss0
+		band := flags.ca.scellBand[i]
+		p, exist := nrgrid.OpBands[band]
+		if !exist || (p.DuplexMode != "TDD" && p.DuplexMode != "FDD") {
+			return errors.New(fmt.Sprintf("Invalid scellBand(=%v) of SCell%v, which must be a TDD or FDD band!", band, idx))
+		}
 
-	switch pat {
-	case 1:
-		if fr == "FR1" || ((fr == "FR2-1" || fr == "FR2-2") && css0 >= 0 && css0 <= 13) {
-			return nil
+		v, _ := strconv.Atoi(band[1:])
+		var fr string
+		if v >= 1 && v <= 256 {
+			fr = "FR1"
+		} else if v >= 257 && v <= 262 {
+			fr = "FR2-1"
 		} else {
-			return errors.New(fmt.Sprintf("Invalid CSS0 setting!\nsearchSpaceZero range is [0, 13] for SSB/CORESET0 multiplexing pattern 1 and FR2\n"))
+			fr = "FR2-2"
 		}
-	case 2, 3:
-		if css0 != 0 {
-			return errors.New(fmt.Sprintf("Invalid CSS0 setting!\nsearchSpaceZero range is [0] for SSB/CORESET0 multiplexing pattern %v\n", css0))
+
+		scs := flags.ca.scellScs[i]
+		if _, exist := nrgrid.Scs2Mu[scs]; !exist {
+			return errors.New(fmt.Sprintf("Invalid scellScs(=%v) of SCell%v!", scs, idx))
+		}
+		scsVal, _ := strconv.Atoi(scs[:len(scs)-3])
+		nrb := getNrb(fr, flags.ca.scellBw[i], scsVal)
+		if nrb == 0 {
+			return errors.New(fmt.Sprintf("Invalid bandwidth of SCell%v: scellBand=%v(%v), scellScs=%v, scellBw=%v", idx, band, fr, scs, flags.ca.scellBw[i]))
 		}
-	}
 
-	return nil
-}
+		if p.DuplexMode == "TDD" {
+			for _, v := range []int{len(flags.ca.scellPatPeriod), len(flags.ca.scellPatNumDlSlots), len(flags.ca.scellPatNumDlSymbs), len(flags.ca.scellPatNumUlSymbs), len(flags.ca.scellPatNumUlSlots)} {
+				if v != n {
+					return errors.New(fmt.Sprintf("The scellPatPeriod(=%v), scellPatNumDlSlots(=%v), scellPatNumDlSymbs(=%v), scellPatNumUlSymbs(=%v) and scellPatNumUlSlots(=%v) must have the same length as scellIndex(=%v) when TDD SCell is configured!", flags.ca.scellPatPeriod, flags.ca.scellPatNumDlSlots, flags.ca.scellPatNumDlSymbs, flags.ca.scellPatNumUlSymbs, flags.ca.scellPatNumUlSlots, flags.ca.scellIndex))
+				}
+			}
 
-func validateSearchSpace() error {
-	regYellow.Printf("-->calling validateSearchSpace\n")
+			// Note: only one TDD-UL-DL-Pattern is supported for SCell, whose periodicity must divide 10ms.
+			period := flags.ca.scellPatPeriod[i]
+			if !utils.ContainsStr([]string{"0.5ms", "0.625ms", "1ms", "1.25ms", "2ms", "2.5ms", "5ms", "10ms"}, period) {
+				return errors.New(fmt.Sprintf("Invalid scellPatPeriod(=%v) of SCell%v, which can be 0.5ms/0.625ms/1ms/1.25ms/2ms/2.5ms/5ms/10ms.", period, idx))
+			}
+			pv, _ := strconv.ParseFloat(period[:len(period)-2], 64)
+			slotPerSubf := math.Exp2(float64(nrgrid.Scs2Mu[scs]))
+			if math.Mod(pv*slotPerSubf, 1) != 0 {
+				return errors.New(fmt.Sprintf("The scellPatPeriod(=%v) of SCell%v is not supported for scellScs(=%v)!", period, idx, scs))
+			}
 
-	// validate CORESET1
-	crbStart := flags.searchspace.coreset1StartCrb
-	numRbs := flags.searchspace.coreset1NumRbs
-	cceRegMapping := flags.searchspace.coreset1CceRegMappingType
-	duration := flags.searchspace._coreset1Duration
-	L, _ := strconv.Atoi(flags.searchspace.coreset1RegBundleSize[1:])
+			numDlSymbs, numUlSymbs := flags.ca.scellPatNumDlSymbs[i], flags.ca.scellPatNumUlSymbs[i]
+			if numDlSymbs < 0 || numUlSymbs < 0 || numDlSymbs+numUlSymbs > 14 {
+				return errors.New(fmt.Sprintf("Invalid TDD-UL-DL-Pattern of SCell%v: scellPatNumDlSymbs(=%v) + scellPatNumUlSymbs(=%v) must be within [0, 14]!", idx, numDlSymbs, numUlSymbs))
+			}
 
-	if crbStart%6 == 0 && numRbs%6 == 0 {
-		fdres := []byte(fmt.Sprintf("%045b", 0))
-		// refer to 38.213 vh40
-		// 10.1	UE procedure for determining physical downlink control channel assignment
-		// ...if a CORESET is not associated with any search space set configured with freqMonitorLocations, the bits of the bitmap have a one-to-one mapping with non-overlapping groups of 6 consecutive PRBs, in ascending order of the PRB index in the DL BWP bandwidth of N_BWP_RB PRBs with starting common RB position N_start_BWP, where the first common RB of the first group of 6 PRBs has common RB index 6*ceil(N_start_BWP/6) if rb-Offset is not provided...
-		rb0grp0 := utils.CeilInt(6 * float64(flags.bwp._bwpStartRb[DED_DL_BWP]) / 6)
-		bit0 := (crbStart - rb0grp0) / 6
-		nbits := numRbs / 6
-		for i := 0; i < nbits; i++ {
-			fdres[bit0+i] = '1'
+			// Note: The flexible symbols are all within one slot, so the pattern must occupy all slots of the periodicity.
+			numSlots := int(pv * slotPerSubf)
+			numDlSlots, numUlSlots := flags.ca.scellPatNumDlSlots[i], flags.ca.scellPatNumUlSlots[i]
+			if numDlSlots < 0 || numUlSlots < 0 || numDlSlots+numUlSlots+1 != numSlots {
+				return errors.New(fmt.Sprintf("Invalid TDD-UL-DL-Pattern of SCell%v: scellPatNumDlSlots(=%v) + scellPatNumUlSlots(=%v) + 1 must be equal to the number of slots(=%v) of scellPatPeriod(=%v).", idx, numDlSlots, numUlSlots, numSlots, period))
+			}
 		}
-		flags.searchspace._coreset1FdRes = string(fdres)
-	} else {
-		return errors.New(fmt.Sprintf("Both coreset1StartCrb and coreset1NumRbs must be multiples of 6!"))
-	}
 
-	if cceRegMapping == "nonInterleaved" {
-		flags.searchspace.coreset1RegBundleSize = "n6"
-	} else {
-		if (duration == 1 && !utils.ContainsInt([]int{2, 6}, L)) || (utils.ContainsInt([]int{2, 3}, duration) && !utils.ContainsInt([]int{duration, 6}, L)) {
-			return errors.New(fmt.Sprintf("For interleaved CCE-to-REG mapping, L = 2/6 for coreset1Duration=1 and L = duration/6 for coreset1Duration=2/3.(cceRegMapping=%v, duration=%v, L=%v)\n", cceRegMapping, duration, L))
+		// refer to 3GPP 38.331 vh30
+		// CrossCarrierSchedulingConfig field descriptions
+		// cif-InSchedulingCell: The field indicates the CIF value used in the scheduling cell to indicate a grant or assignment applicable for this cell, see TS 38.213 [13].
+		// Note: the scheduling cell is always the PCell if cross-carrier scheduling is configured, and the carrier indicator field of the PCell is 0.
+		cif := flags.ca.scellCif[i]
+		if cif < 0 || cif > 7 || (cif > 0 && utils.IndexInt(flags.ca.scellCif, cif) != i) {
+			return errors.New(fmt.Sprintf("Invalid scellCif(=%v), which must be 0(self-scheduled) or unique values within [1, 7](cross-carrier scheduled by PCell)!", flags.ca.scellCif))
 		}
+
+		// Note: the CORESET of self-scheduled SCell has the same configuration as CORESET1 of the PCell, which must be within the SCell carrier.
+		if cif == 0 && flags.searchspace.coreset1StartCrb+flags.searchspace.coreset1NumRbs > nrb {
+			return errors.New(fmt.Sprintf("The CORESET of self-scheduled SCell%v(coreset1StartCrb=%v, coreset1NumRbs=%v) is out of the SCell carrier bandwidth(%v RBs)!", idx, flags.searchspace.coreset1StartCrb, flags.searchspace.coreset1NumRbs, nrb))
+		}
+
+		flags.ca._scellDuplexMode = append(flags.ca._scellDuplexMode, p.DuplexMode)
+		flags.ca._scellCarrierNumRbs = append(flags.ca._scellCarrierNumRbs, nrb)
+		fmt.Printf("SCell%v: band=%v(%v, %v), scs=%v, bw=%v, carrierBandwidth=%v, cif=%v\n", idx, band, p.DuplexMode, fr, scs, flags.ca.scellBw[i], nrb, cif)
 	}
 
-	// TODO: validate type3 CSS/USS MonitoringSymbolWithinSlot
-	// refer to 38.213 vh40
-	// 10.1	UE procedure for determining physical downlink control channel assignment
-	// If the monitoringSymbolsWithinSlot indicates to a UE to monitor PDCCH in a subset of up to three consecutive symbols that are same in every slot where the UE monitors PDCCH for all search space sets, the UE does not expect to be configured with a PDCCH SCS other than 15 kHz if the subset includes at least one symbol after the third symbol.
-	// A UE does not expect to be provided a first symbol and a number of consecutive symbols for a CORESET that results to a PDCCH candidate mapping to symbols of different slots.
-	// A UE does not expect any two PDCCH monitoring occasions on an active DL BWP, for a same search space set or for different search space sets, in a same CORESET to be separated by a non-zero number of symbols that is smaller than the CORESET duration.
+	// Note: PUSCH repetition is not supported for SCells, so the PUSCH of PUSCH repetition Type B must be within a slot.
+	if S, L := flags.uldci._tdStartSymb[DCI_01_PUSCH], flags.uldci._tdNumSymbs[DCI_01_PUSCH]; S+L > 14 {
+		return errors.New(fmt.Sprintf("The time domain resource allocation(S=%v, L=%v) of %v exceeds the slot, which is not supported for SCells!", S, L, flags.uldci._tag[DCI_01_PUSCH]))
+	}
 
 	return nil
 }
 
-func validatePucch() error {
-	regYellow.Printf("-->calling validatePucch\n")
+// getCgPeriodicity returns the periodicity of configured grant in number of symbols.
+func getCgPeriodicity() (int, error) {
+	// refer to 3GPP 38.331 vh30
+	// ConfiguredGrantConfig field descriptions
+	// periodicity: Periodicity for UL transmission without UL grant for type 1 and type 2. The following periodicities are supported depending on the configured subcarrier spacing [symbols]:
+	// 15 kHz: 2, 7, n*14, where n={1, 2, 4, 5, 8, 10, 16, 20, 32, 40, 64, 80, 128, 160, 320, 640}
+	// 30 kHz: 2, 7, n*14, where n={1, 2, 4, 5, 8, 10, 16, 20, 32, 40, 64, 80, 128, 160, 256, 320, 640, 1280}
+	// 60 kHz with normal CP: 2, 7, n*14, where n={1, 2, 4, 5, 8, 10, 16, 20, 32, 40, 64, 80, 128, 160, 256, 320, 512, 640, 1280, 2560}
+	// 60 kHz with ECP: 2, 6, n*12, where n={1, 2, 4, 5, 8, 10, 16, 20, 32, 40, 64, 80, 128, 160, 256, 320, 512, 640, 1280, 2560}
+	// 120 kHz: 2, 7, n*14, where n={1, 2, 4, 5, 8, 10, 16, 20, 32, 40, 64, 80, 128, 160, 256, 320, 512, 640, 1024, 1280, 2560, 5120}
+	ns, exist := map[string][]int{
+		"15KHz":  {1, 2, 4, 5, 8, 10, 16, 20, 32, 40, 64, 80, 128, 160, 320, 640},
+		"30KHz":  {1, 2, 4, 5, 8, 10, 16, 20, 32, 40, 64, 80, 128, 160, 256, 320, 640, 1280},
+		"60KHz":  {1, 2, 4, 5, 8, 10, 16, 20, 32, 40, 64, 80, 128, 160, 256, 320, 512, 640, 1280, 2560},
+		"120KHz": {1, 2, 4, 5, 8, 10, 16, 20, 32, 40, 64, 80, 128, 160, 256, 320, 512, 640, 1024, 1280, 2560, 5120},
+	}[getUlScs()]
+	if !exist {
+		return -1, errors.New(fmt.Sprintf("Configured grant is not supported for subcarrier spacing of %v.", getUlScs()))
+	}
 
-	if flags.pucch._interSlotFreqHop == "enabled" {
-		for _, v := range flags.pucch._pucchIntraSlotFreqHop {
-			if v == "enabled" {
-				return errors.New(fmt.Sprintf("For long PUCCH over multiple slots, the intra and inter slot frequency hopping cannot be enabled at the same time for a UE."))
-			}
+	symbPerSlot := getSymbPerSlot(flags.bwp._bwpCp[DED_UL_BWP])
+	// periodicity of less than one slot, which is 2 or 7 symbols for normal CP, or 2 or 6 symbols for extended CP
+	subSlots := []int{2, symbPerSlot / 2}
+	periodicity := flags.cgsps.cgPeriodicity
+	if strings.HasPrefix(periodicity, "sym") && strings.HasSuffix(periodicity, fmt.Sprintf("x%v", symbPerSlot)) {
+		n, err := strconv.Atoi(periodicity[3 : len(periodicity)-len(fmt.Sprintf("x%v", symbPerSlot))])
+		if err == nil && utils.ContainsInt(ns, n) {
+			return n * symbPerSlot, nil
+		}
+	} else if strings.HasPrefix(periodicity, "sym") {
+		n, err := strconv.Atoi(periodicity[3:])
+		if err == nil && utils.ContainsInt(subSlots, n) {
+			return n, nil
 		}
 	}
 
-	flags.csi._csiRepPucchRes = 1
+	return -1, errors.New(fmt.Sprintf("Invalid periodicity(=%v) of ConfiguredGrantConfig, which must be sym%v, sym%v or symNx%v with N=%v.", periodicity, subSlots[0], subSlots[1], symbPerSlot, ns))
+}
+
+// getPagingFrame returns the first PF no earlier than radio frame sfn and the index i_s of the PO of the UE.
+//  T: DRX cycle of the UE
+//  N: number of total paging frames in T
+//  Ns: number of paging occasions for a PF
+//  pfOffset: offset used for PF determination
+//  ueId: 5G-S-TMSI mod 1024
+func getPagingFrame(sfn, T, N, Ns, pfOffset, ueId int) (int, int) {
+	// refer to 3GPP 38.304 vh40
+	// 7.1	Discontinuous Reception for paging
+	// PF and PO for paging are determined by the following formulae:
+	// SFN for the PF is determined by: (SFN + PF_offset) mod T = (T div N)*(UE_ID mod N)
+	// Index (i_s), indicating the index of the PO is determined by: i_s = floor (UE_ID/N) mod Ns
+	pfRem := (T / N) * (ueId % N)
+	iS := (ueId / N) % Ns
+
+	pf := sfn
+	for (pf%1024+pfOffset)%T != pfRem {
+		pf++
+	}
+
+	return pf, iS
+}
+
+// getPagingParams returns the DRX cycle T, number of total paging frames N in T and number of paging occasions Ns for a PF.
+func getPagingParams() (int, int, int) {
+	T, _ := strconv.Atoi(flags.paging.defaultPagingCycle[2:])
+	n, exist := map[string]int{"oneT": 1, "halfT": 2, "quarterT": 4, "oneEighthT": 8, "oneSixteenthT": 16}[flags.paging.nAndPagingFrameOffset]
+	if !exist {
+		return T, -1, -1
+	}
+	Ns, exist := map[string]int{"four": 4, "two": 2, "one": 1}[flags.paging.ns]
+	if !exist {
+		return T, T / n, -1
+	}
 
-	return nil
+	return T, T / n, Ns
 }
 
-func validateCsi() error {
-	regYellow.Printf("-->calling validateCsi\n")
+// updateDedFdra clamps frequency domain resource allocation of PDSCH scheduled by DCI 1_1 and PUSCH scheduled by DCI 0_1 to the dedicated DL/UL BWP, and updates the 'Frequency domain resource assignment' field accordingly.
+func updateDedFdra() {
+	// DCI 1_1
+	_, bwpSize := getPdschBwp()
+	if flags.dldci._fdRaType[DCI_11_PDSCH] == "raType0" {
+		if len(flags.dldci._fdRa[DCI_11_PDSCH]) != flags.dldci._fdBitsRaType0 {
+			flags.dldci._fdRa[DCI_11_PDSCH] = strings.Repeat("1", flags.dldci._fdBitsRaType0)
+			regYellow.Printf("PDSCH(tag=%v): FDRA bits is set to %v for the dedicated DL BWP(size=%v).\n", flags.dldci._tag[DCI_11_PDSCH], flags.dldci._fdRa[DCI_11_PDSCH], bwpSize)
+		}
+	} else {
+		start, numRbs := clampRbs(flags.dldci.fdStartRb[DCI_11_PDSCH], flags.dldci.fdNumRbs[DCI_11_PDSCH], bwpSize)
+		if start != flags.dldci.fdStartRb[DCI_11_PDSCH] || numRbs != flags.dldci.fdNumRbs[DCI_11_PDSCH] {
+			regYellow.Printf("PDSCH(tag=%v): fdStartRb(=%v) and fdNumRbs(=%v) exceed the dedicated DL BWP(size=%v), and are set to %v and %v.\n", flags.dldci._tag[DCI_11_PDSCH], flags.dldci.fdStartRb[DCI_11_PDSCH], flags.dldci.fdNumRbs[DCI_11_PDSCH], bwpSize, start, numRbs)
+			flags.dldci.fdStartRb[DCI_11_PDSCH], flags.dldci.fdNumRbs[DCI_11_PDSCH] = start, numRbs
+		}
+		riv, _ := makeRiv(numRbs, start, bwpSize)
+		flags.dldci._fdRa[DCI_11_PDSCH] = fmt.Sprintf("%0*b", flags.dldci._fdBitsRaType1[DCI_11_PDSCH], riv)
+	}
 
-	if len(flags.csi._resId) != 2 {
-		return errors.New(fmt.Sprintf("Only two NZP-CSI-RS resources can be configured, one for CSI report, and the other for TRS."))
+	// DCI 0_1
+	bwpStart, bwpSize := getUlBwp(DED_UL_BWP)
+	switch flags.uldci._fdRaType[DCI_01_PUSCH] {
+	case "raType0":
+		if len(flags.uldci._fdRa[DCI_01_PUSCH]) != flags.uldci._fdBitsRaType0 {
+			flags.uldci._fdRa[DCI_01_PUSCH] = strings.Repeat("1", flags.uldci._fdBitsRaType0)
+			regYellow.Printf("PUSCH(tag=%v): FDRA bits is set to %v for the dedicated UL BWP(size=%v).\n", flags.uldci._tag[DCI_01_PUSCH], flags.uldci._fdRa[DCI_01_PUSCH], bwpSize)
+		}
+	case "raType2":
+		// Note: FDRA field of resource allocation type 2 is updated by validateDci01PuschAntPorts.
+		numRbSets := len(getRbSets(bwpStart, bwpSize))
+		start, num := clampRbs(flags.uldci.fdStartRbSet, flags.uldci.fdNumRbSets, numRbSets)
+		if start != flags.uldci.fdStartRbSet || num != flags.uldci.fdNumRbSets {
+			regYellow.Printf("PUSCH(tag=%v): fdStartRbSet(=%v) and fdNumRbSets(=%v) exceed the RB sets(=%v) of dedicated UL BWP, and are set to %v and %v.\n", flags.uldci._tag[DCI_01_PUSCH], flags.uldci.fdStartRbSet, flags.uldci.fdNumRbSets, numRbSets, start, num)
+			flags.uldci.fdStartRbSet, flags.uldci.fdNumRbSets = start, num
+		}
+	default:
+		start, numRbs := clampRbs(flags.uldci.fdStartRb[DCI_01_PUSCH], flags.uldci.fdNumRbs[DCI_01_PUSCH], bwpSize)
+		// refer to 3GPP 38.214 vh40
+		// 6.1.2.2	Resource allocation in frequency domain
+		// When transform precoding is enabled, ... M_RB_PUSCH = 2^a2*3^a3*5^a5
+		if lrbsPuschTp := initLrbsPuschTp(bwpSize); flags.pusch.puschTp == "enabled" && !utils.ContainsInt(lrbsPuschTp, numRbs) {
+			numRbs, _ = utils.NearestInt(lrbsPuschTp, numRbs)
+		}
+		if start != flags.uldci.fdStartRb[DCI_01_PUSCH] || numRbs != flags.uldci.fdNumRbs[DCI_01_PUSCH] {
+			regYellow.Printf("PUSCH(tag=%v): fdStartRb(=%v) and fdNumRbs(=%v) exceed the dedicated UL BWP(size=%v), and are set to %v and %v.\n", flags.uldci._tag[DCI_01_PUSCH], flags.uldci.fdStartRb[DCI_01_PUSCH], flags.uldci.fdNumRbs[DCI_01_PUSCH], bwpSize, start, numRbs)
+			flags.uldci.fdStartRb[DCI_01_PUSCH], flags.uldci.fdNumRbs[DCI_01_PUSCH] = start, numRbs
+		}
+		riv, _ := makeRiv(numRbs, start, bwpSize)
+		flags.uldci._fdRa[DCI_01_PUSCH] = fmt.Sprintf("%0*b", flags.uldci._fdBitsRaType1[DCI_01_PUSCH], riv)
 	}
+}
 
-	// update nzpCsiRsInfo
-	for i := range flags.csi._resId {
-		row := flags.csi.freqAllocRow[i]
-		irow, _ := strconv.Atoi(row[3:])
-		bits := flags.csi.freqAllocBits[i]
-		if !((row == "row1" && len(bits) == 4) || (row == "row2" && len(bits) == 12) || (row == "row4" && len(bits) == 3) || (!utils.ContainsStr([]string{"row1", "row2", "row4"}, row) && len(bits) == 6)) {
-			return errors.New(fmt.Sprintf("[CSI-RS resourceId=%v] Invalid length of freqAllocBits(=%v) for freqAllocRow=%v!", flags.csi._resId[i], bits, row))
+// updateDedPucchPrbs clamps startingPRB and secondHopPRB of the dedicated PUCCH resources to the dedicated UL BWP, and secondHopPRB beyond the BWP is re-derived as the mirror of startingPRB at the other edge of the BWP.
+func updateDedPucchPrbs() {
+	_, bwpSize := getUlBwp(DED_UL_BWP)
+	for i := range flags.pucch._pucchResId {
+		numRbs := flags.pucch._pucchNumRbs[i]
+		start := flags.pucch._pucchStartRb[i]
+		if start < 0 || start+numRbs > bwpSize {
+			start = utils.MaxInt([]int{0, bwpSize - numRbs})
 		}
-
-		ports, _ := strconv.Atoi(flags.csi._numPorts[i][1:])
-		density := map[string]string{"evenPRBs": "0.5", "oddPRBs": "0.5", "one": "1", "three": "3"}[flags.csi._density[i]]
-		key := fmt.Sprintf("%v_%v_%v", ports, density, flags.csi._cdmType[i])
-		p, exist := nrgrid.CsiRsLoc[key]
-		if !exist {
-			return errors.New(fmt.Sprintf("Invalid key(=%v) when referring CsiRsLoc!", key))
-		} else {
-			for _, v := range p {
-				if v.Row == irow {
-					fmt.Printf("NZP-CSI-RS Info(resourceId=%v): %v\n", flags.csi._resId[i], v)
-					flags.csi._tdLoc[i] = v
-				}
-			}
+		secondHop := flags.pucch._pucchSecondHopPrb[i]
+		if secondHop < 0 || secondHop+numRbs > bwpSize {
+			secondHop = utils.MaxInt([]int{0, bwpSize - numRbs - start})
 		}
-	}
-
-	// validate TRS
-	for i, v := range flags.csi._trsInfo {
-		if v == "true" {
-			if flags.gridsetting._freqRange == "FR1" {
-				if !utils.ContainsInt([]int{4, 5, 6}, flags.csi._firstSymb[i]) {
-					return errors.New(fmt.Sprintf("Only time-domain locations (4,8)/(5,9)/(6,10) are supported for TRS with FR1!"))
-				}
-			} else {
-				if !utils.ContainsInt(utils.PyRange(0, 10, 1), flags.csi._firstSymb[i]) {
-					return errors.New(fmt.Sprintf("Only time-domain locations (0,4)~(9,13) are supported for TRS with FR2!"))
-				}
-			}
+		if start != flags.pucch._pucchStartRb[i] || secondHop != flags.pucch._pucchSecondHopPrb[i] {
+			regYellow.Printf("PUCCH(pucchResId=%v): startingPRB(=%v) and secondHopPRB(=%v) are set to %v and %v for the dedicated UL BWP(size=%v).\n", flags.pucch._pucchResId[i], flags.pucch._pucchStartRb[i], flags.pucch._pucchSecondHopPrb[i], start, secondHop, bwpSize)
+			flags.pucch._pucchStartRb[i], flags.pucch._pucchSecondHopPrb[i] = start, secondHop
 		}
 	}
+}
 
-	// update CSI-IM resource
-	flags.csi._csiImPeriod = flags.csi.period[0]
-	flags.csi._csiImOffset = flags.csi.offset[0]
-	flags.csi._csiImStartRb = flags.csi._startRb[0]
-	flags.csi._csiImNumRbs = flags.csi._numRbs[0]
-	if flags.csi._numPorts[0] == "p4" {
-		if flags.csi.freqAllocRow[0] == "row4" {
-			flags.csi._csiImRePattern = "pattern1"
-			flags.csi._csiImScLoc = fmt.Sprintf("s%v", (flags.csi._tdLoc[0].Ki[0]+4)%12)
-			flags.csi._csiImSymbLoc = flags.csi._tdLoc[0].Li[0]
-		} else {
-			flags.csi._csiImRePattern = "pattern0"
-			flags.csi._csiImScLoc = fmt.Sprintf("s%v", (flags.csi._tdLoc[0].Ki[0]+2)%12)
-			flags.csi._csiImSymbLoc = flags.csi._tdLoc[0].Li[0]
-		}
+// clampRbs returns RB_start and L_RBs which are clamped to be within the BWP.
+//  start: RB_start
+//  numRbs: L_RBs
+//  bwpSize: size of the BWP
+func clampRbs(start, numRbs, bwpSize int) (int, int) {
+	if start < 0 || start >= bwpSize {
+		start = 0
+	}
+	if numRbs < 1 || numRbs > bwpSize-start {
+		numRbs = bwpSize - start
 	}
 
-	return nil
+	return start, numRbs
 }
 
 // calculate RIV (refer to 38.214 vh40)
@@ -2819,7 +12559,7 @@ func validatePdsch() error {
 	// refer to 3GPP TS 38.214 vh40: Table 5.1.2.1.1-4: Default PDSCH time domain resource allocation B
 	// refer to 3GPP TS 38.214 vh40: Table 5.1.2.1.1-5: Default PDSCH time domain resource allocation C
 	for i, _ := range flags.dldci._rnti {
-		if i == DCI_10_SIB1 || i == DCI_10_MSG2 || i == DCI_10_MSG4 {
+		if i == DCI_10_SIB1 || i == DCI_10_MSG2 || i == DCI_10_MSG4 || i == DCI_10_MSGB || i == DCI_10_PAGING || i == DCI_10_OSI {
 			// validate TDRA
 			err := validateDci10PdschTdRa(i)
 			if err != nil {
@@ -2844,11 +12584,19 @@ func validatePdsch() error {
 			if err != nil {
 				return err
 			}
-		} else if i == DCI_10_MSGB {
-			// TODO: MSGB transmission scheduled by DCI 1_0 with MSGB-RNTI for two-steps CBRA
 		}
 	}
 
+	// refer to 3GPP TS 38.214 vh40
+	// 5.1.2.1	Resource allocation in time domain
+	// ... if pdsch-AggregationFactor is configured, the same symbol allocation is applied across the pdsch-AggregationFactor consecutive slots. The UE may expect that the TB is repeated within each symbol allocation among each of the pdsch-AggregationFactor consecutive slots and the PDSCH is limited to a single transmission layer.
+	if !utils.ContainsStr([]string{"n1", "n2", "n4", "n8"}, flags.pdsch._pdschAggFactor) {
+		return errors.New(fmt.Sprintf("Invalid pdsch-AggregationFactor(=%v) of PDSCH-Config, which can be n1/n2/n4/n8.", flags.pdsch._pdschAggFactor))
+	}
+	if flags.pdsch._pdschAggFactor != "n1" && len(flags.pdsch._dmrsPorts) > 1 {
+		return errors.New(fmt.Sprintf("PDSCH with pdsch-AggregationFactor(=%v) is limited to a single transmission layer while DMRS ports are %v.", flags.pdsch._pdschAggFactor, flags.pdsch._dmrsPorts))
+	}
+
 	return nil
 }
 
@@ -2873,6 +12621,12 @@ func validateDci10PdschTdRa(i int) error {
 	var p *nrgrid.TimeAllocInfo
 	var exist bool
 
+	// refer to 3GPP TS 38.214 vh40: Table 5.1.2.1.1-1: Applicable PDSCH time domain resource allocation for DCI formats 1_0, 1_1, 4_0, 4_1 and 4_2
+	// Note: PDSCH scheduled with P-RNTI in Type0 common search space(pagingSearchSpace=0) uses the same default tables as SI-RNTI, and Default A is used otherwise.
+	if rnti == "P-RNTI" && flags.paging.pagingSearchSpace == 0 {
+		rnti = "SI-RNTI"
+	}
+
 	switch rnti {
 	case "SI-RNTI":
 		switch flags.gridsetting._coreset0MultiplexingPat {
@@ -2881,20 +12635,20 @@ func validateDci10PdschTdRa(i int) error {
 		case 2:
 			// refer to 3GPP TS 38.214 vh40: Table 5.1.2.1.1-4: Default PDSCH time domain resource allocation B
 			// Note 1: If the PDSCH was scheduled with SI-RNTI in PDCCH Type0 common search space, the UE may assume that this PDSCH resource allocation is not applied.
-			if utils.ContainsInt(nrgrid.PdschTimeAllocDefBNote1Set, flags.dldci.tdra[i]+1) {
-				return errors.New(fmt.Sprintf("Row %v is invalid for SIB1 (refer to 'Note 1' of Table 5.1.2.1.1-4 of TS 38.214).", flags.dldci.tdra[i]+1))
+			if flags.dldci._rnti[i] == "SI-RNTI" && utils.ContainsInt(nrgrid.PdschTimeAllocDefBNote1Set, flags.dldci.tdra[i]+1) {
+				return errors.New(fmt.Sprintf("Row %v is invalid for %v (refer to 'Note 1' of Table 5.1.2.1.1-4 of TS 38.214).", flags.dldci.tdra[i]+1, flags.dldci._tag[i]))
 			}
 			p, exist = nrgrid.PdschTimeAllocDefB[key]
 		case 3:
 			// refer to 3GPP TS 38.214 vh40: Table 5.1.2.1.1-5: Default PDSCH time domain resource allocation C
 			// Note 1: The UE may assume that this PDSCH resource allocation is not used, if the PDSCH was scheduled with SI-RNTI in PDCCH Type0 common search space.
 			// Note 2:	This applies for Case F and Case G candidate SS/PBCH block pattern described in clause 4 of [6, TS 38.213]
-			if utils.ContainsInt(nrgrid.PdschTimeAllocDefCNote1Set, flags.dldci.tdra[i]+1) {
-				return errors.New(fmt.Sprintf("Row %v is invalid for SIB1 (refer to 'Note 1' of Table 5.1.2.1.1-5 of TS 38.214).", flags.dldci.tdra[i]+1))
+			if flags.dldci._rnti[i] == "SI-RNTI" && utils.ContainsInt(nrgrid.PdschTimeAllocDefCNote1Set, flags.dldci.tdra[i]+1) {
+				return errors.New(fmt.Sprintf("Row %v is invalid for %v (refer to 'Note 1' of Table 5.1.2.1.1-5 of TS 38.214).", flags.dldci.tdra[i]+1, flags.dldci._tag[i]))
 			}
 			p, exist = nrgrid.PdschTimeAllocDefC[key]
 		}
-	case "RA-RNTI", "TC-RNTI":
+	case "RA-RNTI", "TC-RNTI", "MSGB-RNTI", "P-RNTI":
 		if flags.bwp._bwpCp[INI_DL_BWP] == "normal" {
 			p, exist = nrgrid.PdschTimeAllocDefANormCp[key]
 		} else {
@@ -2906,12 +12660,12 @@ func validateDci10PdschTdRa(i int) error {
 		return errors.New(fmt.Sprintf("Invalid PDSCH time domain allocation: tdra=%v, dmrsTypeAPos=%v\n", flags.dldci.tdra[i], flags.gridsetting.dmrsTypeAPos))
 	} else {
 		// update DCI 1_0 info
-		fmt.Printf("TimeAllocInfo(tag=%v, rnti=%v, coreset0MultiplexingPat=%v): %v\n", flags.dldci._tag[i], rnti, flags.gridsetting._coreset0MultiplexingPat, *p)
+		fmt.Printf("TimeAllocInfo(tag=%v, rnti=%v, coreset0MultiplexingPat=%v): %v\n", flags.dldci._tag[i], flags.dldci._rnti[i], flags.gridsetting._coreset0MultiplexingPat, *p)
 		flags.dldci._tdMappingType[i] = p.MappingType
 		flags.dldci._tdK0[i] = p.K0K2
 		flags.dldci._tdStartSymb[i] = p.S
 		flags.dldci._tdNumSymbs[i] = p.L
-		sliv, _ := nrgrid.ToSliv(p.S, p.L, "PDSCH", p.MappingType, "normal", "")
+		sliv, _ := nrgrid.ToSliv(p.S, p.L, "PDSCH", p.MappingType, flags.bwp._bwpCp[INI_DL_BWP], "")
 		flags.dldci._tdSliv[i] = sliv
 
 		// update DMRS info
@@ -2932,7 +12686,7 @@ func validateDci10PdschTdRa(i int) error {
 		flags.dmrsCommon._dmrsAddPos[i] = "pos2"
 
 		// update TD/FD pattern of DMRS
-		flags.dmrsCommon._tdL[i], flags.dmrsCommon._fdK[i] = getDmrsPdschTdFdPattern("type1", p.MappingType, p.S, p.L, 1, "pos2", flags.dmrsCommon._cdmGroupsWoData[i])
+		flags.dmrsCommon._tdL[i], flags.dmrsCommon._fdK[i] = getDmrsPdschTdFdPattern("type1", p.MappingType, p.S, p.L, 1, "pos2", flags.dmrsCommon._cdmGroupsWoData[i], flags.bwp._bwpCp[INI_DL_BWP])
 		fmt.Printf("TD pattern within a slot of DMRS for %v: %v\n", flags.dmrsCommon._tag[i], flags.dmrsCommon._tdL[i])
 		fmt.Printf("FD pattern within a PRB of DMRS for %v: %v\n", flags.dmrsCommon._tag[i], flags.dmrsCommon._fdK[i])
 	}
@@ -2940,8 +12694,8 @@ func validateDci10PdschTdRa(i int) error {
 	return nil
 }
 
-// updateDci10PdschTbs updates the TBS field of DCI 1_0 scheduling Sib1/Msg2/Msg4.
-//  i: index of the flags.dci10 slices[0-SIB1, 1-Msg2, 2-Msg4]
+// updateDci10PdschTbs updates the TBS field of DCI 1_0 scheduling Sib1/Msg2/Msg4/MsgB/Paging/OSI.
+//  i: index of the flags.dci10 slices[0-SIB1, 1-Msg2, 2-Msg4, 4-MsgB, 5-Paging, 6-OSI]
 func updateDci10PdschTbs(i int) error {
 	// regYellow.Printf("-->calling updateDci10PdschTbs\n")
 
@@ -3036,7 +12790,7 @@ func validateDci11PdschTdRa() error {
 		flags.dldci._tdK0[DCI_11_PDSCH] = p.K0K2
 		flags.dldci._tdStartSymb[DCI_11_PDSCH] = p.S
 		flags.dldci._tdNumSymbs[DCI_11_PDSCH] = p.L
-		sliv, _ := nrgrid.ToSliv(p.S, p.L, "PDSCH", p.MappingType, "normal", "")
+		sliv, _ := nrgrid.ToSliv(p.S, p.L, "PDSCH", p.MappingType, flags.bwp._bwpCp[DED_DL_BWP], "")
 		flags.dldci._tdSliv[DCI_11_PDSCH] = sliv
 	}
 
@@ -3107,7 +12861,7 @@ func validateDci11PdschAntPorts() error {
 	flags.pdsch._numFrontLoadSymbs = p.NumDmrsSymbs
 
 	// determine TD/FD pattern of DMRS for PDSCH
-	flags.pdsch._tdL, flags.pdsch._fdK = getDmrsPdschTdFdPattern(dmrsType, flags.dldci._tdMappingType[DCI_11_PDSCH], flags.dldci._tdStartSymb[DCI_11_PDSCH], flags.dldci._tdNumSymbs[DCI_11_PDSCH], p.NumDmrsSymbs, flags.pdsch.pdschDmrsAddPos, p.CdmGroups)
+	flags.pdsch._tdL, flags.pdsch._fdK = getDmrsPdschTdFdPattern(dmrsType, flags.dldci._tdMappingType[DCI_11_PDSCH], flags.dldci._tdStartSymb[DCI_11_PDSCH], flags.dldci._tdNumSymbs[DCI_11_PDSCH], p.NumDmrsSymbs, flags.pdsch.pdschDmrsAddPos, p.CdmGroups, flags.bwp._bwpCp[DED_DL_BWP])
 	fmt.Printf("TD pattern within a slot of DMRS for PDSCH(DCI 1_1): %v\n", flags.pdsch._tdL)
 	fmt.Printf("FD pattern within a PRB of DMRS for PDSCH(DCI 1_1): %v\n", flags.pdsch._fdK)
 
@@ -3152,9 +12906,10 @@ func validateDci11PdschAntPorts() error {
 		return errors.New(fmt.Sprintf("Invalid 'Frequency domain resource assignment' field of DCI 1_1: fdRaType=%v, fdRa=%v, len(fdRa)=%v, bitsRaType0=%v\n", fdRaType, fdRa, len(fdRa), flags.dldci._fdBitsRaType0))
 	}
 
+	bwpStart, bwpSize := getPdschBwp()
 	fd := 0
 	if fdRaType == "raType0" {
-		rbgs := getRaType0Rbgs(flags.bwp._bwpStartRb[DED_DL_BWP], flags.bwp._bwpNumRbs[DED_DL_BWP], flags.pdsch._rbgSize)
+		rbgs := getRaType0Rbgs(bwpStart, bwpSize, flags.pdsch._rbgSize)
 		for i, c := range fdRa {
 			if c == '1' {
 				fd += rbgs[i]
@@ -3163,7 +12918,7 @@ func validateDci11PdschAntPorts() error {
 	} else {
 		fd = flags.dldci.fdNumRbs[DCI_11_PDSCH]
 		// update FDRA
-		riv, err := makeRiv(flags.dldci.fdNumRbs[DCI_11_PDSCH], flags.dldci.fdStartRb[DCI_11_PDSCH], flags.bwp._bwpNumRbs[DED_DL_BWP])
+		riv, err := makeRiv(flags.dldci.fdNumRbs[DCI_11_PDSCH], flags.dldci.fdStartRb[DCI_11_PDSCH], bwpSize)
 		if err != nil {
 			return err
 		}
@@ -3202,11 +12957,11 @@ func validateDci11PdschAntPorts() error {
 	// For PDSCH mapping type A,
 	// 	- the case dmrs-AdditionalPosition equals to 'pos3' is only supported when dmrs-TypeA-Position is equal to 'pos2'.
 	//	- l_d = 3 and l_d = 4 symbols in Tables 7.4.1.1.2-3 and 7.4.1.1.2-4 respectively is only applicable when dmrs-TypeA-Position is equal to 'pos2'.
-	//	- single-symbol DM-RS, l1=11 except if all of the following conditions are fulfilled in which case l1=12: (2023/2/22: DSS is not supported!)
+	//	- single-symbol DM-RS, l1=11 except if all of the following conditions are fulfilled in which case l1=12: (see getDmrsPdschTdFdPattern)
 	// For PDSCH mapping type B,
 	// 	- if ... and the front-loaded DM-RS of the PDSCH allocation collides with resources reserved for a search space set associated with a CORESET...(2023/2/23: Assume no collision between PDSCH DMRS and CORESET!)
 	//  - if the PDSCH duration ld is less than or equal to 4 OFDM symbols, only single-symbol DM-RS is supported.
-	//	- if the higher-layer parameter lte-CRS-ToMatchAround, lte-CRS-PatternList1, or lte-CRS-PatternList2 is configured,...(2023/2/23: DSS is not supported!)
+	//	- if the higher-layer parameter lte-CRS-ToMatchAround, lte-CRS-PatternList1, or lte-CRS-PatternList2 is configured,...(Note: DM-RS position adjustment for lte-CRS-ToMatchAround is not supported for PDSCH mapping type B!)
 	dmrsTypeAPos := flags.gridsetting.dmrsTypeAPos
 	if tdMappingType == "typeA" && dmrsAddPos == "pos3" && dmrsTypeAPos != "pos2" {
 		return errors.New(fmt.Sprintf("For PDSCH mapping type A, the case dmrs-AdditionalPosition equals to 'pos3' is only supported when dmrs-TypeA-Position is equal to 'pos2'.\npdschDmrsAddPos=%v,dmrsTypeAPos=%v\n", flags.pdsch.pdschDmrsAddPos, dmrsTypeAPos))
@@ -3253,15 +13008,15 @@ func validatePusch() error {
 	regYellow.Printf("-->calling validatePusch\n")
 
 	for i, _ := range flags.uldci._rnti {
-		if i == RAR_UL_MSG3 {
+		if i == RAR_UL_MSG3 || i == FBRAR_UL_MSG3 {
 			// validate TDRA
-			err := validateRarUlMsg3Tdra()
+			err := validateRarUlMsg3Tdra(i)
 			if err != nil {
 				return err
 			}
 
 			// update TBS
-			err = updateRarUlMsg3PuschTbs()
+			err = updateRarUlMsg3PuschTbs(i)
 			if err != nil {
 				return err
 			}
@@ -3276,24 +13031,49 @@ func validatePusch() error {
 			if err != nil {
 				return err
 			}
-		} else if i == RA_UL_MSGA {
-			// TODO: MSGA transmission for two-steps CBRA
-		} else if i == FBRAR_UL_MSG3 {
-			// TODO: MSG3 transmission scheduled by fallbackRAR UL grant for two-setps CBRA
+		}
+	}
+
+	if !utils.ContainsStr([]string{"n1", "n2", "n4", "n8"}, flags.pusch._puschAggFactor) {
+		return errors.New(fmt.Sprintf("Invalid pusch-AggregationFactor(=%v) of PUSCH-Config, which can be n1/n2/n4/n8.", flags.pusch._puschAggFactor))
+	}
+	if !utils.ContainsStr([]string{"typeA", "typeB"}, flags.pusch._puschRepType) {
+		return errors.New(fmt.Sprintf("Invalid pusch-RepTypeIndicator(=%v) of PUSCH-Config, which can be typeA/typeB.", flags.pusch._puschRepType))
+	}
+
+	// refer to 3GPP TS 38.214 vh40
+	// 6.1.2.1	Resource allocation in time domain
+	// For PUSCH repetition Type A, in case K>1, the same symbol allocation is applied across the K consecutive slots and the PUSCH is limited to a single transmission layer.
+	if flags.pusch._puschRepType == "typeA" && flags.pusch._puschAggFactor != "n1" && len(flags.pusch._dmrsPorts) > 1 {
+		return errors.New(fmt.Sprintf("PUSCH repetition Type A with pusch-AggregationFactor(=%v) is limited to a single transmission layer while DMRS ports are %v.", flags.pusch._puschAggFactor, flags.pusch._dmrsPorts))
+	}
+
+	// refer to 3GPP TS 38.214 vh40
+	// 6.1.2.1	Resource allocation in time domain
+	// 6.3	UE PUSCH frequency hopping procedure
+	// Note: PUSCH repetition Type B always applies PUSCH mapping type B, and its frequency hopping is either inter-repetition or inter-slot frequency hopping, where inter-repetition frequency hopping is not supported.
+	if flags.pusch._puschRepType == "typeB" {
+		if !utils.ContainsStr([]string{"n1", "n2", "n3", "n4", "n7", "n8", "n12", "n16"}, flags.pusch._puschAllocNumReps) {
+			return errors.New(fmt.Sprintf("Invalid numberOfRepetitions-r16(=%v) of PUSCH-Allocation-r16, which can be n1/n2/n3/n4/n7/n8/n12/n16.", flags.pusch._puschAllocNumReps))
+		}
+		if flags.uldci.fdFreqHop[DCI_01_PUSCH] == "intra-slot" {
+			return errors.New(fmt.Sprintf("Intra-slot frequency hopping is not applicable for PUSCH repetition Type B."))
 		}
 	}
 
 	return nil
 }
 
-func validateRarUlMsg3Tdra() error {
+// validateRarUlMsg3Tdra validates the "PUSCH time resource allocation" field of RAR UL grant or fallbackRAR UL grant.
+//  i: index of UL DCI, which can be RAR_UL_MSG3 or FBRAR_UL_MSG3
+func validateRarUlMsg3Tdra(i int) error {
 	// refer to 3GPP TS 38.214 vh40:
 	//  - Table 6.1.2.1.1-1: Applicable PUSCH time domain resource allocation for common search space and DCI format 0_0 in UE specific search space
 	//  - Table 6.1.2.1.1-1A: Applicable PUSCH time domain resource allocation for DCI format 0_1 in UE specific search space scrambled with C-RNTI, MCS-C-RNTI, CS-RNTI or SP-CSI-RNTI
 	//  - Table 6.1.2.1.1-1B: Applicable PUSCH time domain resource allocation for DCI format 0_2 in UE specific search space scrambled with C-RNTI, MCS-C-RNTI, CS-RNTI or SP-CSI-RNTI
 	//  - Table 6.1.2.1.1-2: Default PUSCH time domain resource allocation A for normal CP
 	//  - Table 6.1.2.1.1-3: Default PUSCH time domain resource allocation A for extended CP
-	row := flags.uldci.tdra[RAR_UL_MSG3] + 1
+	row := flags.uldci.tdra[i] + 1
 	var p *nrgrid.TimeAllocInfo
 	var exist bool
 
@@ -3304,33 +13084,66 @@ func validateRarUlMsg3Tdra() error {
 	}
 
 	if !exist {
-		return errors.New(fmt.Sprintf("Invalid PUSCH time domain allocation: tdra=%v, dmrsTypeAPos=%v\n", flags.uldci.tdra[RAR_UL_MSG3], flags.gridsetting.dmrsTypeAPos))
+		return errors.New(fmt.Sprintf("Invalid PUSCH time domain allocation: tdra=%v, dmrsTypeAPos=%v\n", flags.uldci.tdra[i], flags.gridsetting.dmrsTypeAPos))
 	} else {
 		// update Msg3 info
-		fmt.Printf("TimeAllocInfo(tag=%v, rnti=%v): %v\n", flags.uldci._tag[RAR_UL_MSG3], flags.uldci._rnti[RAR_UL_MSG3], *p)
-		flags.uldci._tdMappingType[RAR_UL_MSG3] = p.MappingType
-		flags.uldci._tdK2[RAR_UL_MSG3] = p.K0K2 + nrgrid.PuschTimeAllocK2j[flags.gridsetting.scs]
-		flags.uldci._tdDelta = nrgrid.PuschTimeAllocMsg3K2Delta[flags.gridsetting.scs]
-		flags.uldci._tdStartSymb[RAR_UL_MSG3] = p.S
-		flags.uldci._tdNumSymbs[RAR_UL_MSG3] = p.L
-		sliv, _ := nrgrid.ToSliv(p.S, p.L, "PUSCH", p.MappingType, "normal", "typeA")
-		flags.uldci._tdSliv[RAR_UL_MSG3] = sliv
+		fmt.Printf("TimeAllocInfo(tag=%v, rnti=%v): %v\n", flags.uldci._tag[i], flags.uldci._rnti[i], *p)
+		flags.uldci._tdMappingType[i] = p.MappingType
+		flags.uldci._tdK2[i] = p.K0K2 + nrgrid.PuschTimeAllocK2j[getUlScs()]
+		flags.uldci._tdDelta = nrgrid.PuschTimeAllocMsg3K2Delta[getUlScs()]
+		flags.uldci._tdStartSymb[i] = p.S
+		flags.uldci._tdNumSymbs[i] = p.L
+		sliv, _ := nrgrid.ToSliv(p.S, p.L, "PUSCH", p.MappingType, flags.bwp._bwpCp[INI_UL_BWP], "typeA")
+		flags.uldci._tdSliv[i] = sliv
 	}
 
 	return nil
 }
 
-// updateRarUlMsg3PuschTbs updates the TBS field of Msg3 PUSCH scheduled by RAR Msg2.
-func updateRarUlMsg3PuschTbs() error {
+// msg3UlGrantIdx returns the index of UL DCI which schedules Msg3 PUSCH, which is RAR_UL_MSG3 for 4-step RA or FBRAR_UL_MSG3 for 2-step RA.
+func msg3UlGrantIdx() int {
+	if flags.rach.raType == "2-step" {
+		return FBRAR_UL_MSG3
+	}
+
+	return RAR_UL_MSG3
+}
+
+// getMsg3DmrsCfg returns the number of CDM groups without data and dmrs-AdditionalPosition of DMRS for Msg3 PUSCH scheduled by RAR UL grant or fallbackRAR UL grant.
+//  i: index of UL DCI, which can be RAR_UL_MSG3 or FBRAR_UL_MSG3
+func getMsg3DmrsCfg(i int) (int, string) {
+	// 2023-3-10: assume Msg3 follows the same rules as DCI 0_0 with TC-RNTI which is used for Msg3 retransmission
+	cdmGroupsWoData := 2
+	if flags.uldci._tdNumSymbs[i] <= 2 && flags.rach.msg3Tp == "disabled" {
+		cdmGroupsWoData = 1
+	}
+
+	// refer to 3GPP TS 38.214 vh40: 6.2.2	UE DM-RS transmission procedure
+	// If frequency hopping is disabled:
+	// -	The UE shall assume dmrs-AdditionalPosition equals to 'pos2' and up to two additional DM-RS can be transmitted according to PUSCH duration, or
+	// If frequency hopping is enabled:
+	// -	The UE shall assume dmrs-AdditionalPosition equals to 'pos1' and up to one additional DM-RS can be transmitted according to PUSCH duration.
+	dmrsAddPos := "pos2"
+	if flags.uldci.fdFreqHop[i] == "intra-slot" {
+		dmrsAddPos = "pos1"
+	}
+
+	return cdmGroupsWoData, dmrsAddPos
+}
+
+// updateRarUlMsg3PuschTbs updates the TBS field of Msg3 PUSCH scheduled by RAR UL grant(Msg2) or fallbackRAR UL grant(MsgB).
+//  i: index of UL DCI, which can be RAR_UL_MSG3 or FBRAR_UL_MSG3
+func updateRarUlMsg3PuschTbs(i int) error {
 	//regYellow.Printf("-->calling updateRarUlMsg3PuschTbs\n")
 
-	td := flags.uldci._tdNumSymbs[RAR_UL_MSG3]
-	fd := flags.uldci.fdNumRbs[RAR_UL_MSG3]
+	td := flags.uldci._tdNumSymbs[i]
+	fd := flags.uldci.fdNumRbs[i]
 
 	// validate L_RBs when transform precoding is enabled
+	_, bwpSize := getUlBwp(INI_UL_BWP)
 	if flags.rach.msg3Tp == "enabled" {
 		// valid PUSCH PRB allocations when transforming precoding is enabled
-		lrbsPuschTp := initLrbsPuschTp(flags.bwp._bwpNumRbs[INI_UL_BWP])
+		lrbsPuschTp := initLrbsPuschTp(bwpSize)
 
 		if !utils.ContainsInt(lrbsPuschTp, fd) {
 			lt, gt := utils.NearestInt(lrbsPuschTp, fd)
@@ -3339,64 +13152,51 @@ func updateRarUlMsg3PuschTbs() error {
 	}
 
 	// update FDRA
-	riv, err := makeRiv(flags.uldci.fdNumRbs[RAR_UL_MSG3], flags.uldci.fdStartRb[RAR_UL_MSG3], flags.bwp._bwpNumRbs[INI_UL_BWP])
+	riv, err := makeRiv(flags.uldci.fdNumRbs[i], flags.uldci.fdStartRb[i], bwpSize)
 	if err != nil {
 		return err
 	}
-	flags.uldci._fdRa[RAR_UL_MSG3] = fmt.Sprintf("%0*b", flags.uldci._fdBitsRaType1[RAR_UL_MSG3], riv)
-	fmt.Printf("PUSCH(tag=%v): RIV=%v, FDRA bits=%v\n", flags.uldci._tag[RAR_UL_MSG3], riv, flags.uldci._fdRa[RAR_UL_MSG3])
-	if flags.uldci.fdFreqHop[RAR_UL_MSG3] != "disabled" {
+	flags.uldci._fdRa[i] = fmt.Sprintf("%0*b", flags.uldci._fdBitsRaType1[i], riv)
+	fmt.Printf("PUSCH(tag=%v): RIV=%v, FDRA bits=%v\n", flags.uldci._tag[i], riv, flags.uldci._fdRa[i])
+	if flags.uldci.fdFreqHop[i] != "disabled" {
 		var ulHopBits int
-		if flags.bwp._bwpNumRbs[INI_UL_BWP] >= 50 {
+		if bwpSize >= 50 {
 			ulHopBits = 2
 		} else {
 			ulHopBits = 1
 		}
 
-		v, _ := strconv.Atoi(flags.uldci._fdRa[RAR_UL_MSG3][:ulHopBits])
+		v, _ := strconv.Atoi(flags.uldci._fdRa[i][:ulHopBits])
 		if v != 0 {
 			return errors.New(fmt.Sprintf("The first %v bits of RIV must be all zeros when frequency hopping is enabled!", ulHopBits))
 		}
 	}
 
 	// update DMRS for Msg3 PUSCH
-	// 2023-3-10: assume Msg3 follows the same rules as DCI 0_0 with TC-RNTI which is used for Msg3 retransmission
-	if td <= 2 && flags.rach.msg3Tp == "disabled" {
-		flags.dmrsCommon._cdmGroupsWoData[DMRS_RAR_UL_MSG3] = 1
-	} else {
-		flags.dmrsCommon._cdmGroupsWoData[DMRS_RAR_UL_MSG3] = 2
-	}
-	flags.dmrsCommon._dmrsType[DMRS_RAR_UL_MSG3] = "type1"
-	flags.dmrsCommon._dmrsPorts[DMRS_RAR_UL_MSG3] = 0
-	flags.dmrsCommon._maxLength[DMRS_RAR_UL_MSG3] = "len1"
-	flags.dmrsCommon._numFrontLoadSymbs[DMRS_RAR_UL_MSG3] = 1
-
-	// refer to 3GPP TS 38.214 vh40: 6.2.2	UE DM-RS transmission procedure
-	// When transmitted PUSCH is neither scheduled by DCI format 0_1/0_2 with CRC scrambled by C-RNTI, CS-RNTI, SP-CSI-RNTI or MCS-C-RNTI, nor corresponding to a configured grant, nor being a PUSCH for Type-2 random access procedure, the UE shall use single symbol front-loaded DM-RS of configuration type 1 on DM-RS port 0 and the remaining REs not used for DM-RS in the symbols are not used for any PUSCH transmission except for PUSCH with allocation duration of 2 or less OFDM symbols with transform precoding disabled, additional DM-RS can be transmitted according to the scheduling type and the PUSCH duration as specified in Table 6.4.1.1.3-3 of [4, TS38.211] for frequency hopping disabled and as specified in Table 6.4.1.1.3-6 of [4, TS38.211] for frequency hopping enabled, and
-	// If frequency hopping is disabled:
-	// -	The UE shall assume dmrs-AdditionalPosition equals to 'pos2' and up to two additional DM-RS can be transmitted according to PUSCH duration, or
-	// If frequency hopping is enabled:
-	// -	The UE shall assume dmrs-AdditionalPosition equals to 'pos1' and up to one additional DM-RS can be transmitted according to PUSCH duration.
-	if flags.uldci.fdFreqHop[RAR_UL_MSG3] == "intra-slot" {
-		flags.dmrsCommon._dmrsAddPos[DMRS_RAR_UL_MSG3] = "pos1"
-	} else {
-		flags.dmrsCommon._dmrsAddPos[DMRS_RAR_UL_MSG3] = "pos2"
-	}
-
-	// determine TD/FD pattern of DMRS for Msg3 PUSCH
-	flags.dmrsCommon._tdL[DMRS_RAR_UL_MSG3], flags.dmrsCommon._tdL2, flags.dmrsCommon._fdK[DMRS_RAR_UL_MSG3] = getDmrsPuschTdFdPattern("type1", flags.uldci._tdMappingType[RAR_UL_MSG3], flags.uldci._tdStartSymb[RAR_UL_MSG3], flags.uldci._tdNumSymbs[RAR_UL_MSG3], 1, flags.dmrsCommon._dmrsAddPos[DMRS_RAR_UL_MSG3], flags.dmrsCommon._cdmGroupsWoData[DMRS_RAR_UL_MSG3], flags.uldci.fdFreqHop[RAR_UL_MSG3])
-	if flags.uldci.fdFreqHop[RAR_UL_MSG3] != "intra-slot" {
-		fmt.Printf("TD pattern within a slot of DMRS for %v: %v\n", flags.dmrsCommon._tag[DMRS_RAR_UL_MSG3], flags.dmrsCommon._tdL[DMRS_RAR_UL_MSG3])
-	} else {
-		fmt.Printf("TD pattern within a slot of DMRS for %v (1st hop): %v\n", flags.dmrsCommon._tag[DMRS_RAR_UL_MSG3], flags.dmrsCommon._tdL[DMRS_RAR_UL_MSG3])
-		fmt.Printf("TD pattern within a slot of DMRS for %v (2nd hop): %v\n", flags.dmrsCommon._tag[DMRS_RAR_UL_MSG3], flags.dmrsCommon._tdL2)
+	// Note: common DMRS of Msg3 is shared by Msg3 scheduled by RAR UL grant(4-step RA) and fallbackRAR UL grant(2-step RA), and is updated by the UL grant in use.
+	cdmGroupsWoData, dmrsAddPos := getMsg3DmrsCfg(i)
+	if i == msg3UlGrantIdx() {
+		flags.dmrsCommon._cdmGroupsWoData[DMRS_RAR_UL_MSG3] = cdmGroupsWoData
+		flags.dmrsCommon._dmrsType[DMRS_RAR_UL_MSG3] = "type1"
+		flags.dmrsCommon._dmrsPorts[DMRS_RAR_UL_MSG3] = 0
+		flags.dmrsCommon._maxLength[DMRS_RAR_UL_MSG3] = "len1"
+		flags.dmrsCommon._numFrontLoadSymbs[DMRS_RAR_UL_MSG3] = 1
+		flags.dmrsCommon._dmrsAddPos[DMRS_RAR_UL_MSG3] = dmrsAddPos
+
+		// determine TD/FD pattern of DMRS for Msg3 PUSCH
+		flags.dmrsCommon._tdL[DMRS_RAR_UL_MSG3], flags.dmrsCommon._tdL2, flags.dmrsCommon._fdK[DMRS_RAR_UL_MSG3] = getDmrsPuschTdFdPattern("type1", flags.uldci._tdMappingType[i], flags.uldci._tdStartSymb[i], flags.uldci._tdNumSymbs[i], 1, dmrsAddPos, cdmGroupsWoData, flags.uldci.fdFreqHop[i], flags.bwp._bwpCp[INI_UL_BWP])
+		if flags.uldci.fdFreqHop[i] != "intra-slot" {
+			fmt.Printf("TD pattern within a slot of DMRS for %v: %v\n", flags.dmrsCommon._tag[DMRS_RAR_UL_MSG3], flags.dmrsCommon._tdL[DMRS_RAR_UL_MSG3])
+		} else {
+			fmt.Printf("TD pattern within a slot of DMRS for %v (1st hop): %v\n", flags.dmrsCommon._tag[DMRS_RAR_UL_MSG3], flags.dmrsCommon._tdL[DMRS_RAR_UL_MSG3])
+			fmt.Printf("TD pattern within a slot of DMRS for %v (2nd hop): %v\n", flags.dmrsCommon._tag[DMRS_RAR_UL_MSG3], flags.dmrsCommon._tdL2)
+		}
+		fmt.Printf("FD pattern within a PRB of DMRS for %v: %v\n", flags.dmrsCommon._tag[DMRS_RAR_UL_MSG3], flags.dmrsCommon._fdK[DMRS_RAR_UL_MSG3])
 	}
-	fmt.Printf("FD pattern within a PRB of DMRS for %v: %v\n", flags.dmrsCommon._tag[DMRS_RAR_UL_MSG3], flags.dmrsCommon._fdK[DMRS_RAR_UL_MSG3])
 
 	// calculate DMRS overhead
-	tdMappingType := flags.uldci._tdMappingType[RAR_UL_MSG3]
-	dmrsAddPos := flags.dmrsCommon._dmrsAddPos[DMRS_RAR_UL_MSG3]
-	freqHop := flags.uldci.fdFreqHop[RAR_UL_MSG3]
+	tdMappingType := flags.uldci._tdMappingType[i]
+	freqHop := flags.uldci.fdFreqHop[i]
 	var v1, v2, v []int
 	var e1, e2, e bool
 	var key1, key2, key string
@@ -3443,7 +13243,7 @@ func updateRarUlMsg3PuschTbs() error {
 		// ld is the duration of scheduled PUSCH resources for PUSCH mapping type B according to Tables 6.4.1.1.3-3 and 6.4.1.1.3-4 if intra-slot frequency hopping is not used
 		var ld int
 		if tdMappingType == "typeA" {
-			ld = flags.uldci._tdStartSymb[RAR_UL_MSG3] + td
+			ld = flags.uldci._tdStartSymb[i] + td
 		} else {
 			ld = td
 		}
@@ -3463,23 +13263,23 @@ func updateRarUlMsg3PuschTbs() error {
 
 	var dmrsOh int
 	if freqHop == "intra-slot" {
-		dmrsOh = (2 * flags.dmrsCommon._cdmGroupsWoData[DMRS_RAR_UL_MSG3]) * (len(v1) + len(v2))
-		fmt.Printf("PUSCH(tag=%v) DMRS overhead: cdmGroupsWoData=%v, key1=%v, val1=%v, key2=%v, val2=%v\n", flags.uldci._tag[RAR_UL_MSG3], flags.dmrsCommon._cdmGroupsWoData[DMRS_RAR_UL_MSG3], key1, v1, key2, v2)
+		dmrsOh = (2 * cdmGroupsWoData) * (len(v1) + len(v2))
+		fmt.Printf("PUSCH(tag=%v) DMRS overhead: cdmGroupsWoData=%v, key1=%v, val1=%v, key2=%v, val2=%v\n", flags.uldci._tag[i], cdmGroupsWoData, key1, v1, key2, v2)
 	} else {
-		dmrsOh = (2 * flags.dmrsCommon._cdmGroupsWoData[DMRS_RAR_UL_MSG3]) * len(v)
-		fmt.Printf("PUSCH(tag=%v) DMRS overhead: cdmGroupsWoData=%v, key=%v, val=%v\n", flags.uldci._tag[RAR_UL_MSG3], flags.dmrsCommon._cdmGroupsWoData[DMRS_RAR_UL_MSG3], key, v)
+		dmrsOh = (2 * cdmGroupsWoData) * len(v)
+		fmt.Printf("PUSCH(tag=%v) DMRS overhead: cdmGroupsWoData=%v, key=%v, val=%v\n", flags.uldci._tag[i], cdmGroupsWoData, key, v)
 	}
 
 	// 38.214 vh40 6.1.4.2	Transport block size determination
 	// For Msg3 or MsgA PUSCH transmission the N_PRB_oh is always set to 0.
 	// 38.214 vh40 6.1.1	Transmission schemes
 	// If PUSCH is scheduled by DCI format 0_0, the PUSCH transmission is based on a single antenna port.
-	tbs, err := getTbs("PUSCH", flags.rach.msg3Tp == "enabled", "MSG3", "qam64", td, fd, flags.uldci.mcsCw0[RAR_UL_MSG3], 1, dmrsOh, 0, 1)
+	tbs, err := getTbs("PUSCH", flags.rach.msg3Tp == "enabled", "MSG3", "qam64", td, fd, flags.uldci.mcsCw0[i], 1, dmrsOh, 0, 1)
 	if err != nil {
 		return err
 	} else {
-		fmt.Printf("PUSCH(tag=%v) CW0 TBS=%v bits\n", flags.uldci._tag[RAR_UL_MSG3], tbs)
-		flags.uldci._tbs[RAR_UL_MSG3] = tbs
+		fmt.Printf("PUSCH(tag=%v) CW0 TBS=%v bits\n", flags.uldci._tag[i], tbs)
+		flags.uldci._tbs[i] = tbs
 	}
 	fmt.Println()
 
@@ -3513,10 +13313,28 @@ func validateDci01PuschTdRa() error {
 		// update uldci info
 		fmt.Printf("TimeAllocInfo(tag=%v, rnti=%v): %v\n", flags.uldci._tag[DCI_01_PUSCH], flags.uldci._rnti[DCI_01_PUSCH], *p)
 		flags.uldci._tdMappingType[DCI_01_PUSCH] = p.MappingType
-		flags.uldci._tdK2[DCI_01_PUSCH] = p.K0K2 + nrgrid.PuschTimeAllocK2j[flags.gridsetting.scs]
+		flags.uldci._tdK2[DCI_01_PUSCH] = p.K0K2 + nrgrid.PuschTimeAllocK2j[getUlScs()]
 		flags.uldci._tdStartSymb[DCI_01_PUSCH] = p.S
 		flags.uldci._tdNumSymbs[DCI_01_PUSCH] = p.L
-		sliv, _ := nrgrid.ToSliv(p.S, p.L, "PUSCH", p.MappingType, "normal", flags.pusch._puschRepType)
+		sliv, _ := nrgrid.ToSliv(p.S, p.L, "PUSCH", p.MappingType, flags.bwp._bwpCp[DED_UL_BWP], flags.pusch._puschRepType)
+		flags.uldci._tdSliv[DCI_01_PUSCH] = sliv
+	}
+
+	// refer to 3GPP 38.214 vh40
+	// 6.1.2.1	Resource allocation in time domain
+	// For PUSCH repetition Type B, the starting symbol S relative to the start of the slot, and the number of consecutive symbols L counting from the symbol S allocated for the PUSCH are provided by startSymbol and length of the indexed row of the resource allocation table, respectively.
+	// For PUSCH repetition Type B, the PUSCH mapping type is set to Type B.
+	// Note: k2-r16 of PUSCH-Allocation-r16 is assumed to be the K2 of the default PUSCH time domain resource allocation A.
+	if flags.pusch._puschRepType == "typeB" {
+		S, L := flags.pusch._puschAllocStartSymb, flags.pusch._puschAllocLength
+		sliv, err := nrgrid.ToSliv(S, L, "PUSCH", "typeB", flags.bwp._bwpCp[DED_UL_BWP], "typeB")
+		if err != nil {
+			return errors.New(fmt.Sprintf("Invalid PUSCH-Allocation-r16 for PUSCH repetition Type B: startSymbol=%v, length=%v", S, L))
+		}
+		fmt.Printf("PUSCH-Allocation-r16(tag=%v, rnti=%v): startSymbol=%v, length=%v, numberOfRepetitions=%v\n", flags.uldci._tag[DCI_01_PUSCH], flags.uldci._rnti[DCI_01_PUSCH], S, L, flags.pusch._puschAllocNumReps)
+		flags.uldci._tdMappingType[DCI_01_PUSCH] = "typeB"
+		flags.uldci._tdStartSymb[DCI_01_PUSCH] = S
+		flags.uldci._tdNumSymbs[DCI_01_PUSCH] = L
 		flags.uldci._tdSliv[DCI_01_PUSCH] = sliv
 	}
 
@@ -3682,7 +13500,7 @@ func validateDci01PuschAntPorts() error {
 	}
 
 	// determine TD/FD pattern of DMRS for PUSCH
-	flags.pusch._tdL, flags.pusch._tdL2, flags.pusch._fdK = getDmrsPuschTdFdPattern(dmrsType, flags.uldci._tdMappingType[DCI_01_PUSCH], flags.uldci._tdStartSymb[DCI_01_PUSCH], flags.uldci._tdNumSymbs[DCI_01_PUSCH], p.NumDmrsSymbs, flags.pusch.puschDmrsAddPos, p.CdmGroups, flags.uldci.fdFreqHop[DCI_01_PUSCH])
+	flags.pusch._tdL, flags.pusch._tdL2, flags.pusch._fdK = getDmrsPuschTdFdPattern(dmrsType, flags.uldci._tdMappingType[DCI_01_PUSCH], flags.uldci._tdStartSymb[DCI_01_PUSCH], flags.uldci._tdNumSymbs[DCI_01_PUSCH], p.NumDmrsSymbs, flags.pusch.puschDmrsAddPos, p.CdmGroups, flags.uldci.fdFreqHop[DCI_01_PUSCH], flags.bwp._bwpCp[DED_UL_BWP])
 	if flags.uldci.fdFreqHop[DCI_01_PUSCH] != "intra-slot" {
 		fmt.Printf("TD pattern within a slot of DMRS for PUSCH (DCI 0_1): %v\n", flags.pusch._tdL)
 	} else {
@@ -3863,21 +13681,69 @@ func validateDci01PuschAntPorts() error {
 		return errors.New(fmt.Sprintf("Invalid 'Frequency domain resource assignment' field of DCI 0_1: fdRaType=%v, fdRa=%v, len(fdRa)=%v, bitsRaType0=%v\n", fdRaType, fdRa, len(fdRa), flags.uldci._fdBitsRaType0))
 	}
 
+	bwpStart, bwpSize := getUlBwp(DED_UL_BWP)
 	fd := 0
 	if fdRaType == "raType0" {
-		rbgs := getRaType0Rbgs(flags.bwp._bwpStartRb[DED_UL_BWP], flags.bwp._bwpNumRbs[DED_UL_BWP], flags.pdsch._rbgSize)
+		rbgs := getRaType0Rbgs(bwpStart, bwpSize, flags.pdsch._rbgSize)
 		for i, c := range fdRa {
 			if c == '1' {
 				fd += rbgs[i]
 			}
 		}
+	} else if fdRaType == "raType2" {
+		prbs, err := getInterlacedPrbs(bwpStart, bwpSize, flags.uldci.fdInterlaces, flags.uldci.fdStartRbSet, flags.uldci.fdNumRbSets)
+		if err != nil {
+			return err
+		}
+		fd = len(prbs)
+
+		// update FDRA
+		// refer to 3GPP 38.212 vh40
+		// 7.3.1.1.2	Format 0_1
+		// For resource allocation type 2, ... the X MSBs provide the interlace allocation: for u=0, X=6 bits provide RIV of contiguous interlaces; for u=1, X=5 bits provide a bitmap indicating the allocated interlaces. The Y LSBs provide the RB set allocation, where Y = ceil(log2(N_RBset_BWP*(N_RBset_BWP+1)/2)).
+		M := getNumInterlaces()
+		bitsX := ""
+		if M == 5 {
+			for m := 0; m < M; m++ {
+				if utils.ContainsInt(flags.uldci.fdInterlaces, m) {
+					bitsX += "1"
+				} else {
+					bitsX += "0"
+				}
+			}
+		} else {
+			m0 := utils.MinInt(flags.uldci.fdInterlaces)
+			L := len(flags.uldci.fdInterlaces)
+			if utils.MaxInt(flags.uldci.fdInterlaces)-m0+1 != L {
+				return errors.New(fmt.Sprintf("For 15KHz SCS, the interlaces of PUSCH must be contiguous!(fdInterlaces=%v)", flags.uldci.fdInterlaces))
+			}
+
+			riv, err := makeRiv(L, m0, M)
+			if err != nil {
+				return err
+			}
+			bitsX = fmt.Sprintf("%06b", riv)
+		}
+
+		bitsY := ""
+		numRbSets := len(getRbSets(bwpStart, bwpSize))
+		if numRbSets > 1 {
+			riv, err := makeRiv(flags.uldci.fdNumRbSets, flags.uldci.fdStartRbSet, numRbSets)
+			if err != nil {
+				return err
+			}
+			bitsY = fmt.Sprintf("%0*b", utils.CeilInt(math.Log2(float64(numRbSets*(numRbSets+1))/2)), riv)
+		}
+
+		flags.uldci._fdRa[DCI_01_PUSCH] = bitsX + bitsY
+		fmt.Printf("PUSCH(tag=%v): interlaces=%v, RB sets=[%v, %v), prbs=%v, FDRA bits=%v\n", flags.uldci._tag[DCI_01_PUSCH], flags.uldci.fdInterlaces, flags.uldci.fdStartRbSet, flags.uldci.fdStartRbSet+flags.uldci.fdNumRbSets, prbs, flags.uldci._fdRa[DCI_01_PUSCH])
 	} else {
 		fd = flags.uldci.fdNumRbs[DCI_01_PUSCH]
 
 		// validate L_RBs when transform precoding is enabled
 		if tp == "enabled" {
 			// valid PUSCH PRB allocations when transforming precoding is enabled
-			lrbsPuschTp := initLrbsPuschTp(flags.bwp._bwpNumRbs[DED_UL_BWP])
+			lrbsPuschTp := initLrbsPuschTp(bwpSize)
 
 			if !utils.ContainsInt(lrbsPuschTp, fd) {
 				lt, gt := utils.NearestInt(lrbsPuschTp, fd)
@@ -3886,7 +13752,7 @@ func validateDci01PuschAntPorts() error {
 		}
 
 		// update FDRA
-		riv, err := makeRiv(flags.uldci.fdNumRbs[DCI_01_PUSCH], flags.uldci.fdStartRb[DCI_01_PUSCH], flags.bwp._bwpNumRbs[DED_UL_BWP])
+		riv, err := makeRiv(flags.uldci.fdNumRbs[DCI_01_PUSCH], flags.uldci.fdStartRb[DCI_01_PUSCH], bwpSize)
 		if err != nil {
 			return err
 		}
@@ -3894,7 +13760,7 @@ func validateDci01PuschAntPorts() error {
 		fmt.Printf("PUSCH(tag=%v): RIV=%v, FDRA bits=%v\n", flags.uldci._tag[DCI_01_PUSCH], riv, flags.uldci._fdRa[DCI_01_PUSCH])
 		if flags.uldci.fdFreqHop[DCI_01_PUSCH] != "disabled" {
 			var ulHopBits int
-			if flags.bwp._bwpNumRbs[DED_UL_BWP] >= 50 {
+			if bwpSize >= 50 {
 				ulHopBits = 2
 			} else {
 				ulHopBits = 1
@@ -4054,7 +13920,7 @@ func getRaType0Rbgs(bwpStart, bwpSize, P int) []int {
 func getTbs(sch string, tp bool, rnti string, mcsTab string, td int, fd int, mcs int, layer int, dmrs int, xoh int, scale float64) (int, error) {
 	// regYellow.Printf("-->calling getTbs\n")
 
-	rntiSet := []string{"C-RNTI", "SI-RNTI", "RA-RNTI", "TC-RNTI", "MSG3"}
+	rntiSet := []string{"C-RNTI", "SI-RNTI", "RA-RNTI", "TC-RNTI", "MSGB-RNTI", "P-RNTI", "MSG3", "MSGA"}
 	mcsTabSet := []string{"qam1024", "qam256", "qam64", "qam64LowSE"}
 
 	if !utils.ContainsStr(rntiSet, rnti) || !utils.ContainsStr(mcsTabSet, mcsTab) {
@@ -4091,10 +13957,10 @@ func getTbs(sch string, tp bool, rnti string, mcsTab string, td int, fd int, mcs
 	}
 	Qm, R := p.ModOrder, p.CodeRate
 
-	// The UE is not expected to decode a PDSCH scheduled with P-RNTI, RA-RNTI, SI-RNTI and Qm > 2.
+	// The UE is not expected to decode a PDSCH scheduled with P-RNTI, RA-RNTI, SI-RNTI, MSGB-RNTI and Qm > 2.
 	// FIXME: assume PDSCH scheduled with TC-RNTI has the same restraint.
-	if (rnti == "RA-RNTI" || rnti == "SI-RNTI" || rnti == "TC-RNTI") && Qm > 2 {
-		return 0, errors.New(fmt.Sprintf("The UE is not expected to decode a PDSCH scheduled with P-RNTI, RA-RNTI, SI-RNTI and Qm > 2.\nMcsInfo=%v\n", *p))
+	if (rnti == "RA-RNTI" || rnti == "SI-RNTI" || rnti == "TC-RNTI" || rnti == "MSGB-RNTI" || rnti == "P-RNTI") && Qm > 2 {
+		return 0, errors.New(fmt.Sprintf("The UE is not expected to decode a PDSCH scheduled with P-RNTI, RA-RNTI, SI-RNTI, MSGB-RNTI and Qm > 2.\nMcsInfo=%v\n", *p))
 	}
 
 	// 2nd step: get N_RE
@@ -4150,7 +14016,8 @@ func getTbs(sch string, tp bool, rnti string, mcsTab string, td int, fd int, mcs
 //	numFrontLoadSymbs: number of front-load OFDM symbol(s) of DMRS for PDSCH
 //	dmrsAddPos: the dmrs-AdditionalPosition
 //	cdmGroupsWoData: number of CDM group(s) without data
-func getDmrsPdschTdFdPattern(dmrsType string, tdMappingType string, slivS int, slivL int, numFrontLoadSymbs int, dmrsAddPos string, cdmGroupsWoData int) ([]int, []int) {
+//	cp: cyclic prefix of the BWP, which can be normal or extended
+func getDmrsPdschTdFdPattern(dmrsType string, tdMappingType string, slivS int, slivL int, numFrontLoadSymbs int, dmrsAddPos string, cdmGroupsWoData int, cp string) ([]int, []int) {
 	// determine TD pattern
 	var tdL0, tdLd int
 	if tdMappingType == "typeA" {
@@ -4163,12 +14030,29 @@ func getDmrsPdschTdFdPattern(dmrsType string, tdMappingType string, slivS int, s
 
 	var tdLbar, tdLap []int
 	if numFrontLoadSymbs == 1 {
-		tdLbar = nrgrid.DmrsPdschPosOneSymb[fmt.Sprintf("%v_%v_%v", tdLd, tdMappingType, dmrsAddPos)]
+		if cp == "extended" {
+			tdLbar = nrgrid.DmrsPdschPosOneSymbExtCp[fmt.Sprintf("%v_%v_%v", tdLd, tdMappingType, dmrsAddPos)]
+		} else {
+			tdLbar = nrgrid.DmrsPdschPosOneSymb[fmt.Sprintf("%v_%v_%v", tdLd, tdMappingType, dmrsAddPos)]
+		}
 		tdLap = []int{0}
 	} else {
 		tdLbar = nrgrid.DmrsPdschPosTwoSymbs[fmt.Sprintf("%v_%v_%v", tdLd, tdMappingType, dmrsAddPos)]
 		tdLap = []int{0, 1}
 	}
+	if tdLbar == nil {
+		return nil, nil
+	}
+
+	// refer to 3GPP TS 38.211 vh40: 7.4.1.1.2	Mapping to physical resources (DMRS for PDSCH)
+	// For PDSCH mapping type A, single-symbol DM-RS, l1=11 except if all of the following conditions are fulfilled in which case l1=12:
+	//  - the higher-layer parameter lte-CRS-ToMatchAround is configured
+	//  - the higher-layer parameter dmrs-AdditionalPosition is equal to 'pos1' and l0=3
+	//  - the UE has indicated it is capable of additionalDMRS-DL-Alt
+	// Note: the UE is assumed to be capable of additionalDMRS-DL-Alt.
+	if flags.dss.lteCrsEnabled && tdMappingType == "typeA" && numFrontLoadSymbs == 1 && dmrsAddPos == "pos1" && tdL0 == 3 && len(tdLbar) == 2 && tdLbar[1] == 11 {
+		tdLbar = []int{tdLbar[0], 12}
+	}
 
 	// replace tdLbar[0] with l0
 	tdLbar[0] = tdL0
@@ -4217,7 +14101,8 @@ func getDmrsPdschTdFdPattern(dmrsType string, tdMappingType string, slivS int, s
 //	dmrsAddPos: the dmrs-AdditionalPosition
 //	cdmGroupsWoData: number of CDM group(s) without data
 //	freqHop: indicate whether intra-slot frequency hopping is enabled
-func getDmrsPuschTdFdPattern(dmrsType string, tdMappingType string, slivS int, slivL int, numFrontLoadSymbs int, dmrsAddPos string, cdmGroupsWoData int, freqHop string) ([]int, []int, []int) {
+//	cp: cyclic prefix of the BWP, which can be normal or extended
+func getDmrsPuschTdFdPattern(dmrsType string, tdMappingType string, slivS int, slivL int, numFrontLoadSymbs int, dmrsAddPos string, cdmGroupsWoData int, freqHop string, cp string) ([]int, []int, []int) {
 	// determine TD pattern
 	var tdL0, tdLd int
 	var tdLbar, tdLap []int
@@ -4232,12 +14117,19 @@ func getDmrsPuschTdFdPattern(dmrsType string, tdMappingType string, slivS int, s
 		}
 
 		if numFrontLoadSymbs == 1 {
-			tdLbar = nrgrid.DmrsPuschPosOneSymbWoIntraSlotFh[fmt.Sprintf("%v_%v_%v", tdLd, tdMappingType, dmrsAddPos)]
+			if cp == "extended" {
+				tdLbar = nrgrid.DmrsPuschPosOneSymbWoIntraSlotFhExtCp[fmt.Sprintf("%v_%v_%v", tdLd, tdMappingType, dmrsAddPos)]
+			} else {
+				tdLbar = nrgrid.DmrsPuschPosOneSymbWoIntraSlotFh[fmt.Sprintf("%v_%v_%v", tdLd, tdMappingType, dmrsAddPos)]
+			}
 			tdLap = []int{0}
 		} else {
 			tdLbar = nrgrid.DmrsPuschPosTwoSymbsWoIntraSlotFh[fmt.Sprintf("%v_%v_%v", tdLd, tdMappingType, dmrsAddPos)]
 			tdLap = []int{0, 1}
 		}
+		if tdLbar == nil {
+			return nil, nil, nil
+		}
 		// replace tdLbar[0] with l0
 		tdLbar[0] = tdL0
 
@@ -4427,7 +14319,8 @@ var pdschCmd = &cobra.Command{
 
 		// process pdsch.pdschRbgCfg
 		if cmd.Flags().Lookup("pdschRbgCfg").Changed {
-			flags.pdsch._rbgSize = getNominalRbgSize(flags.bwp._bwpNumRbs[DED_DL_BWP], flags.pdsch.pdschRbgCfg)
+			_, bwpSize := getPdschBwp()
+			flags.pdsch._rbgSize = getNominalRbgSize(bwpSize, flags.pdsch.pdschRbgCfg)
 		}
 
 		laPrint(cmd, args)
@@ -4470,7 +14363,8 @@ var puschCmd = &cobra.Command{
 
 		// process pusch.puschRbgCfg
 		if cmd.Flags().Lookup("puschRbgCfg").Changed {
-			flags.pusch._rbgSize = getNominalRbgSize(flags.bwp._bwpNumRbs[DED_UL_BWP], flags.pusch.puschRbgCfg)
+			_, bwpSize := getUlBwp(DED_UL_BWP)
+			flags.pusch._rbgSize = getNominalRbgSize(bwpSize, flags.pusch.puschRbgCfg)
 		}
 
 		// process pusch.puschPtrsGrpPatternTp
@@ -4532,6 +14426,126 @@ var pucchCmd = &cobra.Command{
 	},
 }
 
+// pagingCmd represents the "nrrg paging" command
+var pagingCmd = &cobra.Command{
+	Use:   "paging",
+	Short: "",
+	Long:  `CMD "nrrg paging" can be used to get/set PCCH-Config and paging related network configurations.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		loadNrrgFlags()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		viper.WatchConfig()
+		laPrint(cmd, args)
+		viper.WriteConfig()
+	},
+}
+
+// osiCmd represents the "nrrg osi" command
+var osiCmd = &cobra.Command{
+	Use:   "osi",
+	Short: "",
+	Long:  `CMD "nrrg osi" can be used to get/set si-SchedulingInfo of SIB1 related network configurations.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		loadNrrgFlags()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		viper.WatchConfig()
+		laPrint(cmd, args)
+		viper.WriteConfig()
+	},
+}
+
+// drxCmd represents the "nrrg drx" command
+var drxCmd = &cobra.Command{
+	Use:   "drx",
+	Short: "",
+	Long:  `CMD "nrrg drx" can be used to get/set DRX-Config related network configurations.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		loadNrrgFlags()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		viper.WatchConfig()
+		laPrint(cmd, args)
+		viper.WriteConfig()
+	},
+}
+
+// cgSpsCmd represents the "nrrg cgsps" command
+var cgSpsCmd = &cobra.Command{
+	Use:   "cgsps",
+	Short: "",
+	Long:  `CMD "nrrg cgsps" can be used to get/set ConfiguredGrantConfig and SPS-Config related network configurations.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		loadNrrgFlags()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		viper.WatchConfig()
+		laPrint(cmd, args)
+		viper.WriteConfig()
+	},
+}
+
+// dssCmd represents the "nrrg dss" command
+var dssCmd = &cobra.Command{
+	Use:   "dss",
+	Short: "",
+	Long:  `CMD "nrrg dss" can be used to get/set lte-CRS-ToMatchAround and RateMatchPattern related network configurations for LTE-NR DSS.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		loadNrrgFlags()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		viper.WatchConfig()
+		laPrint(cmd, args)
+		viper.WriteConfig()
+	},
+}
+
+// caCmd represents the "nrrg ca" command
+var caCmd = &cobra.Command{
+	Use:   "ca",
+	Short: "",
+	Long:  `CMD "nrrg ca" can be used to get/set SCell and CrossCarrierSchedulingConfig related network configurations for carrier aggregation.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		loadNrrgFlags()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		viper.WatchConfig()
+		laPrint(cmd, args)
+		viper.WriteConfig()
+	},
+}
+
+// measCmd represents the "nrrg meas" command
+var measCmd = &cobra.Command{
+	Use:   "meas",
+	Short: "",
+	Long:  `CMD "nrrg meas" can be used to get/set SSB-MTC, MeasGapConfig and neighbour cell related network configurations for measurement.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		loadNrrgFlags()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		viper.WatchConfig()
+		laPrint(cmd, args)
+		viper.WriteConfig()
+	},
+}
+
+// posCmd represents the "nrrg pos" command
+var posCmd = &cobra.Command{
+	Use:   "pos",
+	Short: "",
+	Long:  `CMD "nrrg pos" can be used to get/set DL-PRS-ResourceSet and SRS-PosResource related network configurations for positioning.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		loadNrrgFlags()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		viper.WatchConfig()
+		laPrint(cmd, args)
+		viper.WriteConfig()
+	},
+}
+
 // advancedCmd represents the "nrrg advanced" command
 var advancedCmd = &cobra.Command{
 	Use:   "advanced",
@@ -4610,6 +14624,14 @@ func init() {
 	nrrgCmd.AddCommand(pucchCmd)
 	nrrgCmd.AddCommand(csiCmd)
 	nrrgCmd.AddCommand(srsCmd)
+	nrrgCmd.AddCommand(pagingCmd)
+	nrrgCmd.AddCommand(osiCmd)
+	nrrgCmd.AddCommand(drxCmd)
+	nrrgCmd.AddCommand(cgSpsCmd)
+	nrrgCmd.AddCommand(dssCmd)
+	nrrgCmd.AddCommand(caCmd)
+	nrrgCmd.AddCommand(measCmd)
+	nrrgCmd.AddCommand(posCmd)
 	nrrgCmd.AddCommand(advancedCmd)
 
 	if cmdFlags&CMD_FLAG_NRRG != 0 {
@@ -4637,6 +14659,14 @@ func init() {
 	initCsiCmd()
 	initSrsCmd()
 	initPucchCmd()
+	initPagingCmd()
+	initOsiCmd()
+	initDrxCmd()
+	initCgSpsCmd()
+	initDssCmd()
+	initCaCmd()
+	initMeasCmd()
+	initPosCmd()
 	initAdvancedCmd()
 }
 
@@ -4646,14 +14676,17 @@ func initGridSettingCmd() {
 	gridSettingCmd.Flags().StringVar(&flags.gridsetting._duplexMode, "_duplexMode", "FDD", "Duplex mode")
 	gridSettingCmd.Flags().IntVar(&flags.gridsetting._maxDlFreq, "_maxDlFreq", 803, "Maximum DL frequency(MHz)")
 	gridSettingCmd.Flags().StringVar(&flags.gridsetting._freqRange, "_freqRange", "FR1", "Frequency range(FR1/FR2)")
+	gridSettingCmd.Flags().BoolVar(&flags.gridsetting._unlicensed, "_unlicensed", false, "Whether the frequency band can be used for NR-U")
 	gridSettingCmd.Flags().SortFlags = false
 	viper.BindPFlag("nrrg.gridsetting.band", gridSettingCmd.Flags().Lookup("band"))
 	viper.BindPFlag("nrrg.gridsetting._duplexMode", gridSettingCmd.Flags().Lookup("_duplexMode"))
 	viper.BindPFlag("nrrg.gridsetting._maxDlFreq", gridSettingCmd.Flags().Lookup("_maxDlFreq"))
 	viper.BindPFlag("nrrg.gridsetting._freqRange", gridSettingCmd.Flags().Lookup("_freqRange"))
+	viper.BindPFlag("nrrg.gridsetting._unlicensed", gridSettingCmd.Flags().Lookup("_unlicensed"))
 	gridSettingCmd.Flags().MarkHidden("_duplexMode")
 	gridSettingCmd.Flags().MarkHidden("_maxDlFreq")
 	gridSettingCmd.Flags().MarkHidden("_freqRange")
+	gridSettingCmd.Flags().MarkHidden("_unlicensed")
 
 	// SCS
 	gridSettingCmd.Flags().StringVar(&flags.gridsetting.scs, "scs", "15KHz", "Subcarrier spacing for SSB/RMSI/Carrier/BWP etc.")
@@ -4670,6 +14703,9 @@ func initGridSettingCmd() {
 	gridSettingCmd.Flags().IntVar(&flags.gridsetting._maxLBar, "_maxLBar", 4, "L_max_bar as specified in 38.213")
 	gridSettingCmd.Flags().IntVar(&flags.gridsetting._maxL, "_maxL", 4, "L_max as specified in 38.213")
 	gridSettingCmd.Flags().IntSliceVar(&flags.gridsetting.candSsbIndex, "candSsbIndex", []int{0, 1, 2, 3}, "List of candidate SSB index")
+	gridSettingCmd.Flags().StringVar(&flags.gridsetting.ssbPosInBurst, "ssbPosInBurst", "", "ssb-PositionsInBurst of ServingCellConfigCommon[shortBitmap(4 bits),mediumBitmap(8 bits),longBitmap(64 bits)], which overrides candSsbIndex if configured")
+	gridSettingCmd.Flags().StringVar(&flags.gridsetting.ssbInOneGroup, "ssbInOneGroup", "", "inOneGroup of ssb-PositionsInBurst of SIB1(8 bits), which overrides candSsbIndex if configured and ssbPosInBurst is not configured")
+	gridSettingCmd.Flags().StringVar(&flags.gridsetting.ssbGroupPresence, "ssbGroupPresence", "", "groupPresence of ssb-PositionsInBurst of SIB1(8 bits), which is configured only when L_max is 64")
 	gridSettingCmd.Flags().SortFlags = false
 	viper.BindPFlag("nrrg.gridsetting._ssbScs", gridSettingCmd.Flags().Lookup("_ssbScs"))
 	viper.BindPFlag("nrrg.gridsetting.gscn", gridSettingCmd.Flags().Lookup("gscn"))
@@ -4680,6 +14716,9 @@ func initGridSettingCmd() {
 	viper.BindPFlag("nrrg.gridsetting._maxLBar", gridSettingCmd.Flags().Lookup("_maxLBar"))
 	viper.BindPFlag("nrrg.gridsetting._maxL", gridSettingCmd.Flags().Lookup("_maxL"))
 	viper.BindPFlag("nrrg.gridsetting.candSsbIndex", gridSettingCmd.Flags().Lookup("candSsbIndex"))
+	viper.BindPFlag("nrrg.gridsetting.ssbPosInBurst", gridSettingCmd.Flags().Lookup("ssbPosInBurst"))
+	viper.BindPFlag("nrrg.gridsetting.ssbInOneGroup", gridSettingCmd.Flags().Lookup("ssbInOneGroup"))
+	viper.BindPFlag("nrrg.gridsetting.ssbGroupPresence", gridSettingCmd.Flags().Lookup("ssbGroupPresence"))
 	gridSettingCmd.Flags().MarkHidden("_ssbScs")
 	gridSettingCmd.Flags().MarkHidden("_ssbPattern")
 	gridSettingCmd.Flags().MarkHidden("_kSsb")
@@ -4703,6 +14742,40 @@ func initGridSettingCmd() {
 	gridSettingCmd.Flags().MarkHidden("_carrierNumRbs")
 	gridSettingCmd.Flags().MarkHidden("_offsetToCarrier")
 
+	// supplementary carrier(SUL or SDL)
+	gridSettingCmd.Flags().StringVar(&flags.gridsetting.supBand, "supBand", "", "Supplementary band(SUL or SDL) paired with the band, or empty if not configured")
+	gridSettingCmd.Flags().StringVar(&flags.gridsetting._supDuplexMode, "_supDuplexMode", "", "Duplex mode of the supplementary band[SUL,SDL]")
+	gridSettingCmd.Flags().IntVar(&flags.gridsetting.supArfcn, "supArfcn", 347000, "UL ARFCN of SUL carrier or DL ARFCN of SDL carrier")
+	gridSettingCmd.Flags().StringVar(&flags.gridsetting.supBw, "supBw", "20MHz", "Transmission bandwidth(MHz) of the supplementary carrier")
+	gridSettingCmd.Flags().StringVar(&flags.gridsetting.supScs, "supScs", "", "subcarrierSpacing of the supplementary carrier, e.g. 15KHz for n80/n84 SUL, or empty to use the subcarrierSpacing of the carrier")
+	gridSettingCmd.Flags().StringVar(&flags.gridsetting._supScs, "_supScs", "30KHz", "subcarrierSpacing of SCS-SpecificCarrier of the supplementary carrier")
+	gridSettingCmd.Flags().IntVar(&flags.gridsetting._supCarrierNumRbs, "_supCarrierNumRbs", 106, "carrierBandwidth(N_RB) of SCS-SpecificCarrier of the supplementary carrier")
+	gridSettingCmd.Flags().IntVar(&flags.gridsetting.supOffsetToCarrier, "supOffsetToCarrier", 0, "offsetToCarrier of SCS-SpecificCarrier of the supplementary carrier[0..2199]")
+	gridSettingCmd.Flags().BoolVar(&flags.gridsetting.supUsed, "supUsed", false, "Whether RACH/PUCCH/PUSCH/SRS are transmitted on SUL carrier, or PDSCH scheduled by DCI 1_1 is received on SDL carrier")
+	gridSettingCmd.Flags().SortFlags = false
+	viper.BindPFlag("nrrg.gridsetting.supBand", gridSettingCmd.Flags().Lookup("supBand"))
+	viper.BindPFlag("nrrg.gridsetting._supDuplexMode", gridSettingCmd.Flags().Lookup("_supDuplexMode"))
+	viper.BindPFlag("nrrg.gridsetting.supArfcn", gridSettingCmd.Flags().Lookup("supArfcn"))
+	viper.BindPFlag("nrrg.gridsetting.supBw", gridSettingCmd.Flags().Lookup("supBw"))
+	viper.BindPFlag("nrrg.gridsetting.supScs", gridSettingCmd.Flags().Lookup("supScs"))
+	viper.BindPFlag("nrrg.gridsetting._supScs", gridSettingCmd.Flags().Lookup("_supScs"))
+	viper.BindPFlag("nrrg.gridsetting._supCarrierNumRbs", gridSettingCmd.Flags().Lookup("_supCarrierNumRbs"))
+	viper.BindPFlag("nrrg.gridsetting.supOffsetToCarrier", gridSettingCmd.Flags().Lookup("supOffsetToCarrier"))
+	viper.BindPFlag("nrrg.gridsetting.supUsed", gridSettingCmd.Flags().Lookup("supUsed"))
+	gridSettingCmd.Flags().MarkHidden("_supDuplexMode")
+	gridSettingCmd.Flags().MarkHidden("_supScs")
+	gridSettingCmd.Flags().MarkHidden("_supCarrierNumRbs")
+
+	// NR-U(operation with shared spectrum channel access)
+	gridSettingCmd.Flags().StringVar(&flags.gridsetting.ssbPositionQcl, "ssbPositionQcl", "n4", "ssb-PositionQCL-r16 of ServingCellConfigCommon for NR-U[n1,n2,n4,n8]")
+	gridSettingCmd.Flags().StringVar(&flags.gridsetting.dbWinLen, "dbWinLen", "ms5", "discoveryBurstWindowLength-r16 of ServingCellConfigCommon for NR-U[ms0dot5,ms1,ms2,ms3,ms4,ms5]")
+	gridSettingCmd.Flags().IntSliceVar(&flags.gridsetting._intraCellGuardBands, "_intraCellGuardBands", []int{}, "List of [startCRB, nrofCRBs] of intra-cell guard bands for NR-U")
+	gridSettingCmd.Flags().SortFlags = false
+	viper.BindPFlag("nrrg.gridsetting.ssbPositionQcl", gridSettingCmd.Flags().Lookup("ssbPositionQcl"))
+	viper.BindPFlag("nrrg.gridsetting.dbWinLen", gridSettingCmd.Flags().Lookup("dbWinLen"))
+	viper.BindPFlag("nrrg.gridsetting._intraCellGuardBands", gridSettingCmd.Flags().Lookup("_intraCellGuardBands"))
+	gridSettingCmd.Flags().MarkHidden("_intraCellGuardBands")
+
 	// PCI
 	gridSettingCmd.Flags().IntVar(&flags.gridsetting.pci, "pci", 0, "Physical cell identity[0..1007]")
 	gridSettingCmd.Flags().SortFlags = false
@@ -4755,6 +14828,16 @@ func initTddUlDlCmd() {
 	tddUlDlCmd.Flags().IntSliceVar(&flags.tdduldl.patNumDlSymbs, "patNumDlSymbs", []int{6}, "nrofDownlinkSymbols of TDD-UL-DL-ConfigCommon[0..13]")
 	tddUlDlCmd.Flags().IntSliceVar(&flags.tdduldl.patNumUlSymbs, "patNumUlSymbs", []int{4}, "nrofUplinkSymbols of TDD-UL-DL-ConfigCommon[0..13]")
 	tddUlDlCmd.Flags().IntSliceVar(&flags.tdduldl.patNumUlSlots, "patNumUlSlots", []int{2}, "nrofUplinkSlots of TDD-UL-DL-ConfigCommon[0..80]")
+	tddUlDlCmd.Flags().IntSliceVar(&flags.tdduldl.dedSlotIndex, "dedSlotIndex", []int{}, "slotIndex of each TDD-UL-DL-SlotConfig of TDD-UL-DL-ConfigDedicated[0..319]")
+	tddUlDlCmd.Flags().StringSliceVar(&flags.tdduldl.dedSymbols, "dedSymbols", []string{}, "symbols of each TDD-UL-DL-SlotConfig of TDD-UL-DL-ConfigDedicated[allDownlink,allUplink,explicit]")
+	tddUlDlCmd.Flags().IntSliceVar(&flags.tdduldl.dedNumDlSymbs, "dedNumDlSymbs", []int{}, "nrofDownlinkSymbols of explicit symbols of each TDD-UL-DL-SlotConfig[0..13]")
+	tddUlDlCmd.Flags().IntSliceVar(&flags.tdduldl.dedNumUlSymbs, "dedNumUlSymbs", []int{}, "nrofUplinkSymbols of explicit symbols of each TDD-UL-DL-SlotConfig[0..13]")
+	tddUlDlCmd.Flags().IntVar(&flags.tdduldl.sfiPeriod, "sfiPeriod", 0, "monitoringSlotPeriodicity of the search space for DCI 2_0 in slots, 0 means SFI is not configured[0,1,2,4,5,8,10,16,20]")
+	tddUlDlCmd.Flags().IntVar(&flags.tdduldl.sfiOffset, "sfiOffset", 0, "monitoringSlotOffset of the search space for DCI 2_0 in slots")
+	tddUlDlCmd.Flags().StringVar(&flags.tdduldl.sfcScs, "sfcScs", "", "subcarrierSpacing of SlotFormatCombinationsPerCell, empty means the same as the carrier[15KHz,30KHz,60KHz,120KHz]")
+	tddUlDlCmd.Flags().IntSliceVar(&flags.tdduldl.sfcId, "sfcId", []int{}, "slotFormatCombinationId of each SlotFormatCombination[0..511]")
+	tddUlDlCmd.Flags().StringSliceVar(&flags.tdduldl.sfcSlotFormats, "sfcSlotFormats", []string{}, "slotFormats of each SlotFormatCombination separated by '_', e.g. 0_0_28_1[0..55,255]")
+	tddUlDlCmd.Flags().IntSliceVar(&flags.tdduldl.sfiIndex, "sfiIndex", []int{}, "SFI-index of DCI 2_0 in each PDCCH monitoring occasion, which is repeated over monitoring occasions")
 	tddUlDlCmd.Flags().SortFlags = false
 	viper.BindPFlag("nrrg.tdduldl._refScs", tddUlDlCmd.Flags().Lookup("_refScs"))
 	viper.BindPFlag("nrrg.tdduldl.patPeriod", tddUlDlCmd.Flags().Lookup("patPeriod"))
@@ -4762,6 +14845,16 @@ func initTddUlDlCmd() {
 	viper.BindPFlag("nrrg.tdduldl.patNumDlSymbs", tddUlDlCmd.Flags().Lookup("patNumDlSymbs"))
 	viper.BindPFlag("nrrg.tdduldl.patNumUlSymbs", tddUlDlCmd.Flags().Lookup("patNumUlSymbs"))
 	viper.BindPFlag("nrrg.tdduldl.patNumUlSlots", tddUlDlCmd.Flags().Lookup("patNumUlSlots"))
+	viper.BindPFlag("nrrg.tdduldl.dedSlotIndex", tddUlDlCmd.Flags().Lookup("dedSlotIndex"))
+	viper.BindPFlag("nrrg.tdduldl.dedSymbols", tddUlDlCmd.Flags().Lookup("dedSymbols"))
+	viper.BindPFlag("nrrg.tdduldl.dedNumDlSymbs", tddUlDlCmd.Flags().Lookup("dedNumDlSymbs"))
+	viper.BindPFlag("nrrg.tdduldl.dedNumUlSymbs", tddUlDlCmd.Flags().Lookup("dedNumUlSymbs"))
+	viper.BindPFlag("nrrg.tdduldl.sfiPeriod", tddUlDlCmd.Flags().Lookup("sfiPeriod"))
+	viper.BindPFlag("nrrg.tdduldl.sfiOffset", tddUlDlCmd.Flags().Lookup("sfiOffset"))
+	viper.BindPFlag("nrrg.tdduldl.sfcScs", tddUlDlCmd.Flags().Lookup("sfcScs"))
+	viper.BindPFlag("nrrg.tdduldl.sfcId", tddUlDlCmd.Flags().Lookup("sfcId"))
+	viper.BindPFlag("nrrg.tdduldl.sfcSlotFormats", tddUlDlCmd.Flags().Lookup("sfcSlotFormats"))
+	viper.BindPFlag("nrrg.tdduldl.sfiIndex", tddUlDlCmd.Flags().Lookup("sfiIndex"))
 	tddUlDlCmd.Flags().MarkHidden("_refScs")
 }
 
@@ -4814,27 +14907,27 @@ func initSearchSpaceCmd() {
 }
 
 func initDlDciCmd() {
-	dlDciCmd.Flags().StringSliceVar(&flags.dldci._tag, "_tag", []string{"DCI_10_SIB1", "DCI_10_MSG2", "DCI_10_MSG4", "DCI_11_PDSCH"}, "DCI tag")
-	dlDciCmd.Flags().StringSliceVar(&flags.dldci._rnti, "_rnti", []string{"SI-RNTI", "RA-RNTI", "TC-RNTI", "C-RNTI"}, "RNTI for DCI 1_0/1_1")
-	dlDciCmd.Flags().IntSliceVar(&flags.dldci._muPdcch, "_muPdcch", []int{1, 1, 1, 1}, "Subcarrier spacing of PDCCH")
-	dlDciCmd.Flags().IntSliceVar(&flags.dldci._muPdsch, "_muPdsch", []int{1, 1, 1, 1}, "Subcarrier spacing of PDSCH")
-	dlDciCmd.Flags().IntSliceVar(&flags.dldci._indicatedBwp, "_indicatedBwp", []int{0, 0, 0, 1}, "Bandwidth part indicator field of DCI 1_1")
-	dlDciCmd.Flags().IntSliceVar(&flags.dldci.tdra, "tdra", []int{10, 10, 10, 10}, "Time-domain-resource-assignment field of DCI 1_0")
-	dlDciCmd.Flags().StringSliceVar(&flags.dldci._tdMappingType, "_tdMappingType", []string{"typeA", "typeA", "typeA", "typeA"}, "Mapping type for PDSCH time-domain allocation")
-	dlDciCmd.Flags().IntSliceVar(&flags.dldci._tdK0, "_tdK0", []int{0, 0, 0, 0}, "Slot offset K0 for PDSCH time-domain allocation")
-	dlDciCmd.Flags().IntSliceVar(&flags.dldci._tdSliv, "_tdSliv", []int{26, 26, 26, 26}, "SLIV for PDSCH time-domain allocation")
-	dlDciCmd.Flags().IntSliceVar(&flags.dldci._tdStartSymb, "_tdStartSymb", []int{12, 12, 12, 12}, "Starting symbol S for PDSCH time-domain allocation")
-	dlDciCmd.Flags().IntSliceVar(&flags.dldci._tdNumSymbs, "_tdNumSymbs", []int{2, 2, 2, 2}, "Number of OFDM symbols L for PDSCH time-domain allocation")
-	dlDciCmd.Flags().StringSliceVar(&flags.dldci._fdRaType, "_fdRaType", []string{"raType1", "raType1", "raType1", "raType1"}, "resourceAllocation for PDSCH frequency-domain allocation")
+	dlDciCmd.Flags().StringSliceVar(&flags.dldci._tag, "_tag", []string{"DCI_10_SIB1", "DCI_10_MSG2", "DCI_10_MSG4", "DCI_11_PDSCH", "DCI_10_MSGB", "DCI_10_PAGING", "DCI_10_OSI"}, "DCI tag")
+	dlDciCmd.Flags().StringSliceVar(&flags.dldci._rnti, "_rnti", []string{"SI-RNTI", "RA-RNTI", "TC-RNTI", "C-RNTI", "MSGB-RNTI", "P-RNTI", "SI-RNTI"}, "RNTI for DCI 1_0/1_1")
+	dlDciCmd.Flags().IntSliceVar(&flags.dldci._muPdcch, "_muPdcch", []int{1, 1, 1, 1, 1, 1, 1}, "Subcarrier spacing of PDCCH")
+	dlDciCmd.Flags().IntSliceVar(&flags.dldci._muPdsch, "_muPdsch", []int{1, 1, 1, 1, 1, 1, 1}, "Subcarrier spacing of PDSCH")
+	dlDciCmd.Flags().IntSliceVar(&flags.dldci._indicatedBwp, "_indicatedBwp", []int{0, 0, 0, 1, 0, 0, 0}, "Bandwidth part indicator field of DCI 1_1")
+	dlDciCmd.Flags().IntSliceVar(&flags.dldci.tdra, "tdra", []int{10, 10, 10, 10, 10, 10, 10}, "Time-domain-resource-assignment field of DCI 1_0")
+	dlDciCmd.Flags().StringSliceVar(&flags.dldci._tdMappingType, "_tdMappingType", []string{"typeA", "typeA", "typeA", "typeA", "typeA", "typeA", "typeA"}, "Mapping type for PDSCH time-domain allocation")
+	dlDciCmd.Flags().IntSliceVar(&flags.dldci._tdK0, "_tdK0", []int{0, 0, 0, 0, 0, 0, 0}, "Slot offset K0 for PDSCH time-domain allocation")
+	dlDciCmd.Flags().IntSliceVar(&flags.dldci._tdSliv, "_tdSliv", []int{26, 26, 26, 26, 26, 26, 26}, "SLIV for PDSCH time-domain allocation")
+	dlDciCmd.Flags().IntSliceVar(&flags.dldci._tdStartSymb, "_tdStartSymb", []int{12, 12, 12, 12, 12, 12, 12}, "Starting symbol S for PDSCH time-domain allocation")
+	dlDciCmd.Flags().IntSliceVar(&flags.dldci._tdNumSymbs, "_tdNumSymbs", []int{2, 2, 2, 2, 2, 2, 2}, "Number of OFDM symbols L for PDSCH time-domain allocation")
+	dlDciCmd.Flags().StringSliceVar(&flags.dldci._fdRaType, "_fdRaType", []string{"raType1", "raType1", "raType1", "raType1", "raType1", "raType1", "raType1"}, "resourceAllocation for PDSCH frequency-domain allocation")
 	dlDciCmd.Flags().IntVar(&flags.dldci._fdBitsRaType0, "_fdBitsRaType0", 11, "Bitwidth of PDSCH frequency-domain allocation for RA Type 1")
-	dlDciCmd.Flags().IntSliceVar(&flags.dldci._fdBitsRaType1, "_fdBitsRaType1", []int{11, 11, 11, 11}, "Bitwidth of PDSCH frequency-domain allocation for RA Type 1")
-	dlDciCmd.Flags().StringSliceVar(&flags.dldci._fdRa, "_fdRa", []string{"00001011111", "00001011111", "00001011111", ""}, "Frequency-domain-resource-assignment field of DCI 1_0/1_1")
-	dlDciCmd.Flags().IntSliceVar(&flags.dldci.fdStartRb, "fdStartRb", []int{0, 0, 0, 0}, "RB_start of RIV for PDSCH frequency-domain allocation")
-	dlDciCmd.Flags().IntSliceVar(&flags.dldci.fdNumRbs, "fdNumRbs", []int{48, 48, 48, 160}, "L_RBs of RIV for PDSCH frequency-domain allocation")
-	dlDciCmd.Flags().StringSliceVar(&flags.dldci.fdVrbPrbMappingType, "fdVrbPrbMappingType", []string{"interleaved", "interleaved", "interleaved", "interleaved"}, "VRB-to-PRB-mapping field of DCI 1_0/1_1")
-	dlDciCmd.Flags().StringSliceVar(&flags.dldci.fdBundleSize, "fdBundleSize", []string{"n2", "n2", "n2", "n2"}, "L(vrb-ToPRB-Interleaver) for PDSCH frequency-domain allocation")
-	dlDciCmd.Flags().IntSliceVar(&flags.dldci.mcsCw0, "mcsCw0", []int{2, 2, 2, 27}, "Modulation-and-coding-scheme field of DCI 1_0/1_1 for the 1st TB")
-	dlDciCmd.Flags().IntSliceVar(&flags.dldci._tbsCw0, "_tbsCw0", []int{408, 408, 408, 408}, "Transport block size(bits) for PDSCH CW0")
+	dlDciCmd.Flags().IntSliceVar(&flags.dldci._fdBitsRaType1, "_fdBitsRaType1", []int{11, 11, 11, 11, 11, 11, 11}, "Bitwidth of PDSCH frequency-domain allocation for RA Type 1")
+	dlDciCmd.Flags().StringSliceVar(&flags.dldci._fdRa, "_fdRa", []string{"00001011111", "00001011111", "00001011111", "", "00001011111", "00001011111", "00001011111"}, "Frequency-domain-resource-assignment field of DCI 1_0/1_1")
+	dlDciCmd.Flags().IntSliceVar(&flags.dldci.fdStartRb, "fdStartRb", []int{0, 0, 0, 0, 0, 0, 0}, "RB_start of RIV for PDSCH frequency-domain allocation")
+	dlDciCmd.Flags().IntSliceVar(&flags.dldci.fdNumRbs, "fdNumRbs", []int{48, 48, 48, 160, 48, 48, 48}, "L_RBs of RIV for PDSCH frequency-domain allocation")
+	dlDciCmd.Flags().StringSliceVar(&flags.dldci.fdVrbPrbMappingType, "fdVrbPrbMappingType", []string{"interleaved", "interleaved", "interleaved", "interleaved", "interleaved", "interleaved", "interleaved"}, "VRB-to-PRB-mapping field of DCI 1_0/1_1")
+	dlDciCmd.Flags().StringSliceVar(&flags.dldci.fdBundleSize, "fdBundleSize", []string{"n2", "n2", "n2", "n2", "n2", "n2", "n2"}, "L(vrb-ToPRB-Interleaver) for PDSCH frequency-domain allocation")
+	dlDciCmd.Flags().IntSliceVar(&flags.dldci.mcsCw0, "mcsCw0", []int{2, 2, 2, 27, 2, 2, 2}, "Modulation-and-coding-scheme field of DCI 1_0/1_1 for the 1st TB")
+	dlDciCmd.Flags().IntSliceVar(&flags.dldci._tbsCw0, "_tbsCw0", []int{408, 408, 408, 408, 408, 408, 408}, "Transport block size(bits) for PDSCH CW0")
 	dlDciCmd.Flags().IntVar(&flags.dldci.mcsCw1, "mcsCw1", -1, "Modulation-and-coding-scheme field of DCI 1_1 for the 2nd TB (-1 to disable the 2nd TB)")
 	dlDciCmd.Flags().IntVar(&flags.dldci._tbsCw1, "_tbsCw1", -1, "Transport block size(bits) for PDSCH CW1")
 	dlDciCmd.Flags().Float64Var(&flags.dldci.tbScalingFactor, "tbScalingFactor", 1, "TB scaling factor[0,0.5,0.25]")
@@ -4889,28 +14982,31 @@ func initDlDciCmd() {
 }
 
 func initUlDciCmd() {
-	ulDciCmd.Flags().StringSliceVar(&flags.uldci._tag, "_tag", []string{"RAR_MSG3", "DCI_01_PUSCH"}, "RNTI for DCI 0_1")
-	ulDciCmd.Flags().StringSliceVar(&flags.uldci._rnti, "_rnti", []string{"RA-RNTI", "C-RNTI"}, "RNTI for DCI 0_1")
-	ulDciCmd.Flags().IntSliceVar(&flags.uldci._muPdcch, "_muPdcch", []int{1, 1}, "Subcarrier spacing of PDCCH[0..3]")
-	ulDciCmd.Flags().IntSliceVar(&flags.uldci._muPusch, "_muPusch", []int{1, 1}, "Subcarrier spacing of PUSCH[0..3]")
-	ulDciCmd.Flags().IntSliceVar(&flags.uldci._indicatedBwp, "_indicatedBwp", []int{0, 1}, "Bandwidth-part-indicator field of DCI 0_1[0..1]")
-	ulDciCmd.Flags().IntSliceVar(&flags.uldci.tdra, "tdra", []int{7, 7}, "Time-domain-resource-assignment field of DCI 0_1[0..15]")
-	ulDciCmd.Flags().StringSliceVar(&flags.uldci._tdMappingType, "_tdMappingType", []string{"typeA", "typeA"}, "Mapping type for PUSCH time-domain allocation[typeA,typeB]")
-	ulDciCmd.Flags().IntSliceVar(&flags.uldci._tdK2, "_tdK2", []int{2, 2}, "Slot offset K2 for PUSCH time-domain allocation[0..32]")
+	ulDciCmd.Flags().StringSliceVar(&flags.uldci._tag, "_tag", []string{"RAR_MSG3", "DCI_01_PUSCH", "RA_MSGA", "FBRAR_MSG3"}, "RNTI for DCI 0_1")
+	ulDciCmd.Flags().StringSliceVar(&flags.uldci._rnti, "_rnti", []string{"RA-RNTI", "C-RNTI", "RA-RNTI", "TC-RNTI"}, "RNTI for DCI 0_1")
+	ulDciCmd.Flags().IntSliceVar(&flags.uldci._muPdcch, "_muPdcch", []int{1, 1, 1, 1}, "Subcarrier spacing of PDCCH[0..3]")
+	ulDciCmd.Flags().IntSliceVar(&flags.uldci._muPusch, "_muPusch", []int{1, 1, 1, 1}, "Subcarrier spacing of PUSCH[0..3]")
+	ulDciCmd.Flags().IntSliceVar(&flags.uldci._indicatedBwp, "_indicatedBwp", []int{0, 1, 0, 0}, "Bandwidth-part-indicator field of DCI 0_1[0..1]")
+	ulDciCmd.Flags().IntSliceVar(&flags.uldci.tdra, "tdra", []int{7, 7, -1, 7}, "Time-domain-resource-assignment field of DCI 0_1[0..15]")
+	ulDciCmd.Flags().StringSliceVar(&flags.uldci._tdMappingType, "_tdMappingType", []string{"typeA", "typeA", "typeA", "typeA"}, "Mapping type for PUSCH time-domain allocation[typeA,typeB]")
+	ulDciCmd.Flags().IntSliceVar(&flags.uldci._tdK2, "_tdK2", []int{2, 2, 1, 2}, "Slot offset K2 for PUSCH time-domain allocation[0..32]")
 	ulDciCmd.Flags().IntVar(&flags.uldci._tdDelta, "_tdDelta", 2, "The delta for Msg3 PUSCH scheduled by RAR UL grant(38.214 8.3)")
-	ulDciCmd.Flags().IntSliceVar(&flags.uldci._tdSliv, "_tdSliv", []int{27, 27}, "SLIV for PUSCH time-domain allocation")
-	ulDciCmd.Flags().IntSliceVar(&flags.uldci._tdStartSymb, "_tdStartSymb", []int{0, 0}, "Starting symbol S for PUSCH time-domain allocation")
-	ulDciCmd.Flags().IntSliceVar(&flags.uldci._tdNumSymbs, "_tdNumSymbs", []int{14, 14}, "Number of OFDM symbols L for PUSCH time-domain allocation")
-	ulDciCmd.Flags().StringSliceVar(&flags.uldci._fdRaType, "_fdRaType", []string{"raType1", "raType1"}, "resourceAllocation for PUSCH frequency-domain allocation[raType0,raType1]")
-	ulDciCmd.Flags().StringSliceVar(&flags.uldci.fdFreqHop, "fdFreqHop", []string{"intra-slot", "disabled"}, "Frequency-hopping-flag field for DCI 0_1[disabled,intraSlot,interSlot]")
-	ulDciCmd.Flags().IntSliceVar(&flags.uldci._fdFreqHopOffset, "_fdFreqHopOffset", []int{0, 0}, "frequencyHoppingOffsetLists of PUSCH-Config[0..274]")
+	ulDciCmd.Flags().IntSliceVar(&flags.uldci._tdSliv, "_tdSliv", []int{27, 27, 27, 27}, "SLIV for PUSCH time-domain allocation")
+	ulDciCmd.Flags().IntSliceVar(&flags.uldci._tdStartSymb, "_tdStartSymb", []int{0, 0, 0, 0}, "Starting symbol S for PUSCH time-domain allocation")
+	ulDciCmd.Flags().IntSliceVar(&flags.uldci._tdNumSymbs, "_tdNumSymbs", []int{14, 14, 14, 14}, "Number of OFDM symbols L for PUSCH time-domain allocation")
+	ulDciCmd.Flags().StringSliceVar(&flags.uldci._fdRaType, "_fdRaType", []string{"raType1", "raType1", "raType1", "raType1"}, "resourceAllocation for PUSCH frequency-domain allocation[raType0,raType1,raType2]")
+	ulDciCmd.Flags().StringSliceVar(&flags.uldci.fdFreqHop, "fdFreqHop", []string{"intra-slot", "disabled", "disabled", "intra-slot"}, "Frequency-hopping-flag field for DCI 0_1[disabled,intraSlot,interSlot]")
+	ulDciCmd.Flags().IntSliceVar(&flags.uldci._fdFreqHopOffset, "_fdFreqHopOffset", []int{0, 0, 0, 0}, "frequencyHoppingOffsetLists of PUSCH-Config[0..274]")
 	ulDciCmd.Flags().IntVar(&flags.uldci._fdBitsRaType0, "_fdBitsRaType0", 11, "Bitwidth of PUSCH frequency-domain allocation for RA Type 1")
-	ulDciCmd.Flags().IntSliceVar(&flags.uldci._fdBitsRaType1, "_fdBitsRaType1", []int{14, 11}, "Bitwidth of PUSCH frequency-domain allocation for RA Type 1")
-	ulDciCmd.Flags().StringSliceVar(&flags.uldci._fdRa, "_fdRa", []string{"", "0000001000100001"}, "Frequency-domain-resource-assignment field of DCI 0_1")
-	ulDciCmd.Flags().IntSliceVar(&flags.uldci.fdStartRb, "fdStartRb", []int{0, 0}, "RB_start of RIV for PUSCH frequency-domain allocation")
-	ulDciCmd.Flags().IntSliceVar(&flags.uldci.fdNumRbs, "fdNumRbs", []int{3, 160}, "L_RBs of RIV for PUSCH frequency-domain allocation")
-	ulDciCmd.Flags().IntSliceVar(&flags.uldci.mcsCw0, "mcsCw0", []int{2, 28}, "Modulation-and-coding-scheme-cw0 field of DCI 0_1[0..28]")
-	ulDciCmd.Flags().IntSliceVar(&flags.uldci._tbs, "_tbs", []int{3624, 475584}, "Transport block size(bits) for PUSCH")
+	ulDciCmd.Flags().IntSliceVar(&flags.uldci._fdBitsRaType1, "_fdBitsRaType1", []int{14, 11, -1, 14}, "Bitwidth of PUSCH frequency-domain allocation for RA Type 1")
+	ulDciCmd.Flags().StringSliceVar(&flags.uldci._fdRa, "_fdRa", []string{"", "0000001000100001", "", ""}, "Frequency-domain-resource-assignment field of DCI 0_1")
+	ulDciCmd.Flags().IntSliceVar(&flags.uldci.fdStartRb, "fdStartRb", []int{0, 0, 0, 0}, "RB_start of RIV for PUSCH frequency-domain allocation")
+	ulDciCmd.Flags().IntSliceVar(&flags.uldci.fdNumRbs, "fdNumRbs", []int{3, 160, 4, 3}, "L_RBs of RIV for PUSCH frequency-domain allocation")
+	ulDciCmd.Flags().IntSliceVar(&flags.uldci.fdInterlaces, "fdInterlaces", []int{0}, "Interlace indexes of PUSCH scheduled by DCI 0_1 when useInterlace is configured[0..9(15KHz) or 0..4(30KHz)]")
+	ulDciCmd.Flags().IntVar(&flags.uldci.fdStartRbSet, "fdStartRbSet", 0, "Starting RB set of PUSCH scheduled by DCI 0_1 when useInterlace is configured")
+	ulDciCmd.Flags().IntVar(&flags.uldci.fdNumRbSets, "fdNumRbSets", 1, "Number of contiguous RB sets of PUSCH scheduled by DCI 0_1 when useInterlace is configured")
+	ulDciCmd.Flags().IntSliceVar(&flags.uldci.mcsCw0, "mcsCw0", []int{2, 28, 2, 2}, "Modulation-and-coding-scheme-cw0 field of DCI 0_1[0..28]")
+	ulDciCmd.Flags().IntSliceVar(&flags.uldci._tbs, "_tbs", []int{3624, 475584, -1, 3624}, "Transport block size(bits) for PUSCH")
 	ulDciCmd.Flags().IntVar(&flags.uldci.precodingInfoNumLayers, "precodingInfoNumLayers", 2, "Precoding-information-and-number-of-layers field of DCI 0_1[0..63]")
 	ulDciCmd.Flags().IntVar(&flags.uldci.srsResIndicator, "srsResIndicator", 0, "SRS-resource-indicator field of DCI 0_1")
 	ulDciCmd.Flags().IntVar(&flags.uldci.antennaPorts, "antennaPorts", 0, "Antenna_port(s) field of DCI 0_1[0..7]")
@@ -4936,6 +15032,9 @@ func initUlDciCmd() {
 	viper.BindPFlag("nrrg.uldci._fdRa", ulDciCmd.Flags().Lookup("_fdRa"))
 	viper.BindPFlag("nrrg.uldci.fdStartRb", ulDciCmd.Flags().Lookup("fdStartRb"))
 	viper.BindPFlag("nrrg.uldci.fdNumRbs", ulDciCmd.Flags().Lookup("fdNumRbs"))
+	viper.BindPFlag("nrrg.uldci.fdInterlaces", ulDciCmd.Flags().Lookup("fdInterlaces"))
+	viper.BindPFlag("nrrg.uldci.fdStartRbSet", ulDciCmd.Flags().Lookup("fdStartRbSet"))
+	viper.BindPFlag("nrrg.uldci.fdNumRbSets", ulDciCmd.Flags().Lookup("fdNumRbSets"))
 	viper.BindPFlag("nrrg.uldci.mcsCw0", ulDciCmd.Flags().Lookup("mcsCw0"))
 	viper.BindPFlag("nrrg.uldci._tbs", ulDciCmd.Flags().Lookup("_tbs"))
 	viper.BindPFlag("nrrg.uldci.precodingInfoNumLayers", ulDciCmd.Flags().Lookup("precodingInfoNumLayers"))
@@ -4984,6 +15083,56 @@ func initBwpCmd() {
 	bwpCmd.Flags().MarkHidden("_bwpLocAndBw")
 	bwpCmd.Flags().MarkHidden("_bwpStartRb")
 	bwpCmd.Flags().MarkHidden("_bwpNumRbs")
+
+	// BWPs of the supplementary carrier
+	bwpCmd.Flags().IntSliceVar(&flags.bwp._supBwpStartRb, "_supBwpStartRb", []int{0, 0}, "RB_start of initial and dedicated BWP of the supplementary carrier")
+	bwpCmd.Flags().IntSliceVar(&flags.bwp._supBwpNumRbs, "_supBwpNumRbs", []int{106, 106}, "L_RBs of initial and dedicated BWP of the supplementary carrier")
+	viper.BindPFlag("nrrg.bwp._supBwpStartRb", bwpCmd.Flags().Lookup("_supBwpStartRb"))
+	viper.BindPFlag("nrrg.bwp._supBwpNumRbs", bwpCmd.Flags().Lookup("_supBwpNumRbs"))
+	bwpCmd.Flags().MarkHidden("_supBwpStartRb")
+	bwpCmd.Flags().MarkHidden("_supBwpNumRbs")
+
+	// interlaced PUCCH/PUSCH for NR-U
+	bwpCmd.Flags().BoolVar(&flags.bwp.useInterlace, "useInterlace", false, "useInterlacePUCCH-PUSCH-r16 of BWP-UplinkDedicated for NR-U")
+	viper.BindPFlag("nrrg.bwp.useInterlace", bwpCmd.Flags().Lookup("useInterlace"))
+
+	// additional dedicated BWPs and BWP switching
+	bwpCmd.Flags().IntSliceVar(&flags.bwp.dlBwpId, "dlBwpId", []int{}, "bwp-Id of additional dedicated DL BWPs[2..4]")
+	bwpCmd.Flags().StringSliceVar(&flags.bwp.dlBwpScs, "dlBwpScs", []string{}, "subcarrierSpacing of additional dedicated DL BWPs[15KHz,30KHz,60KHz,120KHz]")
+	bwpCmd.Flags().StringSliceVar(&flags.bwp.dlBwpCp, "dlBwpCp", []string{}, "cyclicPrefix of additional dedicated DL BWPs[normal,extended]")
+	bwpCmd.Flags().IntSliceVar(&flags.bwp.dlBwpStartRb, "dlBwpStartRb", []int{}, "RB_start of additional dedicated DL BWPs")
+	bwpCmd.Flags().IntSliceVar(&flags.bwp.dlBwpNumRbs, "dlBwpNumRbs", []int{}, "L_RBs of additional dedicated DL BWPs")
+	bwpCmd.Flags().IntSliceVar(&flags.bwp.ulBwpId, "ulBwpId", []int{}, "bwp-Id of additional dedicated UL BWPs[2..4]")
+	bwpCmd.Flags().StringSliceVar(&flags.bwp.ulBwpScs, "ulBwpScs", []string{}, "subcarrierSpacing of additional dedicated UL BWPs[15KHz,30KHz,60KHz,120KHz]")
+	bwpCmd.Flags().StringSliceVar(&flags.bwp.ulBwpCp, "ulBwpCp", []string{}, "cyclicPrefix of additional dedicated UL BWPs[normal,extended]")
+	bwpCmd.Flags().IntSliceVar(&flags.bwp.ulBwpStartRb, "ulBwpStartRb", []int{}, "RB_start of additional dedicated UL BWPs")
+	bwpCmd.Flags().IntSliceVar(&flags.bwp.ulBwpNumRbs, "ulBwpNumRbs", []int{}, "L_RBs of additional dedicated UL BWPs")
+	bwpCmd.Flags().IntVar(&flags.bwp.defaultDlBwpId, "defaultDlBwpId", 1, "defaultDownlinkBWP-Id of ServingCellConfig[1..4]")
+	bwpCmd.Flags().StringVar(&flags.bwp.bwpInactivityTimer, "bwpInactivityTimer", "", "bwp-InactivityTimer of ServingCellConfig[ms2,ms3,ms4,ms5,ms6,ms8,ms10,ms20,ms30,ms40,ms50,ms60,ms80,ms100,ms200,ms300,ms500,ms750,ms1280,ms1920,ms2560]")
+	bwpCmd.Flags().StringVar(&flags.bwp.bwpSwDelayType, "bwpSwDelayType", "type1", "bwp-SwitchingDelay of UE capability[type1,type2]")
+	bwpCmd.Flags().StringSliceVar(&flags.bwp.bwpSwTrigger, "bwpSwTrigger", []string{}, "Trigger of each BWP switching[dci,rrc]")
+	bwpCmd.Flags().StringSliceVar(&flags.bwp.bwpSwDir, "bwpSwDir", []string{}, "Link direction of each BWP switching[DL,UL]")
+	bwpCmd.Flags().IntSliceVar(&flags.bwp.bwpSwSfn, "bwpSwSfn", []int{}, "SFN of each BWP switching")
+	bwpCmd.Flags().IntSliceVar(&flags.bwp.bwpSwSlot, "bwpSwSlot", []int{}, "Slot of each BWP switching")
+	bwpCmd.Flags().IntSliceVar(&flags.bwp.bwpSwBwpId, "bwpSwBwpId", []int{}, "bwp-Id of the new active BWP of each BWP switching[1..4]")
+	viper.BindPFlag("nrrg.bwp.dlBwpId", bwpCmd.Flags().Lookup("dlBwpId"))
+	viper.BindPFlag("nrrg.bwp.dlBwpScs", bwpCmd.Flags().Lookup("dlBwpScs"))
+	viper.BindPFlag("nrrg.bwp.dlBwpCp", bwpCmd.Flags().Lookup("dlBwpCp"))
+	viper.BindPFlag("nrrg.bwp.dlBwpStartRb", bwpCmd.Flags().Lookup("dlBwpStartRb"))
+	viper.BindPFlag("nrrg.bwp.dlBwpNumRbs", bwpCmd.Flags().Lookup("dlBwpNumRbs"))
+	viper.BindPFlag("nrrg.bwp.ulBwpId", bwpCmd.Flags().Lookup("ulBwpId"))
+	viper.BindPFlag("nrrg.bwp.ulBwpScs", bwpCmd.Flags().Lookup("ulBwpScs"))
+	viper.BindPFlag("nrrg.bwp.ulBwpCp", bwpCmd.Flags().Lookup("ulBwpCp"))
+	viper.BindPFlag("nrrg.bwp.ulBwpStartRb", bwpCmd.Flags().Lookup("ulBwpStartRb"))
+	viper.BindPFlag("nrrg.bwp.ulBwpNumRbs", bwpCmd.Flags().Lookup("ulBwpNumRbs"))
+	viper.BindPFlag("nrrg.bwp.defaultDlBwpId", bwpCmd.Flags().Lookup("defaultDlBwpId"))
+	viper.BindPFlag("nrrg.bwp.bwpInactivityTimer", bwpCmd.Flags().Lookup("bwpInactivityTimer"))
+	viper.BindPFlag("nrrg.bwp.bwpSwDelayType", bwpCmd.Flags().Lookup("bwpSwDelayType"))
+	viper.BindPFlag("nrrg.bwp.bwpSwTrigger", bwpCmd.Flags().Lookup("bwpSwTrigger"))
+	viper.BindPFlag("nrrg.bwp.bwpSwDir", bwpCmd.Flags().Lookup("bwpSwDir"))
+	viper.BindPFlag("nrrg.bwp.bwpSwSfn", bwpCmd.Flags().Lookup("bwpSwSfn"))
+	viper.BindPFlag("nrrg.bwp.bwpSwSlot", bwpCmd.Flags().Lookup("bwpSwSlot"))
+	viper.BindPFlag("nrrg.bwp.bwpSwBwpId", bwpCmd.Flags().Lookup("bwpSwBwpId"))
 }
 
 func initRachCmd() {
@@ -5008,6 +15157,20 @@ func initRachCmd() {
 	rachCmd.Flags().IntVar(&flags.rach._raLen, "_raLen", 139, "L_RA of 3GPP TS 38.211 Table 6.3.3.1-1 and Table 6.3.3.1-2")
 	rachCmd.Flags().IntVar(&flags.rach._raNumRbs, "_raNumRbs", 12, "Allocation-expressed-in-number-of-RBs-for-PUSCH of 3GPP TS 38.211 Table 6.3.3.2-1")
 	rachCmd.Flags().IntVar(&flags.rach._raKBar, "_raKBar", 2, "k_bar of 3GPP TS 38.211 Table 6.3.3.2-1")
+	rachCmd.Flags().StringVar(&flags.rach.raType, "raType", "4-step", "Type of random access procedure[4-step,2-step]")
+	rachCmd.Flags().IntVar(&flags.rach.msgAPuschTimeOffset, "msgAPuschTimeOffset", 1, "msgA-PUSCH-TimeDomainOffset-r16 of MsgA-PUSCH-Resource-r16[1..32]")
+	rachCmd.Flags().StringVar(&flags.rach.msgAMappingType, "msgAMappingType", "typeA", "mappingTypeMsgA-PUSCH-r16 of MsgA-PUSCH-Resource-r16[typeA,typeB]")
+	rachCmd.Flags().IntVar(&flags.rach.msgAStartSymbAndLength, "msgAStartSymbAndLength", 27, "startSymbolAndLengthMsgA-PO-r16 of MsgA-PUSCH-Resource-r16[0..127]")
+	rachCmd.Flags().IntVar(&flags.rach.msgANumSlots, "msgANumSlots", 1, "nrofSlotsMsgA-PUSCH-r16 of MsgA-PUSCH-Resource-r16[1..4]")
+	rachCmd.Flags().IntVar(&flags.rach.msgANumPosPerSlot, "msgANumPosPerSlot", 1, "nrofMsgA-PO-PerSlot-r16 of MsgA-PUSCH-Resource-r16[1,2,3,6]")
+	rachCmd.Flags().IntVar(&flags.rach.msgAGuardPeriod, "msgAGuardPeriod", 0, "guardPeriodMsgA-PUSCH-r16 of MsgA-PUSCH-Resource-r16[0..3]")
+	rachCmd.Flags().IntVar(&flags.rach.msgANumPosFdm, "msgANumPosFdm", 1, "nrofMsgA-PO-FDM-r16 of MsgA-PUSCH-Resource-r16[1,2,4,8]")
+	rachCmd.Flags().IntVar(&flags.rach.msgAFreqStart, "msgAFreqStart", 12, "frequencyStartMsgA-PUSCH-r16 of MsgA-PUSCH-Resource-r16[0..274]")
+	rachCmd.Flags().IntVar(&flags.rach.msgANumRbs, "msgANumRbs", 4, "nrofPRBs-PerMsgA-PO-r16 of MsgA-PUSCH-Resource-r16[1..32]")
+	rachCmd.Flags().IntVar(&flags.rach.msgAGuardBand, "msgAGuardBand", 0, "guardBandMsgA-PUSCH-r16 of MsgA-PUSCH-Resource-r16[0..1]")
+	rachCmd.Flags().StringVar(&flags.rach.msgADmrsAddPos, "msgADmrsAddPos", "pos1", "msgA-DMRS-AdditionalPosition-r16 of MsgA-DMRS-Config-r16[pos0,pos1,pos2,pos3]")
+	rachCmd.Flags().IntVar(&flags.rach.msgAMcs, "msgAMcs", 2, "msgA-MCS-r16 of MsgA-PUSCH-Resource-r16[0..15]")
+	rachCmd.Flags().StringVar(&flags.rach.msgBRespWin, "msgBRespWin", "sl40", "msgB-ResponseWindow-r16 of RACH-ConfigCommonTwoStepRA-r16[sl1,sl2,sl4,sl8,sl10,sl20,sl40,sl80,sl160,sl320]")
 	rachCmd.Flags().SortFlags = false
 	viper.BindPFlag("nrrg.rach.prachConfId", rachCmd.Flags().Lookup("prachConfId"))
 	viper.BindPFlag("nrrg.rach._raFormat", rachCmd.Flags().Lookup("_raFormat"))
@@ -5030,6 +15193,20 @@ func initRachCmd() {
 	viper.BindPFlag("nrrg.rach._raLen", rachCmd.Flags().Lookup("_raLen"))
 	viper.BindPFlag("nrrg.rach._raNumRbs", rachCmd.Flags().Lookup("_raNumRbs"))
 	viper.BindPFlag("nrrg.rach._raKBar", rachCmd.Flags().Lookup("_raKBar"))
+	viper.BindPFlag("nrrg.rach.raType", rachCmd.Flags().Lookup("raType"))
+	viper.BindPFlag("nrrg.rach.msgAPuschTimeOffset", rachCmd.Flags().Lookup("msgAPuschTimeOffset"))
+	viper.BindPFlag("nrrg.rach.msgAMappingType", rachCmd.Flags().Lookup("msgAMappingType"))
+	viper.BindPFlag("nrrg.rach.msgAStartSymbAndLength", rachCmd.Flags().Lookup("msgAStartSymbAndLength"))
+	viper.BindPFlag("nrrg.rach.msgANumSlots", rachCmd.Flags().Lookup("msgANumSlots"))
+	viper.BindPFlag("nrrg.rach.msgANumPosPerSlot", rachCmd.Flags().Lookup("msgANumPosPerSlot"))
+	viper.BindPFlag("nrrg.rach.msgAGuardPeriod", rachCmd.Flags().Lookup("msgAGuardPeriod"))
+	viper.BindPFlag("nrrg.rach.msgANumPosFdm", rachCmd.Flags().Lookup("msgANumPosFdm"))
+	viper.BindPFlag("nrrg.rach.msgAFreqStart", rachCmd.Flags().Lookup("msgAFreqStart"))
+	viper.BindPFlag("nrrg.rach.msgANumRbs", rachCmd.Flags().Lookup("msgANumRbs"))
+	viper.BindPFlag("nrrg.rach.msgAGuardBand", rachCmd.Flags().Lookup("msgAGuardBand"))
+	viper.BindPFlag("nrrg.rach.msgADmrsAddPos", rachCmd.Flags().Lookup("msgADmrsAddPos"))
+	viper.BindPFlag("nrrg.rach.msgAMcs", rachCmd.Flags().Lookup("msgAMcs"))
+	viper.BindPFlag("nrrg.rach.msgBRespWin", rachCmd.Flags().Lookup("msgBRespWin"))
 	rachCmd.Flags().MarkHidden("_raFormat")
 	rachCmd.Flags().MarkHidden("_raX")
 	rachCmd.Flags().MarkHidden("_raY")
@@ -5045,13 +15222,13 @@ func initRachCmd() {
 }
 
 func initDmrsCommonCmd() {
-	dmrsCommonCmd.Flags().StringSliceVar(&flags.dmrsCommon._tag, "_tag", []string{"SIB1", "Msg2", "Msg4", "Msg3"}, "Information of UL/DL-SCH")
-	dmrsCommonCmd.Flags().StringSliceVar(&flags.dmrsCommon._dmrsType, "_dmrsType", []string{"type1", "type1", "type1", "type1"}, "dmrs-Type as in DMRS-UplinkConfig/DMRS-DownlinkConfig")
-	dmrsCommonCmd.Flags().StringSliceVar(&flags.dmrsCommon._dmrsAddPos, "_dmrsAddPos", []string{"pos0", "pos0", "pos0", "pos1"}, "dmrs-AdditionalPosition as in DMRS-UplinkConfig/DMRS-DownlinkConfig")
-	dmrsCommonCmd.Flags().StringSliceVar(&flags.dmrsCommon._maxLength, "_maxLength", []string{"len1", "len1", "len1", "len1"}, "maxLength as in DMRS-UplinkConfig/DMRS-DownlinkConfig")
-	dmrsCommonCmd.Flags().IntSliceVar(&flags.dmrsCommon._dmrsPorts, "_dmrsPorts", []int{1000, 1000, 1000, 0}, "DMRS antenna ports")
-	dmrsCommonCmd.Flags().IntSliceVar(&flags.dmrsCommon._cdmGroupsWoData, "_cdmGroupsWoData", []int{1, 1, 1, 2}, "CDM group(s) without data")
-	dmrsCommonCmd.Flags().IntSliceVar(&flags.dmrsCommon._numFrontLoadSymbs, "_numFrontLoadSymbs", []int{1, 1, 1, 1}, "Number of front-load DMRS symbols")
+	dmrsCommonCmd.Flags().StringSliceVar(&flags.dmrsCommon._tag, "_tag", []string{"SIB1", "Msg2", "Msg4", "Msg3", "MsgB", "Paging", "OSI"}, "Information of UL/DL-SCH")
+	dmrsCommonCmd.Flags().StringSliceVar(&flags.dmrsCommon._dmrsType, "_dmrsType", []string{"type1", "type1", "type1", "type1", "type1", "type1", "type1"}, "dmrs-Type as in DMRS-UplinkConfig/DMRS-DownlinkConfig")
+	dmrsCommonCmd.Flags().StringSliceVar(&flags.dmrsCommon._dmrsAddPos, "_dmrsAddPos", []string{"pos0", "pos0", "pos0", "pos1", "pos0", "pos0", "pos0"}, "dmrs-AdditionalPosition as in DMRS-UplinkConfig/DMRS-DownlinkConfig")
+	dmrsCommonCmd.Flags().StringSliceVar(&flags.dmrsCommon._maxLength, "_maxLength", []string{"len1", "len1", "len1", "len1", "len1", "len1", "len1"}, "maxLength as in DMRS-UplinkConfig/DMRS-DownlinkConfig")
+	dmrsCommonCmd.Flags().IntSliceVar(&flags.dmrsCommon._dmrsPorts, "_dmrsPorts", []int{1000, 1000, 1000, 0, 1000, 1000, 1000}, "DMRS antenna ports")
+	dmrsCommonCmd.Flags().IntSliceVar(&flags.dmrsCommon._cdmGroupsWoData, "_cdmGroupsWoData", []int{1, 1, 1, 2, 1, 1, 1}, "CDM group(s) without data")
+	dmrsCommonCmd.Flags().IntSliceVar(&flags.dmrsCommon._numFrontLoadSymbs, "_numFrontLoadSymbs", []int{1, 1, 1, 1, 1, 1, 1}, "Number of front-load DMRS symbols")
 	dmrsCommonCmd.Flags().SortFlags = false
 	viper.BindPFlag("nrrg.dmrscommon._tag", dmrsCommonCmd.Flags().Lookup("_tag"))
 	viper.BindPFlag("nrrg.dmrscommon._dmrsType", dmrsCommonCmd.Flags().Lookup("_dmrsType"))
@@ -5124,6 +15301,9 @@ func initPuschCmd() {
 	puschCmd.Flags().StringVar(&flags.pusch.puschMcsTable, "puschMcsTable", "qam64", "mcs-Table of PUSCH-Config[qam64,qam256,qam64LowSE]")
 	puschCmd.Flags().StringVar(&flags.pusch.puschXOh, "puschXOh", "xOh0", "xOverhead of PUSCH-ServingCellConfig[xOh0,xOh6,xOh12,xOh18]")
 	puschCmd.Flags().StringVar(&flags.pusch._puschRepType, "_puschRepType", "typeA", "pusch-RepTypeIndicator of PUSCH-Config[typeA,typeB]")
+	puschCmd.Flags().IntVar(&flags.pusch._puschAllocStartSymb, "_puschAllocStartSymb", 0, "startSymbol-r16 of PUSCH-Allocation-r16 for PUSCH repetition Type B[0..13]")
+	puschCmd.Flags().IntVar(&flags.pusch._puschAllocLength, "_puschAllocLength", 14, "length-r16 of PUSCH-Allocation-r16 for PUSCH repetition Type B[1..14]")
+	puschCmd.Flags().StringVar(&flags.pusch._puschAllocNumReps, "_puschAllocNumReps", "n1", "numberOfRepetitions-r16 of PUSCH-Allocation-r16 for PUSCH repetition Type B[n1,n2,n3,n4,n7,n8,n12,n16]")
 	puschCmd.Flags().StringVar(&flags.pusch.puschDmrsType, "puschDmrsType", "type1", "dmrs-Type as in DMRS-UplinkConfig[type1,type2]")
 	puschCmd.Flags().StringVar(&flags.pusch.puschDmrsAddPos, "puschDmrsAddPos", "pos0", "dmrs-additionalPosition as in DMRS-UplinkConfig[pos0,pos1,pos2,pos3]")
 	puschCmd.Flags().StringVar(&flags.pusch.puschMaxLength, "puschMaxLength", "len1", "maxLength as in DMRS-UplinkConfig[len1,len2]")
@@ -5152,6 +15332,9 @@ func initPuschCmd() {
 	viper.BindPFlag("nrrg.pusch.puschMcsTable", puschCmd.Flags().Lookup("puschMcsTable"))
 	viper.BindPFlag("nrrg.pusch.puschXOh", puschCmd.Flags().Lookup("puschXOh"))
 	viper.BindPFlag("nrrg.pusch._puschRepType", puschCmd.Flags().Lookup("_puschRepType"))
+	viper.BindPFlag("nrrg.pusch._puschAllocStartSymb", puschCmd.Flags().Lookup("_puschAllocStartSymb"))
+	viper.BindPFlag("nrrg.pusch._puschAllocLength", puschCmd.Flags().Lookup("_puschAllocLength"))
+	viper.BindPFlag("nrrg.pusch._puschAllocNumReps", puschCmd.Flags().Lookup("_puschAllocNumReps"))
 	viper.BindPFlag("nrrg.pusch.puschDmrsType", puschCmd.Flags().Lookup("puschDmrsType"))
 	viper.BindPFlag("nrrg.pusch.puschDmrsAddPos", puschCmd.Flags().Lookup("puschDmrsAddPos"))
 	viper.BindPFlag("nrrg.pusch.puschMaxLength", puschCmd.Flags().Lookup("puschMaxLength"))
@@ -5172,6 +15355,9 @@ func initPuschCmd() {
 	puschCmd.Flags().MarkHidden("_puschAggFactor")
 	puschCmd.Flags().MarkHidden("_rbgSize")
 	puschCmd.Flags().MarkHidden("_puschRepType")
+	puschCmd.Flags().MarkHidden("_puschAllocStartSymb")
+	puschCmd.Flags().MarkHidden("_puschAllocLength")
+	puschCmd.Flags().MarkHidden("_puschAllocNumReps")
 	puschCmd.Flags().MarkHidden("_dmrsPorts")
 	puschCmd.Flags().MarkHidden("_cdmGroupsWoData")
 	puschCmd.Flags().MarkHidden("_numFrontLoadSymbs")
@@ -5319,6 +15505,7 @@ func initSrsCmd() {
 }
 
 func initPucchCmd() {
+	pucchCmd.Flags().IntVar(&flags.pucch.pucchResCommon, "pucchResCommon", 11, "pucch-ResourceCommon of PUCCH-ConfigCommon[0..15]")
 	pucchCmd.Flags().StringVar(&flags.pucch._numSlots, "_numSlots", "n1", "nrofSlots of PUCCH-FormatConfig for PUCCH format 1/3/4[n1,n2,n4,n8]")
 	pucchCmd.Flags().StringVar(&flags.pucch._interSlotFreqHop, "_interSlotFreqHop", "disabled", "interslotFrequencyHopping of PUCCH-FormatConfig for PUCCH format 1/3/4[disabled,enabled]")
 	pucchCmd.Flags().BoolVar(&flags.pucch._addDmrs, "_addDmrs", true, "additionalDMRS of PUCCH-FormatConfig for PUCCH format 3/4")
@@ -5332,11 +15519,14 @@ func initPucchCmd() {
 	pucchCmd.Flags().IntSliceVar(&flags.pucch._pucchNumRbs, "_pucchNumRbs", []int{1, 1, 1}, "nrofPRBs of PUCCH-Resource, fixed to 1 for PUCCH format 0/1/4[1..16]")
 	pucchCmd.Flags().IntSliceVar(&flags.pucch._pucchStartSymb, "_pucchStartSymb", []int{0, 0, 0}, "startingSymbolIndex of PUCCH-Resource[0..13(format 0/2) or 0..10(format 1/3/4)]")
 	pucchCmd.Flags().IntSliceVar(&flags.pucch._pucchNumSymbs, "_pucchNumSymbs", []int{14, 14, 14}, "nrofSymbols of PUCCH-Resource[1..2(format 0/2) or 4..14(format 1/3/4)]")
+	pucchCmd.Flags().IntSliceVar(&flags.pucch._pucchInterlace, "_pucchInterlace", []int{0, 1, 2}, "interlace0-r16 of PUCCH-Resource when useInterlace is configured[0..9(15KHz) or 0..4(30KHz)]")
+	pucchCmd.Flags().IntSliceVar(&flags.pucch._pucchRbSet, "_pucchRbSet", []int{0, 0, 0}, "rb-SetIndex-r16 of PUCCH-Resource when useInterlace is configured")
 	//pucchCmd.Flags().IntSliceVar(&flags.pucch._dsrResId, "_dsrResId", []int{0, 1}, "schedulingRequestResourceId of SchedulingRequestResourceConfig")
 	pucchCmd.Flags().StringVar(&flags.pucch.dsrPeriod, "dsrPeriod", "sl20", "periodicityAndOffset of SchedulingRequestResourceConfig[sym2,sym6or7,sl1,sl2,sl4,sl5,sl8,sl10,sl16,sl20,sl40,sl80,sl160,sl320,sl640]")
 	pucchCmd.Flags().IntVar(&flags.pucch.dsrOffset, "dsrOffset", 2, "periodicityAndOffset of SchedulingRequestResourceConfig[0..period-1]")
 	pucchCmd.Flags().IntVar(&flags.pucch._dsrPucchRes, "_dsrPucchRes", 2, "resource of SchedulingRequestResourceConfig")
 	pucchCmd.Flags().SortFlags = false
+	viper.BindPFlag("nrrg.pucch.pucchResCommon", pucchCmd.Flags().Lookup("pucchResCommon"))
 	viper.BindPFlag("nrrg.pucch._numSlots", pucchCmd.Flags().Lookup("_numSlots"))
 	viper.BindPFlag("nrrg.pucch._interSlotFreqHop", pucchCmd.Flags().Lookup("_interSlotFreqHop"))
 	viper.BindPFlag("nrrg.pucch._addDmrs", pucchCmd.Flags().Lookup("_addDmrs"))
@@ -5350,6 +15540,8 @@ func initPucchCmd() {
 	viper.BindPFlag("nrrg.pucch._pucchNumRbs", pucchCmd.Flags().Lookup("_pucchNumRbs"))
 	viper.BindPFlag("nrrg.pucch._pucchStartSymb", pucchCmd.Flags().Lookup("_pucchStartSymb"))
 	viper.BindPFlag("nrrg.pucch._pucchNumSymbs", pucchCmd.Flags().Lookup("_pucchNumSymbs"))
+	viper.BindPFlag("nrrg.pucch._pucchInterlace", pucchCmd.Flags().Lookup("_pucchInterlace"))
+	viper.BindPFlag("nrrg.pucch._pucchRbSet", pucchCmd.Flags().Lookup("_pucchRbSet"))
 	//viper.BindPFlag("nrrg.pucch._dsrResId", pucchCmd.Flags().Lookup("_dsrResId"))
 	viper.BindPFlag("nrrg.pucch.dsrPeriod", pucchCmd.Flags().Lookup("dsrPeriod"))
 	viper.BindPFlag("nrrg.pucch.dsrOffset", pucchCmd.Flags().Lookup("dsrOffset"))
@@ -5367,16 +15559,243 @@ func initPucchCmd() {
 	pucchCmd.Flags().MarkHidden("_pucchNumRbs")
 	pucchCmd.Flags().MarkHidden("_pucchStartSymb")
 	pucchCmd.Flags().MarkHidden("_pucchNumSymbs")
+	pucchCmd.Flags().MarkHidden("_pucchInterlace")
+	pucchCmd.Flags().MarkHidden("_pucchRbSet")
 	//pucchCmd.Flags().MarkHidden("_dsrResId")
 	pucchCmd.Flags().MarkHidden("_dsrPucchRes")
 }
 
+func initPagingCmd() {
+	pagingCmd.Flags().StringVar(&flags.paging.defaultPagingCycle, "defaultPagingCycle", "rf128", "defaultPagingCycle of PCCH-Config[rf32,rf64,rf128,rf256]")
+	pagingCmd.Flags().StringVar(&flags.paging.nAndPagingFrameOffset, "nAndPagingFrameOffset", "oneT", "nAndPagingFrameOffset of PCCH-Config[oneT,halfT,quarterT,oneEighthT,oneSixteenthT]")
+	pagingCmd.Flags().IntVar(&flags.paging.pagingFrameOffset, "pagingFrameOffset", 0, "PF_offset of nAndPagingFrameOffset[0..T/N-1]")
+	pagingCmd.Flags().StringVar(&flags.paging.ns, "ns", "one", "ns of PCCH-Config[four,two,one]")
+	pagingCmd.Flags().IntSliceVar(&flags.paging.firstPdcchMoOfPo, "firstPdcchMoOfPo", []int{}, "firstPDCCH-MonitoringOccasionOfPO of PCCH-Config, one value per PO within a PF, or not configured if not set")
+	pagingCmd.Flags().IntVar(&flags.paging.numPdcchMoPerSsbInPo, "numPdcchMoPerSsbInPo", 1, "nrofPDCCH-MonitoringOccasionPerSSB-InPO of PCCH-Config[1..4]")
+	pagingCmd.Flags().IntVar(&flags.paging.ueId, "ueId", 0, "UE_ID for paging, which is 5G-S-TMSI mod 1024[0..1023]")
+	pagingCmd.Flags().BoolVar(&flags.paging.pagedUe, "pagedUe", false, "Whether UE is paged before random access(mobile terminated access)")
+	pagingCmd.Flags().IntVar(&flags.paging.pagingSearchSpace, "pagingSearchSpace", 3, "pagingSearchSpace of PDCCH-ConfigCommon[0 or searchSpaceId of Type2-PDCCH CSS set]")
+	pagingCmd.Flags().SortFlags = false
+	viper.BindPFlag("nrrg.paging.defaultPagingCycle", pagingCmd.Flags().Lookup("defaultPagingCycle"))
+	viper.BindPFlag("nrrg.paging.nAndPagingFrameOffset", pagingCmd.Flags().Lookup("nAndPagingFrameOffset"))
+	viper.BindPFlag("nrrg.paging.pagingFrameOffset", pagingCmd.Flags().Lookup("pagingFrameOffset"))
+	viper.BindPFlag("nrrg.paging.ns", pagingCmd.Flags().Lookup("ns"))
+	viper.BindPFlag("nrrg.paging.firstPdcchMoOfPo", pagingCmd.Flags().Lookup("firstPdcchMoOfPo"))
+	viper.BindPFlag("nrrg.paging.numPdcchMoPerSsbInPo", pagingCmd.Flags().Lookup("numPdcchMoPerSsbInPo"))
+	viper.BindPFlag("nrrg.paging.ueId", pagingCmd.Flags().Lookup("ueId"))
+	viper.BindPFlag("nrrg.paging.pagedUe", pagingCmd.Flags().Lookup("pagedUe"))
+	viper.BindPFlag("nrrg.paging.pagingSearchSpace", pagingCmd.Flags().Lookup("pagingSearchSpace"))
+}
+
+func initOsiCmd() {
+	osiCmd.Flags().StringVar(&flags.osi.siWindowLength, "siWindowLength", "s20", "si-WindowLength of SI-SchedulingInfo in number of slots[s5,s10,s20,s40,s80,s160,s320,s640,s1280]")
+	osiCmd.Flags().StringSliceVar(&flags.osi.siPeriodicity, "siPeriodicity", []string{}, "si-Periodicity of each SI message in schedulingInfoList[rf8,rf16,rf32,rf64,rf128,rf256,rf512], or no SI message is scheduled if not set")
+	osiCmd.Flags().IntVar(&flags.osi.siNumTxPerSsb, "siNumTxPerSsb", 1, "number of PDCCH monitoring occasions(x=0,1,...) for each transmitted SSB in SI-window in which SI message is transmitted")
+	osiCmd.Flags().SortFlags = false
+	viper.BindPFlag("nrrg.osi.siWindowLength", osiCmd.Flags().Lookup("siWindowLength"))
+	viper.BindPFlag("nrrg.osi.siPeriodicity", osiCmd.Flags().Lookup("siPeriodicity"))
+	viper.BindPFlag("nrrg.osi.siNumTxPerSsb", osiCmd.Flags().Lookup("siNumTxPerSsb"))
+}
+
+func initDrxCmd() {
+	drxCmd.Flags().BoolVar(&flags.drx.drxEnabled, "drxEnabled", false, "Whether DRX-Config is configured")
+	drxCmd.Flags().StringVar(&flags.drx.drxOnDurationTimer, "drxOnDurationTimer", "ms10", "drx-onDurationTimer of DRX-Config[ms1,ms2,ms3,ms4,ms5,ms6,ms8,ms10,ms20,ms30,ms40,ms50,ms60,ms80,ms100,ms200,ms300,ms400,ms500,ms600,ms800,ms1000,ms1200,ms1600]")
+	drxCmd.Flags().StringVar(&flags.drx.drxInactivityTimer, "drxInactivityTimer", "ms20", "drx-InactivityTimer of DRX-Config[ms0,ms1,ms2,ms3,ms4,ms5,ms6,ms8,ms10,ms20,ms30,ms40,ms50,ms60,ms80,ms100,ms200,ms300,ms500,ms750,ms1280,ms1920,ms2560]")
+	drxCmd.Flags().IntVar(&flags.drx.drxHarqRttTimerDl, "drxHarqRttTimerDl", 56, "drx-HARQ-RTT-TimerDL of DRX-Config in number of symbols[0..56]")
+	drxCmd.Flags().IntVar(&flags.drx.drxHarqRttTimerUl, "drxHarqRttTimerUl", 56, "drx-HARQ-RTT-TimerUL of DRX-Config in number of symbols[0..56]")
+	drxCmd.Flags().StringVar(&flags.drx.drxRetransmissionTimerDl, "drxRetransmissionTimerDl", "sl8", "drx-RetransmissionTimerDL of DRX-Config[sl0,sl1,sl2,sl4,sl6,sl8,sl16,sl24,sl33,sl40,sl64,sl80,sl96,sl112,sl128,sl160,sl320]")
+	drxCmd.Flags().StringVar(&flags.drx.drxRetransmissionTimerUl, "drxRetransmissionTimerUl", "sl8", "drx-RetransmissionTimerUL of DRX-Config[sl0,sl1,sl2,sl4,sl6,sl8,sl16,sl24,sl33,sl40,sl64,sl80,sl96,sl112,sl128,sl160,sl320]")
+	drxCmd.Flags().StringVar(&flags.drx.drxLongCycle, "drxLongCycle", "ms40", "drx-LongCycle of drx-LongCycleStartOffset[ms10,ms20,ms32,ms40,ms60,ms64,ms70,ms80,ms128,ms160,ms256,ms320,ms512,ms640,ms1024,ms1280,ms2048,ms2560,ms5120,ms10240]")
+	drxCmd.Flags().IntVar(&flags.drx.drxStartOffset, "drxStartOffset", 0, "drx-StartOffset of drx-LongCycleStartOffset[0..drx-LongCycle-1]")
+	drxCmd.Flags().StringVar(&flags.drx.drxShortCycle, "drxShortCycle", "", "drx-ShortCycle of shortDRX[ms2,ms3,ms4,ms5,ms6,ms7,ms8,ms10,ms14,ms16,ms20,ms30,ms32,ms35,ms40,ms64,ms80,ms128,ms160,ms256,ms320,ms512,ms640], or Short DRX cycle is not configured if not set")
+	drxCmd.Flags().IntVar(&flags.drx.drxShortCycleTimer, "drxShortCycleTimer", 1, "drx-ShortCycleTimer of shortDRX in multiples of drx-ShortCycle[1..16]")
+	drxCmd.Flags().IntVar(&flags.drx.numTxPerCycle, "numTxPerCycle", 1, "Number of PDSCH/PUSCH with new transmission per DRX cycle, or full buffer if 0")
+	drxCmd.Flags().IntVar(&flags.drx.nackPeriod, "nackPeriod", 0, "Every nackPeriod-th PDSCH/PUSCH with new transmission is not successfully decoded, or all are successfully decoded if 0")
+	drxCmd.Flags().SortFlags = false
+	viper.BindPFlag("nrrg.drx.drxEnabled", drxCmd.Flags().Lookup("drxEnabled"))
+	viper.BindPFlag("nrrg.drx.drxOnDurationTimer", drxCmd.Flags().Lookup("drxOnDurationTimer"))
+	viper.BindPFlag("nrrg.drx.drxInactivityTimer", drxCmd.Flags().Lookup("drxInactivityTimer"))
+	viper.BindPFlag("nrrg.drx.drxHarqRttTimerDl", drxCmd.Flags().Lookup("drxHarqRttTimerDl"))
+	viper.BindPFlag("nrrg.drx.drxHarqRttTimerUl", drxCmd.Flags().Lookup("drxHarqRttTimerUl"))
+	viper.BindPFlag("nrrg.drx.drxRetransmissionTimerDl", drxCmd.Flags().Lookup("drxRetransmissionTimerDl"))
+	viper.BindPFlag("nrrg.drx.drxRetransmissionTimerUl", drxCmd.Flags().Lookup("drxRetransmissionTimerUl"))
+	viper.BindPFlag("nrrg.drx.drxLongCycle", drxCmd.Flags().Lookup("drxLongCycle"))
+	viper.BindPFlag("nrrg.drx.drxStartOffset", drxCmd.Flags().Lookup("drxStartOffset"))
+	viper.BindPFlag("nrrg.drx.drxShortCycle", drxCmd.Flags().Lookup("drxShortCycle"))
+	viper.BindPFlag("nrrg.drx.drxShortCycleTimer", drxCmd.Flags().Lookup("drxShortCycleTimer"))
+	viper.BindPFlag("nrrg.drx.numTxPerCycle", drxCmd.Flags().Lookup("numTxPerCycle"))
+	viper.BindPFlag("nrrg.drx.nackPeriod", drxCmd.Flags().Lookup("nackPeriod"))
+}
+
+func initCgSpsCmd() {
+	cgSpsCmd.Flags().BoolVar(&flags.cgsps.spsEnabled, "spsEnabled", false, "Whether SPS-Config is configured")
+	cgSpsCmd.Flags().StringVar(&flags.cgsps.spsPeriodicity, "spsPeriodicity", "ms20", "periodicity of SPS-Config[ms10,ms20,ms32,ms40,ms64,ms80,ms128,ms160,ms320,ms640]")
+	cgSpsCmd.Flags().IntVar(&flags.cgsps.spsNumHarqProc, "spsNumHarqProc", 2, "nrofHARQ-Processes of SPS-Config[1..8]")
+	cgSpsCmd.Flags().IntVar(&flags.cgsps.spsHarqProcIdOffset, "spsHarqProcIdOffset", 0, "harq-ProcID-Offset-r16 of SPS-Config[0..15]")
+	cgSpsCmd.Flags().IntVar(&flags.cgsps.spsN1PucchAn, "spsN1PucchAn", 0, "n1PUCCH-AN of SPS-Config, which is the PUCCH-ResourceId of a format 0 or format 1 PUCCH resource")
+	cgSpsCmd.Flags().BoolVar(&flags.cgsps.cgEnabled, "cgEnabled", false, "Whether ConfiguredGrantConfig is configured")
+	cgSpsCmd.Flags().StringVar(&flags.cgsps.cgType, "cgType", "type2", "Type of configured grant[type1,type2]")
+	cgSpsCmd.Flags().StringVar(&flags.cgsps.cgPeriodicity, "cgPeriodicity", "sym10x14", "periodicity of ConfiguredGrantConfig in number of symbols[sym2,sym7,symNx14 for normal CP or sym2,sym6,symNx12 for extended CP]")
+	cgSpsCmd.Flags().IntVar(&flags.cgsps.cgNumHarqProc, "cgNumHarqProc", 2, "nrofHARQ-Processes of ConfiguredGrantConfig[1..16]")
+	cgSpsCmd.Flags().IntVar(&flags.cgsps.cgHarqProcIdOffset2, "cgHarqProcIdOffset2", 0, "harq-ProcID-Offset2 of ConfiguredGrantConfig[0..15]")
+	cgSpsCmd.Flags().StringVar(&flags.cgsps.cgRepK, "cgRepK", "n1", "repK of ConfiguredGrantConfig[n1,n2,n4,n8]")
+	cgSpsCmd.Flags().StringVar(&flags.cgsps.cgRepKRv, "cgRepKRv", "s1-0231", "repK-RV of ConfiguredGrantConfig[s1-0231,s2-0303,s3-0000]")
+	cgSpsCmd.Flags().IntVar(&flags.cgsps.cgTimeDomainOffset, "cgTimeDomainOffset", 0, "timeDomainOffset of rrc-ConfiguredUplinkGrant for configured grant Type 1[0..5119]")
+	cgSpsCmd.Flags().SortFlags = false
+	viper.BindPFlag("nrrg.cgsps.spsEnabled", cgSpsCmd.Flags().Lookup("spsEnabled"))
+	viper.BindPFlag("nrrg.cgsps.spsPeriodicity", cgSpsCmd.Flags().Lookup("spsPeriodicity"))
+	viper.BindPFlag("nrrg.cgsps.spsNumHarqProc", cgSpsCmd.Flags().Lookup("spsNumHarqProc"))
+	viper.BindPFlag("nrrg.cgsps.spsHarqProcIdOffset", cgSpsCmd.Flags().Lookup("spsHarqProcIdOffset"))
+	viper.BindPFlag("nrrg.cgsps.spsN1PucchAn", cgSpsCmd.Flags().Lookup("spsN1PucchAn"))
+	viper.BindPFlag("nrrg.cgsps.cgEnabled", cgSpsCmd.Flags().Lookup("cgEnabled"))
+	viper.BindPFlag("nrrg.cgsps.cgType", cgSpsCmd.Flags().Lookup("cgType"))
+	viper.BindPFlag("nrrg.cgsps.cgPeriodicity", cgSpsCmd.Flags().Lookup("cgPeriodicity"))
+	viper.BindPFlag("nrrg.cgsps.cgNumHarqProc", cgSpsCmd.Flags().Lookup("cgNumHarqProc"))
+	viper.BindPFlag("nrrg.cgsps.cgHarqProcIdOffset2", cgSpsCmd.Flags().Lookup("cgHarqProcIdOffset2"))
+	viper.BindPFlag("nrrg.cgsps.cgRepK", cgSpsCmd.Flags().Lookup("cgRepK"))
+	viper.BindPFlag("nrrg.cgsps.cgRepKRv", cgSpsCmd.Flags().Lookup("cgRepKRv"))
+	viper.BindPFlag("nrrg.cgsps.cgTimeDomainOffset", cgSpsCmd.Flags().Lookup("cgTimeDomainOffset"))
+}
+
+func initDssCmd() {
+	dssCmd.Flags().BoolVar(&flags.dss.lteCrsEnabled, "lteCrsEnabled", false, "Whether lte-CRS-ToMatchAround is configured")
+	dssCmd.Flags().IntVar(&flags.dss.lteCarrierFreqDl, "lteCarrierFreqDl", 636, "carrierFreqDL of lte-CRS-ToMatchAround in number of 15KHz subcarriers relative to point A[0..16383]")
+	dssCmd.Flags().StringVar(&flags.dss.lteCarrierBw, "lteCarrierBw", "n100", "carrierBandwidthDL of lte-CRS-ToMatchAround[n6,n15,n25,n50,n75,n100]")
+	dssCmd.Flags().StringVar(&flags.dss.lteNumCrsPorts, "lteNumCrsPorts", "n2", "nrofCRS-Ports of lte-CRS-ToMatchAround[n1,n2,n4]")
+	dssCmd.Flags().StringVar(&flags.dss.lteVShift, "lteVShift", "n0", "v-Shift of lte-CRS-ToMatchAround[n0..n5]")
+	dssCmd.Flags().IntSliceVar(&flags.dss.lteMbsfnSubframes, "lteMbsfnSubframes", []int{}, "MBSFN subframes of lte-CRS-ToMatchAround[1,2,3,6,7,8 for FDD or 3,4,7,8,9 for TDD]")
+	dssCmd.Flags().IntVar(&flags.dss.ltePdcchNumSymbs, "ltePdcchNumSymbs", 2, "Number of OFDM symbols of LTE PDCCH region per subframe[1..3]")
+	dssCmd.Flags().StringVar(&flags.dss.rmpRbBitmap, "rmpRbBitmap", "", "resourceBlocks of bitmaps of RateMatchPattern, where the leftmost bit corresponds to CRB 0")
+	dssCmd.Flags().StringVar(&flags.dss.rmpSymbBitmap, "rmpSymbBitmap", "11100000000000", "symbolsInResourceBlock of bitmaps of RateMatchPattern, where the leftmost bit corresponds to symbol 0")
+	dssCmd.Flags().StringVar(&flags.dss.rmpPeriodicityAndPattern, "rmpPeriodicityAndPattern", "", "periodicityAndPattern of bitmaps of RateMatchPattern, where each bit corresponds to a slot[2,4,5,8,10,20,40 bits]")
+	dssCmd.Flags().SortFlags = false
+	viper.BindPFlag("nrrg.dss.lteCrsEnabled", dssCmd.Flags().Lookup("lteCrsEnabled"))
+	viper.BindPFlag("nrrg.dss.lteCarrierFreqDl", dssCmd.Flags().Lookup("lteCarrierFreqDl"))
+	viper.BindPFlag("nrrg.dss.lteCarrierBw", dssCmd.Flags().Lookup("lteCarrierBw"))
+	viper.BindPFlag("nrrg.dss.lteNumCrsPorts", dssCmd.Flags().Lookup("lteNumCrsPorts"))
+	viper.BindPFlag("nrrg.dss.lteVShift", dssCmd.Flags().Lookup("lteVShift"))
+	viper.BindPFlag("nrrg.dss.lteMbsfnSubframes", dssCmd.Flags().Lookup("lteMbsfnSubframes"))
+	viper.BindPFlag("nrrg.dss.ltePdcchNumSymbs", dssCmd.Flags().Lookup("ltePdcchNumSymbs"))
+	viper.BindPFlag("nrrg.dss.rmpRbBitmap", dssCmd.Flags().Lookup("rmpRbBitmap"))
+	viper.BindPFlag("nrrg.dss.rmpSymbBitmap", dssCmd.Flags().Lookup("rmpSymbBitmap"))
+	viper.BindPFlag("nrrg.dss.rmpPeriodicityAndPattern", dssCmd.Flags().Lookup("rmpPeriodicityAndPattern"))
+}
+
+func initCaCmd() {
+	caCmd.Flags().IntSliceVar(&flags.ca.scellIndex, "scellIndex", []int{}, "sCellIndex of each SCell[1..31]")
+	caCmd.Flags().StringSliceVar(&flags.ca.scellBand, "scellBand", []string{}, "NR frequency band of each SCell")
+	caCmd.Flags().StringSliceVar(&flags.ca.scellScs, "scellScs", []string{}, "subcarrierSpacing of SCS-SpecificCarrier of each SCell[15KHz,30KHz,60KHz,120KHz]")
+	caCmd.Flags().StringSliceVar(&flags.ca.scellBw, "scellBw", []string{}, "Transmission bandwidth(MHz) of each SCell")
+	caCmd.Flags().StringSliceVar(&flags.ca.scellPatPeriod, "scellPatPeriod", []string{}, "dl-UL-TransmissionPeriodicity of TDD-UL-DL-Pattern of each SCell[0.5ms,0.625ms,1ms,1.25ms,2ms,2.5ms,5ms,10ms]")
+	caCmd.Flags().IntSliceVar(&flags.ca.scellPatNumDlSlots, "scellPatNumDlSlots", []int{}, "nrofDownlinkSlots of TDD-UL-DL-Pattern of each SCell[0..80]")
+	caCmd.Flags().IntSliceVar(&flags.ca.scellPatNumDlSymbs, "scellPatNumDlSymbs", []int{}, "nrofDownlinkSymbols of TDD-UL-DL-Pattern of each SCell[0..13]")
+	caCmd.Flags().IntSliceVar(&flags.ca.scellPatNumUlSymbs, "scellPatNumUlSymbs", []int{}, "nrofUplinkSymbols of TDD-UL-DL-Pattern of each SCell[0..13]")
+	caCmd.Flags().IntSliceVar(&flags.ca.scellPatNumUlSlots, "scellPatNumUlSlots", []int{}, "nrofUplinkSlots of TDD-UL-DL-Pattern of each SCell[0..80]")
+	caCmd.Flags().IntSliceVar(&flags.ca.scellCif, "scellCif", []int{}, "cif-InSchedulingCell of CrossCarrierSchedulingConfig of each SCell[1..7], or 0 if the SCell is self-scheduled")
+	caCmd.Flags().SortFlags = false
+	viper.BindPFlag("nrrg.ca.scellIndex", caCmd.Flags().Lookup("scellIndex"))
+	viper.BindPFlag("nrrg.ca.scellBand", caCmd.Flags().Lookup("scellBand"))
+	viper.BindPFlag("nrrg.ca.scellScs", caCmd.Flags().Lookup("scellScs"))
+	viper.BindPFlag("nrrg.ca.scellBw", caCmd.Flags().Lookup("scellBw"))
+	viper.BindPFlag("nrrg.ca.scellPatPeriod", caCmd.Flags().Lookup("scellPatPeriod"))
+	viper.BindPFlag("nrrg.ca.scellPatNumDlSlots", caCmd.Flags().Lookup("scellPatNumDlSlots"))
+	viper.BindPFlag("nrrg.ca.scellPatNumDlSymbs", caCmd.Flags().Lookup("scellPatNumDlSymbs"))
+	viper.BindPFlag("nrrg.ca.scellPatNumUlSymbs", caCmd.Flags().Lookup("scellPatNumUlSymbs"))
+	viper.BindPFlag("nrrg.ca.scellPatNumUlSlots", caCmd.Flags().Lookup("scellPatNumUlSlots"))
+	viper.BindPFlag("nrrg.ca.scellCif", caCmd.Flags().Lookup("scellCif"))
+}
+
+func initMeasCmd() {
+	measCmd.Flags().StringVar(&flags.meas.smtcPeriodicity, "smtcPeriodicity", "", "periodicity of periodicityAndOffset of SSB-MTC[sf5,sf10,sf20,sf40,sf80,sf160]")
+	measCmd.Flags().IntVar(&flags.meas.smtcOffset, "smtcOffset", 0, "offset of periodicityAndOffset of SSB-MTC in number of subframes[0..periodicity-1]")
+	measCmd.Flags().StringVar(&flags.meas.smtcDuration, "smtcDuration", "sf5", "duration of SSB-MTC[sf1..sf5]")
+	measCmd.Flags().IntVar(&flags.meas.gapPatternId, "gapPatternId", -1, "gapPatternId of MeasGapConfig[0..25], or -1 if MeasGapConfig is not configured")
+	measCmd.Flags().IntVar(&flags.meas.gapOffset, "gapOffset", 0, "gapOffset of MeasGapConfig in number of subframes[0..MGRP-1]")
+	measCmd.Flags().StringVar(&flags.meas.gapMgta, "gapMgta", "ms0", "mgta of MeasGapConfig[ms0,ms0dot25,ms0dot5]")
+	measCmd.Flags().IntSliceVar(&flags.meas.nbrPci, "nbrPci", []int{}, "Physical cell identity of each neighbour cell[0..1007]")
+	measCmd.Flags().StringSliceVar(&flags.meas.nbrSsbPeriod, "nbrSsbPeriod", []string{}, "SSB periodicity of each neighbour cell[ms5,ms10,ms20,ms40,ms80,ms160]")
+	measCmd.Flags().IntSliceVar(&flags.meas.nbrSsbOffset, "nbrSsbOffset", []int{}, "Timing offset of SSB bursts of each neighbour cell relative to SFN 0 of the serving cell in number of symbols")
+	measCmd.Flags().SortFlags = false
+	viper.BindPFlag("nrrg.meas.smtcPeriodicity", measCmd.Flags().Lookup("smtcPeriodicity"))
+	viper.BindPFlag("nrrg.meas.smtcOffset", measCmd.Flags().Lookup("smtcOffset"))
+	viper.BindPFlag("nrrg.meas.smtcDuration", measCmd.Flags().Lookup("smtcDuration"))
+	viper.BindPFlag("nrrg.meas.gapPatternId", measCmd.Flags().Lookup("gapPatternId"))
+	viper.BindPFlag("nrrg.meas.gapOffset", measCmd.Flags().Lookup("gapOffset"))
+	viper.BindPFlag("nrrg.meas.gapMgta", measCmd.Flags().Lookup("gapMgta"))
+	viper.BindPFlag("nrrg.meas.nbrPci", measCmd.Flags().Lookup("nbrPci"))
+	viper.BindPFlag("nrrg.meas.nbrSsbPeriod", measCmd.Flags().Lookup("nbrSsbPeriod"))
+	viper.BindPFlag("nrrg.meas.nbrSsbOffset", measCmd.Flags().Lookup("nbrSsbOffset"))
+}
+
+func initPosCmd() {
+	posCmd.Flags().IntVar(&flags.pos.prsPeriod, "prsPeriod", 20, "dl-PRS-Periodicity of DL-PRS-ResourceSet[4,5,8,10,16,20,32,40,64,80,160,320,640,1280,2560,5120,10240,20480]")
+	posCmd.Flags().IntVar(&flags.pos.prsSetSlotOffset, "prsSetSlotOffset", 0, "dl-PRS-ResourceSetSlotOffset of DL-PRS-ResourceSet in number of slots[0..2^u*dl-PRS-Periodicity-1]")
+	posCmd.Flags().IntVar(&flags.pos.prsRepFactor, "prsRepFactor", 1, "dl-PRS-ResourceRepetitionFactor of DL-PRS-ResourceSet[1,2,4,6,8,16,32]")
+	posCmd.Flags().IntVar(&flags.pos.prsTimeGap, "prsTimeGap", 1, "dl-PRS-ResourceTimeGap of DL-PRS-ResourceSet in number of slots[1,2,4,8,16,32]")
+	posCmd.Flags().IntVar(&flags.pos.prsCombSize, "prsCombSize", 2, "dl-PRS-CombSizeN of DL-PRS-ResourceSet[2,4,6,12]")
+	posCmd.Flags().IntVar(&flags.pos.prsNumSymbs, "prsNumSymbs", 2, "dl-PRS-NumSymbols of each DL PRS resource[2,4,6,12]")
+	posCmd.Flags().IntVar(&flags.pos.prsStartPrb, "prsStartPrb", 0, "dl-PRS-StartPRB of DL-PRS-ResourceSet relative to point A[0..2176]")
+	posCmd.Flags().IntVar(&flags.pos.prsNumPrbs, "prsNumPrbs", 24, "dl-PRS-ResourceBandwidth of DL-PRS-ResourceSet in number of PRBs[24..272 with a granularity of 4]")
+	posCmd.Flags().StringVar(&flags.pos.prsMutingOpt1, "prsMutingOpt1", "", "Bitmap of dl-PRS-MutingOption1 of DL-PRS-ResourceSet[2,4,6,8,16,32 bits]")
+	posCmd.Flags().IntVar(&flags.pos.prsMutingBitRepFactor, "prsMutingBitRepFactor", 1, "dl-PRS-MutingBitRepetitionFactor of dl-PRS-MutingOption1[1,2,4,8]")
+	posCmd.Flags().StringVar(&flags.pos.prsMutingOpt2, "prsMutingOpt2", "", "Bitmap of dl-PRS-MutingOption2 of DL-PRS-ResourceSet[dl-PRS-ResourceRepetitionFactor bits]")
+	posCmd.Flags().IntSliceVar(&flags.pos.prsResId, "prsResId", []int{}, "nr-DL-PRS-ResourceID of each DL PRS resource[0..63]")
+	posCmd.Flags().IntSliceVar(&flags.pos.prsResReOffset, "prsResReOffset", []int{}, "RE offset of dl-PRS-CombSizeN-AndReOffset of each DL PRS resource[0..dl-PRS-CombSizeN-1]")
+	posCmd.Flags().IntSliceVar(&flags.pos.prsResSlotOffset, "prsResSlotOffset", []int{}, "dl-PRS-ResourceSlotOffset of each DL PRS resource[0..511]")
+	posCmd.Flags().IntSliceVar(&flags.pos.prsResSymbOffset, "prsResSymbOffset", []int{}, "dl-PRS-ResourceSymbolOffset of each DL PRS resource[0..12]")
+	posCmd.Flags().IntSliceVar(&flags.pos.srsPosResId, "srsPosResId", []int{}, "srs-PosResourceId of each SRS-PosResource[0..63]")
+	posCmd.Flags().StringSliceVar(&flags.pos.srsPosNumCombs, "srsPosNumCombs", []string{}, "transmissionComb of each SRS-PosResource[n2,n4,n8]")
+	posCmd.Flags().IntSliceVar(&flags.pos.srsPosCombOff, "srsPosCombOff", []int{}, "combOffset of transmissionComb of each SRS-PosResource[0..K_TC-1]")
+	posCmd.Flags().IntSliceVar(&flags.pos.srsPosStartPos, "srsPosStartPos", []int{}, "startPosition of resourceMapping of each SRS-PosResource[0..13]")
+	posCmd.Flags().StringSliceVar(&flags.pos.srsPosNumSymbs, "srsPosNumSymbs", []string{}, "nrofSymbols of resourceMapping of each SRS-PosResource[n1,n2,n4,n8,n12]")
+	posCmd.Flags().IntSliceVar(&flags.pos.srsPosFreqShift, "srsPosFreqShift", []int{}, "freqDomainShift of each SRS-PosResource[0..268]")
+	posCmd.Flags().IntSliceVar(&flags.pos.srsPosCSrs, "srsPosCSrs", []int{}, "c-SRS of freqHopping of each SRS-PosResource[0..63]")
+	posCmd.Flags().StringSliceVar(&flags.pos.srsPosPeriod, "srsPosPeriod", []string{}, "periodicity of periodicityAndOffset-sp of each SRS-PosResource[sl1,sl2,sl4,sl5,sl8,sl10,sl16,sl20,sl32,sl40,sl64,sl80,sl160,sl320,sl640,sl1280,sl2560,sl5120,sl10240,sl20480,sl40960,sl81920]")
+	posCmd.Flags().IntSliceVar(&flags.pos.srsPosOffset, "srsPosOffset", []int{}, "offset of periodicityAndOffset-sp of each SRS-PosResource in number of slots[0..periodicity-1]")
+	posCmd.Flags().SortFlags = false
+	viper.BindPFlag("nrrg.pos.prsPeriod", posCmd.Flags().Lookup("prsPeriod"))
+	viper.BindPFlag("nrrg.pos.prsSetSlotOffset", posCmd.Flags().Lookup("prsSetSlotOffset"))
+	viper.BindPFlag("nrrg.pos.prsRepFactor", posCmd.Flags().Lookup("prsRepFactor"))
+	viper.BindPFlag("nrrg.pos.prsTimeGap", posCmd.Flags().Lookup("prsTimeGap"))
+	viper.BindPFlag("nrrg.pos.prsCombSize", posCmd.Flags().Lookup("prsCombSize"))
+	viper.BindPFlag("nrrg.pos.prsNumSymbs", posCmd.Flags().Lookup("prsNumSymbs"))
+	viper.BindPFlag("nrrg.pos.prsStartPrb", posCmd.Flags().Lookup("prsStartPrb"))
+	viper.BindPFlag("nrrg.pos.prsNumPrbs", posCmd.Flags().Lookup("prsNumPrbs"))
+	viper.BindPFlag("nrrg.pos.prsMutingOpt1", posCmd.Flags().Lookup("prsMutingOpt1"))
+	viper.BindPFlag("nrrg.pos.prsMutingBitRepFactor", posCmd.Flags().Lookup("prsMutingBitRepFactor"))
+	viper.BindPFlag("nrrg.pos.prsMutingOpt2", posCmd.Flags().Lookup("prsMutingOpt2"))
+	viper.BindPFlag("nrrg.pos.prsResId", posCmd.Flags().Lookup("prsResId"))
+	viper.BindPFlag("nrrg.pos.prsResReOffset", posCmd.Flags().Lookup("prsResReOffset"))
+	viper.BindPFlag("nrrg.pos.prsResSlotOffset", posCmd.Flags().Lookup("prsResSlotOffset"))
+	viper.BindPFlag("nrrg.pos.prsResSymbOffset", posCmd.Flags().Lookup("prsResSymbOffset"))
+	viper.BindPFlag("nrrg.pos.srsPosResId", posCmd.Flags().Lookup("srsPosResId"))
+	viper.BindPFlag("nrrg.pos.srsPosNumCombs", posCmd.Flags().Lookup("srsPosNumCombs"))
+	viper.BindPFlag("nrrg.pos.srsPosCombOff", posCmd.Flags().Lookup("srsPosCombOff"))
+	viper.BindPFlag("nrrg.pos.srsPosStartPos", posCmd.Flags().Lookup("srsPosStartPos"))
+	viper.BindPFlag("nrrg.pos.srsPosNumSymbs", posCmd.Flags().Lookup("srsPosNumSymbs"))
+	viper.BindPFlag("nrrg.pos.srsPosFreqShift", posCmd.Flags().Lookup("srsPosFreqShift"))
+	viper.BindPFlag("nrrg.pos.srsPosCSrs", posCmd.Flags().Lookup("srsPosCSrs"))
+	viper.BindPFlag("nrrg.pos.srsPosPeriod", posCmd.Flags().Lookup("srsPosPeriod"))
+	viper.BindPFlag("nrrg.pos.srsPosOffset", posCmd.Flags().Lookup("srsPosOffset"))
+}
+
 func initAdvancedCmd() {
 	advancedCmd.Flags().IntVar(&flags.advanced.bestSsb, "bestSsb", 0, "Best SSB index")
 	advancedCmd.Flags().IntVar(&flags.advanced.pdcchSlotSib1, "pdcchSlotSib1", -1, "PDCCH slot for SIB1")
 	advancedCmd.Flags().IntVar(&flags.advanced.prachOccMsg1, "prachOccMsg1", -1, "PRACH occasion for Msg1")
 	advancedCmd.Flags().IntVar(&flags.advanced.pdcchOccMsg2, "pdcchOccMsg2", 4, "PDCCH occasion for Msg2")
 	advancedCmd.Flags().IntVar(&flags.advanced.pdcchOccMsg4, "pdcchOccMsg4", 0, "PDCCH occasion for Msg4")
+	advancedCmd.Flags().IntVar(&flags.advanced.msgAPreamb, "msgAPreamb", 0, "Contention-based preamble for MsgA, which is relative to the first preamble associated with the best SSB")
+	advancedCmd.Flags().BoolVar(&flags.advanced.fallbackRar, "fallbackRar", false, "Whether fallbackRAR(true) or successRAR(false) is received in MsgB")
+	advancedCmd.Flags().IntVar(&flags.advanced.cRnti, "cRnti", 17921, "C-RNTI of UE[1..65519]")
+	advancedCmd.Flags().IntVar(&flags.advanced.numSchedRfs, "numSchedRfs", 1, "Number of radio frames for PDSCH/PUSCH scheduling with C-RNTI after random access[0..8]")
+	advancedCmd.Flags().StringSliceVar(&flags.advanced.format, "format", []string{"xlsx"}, "Format(s) of NR resource grid export[xlsx,csv,json,parquet,png,svg]")
+	advancedCmd.Flags().StringVar(&flags.advanced.outPath, "outPath", "", "Path of NR resource grid export without file extension, which is ./logs/nrrg_export_<timestamp> if not set")
+	advancedCmd.Flags().BoolVar(&flags.advanced.report, "report", true, "Whether to export the report of RE counts, overheads and peak throughput to <outPath>_report.csv")
+	advancedCmd.Flags().IntSliceVar(&flags.advanced.imgSlots, "imgSlots", []int{}, "Slot range of PNG/SVG export as [sfn, firstSlot, numSlots], or one image per radio frame if not set")
+	advancedCmd.Flags().IntSliceVar(&flags.advanced.imgRbs, "imgRbs", []int{}, "RB range of PNG/SVG export as [firstRb, numRbs], or all RBs of the carrier if not set")
+	advancedCmd.Flags().IntSliceVar(&flags.advanced.imgSymbs, "imgSymbs", []int{}, "Symbol range within each slot of PNG/SVG export as [firstSymb, numSymbs], or all symbols if not set")
+	advancedCmd.Flags().IntVar(&flags.advanced.lbtFailCands, "lbtFailCands", 0, "Number of candidate SSB positions at the start of discovery burst transmission window which are not transmitted due to LBT failure(NR-U)")
 	//advancedCmd.Flags().IntVar(&flags.advanced.dsrRes, "dsrRes", 0, "DSR resource index")
 	advancedCmd.Flags().SortFlags = false
 	viper.BindPFlag("nrrg.advanced.bestSsb", advancedCmd.Flags().Lookup("bestSsb"))
@@ -5384,6 +15803,17 @@ func initAdvancedCmd() {
 	viper.BindPFlag("nrrg.advanced.prachOccMsg1", advancedCmd.Flags().Lookup("prachOccMsg1"))
 	viper.BindPFlag("nrrg.advanced.pdcchOccMsg2", advancedCmd.Flags().Lookup("pdcchOccMsg2"))
 	viper.BindPFlag("nrrg.advanced.pdcchOccMsg4", advancedCmd.Flags().Lookup("pdcchOccMsg4"))
+	viper.BindPFlag("nrrg.advanced.msgAPreamb", advancedCmd.Flags().Lookup("msgAPreamb"))
+	viper.BindPFlag("nrrg.advanced.fallbackRar", advancedCmd.Flags().Lookup("fallbackRar"))
+	viper.BindPFlag("nrrg.advanced.cRnti", advancedCmd.Flags().Lookup("cRnti"))
+	viper.BindPFlag("nrrg.advanced.numSchedRfs", advancedCmd.Flags().Lookup("numSchedRfs"))
+	viper.BindPFlag("nrrg.advanced.format", advancedCmd.Flags().Lookup("format"))
+	viper.BindPFlag("nrrg.advanced.outPath", advancedCmd.Flags().Lookup("outPath"))
+	viper.BindPFlag("nrrg.advanced.report", advancedCmd.Flags().Lookup("report"))
+	viper.BindPFlag("nrrg.advanced.imgSlots", advancedCmd.Flags().Lookup("imgSlots"))
+	viper.BindPFlag("nrrg.advanced.imgRbs", advancedCmd.Flags().Lookup("imgRbs"))
+	viper.BindPFlag("nrrg.advanced.imgSymbs", advancedCmd.Flags().Lookup("imgSymbs"))
+	viper.BindPFlag("nrrg.advanced.lbtFailCands", advancedCmd.Flags().Lookup("lbtFailCands"))
 	//viper.BindPFlag("nrrg.advanced.dsrRes", advancedCmd.Flags().Lookup("dsrRes"))
 }
 
@@ -5393,6 +15823,7 @@ func loadNrrgFlags() {
 	flags.gridsetting._duplexMode = viper.GetString("nrrg.gridsetting._duplexMode")
 	flags.gridsetting._maxDlFreq = viper.GetInt("nrrg.gridsetting._maxDlFreq")
 	flags.gridsetting._freqRange = viper.GetString("nrrg.gridsetting._freqRange")
+	flags.gridsetting._unlicensed = viper.GetBool("nrrg.gridsetting._unlicensed")
 
 	flags.gridsetting.scs = viper.GetString("nrrg.gridsetting.scs")
 
@@ -5405,12 +15836,27 @@ func loadNrrgFlags() {
 	flags.gridsetting._maxLBar = viper.GetInt("nrrg.gridsetting._maxLBar")
 	flags.gridsetting._maxL = viper.GetInt("nrrg.gridsetting._maxL")
 	flags.gridsetting.candSsbIndex = viper.GetIntSlice("nrrg.gridsetting.candSsbIndex")
+	flags.gridsetting.ssbPosInBurst = viper.GetString("nrrg.gridsetting.ssbPosInBurst")
+	flags.gridsetting.ssbInOneGroup = viper.GetString("nrrg.gridsetting.ssbInOneGroup")
+	flags.gridsetting.ssbGroupPresence = viper.GetString("nrrg.gridsetting.ssbGroupPresence")
 
 	flags.gridsetting._carrierScs = viper.GetString("nrrg.gridsetting._carrierScs")
 	flags.gridsetting.dlArfcn = viper.GetInt("nrrg.gridsetting.dlArfcn")
 	flags.gridsetting.bw = viper.GetString("nrrg.gridsetting.bw")
 	flags.gridsetting._carrierNumRbs = viper.GetInt("nrrg.gridsetting._carrierNumRbs")
 	flags.gridsetting._offsetToCarrier = viper.GetInt("nrrg.gridsetting._offsetToCarrier")
+	flags.gridsetting.supBand = viper.GetString("nrrg.gridsetting.supBand")
+	flags.gridsetting._supDuplexMode = viper.GetString("nrrg.gridsetting._supDuplexMode")
+	flags.gridsetting.supArfcn = viper.GetInt("nrrg.gridsetting.supArfcn")
+	flags.gridsetting.supBw = viper.GetString("nrrg.gridsetting.supBw")
+	flags.gridsetting.supScs = viper.GetString("nrrg.gridsetting.supScs")
+	flags.gridsetting._supScs = viper.GetString("nrrg.gridsetting._supScs")
+	flags.gridsetting._supCarrierNumRbs = viper.GetInt("nrrg.gridsetting._supCarrierNumRbs")
+	flags.gridsetting.supOffsetToCarrier = viper.GetInt("nrrg.gridsetting.supOffsetToCarrier")
+	flags.gridsetting.supUsed = viper.GetBool("nrrg.gridsetting.supUsed")
+	flags.gridsetting.ssbPositionQcl = viper.GetString("nrrg.gridsetting.ssbPositionQcl")
+	flags.gridsetting.dbWinLen = viper.GetString("nrrg.gridsetting.dbWinLen")
+	flags.gridsetting._intraCellGuardBands = viper.GetIntSlice("nrrg.gridsetting._intraCellGuardBands")
 
 	flags.gridsetting.pci = viper.GetInt("nrrg.gridsetting.pci")
 
@@ -5435,6 +15881,16 @@ func loadNrrgFlags() {
 	flags.tdduldl.patNumDlSymbs = viper.GetIntSlice("nrrg.tdduldl.patNumDlSymbs")
 	flags.tdduldl.patNumUlSymbs = viper.GetIntSlice("nrrg.tdduldl.patNumUlSymbs")
 	flags.tdduldl.patNumUlSlots = viper.GetIntSlice("nrrg.tdduldl.patNumUlSlots")
+	flags.tdduldl.dedSlotIndex = viper.GetIntSlice("nrrg.tdduldl.dedSlotIndex")
+	flags.tdduldl.dedSymbols = viper.GetStringSlice("nrrg.tdduldl.dedSymbols")
+	flags.tdduldl.dedNumDlSymbs = viper.GetIntSlice("nrrg.tdduldl.dedNumDlSymbs")
+	flags.tdduldl.dedNumUlSymbs = viper.GetIntSlice("nrrg.tdduldl.dedNumUlSymbs")
+	flags.tdduldl.sfiPeriod = viper.GetInt("nrrg.tdduldl.sfiPeriod")
+	flags.tdduldl.sfiOffset = viper.GetInt("nrrg.tdduldl.sfiOffset")
+	flags.tdduldl.sfcScs = viper.GetString("nrrg.tdduldl.sfcScs")
+	flags.tdduldl.sfcId = viper.GetIntSlice("nrrg.tdduldl.sfcId")
+	flags.tdduldl.sfcSlotFormats = viper.GetStringSlice("nrrg.tdduldl.sfcSlotFormats")
+	flags.tdduldl.sfiIndex = viper.GetIntSlice("nrrg.tdduldl.sfiIndex")
 
 	flags.searchspace._coreset1FdRes = viper.GetString("nrrg.searchspace._coreset1FdRes")
 	flags.searchspace.coreset1StartCrb = viper.GetInt("nrrg.searchspace.coreset1StartCrb")
@@ -5501,6 +15957,9 @@ func loadNrrgFlags() {
 	flags.uldci._fdRa = viper.GetStringSlice("nrrg.uldci._fdRa")
 	flags.uldci.fdStartRb = viper.GetIntSlice("nrrg.uldci.fdStartRb")
 	flags.uldci.fdNumRbs = viper.GetIntSlice("nrrg.uldci.fdNumRbs")
+	flags.uldci.fdInterlaces = viper.GetIntSlice("nrrg.uldci.fdInterlaces")
+	flags.uldci.fdStartRbSet = viper.GetInt("nrrg.uldci.fdStartRbSet")
+	flags.uldci.fdNumRbSets = viper.GetInt("nrrg.uldci.fdNumRbSets")
 	flags.uldci.mcsCw0 = viper.GetIntSlice("nrrg.uldci.mcsCw0")
 	flags.uldci._tbs = viper.GetIntSlice("nrrg.uldci._tbs")
 	flags.uldci.precodingInfoNumLayers = viper.GetInt("nrrg.uldci.precodingInfoNumLayers")
@@ -5515,6 +15974,27 @@ func loadNrrgFlags() {
 	flags.bwp._bwpLocAndBw = viper.GetIntSlice("nrrg.bwp._bwpLocAndBw")
 	flags.bwp._bwpStartRb = viper.GetIntSlice("nrrg.bwp._bwpStartRb")
 	flags.bwp._bwpNumRbs = viper.GetIntSlice("nrrg.bwp._bwpNumRbs")
+	flags.bwp._supBwpStartRb = viper.GetIntSlice("nrrg.bwp._supBwpStartRb")
+	flags.bwp._supBwpNumRbs = viper.GetIntSlice("nrrg.bwp._supBwpNumRbs")
+	flags.bwp.useInterlace = viper.GetBool("nrrg.bwp.useInterlace")
+	flags.bwp.dlBwpId = viper.GetIntSlice("nrrg.bwp.dlBwpId")
+	flags.bwp.dlBwpScs = viper.GetStringSlice("nrrg.bwp.dlBwpScs")
+	flags.bwp.dlBwpCp = viper.GetStringSlice("nrrg.bwp.dlBwpCp")
+	flags.bwp.dlBwpStartRb = viper.GetIntSlice("nrrg.bwp.dlBwpStartRb")
+	flags.bwp.dlBwpNumRbs = viper.GetIntSlice("nrrg.bwp.dlBwpNumRbs")
+	flags.bwp.ulBwpId = viper.GetIntSlice("nrrg.bwp.ulBwpId")
+	flags.bwp.ulBwpScs = viper.GetStringSlice("nrrg.bwp.ulBwpScs")
+	flags.bwp.ulBwpCp = viper.GetStringSlice("nrrg.bwp.ulBwpCp")
+	flags.bwp.ulBwpStartRb = viper.GetIntSlice("nrrg.bwp.ulBwpStartRb")
+	flags.bwp.ulBwpNumRbs = viper.GetIntSlice("nrrg.bwp.ulBwpNumRbs")
+	flags.bwp.defaultDlBwpId = viper.GetInt("nrrg.bwp.defaultDlBwpId")
+	flags.bwp.bwpInactivityTimer = viper.GetString("nrrg.bwp.bwpInactivityTimer")
+	flags.bwp.bwpSwDelayType = viper.GetString("nrrg.bwp.bwpSwDelayType")
+	flags.bwp.bwpSwTrigger = viper.GetStringSlice("nrrg.bwp.bwpSwTrigger")
+	flags.bwp.bwpSwDir = viper.GetStringSlice("nrrg.bwp.bwpSwDir")
+	flags.bwp.bwpSwSfn = viper.GetIntSlice("nrrg.bwp.bwpSwSfn")
+	flags.bwp.bwpSwSlot = viper.GetIntSlice("nrrg.bwp.bwpSwSlot")
+	flags.bwp.bwpSwBwpId = viper.GetIntSlice("nrrg.bwp.bwpSwBwpId")
 
 	flags.rach.prachConfId = viper.GetInt("nrrg.rach.prachConfId")
 	flags.rach._raFormat = viper.GetString("nrrg.rach._raFormat")
@@ -5537,6 +16017,20 @@ func loadNrrgFlags() {
 	flags.rach._raLen = viper.GetInt("nrrg.rach._raLen")
 	flags.rach._raNumRbs = viper.GetInt("nrrg.rach._raNumRbs")
 	flags.rach._raKBar = viper.GetInt("nrrg.rach._raKBar")
+	flags.rach.raType = viper.GetString("nrrg.rach.raType")
+	flags.rach.msgAPuschTimeOffset = viper.GetInt("nrrg.rach.msgAPuschTimeOffset")
+	flags.rach.msgAMappingType = viper.GetString("nrrg.rach.msgAMappingType")
+	flags.rach.msgAStartSymbAndLength = viper.GetInt("nrrg.rach.msgAStartSymbAndLength")
+	flags.rach.msgANumSlots = viper.GetInt("nrrg.rach.msgANumSlots")
+	flags.rach.msgANumPosPerSlot = viper.GetInt("nrrg.rach.msgANumPosPerSlot")
+	flags.rach.msgAGuardPeriod = viper.GetInt("nrrg.rach.msgAGuardPeriod")
+	flags.rach.msgANumPosFdm = viper.GetInt("nrrg.rach.msgANumPosFdm")
+	flags.rach.msgAFreqStart = viper.GetInt("nrrg.rach.msgAFreqStart")
+	flags.rach.msgANumRbs = viper.GetInt("nrrg.rach.msgANumRbs")
+	flags.rach.msgAGuardBand = viper.GetInt("nrrg.rach.msgAGuardBand")
+	flags.rach.msgADmrsAddPos = viper.GetString("nrrg.rach.msgADmrsAddPos")
+	flags.rach.msgAMcs = viper.GetInt("nrrg.rach.msgAMcs")
+	flags.rach.msgBRespWin = viper.GetString("nrrg.rach.msgBRespWin")
 
 	flags.dmrsCommon._tag = viper.GetStringSlice("nrrg.dmrscommon._tag")
 	flags.dmrsCommon._dmrsType = viper.GetStringSlice("nrrg.dmrscommon._dmrsType")
@@ -5595,6 +16089,9 @@ func loadNrrgFlags() {
 	flags.pusch.puschMcsTable = viper.GetString("nrrg.pusch.puschMcsTable")
 	flags.pusch.puschXOh = viper.GetString("nrrg.pusch.puschXOh")
 	flags.pusch._puschRepType = viper.GetString("nrrg.pusch._puschRepType")
+	flags.pusch._puschAllocStartSymb = viper.GetInt("nrrg.pusch._puschAllocStartSymb")
+	flags.pusch._puschAllocLength = viper.GetInt("nrrg.pusch._puschAllocLength")
+	flags.pusch._puschAllocNumReps = viper.GetString("nrrg.pusch._puschAllocNumReps")
 
 	flags.csi._resSetId = viper.GetIntSlice("nrrg.csi._resSetId")
 	flags.csi._trsInfo = viper.GetStringSlice("nrrg.csi._trsInfo")
@@ -5650,6 +16147,7 @@ func loadNrrgFlags() {
 	flags.srs._resSetType = viper.GetStringSlice("nrrg.srs._resSetType")
 	flags.srs._usage = viper.GetStringSlice("nrrg.srs._usage")
 
+	flags.pucch.pucchResCommon = viper.GetInt("nrrg.pucch.pucchResCommon")
 	flags.pucch._numSlots = viper.GetString("nrrg.pucch._numSlots")
 	flags.pucch._interSlotFreqHop = viper.GetString("nrrg.pucch._interSlotFreqHop")
 	flags.pucch._addDmrs = viper.GetBool("nrrg.pucch._addDmrs")
@@ -5663,16 +16161,128 @@ func loadNrrgFlags() {
 	flags.pucch._pucchNumRbs = viper.GetIntSlice("nrrg.pucch._pucchNumRbs")
 	flags.pucch._pucchStartSymb = viper.GetIntSlice("nrrg.pucch._pucchStartSymb")
 	flags.pucch._pucchNumSymbs = viper.GetIntSlice("nrrg.pucch._pucchNumSymbs")
+	flags.pucch._pucchInterlace = viper.GetIntSlice("nrrg.pucch._pucchInterlace")
+	flags.pucch._pucchRbSet = viper.GetIntSlice("nrrg.pucch._pucchRbSet")
 	//flags.pucch._dsrResId = viper.GetIntSlice("nrrg.pucch._dsrResId")
 	flags.pucch.dsrPeriod = viper.GetString("nrrg.pucch.dsrPeriod")
 	flags.pucch.dsrOffset = viper.GetInt("nrrg.pucch.dsrOffset")
 	flags.pucch._dsrPucchRes = viper.GetInt("nrrg.pucch._dsrPucchRes")
 
+	flags.paging.defaultPagingCycle = viper.GetString("nrrg.paging.defaultPagingCycle")
+	flags.paging.nAndPagingFrameOffset = viper.GetString("nrrg.paging.nAndPagingFrameOffset")
+	flags.paging.pagingFrameOffset = viper.GetInt("nrrg.paging.pagingFrameOffset")
+	flags.paging.ns = viper.GetString("nrrg.paging.ns")
+	flags.paging.firstPdcchMoOfPo = viper.GetIntSlice("nrrg.paging.firstPdcchMoOfPo")
+	flags.paging.numPdcchMoPerSsbInPo = viper.GetInt("nrrg.paging.numPdcchMoPerSsbInPo")
+	flags.paging.ueId = viper.GetInt("nrrg.paging.ueId")
+	flags.paging.pagedUe = viper.GetBool("nrrg.paging.pagedUe")
+	flags.paging.pagingSearchSpace = viper.GetInt("nrrg.paging.pagingSearchSpace")
+
+	flags.osi.siWindowLength = viper.GetString("nrrg.osi.siWindowLength")
+	flags.osi.siPeriodicity = viper.GetStringSlice("nrrg.osi.siPeriodicity")
+	flags.osi.siNumTxPerSsb = viper.GetInt("nrrg.osi.siNumTxPerSsb")
+
+	flags.drx.drxEnabled = viper.GetBool("nrrg.drx.drxEnabled")
+	flags.drx.drxOnDurationTimer = viper.GetString("nrrg.drx.drxOnDurationTimer")
+	flags.drx.drxInactivityTimer = viper.GetString("nrrg.drx.drxInactivityTimer")
+	flags.drx.drxHarqRttTimerDl = viper.GetInt("nrrg.drx.drxHarqRttTimerDl")
+	flags.drx.drxHarqRttTimerUl = viper.GetInt("nrrg.drx.drxHarqRttTimerUl")
+	flags.drx.drxRetransmissionTimerDl = viper.GetString("nrrg.drx.drxRetransmissionTimerDl")
+	flags.drx.drxRetransmissionTimerUl = viper.GetString("nrrg.drx.drxRetransmissionTimerUl")
+	flags.drx.drxLongCycle = viper.GetString("nrrg.drx.drxLongCycle")
+	flags.drx.drxStartOffset = viper.GetInt("nrrg.drx.drxStartOffset")
+	flags.drx.drxShortCycle = viper.GetString("nrrg.drx.drxShortCycle")
+	flags.drx.drxShortCycleTimer = viper.GetInt("nrrg.drx.drxShortCycleTimer")
+	flags.drx.numTxPerCycle = viper.GetInt("nrrg.drx.numTxPerCycle")
+	flags.drx.nackPeriod = viper.GetInt("nrrg.drx.nackPeriod")
+
+	flags.cgsps.spsEnabled = viper.GetBool("nrrg.cgsps.spsEnabled")
+	flags.cgsps.spsPeriodicity = viper.GetString("nrrg.cgsps.spsPeriodicity")
+	flags.cgsps.spsNumHarqProc = viper.GetInt("nrrg.cgsps.spsNumHarqProc")
+	flags.cgsps.spsHarqProcIdOffset = viper.GetInt("nrrg.cgsps.spsHarqProcIdOffset")
+	flags.cgsps.spsN1PucchAn = viper.GetInt("nrrg.cgsps.spsN1PucchAn")
+	flags.cgsps.cgEnabled = viper.GetBool("nrrg.cgsps.cgEnabled")
+	flags.cgsps.cgType = viper.GetString("nrrg.cgsps.cgType")
+	flags.cgsps.cgPeriodicity = viper.GetString("nrrg.cgsps.cgPeriodicity")
+	flags.cgsps.cgNumHarqProc = viper.GetInt("nrrg.cgsps.cgNumHarqProc")
+	flags.cgsps.cgHarqProcIdOffset2 = viper.GetInt("nrrg.cgsps.cgHarqProcIdOffset2")
+	flags.cgsps.cgRepK = viper.GetString("nrrg.cgsps.cgRepK")
+	flags.cgsps.cgRepKRv = viper.GetString("nrrg.cgsps.cgRepKRv")
+	flags.cgsps.cgTimeDomainOffset = viper.GetInt("nrrg.cgsps.cgTimeDomainOffset")
+
+	flags.dss.lteCrsEnabled = viper.GetBool("nrrg.dss.lteCrsEnabled")
+	flags.dss.lteCarrierFreqDl = viper.GetInt("nrrg.dss.lteCarrierFreqDl")
+	flags.dss.lteCarrierBw = viper.GetString("nrrg.dss.lteCarrierBw")
+	flags.dss.lteNumCrsPorts = viper.GetString("nrrg.dss.lteNumCrsPorts")
+	flags.dss.lteVShift = viper.GetString("nrrg.dss.lteVShift")
+	flags.dss.lteMbsfnSubframes = viper.GetIntSlice("nrrg.dss.lteMbsfnSubframes")
+	flags.dss.ltePdcchNumSymbs = viper.GetInt("nrrg.dss.ltePdcchNumSymbs")
+	flags.dss.rmpRbBitmap = viper.GetString("nrrg.dss.rmpRbBitmap")
+	flags.dss.rmpSymbBitmap = viper.GetString("nrrg.dss.rmpSymbBitmap")
+	flags.dss.rmpPeriodicityAndPattern = viper.GetString("nrrg.dss.rmpPeriodicityAndPattern")
+
+	flags.ca.scellIndex = viper.GetIntSlice("nrrg.ca.scellIndex")
+	flags.ca.scellBand = viper.GetStringSlice("nrrg.ca.scellBand")
+	flags.ca.scellScs = viper.GetStringSlice("nrrg.ca.scellScs")
+	flags.ca.scellBw = viper.GetStringSlice("nrrg.ca.scellBw")
+	flags.ca.scellPatPeriod = viper.GetStringSlice("nrrg.ca.scellPatPeriod")
+	flags.ca.scellPatNumDlSlots = viper.GetIntSlice("nrrg.ca.scellPatNumDlSlots")
+	flags.ca.scellPatNumDlSymbs = viper.GetIntSlice("nrrg.ca.scellPatNumDlSymbs")
+	flags.ca.scellPatNumUlSymbs = viper.GetIntSlice("nrrg.ca.scellPatNumUlSymbs")
+	flags.ca.scellPatNumUlSlots = viper.GetIntSlice("nrrg.ca.scellPatNumUlSlots")
+	flags.ca.scellCif = viper.GetIntSlice("nrrg.ca.scellCif")
+
+	flags.meas.smtcPeriodicity = viper.GetString("nrrg.meas.smtcPeriodicity")
+	flags.meas.smtcOffset = viper.GetInt("nrrg.meas.smtcOffset")
+	flags.meas.smtcDuration = viper.GetString("nrrg.meas.smtcDuration")
+	flags.meas.gapPatternId = viper.GetInt("nrrg.meas.gapPatternId")
+	flags.meas.gapOffset = viper.GetInt("nrrg.meas.gapOffset")
+	flags.meas.gapMgta = viper.GetString("nrrg.meas.gapMgta")
+	flags.meas.nbrPci = viper.GetIntSlice("nrrg.meas.nbrPci")
+	flags.meas.nbrSsbPeriod = viper.GetStringSlice("nrrg.meas.nbrSsbPeriod")
+	flags.meas.nbrSsbOffset = viper.GetIntSlice("nrrg.meas.nbrSsbOffset")
+
+	flags.pos.prsPeriod = viper.GetInt("nrrg.pos.prsPeriod")
+	flags.pos.prsSetSlotOffset = viper.GetInt("nrrg.pos.prsSetSlotOffset")
+	flags.pos.prsRepFactor = viper.GetInt("nrrg.pos.prsRepFactor")
+	flags.pos.prsTimeGap = viper.GetInt("nrrg.pos.prsTimeGap")
+	flags.pos.prsCombSize = viper.GetInt("nrrg.pos.prsCombSize")
+	flags.pos.prsNumSymbs = viper.GetInt("nrrg.pos.prsNumSymbs")
+	flags.pos.prsStartPrb = viper.GetInt("nrrg.pos.prsStartPrb")
+	flags.pos.prsNumPrbs = viper.GetInt("nrrg.pos.prsNumPrbs")
+	flags.pos.prsMutingOpt1 = viper.GetString("nrrg.pos.prsMutingOpt1")
+	flags.pos.prsMutingBitRepFactor = viper.GetInt("nrrg.pos.prsMutingBitRepFactor")
+	flags.pos.prsMutingOpt2 = viper.GetString("nrrg.pos.prsMutingOpt2")
+	flags.pos.prsResId = viper.GetIntSlice("nrrg.pos.prsResId")
+	flags.pos.prsResReOffset = viper.GetIntSlice("nrrg.pos.prsResReOffset")
+	flags.pos.prsResSlotOffset = viper.GetIntSlice("nrrg.pos.prsResSlotOffset")
+	flags.pos.prsResSymbOffset = viper.GetIntSlice("nrrg.pos.prsResSymbOffset")
+	flags.pos.srsPosResId = viper.GetIntSlice("nrrg.pos.srsPosResId")
+	flags.pos.srsPosNumCombs = viper.GetStringSlice("nrrg.pos.srsPosNumCombs")
+	flags.pos.srsPosCombOff = viper.GetIntSlice("nrrg.pos.srsPosCombOff")
+	flags.pos.srsPosStartPos = viper.GetIntSlice("nrrg.pos.srsPosStartPos")
+	flags.pos.srsPosNumSymbs = viper.GetStringSlice("nrrg.pos.srsPosNumSymbs")
+	flags.pos.srsPosFreqShift = viper.GetIntSlice("nrrg.pos.srsPosFreqShift")
+	flags.pos.srsPosCSrs = viper.GetIntSlice("nrrg.pos.srsPosCSrs")
+	flags.pos.srsPosPeriod = viper.GetStringSlice("nrrg.pos.srsPosPeriod")
+	flags.pos.srsPosOffset = viper.GetIntSlice("nrrg.pos.srsPosOffset")
+
 	flags.advanced.bestSsb = viper.GetInt("nrrg.advanced.bestSsb")
 	flags.advanced.pdcchSlotSib1 = viper.GetInt("nrrg.advanced.pdcchSlotSib1")
 	flags.advanced.prachOccMsg1 = viper.GetInt("nrrg.advanced.prachOccMsg1")
 	flags.advanced.pdcchOccMsg2 = viper.GetInt("nrrg.advanced.pdcchOccMsg2")
 	flags.advanced.pdcchOccMsg4 = viper.GetInt("nrrg.advanced.pdcchOccMsg4")
+	flags.advanced.msgAPreamb = viper.GetInt("nrrg.advanced.msgAPreamb")
+	flags.advanced.fallbackRar = viper.GetBool("nrrg.advanced.fallbackRar")
+	flags.advanced.cRnti = viper.GetInt("nrrg.advanced.cRnti")
+	flags.advanced.numSchedRfs = viper.GetInt("nrrg.advanced.numSchedRfs")
+	flags.advanced.format = viper.GetStringSlice("nrrg.advanced.format")
+	flags.advanced.outPath = viper.GetString("nrrg.advanced.outPath")
+	flags.advanced.report = viper.GetBool("nrrg.advanced.report")
+	flags.advanced.imgSlots = viper.GetIntSlice("nrrg.advanced.imgSlots")
+	flags.advanced.imgRbs = viper.GetIntSlice("nrrg.advanced.imgRbs")
+	flags.advanced.imgSymbs = viper.GetIntSlice("nrrg.advanced.imgSymbs")
+	flags.advanced.lbtFailCands = viper.GetInt("nrrg.advanced.lbtFailCands")
 	//flags.advanced.dsrRes = viper.GetInt("nrrg.advanced.dsrRes")
 }
 
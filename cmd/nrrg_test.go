@@ -0,0 +1,992 @@
+/*
+Copyright © 2020 Zhengwei Gao<28912001@qq.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zhenggao2/ngapp/nrgrid"
+	"github.com/zhenggao2/ngapp/utils"
+)
+
+func TestGetPagingParams(t *testing.T) {
+	tests := []struct {
+		cycle    string
+		nAndPfo  string
+		ns       string
+		T, N, Ns int
+	}{
+		{"rf32", "oneT", "one", 32, 32, 1},
+		{"rf64", "halfT", "two", 64, 32, 2},
+		{"rf128", "quarterT", "four", 128, 32, 4},
+		{"rf256", "oneSixteenthT", "four", 256, 16, 4},
+		{"rf32", "oneThirtySecondT", "one", 32, -1, -1},
+		{"rf64", "oneEighthT", "three", 64, 8, -1},
+	}
+
+	saved := flags.paging
+	defer func() { flags.paging = saved }()
+	for _, tt := range tests {
+		flags.paging.defaultPagingCycle = tt.cycle
+		flags.paging.nAndPagingFrameOffset = tt.nAndPfo
+		flags.paging.ns = tt.ns
+		T, N, Ns := getPagingParams()
+		if T != tt.T || N != tt.N || Ns != tt.Ns {
+			t.Errorf("getPagingParams(%v, %v, %v) = (%v, %v, %v), want (%v, %v, %v)", tt.cycle, tt.nAndPfo, tt.ns, T, N, Ns, tt.T, tt.N, tt.Ns)
+		}
+	}
+}
+
+func TestGetPagingFrame(t *testing.T) {
+	tests := []struct {
+		sfn, T, N, Ns, pfOffset, ueId int
+		pf, iS                        int
+	}{
+		// (SFN + PF_offset) mod T = (T div N)*(UE_ID mod N), i_s = floor(UE_ID/N) mod Ns
+		{0, 32, 32, 1, 0, 5, 5, 0},
+		{5, 32, 32, 1, 0, 5, 5, 0},
+		{6, 32, 32, 1, 0, 5, 37, 0},
+		{0, 64, 16, 4, 2, 37, 18, 2},
+		{19, 64, 16, 4, 2, 37, 82, 2},
+		{0, 128, 8, 2, 15, 1023, 97, 1},
+		{0, 256, 256, 4, 0, 1023, 255, 3},
+		// PF is determined by SFN mod 1024
+		{1020, 32, 32, 1, 0, 5, 1029, 0},
+	}
+
+	for _, tt := range tests {
+		pf, iS := getPagingFrame(tt.sfn, tt.T, tt.N, tt.Ns, tt.pfOffset, tt.ueId)
+		if pf != tt.pf || iS != tt.iS {
+			t.Errorf("getPagingFrame(%v, %v, %v, %v, %v, %v) = (%v, %v), want (%v, %v)", tt.sfn, tt.T, tt.N, tt.Ns, tt.pfOffset, tt.ueId, pf, iS, tt.pf, tt.iS)
+		}
+	}
+}
+
+func TestGetDbWinNumCands(t *testing.T) {
+	tests := []struct {
+		dbWinLen string
+		ssbScs   string
+		maxLBar  int
+		want     int
+	}{
+		{"ms0dot5", "15KHz", 10, 1},
+		{"ms1", "15KHz", 10, 2},
+		{"ms5", "15KHz", 10, 10},
+		{"ms1", "30KHz", 20, 4},
+		{"ms2", "30KHz", 20, 8},
+		{"ms5", "30KHz", 20, 20},
+	}
+
+	saved := flags.gridsetting
+	defer func() { flags.gridsetting = saved }()
+	for _, tt := range tests {
+		flags.gridsetting.dbWinLen = tt.dbWinLen
+		flags.gridsetting._ssbScs = tt.ssbScs
+		flags.gridsetting._maxLBar = tt.maxLBar
+		if got := getDbWinNumCands(); got != tt.want {
+			t.Errorf("getDbWinNumCands(%v, %v) = %v, want %v", tt.dbWinLen, tt.ssbScs, got, tt.want)
+		}
+	}
+}
+
+func TestGetSsbCands(t *testing.T) {
+	tests := []struct {
+		unlicensed   bool
+		candSsbIndex []int
+		ssbQcl       string
+		lbtFailCands int
+		cands        []int
+		ssbs         []int
+	}{
+		{false, []int{0, 2, 5}, "n4", 0, []int{0, 2, 5}, []int{0, 2, 5}},
+		// 8 candidate SSB positions within a discovery burst transmission window of 2ms with 30KHz SCS
+		{true, []int{0, 1}, "n4", 0, []int{0, 1}, []int{0, 1}},
+		{true, []int{0, 1}, "n4", 1, []int{1, 4}, []int{1, 0}},
+		{true, []int{0, 1}, "n4", 3, []int{4, 5}, []int{0, 1}},
+		{true, []int{0, 1}, "n4", 7, nil, nil},
+		{true, []int{0, 1, 2, 3}, "n2", 0, []int{0, 1}, []int{0, 1}},
+		{true, []int{3}, "n8", 2, []int{3}, []int{3}},
+		{true, []int{0}, "n1", 5, []int{5}, []int{0}},
+	}
+
+	savedGs, savedAdv := flags.gridsetting, flags.advanced
+	defer func() { flags.gridsetting, flags.advanced = savedGs, savedAdv }()
+	flags.gridsetting.dbWinLen = "ms2"
+	flags.gridsetting._ssbScs = "30KHz"
+	flags.gridsetting._maxLBar = 20
+	for _, tt := range tests {
+		flags.gridsetting._unlicensed = tt.unlicensed
+		flags.gridsetting.candSsbIndex = tt.candSsbIndex
+		flags.gridsetting.ssbPositionQcl = tt.ssbQcl
+		flags.advanced.lbtFailCands = tt.lbtFailCands
+		cands := getSsbCands()
+		if !reflect.DeepEqual(cands, tt.cands) {
+			t.Errorf("getSsbCands(%v, %v, %v, %v) = %v, want %v", tt.unlicensed, tt.candSsbIndex, tt.ssbQcl, tt.lbtFailCands, cands, tt.cands)
+			continue
+		}
+		for i, cand := range cands {
+			if ssb := getSsbIndex(cand); ssb != tt.ssbs[i] {
+				t.Errorf("getSsbIndex(%v) = %v, want %v(unlicensed=%v, ssbPositionQcl=%v)", cand, ssb, tt.ssbs[i], tt.unlicensed, tt.ssbQcl)
+			}
+		}
+	}
+}
+
+func TestGetInterlacedPrbs(t *testing.T) {
+	tests := []struct {
+		carrierScs      string
+		carrierNumRbs   int
+		offsetToCarrier int
+		guardBands      []int
+		bwpStart        int
+		bwpSize         int
+		interlaces      []int
+		rbSetStart      int
+		numRbSets       int
+		want            []int
+		wantErr         bool
+	}{
+		// M=10 for 15KHz, and RB sets [0, 50) and [53, 106) are separated by intra-cell guard band [50, 53)
+		{"15KHz", 106, 0, []int{50, 3}, 0, 106, []int{0}, 0, 1, []int{0, 10, 20, 30, 40}, false},
+		{"15KHz", 106, 0, []int{50, 3}, 0, 106, []int{3}, 1, 1, []int{53, 63, 73, 83, 93, 103}, false},
+		{"15KHz", 106, 0, []int{50, 3}, 0, 106, []int{0}, 0, 2, []int{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100}, false},
+		// M=5 for 30KHz, and n_CRB = M*n_IRB + N_start_BWP + ((m - N_start_BWP) mod M)
+		{"30KHz", 51, 2, nil, 3, 20, []int{1, 3}, 0, 1, []int{1, 3, 6, 8, 11, 13, 16, 18}, false},
+		{"30KHz", 51, 0, nil, 4, 10, []int{0}, 0, 1, []int{1, 6}, false},
+		{"60KHz", 24, 0, nil, 0, 24, []int{0}, 0, 1, nil, true},
+		{"15KHz", 106, 0, []int{50, 3}, 0, 106, []int{10}, 0, 1, nil, true},
+		{"15KHz", 106, 0, []int{50, 3}, 0, 106, []int{0}, 1, 2, nil, true},
+		{"15KHz", 106, 0, []int{50, 3}, 0, 50, []int{0}, 1, 1, nil, true},
+	}
+
+	saved := flags.gridsetting
+	defer func() { flags.gridsetting = saved }()
+	for _, tt := range tests {
+		flags.gridsetting._carrierScs = tt.carrierScs
+		flags.gridsetting._carrierNumRbs = tt.carrierNumRbs
+		flags.gridsetting._offsetToCarrier = tt.offsetToCarrier
+		flags.gridsetting._intraCellGuardBands = tt.guardBands
+		prbs, err := getInterlacedPrbs(tt.bwpStart, tt.bwpSize, tt.interlaces, tt.rbSetStart, tt.numRbSets)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("getInterlacedPrbs(%v, %v, %v, %v, %v) with carrierScs=%v: err=%v, wantErr=%v", tt.bwpStart, tt.bwpSize, tt.interlaces, tt.rbSetStart, tt.numRbSets, tt.carrierScs, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(prbs, tt.want) {
+			t.Errorf("getInterlacedPrbs(%v, %v, %v, %v, %v) with carrierScs=%v = %v, want %v", tt.bwpStart, tt.bwpSize, tt.interlaces, tt.rbSetStart, tt.numRbSets, tt.carrierScs, prbs, tt.want)
+		}
+	}
+}
+
+func TestUpdateDrx(t *testing.T) {
+	tests := []struct {
+		slotPerSubf int
+		onDuration  string
+		inactivity  string
+		longCycle   string
+		startOffset int
+		shortCycle  string
+		shortTimer  int
+		nackPeriod  int
+		numSlots    int
+		newTxSlots  []int // slots of PDCCH indicating new transmission
+		activeSlots []int
+		nackSlots   []int
+	}{
+		// Long DRX cycle only
+		{1, "ms2", "ms0", "ms10", 0, "", 0, 0, 25, nil, []int{0, 1, 10, 11, 20, 21}, nil},
+		{2, "ms1", "ms0", "ms10", 3, "", 0, 0, 30, nil, []int{6, 7, 26, 27}, nil},
+		// drx-InactivityTimer is started in the slot after the PDCCH, and the Short DRX cycle is used after its expiry until drx-ShortCycleTimer expires
+		{1, "ms2", "ms3", "ms10", 0, "ms5", 2, 0, 25, []int{1}, []int{0, 1, 2, 3, 4, 5, 6, 10, 11, 20, 21}, nil},
+		// drx-InactivityTimer is restarted by each new transmission, and every nackPeriod-th new transmission is not successfully decoded
+		{1, "ms2", "ms2", "ms20", 0, "", 0, 2, 20, []int{1, 3, 5}, []int{0, 1, 2, 3, 4, 5, 6, 7}, []int{3}},
+	}
+
+	savedDrx, savedSlotPerSubf, savedSlotPerRf := flags.drx, rgd.slotPerSubf, rgd.slotPerRf
+	defer func() { flags.drx, rgd.slotPerSubf, rgd.slotPerRf = savedDrx, savedSlotPerSubf, savedSlotPerRf }()
+	for i, tt := range tests {
+		rgd.slotPerSubf, rgd.slotPerRf = tt.slotPerSubf, 10*tt.slotPerSubf
+		flags.drx.drxOnDurationTimer = tt.onDuration
+		flags.drx.drxInactivityTimer = tt.inactivity
+		flags.drx.drxLongCycle = tt.longCycle
+		flags.drx.drxStartOffset = tt.startOffset
+		flags.drx.drxShortCycle = tt.shortCycle
+		flags.drx.drxShortCycleTimer = tt.shortTimer
+		flags.drx.nackPeriod = tt.nackPeriod
+
+		drx := &DrxInfo{numSlots: tt.numSlots}
+		var active, nack []int
+		for n := 0; n < tt.numSlots; n++ {
+			if updateDrx(drx, n) {
+				active = append(active, n)
+			}
+			if utils.ContainsInt(tt.newTxSlots, n) && nackDrxNewTx(drx, n) {
+				nack = append(nack, n)
+			}
+		}
+
+		if !reflect.DeepEqual(active, tt.activeSlots) {
+			t.Errorf("case %v: active time=%v, want %v", i, active, tt.activeSlots)
+		}
+		if !reflect.DeepEqual(nack, tt.nackSlots) {
+			t.Errorf("case %v: NACK=%v, want %v", i, nack, tt.nackSlots)
+		}
+	}
+}
+
+func TestGetDrxRetx(t *testing.T) {
+	retx := [][]int{{5, 8, 0}, {10, 12, 1}}
+	tests := []struct {
+		n    int
+		nack bool
+		want int
+	}{
+		{4, false, -1},
+		{5, false, 0},
+		{7, false, 0},
+		{8, false, -1},
+		{6, true, -1},
+		{10, true, 1},
+		{11, false, 1},
+		{12, false, -1},
+	}
+
+	for _, tt := range tests {
+		if got := getDrxRetx(retx, tt.n, tt.nack); got != tt.want {
+			t.Errorf("getDrxRetx(%v, %v, %v) = %v, want %v", retx, tt.n, tt.nack, got, tt.want)
+		}
+	}
+}
+
+func TestGetPuschReps(t *testing.T) {
+	// DDDSU with special slot of 10 DL symbols, 2 flexible symbols and 2 UL symbols
+	var pat []string
+	for slot := 0; slot < 10; slot++ {
+		for symb := 0; symb < 14; symb++ {
+			switch {
+			case slot%5 < 3 || (slot%5 == 3 && symb < 10):
+				pat = append(pat, "D")
+			case slot%5 == 3 && symb < 12:
+				pat = append(pat, "F")
+			default:
+				pat = append(pat, "U")
+			}
+		}
+	}
+
+	tests := []struct {
+		duplexMode string
+		n, S, L, K int
+		repType    string
+		want       [][]int
+	}{
+		// [slot, firstSymb, numSymbs, rv, omitted, index of nominal repetition]
+		{"FDD", 3, 0, 14, 4, "typeA", [][]int{{3, 0, 14, 0, 0, 0}, {4, 0, 14, 2, 0, 1}, {5, 0, 14, 3, 0, 2}, {6, 0, 14, 1, 0, 3}}},
+		{"TDD", 3, 0, 14, 2, "typeA", [][]int{{3, 0, 14, 0, 1, 0}, {4, 0, 14, 2, 0, 1}}},
+		{"FDD", 0, 10, 4, 2, "typeB", [][]int{{0, 10, 4, 0, 0, 0}, {1, 0, 4, 2, 0, 1}}},
+		// the nominal repetition crossing the slot boundary is split into two actual repetitions
+		{"FDD", 0, 12, 4, 1, "typeB", [][]int{{0, 12, 2, 0, 0, 0}, {1, 0, 2, 2, 0, 0}}},
+		// the actual repetition of a single symbol is omitted, while its redundancy version is counted
+		{"FDD", 0, 13, 4, 2, "typeB", [][]int{{0, 13, 1, 0, 1, 0}, {1, 0, 3, 2, 0, 0}, {1, 3, 4, 3, 0, 1}}},
+		// DL symbols are invalid for PUSCH repetition Type B
+		{"TDD", 3, 10, 6, 2, "typeB", [][]int{{3, 10, 4, 0, 0, 0}, {4, 0, 2, 2, 0, 0}, {4, 2, 6, 3, 0, 1}}},
+		{"TDD", 3, 8, 4, 1, "typeB", [][]int{{3, 10, 2, 0, 0, 0}}},
+		{"TDD", 3, 7, 4, 1, "typeB", [][]int{{3, 10, 1, 0, 1, 0}}},
+		{"TDD", 2, 8, 4, 3, "typeB", nil},
+	}
+
+	savedGs, savedRgd := flags.gridsetting, rgd
+	defer func() { flags.gridsetting, rgd = savedGs, savedRgd }()
+	flags.gridsetting.supUsed = false
+	rgd.symbPerSlot, rgd.slotPerRf, rgd.symbPerRf = 14, 10, 140
+	rgd.tddPat = map[int][]string{0: pat}
+	for _, tt := range tests {
+		flags.gridsetting._duplexMode = tt.duplexMode
+		if reps := getPuschReps(tt.n, tt.S, tt.L, tt.K, tt.repType); !reflect.DeepEqual(reps, tt.want) {
+			t.Errorf("getPuschReps(%v, %v, %v, %v, %v) with %v = %v, want %v", tt.n, tt.S, tt.L, tt.K, tt.repType, tt.duplexMode, reps, tt.want)
+		}
+	}
+}
+
+func TestGetTddPat(t *testing.T) {
+	// DDDFU of TDD-UL-DL-ConfigCommon with periodicity of 5 slots
+	var common []string
+	for slot := 0; slot < 10; slot++ {
+		for symb := 0; symb < 14; symb++ {
+			common = append(common, string("DDDFU"[slot%5]))
+		}
+	}
+
+	tests := []struct {
+		dedSymbols     string
+		dedNumDlSymbs  int
+		dedNumUlSymbs  int
+		sfiPeriod      int
+		sfcSlotFormats string
+		want           string // slot format of slot 3 and slot 8
+	}{
+		{"", 0, 0, 0, "", "FFFFFFFFFFFFFF"},
+		// tdd-UL-DL-ConfigurationDedicated overrides flexible symbols of slot 3
+		{"explicit", 6, 2, 0, "", "DDDDDDFFFFFFUU"},
+		{"allDownlink", 0, 0, 0, "", "DDDDDDDDDDDDDD"},
+		{"allUplink", 0, 0, 0, "", "UUUUUUUUUUUUUU"},
+		// DCI 2_0 overrides the remaining flexible symbols, while the conflicting symbols are ignored
+		{"", 0, 0, 5, "0_0_0_33_1", "DDDDDDDDDFFFUU"},
+		{"explicit", 6, 2, 5, "0_0_0_45_1", "DDDDDDFFUUUUUU"},
+		{"explicit", 6, 2, 5, "0_0_0_0_1", "DDDDDDDDDDDDUU"},
+		// slot format 255 keeps the slot format of tdd-UL-DL-ConfigurationCommon and tdd-UL-DL-ConfigurationDedicated
+		{"explicit", 6, 2, 5, "0_0_0_255_1", "DDDDDDFFFFFFUU"},
+	}
+
+	savedGs, savedTdd, savedRgd := flags.gridsetting, flags.tdduldl, rgd
+	defer func() { flags.gridsetting, flags.tdduldl, rgd = savedGs, savedTdd, savedRgd }()
+	flags.gridsetting.scs = "15KHz"
+	rgd.symbPerSlot, rgd.slotPerRf, rgd.symbPerRf, rgd.tddNumSlots = 14, 10, 140, 5
+	rgd.tddPatEvenRf, rgd.tddPatOddRf = common, common
+	for _, tt := range tests {
+		flags.tdduldl = TddUlDlFlags{sfiPeriod: tt.sfiPeriod, sfcId: []int{1}, sfcSlotFormats: []string{tt.sfcSlotFormats}, sfiIndex: []int{1}}
+		if len(tt.dedSymbols) > 0 {
+			flags.tdduldl.dedSlotIndex = []int{3}
+			flags.tdduldl.dedSymbols = []string{tt.dedSymbols}
+			flags.tdduldl.dedNumDlSymbs = []int{tt.dedNumDlSymbs}
+			flags.tdduldl.dedNumUlSymbs = []int{tt.dedNumUlSymbs}
+		}
+		rgd.tddPat = make(map[int][]string)
+
+		want := strings.Repeat(strings.Repeat("D", 42)+tt.want+strings.Repeat("U", 14), 2)
+		for _, sfn := range []int{0, 1} {
+			if pat := strings.Join(getTddPat(sfn), ""); pat != want {
+				t.Errorf("getTddPat(%v) with dedSymbols=%v, sfcSlotFormats=%v = %v, want %v", sfn, tt.dedSymbols, tt.sfcSlotFormats, pat, want)
+			}
+		}
+	}
+}
+
+func TestGetSfiSlotRatio(t *testing.T) {
+	tests := []struct {
+		scs    string
+		sfcScs string
+		want   int
+	}{
+		{"30KHz", "", 1},
+		{"30KHz", "30KHz", 1},
+		{"30KHz", "15KHz", 2},
+		{"120KHz", "60KHz", 2},
+		{"120KHz", "15KHz", 8},
+	}
+
+	savedGs, savedTdd := flags.gridsetting, flags.tdduldl
+	defer func() { flags.gridsetting, flags.tdduldl = savedGs, savedTdd }()
+	for _, tt := range tests {
+		flags.gridsetting.scs = tt.scs
+		flags.tdduldl.sfcScs = tt.sfcScs
+		if got := getSfiSlotRatio(); got != tt.want {
+			t.Errorf("getSfiSlotRatio(%v, %v) = %v, want %v", tt.scs, tt.sfcScs, got, tt.want)
+		}
+	}
+}
+
+func TestCheckSlotFormat(t *testing.T) {
+	// slot 3 is DDDDDDFFFFFFUU, and slot 4 is UUUUUUUUUUUUUU
+	pat := strings.Repeat(strings.Repeat("D", 42)+"DDDDDDFFFFFFUU"+strings.Repeat("U", 14), 2)
+
+	tests := []struct {
+		duplexMode  string
+		slot, S, L  int
+		dir         string
+		isTdd       bool
+		hasDl       bool
+		sfConflicts []string
+	}{
+		{"TDD", 0, 0, 14, "D", true, true, nil},
+		{"TDD", 3, 0, 6, "D", true, true, nil},
+		{"TDD", 3, 2, 6, "D", false, true, []string{"SPS PDSCH@[0,3,S=2,L=6](DDDDFF)"}},
+		{"TDD", 3, 12, 2, "U", true, false, nil},
+		{"TDD", 3, 10, 4, "U", false, false, []string{"CG PUSCH@[0,3,S=10,L=4](FFUU)"}},
+		{"TDD", 4, 0, 14, "U", true, false, nil},
+		{"FDD", 3, 2, 6, "D", true, false, nil},
+		{"FDD", 3, 10, 4, "U", true, false, nil},
+	}
+
+	savedGs, savedRgd := flags.gridsetting, rgd
+	defer func() { flags.gridsetting, rgd = savedGs, savedRgd }()
+	flags.gridsetting.supUsed = false
+	rgd.symbPerSlot, rgd.slotPerRf, rgd.symbPerRf = 14, 10, 140
+	rgd.tddPat = map[int][]string{0: strings.Split(pat[:140], "")}
+	for _, tt := range tests {
+		flags.gridsetting._duplexMode = tt.duplexMode
+		rgd.sfConflicts = nil
+		if got := isTddSymbs(0, tt.slot, tt.S, tt.L, tt.dir); got != tt.isTdd {
+			t.Errorf("isTddSymbs(0, %v, %v, %v, %v) with %v = %v, want %v", tt.slot, tt.S, tt.L, tt.dir, tt.duplexMode, got, tt.isTdd)
+		}
+		if got := hasTddDlSymbs(tt.slot, tt.S, tt.L); got != tt.hasDl {
+			t.Errorf("hasTddDlSymbs(%v, %v, %v) with %v = %v, want %v", tt.slot, tt.S, tt.L, tt.duplexMode, got, tt.hasDl)
+		}
+
+		ch := map[string]string{"D": "SPS PDSCH", "U": "CG PUSCH"}[tt.dir]
+		if got := checkSlotFormat(ch, 0, tt.slot, tt.S, tt.L, tt.dir); got != tt.isTdd || !reflect.DeepEqual(rgd.sfConflicts, tt.sfConflicts) {
+			t.Errorf("checkSlotFormat(%v, 0, %v, %v, %v, %v) with %v = %v, sfConflicts=%v, want %v, sfConflicts=%v", ch, tt.slot, tt.S, tt.L, tt.dir, tt.duplexMode, got, rgd.sfConflicts, tt.isTdd, tt.sfConflicts)
+		}
+	}
+}
+
+func TestGetBwpSwDelay(t *testing.T) {
+	tests := []struct {
+		delayType string
+		id0, id1  int
+		want      int
+		wantErr   bool
+	}{
+		// T_BWPswitchDelay of the larger u, in slots of the 30KHz carrier
+		{"type1", 1, 2, 2, false},
+		{"type2", 2, 1, 5, false},
+		{"type1", 1, 3, 2, false},
+		{"type2", 3, 2, 5, false},
+		// T_BWPswitchDelay is not defined for 960KHz SCS
+		{"type1", 1, 4, -1, true},
+		{"type3", 1, 2, -1, true},
+		{"", 1, 2, -1, true},
+	}
+
+	savedBwp, savedRgd := flags.bwp, rgd
+	defer func() { flags.bwp, rgd = savedBwp, savedRgd }()
+	flags.bwp._bwpScs = []string{"30KHz", "30KHz", "30KHz", "30KHz"}
+	rgd.subfPerRf, rgd.slotPerSubf = 10, 2
+	// BWP2 of 15KHz, BWP3 of 60KHz and BWP4 of 960KHz
+	rgd.bwps = []BwpData{{id: 2, dir: "DL", slotPerRf: 10}, {id: 3, dir: "DL", slotPerRf: 40}, {id: 4, dir: "DL", slotPerRf: 640}}
+	for _, tt := range tests {
+		flags.bwp.bwpSwDelayType = tt.delayType
+		delay, err := getBwpSwDelay("DL", tt.id0, tt.id1)
+		if (err != nil) != tt.wantErr || delay != tt.want {
+			t.Errorf("getBwpSwDelay(DL, %v, %v) with bwpSwDelayType=%v = (%v, %v), want %v(wantErr=%v)", tt.id0, tt.id1, tt.delayType, delay, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestGetNbrSsbMeas(t *testing.T) {
+	tests := []struct {
+		msg4Slot     int
+		gapPatternId int
+		s            int
+		smtc, gap    bool
+	}{
+		// SMTC windows are [280k, 280k+70) and measurement gaps are [560k+336, 560k+420) in symbols
+		{19, 0, 2, false, false},
+		{19, 0, 282, true, false},
+		{19, 0, 346, true, true},
+		{19, 0, 348, false, true},
+		{19, 0, 418, false, false},
+		{19, 0, 562, true, false},
+		{19, 0, 896, true, true},
+		{19, -1, 346, true, false},
+		// SMTC windows and measurement gaps are not applied before Msg4
+		{-1, 0, 346, false, false},
+		{30, 0, 346, false, false},
+	}
+
+	savedMeas, savedRgd := flags.meas, rgd
+	defer func() { flags.meas, rgd = savedMeas, savedRgd }()
+	rgd.symbPerSlot, rgd.symbPerSubf = 14, 14
+	flags.meas.smtcPeriodicity, flags.meas.smtcOffset, flags.meas.smtcDuration = "sf20", 0, "sf5"
+	flags.meas.gapOffset, flags.meas.gapMgta = 24, "ms0"
+	for _, tt := range tests {
+		rgd.msg4Slot = tt.msg4Slot
+		flags.meas.gapPatternId = tt.gapPatternId
+		if smtc, gap := getNbrSsbMeas(tt.s); smtc != tt.smtc || gap != tt.gap {
+			t.Errorf("getNbrSsbMeas(%v) with msg4Slot=%v, gapPatternId=%v = (%v, %v), want (%v, %v)", tt.s, tt.msg4Slot, tt.gapPatternId, smtc, gap, tt.smtc, tt.gap)
+		}
+	}
+}
+
+func TestIsGscnInRaster(t *testing.T) {
+	tests := []struct {
+		raster string
+		gscn   int
+		want   bool
+	}{
+		{"8993 – <1> – 9530", 8993, true},
+		{"8993 – <1> – 9530", 9530, true},
+		{"8993 – <1> – 9530", 1931, false},
+		{"8993 – <1> – 9530", 9531, false},
+		{"6246 – <3> – 6717", 6249, true},
+		{"6246 – <3> – 6717", 6250, false},
+		{"5279 – <1> – 5279,5300 – <2> – 5310", 5302, true},
+		{"5279 – <1> – 5279,5300 – <2> – 5310", 5301, false},
+		{"9996, 10010, 10024", 10010, true},
+		{"9996, 10010, 10024", 10011, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		if got := isGscnInRaster(tt.raster, tt.gscn); got != tt.want {
+			t.Errorf("isGscnInRaster(%v, %v) = %v, want %v", tt.raster, tt.gscn, got, tt.want)
+		}
+	}
+}
+
+func TestValidateNruRaster(t *testing.T) {
+	tests := []struct {
+		band    string
+		gscn    int
+		dlArfcn int
+		wantErr bool
+	}{
+		// SS_REF=5161.44MHz and F_REF=5160MHz
+		{"n46", 9000, 744000, false},
+		{"n46", 1931, 744000, true},
+		{"n46", 9000, 154600, true},
+		{"n46", 9000, 800000, true},
+		// SS_REF=6001.44MHz and F_REF=6000MHz
+		{"n96", 9583, 800000, false},
+		{"n102", 9583, 800000, false},
+		{"n102", 9900, 800000, true},
+	}
+
+	saved := flags.gridsetting
+	defer func() { flags.gridsetting = saved }()
+	flags.gridsetting._ssbScs = "30KHz"
+	for _, tt := range tests {
+		flags.gridsetting.band = tt.band
+		flags.gridsetting._maxDlFreq = nrgrid.OpBands[tt.band].MaxDlFreq
+		flags.gridsetting.gscn, flags.gridsetting.dlArfcn = tt.gscn, tt.dlArfcn
+		if err := validateNruRaster(); (err != nil) != tt.wantErr {
+			t.Errorf("validateNruRaster(%v, gscn=%v, dlArfcn=%v): err=%v, wantErr=%v", tt.band, tt.gscn, tt.dlArfcn, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGetNrrgPeakTput(t *testing.T) {
+	rep := func(s []int, n int) []int {
+		var r []int
+		for i := 0; i < n; i++ {
+			r = append(r, s...)
+		}
+		return r
+	}
+
+	tests := []struct {
+		sch       string
+		numPrbs   int
+		dmrsOh    int
+		slotSymbs []int
+		numFrames int
+		want      float64
+	}{
+		// FDD with 30KHz SCS: 20 slots per radio frame
+		{"PDSCH", 160, 12, rep([]int{14}, 20), 1, 20 * 352440 / 1e4},
+		{"PDSCH", 160, 12, rep([]int{14}, 40), 2, 20 * 352440 / 1e4},
+		// TDD of DDDSU with 6 DL symbols and 4 UL symbols in the special slot
+		{"PDSCH", 273, 12, rep([]int{14, 14, 14, 6, 0}, 4), 1, (12*606504 + 4*217128) / 1e4},
+		{"PUSCH", 273, 6, rep([]int{0, 0, 0, 4, 14}, 4), 1, (4*475584 + 4*127080) / 1e4},
+		// no RE for data
+		{"PDSCH", 160, 12, []int{1, 0}, 1, 0},
+		{"PUSCH", 160, 6, nil, 1, 0},
+	}
+
+	savedPdsch, savedPusch := flags.pdsch, flags.pusch
+	defer func() { flags.pdsch, flags.pusch = savedPdsch, savedPusch }()
+	flags.pdsch.pdschMcsTable, flags.pdsch.pdschXOh, flags.pdsch.pdschMaxLayers = "qam256", "xOh6", 2
+	flags.pusch.puschTp, flags.pusch.puschMcsTable, flags.pusch.puschXOh, flags.pusch.puschCbMaxRankNonCbMaxLayers = "disabled", "qam64", "xOh0", 2
+	for _, tt := range tests {
+		if tput := getNrrgPeakTput(tt.sch, tt.numPrbs, tt.dmrsOh, tt.slotSymbs, tt.numFrames); math.Abs(tput-tt.want) > 1e-6 {
+			t.Errorf("getNrrgPeakTput(%v, %v, %v, %v, %v) = %v, want %v", tt.sch, tt.numPrbs, tt.dmrsOh, tt.slotSymbs, tt.numFrames, tput, tt.want)
+		}
+	}
+}
+
+func TestGetOverlapSlot(t *testing.T) {
+	tests := []struct {
+		m, slotPerRf1, slotPerRf2 int
+		first, last               int
+	}{
+		// the same numerology
+		{7, 20, 20, 7, 7},
+		// slot of 30KHz to slots of 15KHz
+		{3, 20, 10, 1, 1},
+		{4, 20, 10, 2, 2},
+		{23, 20, 10, 11, 11},
+		// slot of 15KHz to slots of 30KHz
+		{1, 10, 20, 2, 3},
+		{10, 10, 20, 20, 21},
+		// slot of 15KHz to slots of 60KHz
+		{2, 10, 40, 8, 11},
+	}
+
+	for _, tt := range tests {
+		first := getOverlapSlot(tt.m, tt.slotPerRf1, tt.slotPerRf2, false)
+		last := getOverlapSlot(tt.m, tt.slotPerRf1, tt.slotPerRf2, true)
+		if first != tt.first || last != tt.last {
+			t.Errorf("getOverlapSlot(%v, %v, %v) = (%v, %v), want (%v, %v)", tt.m, tt.slotPerRf1, tt.slotPerRf2, first, last, tt.first, tt.last)
+		}
+	}
+}
+
+func TestUpdateSupCarrier(t *testing.T) {
+	tests := []struct {
+		supBand, supScs, supBw string
+		supScsWant             string
+		numRbs                 int
+		wantErr                bool
+	}{
+		// 15KHz SUL paired with 30KHz TDD carrier
+		{"n80", "15KHz", "20MHz", "15KHz", 106, false},
+		// the subcarrierSpacing of the carrier is used if supScs is not configured
+		{"n80", "", "20MHz", "30KHz", 51, false},
+		{"n75", "30KHz", "20MHz", "30KHz", 51, false},
+		{"n80", "20KHz", "20MHz", "", 0, true},
+		{"n80", "15KHz", "200MHz", "", 0, true},
+		{"n78", "30KHz", "20MHz", "", 0, true},
+	}
+
+	savedGs, savedBwp := flags.gridsetting, flags.bwp
+	defer func() { flags.gridsetting, flags.bwp = savedGs, savedBwp }()
+	flags.gridsetting._carrierScs = "30KHz"
+	for _, tt := range tests {
+		flags.gridsetting.supBand, flags.gridsetting.supScs, flags.gridsetting.supBw = tt.supBand, tt.supScs, tt.supBw
+		err := updateSupCarrier()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("updateSupCarrier(%v, %v, %v): err=%v, wantErr=%v", tt.supBand, tt.supScs, tt.supBw, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (flags.gridsetting._supScs != tt.supScsWant || flags.gridsetting._supCarrierNumRbs != tt.numRbs) {
+			t.Errorf("updateSupCarrier(%v, %v, %v) = (%v, %v), want (%v, %v)", tt.supBand, tt.supScs, tt.supBw, flags.gridsetting._supScs, flags.gridsetting._supCarrierNumRbs, tt.supScsWant, tt.numRbs)
+		}
+	}
+}
+
+func TestValidateBwpCp(t *testing.T) {
+	tests := []struct {
+		scs, cp []string
+		wantErr bool
+	}{
+		{[]string{"30KHz", "30KHz", "30KHz", "30KHz"}, []string{"normal", "normal", "normal", "normal"}, false},
+		{[]string{"60KHz", "60KHz", "60KHz", "60KHz"}, []string{"extended", "extended", "extended", "extended"}, false},
+		// extended cyclic prefix is only supported for 60KHz
+		{[]string{"30KHz", "30KHz", "30KHz", "30KHz"}, []string{"extended", "extended", "extended", "extended"}, true},
+		{[]string{"60KHz", "120KHz", "120KHz", "120KHz"}, []string{"extended", "normal", "normal", "normal"}, true},
+		// all BWPs on the carrier must have the same cyclic prefix
+		{[]string{"60KHz", "60KHz", "60KHz", "60KHz"}, []string{"extended", "normal", "normal", "normal"}, true},
+		{[]string{"60KHz", "60KHz", "60KHz", "60KHz"}, []string{"normal", "normal", "ecp", "normal"}, true},
+	}
+
+	savedBwp := flags.bwp
+	defer func() { flags.bwp = savedBwp }()
+	flags.bwp._bwpType = []string{"iniDlBwp", "dedDlBwp", "iniUlBwp", "dedUlBwp"}
+	for _, tt := range tests {
+		flags.bwp._bwpScs, flags.bwp._bwpCp = tt.scs, tt.cp
+		if err := validateBwpCp(); (err != nil) != tt.wantErr {
+			t.Errorf("validateBwpCp() with bwpScs=%v, bwpCp=%v: err=%v, wantErr=%v", tt.scs, tt.cp, err, tt.wantErr)
+		}
+	}
+}
+
+func TestInitBwpDataExtCp(t *testing.T) {
+	savedBwp, savedRgd := flags.bwp, rgd
+	defer func() { flags.bwp, rgd = savedBwp, savedRgd }()
+	rgd.subfPerRf, rgd.scPerRb = 10, 12
+	flags.bwp.dlBwpId, flags.bwp.dlBwpScs, flags.bwp.dlBwpCp, flags.bwp.dlBwpStartRb, flags.bwp.dlBwpNumRbs = []int{2, 3}, []string{"60KHz", "60KHz"}, []string{"extended", "normal"}, []int{0, 0}, []int{24, 24}
+	flags.bwp.ulBwpId, flags.bwp.ulBwpScs, flags.bwp.ulBwpCp, flags.bwp.ulBwpStartRb, flags.bwp.ulBwpNumRbs = []int{2}, []string{"60KHz"}, []string{"extended"}, []int{2}, []int{10}
+
+	initBwpData()
+	want := [][]int{
+		// [slotPerRf, symbPerSlot, scPerSymb, scPerSlot]
+		{40, 12, 288, 3456},
+		{40, 14, 288, 4032},
+		{40, 12, 144, 1728},
+	}
+	if len(rgd.bwps) != len(want) {
+		t.Fatalf("initBwpData() initialized %v BWPs, want %v", len(rgd.bwps), len(want))
+	}
+	for j, bd := range rgd.bwps {
+		if got := []int{bd.slotPerRf, bd.symbPerSlot, bd.scPerSymb, bd.scPerSlot}; !reflect.DeepEqual(got, want[j]) {
+			t.Errorf("initBwpData(): %v BWP%v(cp=%v) = %v, want %v", bd.dir, bd.id, bd.cp, got, want[j])
+		}
+	}
+}
+
+func TestGetDmrsTdPatternExtCp(t *testing.T) {
+	tests := []struct {
+		sch, mappingType string
+		S, L             int
+		dmrsAddPos, cp   string
+		want             []int
+	}{
+		// l0=2 for mapping type A, and the positions of extended cyclic prefix are different from normal cyclic prefix
+		{"PDSCH", "typeA", 2, 10, "pos1", "normal", []int{2, 9}},
+		{"PDSCH", "typeA", 2, 10, "pos1", "extended", []int{2, 8}},
+		{"PDSCH", "typeA", 2, 6, "pos1", "extended", []int{2, 6}},
+		{"PDSCH", "typeB", 4, 6, "pos1", "extended", []int{0, 4}},
+		{"PDSCH", "typeB", 0, 12, "pos0", "extended", nil},
+		{"PDSCH", "typeA", 2, 10, "pos2", "extended", nil},
+		{"PUSCH", "typeA", 0, 12, "pos1", "normal", []int{2, 9}},
+		{"PUSCH", "typeA", 0, 12, "pos1", "extended", []int{2, 8}},
+		{"PUSCH", "typeB", 2, 5, "pos1", "extended", []int{0, 4}},
+		{"PUSCH", "typeA", 0, 3, "pos0", "extended", nil},
+		{"PUSCH", "typeB", 0, 12, "pos3", "extended", nil},
+	}
+
+	savedGs, savedDss := flags.gridsetting, flags.dss
+	defer func() { flags.gridsetting, flags.dss = savedGs, savedDss }()
+	flags.gridsetting.dmrsTypeAPos = "pos2"
+	flags.dss.lteCrsEnabled = false
+	for _, tt := range tests {
+		var tdL []int
+		if tt.sch == "PDSCH" {
+			tdL, _ = getDmrsPdschTdFdPattern("type1", tt.mappingType, tt.S, tt.L, 1, tt.dmrsAddPos, 2, tt.cp)
+		} else {
+			tdL, _, _ = getDmrsPuschTdFdPattern("type1", tt.mappingType, tt.S, tt.L, 1, tt.dmrsAddPos, 2, "disabled", tt.cp)
+		}
+		if !reflect.DeepEqual(tdL, tt.want) {
+			t.Errorf("TD pattern of DMRS for %v(mappingType=%v, S=%v, L=%v, dmrsAddPos=%v, cp=%v) = %v, want %v", tt.sch, tt.mappingType, tt.S, tt.L, tt.dmrsAddPos, tt.cp, tdL, tt.want)
+		}
+	}
+}
+
+func TestGetCgPeriodicityExtCp(t *testing.T) {
+	tests := []struct {
+		cp, periodicity string
+		want            int
+		wantErr         bool
+	}{
+		{"normal", "sym7", 7, false},
+		{"normal", "sym2x14", 28, false},
+		{"normal", "sym6", -1, true},
+		{"extended", "sym2", 2, false},
+		{"extended", "sym6", 6, false},
+		{"extended", "sym2x12", 24, false},
+		{"extended", "sym7", -1, true},
+		{"extended", "sym2x14", -1, true},
+	}
+
+	savedGs, savedBwp, savedCgSps := flags.gridsetting, flags.bwp, flags.cgsps
+	defer func() { flags.gridsetting, flags.bwp, flags.cgsps = savedGs, savedBwp, savedCgSps }()
+	flags.gridsetting.scs = "60KHz"
+	for _, tt := range tests {
+		flags.bwp._bwpCp = []string{tt.cp, tt.cp, tt.cp, tt.cp}
+		flags.cgsps.cgPeriodicity = tt.periodicity
+		if p, err := getCgPeriodicity(); (err != nil) != tt.wantErr || p != tt.want {
+			t.Errorf("getCgPeriodicity() with cp=%v, cgPeriodicity=%v = (%v, %v), want %v(wantErr=%v)", tt.cp, tt.periodicity, p, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestIsPrsMuted(t *testing.T) {
+	tests := []struct {
+		opt1      string
+		factor    int
+		opt2      string
+		inst, rep int
+		want      bool
+	}{
+		// dl-PRS-MutingOption1 only, where each bit corresponds to dl-PRS-MutingBitRepetitionFactor consecutive instances
+		{"10", 1, "", 0, 0, false},
+		{"10", 1, "", 1, 3, true},
+		{"10", 2, "", 1, 0, false},
+		{"10", 2, "", 2, 0, true},
+		{"10", 2, "", 3, 1, true},
+		{"10", 2, "", 4, 0, false},
+		{"1101", 1, "", 6, 0, true},
+		{"1101", 1, "", 7, 0, false},
+		// dl-PRS-MutingOption2 only, where each bit corresponds to a single repetition index
+		{"", 1, "0110", 0, 0, true},
+		{"", 1, "0110", 5, 1, false},
+		{"", 1, "0110", 5, 3, true},
+		// both dl-PRS-MutingOption1 and dl-PRS-MutingOption2
+		{"01", 1, "10", 0, 0, true},
+		{"01", 1, "10", 1, 0, false},
+		{"01", 1, "10", 1, 1, true},
+		// neither
+		{"", 1, "", 3, 2, false},
+	}
+
+	savedPos := flags.pos
+	defer func() { flags.pos = savedPos }()
+	for _, tt := range tests {
+		flags.pos.prsMutingOpt1, flags.pos.prsMutingBitRepFactor, flags.pos.prsMutingOpt2 = tt.opt1, tt.factor, tt.opt2
+		if got := isPrsMuted(tt.inst, tt.rep); got != tt.want {
+			t.Errorf("isPrsMuted(%v, %v) with prsMutingOpt1=%v, prsMutingBitRepFactor=%v, prsMutingOpt2=%v = %v, want %v", tt.inst, tt.rep, tt.opt1, tt.factor, tt.opt2, got, tt.want)
+		}
+	}
+}
+
+func TestGetSrsPosOccasion(t *testing.T) {
+	tests := []struct {
+		offsetToCarrier, bwpStartRb int
+		numCombs                    string
+		combOff, startPos           int
+		numSymbs                    string
+		freqShift, cSrs             int
+		numRes                      int
+		first, last                 []int
+	}{
+		// the reference point is the lowest subcarrier of the BWP if N_BWP_start > n_shift
+		{0, 2, "n2", 1, 0, "n1", 0, 0, 24, []int{1, 3, 13, 25, NR_RES_SRS_POS}, []int{1, 3, 13, 71, NR_RES_SRS_POS}},
+		{1, 2, "n2", 1, 0, "n1", 2, 0, 24, []int{1, 3, 13, 49, NR_RES_SRS_POS}, []int{1, 3, 13, 95, NR_RES_SRS_POS}},
+		// otherwise the reference point is subcarrier 0 in common resource block 0
+		{0, 2, "n2", 1, 0, "n1", 2, 0, 24, []int{1, 3, 13, 25, NR_RES_SRS_POS}, []int{1, 3, 13, 71, NR_RES_SRS_POS}},
+		{0, 2, "n2", 1, 0, "n1", 4, 0, 24, []int{1, 3, 13, 49, NR_RES_SRS_POS}, []int{1, 3, 13, 95, NR_RES_SRS_POS}},
+		// k_offset of Table 6.4.1.4.3-2 is applied to each symbol
+		{0, 2, "n4", 0, 1, "n2", 0, 0, 24, []int{1, 3, 12, 24, NR_RES_SRS_POS}, []int{1, 3, 13, 70, NR_RES_SRS_POS}},
+		{0, 2, "n8", 3, 3, "n4", 0, 1, 48, []int{1, 3, 10, 27, NR_RES_SRS_POS}, []int{1, 3, 13, 113, NR_RES_SRS_POS}},
+		// REs outside the carrier are dropped
+		{0, 2, "n2", 1, 0, "n1", 18, 0, 12, []int{1, 3, 13, 217, NR_RES_SRS_POS}, []int{1, 3, 13, 239, NR_RES_SRS_POS}},
+		{0, 2, "n2", 1, 0, "n1", 20, 0, 0, nil, nil},
+	}
+
+	savedGs, savedBwp, savedPos, savedRgd := flags.gridsetting, flags.bwp, flags.pos, rgd
+	defer func() { flags.gridsetting, flags.bwp, flags.pos, rgd = savedGs, savedBwp, savedPos, savedRgd }()
+	rgd.symbPerSlot, rgd.scPerRb = 14, 12
+	flags.gridsetting.supUsed, flags.gridsetting._carrierNumRbs = false, 20
+	for _, tt := range tests {
+		flags.gridsetting._offsetToCarrier = tt.offsetToCarrier
+		flags.bwp._bwpStartRb = []int{0, 0, 0, tt.bwpStartRb}
+		flags.pos.srsPosNumCombs, flags.pos.srsPosCombOff, flags.pos.srsPosStartPos = []string{tt.numCombs}, []int{tt.combOff}, []int{tt.startPos}
+		flags.pos.srsPosNumSymbs, flags.pos.srsPosFreqShift, flags.pos.srsPosCSrs = []string{tt.numSymbs}, []int{tt.freqShift}, []int{tt.cSrs}
+		occasion := getSrsPosOccasion(0, 1, 3)
+		if len(occasion) != tt.numRes {
+			t.Errorf("getSrsPosOccasion() with %+v returns %v REs, want %v", tt, len(occasion), tt.numRes)
+			continue
+		}
+		if len(occasion) > 0 && (!reflect.DeepEqual(occasion[0], tt.first) || !reflect.DeepEqual(occasion[len(occasion)-1], tt.last)) {
+			t.Errorf("getSrsPosOccasion() with %+v = [%v ... %v], want [%v ... %v]", tt, occasion[0], occasion[len(occasion)-1], tt.first, tt.last)
+		}
+	}
+}
+
+func TestMuxDedUci(t *testing.T) {
+	tests := []struct {
+		ucis           []string
+		numAck         int
+		dsrRes, csiRes int
+		simHarqAckCsi  bool
+		r              int
+		muxUci         []string
+		decision       string
+		wantErr        bool
+	}{
+		{[]string{"SR"}, 0, 4, 2, true, 4, []string{"SR"}, "", false},
+		{[]string{"CSI"}, 0, 4, 2, true, 2, []string{"CSI"}, "", false},
+		// PUCCH resource set 0 for at most 2 HARQ-ACK bits, otherwise PUCCH resource set 1
+		{[]string{"HARQ-ACK"}, 1, 4, 2, true, 0, []string{"HARQ-ACK"}, "", false},
+		{[]string{"HARQ-ACK"}, 3, 4, 2, true, 2, []string{"HARQ-ACK"}, "HARQ-ACK information bits multiplexed in the PUCCH resource indicated by DCI 1_1", false},
+		// HARQ-ACK for SPS PDSCH only is transmitted in the PUCCH resource of n1PUCCH-AN
+		{[]string{"SPS-ACK"}, 1, 4, 2, true, 1, []string{"HARQ-ACK"}, "", false},
+		// SR and HARQ-ACK
+		{[]string{"SR", "HARQ-ACK"}, 1, 4, 2, true, 0, []string{"SR", "HARQ-ACK"}, "SR multiplexed in the PUCCH format 0 resource for HARQ-ACK", false},
+		{[]string{"SR", "HARQ-ACK"}, 1, 0, 2, true, 1, []string{"HARQ-ACK"}, "SR dropped and HARQ-ACK transmitted in the PUCCH format 1 resource for HARQ-ACK", false},
+		{[]string{"SR", "SPS-ACK"}, 1, 4, 2, true, 4, []string{"SR", "HARQ-ACK"}, "HARQ-ACK transmitted in the PUCCH resource for positive SR", false},
+		{[]string{"SR", "HARQ-ACK"}, 3, 4, 2, true, 2, []string{"SR", "HARQ-ACK"}, "SR multiplexed with HARQ-ACK in the PUCCH resource indicated by DCI 1_1", false},
+		// SR and CSI
+		{[]string{"SR", "CSI"}, 0, 4, 2, true, 2, []string{"SR", "CSI"}, "SR multiplexed in the PUCCH resource for CSI report", false},
+		// HARQ-ACK and CSI with or without simultaneousHARQ-ACK-CSI
+		{[]string{"HARQ-ACK", "CSI"}, 2, 4, 2, true, 2, []string{"HARQ-ACK", "CSI"}, "HARQ-ACK, SR if any and CSI multiplexed in the PUCCH resource indicated by DCI 1_1", false},
+		{[]string{"HARQ-ACK", "CSI"}, 2, 4, 2, false, 0, []string{"HARQ-ACK"}, "CSI dropped without simultaneousHARQ-ACK-CSI; HARQ-ACK information bits multiplexed in the PUCCH resource indicated by DCI 1_1", false},
+		{[]string{"SPS-ACK", "CSI"}, 1, 4, 2, true, 2, []string{"HARQ-ACK", "CSI"}, "HARQ-ACK for SPS PDSCH, SR if any and CSI multiplexed in the PUCCH resource for CSI report", false},
+		// no PUCCH resource for CSI report
+		{[]string{"CSI"}, 0, 4, 9, true, -1, nil, "", true},
+	}
+
+	savedPucch, savedCsi, savedCgSps, savedDlDci := flags.pucch, flags.csi, flags.cgsps, flags.dldci
+	defer func() {
+		flags.pucch, flags.csi, flags.cgsps, flags.dldci = savedPucch, savedCsi, savedCgSps, savedDlDci
+	}()
+	flags.pucch._pucchResId = []int{0, 1, 2, 3, 4}
+	flags.pucch._pucchFormat = []string{"format0", "format1", "format2", "format3", "format1"}
+	flags.cgsps.spsN1PucchAn, flags.dldci.deltaPri = 1, 0
+	for _, tt := range tests {
+		flags.pucch._dsrPucchRes, flags.csi._csiRepPucchRes, flags.pucch._simHarqAckCsi = tt.dsrRes, tt.csiRes, tt.simHarqAckCsi
+		r, muxUci, decision, err := muxDedUci(tt.ucis, tt.numAck)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("muxDedUci(%v, %v): err=%v, wantErr=%v", tt.ucis, tt.numAck, err, tt.wantErr)
+			continue
+		}
+		if r != tt.r || !reflect.DeepEqual(muxUci, tt.muxUci) || decision != tt.decision {
+			t.Errorf("muxDedUci(%v, %v) with dsrPucchRes=%v, simHarqAckCsi=%v = (%v, %v, %q), want (%v, %v, %q)", tt.ucis, tt.numAck, tt.dsrRes, tt.simHarqAckCsi, r, muxUci, decision, tt.r, tt.muxUci, tt.decision)
+		}
+	}
+}
+
+func TestGetUciPriority(t *testing.T) {
+	tests := []struct {
+		ucis []string
+		want int
+	}{
+		{[]string{"CSI"}, 0},
+		{[]string{"SR"}, 1},
+		{[]string{"SR", "CSI"}, 1},
+		{[]string{"HARQ-ACK"}, 2},
+		{[]string{"SPS-ACK", "CSI"}, 2},
+		{[]string{"SR", "HARQ-ACK", "CSI"}, 2},
+	}
+
+	for _, tt := range tests {
+		if got := getUciPriority(tt.ucis); got != tt.want {
+			t.Errorf("getUciPriority(%v) = %v, want %v", tt.ucis, got, tt.want)
+		}
+	}
+}
+
+func TestMuxUciOnPusch(t *testing.T) {
+	tests := []struct {
+		reqUci []string
+		numAck int
+	}{
+		{[]string{"SR"}, 0},
+		{[]string{"HARQ-ACK"}, 2},
+		{[]string{"SR", "SPS-ACK", "CSI"}, 1},
+	}
+
+	for _, tt := range tests {
+		tr := &PucchTrInfo{reqUci: tt.reqUci, uci: tt.reqUci, numAck: tt.numAck, r: 0, numRep: 1, res: make(map[int][]int)}
+		muxUciOnPusch(tr, "[sfn=0, slot=0]")
+		// the PUCCH is dropped and its UCI is marked as multiplexed on PUSCH
+		if !tr.onPusch || len(tr.res) != 0 || !reflect.DeepEqual(tr.uci, tt.reqUci) {
+			t.Errorf("muxUciOnPusch() with reqUci=%v: onPusch=%v, res=%v, uci=%v", tt.reqUci, tr.onPusch, tr.res, tr.uci)
+		}
+	}
+}
+
+func TestHasPucchRep(t *testing.T) {
+	tests := []struct {
+		n, numSlots int
+		want        bool
+	}{
+		// PUCCH without repetitions in slot 4
+		{4, 1, false},
+		{2, 4, false},
+		// PUCCH with 2 repetitions in slot 8 and 10
+		{8, 1, true},
+		{5, 4, true},
+		{9, 1, false},
+		{10, 2, true},
+		{11, 5, false},
+	}
+
+	savedRgd := rgd
+	defer func() { rgd = savedRgd }()
+	rgd.pucchTr = map[string]*PucchTrInfo{
+		"4_SR":       {reqUci: []string{"SR"}, uci: []string{"SR"}, numRep: 1},
+		"8_HARQ-ACK": {reqUci: []string{"HARQ-ACK"}, uci: []string{"HARQ-ACK"}, numAck: 1, numRep: 2},
+	}
+	rgd.pucchSlots = map[int]string{4: "4_SR", 8: "8_HARQ-ACK", 10: "8_HARQ-ACK"}
+	for _, tt := range tests {
+		if got := hasPucchRep(tt.n, tt.numSlots); got != tt.want {
+			t.Errorf("hasPucchRep(%v, %v) = %v, want %v", tt.n, tt.numSlots, got, tt.want)
+		}
+	}
+}
@@ -24,6 +24,7 @@ import (
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
 	"github.com/zhenggao2/ngapp/utils"
+	"go.uber.org/zap"
 )
 
 // separate flag per module
@@ -50,7 +51,8 @@ var (
 )
 
 var (
-	Logger  = utils.NewZapLogger(fmt.Sprintf("./logs/ngapp_%v.log", time.Now().Format("20060102_150405")))
+	// Logger is created by initConfig when a command is executed, so that no log file is created when the package is only imported(e.g. by tests).
+	Logger  *zap.Logger
 	cfgFile string
 	// maximum number of goroutines. Adjust maxgo in case ngapp has crashed with 'out of memory' error.
 	maxgo int
@@ -95,6 +97,8 @@ func init() {
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
+	Logger = utils.NewZapLogger(fmt.Sprintf("./logs/ngapp_%v.log", time.Now().Format("20060102_150405")))
+
 	if cfgFile != "" {
 		// Use config file from the flag.
 		viper.SetConfigFile(cfgFile)
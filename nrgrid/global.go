@@ -320,6 +320,22 @@ var NrbFr1 = map[int][]int{
 	60: {0, 11, 18, 24, 31, 38, 44, 51, 58, 65, 79, 93, 107, 121, 135},
 }
 
+// refer to 3GPP 38.101-1 vh80
+//  5.3.3	Minimum guardband and transmission bandwidth configuration: intra-cell guard bands for operation with shared spectrum channel access
+// key = scs_bw, value = list of [startCRB, nrofCRBs] of each intra-cell guard band, where startCRB is relative to the lowest RB of the carrier
+// Note: The carrier is divided into RB sets of nominal 20MHz(LBT bandwidth) by the intra-cell guard bands, and there is no intra-cell guard band for carrier bandwidth of 20MHz or less.
+var IntraCellGbFr1 = map[string][]int{
+	"15_40MHz":  {106, 4},
+	"30_40MHz":  {50, 6},
+	"30_60MHz":  {50, 6, 106, 6},
+	"30_80MHz":  {50, 6, 106, 5, 161, 6},
+	"30_100MHz": {50, 6, 106, 5, 161, 6, 217, 6},
+	"60_40MHz":  {24, 3},
+	"60_60MHz":  {24, 3, 51, 4},
+	"60_80MHz":  {24, 3, 51, 4, 79, 4},
+	"60_100MHz": {24, 3, 51, 4, 79, 4, 107, 4},
+}
+
 // refer to 3GPP 38.104 vh80
 //  Table 5.3.2-2: Transmission bandwidth configuration N_RB for FR2-1
 var NrbFr21 = map[int][]int{
@@ -520,6 +536,28 @@ var Coreset0Fr1MinChBw5m10m = map[string]*Coreset0Info{
 	"30_30_15": {1, 48, 2, []int{16}},
 }
 
+// refer to 3GPP 38.213 vh40
+//  Table 13-4A: Set of resource blocks and slot symbols of CORESET for Type0-PDCCH search space set when {SS/PBCH block, PDCCH} SCS is {30, 30} kHz for frequency bands operated with shared spectrum channel access
+// Table for FR1 with shared spectrum channel access(NR-U)
+var Coreset0Fr1SharedSpectrum = map[string]*Coreset0Info{
+	"30_30_0":  {1, 48, 1, []int{0}},
+	"30_30_1":  {1, 48, 1, []int{1}},
+	"30_30_2":  {1, 48, 1, []int{2}},
+	"30_30_3":  {1, 48, 1, []int{3}},
+	"30_30_4":  {1, 48, 2, []int{0}},
+	"30_30_5":  {1, 48, 2, []int{1}},
+	"30_30_6":  {1, 48, 2, []int{2}},
+	"30_30_7":  {1, 48, 2, []int{3}},
+	"30_30_8":  nil,
+	"30_30_9":  nil,
+	"30_30_10": nil,
+	"30_30_11": nil,
+	"30_30_12": nil,
+	"30_30_13": nil,
+	"30_30_14": nil,
+	"30_30_15": nil,
+}
+
 // refer to 3GPP 38.213 vf30/vh40
 //  vh40: Table 13-5: Set of resource blocks and slot symbols of CORESET for Type0-PDCCH search space set when {SS/PBCH block, PDCCH} SCS is {30, 15} kHz for frequency bands with minimum channel bandwidth 40MHz or for the frequency bands given in [8-1, TS 38.101-1]
 //  vh40: Table 13-6: Set of resource blocks and slot symbols of CORESET for Type0-PDCCH search space set when {SS/PBCH block, PDCCH} SCS is {30, 30} kHz for frequency bands with minimum channel bandwidth 40MHz or for the frequency bands given in [8-1, TS 38.101-1]
@@ -2744,3 +2782,97 @@ var SrPeriodSet = map[string][]string{
 	"480KHz": {"1sl", "2sl", "4sl", "8sl", "16sl", "40sl", "80sl", "160sl", "320sl", "640sl", "1280sl", "2560sl"},
 	"960KHz": {"1sl", "2sl", "4sl", "8sl", "16sl", "40sl", "80sl", "160sl", "320sl", "640sl", "1280sl", "2560sl", "5120sl"},
 }
+
+// refer to 3GPP 38.213 vh40
+//  Table 11.1.1-1: Slot formats for normal cyclic prefix
+// Note: slot formats 56~254 are reserved, and slot format 255 indicates that the UE determines the slot format based on tdd-UL-DL-ConfigurationCommon, tdd-UL-DL-ConfigurationDedicated and detected DCI formats.
+var SlotFormats = []string{
+	"DDDDDDDDDDDDDD", // 0
+	"UUUUUUUUUUUUUU", // 1
+	"FFFFFFFFFFFFFF", // 2
+	"DDDDDDDDDDDDDF", // 3
+	"DDDDDDDDDDDDFF", // 4
+	"DDDDDDDDDDDFFF", // 5
+	"DDDDDDDDDDFFFF", // 6
+	"DDDDDDDDDFFFFF", // 7
+	"FFFFFFFFFFFFFU", // 8
+	"FFFFFFFFFFFFUU", // 9
+	"FUUUUUUUUUUUUU", // 10
+	"FFUUUUUUUUUUUU", // 11
+	"FFFUUUUUUUUUUU", // 12
+	"FFFFUUUUUUUUUU", // 13
+	"FFFFFUUUUUUUUU", // 14
+	"FFFFFFUUUUUUUU", // 15
+	"DFFFFFFFFFFFFF", // 16
+	"DDFFFFFFFFFFFF", // 17
+	"DDDFFFFFFFFFFF", // 18
+	"DFFFFFFFFFFFFU", // 19
+	"DDFFFFFFFFFFFU", // 20
+	"DDDFFFFFFFFFFU", // 21
+	"DFFFFFFFFFFFUU", // 22
+	"DDFFFFFFFFFFUU", // 23
+	"DDDFFFFFFFFFUU", // 24
+	"DFFFFFFFFFFUUU", // 25
+	"DDFFFFFFFFFUUU", // 26
+	"DDDFFFFFFFFUUU", // 27
+	"DDDDDDDDDDDDFU", // 28
+	"DDDDDDDDDDDFFU", // 29
+	"DDDDDDDDDDFFFU", // 30
+	"DDDDDDDDDDDFUU", // 31
+	"DDDDDDDDDDFFUU", // 32
+	"DDDDDDDDDFFFUU", // 33
+	"DFUUUUUUUUUUUU", // 34
+	"DDFUUUUUUUUUUU", // 35
+	"DDDFUUUUUUUUUU", // 36
+	"DFFUUUUUUUUUUU", // 37
+	"DDFFUUUUUUUUUU", // 38
+	"DDDFFUUUUUUUUU", // 39
+	"DFFFUUUUUUUUUU", // 40
+	"DDFFFUUUUUUUUU", // 41
+	"DDDFFFUUUUUUUU", // 42
+	"DDDDDDDDDFFFFU", // 43
+	"DDDDDDFFFFFFUU", // 44
+	"DDDDDDFFUUUUUU", // 45
+	"DDDDDFUDDDDDFU", // 46
+	"DDFUUUUDDFUUUU", // 47
+	"DFUUUUUDFUUUUU", // 48
+	"DDDDFFUDDDDFFU", // 49
+	"DDFFUUUDDFFUUU", // 50
+	"DFFUUUUDFFUUUU", // 51
+	"DFFFFFUDFFFFFU", // 52
+	"DDFFFFUDDFFFFU", // 53
+	"FFFFFFFDDDDDDD", // 54
+	"DDFFFUUUDDDDDD", // 55
+}
+
+// refer to 3GPP 38.133 vh40
+//  Table 9.1.2-1: Gap Pattern Configurations
+// Note: key is the gap pattern ID, and value is [MGL, MGRP] in ms.
+var MeasGapPatterns = map[int][]float64{
+	0:  {6, 40},
+	1:  {6, 80},
+	2:  {3, 40},
+	3:  {3, 80},
+	4:  {6, 20},
+	5:  {6, 160},
+	6:  {4, 20},
+	7:  {4, 40},
+	8:  {4, 80},
+	9:  {4, 160},
+	10: {3, 20},
+	11: {3, 160},
+	12: {5.5, 20},
+	13: {5.5, 40},
+	14: {5.5, 80},
+	15: {5.5, 160},
+	16: {3.5, 20},
+	17: {3.5, 40},
+	18: {3.5, 80},
+	19: {3.5, 160},
+	20: {1.5, 20},
+	21: {1.5, 40},
+	22: {1.5, 80},
+	23: {1.5, 160},
+	24: {10, 80},
+	25: {20, 160},
+}
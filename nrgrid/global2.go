@@ -78,6 +78,35 @@ var DmrsPdschPosOneSymb = map[string][]int{
 	"14_typeB_pos0": nil, "14_typeB_pos1": nil, "14_typeB_pos2": nil, "14_typeB_pos3": nil,
 }
 
+// refer to 3GPP 38.211 vh40
+//  Table 7.4.1.1.2-5: PDSCH DM-RS positions l- for single-symbol DM-RS and extended cyclic prefix.
+// key="ld_mapping type_additional position"
+// Note: only dmrs-AdditionalPosition of pos0 and pos1 is supported for extended cyclic prefix.
+var DmrsPdschPosOneSymbExtCp = map[string][]int{
+	"2_typeA_pos0": nil, "2_typeA_pos1": nil,
+	"3_typeA_pos0": {0}, "3_typeA_pos1": {0},
+	"4_typeA_pos0": {0}, "4_typeA_pos1": {0},
+	"5_typeA_pos0": {0}, "5_typeA_pos1": {0},
+	"6_typeA_pos0": {0}, "6_typeA_pos1": {0, 4},
+	"7_typeA_pos0": {0}, "7_typeA_pos1": {0, 4},
+	"8_typeA_pos0": {0}, "8_typeA_pos1": {0, 6},
+	"9_typeA_pos0": {0}, "9_typeA_pos1": {0, 6},
+	"10_typeA_pos0": {0}, "10_typeA_pos1": {0, 8},
+	"11_typeA_pos0": {0}, "11_typeA_pos1": {0, 8},
+	"12_typeA_pos0": {0}, "12_typeA_pos1": {0, 8},
+	"2_typeB_pos0": {0}, "2_typeB_pos1": {0},
+	"3_typeB_pos0": {0}, "3_typeB_pos1": {0},
+	"4_typeB_pos0": {0}, "4_typeB_pos1": {0},
+	"5_typeB_pos0": {0}, "5_typeB_pos1": {0, 4},
+	"6_typeB_pos0": {0}, "6_typeB_pos1": {0, 4},
+	"7_typeB_pos0": {0}, "7_typeB_pos1": {0, 4},
+	"8_typeB_pos0": {0}, "8_typeB_pos1": {0, 6},
+	"9_typeB_pos0": {0}, "9_typeB_pos1": {0, 6},
+	"10_typeB_pos0": {0}, "10_typeB_pos1": {0, 8},
+	"11_typeB_pos0": {0}, "11_typeB_pos1": {0, 8},
+	"12_typeB_pos0": nil, "12_typeB_pos1": nil,
+}
+
 // refer to 3GPP 38.211 vh40
 //  Table 7.4.1.1.2-4: PDSCH DM-RS positions l- for double-symbol DM-RS.
 // key="td_mapping type_additional position"
@@ -148,6 +177,37 @@ var DmrsPuschPosOneSymbWoIntraSlotFh = map[string][]int{
 	"14_typeB_pos0": {0}, "14_typeB_pos1": {0, 10}, "14_typeB_pos2": {0, 5, 10}, "14_typeB_pos3": {0, 3, 6, 9},
 }
 
+// refer to 3GPP 38.211 vh40
+//  Table 6.4.1.1.3-4: PUSCH DM-RS positions l- within a slot for single-symbol DM-RS, intra-slot frequency hopping disabled and extended cyclic prefix.
+// key="ld_mapping type_additional position"
+// Note: only dmrs-AdditionalPosition of pos0 and pos1 is supported for extended cyclic prefix.
+var DmrsPuschPosOneSymbWoIntraSlotFhExtCp = map[string][]int{
+	"1_typeA_pos0": nil, "1_typeA_pos1": nil,
+	"2_typeA_pos0": nil, "2_typeA_pos1": nil,
+	"3_typeA_pos0": nil, "3_typeA_pos1": nil,
+	"4_typeA_pos0": {0}, "4_typeA_pos1": {0},
+	"5_typeA_pos0": {0}, "5_typeA_pos1": {0},
+	"6_typeA_pos0": {0}, "6_typeA_pos1": {0, 4},
+	"7_typeA_pos0": {0}, "7_typeA_pos1": {0, 4},
+	"8_typeA_pos0": {0}, "8_typeA_pos1": {0, 6},
+	"9_typeA_pos0": {0}, "9_typeA_pos1": {0, 6},
+	"10_typeA_pos0": {0}, "10_typeA_pos1": {0, 8},
+	"11_typeA_pos0": {0}, "11_typeA_pos1": {0, 8},
+	"12_typeA_pos0": {0}, "12_typeA_pos1": {0, 8},
+	"1_typeB_pos0": {0}, "1_typeB_pos1": {0},
+	"2_typeB_pos0": {0}, "2_typeB_pos1": {0},
+	"3_typeB_pos0": {0}, "3_typeB_pos1": {0},
+	"4_typeB_pos0": {0}, "4_typeB_pos1": {0},
+	"5_typeB_pos0": {0}, "5_typeB_pos1": {0, 4},
+	"6_typeB_pos0": {0}, "6_typeB_pos1": {0, 4},
+	"7_typeB_pos0": {0}, "7_typeB_pos1": {0, 4},
+	"8_typeB_pos0": {0}, "8_typeB_pos1": {0, 6},
+	"9_typeB_pos0": {0}, "9_typeB_pos1": {0, 6},
+	"10_typeB_pos0": {0}, "10_typeB_pos1": {0, 8},
+	"11_typeB_pos0": {0}, "11_typeB_pos1": {0, 8},
+	"12_typeB_pos0": {0}, "12_typeB_pos1": {0, 8},
+}
+
 // refer to 3GPP 38.211 vh40
 //  Table 6.4.1.1.3-4: PUSCH DM-RS positions l- within a slot for double-symbol DM-RS and intra-slot frequency hopping disabled.
 // key="ld_mapping type_additional position"
@@ -735,6 +795,38 @@ var PucchFmt1WithIntraSlotFreqHop = map[int][][]int{
 	14: {{3, 4}, {4, 3}},
 }
 
+// refer to 3GPP 38.211 vh40
+//  Table 6.4.1.3.3.2-1: DM-RS positions for PUCCH format 3 and 4.
+//  key=number of PUCCH symbols, val={{no additional DMRS, no hopping}, {no additional DMRS, hopping}, {additional DMRS, no hopping}, {additional DMRS, hopping}}
+var PucchFmt3Fmt4DmrsPos = map[int][][]int{
+	4:  {{1}, {0, 2}, {1}, {0, 2}},
+	5:  {{0, 3}, {0, 3}, {0, 3}, {0, 3}},
+	6:  {{1, 4}, {1, 4}, {1, 4}, {1, 4}},
+	7:  {{1, 4}, {1, 4}, {1, 4}, {1, 4}},
+	8:  {{1, 5}, {1, 5}, {1, 5}, {1, 5}},
+	9:  {{1, 6}, {1, 6}, {1, 6}, {1, 6}},
+	10: {{2, 7}, {2, 7}, {1, 3, 6, 8}, {1, 3, 6, 8}},
+	11: {{2, 7}, {2, 7}, {1, 3, 6, 9}, {1, 3, 6, 9}},
+	12: {{2, 8}, {2, 8}, {1, 4, 7, 10}, {1, 4, 7, 10}},
+	13: {{2, 9}, {2, 9}, {1, 4, 7, 11}, {1, 4, 7, 11}},
+	14: {{3, 10}, {3, 10}, {1, 5, 8, 12}, {1, 5, 8, 12}},
+}
+
+// refer to 3GPP 38.211 vh40
+//  Table 6.4.1.2.2.1-1: The parameter k_RE_ref.
+//  Table 7.4.1.2.2-1: The parameter k_RE_ref.
+//  key=[DMRS configuration type, resourceElementOffset], val=k_RE_ref of DMRS port 0..3(type1) or 0..5(type2), which is port 1000..1003 or 1000..1005 for PDSCH
+var PtrsKRefRe = map[string][]int{
+	"type1_offset00": {0, 2, 1, 3},
+	"type1_offset01": {2, 4, 3, 5},
+	"type1_offset10": {6, 8, 7, 9},
+	"type1_offset11": {8, 10, 9, 11},
+	"type2_offset00": {0, 1, 2, 3, 4, 5},
+	"type2_offset01": {1, 6, 3, 8, 5, 10},
+	"type2_offset10": {6, 7, 8, 9, 10, 11},
+	"type2_offset11": {7, 0, 9, 2, 11, 4},
+}
+
 // CsiRsLocInfo contains information on CSI-RS locations within a slot.
 type CsiRsLocInfo struct {
 	Row        int
@@ -1039,15 +1131,19 @@ func initPuschSlivRepTypeB() (map[string]int, map[string][]int) {
 	puschFromSliv := make(map[string][]int)
 	var prefix string
 
+	// Note: SLIV is not applicable when S+L exceeds the slot, in which case S and L are provided by startSymbol-r16 and length-r16 of PUSCH-Allocation-r16, and the SLIV is set to -1.
 	// case #3: prefix="10"
 	prefix = "10"
 	for _, S := range utils.PyRange(0, 14, 1) {
 		for _, L := range utils.PyRange(1, 15, 1) {
 			if S+L >= 1 && S+L <= 27 {
 				sliv, err := makeSliv(S, L)
-				if err == nil {
-					keyToSliv := fmt.Sprintf("%s_%d_%d", prefix, S, L)
-					puschToSliv[keyToSliv] = sliv
+				if err != nil {
+					sliv = -1
+				}
+				keyToSliv := fmt.Sprintf("%s_%d_%d", prefix, S, L)
+				puschToSliv[keyToSliv] = sliv
+				if sliv >= 0 {
 					keyFromSliv := fmt.Sprintf("%s_%d", prefix, sliv)
 					puschFromSliv[keyFromSliv] = []int{S, L}
 				}
@@ -1061,9 +1157,12 @@ func initPuschSlivRepTypeB() (map[string]int, map[string][]int) {
 		for _, L := range utils.PyRange(1, 13, 1) {
 			if S+L >= 1 && S+L <= 23 {
 				sliv, err := makeSliv(S, L)
-				if err == nil {
-					keyToSliv := fmt.Sprintf("%s_%d_%d", prefix, S, L)
-					puschToSliv[keyToSliv] = sliv
+				if err != nil {
+					sliv = -1
+				}
+				keyToSliv := fmt.Sprintf("%s_%d_%d", prefix, S, L)
+				puschToSliv[keyToSliv] = sliv
+				if sliv >= 0 {
 					keyFromSliv := fmt.Sprintf("%s_%d", prefix, sliv)
 					puschFromSliv[keyFromSliv] = []int{S, L}
 				}